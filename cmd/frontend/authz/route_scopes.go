@@ -0,0 +1,26 @@
+package authz
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteScopesMiddleware returns a middleware for a mux router that rejects requests authenticated
+// with an access token that grants none of the scopes declared for the matched route in
+// routeScopes (by route name). Routes that aren't in routeScopes only accept the "user:all" scope.
+func RouteScopesMiddleware(routeScopes map[string][]string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var declared []string
+			if route := mux.CurrentRoute(r); route != nil {
+				declared = routeScopes[route.GetName()]
+			}
+			if err := CheckDeclaredScopes(r.Context(), declared); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
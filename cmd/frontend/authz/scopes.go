@@ -1,13 +1,137 @@
 package authz
 
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
 const (
 	// Access token scopes.
-	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
-	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeUserAll        = "user:all"        // Full control of all resources accessible to the user account.
+	ScopeSiteAdminSudo  = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeSearchRead     = "search:read"     // Ability to run searches.
+	ScopeRepoRead       = "repo:read"       // Ability to read repositories and their contents.
+	ScopeCampaignsWrite = "campaigns:write" // Ability to read, create and update campaigns.
+	ScopeSettingsRead   = "settings:read"   // Ability to read settings.
+	ScopeSiteAdminSCIM  = "site-admin:scim" // Ability to provision users and organizations with the SCIM API.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
 	ScopeSiteAdminSudo,
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCampaignsWrite,
+	ScopeSettingsRead,
+	ScopeSiteAdminSCIM,
+}
+
+// UserScopes is a list of the access token scopes that let a token act as its own user (i.e., all
+// scopes except "site-admin:sudo" and "site-admin:scim"). Tokens with the "site-admin:scim" scope
+// are only accepted by the SCIM API.
+var UserScopes = []string{
+	ScopeUserAll,
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCampaignsWrite,
+	ScopeSettingsRead,
+}
+
+// TokenScopes describes what the access token used to authenticate a request may do.
+type TokenScopes struct {
+	Scopes []string
+	// Repos, if non-empty, is the list of the only repositories the token may access.
+	Repos []api.RepoName
+}
+
+// has reports whether the token grants scope. The "site-admin:sudo" scope grants every scope, and
+// the "user:all" scope grants every scope except the "site-admin:*" scopes.
+func (t *TokenScopes) has(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || s == ScopeSiteAdminSudo || (s == ScopeUserAll && !strings.HasPrefix(scope, "site-admin:")) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRepo reports whether the token may access the repository.
+func (t *TokenScopes) AllowsRepo(name api.RepoName) bool {
+	if len(t.Repos) == 0 {
+		return true
+	}
+	for _, r := range t.Repos {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenScopesKey struct{}
+
+// WithTokenScopes returns a context recording that the request was authenticated with an access
+// token granting the given scopes.
+func WithTokenScopes(ctx context.Context, t *TokenScopes) context.Context {
+	return context.WithValue(ctx, tokenScopesKey{}, t)
+}
+
+// TokenScopesFromContext returns the scopes of the access token used to authenticate the request,
+// or nil if the request was not authenticated with an access token.
+func TokenScopesFromContext(ctx context.Context) *TokenScopes {
+	t, _ := ctx.Value(tokenScopesKey{}).(*TokenScopes)
+	return t
+}
+
+// ErrScopeRequired is returned when the access token used to authenticate a request does not grant
+// a scope needed to perform an operation.
+type ErrScopeRequired struct {
+	Scope string
+}
+
+func (e *ErrScopeRequired) Error() string {
+	return fmt.Sprintf("access token is missing required scope %q", e.Scope)
+}
+
+// CheckScope returns an error if the request was authenticated with an access token that does not
+// grant the scope. Requests not authenticated with an access token (e.g., with a session cookie)
+// are not restricted.
+func CheckScope(ctx context.Context, scope string) error {
+	t := TokenScopesFromContext(ctx)
+	if t == nil || t.has(scope) {
+		return nil
+	}
+	return &ErrScopeRequired{Scope: scope}
+}
+
+// CheckDeclaredScopes returns an error if the request was authenticated with an access token that
+// grants none of the scopes that an operation declares it accepts. Operations are denied by default:
+// the "user:all" scope is always accepted, and it is the only one accepted by operations that
+// declare no scopes. Requests not authenticated with an access token are not restricted.
+func CheckDeclaredScopes(ctx context.Context, declared []string) error {
+	t := TokenScopesFromContext(ctx)
+	if t == nil {
+		return nil
+	}
+	for _, scope := range declared {
+		if t.has(scope) {
+			return nil
+		}
+	}
+	return CheckScope(ctx, ScopeUserAll)
+}
+
+// CheckTokenScope is like CheckScope, except that it also returns an error if the request was not
+// authenticated with an access token. It is used for APIs that are only meant to be used by other
+// services (such as the SCIM API).
+func CheckTokenScope(ctx context.Context, scope string) error {
+	t := TokenScopesFromContext(ctx)
+	if t == nil || !t.has(scope) {
+		return &ErrScopeRequired{Scope: scope}
+	}
+	return nil
 }
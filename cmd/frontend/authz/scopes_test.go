@@ -0,0 +1,94 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestCheckScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes *TokenScopes
+		scope  string
+		want   bool
+	}{
+		{name: "no token", scopes: nil, scope: ScopeUserAll, want: true},
+		{name: "same scope", scopes: &TokenScopes{Scopes: []string{ScopeSearchRead}}, scope: ScopeSearchRead, want: true},
+		{name: "other scope", scopes: &TokenScopes{Scopes: []string{ScopeSearchRead}}, scope: ScopeRepoRead, want: false},
+		{name: "narrow scope", scopes: &TokenScopes{Scopes: []string{ScopeSearchRead}}, scope: ScopeUserAll, want: false},
+		{name: "user:all", scopes: &TokenScopes{Scopes: []string{ScopeUserAll}}, scope: ScopeCampaignsWrite, want: true},
+		{name: "user:all is not sudo", scopes: &TokenScopes{Scopes: []string{ScopeUserAll}}, scope: ScopeSiteAdminSudo, want: false},
+		{name: "sudo", scopes: &TokenScopes{Scopes: []string{ScopeSiteAdminSudo}}, scope: ScopeSettingsRead, want: true},
+		{name: "user:all is not scim", scopes: &TokenScopes{Scopes: []string{ScopeUserAll}}, scope: ScopeSiteAdminSCIM, want: false},
+		{name: "scim is not user:all", scopes: &TokenScopes{Scopes: []string{ScopeSiteAdminSCIM}}, scope: ScopeUserAll, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.scopes != nil {
+				ctx = WithTokenScopes(ctx, test.scopes)
+			}
+			err := CheckScope(ctx, test.scope)
+			if got := err == nil; got != test.want {
+				t.Errorf("got allowed %v (err %v), want %v", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckTokenScope(t *testing.T) {
+	if err := CheckTokenScope(context.Background(), ScopeSiteAdminSCIM); err == nil {
+		t.Error("want error for request not authenticated with an access token")
+	}
+	ctx := WithTokenScopes(context.Background(), &TokenScopes{Scopes: []string{ScopeSiteAdminSCIM}})
+	if err := CheckTokenScope(ctx, ScopeSiteAdminSCIM); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+	if err := CheckTokenScope(ctx, ScopeSearchRead); err == nil {
+		t.Error("want error for access token without the scope")
+	}
+}
+
+func TestTokenScopes_AllowsRepo(t *testing.T) {
+	unrestricted := &TokenScopes{Scopes: []string{ScopeRepoRead}}
+	if !unrestricted.AllowsRepo("github.com/foo/bar") {
+		t.Error("want token without repository restrictions to allow all repositories")
+	}
+
+	restricted := &TokenScopes{Scopes: []string{ScopeRepoRead}, Repos: []api.RepoName{"github.com/foo/bar"}}
+	if !restricted.AllowsRepo("github.com/foo/bar") {
+		t.Error("want token to allow github.com/foo/bar")
+	}
+	if restricted.AllowsRepo("github.com/foo/baz") {
+		t.Error("want token to not allow github.com/foo/baz")
+	}
+}
+
+func TestCheckDeclaredScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   *TokenScopes
+		declared []string
+		want     bool
+	}{
+		{name: "no token", scopes: nil, declared: nil, want: true},
+		{name: "declared scope", scopes: &TokenScopes{Scopes: []string{ScopeSearchRead}}, declared: []string{ScopeRepoRead, ScopeSearchRead}, want: true},
+		{name: "undeclared scope", scopes: &TokenScopes{Scopes: []string{ScopeSearchRead}}, declared: []string{ScopeRepoRead}, want: false},
+		{name: "no declared scopes", scopes: &TokenScopes{Scopes: []string{ScopeSearchRead}}, declared: nil, want: false},
+		{name: "user:all", scopes: &TokenScopes{Scopes: []string{ScopeUserAll}}, declared: nil, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.scopes != nil {
+				ctx = WithTokenScopes(ctx, test.scopes)
+			}
+			err := CheckDeclaredScopes(ctx, test.declared)
+			if got := err == nil; got != test.want {
+				t.Errorf("got allowed %v (err %v), want %v", got, err, test.want)
+			}
+		})
+	}
+}
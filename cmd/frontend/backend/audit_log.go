@@ -0,0 +1,300 @@
+package backend
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+)
+
+var auditLogSinkSpec = env.Get("AUDIT_LOG_SINK", "", `Where to export audit log entries to, in addition to the database: "file:/path/to/audit.jsonl" (append JSON lines to a file), "syslog" (the local syslog daemon) or "syslog://host:port" and "syslog+tcp://host:port" (a remote syslog daemon).`)
+
+// Audit log actions.
+const (
+	AuditActionExternalServiceAdd           = "externalService.add"
+	AuditActionExternalServiceUpdate        = "externalService.update"
+	AuditActionExternalServiceDelete        = "externalService.delete"
+	AuditActionSiteConfigUpdate             = "site.configuration.update"
+	AuditActionUserSiteAdminSet             = "user.siteAdmin.set"
+	AuditActionAccessTokenCreate            = "accessToken.create"
+	AuditActionAccessTokenDelete            = "accessToken.delete"
+	AuditActionRepoPermissionsSet           = "repository.permissions.set"
+	AuditActionUserTwoFactorRemove          = "user.twoFactor.remove"
+	AuditActionExtensionSigningPublicKeySet = "extension.signingPublicKey.set"
+	AuditActionExtensionPinnedReleaseSet    = "extension.pinnedRelease.set"
+	AuditActionExtensionMirrorImport        = "extension.mirror.import"
+)
+
+// AuditEvent describes a security-relevant administrative action to record in the audit log.
+type AuditEvent struct {
+	Action     string // one of the AuditAction* constants
+	TargetType string // the GraphQL type name of the target (e.g., "ExternalService")
+	TargetID   string
+	Before     *string // the state of the target before the action (nil if not applicable)
+	After      *string // the state of the target after the action (nil if not applicable)
+}
+
+// PerformAuditedAction calls action and records the event that it returns in the audit log,
+// attributing it to the actor and client IP address of the request in ctx. The database changes
+// made by the action (with stores that use dbconn.FromContext) and the audit log entry are written
+// in one transaction, so the action fails if it can't be recorded. Nothing is recorded if the action
+// returns a nil event.
+//
+// The entry is exported to the configured audit log sink (if any) once the transaction is
+// committed. Failures to export it are logged instead of being returned to the caller.
+func PerformAuditedAction(ctx context.Context, action func(ctx context.Context) (*AuditEvent, error)) error {
+	e, err := db.AuditLog.Record(ctx, func(ctx context.Context) (*db.AuditLogEntry, error) {
+		ev, err := action(ctx)
+		if err != nil || ev == nil {
+			return nil, err
+		}
+		return &db.AuditLogEntry{
+			ActorUserID: actor.FromContext(ctx).UID,
+			ActorIP:     ClientIPFromContext(ctx),
+			Action:      ev.Action,
+			TargetType:  ev.TargetType,
+			TargetID:    ev.TargetID,
+			Before:      ev.Before,
+			After:       ev.After,
+		}, nil
+	})
+	if err != nil || e == nil {
+		return err
+	}
+	if err := exportAuditLogEntry(e); err != nil {
+		log15.Error("Failed to export audit log entry.", "id", e.ID, "sink", auditLogSinkSpec, "err", err)
+	}
+	return nil
+}
+
+// RedactSecrets returns the JSONC configuration with the values of all properties that look like
+// secrets (such as "token" and "password") replaced, for recording it in the audit log. Comments
+// and formatting are not preserved. It returns nil if config is nil.
+func RedactSecrets(config *string) *string {
+	if config == nil {
+		return nil
+	}
+	redacted := "REDACTED"
+	var v interface{}
+	if err := jsonc.Unmarshal(*config, &v); err != nil {
+		// Don't record configuration that we can't redact.
+		return &redacted
+	}
+	b, err := json.MarshalIndent(redactSecrets(v), "", "  ")
+	if err != nil {
+		return &redacted
+	}
+	s := string(b)
+	return &s
+}
+
+// secretPropertySubstrings are the (lowercase) substrings of the names of configuration properties
+// whose values are redacted by RedactSecrets.
+var secretPropertySubstrings = []string{"token", "password", "secret", "privatekey", "credential", "apikey"}
+
+func redactSecrets(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			lk := strings.ToLower(k)
+			isSecret := false
+			for _, s := range secretPropertySubstrings {
+				if strings.Contains(lk, s) {
+					isSecret = true
+					break
+				}
+			}
+			if isSecret {
+				v[k] = "REDACTED"
+			} else {
+				v[k] = redactSecrets(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = redactSecrets(val)
+		}
+	}
+	return v
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a context recording the IP address of the client that made the request.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the IP address of the client that made the request, or "" if
+// unknown.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+var trustedProxies = parseTrustedProxies(env.Get("TRUSTED_PROXIES", "", "Comma-separated IP addresses and CIDR ranges (e.g. 10.0.0.0/8) of the reverse proxies in front of Sourcegraph. The client IP address recorded in the audit log is only taken from the X-Forwarded-For header of requests from these proxies."))
+
+// parseTrustedProxies parses the comma-separated list of IP addresses and CIDR ranges in spec.
+// Invalid entries are logged and ignored, so that they aren't trusted.
+func parseTrustedProxies(spec string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil {
+				bits := 8 * len(ip.To16())
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 32
+				}
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			log15.Error("Ignoring invalid entry in TRUSTED_PROXIES.", "entry", s, "err", err)
+			continue
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}
+
+// ClientIP returns the IP address of the client that made the request. It is the address of the
+// remote end of the connection, unless that is a trusted proxy (see TRUSTED_PROXIES). Then it is
+// the right-most address in the X-Forwarded-For header that isn't a trusted proxy, because each
+// proxy appends the address it received the request from and the addresses to the left of it may
+// have been forged by the client.
+func ClientIP(r *http.Request) string {
+	return clientIP(r, trustedProxies)
+}
+
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !isTrustedProxy(ip, trusted) {
+		return ip
+	}
+
+	var hops []string
+	for _, h := range r.Header["X-Forwarded-For"] {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !isTrustedProxy(hop, trusted) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrustedProxy(addr string, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditLogExportEntry is the JSON representation of an audit log entry written to the sink.
+type auditLogExportEntry struct {
+	ID          int64     `json:"id"`
+	Timestamp   time.Time `json:"timestamp"`
+	ActorUserID int32     `json:"actorUserID,omitempty"`
+	ActorIP     string    `json:"actorIP,omitempty"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"targetType"`
+	TargetID    string    `json:"targetID,omitempty"`
+	Before      *string   `json:"before,omitempty"`
+	After       *string   `json:"after,omitempty"`
+	PrevHash    string    `json:"prevHash"`
+	Hash        string    `json:"hash"`
+}
+
+var (
+	auditLogSinkOnce sync.Once
+	auditLogSinkMu   sync.Mutex
+	auditLogSink     io.Writer
+	auditLogSinkErr  error
+)
+
+// openAuditLogSink opens the audit log sink described by spec (see AUDIT_LOG_SINK). It returns a nil
+// writer if spec is empty.
+func openAuditLogSink(spec string) (io.Writer, error) {
+	switch {
+	case spec == "":
+		return nil, nil
+	case strings.HasPrefix(spec, "file:"):
+		return os.OpenFile(strings.TrimPrefix(spec, "file:"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	case spec == "syslog":
+		return syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, "sourcegraph-audit")
+	case strings.HasPrefix(spec, "syslog://"):
+		return syslog.Dial("udp", strings.TrimPrefix(spec, "syslog://"), syslog.LOG_NOTICE|syslog.LOG_AUTH, "sourcegraph-audit")
+	case strings.HasPrefix(spec, "syslog+tcp://"):
+		return syslog.Dial("tcp", strings.TrimPrefix(spec, "syslog+tcp://"), syslog.LOG_NOTICE|syslog.LOG_AUTH, "sourcegraph-audit")
+	}
+	return nil, fmt.Errorf("invalid AUDIT_LOG_SINK %q", spec)
+}
+
+// exportAuditLogEntry writes the entry as a line of JSON to the audit log sink.
+func exportAuditLogEntry(e *db.AuditLogEntry) error {
+	auditLogSinkOnce.Do(func() {
+		auditLogSink, auditLogSinkErr = openAuditLogSink(auditLogSinkSpec)
+	})
+	if auditLogSinkErr != nil || auditLogSink == nil {
+		return auditLogSinkErr
+	}
+	return writeAuditLogEntry(auditLogSink, e)
+}
+
+func writeAuditLogEntry(w io.Writer, e *db.AuditLogEntry) error {
+	b, err := json.Marshal(auditLogExportEntry{
+		ID:          e.ID,
+		Timestamp:   e.Timestamp,
+		ActorUserID: e.ActorUserID,
+		ActorIP:     e.ActorIP,
+		Action:      e.Action,
+		TargetType:  e.TargetType,
+		TargetID:    e.TargetID,
+		Before:      e.Before,
+		After:       e.After,
+		PrevHash:    hex.EncodeToString(e.PrevHash),
+		Hash:        hex.EncodeToString(e.Hash),
+	})
+	if err != nil {
+		return err
+	}
+	auditLogSinkMu.Lock()
+	defer auditLogSinkMu.Unlock()
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+func TestPerformAuditedAction(t *testing.T) {
+	ctx := WithClientIP(testContext(), "10.0.0.1")
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	var got *db.AuditLogEntry
+	db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+		got = e
+		return nil
+	}
+	after := "true"
+	if err := PerformAuditedAction(ctx, func(context.Context) (*AuditEvent, error) {
+		return &AuditEvent{Action: AuditActionUserSiteAdminSet, TargetType: "User", TargetID: "2", After: &after}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Fatal("audit log entry was not inserted")
+	}
+	if got.ActorUserID != 1 || got.ActorIP != "10.0.0.1" || got.Action != AuditActionUserSiteAdminSet || got.TargetType != "User" || got.TargetID != "2" || got.Before != nil || *got.After != "true" {
+		t.Errorf("got unexpected entry %+v", got)
+	}
+
+	got = nil
+	wantErr := errors.New("x")
+	if err := PerformAuditedAction(ctx, func(context.Context) (*AuditEvent, error) { return nil, wantErr }); err != wantErr {
+		t.Errorf("got error %v, want %v", err, wantErr)
+	}
+	if err := PerformAuditedAction(ctx, func(context.Context) (*AuditEvent, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("got entry %+v for failed or unrecorded actions, want none", got)
+	}
+}
+
+// 🚨 SECURITY: This tests that secrets in configuration are not recorded in the audit log.
+func TestRedactSecrets(t *testing.T) {
+	if got := RedactSecrets(nil); got != nil {
+		t.Errorf("got %q, want nil", *got)
+	}
+
+	config := `{
+  // comment
+  "url": "https://github.com",
+  "token": "abc",
+  "auth.providers": [{"type": "openidconnect", "clientSecret": "def"}],
+  "nested": {"Password": "ghi", "username": "u"},
+}`
+	got := RedactSecrets(&config)
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(*got), &v); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"url":            "https://github.com",
+		"token":          "REDACTED",
+		"auth.providers": []interface{}{map[string]interface{}{"type": "openidconnect", "clientSecret": "REDACTED"}},
+		"nested":         map[string]interface{}{"Password": "REDACTED", "username": "u"},
+	}
+	gotJSON, _ := json.Marshal(v)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("got %s, want %s", gotJSON, wantJSON)
+	}
+
+	invalid := `{"token": `
+	if got := RedactSecrets(&invalid); *got != "REDACTED" {
+		t.Errorf("got %q for invalid config, want fully redacted", *got)
+	}
+}
+
+// 🚨 SECURITY: This tests that clients can't forge the IP address recorded in the audit log.
+func TestClientIP(t *testing.T) {
+	trusted := parseTrustedProxies("10.0.0.0/24, 192.168.0.1, invalid")
+	if len(trusted) != 2 {
+		t.Fatalf("got %d trusted proxies, want 2", len(trusted))
+	}
+
+	tests := map[string]struct {
+		remoteAddr    string
+		xForwardedFor []string
+		want          string
+	}{
+		"remote addr":                      {remoteAddr: "10.0.0.1:1234", want: "10.0.0.1"},
+		"ipv6":                             {remoteAddr: "[::1]:1234", want: "::1"},
+		"x-forwarded-for from untrusted":   {remoteAddr: "172.16.0.1:1234", xForwardedFor: []string{"1.2.3.4"}, want: "172.16.0.1"},
+		"x-forwarded-for from trusted":     {remoteAddr: "10.0.0.1:1234", xForwardedFor: []string{"1.2.3.4"}, want: "1.2.3.4"},
+		"forged hop":                       {remoteAddr: "10.0.0.1:1234", xForwardedFor: []string{"5.6.7.8, 1.2.3.4"}, want: "1.2.3.4"},
+		"chain of trusted proxies":         {remoteAddr: "10.0.0.1:1234", xForwardedFor: []string{"5.6.7.8, 1.2.3.4, 192.168.0.1, 10.0.0.2"}, want: "1.2.3.4"},
+		"multiple headers":                 {remoteAddr: "10.0.0.1:1234", xForwardedFor: []string{"5.6.7.8", "1.2.3.4"}, want: "1.2.3.4"},
+		"only trusted hops":                {remoteAddr: "10.0.0.1:1234", xForwardedFor: []string{"10.0.0.3, 10.0.0.2"}, want: "10.0.0.3"},
+		"trusted proxy without the header": {remoteAddr: "10.0.0.1:1234", want: "10.0.0.1"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: test.remoteAddr, Header: http.Header{}}
+			for _, h := range test.xForwardedFor {
+				r.Header.Add("X-Forwarded-For", h)
+			}
+			if got := clientIP(r, trusted); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWriteAuditLogEntry(t *testing.T) {
+	var buf bytes.Buffer
+	e := &db.AuditLogEntry{
+		ID:         1,
+		Timestamp:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Action:     AuditActionSiteConfigUpdate,
+		TargetType: "Site",
+		PrevHash:   []byte{},
+		Hash:       []byte{0xab, 0xcd},
+	}
+	for i := 0; i < 2; i++ {
+		if err := writeAuditLogEntry(&buf, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	line := `{"id":1,"timestamp":"2020-01-02T03:04:05Z","action":"site.configuration.update","targetType":"Site","prevHash":"","hash":"abcd"}` + "\n"
+	if got, want := buf.String(), line+line; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
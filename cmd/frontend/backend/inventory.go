@@ -10,6 +10,7 @@ import (
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/inventory"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/env"
@@ -22,6 +23,8 @@ import (
 // filenames. Enabled by default.
 var useEnhancedLanguageDetection, _ = strconv.ParseBool(env.Get("USE_ENHANCED_LANGUAGE_DETECTION", "true", "Enable more accurate but slower language detection that uses file contents"))
 
+// inventoryCache caches inventories of trees in front of db.InventoryObjects,
+// which stores them persistently, and of blobs, which are only cached.
 var inventoryCache = rcache.New(fmt.Sprintf("inv:v2:enhanced_%v", useEnhancedLanguageDetection))
 
 // InventoryContext returns the inventory context for computing the inventory for the repository at
@@ -47,22 +50,37 @@ func InventoryContext(repo gitserver.Repo, commitID api.CommitID, forceEnhancedL
 		NewFileReader: func(ctx context.Context, path string) (io.ReadCloser, error) {
 			return git.NewFileReader(ctx, repo, commitID, path)
 		},
-		CacheGet: func(e os.FileInfo) (inventory.Inventory, bool) {
+		CacheGet: func(ctx context.Context, e os.FileInfo) (inventory.Inventory, bool) {
 			cacheKey := cacheKey(e)
 			if cacheKey == "" {
 				return inventory.Inventory{}, false // not cacheable
 			}
-			if b, ok := inventoryCache.Get(cacheKey); ok {
-				var inv inventory.Inventory
-				if err := json.Unmarshal(b, &inv); err != nil {
-					log15.Warn("Failed to unmarshal cached JSON inventory.", "repo", repo.Name, "commitID", commitID, "path", e.Name(), "err", err)
+			b, ok := inventoryCache.Get(cacheKey)
+			if !ok {
+				// Fall back to the inventories of trees stored in the
+				// database, which are only deleted when unused.
+				if !e.Mode().IsDir() {
 					return inventory.Inventory{}, false
 				}
-				return inv, true
+				var err error
+				b, ok, err = db.InventoryObjects.Get(ctx, cacheKey, useEnhancedLanguageDetection)
+				if err != nil {
+					log15.Warn("Failed to get stored inventory.", "repo", repo.Name, "commitID", commitID, "path", e.Name(), "err", err)
+					return inventory.Inventory{}, false
+				}
+				if !ok {
+					return inventory.Inventory{}, false
+				}
+				inventoryCache.Set(cacheKey, b)
 			}
-			return inventory.Inventory{}, false
+			var inv inventory.Inventory
+			if err := json.Unmarshal(b, &inv); err != nil {
+				log15.Warn("Failed to unmarshal cached JSON inventory.", "repo", repo.Name, "commitID", commitID, "path", e.Name(), "err", err)
+				return inventory.Inventory{}, false
+			}
+			return inv, true
 		},
-		CacheSet: func(e os.FileInfo, inv inventory.Inventory) {
+		CacheSet: func(ctx context.Context, e os.FileInfo, inv inventory.Inventory) {
 			cacheKey := cacheKey(e)
 			if cacheKey == "" {
 				return // not cacheable
@@ -73,6 +91,14 @@ func InventoryContext(repo gitserver.Repo, commitID api.CommitID, forceEnhancedL
 				return
 			}
 			inventoryCache.Set(cacheKey, b)
+			// There are far more blobs than trees, and a tree's inventory
+			// includes its blobs, so only the inventories of trees are stored.
+			if !e.Mode().IsDir() {
+				return
+			}
+			if err := db.InventoryObjects.Set(ctx, cacheKey, useEnhancedLanguageDetection, b); err != nil {
+				log15.Warn("Failed to store inventory.", "repo", repo.Name, "commitID", commitID, "path", e.Name(), "err", err)
+			}
 		},
 	}
 
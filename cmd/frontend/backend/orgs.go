@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 )
@@ -14,11 +15,21 @@ var ErrNotAuthenticated = errors.New("not authenticated")
 // member of the organization with the specified ID.
 //
 // It is used when an action on a user can be performed by site admins and the organization's
-// members, but nobody else.
+// members, but nobody else. Requests authenticated with an access token require the "user:all"
+// scope.
 func CheckOrgAccess(ctx context.Context, orgID int32) error {
+	return CheckOrgAccessWithScope(ctx, orgID, authz.ScopeUserAll)
+}
+
+// CheckOrgAccessWithScope is like CheckOrgAccess, but requests authenticated with an access token
+// require scope instead of "user:all".
+func CheckOrgAccessWithScope(ctx context.Context, orgID int32, scope string) error {
 	if hasAuthzBypass(ctx) {
 		return nil
 	}
+	if err := authz.CheckScope(ctx, scope); err != nil {
+		return err
+	}
 	currentUser, err := CurrentUser(ctx)
 	if err != nil {
 		return err
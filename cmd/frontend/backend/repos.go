@@ -150,8 +150,13 @@ func (s *repos) GetInventory(ctx context.Context, repo *types.Repo, commitID api
 	if Mocks.Repos.GetInventory != nil {
 		return Mocks.Repos.GetInventory(ctx, repo, commitID)
 	}
+	return s.GetInventoryForPath(ctx, repo, commitID, "", forceEnhancedLanguageDetection)
+}
 
-	ctx, done := trace(ctx, "Repos", "GetInventory", map[string]interface{}{"repo": repo.Name, "commitID": commitID}, &err)
+// GetInventoryForPath returns the inventory of the file or directory at path in the repository at
+// the given commit. An empty path is the root of the repository.
+func (s *repos) GetInventoryForPath(ctx context.Context, repo *types.Repo, commitID api.CommitID, path string, forceEnhancedLanguageDetection bool) (res *inventory.Inventory, err error) {
+	ctx, done := trace(ctx, "Repos", "GetInventoryForPath", map[string]interface{}{"repo": repo.Name, "commitID": commitID, "path": path}, &err)
 	defer done()
 
 	// Cap GetInventory operation to some reasonable time.
@@ -168,7 +173,7 @@ func (s *repos) GetInventory(ctx context.Context, repo *types.Repo, commitID api
 		return nil, err
 	}
 
-	root, err := git.Stat(ctx, *cachedRepo, commitID, "")
+	root, err := git.Stat(ctx, *cachedRepo, commitID, path)
 	if err != nil {
 		return nil, err
 	}
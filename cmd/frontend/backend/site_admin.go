@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
@@ -13,11 +14,21 @@ import (
 
 var ErrMustBeSiteAdmin = errors.New("must be site admin")
 
-// CheckCurrentUserIsSiteAdmin returns an error if the current user is NOT a site admin.
+// CheckCurrentUserIsSiteAdmin returns an error if the current user is NOT a site admin, or if the
+// request was authenticated with an access token lacking the "user:all" scope.
 func CheckCurrentUserIsSiteAdmin(ctx context.Context) error {
+	return CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeUserAll)
+}
+
+// CheckCurrentUserIsSiteAdminWithScope returns an error if the current user is NOT a site admin, or
+// if the request was authenticated with an access token that does not grant scope.
+func CheckCurrentUserIsSiteAdminWithScope(ctx context.Context, scope string) error {
 	if hasAuthzBypass(ctx) {
 		return nil
 	}
+	if err := authz.CheckScope(ctx, scope); err != nil {
+		return err
+	}
 	user, err := CurrentUser(ctx)
 	if err != nil {
 		return err
@@ -62,18 +73,29 @@ func (e *InsufficientAuthorizationError) Error() string { return e.Message }
 // site admin NOR (2) the user specified by subjectUserID.
 //
 // It is used when an action on a user can be performed by site admins and the
-// user themselves, but nobody else.
+// user themselves, but nobody else. Requests authenticated with an access
+// token require the "user:all" scope.
 //
 // Returns an error containing the name of the given user.
 func CheckSiteAdminOrSameUser(ctx context.Context, subjectUserID int32) error {
+	return CheckSiteAdminOrSameUserWithScope(ctx, subjectUserID, authz.ScopeUserAll)
+}
+
+// CheckSiteAdminOrSameUserWithScope is like CheckSiteAdminOrSameUser, but
+// requests authenticated with an access token require scope instead of
+// "user:all".
+func CheckSiteAdminOrSameUserWithScope(ctx context.Context, subjectUserID int32, scope string) error {
 	if hasAuthzBypass(ctx) {
 		return nil
 	}
+	if err := authz.CheckScope(ctx, scope); err != nil {
+		return err
+	}
 	actor := actor.FromContext(ctx)
 	if actor.IsAuthenticated() && actor.UID == subjectUserID {
 		return nil
 	}
-	isSiteAdminErr := CheckCurrentUserIsSiteAdmin(ctx)
+	isSiteAdminErr := CheckCurrentUserIsSiteAdminWithScope(ctx, scope)
 	if isSiteAdminErr == nil {
 		return nil
 	}
@@ -24,6 +24,8 @@ type AccessToken struct {
 	CreatorUserID int32
 	CreatedAt     time.Time
 	LastUsedAt    *time.Time
+	ExpiresAt     *time.Time // if set, the access token is not valid after this time
+	Repos         []string   // if non-empty, the only repositories the access token may access
 }
 
 // ErrAccessTokenNotFound occurs when a database operation expects a specific access token to exist
@@ -46,11 +48,14 @@ type accessTokens struct{}
 // space; also bcrypt is slow and would add noticeable latency to each request that supplied a
 // token.
 //
+// If expiresAt is non-nil, the token is not valid after that time. If repos is non-empty, the
+// token may only access the repositories with those names.
+//
 // 🚨 SECURITY: The caller must ensure that the actor is permitted to create tokens for the
 // specified user (i.e., that the actor is either the user or a site admin).
-func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error) {
+func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time, repos []string) (id int64, token string, err error) {
 	if Mocks.AccessTokens.Create != nil {
-		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID)
+		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID, expiresAt, repos)
 	}
 
 	var b [20]byte
@@ -64,8 +69,11 @@ func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes [
 		// GraphQL API wouldn't let you do so anyway.
 		return 0, "", errors.New("access tokens without scopes are not supported")
 	}
+	if repos == nil {
+		repos = []string{}
+	}
 
-	if err := dbconn.Global.QueryRowContext(ctx,
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx,
 		// Include users table query (with "FOR UPDATE") to ensure that subject/creator users have
 		// not been deleted. If they were deleted, the query will return an error.
 		`
@@ -76,60 +84,64 @@ creator_user AS (
   SELECT id FROM users WHERE id=$5 AND deleted_at IS NULL FOR UPDATE
 ),
 insert_values AS (
-  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id
+  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id, $6::timestamp with time zone AS expires_at, $7::text[] AS repos
   FROM subject_user, creator_user
 )
-INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id) SELECT * FROM insert_values RETURNING id
+INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id, expires_at, repos) SELECT * FROM insert_values RETURNING id
 `,
-		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID,
+		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID, expiresAt, pq.Array(repos),
 	).Scan(&id); err != nil {
 		return 0, "", err
 	}
 	return id, token, nil
 }
 
-// Lookup looks up the access token. If it's valid and contains the required scope, it returns the
-// subject's user ID. Otherwise ErrAccessTokenNotFound is returned.
+// Lookup looks up the access token. If it's valid and contains at least one of the required scopes,
+// it returns the access token. Otherwise ErrAccessTokenNotFound is returned.
 //
 // Calling Lookup also updates the access token's last-used-at date.
 //
-// 🚨 SECURITY: This returns a user ID if and only if the tokenHexEncoded corresponds to a valid,
-// non-deleted access token.
-func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScope string) (subjectUserID int32, err error) {
+// 🚨 SECURITY: This returns an access token if and only if the tokenHexEncoded corresponds to a
+// valid, non-deleted, non-expired access token. The caller is responsible for enforcing the
+// token's scopes and repository restrictions.
+func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScopes []string) (*AccessToken, error) {
 	if Mocks.AccessTokens.Lookup != nil {
-		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScope)
+		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScopes)
 	}
 
-	if requiredScope == "" {
-		return 0, errors.New("no scope provided in access token lookup")
+	if len(requiredScopes) == 0 {
+		return nil, errors.New("no scope provided in access token lookup")
 	}
 
 	token, err := hex.DecodeString(tokenHexEncoded)
 	if err != nil {
-		return 0, errors.Wrap(err, "AccessTokens.Lookup")
+		return nil, errors.Wrap(err, "AccessTokens.Lookup")
 	}
 
+	var t AccessToken
 	if err := dbconn.Global.QueryRowContext(ctx,
-		// Ensure that subject and creator users still exist.
+		// Ensure that subject and creator users still exist, and that the subject user is not
+		// deactivated.
 		`
 UPDATE access_tokens t SET last_used_at=now()
 WHERE t.id IN (
 	SELECT t2.id FROM access_tokens t2
-	JOIN users subject_user ON t2.subject_user_id=subject_user.id AND subject_user.deleted_at IS NULL
+	JOIN users subject_user ON t2.subject_user_id=subject_user.id AND subject_user.deleted_at IS NULL AND subject_user.deactivated_at IS NULL
 	JOIN users creator_user ON t2.creator_user_id=creator_user.id AND creator_user.deleted_at IS NULL
 	WHERE t2.value_sha256=$1 AND t2.deleted_at IS NULL AND
-	$2 = ANY (t2.scopes)
+	(t2.expires_at IS NULL OR t2.expires_at > now()) AND
+	t2.scopes && $2
 )
-RETURNING t.subject_user_id
+RETURNING t.id, t.subject_user_id, t.scopes, t.note, t.creator_user_id, t.created_at, t.last_used_at, t.expires_at, t.repos
 `,
-		toSHA256Bytes(token), requiredScope,
-	).Scan(&subjectUserID); err != nil {
+		toSHA256Bytes(token), pq.Array(requiredScopes),
+	).Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, &t.ExpiresAt, pq.Array(&t.Repos)); err != nil {
 		if err == sql.ErrNoRows {
-			return 0, ErrAccessTokenNotFound
+			return nil, ErrAccessTokenNotFound
 		}
-		return 0, err
+		return nil, err
 	}
-	return subjectUserID, nil
+	return &t, nil
 }
 
 // GetByID retrieves the access token (if any) given its ID.
@@ -182,7 +194,7 @@ func (s *accessTokens) List(ctx context.Context, opt AccessTokensListOptions) ([
 
 func (s *accessTokens) list(ctx context.Context, conds []*sqlf.Query, limitOffset *LimitOffset) ([]*AccessToken, error) {
 	q := sqlf.Sprintf(`
-SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at FROM access_tokens
+SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at, expires_at, repos FROM access_tokens
 WHERE (%s)
 ORDER BY now() - created_at < interval '5 minutes' DESC, -- show recently created tokens first
 last_used_at DESC NULLS FIRST, -- ensure newly created tokens show first
@@ -201,7 +213,7 @@ created_at DESC
 	var results []*AccessToken
 	for rows.Next() {
 		var t AccessToken
-		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, &t.ExpiresAt, pq.Array(&t.Repos)); err != nil {
 			return nil, err
 		}
 		results = append(results, &t)
@@ -245,7 +257,7 @@ func (s *accessTokens) delete(ctx context.Context, cond *sqlf.Query) error {
 	conds := []*sqlf.Query{cond, sqlf.Sprintf("deleted_at IS NULL")}
 	q := sqlf.Sprintf("UPDATE access_tokens SET deleted_at=now() WHERE (%s)", sqlf.Join(conds, ") AND ("))
 
-	res, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return err
 	}
@@ -265,8 +277,8 @@ func toSHA256Bytes(input []byte) []byte {
 }
 
 type MockAccessTokens struct {
-	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
+	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time, repos []string) (id int64, token string, err error)
 	DeleteByID func(id int64, subjectUserID int32) error
-	Lookup     func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
+	Lookup     func(tokenHexEncoded string, requiredScopes []string) (*AccessToken, error)
 	GetByID    func(id int64) (*AccessToken, error)
 }
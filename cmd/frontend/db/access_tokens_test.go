@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
 )
@@ -34,7 +35,7 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,12 +54,12 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Errorf("got %q, want %q", got.Note, want)
 	}
 
-	gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, "a")
+	gotToken, err := AccessTokens.Lookup(ctx, tv0, []string{"a"})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if want := subject.ID; gotSubjectUserID != want {
-		t.Errorf("got %v, want %v", gotSubjectUserID, want)
+	if want := subject.ID; gotToken.SubjectUserID != want {
+		t.Errorf("got %v, want %v", gotToken.SubjectUserID, want)
 	}
 
 	ts, err := AccessTokens.List(ctx, AccessTokensListOptions{SubjectUserID: subject.ID})
@@ -108,11 +109,11 @@ func TestAccessTokens_List(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -187,28 +188,33 @@ func TestAccessTokens_Lookup(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	for _, scope := range []string{"a", "b"} {
-		gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, scope)
+		gotToken, err := AccessTokens.Lookup(ctx, tv0, []string{scope})
 		if err != nil {
 			t.Fatal(err)
 		}
-		if want := subject.ID; gotSubjectUserID != want {
-			t.Errorf("got %v, want %v", gotSubjectUserID, want)
+		if want := subject.ID; gotToken.SubjectUserID != want {
+			t.Errorf("got %v, want %v", gotToken.SubjectUserID, want)
 		}
 	}
 
+	// Lookup with one of several scopes.
+	if _, err := AccessTokens.Lookup(ctx, tv0, []string{"x", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
 	// Lookup with a nonexistent scope and ensure it fails.
-	if _, err := AccessTokens.Lookup(ctx, tv0, "x"); err == nil {
+	if _, err := AccessTokens.Lookup(ctx, tv0, []string{"x"}); err == nil {
 		t.Fatal(err)
 	}
 
-	// Lookup with an empty scope and ensure it fails.
-	if _, err := AccessTokens.Lookup(ctx, tv0, ""); err == nil {
+	// Lookup with no scopes and ensure it fails.
+	if _, err := AccessTokens.Lookup(ctx, tv0, nil); err == nil {
 		t.Fatal(err)
 	}
 
@@ -216,14 +222,59 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	if err := AccessTokens.DeleteByID(ctx, tid0, subject.ID); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+	if _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 		t.Fatal(err)
 	}
 
 	// Try to Lookup a token that was never created.
-	if _, err := AccessTokens.Lookup(ctx, "abcdefg" /* this token value was never created */, "a"); err == nil {
+	if _, err := AccessTokens.Lookup(ctx, "abcdefg" /* this token value was never created */, []string{"a"}); err == nil {
+		t.Fatal(err)
+	}
+}
+
+// 🚨 SECURITY: This tests that expired access tokens are rejected, and that a token's repository
+// restrictions are returned to the caller enforcing them.
+func TestAccessTokens_Lookup_restrictions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	subject, err := Users.Create(ctx, NewUser{
+		Email:                 "a@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
 		t.Fatal(err)
 	}
+
+	past := time.Now().Add(-time.Hour)
+	_, expired, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", subject.ID, &past, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AccessTokens.Lookup(ctx, expired, []string{"a"}); err != ErrAccessTokenNotFound {
+		t.Fatalf("Lookup: got err %v, want %v for expired token", err, ErrAccessTokenNotFound)
+	}
+
+	future := time.Now().Add(time.Hour)
+	_, valid, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n1", subject.ID, &future, []string{"github.com/foo/bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := AccessTokens.Lookup(ctx, valid, []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(future.Truncate(time.Microsecond)) {
+		t.Errorf("got expiry %v, want %v", got.ExpiresAt, future)
+	}
+	if want := []string{"github.com/foo/bar"}; !reflect.DeepEqual(got.Repos, want) {
+		t.Errorf("got repos %q, want %q", got.Repos, want)
+	}
 }
 
 // 🚨 SECURITY: This tests that deleting the subject or creator user of an access token invalidates
@@ -255,18 +306,18 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if err := Users.Delete(ctx, subject.ID); err != nil {
 			t.Fatal(err)
 		}
-		if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+		if _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 			t.Fatal("Lookup: want error looking up token for deleted subject user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID, nil, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted subject user")
 		}
 	})
@@ -291,18 +342,18 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if err := Users.Delete(ctx, creator.ID); err != nil {
 			t.Fatal(err)
 		}
-		if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+		if _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 			t.Fatal("Lookup: want error looking up token for deleted creator user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID, nil, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted creator user")
 		}
 	})
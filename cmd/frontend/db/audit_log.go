@@ -0,0 +1,278 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// AuditLogEntry describes a security-relevant administrative action, such as a site configuration
+// change.
+//
+// Each entry's Hash is the SHA-256 hash of the previous entry's Hash (PrevHash) and the entry's
+// own contents. Modifying or removing an entry (other than the most recent ones) therefore breaks
+// the chain, which is detected by (*auditLog).Verify.
+type AuditLogEntry struct {
+	ID          int64
+	Timestamp   time.Time
+	ActorUserID int32  // the user who performed the action, or 0 if unknown
+	ActorIP     string // the IP address of the client that performed the action
+	Action      string // e.g. "site.configuration.update"
+	TargetType  string // e.g. "ExternalService"
+	TargetID    string
+	Before      *string // the state of the target before the action (nil if not applicable)
+	After       *string // the state of the target after the action (nil if not applicable)
+	PrevHash    []byte
+	Hash        []byte
+}
+
+// computeHash returns the hash of the entry chained to prevHash.
+func (e *AuditLogEntry) computeHash(prevHash []byte) ([]byte, error) {
+	// The contents are hashed in a fixed, canonical JSON encoding.
+	contents, err := json.Marshal(struct {
+		Timestamp   string
+		ActorUserID int32
+		ActorIP     string
+		Action      string
+		TargetType  string
+		TargetID    string
+		Before      *string
+		After       *string
+	}{
+		Timestamp:   e.Timestamp.UTC().Format(time.RFC3339Nano),
+		ActorUserID: e.ActorUserID,
+		ActorIP:     e.ActorIP,
+		Action:      e.Action,
+		TargetType:  e.TargetType,
+		TargetID:    e.TargetID,
+		Before:      e.Before,
+		After:       e.After,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toSHA256Bytes(append(append([]byte{}, prevHash...), contents...)), nil
+}
+
+// auditLog provides access to the `audit_log` table.
+//
+// For a detailed overview of the schema, see schema.md.
+type auditLog struct{}
+
+// Insert appends an entry to the audit log. The entry's ID, Timestamp (if zero), PrevHash and Hash
+// fields are set.
+//
+// If ctx carries a transaction (see dbconn.Transaction), the entry is inserted in it, so that it is
+// recorded if and only if the transaction is committed.
+func (*auditLog) Insert(ctx context.Context, e *AuditLogEntry) error {
+	if Mocks.AuditLog.Insert != nil {
+		return Mocks.AuditLog.Insert(e)
+	}
+
+	if e.Action == "" || e.TargetType == "" {
+		return errors.New("audit log entry must have an action and target type")
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	// Postgres stores timestamps with microsecond precision, so truncate it to make the hash
+	// computed here match the one computed from the stored entry.
+	e.Timestamp = e.Timestamp.UTC().Truncate(time.Microsecond)
+
+	var actorUserID *int32
+	if e.ActorUserID != 0 {
+		actorUserID = &e.ActorUserID
+	}
+
+	return dbconn.Transaction(ctx, func(ctx context.Context) error {
+		tx := dbconn.FromContext(ctx)
+		// Serialize inserts so that each entry is chained to the one inserted before it.
+		if _, err := tx.ExecContext(ctx, "LOCK TABLE audit_log IN EXCLUSIVE MODE"); err != nil {
+			return err
+		}
+		prevHash := []byte{}
+		if err := tx.QueryRowContext(ctx, "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1").Scan(&prevHash); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		hash, err := e.computeHash(prevHash)
+		if err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx,
+			"INSERT INTO audit_log(timestamp, actor_user_id, actor_ip, action, target_type, target_id, before, after, prev_hash, hash) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id",
+			e.Timestamp, actorUserID, e.ActorIP, e.Action, e.TargetType, e.TargetID, e.Before, e.After, prevHash, hash,
+		).Scan(&e.ID); err != nil {
+			return errors.Wrap(err, "INSERT")
+		}
+		e.PrevHash = prevHash
+		e.Hash = hash
+		return nil
+	})
+}
+
+// Record calls action with a context that carries a transaction (see dbconn.Transaction) and
+// appends the entry that it returns to the audit log in the same transaction. The changes made by
+// the action are therefore only committed if they are recorded. Nothing is recorded if the action
+// returns a nil entry.
+func (s *auditLog) Record(ctx context.Context, action func(ctx context.Context) (*AuditLogEntry, error)) (*AuditLogEntry, error) {
+	if Mocks.AuditLog.Insert != nil {
+		// There's no database to run a transaction on.
+		e, err := action(ctx)
+		if err != nil || e == nil {
+			return nil, err
+		}
+		return e, s.Insert(ctx, e)
+	}
+
+	var entry *AuditLogEntry
+	err := dbconn.Transaction(ctx, func(ctx context.Context) error {
+		e, err := action(ctx)
+		if err != nil || e == nil {
+			return err
+		}
+		entry = e
+		return s.Insert(ctx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// AuditLogListOptions contains options for listing audit log entries.
+type AuditLogListOptions struct {
+	ActorUserID int32     // only include entries for actions performed by this user
+	Action      string    // only include entries with this action
+	TargetType  string    // only include entries with this target type
+	TargetID    string    // only include entries with this target ID
+	After       time.Time // only include entries recorded after this time
+	Before      time.Time // only include entries recorded before this time
+	*LimitOffset
+}
+
+func (o AuditLogListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.ActorUserID != 0 {
+		conds = append(conds, sqlf.Sprintf("actor_user_id=%d", o.ActorUserID))
+	}
+	if o.Action != "" {
+		conds = append(conds, sqlf.Sprintf("action=%s", o.Action))
+	}
+	if o.TargetType != "" {
+		conds = append(conds, sqlf.Sprintf("target_type=%s", o.TargetType))
+	}
+	if o.TargetID != "" {
+		conds = append(conds, sqlf.Sprintf("target_id=%s", o.TargetID))
+	}
+	if !o.After.IsZero() {
+		conds = append(conds, sqlf.Sprintf("timestamp > %s", o.After))
+	}
+	if !o.Before.IsZero() {
+		conds = append(conds, sqlf.Sprintf("timestamp < %s", o.Before))
+	}
+	return conds
+}
+
+// List lists audit log entries that match the options, most recent first.
+func (s *auditLog) List(ctx context.Context, opt AuditLogListOptions) ([]*AuditLogEntry, error) {
+	if Mocks.AuditLog.List != nil {
+		return Mocks.AuditLog.List(opt)
+	}
+
+	return s.getBySQL(ctx, sqlf.Sprintf("WHERE (%s) ORDER BY id DESC %s", sqlf.Join(opt.sqlConditions(), ") AND ("), opt.LimitOffset.SQL()))
+}
+
+// Count counts audit log entries that match the options.
+func (*auditLog) Count(ctx context.Context, opt AuditLogListOptions) (int, error) {
+	if Mocks.AuditLog.Count != nil {
+		return Mocks.AuditLog.Count(opt)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM audit_log WHERE (%s)", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Verify checks the hash chain of the audit log. It returns the first entry whose hash does not
+// match its contents or whose PrevHash does not match the hash of the entry before it, or nil if
+// the whole audit log is intact.
+//
+// Removal of the most recent entries can't be detected from the table alone. To detect it, compare
+// the hash of the most recent entry to one recorded elsewhere (e.g., in an exported audit log).
+func (s *auditLog) Verify(ctx context.Context) (*AuditLogEntry, error) {
+	if Mocks.AuditLog.Verify != nil {
+		return Mocks.AuditLog.Verify()
+	}
+
+	q := sqlf.Sprintf("SELECT id, timestamp, actor_user_id, actor_ip, action, target_type, target_id, before, after, prev_hash, hash FROM audit_log ORDER BY id ASC")
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prevHash := []byte{}
+	for rows.Next() {
+		e, err := scanAuditLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(e.PrevHash, prevHash) {
+			return e, nil
+		}
+		hash, err := e.computeHash(prevHash)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(e.Hash, hash) {
+			return e, nil
+		}
+		prevHash = e.Hash
+	}
+	return nil, rows.Err()
+}
+
+func (*auditLog) getBySQL(ctx context.Context, querySuffix *sqlf.Query) ([]*AuditLogEntry, error) {
+	q := sqlf.Sprintf("SELECT id, timestamp, actor_user_id, actor_ip, action, target_type, target_id, before, after, prev_hash, hash FROM audit_log %s", querySuffix)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		e, err := scanAuditLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func scanAuditLogEntry(rows *sql.Rows) (*AuditLogEntry, error) {
+	var e AuditLogEntry
+	var actorUserID sql.NullInt64
+	if err := rows.Scan(&e.ID, &e.Timestamp, &actorUserID, &e.ActorIP, &e.Action, &e.TargetType, &e.TargetID, &e.Before, &e.After, &e.PrevHash, &e.Hash); err != nil {
+		return nil, err
+	}
+	e.ActorUserID = int32(actorUserID.Int64)
+	return &e, nil
+}
+
+type MockAuditLog struct {
+	Insert func(e *AuditLogEntry) error
+	List   func(opt AuditLogListOptions) ([]*AuditLogEntry, error)
+	Count  func(opt AuditLogListOptions) (int, error)
+	Verify func() (*AuditLogEntry, error)
+}
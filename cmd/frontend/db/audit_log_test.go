@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestAuditLog_InsertList(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	before, after := "a", "b"
+	entries := []*AuditLogEntry{
+		{ActorUserID: 1, ActorIP: "10.0.0.1", Action: "site.configuration.update", TargetType: "Site", Before: &before, After: &after},
+		{ActorUserID: 2, Action: "user.siteAdmin.set", TargetType: "User", TargetID: "1"},
+		{ActorUserID: 1, Action: "user.siteAdmin.set", TargetType: "User", TargetID: "2"},
+	}
+	for _, e := range entries {
+		if err := AuditLog.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(entries[0].PrevHash) != 0 {
+		t.Errorf("got PrevHash %x for first entry, want empty", entries[0].PrevHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		if string(entries[i].PrevHash) != string(entries[i-1].Hash) {
+			t.Errorf("entry %d is not chained to entry %d", i, i-1)
+		}
+	}
+
+	if err := AuditLog.Insert(ctx, &AuditLogEntry{TargetType: "User"}); err == nil {
+		t.Error("got nil error for entry without action, want error")
+	}
+
+	tests := map[string]struct {
+		opt     AuditLogListOptions
+		wantIDs []int64
+	}{
+		"all":         {opt: AuditLogListOptions{}, wantIDs: []int64{entries[2].ID, entries[1].ID, entries[0].ID}},
+		"actor":       {opt: AuditLogListOptions{ActorUserID: 1}, wantIDs: []int64{entries[2].ID, entries[0].ID}},
+		"action":      {opt: AuditLogListOptions{Action: "user.siteAdmin.set"}, wantIDs: []int64{entries[2].ID, entries[1].ID}},
+		"target":      {opt: AuditLogListOptions{TargetType: "User", TargetID: "1"}, wantIDs: []int64{entries[1].ID}},
+		"before":      {opt: AuditLogListOptions{Before: entries[0].Timestamp.Add(-time.Hour)}, wantIDs: nil},
+		"after":       {opt: AuditLogListOptions{After: entries[0].Timestamp.Add(-time.Hour)}, wantIDs: []int64{entries[2].ID, entries[1].ID, entries[0].ID}},
+		"limitOffset": {opt: AuditLogListOptions{LimitOffset: &LimitOffset{Limit: 1, Offset: 1}}, wantIDs: []int64{entries[1].ID}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := AuditLog.List(ctx, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotIDs []int64
+			for _, e := range got {
+				gotIDs = append(gotIDs, e.ID)
+			}
+			if len(gotIDs) != len(test.wantIDs) {
+				t.Fatalf("got IDs %v, want %v", gotIDs, test.wantIDs)
+			}
+			for i := range gotIDs {
+				if gotIDs[i] != test.wantIDs[i] {
+					t.Fatalf("got IDs %v, want %v", gotIDs, test.wantIDs)
+				}
+			}
+
+			if test.opt.LimitOffset == nil {
+				count, err := AuditLog.Count(ctx, test.opt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if count != len(test.wantIDs) {
+					t.Errorf("got count %d, want %d", count, len(test.wantIDs))
+				}
+			}
+		})
+	}
+}
+
+// 🚨 SECURITY: This tests that an action is rolled back if it can't be recorded in the audit log.
+func TestAuditLog_Record(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setSiteAdmin := func(e *AuditLogEntry) func(ctx context.Context) (*AuditLogEntry, error) {
+		return func(ctx context.Context) (*AuditLogEntry, error) {
+			return e, Users.SetIsSiteAdmin(ctx, user.ID, true)
+		}
+	}
+	isSiteAdmin := func() bool {
+		u, err := Users.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.SiteAdmin
+	}
+
+	// An entry without an action can't be inserted.
+	if _, err := AuditLog.Record(ctx, setSiteAdmin(&AuditLogEntry{TargetType: "User"})); err == nil {
+		t.Fatal("got nil error for entry without action, want error")
+	}
+	if isSiteAdmin() {
+		t.Error("got site admin after failing to record the change, want change rolled back")
+	}
+
+	e, err := AuditLog.Record(ctx, setSiteAdmin(&AuditLogEntry{Action: "user.siteAdmin.set", TargetType: "User"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e == nil || e.ID == 0 {
+		t.Errorf("got entry %+v, want inserted entry", e)
+	}
+	if !isSiteAdmin() {
+		t.Error("got not site admin, want change committed")
+	}
+}
+
+// 🚨 SECURITY: This tests that tampering with the audit log is detected.
+func TestAuditLog_Verify(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	var entries []*AuditLogEntry
+	for _, action := range []string{"a", "b", "c"} {
+		e := &AuditLogEntry{ActorUserID: 1, Action: action, TargetType: "Site"}
+		if err := AuditLog.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+
+	if bad, err := AuditLog.Verify(ctx); err != nil {
+		t.Fatal(err)
+	} else if bad != nil {
+		t.Fatalf("got invalid entry %d, want intact audit log", bad.ID)
+	}
+
+	t.Run("modified entry", func(t *testing.T) {
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE audit_log SET actor_user_id=2 WHERE id=$1", entries[1].ID); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if _, err := dbconn.Global.ExecContext(ctx, "UPDATE audit_log SET actor_user_id=1 WHERE id=$1", entries[1].ID); err != nil {
+				t.Fatal(err)
+			}
+		}()
+		bad, err := AuditLog.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bad == nil || bad.ID != entries[1].ID {
+			t.Errorf("got invalid entry %+v, want entry %d", bad, entries[1].ID)
+		}
+	})
+
+	t.Run("deleted entry", func(t *testing.T) {
+		if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM audit_log WHERE id=$1", entries[1].ID); err != nil {
+			t.Fatal(err)
+		}
+		bad, err := AuditLog.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bad == nil || bad.ID != entries[2].ID {
+			t.Errorf("got invalid entry %+v, want entry %d", bad, entries[2].ID)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/keegancsmith/sqlf"
@@ -95,6 +96,23 @@ func (l *eventLogs) ListAll(ctx context.Context, opt EventLogsListOptions) ([]*t
 	return l.getBySQL(ctx, sqlf.Sprintf("WHERE %s ORDER BY timestamp DESC %s", sqlf.Join(conds, "AND"), opt.LimitOffset.SQL()))
 }
 
+// ListAfterID gets up to limit event logs with an ID greater than afterID, in ascending order of ID.
+// It stops before the first event that was inserted less than minAge ago.
+//
+// An event's ID is assigned when its insert begins, but inserts may commit in a different order, so
+// an event may become visible after events with higher IDs. Callers that remember the last ID they
+// saw (such as usage event exports) use a minAge that is longer than any insert takes, so that no
+// event with a lower ID can still appear.
+func (l *eventLogs) ListAfterID(ctx context.Context, afterID int64, limit int, minAge time.Duration) ([]*types.Event, error) {
+	return l.getBySQL(ctx, sqlf.Sprintf(`
+WHERE id > %d AND id < COALESCE((
+  SELECT min(id) FROM (SELECT id, created_at FROM event_logs WHERE id > %d ORDER BY id ASC LIMIT %d) e
+  WHERE e.created_at > now() - %s::interval
+), %d)
+ORDER BY id ASC LIMIT %d`,
+		afterID, afterID, limit, fmt.Sprintf("%d milliseconds", minAge.Milliseconds()), int64(math.MaxInt64), limit))
+}
+
 // CountByUserID gets a count of events logged by a given user.
 func (l *eventLogs) CountByUserID(ctx context.Context, userID int32) (int, error) {
 	return l.countBySQL(ctx, sqlf.Sprintf("WHERE user_id = %d", userID))
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
 )
 
@@ -56,6 +57,49 @@ func TestEventLogs_ValidInfo(t *testing.T) {
 	}
 }
 
+func TestEventLogs_ListAfterID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := EventLogs.Insert(ctx, &Event{Name: name, UserID: 1, URL: "http://sourcegraph.com", Source: "WEB"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := EventLogs.ListAfterID(ctx, 0, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d events, want 3", len(all))
+	}
+
+	events, err := EventLogs.ListAfterID(ctx, int64(all[0].ID), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Name != "b" {
+		t.Errorf("got %+v, want event b", events)
+	}
+
+	// Only events inserted at least minAge ago are listed, up to the first recent event (even if
+	// later events are old enough).
+	if _, err := dbconn.Global.ExecContext(ctx, "UPDATE event_logs SET created_at=now() - interval '1 hour' WHERE name IN ('a', 'c')"); err != nil {
+		t.Fatal(err)
+	}
+	events, err = EventLogs.ListAfterID(ctx, 0, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Name != "a" {
+		t.Errorf("got %+v, want only event a", events)
+	}
+}
+
 func TestEventLogs_CountUniqueUsersPerPeriod(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -16,7 +15,6 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
-	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"github.com/xeipuuv/gojsonschema"
@@ -222,7 +220,7 @@ func (c *ExternalServicesStore) Create(ctx context.Context, confGet func() *conf
 	externalService.CreatedAt = time.Now()
 	externalService.UpdatedAt = externalService.CreatedAt
 
-	return dbconn.Global.QueryRowContext(
+	return dbconn.FromContext(ctx).QueryRowContext(
 		ctx,
 		"INSERT INTO external_services(kind, display_name, config, created_at, updated_at) VALUES($1, $2, $3, $4, $5) RETURNING id",
 		externalService.Kind, externalService.DisplayName, externalService.Config, externalService.CreatedAt, externalService.UpdatedAt,
@@ -251,7 +249,7 @@ func (c *ExternalServicesStore) Update(ctx context.Context, ps []schema.AuthProv
 		}
 	}
 
-	execUpdate := func(ctx context.Context, tx *sql.Tx, update *sqlf.Query) error {
+	execUpdate := func(ctx context.Context, tx dbconn.Queryer, update *sqlf.Query) error {
 		q := sqlf.Sprintf("UPDATE external_services SET %s, updated_at=now() WHERE id=%d AND deleted_at IS NULL", update, id)
 		res, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 		if err != nil {
@@ -266,7 +264,8 @@ func (c *ExternalServicesStore) Update(ctx context.Context, ps []schema.AuthProv
 		}
 		return nil
 	}
-	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+	return dbconn.Transaction(ctx, func(ctx context.Context) error {
+		tx := dbconn.FromContext(ctx)
 		if update.DisplayName != nil {
 			if err := execUpdate(ctx, tx, sqlf.Sprintf("display_name=%s", update.DisplayName)); err != nil {
 				return err
@@ -297,7 +296,7 @@ func (e externalServiceNotFoundError) NotFound() bool {
 //
 // 🚨 SECURITY: The caller must ensure that the actor is a site admin.
 func (*ExternalServicesStore) Delete(ctx context.Context, id int64) error {
-	res, err := dbconn.Global.ExecContext(ctx, "UPDATE external_services SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx, "UPDATE external_services SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
 	if err != nil {
 		return err
 	}
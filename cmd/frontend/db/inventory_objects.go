@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// inventoryObjects stores the language inventories of Git trees, keyed by
+// object ID. The inventories are stored as JSON, which is encoded and decoded
+// by the caller. Inventories which have not been used for a while are deleted
+// with DeleteUnusedSince.
+type inventoryObjects struct{}
+
+// Get returns the inventory of the object with the given OID, if it is
+// stored. enhanced is whether the inventory was computed with enhanced
+// language detection.
+func (*inventoryObjects) Get(ctx context.Context, oid string, enhanced bool) (inventory []byte, ok bool, err error) {
+	if Mocks.InventoryObjects.Get != nil {
+		return Mocks.InventoryObjects.Get(ctx, oid, enhanced)
+	}
+
+	var lastUsedAt time.Time
+	err = dbconn.Global.QueryRowContext(
+		ctx,
+		"SELECT inventory, last_used_at FROM inventory_objects WHERE oid=$1 AND enhanced=$2",
+		oid, enhanced,
+	).Scan(&inventory, &lastUsedAt)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "QueryRow")
+	}
+
+	// Only record the use once a day, so reads rarely need a write.
+	if time.Since(lastUsedAt) > 24*time.Hour {
+		if _, err := dbconn.Global.ExecContext(
+			ctx,
+			"UPDATE inventory_objects SET last_used_at=now() WHERE oid=$1 AND enhanced=$2",
+			oid, enhanced,
+		); err != nil {
+			return nil, false, errors.Wrap(err, "UPDATE")
+		}
+	}
+	return inventory, true, nil
+}
+
+// Set stores the inventory of the object with the given OID. Objects are
+// immutable, so an existing inventory is kept.
+func (*inventoryObjects) Set(ctx context.Context, oid string, enhanced bool, inventory []byte) error {
+	if Mocks.InventoryObjects.Set != nil {
+		return Mocks.InventoryObjects.Set(ctx, oid, enhanced, inventory)
+	}
+
+	_, err := dbconn.Global.ExecContext(
+		ctx,
+		"INSERT INTO inventory_objects(oid, enhanced, inventory) VALUES($1, $2, $3) ON CONFLICT DO NOTHING",
+		oid, enhanced, inventory,
+	)
+	return errors.Wrap(err, "INSERT")
+}
+
+// DeleteUnusedSince deletes the inventories which have not been used since
+// t. It returns the number of inventories deleted.
+func (*inventoryObjects) DeleteUnusedSince(ctx context.Context, t time.Time) (int64, error) {
+	if Mocks.InventoryObjects.DeleteUnusedSince != nil {
+		return Mocks.InventoryObjects.DeleteUnusedSince(ctx, t)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM inventory_objects WHERE last_used_at < $1", t.UTC())
+	if err != nil {
+		return 0, errors.Wrap(err, "DELETE")
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,12 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type MockInventoryObjects struct {
+	Get               func(ctx context.Context, oid string, enhanced bool) ([]byte, bool, error)
+	Set               func(ctx context.Context, oid string, enhanced bool, inventory []byte) error
+	DeleteUnusedSince func(ctx context.Context, t time.Time) (int64, error)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestInventoryObjects(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	const oid = "2b8d3e3d7bd0d0c5a5f5e9b3c1f0a0e4d2c1b0a9"
+	if _, ok, err := InventoryObjects.Get(ctx, oid, true); err != nil || ok {
+		t.Fatalf("got ok=%v err=%v, want a miss", ok, err)
+	}
+
+	if err := InventoryObjects.Set(ctx, oid, true, []byte(`{"Languages":[{"Name":"Go","TotalBytes":10,"TotalLines":1}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	// Objects are immutable, so setting again keeps the first inventory.
+	if err := InventoryObjects.Set(ctx, oid, true, []byte(`{"Languages":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	inv, ok, err := InventoryObjects.Get(ctx, oid, true)
+	if err != nil || !ok {
+		t.Fatalf("got ok=%v err=%v, want a hit", ok, err)
+	}
+	if want := `{"Languages": [{"Name": "Go", "TotalBytes": 10, "TotalLines": 1}]}`; string(inv) != want {
+		t.Errorf("got inventory %s, want %s", inv, want)
+	}
+
+	// Inventories computed without enhanced language detection are separate.
+	if _, ok, err := InventoryObjects.Get(ctx, oid, false); err != nil || ok {
+		t.Fatalf("got ok=%v err=%v, want a miss", ok, err)
+	}
+
+	// Inventories used since are kept, and unused ones are deleted.
+	if n, err := InventoryObjects.DeleteUnusedSince(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("got %d deleted (err=%v), want 0", n, err)
+	}
+	if n, err := InventoryObjects.DeleteUnusedSince(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("got %d deleted (err=%v), want 1", n, err)
+	}
+	if _, ok, err := InventoryObjects.Get(ctx, oid, true); err != nil || ok {
+		t.Fatalf("got ok=%v err=%v, want a miss", ok, err)
+	}
+}
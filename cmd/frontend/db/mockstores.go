@@ -17,6 +17,7 @@ type MockStores struct {
 	Settings      MockSettings
 	Users         MockUsers
 	UserEmails    MockUserEmails
+	UserTwoFactor MockUserTwoFactor
 
 	Phabricator MockPhabricator
 
@@ -26,5 +27,13 @@ type MockStores struct {
 
 	ExternalServices MockExternalServices
 
+	InventoryObjects MockInventoryObjects
+
+	RepoPackages MockRepoPackages
+
 	Authz MockAuthz
+
+	AuditLog MockAuditLog
+
+	SearchHistory MockSearchHistory
 }
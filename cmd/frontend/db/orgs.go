@@ -23,6 +23,8 @@ func (e *OrgNotFoundError) Error() string {
 	return fmt.Sprintf("org not found: %s", e.Message)
 }
 
+func (e *OrgNotFoundError) NotFound() bool { return true }
+
 var errOrgNameAlreadyExists = errors.New("organization name is already taken (by a user or another organization)")
 
 type orgs struct{}
@@ -148,6 +150,10 @@ func (*orgs) getBySQL(ctx context.Context, query string, args ...interface{}) ([
 }
 
 func (*orgs) Create(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+	if Mocks.Orgs.Create != nil {
+		return Mocks.Orgs.Create(ctx, name, displayName)
+	}
+
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -197,6 +203,10 @@ func (*orgs) Create(ctx context.Context, name string, displayName *string) (*typ
 }
 
 func (o *orgs) Update(ctx context.Context, id int32, displayName *string) (*types.Org, error) {
+	if Mocks.Orgs.Update != nil {
+		return Mocks.Orgs.Update(ctx, id, displayName)
+	}
+
 	org, err := o.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -221,6 +231,10 @@ func (o *orgs) Update(ctx context.Context, id int32, displayName *string) (*type
 }
 
 func (o *orgs) Delete(ctx context.Context, id int32) error {
+	if Mocks.Orgs.Delete != nil {
+		return Mocks.Orgs.Delete(ctx, id)
+	}
+
 	// Wrap in transaction because we delete from multiple tables.
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+)
+
+// repoPackages stores the packages that repositories provide and depend on,
+// as declared by the manifest files of their default branch.
+//
+// 🚨 SECURITY: The methods return repository IDs without checking
+// permissions. Callers must look up the repositories with Repos, which
+// enforces them.
+type repoPackages struct{}
+
+// RepoDependency is a package that a repository depends on.
+type RepoDependency struct {
+	depgraph.Package
+
+	// RepoID is the ID of the repository that provides the package, or 0 if
+	// it is not known.
+	RepoID api.RepoID
+}
+
+// GetCommit returns the commit at which the packages of the repository were
+// last set, or "" if they never were.
+func (*repoPackages) GetCommit(ctx context.Context, repo api.RepoID) (api.CommitID, error) {
+	if Mocks.RepoPackages.GetCommit != nil {
+		return Mocks.RepoPackages.GetCommit(ctx, repo)
+	}
+
+	var commit api.CommitID
+	err := dbconn.Global.QueryRowContext(ctx, "SELECT commit_id FROM repo_packages_commits WHERE repo_id=$1", repo).Scan(&commit)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return commit, errors.Wrap(err, "QueryRow")
+}
+
+// Set replaces the packages of the repository with those declared by m at
+// the given commit.
+func (*repoPackages) Set(ctx context.Context, repo api.RepoID, commit api.CommitID, m *depgraph.Manifest) error {
+	if Mocks.RepoPackages.Set != nil {
+		return Mocks.RepoPackages.Set(ctx, repo, commit, m)
+	}
+
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM repo_packages WHERE repo_id=$1", repo); err != nil {
+			return errors.Wrap(err, "DELETE")
+		}
+
+		var values []*sqlf.Query
+		for _, p := range m.Provides {
+			values = append(values, sqlf.Sprintf("(%s, %s, %s, %s, false)", repo, p.Kind, p.Name, p.Version))
+		}
+		for _, p := range m.Dependencies {
+			values = append(values, sqlf.Sprintf("(%s, %s, %s, %s, true)", repo, p.Kind, p.Name, p.Version))
+		}
+		if len(values) > 0 {
+			q := sqlf.Sprintf("INSERT INTO repo_packages(repo_id, kind, name, version, dependency) VALUES %s", sqlf.Join(values, ","))
+			if _, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+				return errors.Wrap(err, "INSERT")
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+INSERT INTO repo_packages_commits(repo_id, commit_id) VALUES($1, $2)
+ON CONFLICT (repo_id) DO UPDATE SET commit_id=excluded.commit_id, updated_at=now()`,
+			repo, commit,
+		)
+		return errors.Wrap(err, "INSERT")
+	})
+}
+
+// goModuleRepoName is the SQL expression of the name of the repository that
+// presumably provides a Go module, for modules that aren't provided by a
+// repository with a go.mod file: the module path without its major version
+// suffix (e.g. github.com/a/b for github.com/a/b/v2).
+const goModuleRepoName = `regexp_replace(d.name, '/v[0-9]+$', '')`
+
+// ListDependencies returns the packages that the repository depends on,
+// ordered by kind and name. A package is resolved to the repository which
+// provides it; Go modules are also resolved to the repository named like
+// the module.
+func (*repoPackages) ListDependencies(ctx context.Context, repo api.RepoID) ([]*RepoDependency, error) {
+	if Mocks.RepoPackages.ListDependencies != nil {
+		return Mocks.RepoPackages.ListDependencies(ctx, repo)
+	}
+
+	rows, err := dbconn.Global.QueryContext(ctx, `
+SELECT d.kind, d.name, d.version, COALESCE(
+	(SELECT p.repo_id FROM repo_packages p JOIN repo r ON r.id=p.repo_id AND r.deleted_at IS NULL
+	 WHERE p.kind=d.kind AND p.name=d.name AND NOT p.dependency AND p.repo_id != d.repo_id
+	 ORDER BY p.repo_id LIMIT 1),
+	(SELECT r.id FROM repo r WHERE d.kind='go' AND r.name=`+goModuleRepoName+` AND r.deleted_at IS NULL),
+	0
+)
+FROM repo_packages d
+WHERE d.repo_id=$1 AND d.dependency
+ORDER BY d.kind, d.name`,
+		repo,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Query")
+	}
+	defer rows.Close()
+
+	var deps []*RepoDependency
+	for rows.Next() {
+		var d RepoDependency
+		if err := rows.Scan(&d.Kind, &d.Name, &d.Version, &d.RepoID); err != nil {
+			return nil, err
+		}
+		deps = append(deps, &d)
+	}
+	return deps, rows.Err()
+}
+
+// ListDependents returns the IDs of the repositories which depend on a
+// package provided by the repository, in ascending order. At most limit IDs
+// are returned.
+func (*repoPackages) ListDependents(ctx context.Context, repo api.RepoID, limit int) ([]api.RepoID, error) {
+	if Mocks.RepoPackages.ListDependents != nil {
+		return Mocks.RepoPackages.ListDependents(ctx, repo, limit)
+	}
+
+	rows, err := dbconn.Global.QueryContext(ctx, `
+SELECT DISTINCT d.repo_id
+FROM repo_packages d
+JOIN repo r ON r.id=d.repo_id AND r.deleted_at IS NULL
+WHERE d.dependency AND d.repo_id != $1 AND (
+	EXISTS (SELECT 1 FROM repo_packages p WHERE p.repo_id=$1 AND NOT p.dependency AND p.kind=d.kind AND p.name=d.name)
+	OR (d.kind='go' AND `+goModuleRepoName+`=(SELECT name FROM repo WHERE id=$1))
+)
+ORDER BY d.repo_id
+LIMIT $2`,
+		repo, limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Query")
+	}
+	defer rows.Close()
+
+	var ids []api.RepoID
+	for rows.Next() {
+		var id api.RepoID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
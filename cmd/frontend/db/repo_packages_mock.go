@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+)
+
+type MockRepoPackages struct {
+	GetCommit        func(ctx context.Context, repo api.RepoID) (api.CommitID, error)
+	Set              func(ctx context.Context, repo api.RepoID, commit api.CommitID, m *depgraph.Manifest) error
+	ListDependencies func(ctx context.Context, repo api.RepoID) ([]*RepoDependency, error)
+	ListDependents   func(ctx context.Context, repo api.RepoID, limit int) ([]api.RepoID, error)
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+)
+
+func TestRepoPackages(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	repos := mustCreate(ctx, t,
+		&types.Repo{Name: "github.com/a/lib"},
+		&types.Repo{Name: "github.com/a/web"},
+		&types.Repo{Name: "github.com/a/app"},
+	)
+	lib, web, app := repos[0].ID, repos[1].ID, repos[2].ID
+
+	if commit, err := RepoPackages.GetCommit(ctx, app); err != nil || commit != "" {
+		t.Fatalf("got commit %q (error %v), want none", commit, err)
+	}
+
+	set := func(repo api.RepoID, commit api.CommitID, m *depgraph.Manifest) {
+		t.Helper()
+		if err := RepoPackages.Set(ctx, repo, commit, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set(lib, "c0", &depgraph.Manifest{})
+	set(web, "c1", &depgraph.Manifest{
+		Provides: []depgraph.Package{{Kind: depgraph.KindNPM, Name: "@a/web", Version: "1.0.0"}},
+	})
+	set(app, "c2", &depgraph.Manifest{
+		Dependencies: []depgraph.Package{{Kind: depgraph.KindNPM, Name: "left-pad", Version: "1"}},
+	})
+	// Setting replaces the previous packages.
+	set(app, "c3", &depgraph.Manifest{
+		Provides: []depgraph.Package{{Kind: depgraph.KindGo, Name: "github.com/a/app"}},
+		Dependencies: []depgraph.Package{
+			{Kind: depgraph.KindGo, Name: "github.com/a/lib/v2", Version: "v2.0.0"},
+			{Kind: depgraph.KindGo, Name: "github.com/x/y", Version: "v1.0.0"},
+			{Kind: depgraph.KindNPM, Name: "@a/web", Version: "^1.0.0"},
+		},
+	})
+
+	if commit, err := RepoPackages.GetCommit(ctx, app); err != nil || commit != "c3" {
+		t.Fatalf("got commit %q (error %v), want c3", commit, err)
+	}
+
+	deps, err := RepoPackages.ListDependencies(ctx, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDeps := []*RepoDependency{
+		{Package: depgraph.Package{Kind: depgraph.KindGo, Name: "github.com/a/lib/v2", Version: "v2.0.0"}, RepoID: lib},
+		{Package: depgraph.Package{Kind: depgraph.KindGo, Name: "github.com/x/y", Version: "v1.0.0"}},
+		{Package: depgraph.Package{Kind: depgraph.KindNPM, Name: "@a/web", Version: "^1.0.0"}, RepoID: web},
+	}
+	if !reflect.DeepEqual(deps, wantDeps) {
+		t.Errorf("got dependencies %+v, want %+v", deps, wantDeps)
+	}
+
+	for repo, want := range map[api.RepoID][]api.RepoID{lib: {app}, web: {app}, app: nil} {
+		dependents, err := RepoPackages.ListDependents(ctx, repo, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(dependents, want) {
+			t.Errorf("repo %d: got dependents %v, want %v", repo, dependents, want)
+		}
+	}
+}
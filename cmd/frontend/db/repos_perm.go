@@ -17,6 +17,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 )
 
@@ -66,6 +67,10 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 
 	began := time.Now()
 	tr, ctx := trace.New(ctx, "authzFilter", "")
+	// Authz providers are asked for permissions while a user waits on the
+	// request, so their requests to code hosts take priority over background
+	// work that uses the same API budget.
+	ctx = ratelimit.WithPriority(ctx, ratelimit.PriorityInteractive)
 	defer func() {
 		defer tr.Finish()
 
@@ -89,6 +94,18 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		tr.LogFields(fields...)
 	}()
 
+	// 🚨 SECURITY: An access token restricted to specific repositories may not access any other
+	// repository, regardless of the permissions of its user.
+	if t := authz.TokenScopesFromContext(ctx); t != nil && len(t.Repos) > 0 {
+		filtered := repos[:0]
+		for _, r := range repos {
+			if t.AllowsRepo(r.Name) {
+				filtered = append(filtered, r)
+			}
+		}
+		repos = filtered
+	}
+
 	if isInternalActor(ctx) {
 		return repos, nil
 	}
@@ -656,6 +656,29 @@ func Test_authzFilter(t *testing.T) {
 	}
 }
 
+// 🚨 SECURITY: This tests that access tokens restricted to specific repositories cannot access
+// other repositories, even for site admins.
+func Test_authzFilter_tokenRepos(t *testing.T) {
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	defer func() { Mocks.Users.GetByCurrentAuthUser = nil }()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	ctx = authz.WithTokenScopes(ctx, &authz.TokenScopes{
+		Scopes: []string{authz.ScopeRepoRead},
+		Repos:  []api.RepoName{"github.com/foo/b"},
+	})
+
+	filtered, err := authzFilter(ctx, makeRepos("github.com/foo/a", "github.com/foo/b", "github.com/foo/c"), authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].Name != "github.com/foo/b" {
+		t.Fatalf("got repos %v, want only github.com/foo/b", filtered)
+	}
+}
+
 func Test_authzFilter_createsNewUsers(t *testing.T) {
 	associateUserAndSaveCount := make(map[int32]map[extsvc.AccountSpec]int)
 	Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.AccountSpec, data extsvc.AccountData) error {
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// SearchHistoryEntry is a search performed by a user.
+type SearchHistoryEntry struct {
+	ID          int64
+	UserID      int32
+	Query       string
+	PatternType string // "literal", "regexp" or "structural"
+	ResultCount int32
+	LatencyMs   int32
+	CreatedAt   time.Time
+}
+
+// FrequentSearch is a distinct search (query and pattern type) performed by a user, with the number
+// of times the user performed it.
+type FrequentSearch struct {
+	Query          string
+	PatternType    string
+	Count          int32
+	LastSearchedAt time.Time
+}
+
+// searchHistory provides access to the `search_history` table.
+//
+// For a detailed overview of the schema, see schema.md.
+type searchHistory struct{}
+
+// Insert records a search performed by a user. The entry's ID and CreatedAt (if zero) fields are
+// set.
+func (*searchHistory) Insert(ctx context.Context, e *SearchHistoryEntry) error {
+	if Mocks.SearchHistory.Insert != nil {
+		return Mocks.SearchHistory.Insert(e)
+	}
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if err := dbconn.Global.QueryRowContext(ctx,
+		"INSERT INTO search_history(user_id, query, pattern_type, result_count, latency_ms, created_at) VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
+		e.UserID, e.Query, e.PatternType, e.ResultCount, e.LatencyMs, e.CreatedAt.UTC(),
+	).Scan(&e.ID); err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+	return nil
+}
+
+// SearchHistoryListOptions contains options for listing a user's search history.
+type SearchHistoryListOptions struct {
+	UserID int32 // list the history of this user (required)
+	// BeforeID, if nonzero, only includes entries with an ID less than this (i.e., entries recorded
+	// before it). It is used to paginate recent searches.
+	BeforeID int64
+	// Prefix, if set, only includes searches whose query starts with this string.
+	Prefix string
+	*LimitOffset
+}
+
+func (o SearchHistoryListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("user_id=%d", o.UserID)}
+	if o.BeforeID != 0 {
+		conds = append(conds, sqlf.Sprintf("id < %d", o.BeforeID))
+	}
+	if o.Prefix != "" {
+		conds = append(conds, sqlf.Sprintf("position(%s in query)=1", o.Prefix))
+	}
+	return conds
+}
+
+// ListRecent lists the searches performed by the user, most recent first.
+func (*searchHistory) ListRecent(ctx context.Context, opt SearchHistoryListOptions) ([]*SearchHistoryEntry, error) {
+	if Mocks.SearchHistory.ListRecent != nil {
+		return Mocks.SearchHistory.ListRecent(opt)
+	}
+
+	q := sqlf.Sprintf("SELECT id, user_id, query, pattern_type, result_count, latency_ms, created_at FROM search_history WHERE (%s) ORDER BY id DESC %s", sqlf.Join(opt.sqlConditions(), ") AND ("), opt.LimitOffset.SQL())
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*SearchHistoryEntry
+	for rows.Next() {
+		var e SearchHistoryEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Query, &e.PatternType, &e.ResultCount, &e.LatencyMs, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// CountRecent counts the searches performed by the user that match the options (ignoring
+// LimitOffset).
+func (*searchHistory) CountRecent(ctx context.Context, opt SearchHistoryListOptions) (int, error) {
+	if Mocks.SearchHistory.CountRecent != nil {
+		return Mocks.SearchHistory.CountRecent(opt)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM search_history WHERE (%s)", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListFrequent lists the distinct searches (query and pattern type) performed by the user, most
+// frequently performed first. Searches performed equally often are ordered by most recent first.
+// The BeforeID option is ignored.
+func (*searchHistory) ListFrequent(ctx context.Context, opt SearchHistoryListOptions) ([]*FrequentSearch, error) {
+	if Mocks.SearchHistory.ListFrequent != nil {
+		return Mocks.SearchHistory.ListFrequent(opt)
+	}
+
+	opt.BeforeID = 0
+	q := sqlf.Sprintf(`
+SELECT query, pattern_type, COUNT(*) AS count, MAX(created_at) AS last_searched_at FROM search_history
+WHERE (%s)
+GROUP BY query, pattern_type
+ORDER BY count DESC, last_searched_at DESC, query ASC
+%s`, sqlf.Join(opt.sqlConditions(), ") AND ("), opt.LimitOffset.SQL())
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []*FrequentSearch
+	for rows.Next() {
+		var s FrequentSearch
+		if err := rows.Scan(&s.Query, &s.PatternType, &s.Count, &s.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		searches = append(searches, &s)
+	}
+	return searches, rows.Err()
+}
+
+// CountFrequent counts the distinct searches (query and pattern type) performed by the user that
+// match the options (ignoring BeforeID and LimitOffset).
+func (*searchHistory) CountFrequent(ctx context.Context, opt SearchHistoryListOptions) (int, error) {
+	if Mocks.SearchHistory.CountFrequent != nil {
+		return Mocks.SearchHistory.CountFrequent(opt)
+	}
+
+	opt.BeforeID = 0
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM (SELECT DISTINCT query, pattern_type FROM search_history WHERE (%s)) AS searches", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteByUserID deletes all of the user's search history.
+func (*searchHistory) DeleteByUserID(ctx context.Context, userID int32) error {
+	if Mocks.SearchHistory.DeleteByUserID != nil {
+		return Mocks.SearchHistory.DeleteByUserID(userID)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM search_history WHERE user_id=$1", userID)
+	return err
+}
+
+// DeleteOlderThan deletes all search history entries recorded before t. It returns the number of
+// entries deleted.
+func (*searchHistory) DeleteOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM search_history WHERE created_at < $1", t.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
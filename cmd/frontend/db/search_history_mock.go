@@ -0,0 +1,10 @@
+package db
+
+type MockSearchHistory struct {
+	Insert         func(e *SearchHistoryEntry) error
+	ListRecent     func(opt SearchHistoryListOptions) ([]*SearchHistoryEntry, error)
+	CountRecent    func(opt SearchHistoryListOptions) (int, error)
+	ListFrequent   func(opt SearchHistoryListOptions) ([]*FrequentSearch, error)
+	CountFrequent  func(opt SearchHistoryListOptions) (int, error)
+	DeleteByUserID func(userID int32) error
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestSearchHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user1, err := Users.Create(ctx, NewUser{Username: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user2, err := Users.Create(ctx, NewUser{Username: "u2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for i, e := range []*SearchHistoryEntry{
+		{UserID: user1.ID, Query: "foo", PatternType: "literal", CreatedAt: now.Add(-72 * time.Hour)},
+		{UserID: user1.ID, Query: "bar", PatternType: "literal", CreatedAt: now.Add(-3 * time.Minute)},
+		{UserID: user1.ID, Query: "foo", PatternType: "regexp", CreatedAt: now.Add(-2 * time.Minute)},
+		{UserID: user1.ID, Query: "foo", PatternType: "literal", CreatedAt: now.Add(-1 * time.Minute)},
+		{UserID: user2.ID, Query: "foo", PatternType: "literal", CreatedAt: now},
+	} {
+		e.ResultCount = int32(i)
+		e.LatencyMs = 100
+		if err := SearchHistory.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queries := func(entries []*SearchHistoryEntry) (qs []string) {
+		for _, e := range entries {
+			qs = append(qs, e.Query+"/"+e.PatternType)
+		}
+		return qs
+	}
+
+	t.Run("ListRecent", func(t *testing.T) {
+		entries, err := SearchHistory.ListRecent(ctx, SearchHistoryListOptions{UserID: user1.ID, LimitOffset: &LimitOffset{Limit: 2}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"foo/literal", "foo/regexp"}; !reflect.DeepEqual(queries(entries), want) {
+			t.Errorf("got %v, want %v", queries(entries), want)
+		}
+
+		// Next page.
+		entries, err = SearchHistory.ListRecent(ctx, SearchHistoryListOptions{UserID: user1.ID, BeforeID: entries[1].ID, LimitOffset: &LimitOffset{Limit: 2}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"bar/literal", "foo/literal"}; !reflect.DeepEqual(queries(entries), want) {
+			t.Errorf("got %v, want %v", queries(entries), want)
+		}
+
+		count, err := SearchHistory.CountRecent(ctx, SearchHistoryListOptions{UserID: user1.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 4; count != want {
+			t.Errorf("got count %d, want %d", count, want)
+		}
+	})
+
+	t.Run("ListFrequent", func(t *testing.T) {
+		searches, err := SearchHistory.ListFrequent(ctx, SearchHistoryListOptions{UserID: user1.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*FrequentSearch{
+			{Query: "foo", PatternType: "literal", Count: 2, LastSearchedAt: now.Add(-1 * time.Minute)},
+			{Query: "foo", PatternType: "regexp", Count: 1, LastSearchedAt: now.Add(-2 * time.Minute)},
+			{Query: "bar", PatternType: "literal", Count: 1, LastSearchedAt: now.Add(-3 * time.Minute)},
+		}
+		for _, s := range searches {
+			s.LastSearchedAt = s.LastSearchedAt.In(now.Location())
+		}
+		if !reflect.DeepEqual(searches, want) {
+			t.Errorf("got %+v, want %+v", searches, want)
+		}
+
+		count, err := SearchHistory.CountFrequent(ctx, SearchHistoryListOptions{UserID: user1.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 3; count != want {
+			t.Errorf("got count %d, want %d", count, want)
+		}
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		searches, err := SearchHistory.ListFrequent(ctx, SearchHistoryListOptions{UserID: user1.ID, Prefix: "ba"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(searches) != 1 || searches[0].Query != "bar" {
+			t.Errorf("got %+v, want only bar", searches)
+		}
+	})
+
+	t.Run("DeleteOlderThan", func(t *testing.T) {
+		n, err := SearchHistory.DeleteOlderThan(ctx, now.Add(-24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("got %d deleted, want 1", n)
+		}
+	})
+
+	t.Run("DeleteByUserID", func(t *testing.T) {
+		if err := SearchHistory.DeleteByUserID(ctx, user1.ID); err != nil {
+			t.Fatal(err)
+		}
+		for userID, want := range map[int32]int{user1.ID: 0, user2.ID: 1} {
+			count, err := SearchHistory.CountRecent(ctx, SearchHistoryListOptions{UserID: userID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != want {
+				t.Errorf("user %d: got count %d, want %d", userID, count, want)
+			}
+		}
+	})
+}
@@ -17,6 +17,9 @@ var (
 	Users                     = &users{}
 	UserEmails                = &userEmails{}
 	EventLogs                 = &eventLogs{}
+	AuditLog                  = &auditLog{}
+	UserTwoFactor             = &userTwoFactor{}
+	SearchHistory             = &searchHistory{}
 
 	SurveyResponses = &surveyResponses{}
 
@@ -24,5 +27,9 @@ var (
 
 	OrgInvitations = &orgInvitations{}
 
+	InventoryObjects = &inventoryObjects{}
+
+	RepoPackages = &repoPackages{}
+
 	Authz AuthzStore = &authzStore{}
 )
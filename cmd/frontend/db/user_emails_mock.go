@@ -11,4 +11,6 @@ type MockUserEmails struct {
 	GetLatestVerificationSentEmail func(ctx context.Context, email string) (*UserEmail, error)
 	GetVerifiedEmails              func(ctx context.Context, emails ...string) ([]*UserEmail, error)
 	ListByUser                     func(ctx context.Context, opt UserEmailsListOptions) ([]*UserEmail, error)
+	Add                            func(ctx context.Context, userID int32, email string, verificationCode *string) error
+	Remove                         func(ctx context.Context, userID int32, email string) error
 }
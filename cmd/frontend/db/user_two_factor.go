@@ -0,0 +1,262 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// UserTOTP describes a user's TOTP (time-based one-time password) second factor.
+type UserTOTP struct {
+	EncryptedSecret []byte     // the TOTP secret, encrypted by the caller
+	EnabledAt       *time.Time // nil if enrollment has not been confirmed yet
+	LastUsedStep    int64      // the most recent time step whose code was used (to prevent replays)
+}
+
+// UserWebAuthnCredential describes a WebAuthn security key registered as a user's second factor.
+type UserWebAuthnCredential struct {
+	ID           int32
+	UserID       int32
+	Name         string
+	CredentialID []byte
+	PublicKey    []byte // the credential's public key, in PKIX (SubjectPublicKeyInfo) DER encoding
+	SignCount    int64
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time
+}
+
+// ErrWebAuthnCredentialNotFound occurs when a WebAuthn credential is not found.
+var ErrWebAuthnCredentialNotFound = errors.New("WebAuthn credential not found")
+
+// userTwoFactor provides access to the two-factor authentication columns of the `users` table and
+// the `user_webauthn_credentials` table.
+//
+// For a detailed overview of the schema, see schema.md.
+type userTwoFactor struct{}
+
+// GetTOTP returns the user's TOTP factor, or nil if the user has none (not even a pending one).
+func (*userTwoFactor) GetTOTP(ctx context.Context, userID int32) (*UserTOTP, error) {
+	if Mocks.UserTwoFactor.GetTOTP != nil {
+		return Mocks.UserTwoFactor.GetTOTP(ctx, userID)
+	}
+
+	var t UserTOTP
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx,
+		"SELECT totp_secret, totp_enabled_at, totp_last_used_step FROM users WHERE id=$1 AND deleted_at IS NULL", userID,
+	).Scan(&t.EncryptedSecret, &t.EnabledAt, &t.LastUsedStep); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, userNotFoundErr{args: []interface{}{"id", userID}}
+		}
+		return nil, err
+	}
+	if t.EncryptedSecret == nil {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// SetPendingTOTP stores a new (encrypted) TOTP secret for the user, pending confirmation with
+// EnableTOTP. It replaces any pending secret but fails if the user already has TOTP enabled.
+func (*userTwoFactor) SetPendingTOTP(ctx context.Context, userID int32, encryptedSecret []byte) error {
+	if Mocks.UserTwoFactor.SetPendingTOTP != nil {
+		return Mocks.UserTwoFactor.SetPendingTOTP(ctx, userID, encryptedSecret)
+	}
+
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx,
+		"UPDATE users SET totp_secret=$2, totp_enabled_at=NULL, totp_last_used_step=0 WHERE id=$1 AND deleted_at IS NULL AND totp_enabled_at IS NULL",
+		userID, encryptedSecret,
+	)
+	if err != nil {
+		return err
+	}
+	if nrows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if nrows == 0 {
+		return errors.New("TOTP is already enabled for the user (or the user does not exist)")
+	}
+	return nil
+}
+
+// EnableTOTP confirms the user's pending TOTP secret, recording step as the last used time step.
+func (*userTwoFactor) EnableTOTP(ctx context.Context, userID int32, step int64) error {
+	if Mocks.UserTwoFactor.EnableTOTP != nil {
+		return Mocks.UserTwoFactor.EnableTOTP(ctx, userID, step)
+	}
+
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx,
+		"UPDATE users SET totp_enabled_at=now(), totp_last_used_step=$2 WHERE id=$1 AND deleted_at IS NULL AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL",
+		userID, step,
+	)
+	if err != nil {
+		return err
+	}
+	if nrows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if nrows == 0 {
+		return errors.New("no pending TOTP enrollment for the user")
+	}
+	return nil
+}
+
+// UseTOTPStep records that the code of the given time step was used. It returns false if a code of
+// the same or a later time step was already used, in which case the code must be rejected.
+func (*userTwoFactor) UseTOTPStep(ctx context.Context, userID int32, step int64) (bool, error) {
+	if Mocks.UserTwoFactor.UseTOTPStep != nil {
+		return Mocks.UserTwoFactor.UseTOTPStep(ctx, userID, step)
+	}
+
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx,
+		"UPDATE users SET totp_last_used_step=$2 WHERE id=$1 AND totp_enabled_at IS NOT NULL AND totp_last_used_step < $2",
+		userID, step,
+	)
+	if err != nil {
+		return false, err
+	}
+	nrows, err := res.RowsAffected()
+	return nrows == 1, err
+}
+
+// DeleteTOTP removes the user's TOTP factor (enabled or pending).
+func (*userTwoFactor) DeleteTOTP(ctx context.Context, userID int32) error {
+	if Mocks.UserTwoFactor.DeleteTOTP != nil {
+		return Mocks.UserTwoFactor.DeleteTOTP(ctx, userID)
+	}
+
+	_, err := dbconn.FromContext(ctx).ExecContext(ctx, "UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_used_step=0 WHERE id=$1", userID)
+	return err
+}
+
+// SetRecoveryCodes replaces the user's recovery codes with the given hashes.
+func (*userTwoFactor) SetRecoveryCodes(ctx context.Context, userID int32, codeHashes []string) error {
+	if Mocks.UserTwoFactor.SetRecoveryCodes != nil {
+		return Mocks.UserTwoFactor.SetRecoveryCodes(ctx, userID, codeHashes)
+	}
+
+	if codeHashes == nil {
+		codeHashes = []string{}
+	}
+	_, err := dbconn.FromContext(ctx).ExecContext(ctx, "UPDATE users SET two_factor_recovery_codes=$2 WHERE id=$1", userID, pq.Array(codeHashes))
+	return err
+}
+
+// UseRecoveryCode removes the recovery code with the given hash. It returns false if the user has no
+// such recovery code.
+func (*userTwoFactor) UseRecoveryCode(ctx context.Context, userID int32, codeHash string) (bool, error) {
+	if Mocks.UserTwoFactor.UseRecoveryCode != nil {
+		return Mocks.UserTwoFactor.UseRecoveryCode(ctx, userID, codeHash)
+	}
+
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx,
+		"UPDATE users SET two_factor_recovery_codes=array_remove(two_factor_recovery_codes, $2) WHERE id=$1 AND $2=ANY(two_factor_recovery_codes)",
+		userID, codeHash,
+	)
+	if err != nil {
+		return false, err
+	}
+	nrows, err := res.RowsAffected()
+	return nrows == 1, err
+}
+
+// CountRecoveryCodes returns the number of unused recovery codes of the user.
+func (*userTwoFactor) CountRecoveryCodes(ctx context.Context, userID int32) (int, error) {
+	if Mocks.UserTwoFactor.CountRecoveryCodes != nil {
+		return Mocks.UserTwoFactor.CountRecoveryCodes(ctx, userID)
+	}
+
+	var count int
+	err := dbconn.FromContext(ctx).QueryRowContext(ctx, "SELECT coalesce(array_length(two_factor_recovery_codes, 1), 0) FROM users WHERE id=$1", userID).Scan(&count)
+	return count, err
+}
+
+// CreateWebAuthnCredential registers a WebAuthn credential. The ID and CreatedAt fields of c are
+// set.
+func (*userTwoFactor) CreateWebAuthnCredential(ctx context.Context, c *UserWebAuthnCredential) error {
+	if Mocks.UserTwoFactor.CreateWebAuthnCredential != nil {
+		return Mocks.UserTwoFactor.CreateWebAuthnCredential(ctx, c)
+	}
+
+	if c.Name == "" {
+		return errors.New("WebAuthn credential must have a name")
+	}
+	err := dbconn.FromContext(ctx).QueryRowContext(ctx,
+		"INSERT INTO user_webauthn_credentials(user_id, name, credential_id, public_key, sign_count) VALUES($1, $2, $3, $4, $5) RETURNING id, created_at",
+		c.UserID, c.Name, c.CredentialID, c.PublicKey, c.SignCount,
+	).Scan(&c.ID, &c.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "user_webauthn_credentials_credential_id" {
+		return errors.New("WebAuthn credential is already registered")
+	}
+	return err
+}
+
+// ListWebAuthnCredentials lists the user's WebAuthn credentials, oldest first.
+func (*userTwoFactor) ListWebAuthnCredentials(ctx context.Context, userID int32) ([]*UserWebAuthnCredential, error) {
+	if Mocks.UserTwoFactor.ListWebAuthnCredentials != nil {
+		return Mocks.UserTwoFactor.ListWebAuthnCredentials(ctx, userID)
+	}
+
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx,
+		"SELECT id, user_id, name, credential_id, public_key, sign_count, created_at, last_used_at FROM user_webauthn_credentials WHERE user_id=$1 ORDER BY id ASC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cs []*UserWebAuthnCredential
+	for rows.Next() {
+		var c UserWebAuthnCredential
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Name, &c.CredentialID, &c.PublicKey, &c.SignCount, &c.CreatedAt, &c.LastUsedAt); err != nil {
+			return nil, err
+		}
+		cs = append(cs, &c)
+	}
+	return cs, rows.Err()
+}
+
+// UpdateWebAuthnSignCount records a successful use of the WebAuthn credential with the given
+// signature counter value.
+func (*userTwoFactor) UpdateWebAuthnSignCount(ctx context.Context, id int32, signCount int64) error {
+	if Mocks.UserTwoFactor.UpdateWebAuthnSignCount != nil {
+		return Mocks.UserTwoFactor.UpdateWebAuthnSignCount(ctx, id, signCount)
+	}
+
+	_, err := dbconn.FromContext(ctx).ExecContext(ctx, "UPDATE user_webauthn_credentials SET sign_count=$2, last_used_at=now() WHERE id=$1", id, signCount)
+	return err
+}
+
+// DeleteWebAuthnCredential deletes the user's WebAuthn credential with the given ID.
+func (*userTwoFactor) DeleteWebAuthnCredential(ctx context.Context, userID, id int32) error {
+	if Mocks.UserTwoFactor.DeleteWebAuthnCredential != nil {
+		return Mocks.UserTwoFactor.DeleteWebAuthnCredential(ctx, userID, id)
+	}
+
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx, "DELETE FROM user_webauthn_credentials WHERE user_id=$1 AND id=$2", userID, id)
+	if err != nil {
+		return err
+	}
+	if nrows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if nrows == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+type MockUserTwoFactor struct {
+	GetTOTP                  func(ctx context.Context, userID int32) (*UserTOTP, error)
+	SetPendingTOTP           func(ctx context.Context, userID int32, encryptedSecret []byte) error
+	EnableTOTP               func(ctx context.Context, userID int32, step int64) error
+	UseTOTPStep              func(ctx context.Context, userID int32, step int64) (bool, error)
+	DeleteTOTP               func(ctx context.Context, userID int32) error
+	SetRecoveryCodes         func(ctx context.Context, userID int32, codeHashes []string) error
+	UseRecoveryCode          func(ctx context.Context, userID int32, codeHash string) (bool, error)
+	CountRecoveryCodes       func(ctx context.Context, userID int32) (int, error)
+	CreateWebAuthnCredential func(ctx context.Context, c *UserWebAuthnCredential) error
+	ListWebAuthnCredentials  func(ctx context.Context, userID int32) ([]*UserWebAuthnCredential, error)
+	UpdateWebAuthnSignCount  func(ctx context.Context, id int32, signCount int64) error
+	DeleteWebAuthnCredential func(ctx context.Context, userID, id int32) error
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestUserTwoFactor_TOTP(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if totp, err := UserTwoFactor.GetTOTP(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if totp != nil {
+		t.Fatalf("got TOTP %+v, want nil", totp)
+	}
+
+	if err := UserTwoFactor.EnableTOTP(ctx, user.ID, 1); err == nil {
+		t.Error("EnableTOTP without a pending secret: want error")
+	}
+	if err := UserTwoFactor.SetPendingTOTP(ctx, user.ID, []byte("s1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := UserTwoFactor.SetPendingTOTP(ctx, user.ID, []byte("s2")); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := UserTwoFactor.UseTOTPStep(ctx, user.ID, 10); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Error("UseTOTPStep before TOTP is enabled: got ok, want !ok")
+	}
+	if err := UserTwoFactor.EnableTOTP(ctx, user.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	totp, err := UserTwoFactor.GetTOTP(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(totp.EncryptedSecret) != "s2" || totp.EnabledAt == nil || totp.LastUsedStep != 10 {
+		t.Errorf("got TOTP %+v", totp)
+	}
+	if err := UserTwoFactor.SetPendingTOTP(ctx, user.ID, []byte("s3")); err == nil {
+		t.Error("SetPendingTOTP with TOTP enabled: want error")
+	}
+
+	for _, test := range []struct {
+		step   int64
+		wantOK bool
+	}{{10, false}, {9, false}, {11, true}, {11, false}} {
+		if ok, err := UserTwoFactor.UseTOTPStep(ctx, user.ID, test.step); err != nil {
+			t.Fatal(err)
+		} else if ok != test.wantOK {
+			t.Errorf("UseTOTPStep(%d): got %v, want %v", test.step, ok, test.wantOK)
+		}
+	}
+
+	if err := UserTwoFactor.DeleteTOTP(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if totp, err := UserTwoFactor.GetTOTP(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if totp != nil {
+		t.Errorf("got TOTP %+v after deletion, want nil", totp)
+	}
+}
+
+func TestUserTwoFactor_RecoveryCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UserTwoFactor.SetRecoveryCodes(ctx, user.ID, []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := UserTwoFactor.UseRecoveryCode(ctx, user.ID, "a"); err != nil || !ok {
+		t.Fatalf("UseRecoveryCode(a): got %v, %v, want true", ok, err)
+	}
+	if ok, err := UserTwoFactor.UseRecoveryCode(ctx, user.ID, "a"); err != nil || ok {
+		t.Fatalf("UseRecoveryCode(a) again: got %v, %v, want false", ok, err)
+	}
+	if count, err := UserTwoFactor.CountRecoveryCodes(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Errorf("got %d recovery codes, want 1", count)
+	}
+	if err := UserTwoFactor.SetRecoveryCodes(ctx, user.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := UserTwoFactor.CountRecoveryCodes(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Errorf("got %d recovery codes, want 0", count)
+	}
+}
+
+func TestUserTwoFactor_WebAuthnCredentials(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &UserWebAuthnCredential{UserID: user.ID, Name: "key", CredentialID: []byte("id"), PublicKey: []byte("pk"), SignCount: 1}
+	if err := UserTwoFactor.CreateWebAuthnCredential(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := UserTwoFactor.CreateWebAuthnCredential(ctx, &UserWebAuthnCredential{UserID: user.ID, Name: "dup", CredentialID: []byte("id"), PublicKey: []byte("pk")}); err == nil {
+		t.Error("CreateWebAuthnCredential with duplicate credential ID: want error")
+	}
+	if err := UserTwoFactor.UpdateWebAuthnSignCount(ctx, c.ID, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := UserTwoFactor.ListWebAuthnCredentials(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || cs[0].Name != "key" || cs[0].SignCount != 5 || cs[0].LastUsedAt == nil {
+		t.Fatalf("got credentials %+v", cs)
+	}
+
+	if err := UserTwoFactor.DeleteWebAuthnCredential(ctx, user.ID+1, c.ID); err != ErrWebAuthnCredentialNotFound {
+		t.Errorf("DeleteWebAuthnCredential of other user: got error %v, want %v", err, ErrWebAuthnCredentialNotFound)
+	}
+	if err := UserTwoFactor.DeleteWebAuthnCredential(ctx, user.ID, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if cs, err := UserTwoFactor.ListWebAuthnCredentials(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if len(cs) != 0 {
+		t.Errorf("got %d credentials after deletion, want 0", len(cs))
+	}
+}
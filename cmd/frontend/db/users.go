@@ -546,10 +546,36 @@ func (u *users) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool)
 	if Mocks.Users.SetIsSiteAdmin != nil {
 		return Mocks.Users.SetIsSiteAdmin(id, isSiteAdmin)
 	}
-	_, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET site_admin=$1 WHERE id=$2", isSiteAdmin, id)
+	_, err := dbconn.FromContext(ctx).ExecContext(ctx, "UPDATE users SET site_admin=$1 WHERE id=$2", isSiteAdmin, id)
 	return err
 }
 
+// SetDeactivated deactivates or reactivates the user. Deactivated users may not sign in or use
+// access tokens, but their data is kept.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (u *users) SetDeactivated(ctx context.Context, id int32, deactivated bool) error {
+	if Mocks.Users.SetDeactivated != nil {
+		return Mocks.Users.SetDeactivated(id, deactivated)
+	}
+	q := "UPDATE users SET deactivated_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NULL"
+	if deactivated {
+		q = "UPDATE users SET deactivated_at=COALESCE(deactivated_at, now()), updated_at=now() WHERE id=$1 AND deleted_at IS NULL"
+	}
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	return nil
+}
+
 // CheckAndDecrementInviteQuota should be called before the user (identified
 // by userID) is allowed to invite any other user. If ok is false, then the
 // user is not allowed to invite any other user (either because they've
@@ -726,7 +752,7 @@ func (u *users) getOneBySQL(ctx context.Context, query string, args ...interface
 
 // getBySQL returns users matching the SQL query, if any exist.
 func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*types.User, error) {
-	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags FROM users u "+query, args...)
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags, u.deactivated_at IS NOT NULL FROM users u "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -736,7 +762,7 @@ func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags))
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.Deactivated)
 		if err != nil {
 			return nil, err
 		}
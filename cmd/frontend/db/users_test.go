@@ -635,6 +635,52 @@ func TestUsers_Delete(t *testing.T) {
 	}
 }
 
+// 🚨 SECURITY: This tests that deactivated users can't use their access tokens.
+func TestUsers_SetDeactivated(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := AccessTokens.Create(ctx, user.ID, []string{"a"}, "n", user.ID, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Users.SetDeactivated(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if !user.Deactivated {
+		t.Error("got user not deactivated, want deactivated")
+	}
+	if _, err := AccessTokens.Lookup(ctx, token, []string{"a"}); err != ErrAccessTokenNotFound {
+		t.Errorf("got error %v for access token of deactivated user, want %v", err, ErrAccessTokenNotFound)
+	}
+
+	if err := Users.SetDeactivated(ctx, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if user, err := Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if user.Deactivated {
+		t.Error("got user deactivated, want reactivated")
+	}
+	if _, err := AccessTokens.Lookup(ctx, token, []string{"a"}); err != nil {
+		t.Errorf("got error %v for access token of reactivated user, want nil", err)
+	}
+
+	if err := Users.SetDeactivated(ctx, 1234, true); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v for nonexistent user, want not found", err)
+	}
+}
+
 func normalizeUsers(users []*types.User) []*types.User {
 	for _, u := range users {
 		u.CreatedAt = u.CreatedAt.Local().Round(time.Second)
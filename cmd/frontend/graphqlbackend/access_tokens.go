@@ -2,10 +2,12 @@ package graphqlbackend
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -17,9 +19,11 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User         graphql.ID
+	Scopes       []string
+	Note         string
+	ExpiresAt    *DateTime
+	Repositories *[]string
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -46,18 +50,24 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var hasUserScope bool
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
-		case authz.ScopeUserAll:
-			hasUserAllScope = true
+		case authz.ScopeUserAll, authz.ScopeSearchRead, authz.ScopeRepoRead, authz.ScopeCampaignsWrite, authz.ScopeSettingsRead:
+			hasUserScope = true
 		case authz.ScopeSiteAdminSudo:
 			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
 				return nil, err
 			}
+		case authz.ScopeSiteAdminSCIM:
+			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:scim" scope.
+			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+				return nil, err
+			}
+			hasUserScope = true
 		default:
 			return nil, fmt.Errorf("unknown access token scope %q (valid scopes: %q)", scope, authz.AllScopes)
 		}
@@ -67,12 +77,58 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, fmt.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if !hasUserScope {
+		return nil, fmt.Errorf("access tokens must have at least one of the scopes %q or %q", authz.UserScopes, authz.ScopeSiteAdminSCIM)
+	}
+
+	var expiresAt *time.Time
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.After(time.Now()) {
+			return nil, errors.New("access token expiry time must be in the future")
+		}
+		expiresAt = &args.ExpiresAt.Time
+	}
+
+	var repos []string
+	if args.Repositories != nil {
+		if len(*args.Repositories) == 0 {
+			return nil, errors.New("access token repositories must be omitted or non-empty")
+		}
+		repos = *args.Repositories
 	}
 
-	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
-	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, err
+	// The token's secret value is never recorded in the audit log.
+	after, err := json.Marshal(struct {
+		Subject      graphql.ID `json:"subject"`
+		Scopes       []string   `json:"scopes"`
+		Note         string     `json:"note"`
+		ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+		Repositories []string   `json:"repositories,omitempty"`
+	}{args.User, args.Scopes, args.Note, expiresAt, repos})
+	if err != nil {
+		return nil, err
+	}
+	afterStr := string(after)
+	var (
+		id    int64
+		token string
+	)
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		var err error
+		id, token, err = db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, expiresAt, repos)
+		if err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionAccessTokenCreate,
+			TargetType: "AccessToken",
+			TargetID:   string(marshalAccessTokenID(id)),
+			After:      &afterStr,
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, nil
 }
 
 type createAccessTokenResult struct {
@@ -96,14 +152,17 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		return nil, errors.New("exactly one of byID or byToken must be specified")
 	}
 
-	var token *db.AccessToken
+	var (
+		targetID    graphql.ID
+		deleteToken func(ctx context.Context) error
+	)
 	switch {
 	case args.ByID != nil:
 		accessTokenID, err := unmarshalAccessTokenID(*args.ByID)
 		if err != nil {
 			return nil, err
 		}
-		token, err = db.AccessTokens.GetByID(ctx, accessTokenID)
+		token, err := db.AccessTokens.GetByID(ctx, accessTokenID)
 		if err != nil {
 			return nil, err
 		}
@@ -112,18 +171,31 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		if err := backend.CheckSiteAdminOrSameUser(ctx, token.SubjectUserID); err != nil {
 			return nil, err
 		}
-		if err := db.AccessTokens.DeleteByID(ctx, token.ID, token.SubjectUserID); err != nil {
-			return nil, err
+		deleteToken = func(ctx context.Context) error {
+			return db.AccessTokens.DeleteByID(ctx, token.ID, token.SubjectUserID)
 		}
+		targetID = *args.ByID
 
 	case args.ByToken != nil:
 		// 🚨 SECURITY: This is easier than the ByID case because anyone holding the access token's
 		// secret value is assumed to be allowed to delete it.
-		if err := db.AccessTokens.DeleteByToken(ctx, *args.ByToken); err != nil {
-			return nil, err
+		deleteToken = func(ctx context.Context) error {
+			return db.AccessTokens.DeleteByToken(ctx, *args.ByToken)
 		}
 	}
 
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := deleteToken(ctx); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionAccessTokenDelete,
+			TargetType: "AccessToken",
+			TargetID:   string(targetID),
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
 	return &EmptyResponse{}, nil
 }
 
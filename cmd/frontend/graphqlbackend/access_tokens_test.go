@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/gqltesting"
@@ -17,7 +18,7 @@ import (
 // 🚨 SECURITY: This tests that users can't create tokens for users they aren't allowed to do so for.
 func TestMutation_CreateAccessToken(t *testing.T) {
 	mockAccessTokensCreate := func(t *testing.T, wantCreatorUserID int32, wantScopes []string) {
-		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (int64, string, error) {
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time, repos []string) (int64, string, error) {
 			if want := int32(1); subjectUserID != want {
 				t.Errorf("got %v, want %v", subjectUserID, want)
 			}
@@ -32,6 +33,12 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 			}
 			return 1, "t", nil
 		}
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			if e.Action != backend.AuditActionAccessTokenCreate || e.TargetID != "QWNjZXNzVG9rZW46MQ==" {
+				t.Errorf("unexpected audit log entry %+v", e)
+			}
+			return nil
+		}
 	}
 
 	const uid1GQLID = "VXNlcjox"
@@ -76,6 +83,93 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, using fine-grained scopes with an expiry and repositories", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time, repos []string) (int64, string, error) {
+			if want := []string{authz.ScopeRepoRead, authz.ScopeSearchRead}; !reflect.DeepEqual(scopes, want) {
+				t.Errorf("got scopes %q, want %q", scopes, want)
+			}
+			if want := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC); expiresAt == nil || !expiresAt.Equal(want) {
+				t.Errorf("got expiry %v, want %v", expiresAt, want)
+			}
+			if want := []string{"github.com/foo/bar"}; !reflect.DeepEqual(repos, want) {
+				t.Errorf("got repos %q, want %q", repos, want)
+			}
+			return 1, "t", nil
+		}
+		var auditLogEntry *db.AuditLogEntry
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			auditLogEntry = e
+			return nil
+		}
+		gqltesting.RunTests(t, []*gqltesting.Test{
+			{
+				Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+				Schema:  mustParseGraphQLSchema(t),
+				Query: `
+				mutation {
+					createAccessToken(user: "` + uid1GQLID + `", scopes: ["search:read", "repo:read"], note: "n", expiresAt: "2100-01-01T00:00:00Z", repositories: ["github.com/foo/bar"]) {
+						id
+						token
+					}
+				}
+			`,
+				ExpectedResult: `
+				{
+					"createAccessToken": {
+						"id": "QWNjZXNzVG9rZW46MQ==",
+						"token": "t"
+					}
+				}
+			`,
+			},
+		})
+		// 🚨 SECURITY: The token's secret value must not be recorded in the audit log.
+		if auditLogEntry == nil {
+			t.Fatal("no audit log entry recorded")
+		}
+		if want := `{"subject":"VXNlcjox","scopes":["repo:read","search:read"],"note":"n","expiresAt":"2100-01-01T00:00:00Z","repositories":["github.com/foo/bar"]}`; *auditLogEntry.After != want {
+			t.Errorf("got audit log entry after %s, want %s", *auditLogEntry.After, want)
+		}
+	})
+
+	t.Run("authenticated as user, using an expiry in the past", func(t *testing.T) {
+		resetMocks()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeUserAll},
+			Note:      "n",
+			ExpiresAt: &DateTime{Time: time.Now().Add(-time.Hour)},
+		})
+		if err == nil {
+			t.Error("err == nil")
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
+	// 🚨 SECURITY: A token with narrower scopes must not be able to create a token with more scopes.
+	t.Run("authenticated with an access token without the user:all scope", func(t *testing.T) {
+		resetMocks()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		ctx = authz.WithTokenScopes(ctx, &authz.TokenScopes{Scopes: []string{authz.ScopeSearchRead}})
+		result, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeUserAll},
+			Note:   "n",
+		})
+		if _, ok := err.(*authz.ErrScopeRequired); !ok {
+			t.Errorf("got err %v, want *authz.ErrScopeRequired", err)
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
 	t.Run("authenticated as user, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
@@ -220,6 +314,12 @@ func TestMutation_DeleteAccessToken(t *testing.T) {
 			}
 			return &db.AccessToken{ID: 1, SubjectUserID: 2}, nil
 		}
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			if e.Action != backend.AuditActionAccessTokenDelete || e.TargetID != "QWNjZXNzVG9rZW46MQ==" {
+				t.Errorf("unexpected audit log entry %+v", e)
+			}
+			return nil
+		}
 	}
 
 	token1GQLID := graphql.ID("QWNjZXNzVG9rZW46MQ==")
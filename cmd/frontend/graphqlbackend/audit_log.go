@@ -0,0 +1,182 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func (r *siteResolver) AuditLog(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+	Actor      *graphql.ID
+	Action     *string
+	TargetType *string
+	TargetID   *string
+	Since      *DateTime
+	Until      *DateTime
+}) (*auditLogEntryConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins can read the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var opt db.AuditLogListOptions
+	if args.Actor != nil {
+		var err error
+		opt.ActorUserID, err = UnmarshalUserID(*args.Actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.Action != nil {
+		opt.Action = *args.Action
+	}
+	if args.TargetType != nil {
+		opt.TargetType = *args.TargetType
+	}
+	if args.TargetID != nil {
+		opt.TargetID = *args.TargetID
+	}
+	if args.Since != nil {
+		opt.After = args.Since.Time
+	}
+	if args.Until != nil {
+		opt.Before = args.Until.Time
+	}
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &auditLogEntryConnectionResolver{opt: opt}, nil
+}
+
+func (r *siteResolver) AuditLogVerification(ctx context.Context) (*auditLogVerificationResolver, error) {
+	// 🚨 SECURITY: Only site admins can read the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	firstInvalid, err := db.AuditLog.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLogVerificationResolver{firstInvalid: firstInvalid}, nil
+}
+
+// auditLogEntryConnectionResolver resolves a list of audit log entries.
+//
+// 🚨 SECURITY: When instantiating an auditLogEntryConnectionResolver value, the caller MUST check
+// permissions.
+type auditLogEntryConnectionResolver struct {
+	opt db.AuditLogListOptions
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	entries []*db.AuditLogEntry
+	err     error
+}
+
+func (r *auditLogEntryConnectionResolver) compute(ctx context.Context) ([]*db.AuditLogEntry, error) {
+	r.once.Do(func() {
+		opt2 := r.opt
+		if opt2.LimitOffset != nil {
+			tmp := *opt2.LimitOffset
+			opt2.LimitOffset = &tmp
+			opt2.Limit++ // so we can detect if there is a next page
+		}
+
+		r.entries, r.err = db.AuditLog.List(ctx, opt2)
+	})
+	return r.entries, r.err
+}
+
+func (r *auditLogEntryConnectionResolver) Nodes(ctx context.Context) ([]*auditLogEntryResolver, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(entries) > r.opt.LimitOffset.Limit {
+		entries = entries[:r.opt.LimitOffset.Limit]
+	}
+
+	l := make([]*auditLogEntryResolver, 0, len(entries))
+	for _, entry := range entries {
+		l = append(l, &auditLogEntryResolver{entry: entry})
+	}
+	return l, nil
+}
+
+func (r *auditLogEntryConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.AuditLog.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *auditLogEntryConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && len(entries) > r.opt.Limit), nil
+}
+
+// auditLogEntryResolver resolves an audit log entry.
+//
+// 🚨 SECURITY: When instantiating an auditLogEntryResolver value, the caller MUST check permissions.
+type auditLogEntryResolver struct {
+	entry *db.AuditLogEntry
+}
+
+func marshalAuditLogEntryID(id int64) graphql.ID { return relay.MarshalID("AuditLogEntry", id) }
+
+func (r *auditLogEntryResolver) ID() graphql.ID { return marshalAuditLogEntryID(r.entry.ID) }
+
+func (r *auditLogEntryResolver) Timestamp() DateTime { return DateTime{Time: r.entry.Timestamp} }
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (*UserResolver, error) {
+	if r.entry.ActorUserID == 0 {
+		return nil, nil
+	}
+	user, err := UserByIDInt32(ctx, r.entry.ActorUserID)
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *auditLogEntryResolver) ActorIP() *string { return nonEmptyStringPtr(r.entry.ActorIP) }
+
+func (r *auditLogEntryResolver) Action() string { return r.entry.Action }
+
+func (r *auditLogEntryResolver) TargetType() string { return r.entry.TargetType }
+
+func (r *auditLogEntryResolver) TargetID() *string { return nonEmptyStringPtr(r.entry.TargetID) }
+
+func (r *auditLogEntryResolver) Before() *string { return r.entry.Before }
+
+func (r *auditLogEntryResolver) After() *string { return r.entry.After }
+
+func (r *auditLogEntryResolver) Hash() string { return hex.EncodeToString(r.entry.Hash) }
+
+type auditLogVerificationResolver struct {
+	firstInvalid *db.AuditLogEntry
+}
+
+func (r *auditLogVerificationResolver) Valid() bool { return r.firstInvalid == nil }
+
+func (r *auditLogVerificationResolver) FirstInvalidEntry() *auditLogEntryResolver {
+	if r.firstInvalid == nil {
+		return nil
+	}
+	return &auditLogEntryResolver{entry: r.firstInvalid}
+}
+
+func nonEmptyStringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
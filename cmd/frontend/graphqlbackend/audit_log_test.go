@@ -0,0 +1,128 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// 🚨 SECURITY: This tests that only site admins can read the audit log.
+func TestSite_AuditLog(t *testing.T) {
+	t.Run("authenticated as non-admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1}, nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&siteResolver{}).AuditLog(ctx, nil); err != backend.ErrMustBeSiteAdmin {
+			t.Errorf("got err %v, want %v", err, backend.ErrMustBeSiteAdmin)
+		}
+		if _, err := (&siteResolver{}).AuditLogVerification(ctx); err != backend.ErrMustBeSiteAdmin {
+			t.Errorf("got err %v, want %v", err, backend.ErrMustBeSiteAdmin)
+		}
+	})
+
+	t.Run("authenticated as site admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+			return &types.User{ID: id, Username: "alice"}, nil
+		}
+		since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		wantOpt := func(t *testing.T, opt db.AuditLogListOptions) {
+			if opt.ActorUserID != 2 || opt.Action != backend.AuditActionSiteConfigUpdate || !opt.After.Equal(since) {
+				t.Errorf("got unexpected options %+v", opt)
+			}
+		}
+		after := `{"a": 1}`
+		db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+			wantOpt(t, opt)
+			return []*db.AuditLogEntry{{
+				ID:          1,
+				Timestamp:   time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				ActorUserID: 2,
+				ActorIP:     "10.0.0.1",
+				Action:      backend.AuditActionSiteConfigUpdate,
+				TargetType:  "Site",
+				TargetID:    "U2l0ZToic2l0ZSI=",
+				After:       &after,
+				Hash:        []byte{0xab, 0xcd},
+			}}, nil
+		}
+		db.Mocks.AuditLog.Count = func(opt db.AuditLogListOptions) (int, error) {
+			wantOpt(t, opt)
+			return 1, nil
+		}
+		db.Mocks.AuditLog.Verify = func() (*db.AuditLogEntry, error) {
+			return nil, nil
+		}
+
+		gqltesting.RunTests(t, []*gqltesting.Test{
+			{
+				Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+				Schema:  mustParseGraphQLSchema(t),
+				Query: `
+				{
+					site {
+						auditLog(first: 10, actor: "VXNlcjoy", action: "site.configuration.update", since: "2020-01-01T00:00:00Z") {
+							nodes {
+								timestamp
+								actor { username }
+								actorIP
+								action
+								targetType
+								targetID
+								before
+								after
+								hash
+							}
+							totalCount
+							pageInfo { hasNextPage }
+						}
+						auditLogVerification {
+							valid
+							firstInvalidEntry { id }
+						}
+					}
+				}
+			`,
+				ExpectedResult: `
+				{
+					"site": {
+						"auditLog": {
+							"nodes": [
+								{
+									"timestamp": "2020-01-02T00:00:00Z",
+									"actor": { "username": "alice" },
+									"actorIP": "10.0.0.1",
+									"action": "site.configuration.update",
+									"targetType": "Site",
+									"targetID": "U2l0ZToic2l0ZSI=",
+									"before": null,
+									"after": "{\"a\": 1}",
+									"hash": "abcd"
+								}
+							],
+							"totalCount": 1,
+							"pageInfo": { "hasNextPage": false }
+						},
+						"auditLogVerification": {
+							"valid": true,
+							"firstInvalidEntry": null
+						}
+					}
+				}
+			`,
+			},
+		})
+	})
+}
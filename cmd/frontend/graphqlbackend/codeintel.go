@@ -86,6 +86,8 @@ type LSIFQueryResolver interface {
 	Definitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error)
 	References(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error)
 	Hover(ctx context.Context, args *LSIFQueryPositionArgs) (HoverResolver, error)
+	Implementations(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error)
+	TypeDefinitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error)
 }
 
 type LSIFQueryArgs struct {
@@ -93,6 +95,10 @@ type LSIFQueryArgs struct {
 	Commit     GitObjectID
 	Path       string
 	UploadID   int64
+
+	// GoFallback is whether to return a resolver for Go files without an
+	// LSIF upload.
+	GoFallback bool
 }
 
 type LSIFQueryPositionArgs struct {
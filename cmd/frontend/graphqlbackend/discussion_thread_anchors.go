@@ -0,0 +1,240 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// The possible values of DiscussionThreadAnchor.status.
+const (
+	discussionThreadAnchorExact    = "EXACT"
+	discussionThreadAnchorMoved    = "MOVED"
+	discussionThreadAnchorOutdated = "OUTDATED"
+)
+
+type discussionThreadAnchorResolver struct {
+	path      *string
+	selection *discussionSelectionRangeResolver
+	status    string
+}
+
+func (r *discussionThreadAnchorResolver) Path() *string { return r.path }
+func (r *discussionThreadAnchorResolver) Selection() *discussionSelectionRangeResolver {
+	return r.selection
+}
+func (r *discussionThreadAnchorResolver) Status() string { return r.status }
+
+func (r *discussionThreadTargetRepoResolver) RelativeAnchor(ctx context.Context, args *struct {
+	Rev string
+}) (*discussionThreadAnchorResolver, error) {
+	if r.t.Path == nil || !r.t.HasSelection() {
+		return nil, nil
+	}
+	repo, err := RepositoryByIDInt32(ctx, r.t.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.Commit(ctx, &RepositoryCommitArgs{Rev: args.Rev})
+	if err != nil || commit == nil {
+		return nil, err
+	}
+	outdated := &discussionThreadAnchorResolver{status: discussionThreadAnchorOutdated}
+
+	path := *r.t.Path
+	var hunks []*diff.Hunk
+	if rev := r.t.Revision; rev != nil || r.t.Branch != nil {
+		if rev == nil {
+			rev = r.t.Branch
+		}
+		origCommit, err := repo.Commit(ctx, &RepositoryCommitArgs{Rev: *rev})
+		if err != nil {
+			return nil, err
+		}
+		if origCommit != nil && origCommit.OID() != commit.OID() {
+			fileDiff, err := discussionThreadFileDiff(ctx, repo, origCommit.OID(), commit.OID(), path)
+			if err != nil {
+				return nil, err
+			}
+			if fileDiff != nil {
+				newPath := diffPathOrNull(fileDiff.NewName)
+				if newPath == nil {
+					// The file was removed.
+					return outdated, nil
+				}
+				path = *newPath
+				hunks = fileDiff.Hunks
+			}
+		}
+	}
+
+	file, err := commit.File(ctx, &struct{ Path string }{Path: path})
+	if os.IsNotExist(err) {
+		// The file does not exist in this revision.
+		return outdated, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.Content(ctx)
+	if err != nil {
+		return nil, err
+	}
+	selection, status := relocateDiscussionSelection(r.t, hunks, content)
+	return &discussionThreadAnchorResolver{path: &path, selection: selection, status: status}, nil
+}
+
+// discussionThreadFileDiff returns the diff of the file at path between the
+// given commits, or nil if the file did not change. If the file was renamed,
+// the diff is between path and its new name.
+func discussionThreadFileDiff(ctx context.Context, repo *RepositoryResolver, from, to GitObjectID, path string) (*diff.FileDiff, error) {
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo.repo)
+	if err != nil {
+		return nil, err
+	}
+	rangeSpec := string(from) + ".." + string(to)
+	if strings.HasPrefix(rangeSpec, "-") || strings.HasPrefix(rangeSpec, ".") {
+		// This should not be possible since both are OIDs, but be extra
+		// careful to avoid letting user input add `git diff` flags.
+		return nil, fmt.Errorf("invalid diff range argument: %q", rangeSpec)
+	}
+
+	fileDiff, err := discussionThreadPathsDiff(ctx, *cachedRepo, rangeSpec, path, path)
+	if err != nil || fileDiff == nil || diffPathOrNull(fileDiff.NewName) != nil {
+		return fileDiff, err
+	}
+
+	// The file was removed from path. Renames are only detected when both
+	// names are diffed, so we look for a rename of path among the names of
+	// all changed files first.
+	rdr, err := git.ExecReader(ctx, *cachedRepo, []string{
+		"diff",
+		"--find-renames",
+		"--name-status",
+		"--diff-filter=R",
+		"-z",
+		rangeSpec,
+		"--",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+	out, err := ioutil.ReadAll(rdr)
+	if err != nil {
+		return nil, err
+	}
+	// Each rename is printed as "R<score>\x00<old name>\x00<new name>\x00".
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+1] == path {
+			return discussionThreadPathsDiff(ctx, *cachedRepo, rangeSpec, path, fields[i+2])
+		}
+	}
+	return fileDiff, nil
+}
+
+// discussionThreadPathsDiff returns the diff of the file at oldPath in
+// rangeSpec, limited to the files at oldPath and newPath. It returns nil if
+// the file did not change.
+func discussionThreadPathsDiff(ctx context.Context, repo gitserver.Repo, rangeSpec, oldPath, newPath string) (*diff.FileDiff, error) {
+	args := []string{
+		"diff",
+		"--find-renames",
+		"--full-index",
+		"--unified=0",
+		"--no-prefix",
+		rangeSpec,
+		"--",
+		// The paths are matched literally, not as patterns.
+		":(literal)" + oldPath,
+	}
+	if newPath != oldPath {
+		args = append(args, ":(literal)"+newPath)
+	}
+	rdr, err := git.ExecReader(ctx, repo, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+
+	dr := diff.NewMultiFileDiffReader(rdr)
+	for {
+		fileDiff, err := dr.ReadFile()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fileDiff.OrigName == oldPath {
+			return fileDiff, nil
+		}
+	}
+}
+
+// relocateDiscussionSelection locates the selection of t in newContent.
+// hunks are the hunks of the diff between the revision the thread was
+// created on and the revision of newContent, if known. The selected lines are
+// followed through the diff first. If they changed, or no diff is known, we
+// fall back to searching newContent for the lines and their context.
+func relocateDiscussionSelection(t *types.DiscussionThreadTargetRepo, hunks []*diff.Hunk, newContent string) (*discussionSelectionRangeResolver, string) {
+	oldRange := discussions.LineRange{StartLine: int(*t.StartLine), EndLine: int(*t.EndLine)}
+	relocated, unchanged := discussions.RelocateLineRange(hunks, oldRange)
+
+	// The diff may not tell the whole story, e.g. if the thread was created
+	// on a branch which has since moved, so we also check the content.
+	if unchanged && t.Lines != nil {
+		_, lines, _ := discussions.LinesForSelection(newContent, relocated)
+		unchanged = equalStrings(lines, *t.Lines)
+	}
+	if unchanged {
+		status := discussionThreadAnchorMoved
+		if relocated == oldRange {
+			status = discussionThreadAnchorExact
+		}
+		return discussionSelectionRange(t, relocated), status
+	}
+
+	if t.LinesBefore != nil && t.Lines != nil && t.LinesAfter != nil {
+		if sel := discussionSelectionRelativeTo(t, newContent); sel != nil {
+			status := discussionThreadAnchorMoved
+			if int(sel.startLine) == oldRange.StartLine && int(sel.endLine) == oldRange.EndLine {
+				status = discussionThreadAnchorExact
+			}
+			return sel, status
+		}
+	}
+	return discussionSelectionRange(t, relocated), discussionThreadAnchorOutdated
+}
+
+func discussionSelectionRange(t *types.DiscussionThreadTargetRepo, r discussions.LineRange) *discussionSelectionRangeResolver {
+	return &discussionSelectionRangeResolver{
+		startLine:      int32(r.StartLine),
+		startCharacter: *t.StartCharacter,
+		endLine:        int32(r.EndLine),
+		endCharacter:   *t.EndCharacter,
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -569,51 +569,14 @@ func discussionSelectionRelativeTo(oldSel *types.DiscussionThreadTargetRepo, new
 func (r *discussionThreadTargetRepoResolver) RelativeSelection(ctx context.Context, args *struct {
 	Rev string
 }) (*discussionSelectionRangeResolver, error) {
-	if !r.t.HasSelection() {
-		return nil, nil
-	}
-	path, err := r.RelativePath(ctx, args)
-	if err != nil {
+	anchor, err := r.RelativeAnchor(ctx, args)
+	if err != nil || anchor == nil {
 		return nil, err
 	}
-	if path == nil {
+	if anchor.status == discussionThreadAnchorOutdated {
 		return nil, nil
 	}
-	repo, err := RepositoryByIDInt32(ctx, r.t.RepoID)
-	if err != nil {
-		return nil, err
-	}
-	commit, err := repo.Commit(ctx, &RepositoryCommitArgs{Rev: args.Rev})
-	if err != nil {
-		return nil, err
-	}
-	oldSel := &discussionSelectionRangeResolver{
-		startLine:      *r.t.StartLine,
-		startCharacter: *r.t.StartCharacter,
-		endLine:        *r.t.EndLine,
-		endCharacter:   *r.t.EndCharacter,
-	}
-	if r.t.Revision != nil && *r.t.Revision == string(commit.OID()) {
-		return oldSel, nil // nothing to do (requested relative revision is identical to the stored revision)
-	}
-	if r.t.Branch != nil {
-		branchCommit, err := repo.Commit(ctx, &RepositoryCommitArgs{Rev: *r.t.Branch})
-		if err != nil {
-			return nil, err
-		}
-		if branchCommit.OID() == commit.OID() {
-			return oldSel, nil // nothing to do (requested relative revision is identical to the stored branch revision)
-		}
-	}
-	file, err := commit.File(ctx, &struct{ Path string }{Path: *path})
-	if err != nil {
-		return nil, err
-	}
-	newContent, err := file.Content(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return discussionSelectionRelativeTo(r.t, newContent), nil
+	return anchor.selection, nil
 }
 
 type discussionThreadTargetResolver struct {
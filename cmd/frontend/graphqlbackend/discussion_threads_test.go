@@ -2,13 +2,18 @@ package graphqlbackend
 
 import (
 	"context"
+	"errors"
+	"os"
 	"reflect"
 	"testing"
 
 	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/go-diff/diff"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
 func TestDiscussionThread_Get(t *testing.T) {
@@ -137,6 +142,70 @@ func TestDiscussionSelectionRelativeTo(t *testing.T) {
 	}
 }
 
+func TestRelocateDiscussionSelection(t *testing.T) {
+	i32 := func(i int32) *int32 {
+		return &i
+	}
+	thread := &types.DiscussionThreadTargetRepo{
+		StartLine: i32(3), StartCharacter: i32(0), EndLine: i32(4), EndCharacter: i32(1),
+		LinesBefore: &[]string{"0", "1", "2"},
+		Lines:       &[]string{"3"},
+		LinesAfter:  &[]string{"4", "5", "6"},
+	}
+	hunks := func(fileDiff string) []*diff.Hunk {
+		fd, err := diff.ParseFileDiff([]byte(fileDiff))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fd.Hunks
+	}
+
+	tests := []struct {
+		name       string
+		hunks      []*diff.Hunk
+		newContent string
+		want       *discussionSelectionRangeResolver
+		wantStatus string
+	}{
+		{
+			name:       "unchanged",
+			newContent: "0\n1\n2\n3\n4\n5\n6",
+			want:       &discussionSelectionRangeResolver{startLine: 3, startCharacter: 0, endLine: 4, endCharacter: 1},
+			wantStatus: discussionThreadAnchorExact,
+		},
+		{
+			name:       "moved_by_diff",
+			hunks:      hunks("--- a\n+++ a\n@@ -0,0 +1,2 @@\n+a\n+b\n"),
+			newContent: "a\nb\n0\n1\n2\n3\n4\n5\n6",
+			want:       &discussionSelectionRangeResolver{startLine: 5, startCharacter: 0, endLine: 6, endCharacter: 1},
+			wantStatus: discussionThreadAnchorMoved,
+		},
+		{
+			// Without a diff, the lines are searched for.
+			name:       "moved_without_diff",
+			newContent: "a\nb\nc\n0\n1\n2\n3\n4\n5\n6",
+			want:       &discussionSelectionRangeResolver{startLine: 6, startCharacter: 0, endLine: 7, endCharacter: 1},
+			wantStatus: discussionThreadAnchorMoved,
+		},
+		{
+			name:       "changed",
+			hunks:      hunks("--- a\n+++ a\n@@ -4 +4 @@\n-3\n+x\n"),
+			newContent: "0\n1\n2\nx\n4\n5\n6",
+			want:       &discussionSelectionRangeResolver{startLine: 3, startCharacter: 0, endLine: 4, endCharacter: 1},
+			wantStatus: discussionThreadAnchorOutdated,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			got, status := relocateDiscussionSelection(thread, tst.hunks, tst.newContent)
+			if !reflect.DeepEqual(got, tst.want) || status != tst.wantStatus {
+				t.Logf("got  %+v (%s)\n", got, status)
+				t.Fatalf("want %+v (%s)\n", tst.want, tst.wantStatus)
+			}
+		})
+	}
+}
+
 func TestDiscussionsMutations_UpdateThread(t *testing.T) {
 	resetMocks()
 	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) { return &types.User{}, nil }
@@ -192,3 +261,46 @@ func TestDiscussionsMutations_UpdateThread(t *testing.T) {
 		},
 	})
 }
+
+func TestDiscussionThreadTargetRepo_RelativeAnchor(t *testing.T) {
+	resetMocks()
+	defer git.ResetMocks()
+	db.Mocks.ExternalServices.List = func(opt db.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+		return nil, nil
+	}
+	db.Mocks.Repos.MockGet(t, 1)
+	backend.Mocks.Repos.ResolveRev = func(ctx context.Context, repo *types.Repo, rev string) (api.CommitID, error) {
+		return exampleCommitSHA1, nil
+	}
+	backend.Mocks.Repos.MockGetCommit_Return_NoCheck(t, &git.Commit{ID: exampleCommitSHA1})
+
+	path := "a.go"
+	line, character := int32(1), int32(0)
+	r := &discussionThreadTargetRepoResolver{t: &types.DiscussionThreadTargetRepo{
+		RepoID:         1,
+		Path:           &path,
+		StartLine:      &line,
+		EndLine:        &line,
+		StartCharacter: &character,
+		EndCharacter:   &character,
+	}}
+
+	git.Mocks.Stat = func(commit api.CommitID, path string) (os.FileInfo, error) {
+		return nil, &os.PathError{Op: "ls-tree", Path: path, Err: os.ErrNotExist}
+	}
+	anchor, err := r.RelativeAnchor(context.Background(), &struct{ Rev string }{Rev: "master"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anchor.Status() != discussionThreadAnchorOutdated {
+		t.Errorf("got status %q for a removed file, want %q", anchor.Status(), discussionThreadAnchorOutdated)
+	}
+
+	// Other errors are returned, not reported as an outdated anchor.
+	git.Mocks.Stat = func(commit api.CommitID, path string) (os.FileInfo, error) {
+		return nil, errors.New("gitserver unavailable")
+	}
+	if _, err := r.RelativeAnchor(context.Background(), &struct{ Rev string }{Rev: "master"}); err == nil {
+		t.Error("expected error")
+	}
+}
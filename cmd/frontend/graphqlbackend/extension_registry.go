@@ -42,6 +42,8 @@ type ExtensionRegistryResolver interface {
 	UpdateExtension(context.Context, *ExtensionRegistryUpdateExtensionArgs) (ExtensionRegistryMutationResult, error)
 	PublishExtension(context.Context, *ExtensionRegistryPublishExtensionArgs) (ExtensionRegistryMutationResult, error)
 	DeleteExtension(context.Context, *ExtensionRegistryDeleteExtensionArgs) (*EmptyResponse, error)
+	UpdateExtensionSigningPublicKey(context.Context, *ExtensionRegistryUpdateExtensionSigningPublicKeyArgs) (ExtensionRegistryMutationResult, error)
+	PinExtensionRelease(context.Context, *ExtensionRegistryPinExtensionReleaseArgs) (ExtensionRegistryMutationResult, error)
 	LocalExtensionIDPrefix() *string
 
 	ImplementsLocalExtensionRegistry() bool // not exposed via GraphQL
@@ -76,11 +78,22 @@ type ExtensionRegistryUpdateExtensionArgs struct {
 }
 
 type ExtensionRegistryPublishExtensionArgs struct {
-	ExtensionID string
-	Manifest    string
-	Bundle      *string
-	SourceMap   *string
-	Force       bool
+	ExtensionID     string
+	Manifest        string
+	Bundle          *string
+	SourceMap       *string
+	BundleSignature *string
+	Force           bool
+}
+
+type ExtensionRegistryUpdateExtensionSigningPublicKeyArgs struct {
+	Extension        graphql.ID
+	SigningPublicKey *string
+}
+
+type ExtensionRegistryPinExtensionReleaseArgs struct {
+	Extension graphql.ID
+	Release   *graphql.ID
 }
 
 type ExtensionRegistryDeleteExtensionArgs struct {
@@ -118,6 +131,28 @@ type RegistryExtension interface {
 	IsLocal() bool
 	IsWorkInProgress() bool
 	ViewerCanAdminister(ctx context.Context) (bool, error)
+	SigningPublicKey() *string
+	PinnedRelease(ctx context.Context) (RegistryExtensionRelease, error)
+	Releases(ctx context.Context, args *graphqlutil.ConnectionArgs) (RegistryExtensionReleaseConnection, error)
+}
+
+// RegistryExtensionRelease is the interface for the GraphQL type RegistryExtensionRelease.
+type RegistryExtensionRelease interface {
+	ID() graphql.ID
+	Version() *string
+	Creator(ctx context.Context) (*UserResolver, error)
+	CreatedAt() DateTime
+	BundleURL() (*string, error)
+	Signed() bool
+	Pinned() bool
+}
+
+// RegistryExtensionReleaseConnection is the interface for the GraphQL type
+// RegistryExtensionReleaseConnection.
+type RegistryExtensionReleaseConnection interface {
+	Nodes(context.Context) ([]RegistryExtensionRelease, error)
+	TotalCount(context.Context) (int32, error)
+	PageInfo(context.Context) (*graphqlutil.PageInfo, error)
 }
 
 // ExtensionManifest is the interface for the GraphQL type ExtensionManifest.
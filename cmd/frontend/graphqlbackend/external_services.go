@@ -44,7 +44,17 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
 		Config:      args.Input.Config,
 	}
 
-	if err := db.ExternalServices.Create(ctx, conf.Get, externalService); err != nil {
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := db.ExternalServices.Create(ctx, conf.Get, externalService); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionExternalServiceAdd,
+			TargetType: "ExternalService",
+			TargetID:   string(marshalExternalServiceID(externalService.ID)),
+			After:      backend.RedactSecrets(&externalService.Config),
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 
@@ -80,12 +90,31 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 		return nil, fmt.Errorf("blank external service configuration is invalid (must be valid JSONC)")
 	}
 
+	before, err := db.ExternalServices.GetByID(ctx, externalServiceID)
+	if err != nil {
+		return nil, err
+	}
+
 	ps := conf.Get().AuthProviders
 	update := &db.ExternalServiceUpdate{
 		DisplayName: args.Input.DisplayName,
 		Config:      args.Input.Config,
 	}
-	if err := db.ExternalServices.Update(ctx, ps, externalServiceID, update); err != nil {
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := db.ExternalServices.Update(ctx, ps, externalServiceID, update); err != nil {
+			return nil, err
+		}
+		if args.Input.Config == nil {
+			return nil, nil
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionExternalServiceUpdate,
+			TargetType: "ExternalService",
+			TargetID:   string(args.Input.ID),
+			Before:     backend.RedactSecrets(&before.Config),
+			After:      backend.RedactSecrets(args.Input.Config),
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 
@@ -146,7 +175,17 @@ func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 		return nil, err
 	}
 
-	if err := db.ExternalServices.Delete(ctx, id); err != nil {
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := db.ExternalServices.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionExternalServiceDelete,
+			TargetType: "ExternalService",
+			TargetID:   string(args.ExternalService),
+			Before:     backend.RedactSecrets(&externalService.Config),
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 	now := time.Now()
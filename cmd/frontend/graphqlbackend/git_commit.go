@@ -9,6 +9,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/externallink"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/inventory"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
@@ -243,13 +244,23 @@ func (r *GitCommitResolver) Languages(ctx context.Context) ([]string, error) {
 	return names, nil
 }
 
-func (r *GitCommitResolver) LanguageStatistics(ctx context.Context) ([]*languageStatisticsResolver, error) {
-	inventory, err := backend.Repos.GetInventory(ctx, r.repo.repo, api.CommitID(r.oid), false)
+func (r *GitCommitResolver) LanguageStatistics(ctx context.Context, args *struct {
+	Path *string
+}) ([]*languageStatisticsResolver, error) {
+	var (
+		inv *inventory.Inventory
+		err error
+	)
+	if args.Path == nil || *args.Path == "" {
+		inv, err = backend.Repos.GetInventory(ctx, r.repo.repo, api.CommitID(r.oid), false)
+	} else {
+		inv, err = backend.Repos.GetInventoryForPath(ctx, r.repo.repo, api.CommitID(r.oid), *args.Path, false)
+	}
 	if err != nil {
 		return nil, err
 	}
-	stats := make([]*languageStatisticsResolver, 0, len(inventory.Languages))
-	for _, lang := range inventory.Languages {
+	stats := make([]*languageStatisticsResolver, 0, len(inv.Languages))
+	for _, lang := range inv.Languages {
 		stats = append(stats, &languageStatisticsResolver{
 			l: lang,
 		})
@@ -6,6 +6,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
@@ -20,6 +21,10 @@ type gitCommitConnectionResolver struct {
 
 	repo *RepositoryResolver
 
+	// alternate, if set, is another repository whose objects are available
+	// when listing the commits of repo.
+	alternate *protocol.AlternateRepo
+
 	// cache results because it is used by multiple fields
 	once    sync.Once
 	commits []*git.Commit
@@ -60,6 +65,7 @@ func (r *gitCommitConnectionResolver) compute(ctx context.Context) ([]*git.Commi
 			Author:       author,
 			After:        after,
 			Path:         path,
+			Alternate:    r.alternate,
 		})
 	}
 
@@ -0,0 +1,58 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+func (r *GitTreeEntryResolver) Owners(ctx context.Context) ([]*codeOwnerResolver, error) {
+	rs, err := codeowners.ForCommit(ctx, gitserver.Repo{Name: r.commit.repo.repo.Name}, api.CommitID(r.commit.oid))
+	if err != nil {
+		return nil, err
+	}
+	owners := rs.Match(r.Path())
+	resolvers := make([]*codeOwnerResolver, len(owners))
+	for i, owner := range owners {
+		resolvers[i] = &codeOwnerResolver{owner: owner}
+	}
+	return resolvers, nil
+}
+
+type codeOwnerResolver struct {
+	owner codeowners.Owner
+}
+
+func (r *codeOwnerResolver) Name() string { return r.owner.Name }
+func (r *codeOwnerResolver) IsTeam() bool { return r.owner.IsTeam }
+
+// User resolves the owner to a user, by username or by verified email
+// address. Not all owners can be resolved to a user.
+func (r *codeOwnerResolver) User(ctx context.Context) (*UserResolver, error) {
+	if r.owner.IsTeam {
+		return nil, nil
+	}
+
+	var (
+		user *types.User
+		err  error
+	)
+	if strings.HasPrefix(r.owner.Name, "@") {
+		user, err = db.Users.GetByUsername(ctx, r.owner.Handle())
+	} else {
+		user, err = db.Users.GetByVerifiedEmail(ctx, r.owner.Name)
+	}
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &UserResolver{user: user}, nil
+}
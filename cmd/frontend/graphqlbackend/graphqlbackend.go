@@ -16,6 +16,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/api"
@@ -352,7 +353,7 @@ func NewSchema(campaigns CampaignsResolver, codeIntel CodeIntelResolver, authz A
 	return graphql.ParseSchema(
 		Schema,
 		resolver,
-		graphql.Tracer(prometheusTracer{}),
+		graphql.Tracer(scopesTracer{prometheusTracer{}}),
 	)
 }
 
@@ -624,6 +625,11 @@ func (r *schemaResolver) RepositoryRedirect(ctx context.Context, args *struct {
 	Name     *string
 	CloneURL *string
 }) (*repositoryRedirect, error) {
+	// 🚨 SECURITY: Access tokens must grant the "repo:read" scope to read repositories.
+	if err := authz.CheckScope(ctx, authz.ScopeRepoRead); err != nil {
+		return nil, err
+	}
+
 	var name api.RepoName
 	if args.Name != nil {
 		// Query by name
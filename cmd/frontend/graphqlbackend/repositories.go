@@ -10,6 +10,7 @@ import (
 	"github.com/google/zoekt"
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
@@ -21,7 +22,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/search"
 )
 
-func (r *schemaResolver) Repositories(args *struct {
+func (r *schemaResolver) Repositories(ctx context.Context, args *struct {
 	graphqlutil.ConnectionArgs
 	Query           *string
 	Names           *[]string
@@ -33,6 +34,11 @@ func (r *schemaResolver) Repositories(args *struct {
 	OrderBy         string
 	Descending      bool
 }) (*repositoryConnectionResolver, error) {
+	// 🚨 SECURITY: Access tokens must grant the "repo:read" scope to read repositories.
+	if err := authz.CheckScope(ctx, authz.ScopeRepoRead); err != nil {
+		return nil, err
+	}
+
 	opt := db.ReposListOptions{
 		OrderBy: db.RepoListOrderBy{{
 			Field:      toDBRepoListColumn(args.OrderBy),
@@ -9,11 +9,12 @@ import (
 	"strings"
 	"sync"
 
+	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/go-diff/diff"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
-	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
@@ -28,8 +29,9 @@ type RepositoryComparisonConnectionResolver interface {
 }
 
 type RepositoryComparisonInput struct {
-	Base *string
-	Head *string
+	Base           *string
+	Head           *string
+	HeadRepository *graphql.ID
 }
 
 func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *RepositoryComparisonInput) (*RepositoryComparisonResolver, error) {
@@ -45,34 +47,44 @@ func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *R
 		headRevspec = *args.Head
 	}
 
-	getCommit := func(ctx context.Context, repo gitserver.Repo, revspec string) (*GitCommitResolver, error) {
+	headRepo := r
+	if args.HeadRepository != nil {
+		// 🚨 SECURITY: This checks that the user can access the head repository.
+		var err error
+		headRepo, err = repositoryByID(ctx, *args.HeadRepository)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	getCommit := func(ctx context.Context, repo *RepositoryResolver, revspec string) (*GitCommitResolver, error) {
 		if revspec == devNullSHA {
 			return nil, nil
 		}
 
+		grepo, err := backend.CachedGitRepo(ctx, repo.repo)
+		if err != nil {
+			return nil, err
+		}
+
 		// Optimistically fetch using revspec
-		commit, err := git.GetCommit(ctx, repo, nil, api.CommitID(revspec))
+		commit, err := git.GetCommit(ctx, *grepo, nil, api.CommitID(revspec))
 		if err == nil {
-			return toGitCommitResolver(r, commit), nil
+			return toGitCommitResolver(repo, commit), nil
 		}
 
 		// Call ResolveRevision to trigger fetches from remote (in case base/head commits don't
 		// exist).
-		commitID, err := git.ResolveRevision(ctx, repo, nil, revspec, nil)
+		commitID, err := git.ResolveRevision(ctx, *grepo, nil, revspec, nil)
 		if err != nil {
 			return nil, err
 		}
 
-		commit, err = git.GetCommit(ctx, repo, nil, commitID)
+		commit, err = git.GetCommit(ctx, *grepo, nil, commitID)
 		if err != nil {
 			return nil, err
 		}
-		return toGitCommitResolver(r, commit), nil
-	}
-
-	grepo, err := backend.CachedGitRepo(ctx, r.repo)
-	if err != nil {
-		return nil, err
+		return toGitCommitResolver(repo, commit), nil
 	}
 
 	var (
@@ -84,11 +96,11 @@ func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *R
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		base, baseErr = getCommit(ctx, *grepo, baseRevspec)
+		base, baseErr = getCommit(ctx, r, baseRevspec)
 	}()
 	go func() {
 		defer wg.Done()
-		head, headErr = getCommit(ctx, *grepo, headRevspec)
+		head, headErr = getCommit(ctx, headRepo, headRevspec)
 	}()
 	wg.Wait()
 	if baseErr != nil {
@@ -104,6 +116,7 @@ func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *R
 		base:        base,
 		head:        head,
 		repo:        r,
+		headRepo:    headRepo,
 	}, nil
 }
 
@@ -114,18 +127,42 @@ func (r *RepositoryResolver) Comparison(ctx context.Context, args *RepositoryCom
 type RepositoryComparisonResolver struct {
 	baseRevspec, headRevspec string
 	base, head               *GitCommitResolver
-	repo                     *RepositoryResolver
+	repo                     *RepositoryResolver // the base repository
+
+	// headRepo is the repository of head. If it isn't repo, commits and
+	// diffs are computed in headRepo with the objects of repo available as
+	// an alternate.
+	headRepo *RepositoryResolver
 }
 
 func (r *RepositoryComparisonResolver) BaseRepository() *RepositoryResolver { return r.repo }
 
-func (r *RepositoryComparisonResolver) HeadRepository() *RepositoryResolver { return r.repo }
+func (r *RepositoryComparisonResolver) HeadRepository() *RepositoryResolver {
+	if r.headRepo == nil {
+		return r.repo
+	}
+	return r.headRepo
+}
+
+// crossRepo reports whether the base and head are in different repositories.
+func (r *RepositoryComparisonResolver) crossRepo() bool {
+	return r.headRepo != nil && r.headRepo.repo.ID != r.repo.repo.ID
+}
+
+// alternate returns the base repository as an alternate for commands in the
+// head repository, or nil if they don't need one.
+func (r *RepositoryComparisonResolver) alternate() *protocol.AlternateRepo {
+	if !r.crossRepo() || r.base == nil {
+		return nil
+	}
+	return &protocol.AlternateRepo{Repo: r.repo.repo.Name, Commit: api.CommitID(r.base.OID())}
+}
 
 func (r *RepositoryComparisonResolver) Range() *gitRevisionRange {
 	return &gitRevisionRange{
 		expr:      r.baseRevspec + "..." + r.headRevspec,
 		base:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.baseRevspec, repo: r.repo}},
-		head:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.headRevspec, repo: r.repo}},
+		head:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.headRevspec, repo: r.HeadRepository()}},
 		mergeBase: nil, // not currently used
 	}
 }
@@ -133,10 +170,17 @@ func (r *RepositoryComparisonResolver) Range() *gitRevisionRange {
 func (r *RepositoryComparisonResolver) Commits(
 	args *graphqlutil.ConnectionArgs,
 ) *gitCommitConnectionResolver {
+	revisionRange := string(r.baseRevspec) + ".." + string(r.headRevspec)
+	if r.crossRepo() && r.base != nil && r.head != nil {
+		// The revspecs are resolved in different repositories, so we use
+		// the commits they resolved to.
+		revisionRange = string(r.base.OID()) + ".." + string(r.head.OID())
+	}
 	return &gitCommitConnectionResolver{
-		revisionRange: string(r.baseRevspec) + ".." + string(r.headRevspec),
+		revisionRange: revisionRange,
 		first:         args.First,
-		repo:          r.repo,
+		repo:          r.HeadRepository(),
+		alternate:     r.alternate(),
 	}
 }
 
@@ -176,11 +220,11 @@ func (r *fileDiffConnectionResolver) compute(ctx context.Context) ([]*diff.FileD
 			// flags or refer to a file.
 			return nil, fmt.Errorf("invalid diff range argument: %q", rangeSpec)
 		}
-		cachedRepo, err := backend.CachedGitRepo(ctx, r.cmp.repo.repo)
+		cachedRepo, err := backend.CachedGitRepo(ctx, r.cmp.HeadRepository().repo)
 		if err != nil {
 			return nil, err
 		}
-		rdr, err := git.ExecReader(ctx, *cachedRepo, []string{
+		rdr, err := git.ExecReaderWithAlternate(ctx, *cachedRepo, r.cmp.alternate(), []string{
 			"diff",
 			"--find-renames",
 			"--find-copies",
@@ -0,0 +1,89 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestRepositoryComparison_crossRepository(t *testing.T) {
+	resetMocks()
+	defer git.ResetMocks()
+
+	const (
+		baseCommit = "1111111111111111111111111111111111111111"
+		headCommit = "2222222222222222222222222222222222222222"
+	)
+	base := &types.Repo{ID: 1, Name: "example.com/upstream"}
+	fork := &types.Repo{ID: 2, Name: "example.com/fork"}
+
+	db.Mocks.Repos.Get = func(ctx context.Context, id api.RepoID) (*types.Repo, error) {
+		if id != fork.ID {
+			t.Fatalf("unexpected repository %d", id)
+		}
+		return fork, nil
+	}
+	git.Mocks.GetCommit = func(id api.CommitID) (*git.Commit, error) {
+		switch id {
+		case "master":
+			return &git.Commit{ID: baseCommit}, nil
+		case "feature":
+			return &git.Commit{ID: headCommit}, nil
+		}
+		t.Fatalf("unexpected commit %q", id)
+		return nil, nil
+	}
+
+	baseRev, headRev := "master", "feature"
+	headRepoID := relay.MarshalID("Repository", fork.ID)
+	cmp, err := NewRepositoryComparison(context.Background(), &RepositoryResolver{repo: base}, &RepositoryComparisonInput{
+		Base:           &baseRev,
+		Head:           &headRev,
+		HeadRepository: &headRepoID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if have := cmp.BaseRepository().repo.ID; have != base.ID {
+		t.Errorf("have base repository %d, want %d", have, base.ID)
+	}
+	if have := cmp.HeadRepository().repo.ID; have != fork.ID {
+		t.Errorf("have head repository %d, want %d", have, fork.ID)
+	}
+	if have := cmp.head.repo.repo.ID; have != fork.ID {
+		t.Errorf("head commit resolved in repository %d, want %d", have, fork.ID)
+	}
+
+	commits := cmp.Commits(&graphqlutil.ConnectionArgs{})
+	if want := baseCommit + ".." + headCommit; commits.revisionRange != want {
+		t.Errorf("have commits range %q, want %q", commits.revisionRange, want)
+	}
+	if commits.repo.repo.ID != fork.ID {
+		t.Errorf("have commits listed in repository %d, want %d", commits.repo.repo.ID, fork.ID)
+	}
+	if commits.alternate == nil || commits.alternate.Repo != base.Name || commits.alternate.Commit != baseCommit {
+		t.Errorf("unexpected alternate %+v", commits.alternate)
+	}
+
+	// Comparisons within a repository don't need an alternate.
+	cmp, err = NewRepositoryComparison(context.Background(), &RepositoryResolver{repo: base}, &RepositoryComparisonInput{
+		Base: &baseRev,
+		Head: &headRev,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmp.HeadRepository().repo.ID != base.ID {
+		t.Errorf("have head repository %d, want %d", cmp.HeadRepository().repo.ID, base.ID)
+	}
+	if commits := cmp.Commits(&graphqlutil.ConnectionArgs{}); commits.revisionRange != "master..feature" || commits.alternate != nil {
+		t.Errorf("unexpected commits connection: range %q, alternate %+v", commits.revisionRange, commits.alternate)
+	}
+}
@@ -0,0 +1,88 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func (r *RepositoryResolver) Dependencies(ctx context.Context) ([]*repositoryDependencyResolver, error) {
+	deps, err := db.RepoPackages.ListDependencies(ctx, r.repo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []api.RepoID
+	for _, d := range deps {
+		if d.RepoID != 0 {
+			ids = append(ids, d.RepoID)
+		}
+	}
+	// 🚨 SECURITY: GetByIDs only returns the repositories that the user is
+	// allowed to see.
+	repos, err := db.Repos.GetByIDs(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	reposByID := make(map[api.RepoID]*types.Repo, len(repos))
+	for _, repo := range repos {
+		reposByID[repo.ID] = repo
+	}
+
+	resolvers := make([]*repositoryDependencyResolver, len(deps))
+	for i, d := range deps {
+		resolvers[i] = &repositoryDependencyResolver{dep: d, repo: reposByID[d.RepoID]}
+	}
+	return resolvers, nil
+}
+
+func (r *RepositoryResolver) Dependents(ctx context.Context, args *struct{ First *int32 }) ([]*RepositoryResolver, error) {
+	limit := 50
+	if args.First != nil {
+		limit = int(*args.First)
+		if limit < 0 {
+			limit = 0
+		} else if limit > 1000 {
+			limit = 1000
+		}
+	}
+	ids, err := db.RepoPackages.ListDependents(ctx, r.repo.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: GetByIDs only returns the repositories that the user is
+	// allowed to see.
+	repos, err := db.Repos.GetByIDs(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*RepositoryResolver, len(repos))
+	for i, repo := range repos {
+		resolvers[i] = &RepositoryResolver{repo: repo}
+	}
+	return resolvers, nil
+}
+
+type repositoryDependencyResolver struct {
+	dep  *db.RepoDependency
+	repo *types.Repo // nil if unknown or not visible to the user
+}
+
+func (r *repositoryDependencyResolver) Kind() string { return r.dep.Kind }
+func (r *repositoryDependencyResolver) Name() string { return r.dep.Name }
+
+func (r *repositoryDependencyResolver) Version() *string {
+	if r.dep.Version == "" {
+		return nil
+	}
+	return &r.dep.Version
+}
+
+func (r *repositoryDependencyResolver) Repository() *RepositoryResolver {
+	if r.repo == nil {
+		return nil
+	}
+	return &RepositoryResolver{repo: r.repo}
+}
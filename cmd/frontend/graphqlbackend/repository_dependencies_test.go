@@ -0,0 +1,98 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/gqltesting"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+)
+
+func TestRepository_Dependencies(t *testing.T) {
+	resetMocks()
+	db.Mocks.Repos.MockGetByName(t, "github.com/a/app", 1)
+	db.Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error) {
+		// Repository 3 is not visible to the user.
+		var repos []*types.Repo
+		for _, id := range ids {
+			if id == 2 {
+				repos = append(repos, &types.Repo{ID: 2, Name: "github.com/a/lib"})
+			}
+		}
+		return repos, nil
+	}
+	db.Mocks.RepoPackages.ListDependencies = func(ctx context.Context, repo api.RepoID) ([]*db.RepoDependency, error) {
+		return []*db.RepoDependency{
+			{Package: depgraph.Package{Kind: depgraph.KindGo, Name: "github.com/a/lib", Version: "v1.0.0"}, RepoID: 2},
+			{Package: depgraph.Package{Kind: depgraph.KindNPM, Name: "private"}, RepoID: 3},
+			{Package: depgraph.Package{Kind: depgraph.KindPip, Name: "django", Version: "2.2"}},
+		}, nil
+	}
+	db.Mocks.RepoPackages.ListDependents = func(ctx context.Context, repo api.RepoID, limit int) ([]api.RepoID, error) {
+		if limit != 10 {
+			t.Errorf("got limit %d, want 10", limit)
+		}
+		return []api.RepoID{2, 3}, nil
+	}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: mustParseGraphQLSchema(t),
+			Query: `
+				{
+					repository(name: "github.com/a/app") {
+						dependencies {
+							kind
+							name
+							version
+							repository {
+								name
+							}
+						}
+						dependents(first: 10) {
+							name
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"repository": {
+						"dependencies": [
+							{"kind": "go", "name": "github.com/a/lib", "version": "v1.0.0", "repository": {"name": "github.com/a/lib"}},
+							{"kind": "npm", "name": "private", "version": null, "repository": null},
+							{"kind": "pip", "name": "django", "version": "2.2", "repository": null}
+						],
+						"dependents": [
+							{"name": "github.com/a/lib"}
+						]
+					}
+				}
+			`,
+		},
+	})
+}
+
+func TestRepository_DependentsNegativeFirst(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	db.Mocks.RepoPackages.ListDependents = func(ctx context.Context, repo api.RepoID, limit int) ([]api.RepoID, error) {
+		if limit != 0 {
+			t.Errorf("got limit %d, want 0", limit)
+		}
+		return nil, nil
+	}
+	db.Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error) {
+		return nil, nil
+	}
+
+	first := int32(-1)
+	r := &RepositoryResolver{repo: &types.Repo{ID: 1}}
+	if _, err := r.Dependents(context.Background(), &struct{ First *int32 }{First: &first}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/trace"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+)
+
+// rootFieldScopes are the access token scopes, other than "user:all", that the fields of the Query
+// and Mutation types accept. 🚨 SECURITY: Fields that aren't listed may only be used with access
+// tokens that have the "user:all" scope, so a field must be added here before a token with a
+// narrower scope can use it. The fields of the types they return are not checked, as they are
+// reached through a root field.
+var rootFieldScopes = map[string][]string{
+	"Query.search":                  {authz.ScopeSearchRead},
+	"Query.searchFilterSuggestions": {authz.ScopeSearchRead},
+
+	"Query.repository":         {authz.ScopeRepoRead},
+	"Query.repositories":       {authz.ScopeRepoRead},
+	"Query.repositoryRedirect": {authz.ScopeRepoRead},
+
+	"Query.viewerSettings":      {authz.ScopeSettingsRead},
+	"Query.viewerConfiguration": {authz.ScopeSettingsRead},
+	"Query.settingsSubject":     {authz.ScopeSettingsRead},
+	"Query.user":                {authz.ScopeSettingsRead},
+	"Query.organization":        {authz.ScopeSettingsRead},
+	"Query.site":                {authz.ScopeSettingsRead},
+
+	"Query.campaigns":                    {authz.ScopeCampaignsWrite},
+	"Mutation.createCampaign":            {authz.ScopeCampaignsWrite},
+	"Mutation.updateCampaign":            {authz.ScopeCampaignsWrite},
+	"Mutation.retryCampaign":             {authz.ScopeCampaignsWrite},
+	"Mutation.deleteCampaign":            {authz.ScopeCampaignsWrite},
+	"Mutation.closeCampaign":             {authz.ScopeCampaignsWrite},
+	"Mutation.publishCampaign":           {authz.ScopeCampaignsWrite},
+	"Mutation.createPatchSetFromPatches": {authz.ScopeCampaignsWrite},
+	"Mutation.createChangesets":          {authz.ScopeCampaignsWrite},
+	"Mutation.addChangesetsToCampaign":   {authz.ScopeCampaignsWrite},
+	"Mutation.publishChangeset":          {authz.ScopeCampaignsWrite},
+	"Mutation.syncChangeset":             {authz.ScopeCampaignsWrite},
+}
+
+// scopesTracer is a GraphQL tracer that rejects the root fields that the access token used to
+// authenticate the request may not use, according to rootFieldScopes.
+type scopesTracer struct {
+	trace.Tracer
+}
+
+func (t scopesTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	ctx, finish := t.Tracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+	if (typeName == "Query" || typeName == "Mutation") && !strings.HasPrefix(fieldName, "__") {
+		if err := authz.CheckDeclaredScopes(ctx, rootFieldScopes[typeName+"."+fieldName]); err != nil {
+			// The resolver of a field isn't called if its context has an error, which is returned
+			// for the field instead.
+			return &scopeDeniedContext{Context: ctx, err: err}, finish
+		}
+	}
+	return ctx, finish
+}
+
+// scopeDeniedContext is the context of a root field that the access token may not use.
+type scopeDeniedContext struct {
+	context.Context
+	err error
+}
+
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+func (c *scopeDeniedContext) Done() <-chan struct{} { return closedChan }
+func (c *scopeDeniedContext) Err() error            { return c.err }
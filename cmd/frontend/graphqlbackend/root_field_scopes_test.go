@@ -0,0 +1,77 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// 🚨 SECURITY: This tests that access tokens without the "user:all" scope can only use the root
+// fields that declare one of their scopes.
+func TestRootFieldScopes(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	db.Mocks.Orgs.Create = func(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+		t.Error("organization was created")
+		return &types.Org{ID: 1, Name: name}, nil
+	}
+
+	tests := map[string]struct {
+		query   string
+		scopes  []string
+		wantErr bool
+	}{
+		"node with search:read": {
+			query:   `{ node(id: "UmVwb3NpdG9yeTox") { id } }`,
+			scopes:  []string{authz.ScopeSearchRead},
+			wantErr: true,
+		},
+		"mutation with search:read": {
+			query:   `mutation { createOrganization(name: "o") { id } }`,
+			scopes:  []string{authz.ScopeSearchRead},
+			wantErr: true,
+		},
+		"mutation with campaigns:write": {
+			query:   `mutation { createOrganization(name: "o") { id } }`,
+			scopes:  []string{authz.ScopeCampaignsWrite},
+			wantErr: true,
+		},
+		"field declaring settings:read with search:read": {
+			query:   `{ site { __typename } }`,
+			scopes:  []string{authz.ScopeSearchRead},
+			wantErr: true,
+		},
+		"field declaring settings:read with settings:read": {
+			query:  `{ site { __typename } }`,
+			scopes: []string{authz.ScopeSettingsRead},
+		},
+		"field declaring settings:read with user:all": {
+			query:  `{ site { __typename } }`,
+			scopes: []string{authz.ScopeUserAll},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+			ctx = authz.WithTokenScopes(ctx, &authz.TokenScopes{Scopes: test.scopes})
+			resp := mustParseGraphQLSchema(t).Exec(ctx, test.query, "", nil)
+			if !test.wantErr {
+				if len(resp.Errors) > 0 {
+					t.Errorf("got errors %v, want none", resp.Errors)
+				}
+				return
+			}
+			if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, `required scope "user:all"`) {
+				t.Errorf("got errors %v, want a missing scope error", resp.Errors)
+			}
+		})
+	}
+}
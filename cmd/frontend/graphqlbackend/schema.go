@@ -202,16 +202,79 @@ type Mutation {
     # The supported scopes are:
     #
     # - "user:all": Full control of all resources accessible to the user account.
+    # - "search:read": Ability to run searches.
+    # - "repo:read": Ability to read repositories and their contents.
+    # - "campaigns:write": Ability to read, create and update campaigns.
+    # - "settings:read": Ability to read settings.
     # - "site-admin:sudo": Ability to perform any action as any other user. (Only site admins may create tokens
     #   with this scope.)
+    # - "site-admin:scim": Ability to provision users and organizations with the SCIM API. (Only site admins may
+    #   create tokens with this scope, and it is not granted by "user:all".)
+    #
+    # Every access token must have at least one scope other than "site-admin:sudo". Only tokens with the
+    # "user:all" scope may manage the user account or perform site admin actions.
     #
     # Only the user or site admins may perform this mutation.
-    createAccessToken(user: ID!, scopes: [String!]!, note: String!): CreateAccessTokenResult!
+    createAccessToken(
+        user: ID!
+        scopes: [String!]!
+        note: String!
+        # The time after which the access token is no longer valid. If null, the access token never expires.
+        expiresAt: DateTime
+        # The names of the only repositories that the access token may access. If null, the access token may
+        # access all repositories accessible to the user.
+        repositories: [String!]
+    ): CreateAccessTokenResult!
     # Deletes and immediately revokes the specified access token, specified by either its ID or by the token
     # itself.
     #
     # Only site admins or the user who owns the token may perform this mutation.
     deleteAccessToken(byID: ID, byToken: String): EmptyResponse!
+    # Begins enrolling an authenticator app (TOTP) as a second factor for two-factor authentication. The result
+    # is the new secret, which the user adds to their authenticator app. The enrollment must be confirmed with
+    # confirmTOTPEnrollment.
+    #
+    # Only the user may perform this mutation.
+    beginTOTPEnrollment(user: ID!): TOTPEnrollment!
+    # Confirms a pending TOTP enrollment with a code from the user's authenticator app. If this is the user's
+    # first second factor, the result is the user's new recovery codes (which the caller is responsible for
+    # showing to the user; they are not accessible by Sourcegraph after creation). Otherwise it is empty.
+    #
+    # Only the user may perform this mutation.
+    confirmTOTPEnrollment(user: ID!, code: String!): [String!]!
+    # Removes the user's authenticator app (TOTP) second factor. The current user (the user, or a site admin
+    # removing another user's second factor) must confirm the removal with the reauthentication.
+    #
+    # Only the user or site admins may perform this mutation. Users may not remove their last second factor if
+    # two-factor authentication is required for them.
+    removeTOTP(user: ID!, reauthentication: TwoFactorReauthenticationInput!): EmptyResponse!
+    # Begins registering a WebAuthn security key as a second factor for two-factor authentication. The result is
+    # the JSON-encoded PublicKeyCredentialCreationOptions (with binary values base64url-encoded) to pass to
+    # navigator.credentials.create.
+    #
+    # Only the user may perform this mutation.
+    beginWebAuthnRegistration(user: ID!): String!
+    # Finishes registering a WebAuthn security key. The credential is the JSON-encoded response of
+    # navigator.credentials.create: {id, clientDataJSON, authenticatorData, publicKey} (base64url-encoded). If this
+    # is the user's first second factor, the result is the user's new recovery codes. Otherwise it is empty.
+    #
+    # Only the user may perform this mutation.
+    finishWebAuthnRegistration(user: ID!, name: String!, credential: String!): [String!]!
+    # Removes one of the user's WebAuthn security keys. The current user (the user, or a site admin removing
+    # another user's security key) must confirm the removal with the reauthentication.
+    #
+    # Only the user or site admins may perform this mutation. Users may not remove their last second factor if
+    # two-factor authentication is required for them.
+    removeWebAuthnCredential(
+        user: ID!
+        credential: ID!
+        reauthentication: TwoFactorReauthenticationInput!
+    ): EmptyResponse!
+    # Replaces the user's two-factor authentication recovery codes with new ones and returns them. The user must
+    # confirm the change with the reauthentication.
+    #
+    # Only the user may perform this mutation.
+    regenerateTwoFactorRecoveryCodes(user: ID!, reauthentication: TwoFactorReauthenticationInput!): [String!]!
     # Deletes the association between an external account and its Sourcegraph user. It does NOT delete the external
     # account on the external service where it resides.
     #
@@ -397,6 +460,10 @@ type Mutation {
     ): SavedSearch!
     # Deletes a saved search
     deleteSavedSearch(id: ID!): EmptyResponse
+    # Deletes all of the user's search history (their recent and frequent searches).
+    #
+    # Only the user and site admins may perform this mutation.
+    deleteSearchHistory(user: ID!): EmptyResponse!
 
     # (experimental) The LSIF API may change substantially in the near future as we
     # continue to adjust it for our use cases. Changes will not be documented in the
@@ -1106,6 +1173,51 @@ type UpdateSettingsPayload {
     empty: EmptyResponse
 }
 
+# Proof that the current user is present, required for sensitive changes to second factors. Exactly one of the
+# fields must be set.
+input TwoFactorReauthenticationInput {
+    # The current user's password.
+    password: String
+    # A code from the current user's authenticator app.
+    totpCode: String
+    # One of the current user's unused recovery codes (which is used up).
+    recoveryCode: String
+}
+
+# A user's two-factor authentication settings.
+type UserTwoFactor {
+    # Whether the user has at least one second factor, so that signing in requires two-factor authentication.
+    enabled: Boolean!
+    # Whether the site configuration requires the user to use two-factor authentication.
+    required: Boolean!
+    # Whether the user has an authenticator app (TOTP) second factor.
+    totpEnabled: Boolean!
+    # The user's WebAuthn security keys.
+    webAuthnCredentials: [WebAuthnCredential!]!
+    # The number of the user's unused recovery codes.
+    recoveryCodesRemaining: Int!
+}
+
+# A WebAuthn security key registered as a second factor.
+type WebAuthnCredential {
+    # The unique ID of the security key.
+    id: ID!
+    # The name that the user gave the security key.
+    name: String!
+    # The date when the security key was registered.
+    createdAt: DateTime!
+    # The date when the security key was last used to sign in, if ever.
+    lastUsedAt: DateTime
+}
+
+# The result for Mutation.beginTOTPEnrollment.
+type TOTPEnrollment {
+    # The base32-encoded TOTP secret, for manual entry in an authenticator app.
+    secret: String!
+    # The otpauth:// URI of the TOTP secret, for QR codes.
+    uri: String!
+}
+
 # The result for Mutation.createAccessToken.
 type CreateAccessTokenResult {
     # The ID of the newly created access token.
@@ -1594,7 +1706,7 @@ type Language {
 }
 
 # A search suggestion.
-union SearchSuggestion = Repository | File | Symbol | Language
+union SearchSuggestion = Repository | File | Symbol | Language | FrequentSearch
 
 # A search-related alert message.
 type SearchAlert {
@@ -1627,6 +1739,54 @@ type SavedSearch implements Node {
     slackWebhookURL: String
 }
 
+# A search performed by a user, in the user's search history.
+type SearchHistoryEntry {
+    # The search query.
+    query: String!
+    # The pattern type of the search.
+    patternType: SearchPatternType!
+    # The number of results that the search returned.
+    resultCount: Int!
+    # How long the search took, in milliseconds.
+    latencyMilliseconds: Int!
+    # When the search was performed.
+    createdAt: DateTime!
+}
+
+# A list of searches in a user's search history.
+type SearchHistoryEntryConnection {
+    # A list of searches.
+    nodes: [SearchHistoryEntry!]!
+    # The total count of searches in the connection. This total count may be larger than the number of nodes
+    # in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A distinct search (query and pattern type) that a user performed, with the number of times they performed it.
+type FrequentSearch {
+    # The search query.
+    query: String!
+    # The pattern type of the search.
+    patternType: SearchPatternType!
+    # The number of times the user performed the search.
+    count: Int!
+    # When the user last performed the search.
+    lastSearchedAt: DateTime!
+}
+
+# A list of a user's frequent searches.
+type FrequentSearchConnection {
+    # A list of searches.
+    nodes: [FrequentSearch!]!
+    # The total count of searches in the connection. This total count may be larger than the number of nodes
+    # in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
 # A search query description.
 type SearchQueryDescription {
     # The description.
@@ -1669,6 +1829,9 @@ type CommitSearchResult implements GenericSearchResultInterface {
     refs: [GitRef!]!
     # The refs by which this commit was reached.
     sourceRefs: [GitRef!]!
+    # The searched refs which contain this commit. It is only set if the search is for ref globs (such as
+    # "refs:heads/release/*" or "repo:r@*refs/heads/release/*"), otherwise it is empty.
+    containingRefs: [GitRef!]!
     # The matching portion of the commit message, if any.
     messagePreview: HighlightedString
     # The matching portion of the diff, if any.
@@ -1882,12 +2045,15 @@ type Repository implements Node & GenericSearchResultInterface {
         # Return Git tags whose names match the query.
         query: String
     ): GitRefConnection!
-    # A Git comparison in this repository between a base and head commit.
+    # A Git comparison between a base commit in this repository and a head commit in this repository or
+    # in another repository, such as a fork.
     comparison(
         # The base of the diff ("old" or "left-hand side"), or "HEAD" if not specified.
         base: String
         # The head of the diff ("new" or "right-hand side"), or "HEAD" if not specified.
         head: String
+        # The repository that contains the head of the diff. Defaults to this repository.
+        headRepository: ID
     ): RepositoryComparison!
     # The repository's contributors.
     contributors(
@@ -1900,6 +2066,15 @@ type Repository implements Node & GenericSearchResultInterface {
         # Returns the first n contributors from the list.
         first: Int
     ): RepositoryContributorConnection!
+    # The packages that this repository depends on, as declared by the manifest files (go.mod, package.json,
+    # pom.xml, requirements.txt and Cargo.toml) of its default branch. Manifest files are indexed periodically,
+    # so the dependencies may lag behind the default branch.
+    dependencies: [RepositoryDependency!]!
+    # The repositories that depend on a package provided by this repository.
+    dependents(
+        # Returns the first n repositories from the list.
+        first: Int
+    ): [Repository!]!
     # Link to another Sourcegraph instance location where this repository is located.
     redirectURL: String @deprecated(reason: "use repositoryRedirect query instead")
     # Whether the viewer has admin privileges on this repository.
@@ -2133,14 +2308,14 @@ type PreviewFileDiff {
     internalID: String!
 }
 
-# The differences between two concrete Git commits in a repository.
+# The differences between two concrete Git commits, which can be in different repositories.
 type RepositoryComparison {
     # The repository that is the base (left-hand side) of this comparison.
     baseRepository: Repository!
 
-    # The repository that is the head (right-hand side) of this comparison. Cross-repository
-    # comparisons are not yet supported, so this is always equal to
-    # RepositoryComparison.baseRepository.
+    # The repository that is the head (right-hand side) of this comparison. This is equal to
+    # RepositoryComparison.baseRepository unless the comparison is between two repositories, such as
+    # a repository and one of its forks.
     headRepository: Repository!
 
     # The range that this comparison represents.
@@ -2256,6 +2431,18 @@ type RepositoryContributor {
     ): GitCommitConnection!
 }
 
+# A package that a repository depends on.
+type RepositoryDependency {
+    # The package ecosystem: "go", "npm", "maven", "pip" or "cargo".
+    kind: String!
+    # The name of the package.
+    name: String!
+    # The version (or version constraint) of the package that is depended on, or null if unknown.
+    version: String
+    # The repository that provides the package, or null if it is not known.
+    repository: Repository
+}
+
 # A code symbol (e.g., a function, variable, type, class, etc.).
 #
 # It is derived from DocumentSymbol as defined in the Language Server Protocol (see
@@ -2541,7 +2728,11 @@ type GitCommit implements Node {
     # Lists the programming languages present in the tree at this commit.
     languages: [String!]!
     # List statistics for each language present in the repository.
-    languageStatistics: [LanguageStatistics!]!
+    languageStatistics(
+        # Return statistics for the file or directory at this path. Defaults to the root of the
+        # repository.
+        path: String
+    ): [LanguageStatistics!]!
     # The log of commits consisting of this commit and its ancestors.
     ancestors(
         # Returns the first n commits from the list.
@@ -2637,6 +2828,18 @@ interface TreeEntry {
         # Recurse into sub-trees.
         recursive: Boolean = false
     ): Boolean!
+    # The owners of this tree entry, according to the CODEOWNERS file of the repository at this commit.
+    owners: [CodeOwner!]!
+}
+
+# An owner of files in a repository, as listed in a CODEOWNERS file.
+type CodeOwner {
+    # The owner as written in the CODEOWNERS file, e.g. "@alice", "@org/team" or "alice@example.com".
+    name: String!
+    # Whether the owner is a team or group rather than an individual.
+    isTeam: Boolean!
+    # The user corresponding to the owner, matched by username or verified email address, if any.
+    user: User
 }
 
 # A Git tree in a repository.
@@ -2701,6 +2904,8 @@ type GitTree implements TreeEntry {
         # Recurse into sub-trees.
         recursive: Boolean = false
     ): Boolean!
+    # The owners of this tree entry, according to the CODEOWNERS file of the repository at this commit.
+    owners: [CodeOwner!]!
 }
 
 # A file.
@@ -2809,13 +3014,20 @@ type GitBlob implements TreeEntry & File2 {
         # Recurse into sub-trees of single-child directories
         recursiveSingleChild: Boolean = false
     ): Boolean!
+    # The owners of this tree entry, according to the CODEOWNERS file of the repository at this commit.
+    owners: [CodeOwner!]!
 
     # (experimental) The LSIF API may change substantially in the near future as we
     # continue to adjust it for our use cases. Changes will not be documented in the
     # CHANGELOG during this time.
     # A wrapper around LSIF query methods. If no LSIF upload can be used to answer code
     # intelligence queries for this path-at-revision, this resolves to null.
-    lsif: LSIFQueryResolver
+    lsif(
+        # Whether to also resolve to a wrapper for Go files without an LSIF upload. Such a wrapper
+        # computes implementations and type definitions from the Go type information extracted by
+        # the symbols service, and resolves the other queries to empty results.
+        goFallback: Boolean = false
+    ): LSIFQueryResolver
 }
 
 # A wrapper object around LSIF query methods for a particular path-at-revision. When this node is
@@ -2868,6 +3080,47 @@ type LSIFQueryResolver {
         # The character (not byte) of the start line on which the symbol occurs (zero-based, inclusive).
         character: Int!
     ): Hover
+
+    # (experimental) The LSIF API may change substantially in the near future as we
+    # continue to adjust it for our use cases. Changes will not be documented in the
+    # CHANGELOG during this time.
+    # A list of implementations of the type under the given document position: the types that
+    # implement an interface, or the interfaces that a type implements. For Go, these are
+    # computed from the method sets of the types declared in the repository and in the
+    # repositories that depend on it (for an interface) or that it depends on (for a type). Only a
+    # limited number of other repositories are searched. If there is an LSIF upload, the type is
+    # the one that LSIF resolves the symbol to.
+    implementations(
+        # The line on which the symbol occurs (zero-based, inclusive).
+        line: Int!
+
+        # The character (not byte) of the start line on which the symbol occurs (zero-based, inclusive).
+        character: Int!
+
+        # When specified, indicates that this request should be paginated and
+        # to fetch results starting at this cursor.
+        #
+        # A future request can be made for more results by passing in the
+        # 'LocationConnection.pageInfo.endCursor' that is returned.
+        after: String
+
+        # When specified, indicates that this request should be paginated and
+        # the first N results (relative to the cursor) should be returned. i.e.
+        # how many results to return per page.
+        first: Int
+    ): LocationConnection
+
+    # (experimental) The LSIF API may change substantially in the near future as we
+    # continue to adjust it for our use cases. Changes will not be documented in the
+    # CHANGELOG during this time.
+    # A list of definitions of the type of the symbol under the given document position.
+    typeDefinitions(
+        # The line on which the symbol occurs (zero-based, inclusive).
+        line: Int!
+
+        # The character (not byte) of the start line on which the symbol occurs (zero-based, inclusive).
+        character: Int!
+    ): LocationConnection
 }
 
 # A highlighted file.
@@ -2897,6 +3150,9 @@ type FileMatch {
     symbols: [Symbol!]!
     # The line matches.
     lineMatches: [LineMatch!]!
+    # The matches of a multiline search (with multiline:yes in the query), which may span multiple
+    # lines. They are returned instead of lineMatches for such searches.
+    multilineMatches: [MultilineMatch!]!
     # Whether or not the limit was hit.
     limitHit: Boolean!
 }
@@ -2913,6 +3169,14 @@ type LineMatch {
     limitHit: Boolean!
 }
 
+# A match which may span multiple lines.
+type MultilineMatch {
+    # The content of the lines from the start to the end of the match.
+    preview: String!
+    # The range of the match in the file. Characters are measured in characters (not bytes).
+    range: Range!
+}
+
 # A hunk.
 type Hunk {
     # The startLine.
@@ -3031,6 +3295,30 @@ type User implements Node & SettingsSubject & Namespace {
         # Returns the first n external accounts from the list.
         first: Int
     ): ExternalAccountConnection!
+    # The user's two-factor authentication settings.
+    #
+    # Only the user and site admins can access this field.
+    twoFactor: UserTwoFactor!
+    # The searches that the user recently performed in the web app, most recent first.
+    #
+    # Searches are only recorded if search history is enabled in site configuration and the user has not opted
+    # out with the search.recordHistory setting. Only the user and site admins can access this field.
+    recentSearches(
+        # Returns the first n searches from the list.
+        first: Int = 20
+        # Opaque pagination cursor.
+        after: String
+    ): SearchHistoryEntryConnection!
+    # The distinct searches (query and pattern type) that the user performed most frequently in the web app,
+    # most frequent first.
+    #
+    # Only the user and site admins can access this field.
+    frequentSearches(
+        # Returns the first n searches from the list.
+        first: Int = 20
+        # Opaque pagination cursor.
+        after: String
+    ): FrequentSearchConnection!
     # The user's currently active session.
     #
     # Only the currently authenticated user can access this field. Site admins are not able to access sessions for
@@ -3080,6 +3368,11 @@ type AccessToken implements Node {
     createdAt: DateTime!
     # The date when the access token was last used to authenticate a request.
     lastUsedAt: DateTime
+    # The date after which the access token is no longer valid, or null if it never expires.
+    expiresAt: DateTime
+    # The names of the only repositories that the access token may access. If empty, the access token may
+    # access all repositories accessible to the user.
+    repositories: [String!]!
 }
 
 # A list of access tokens.
@@ -3093,6 +3386,52 @@ type AccessTokenConnection {
     pageInfo: PageInfo!
 }
 
+# A list of audit log entries.
+type AuditLogEntryConnection {
+    # A list of audit log entries.
+    nodes: [AuditLogEntry!]!
+    # The total count of audit log entries in the connection. This total count may be larger than the number of
+    # nodes in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# An entry in the audit log, which describes a security-relevant administrative action.
+type AuditLogEntry {
+    # The unique ID of the audit log entry.
+    id: ID!
+    # The time when the action was performed.
+    timestamp: DateTime!
+    # The user who performed the action, or null if unknown or if the user has been deleted.
+    actor: User
+    # The IP address of the client that performed the action, or null if unknown.
+    actorIP: String
+    # The kind of action (e.g., "externalService.update").
+    action: String!
+    # The type of the action's target (e.g., "ExternalService").
+    targetType: String!
+    # The ID of the action's target, or null if not applicable.
+    targetID: String
+    # The state of the target before the action, or null if not applicable. Secrets in configuration are
+    # redacted.
+    before: String
+    # The state of the target after the action, or null if not applicable. Secrets in configuration are
+    # redacted.
+    after: String
+    # The hex-encoded SHA-256 hash of this entry, which covers the hash of the previous entry.
+    hash: String!
+}
+
+# The result of verifying the audit log.
+type AuditLogVerification {
+    # Whether no audit log entry has been modified or removed.
+    valid: Boolean!
+    # The first audit log entry that was modified or that follows a removed entry, or null if the audit log is
+    # valid.
+    firstInvalidEntry: AuditLogEntry
+}
+
 # A list of authentication providers.
 type AuthProviderConnection {
     # A list of authentication providers.
@@ -3379,17 +3718,43 @@ type DiscussionThreadTargetRepo {
     relativePath(rev: String!): String
 
     # Where the selection would be relative to the given Git revision specifier
-    # (branch/commit/etc).
+    # (branch/commit/etc). This is the selection of relativeAnchor.
     #
-    # The implementation relies on a hueristic which is generally good enough,
-    # but under certain circumstances may not be as accurate as e.g. determining
-    # this placement by walking through the Git history.
-    #
-    # If determining the relative placement is not possible (file was renamed
-    # or removed, the selection no longer exists in the file, or the hueristic
-    # failed) null is returned and it should be assumed the selection does not
-    # exist in this revision.
+    # If the selection no longer exists in this revision (the file was removed
+    # or the selected lines changed) null is returned.
     relativeSelection(rev: String!): DiscussionSelectionRange
+
+    # Where the path and selection of the thread would be relative to the given
+    # Git revision specifier (branch/commit/etc). The selection is followed
+    # through the Git diff between the revision the thread was created on and
+    # the given revision. If the selected lines changed, they are searched for
+    # in the file along with the lines around them.
+    #
+    # null is returned if the thread has no selection.
+    relativeAnchor(rev: String!): DiscussionThreadAnchor
+}
+
+# The location of a discussion thread's selection in a Git revision.
+type DiscussionThreadAnchor {
+    # The path of the file in the revision, or null if the file no longer exists.
+    path: String
+
+    # The selection in the revision, or null if the file no longer exists. If
+    # the status is OUTDATED, this is a best guess.
+    selection: DiscussionSelectionRange
+
+    # Whether the selected lines are unchanged in the revision.
+    status: DiscussionThreadAnchorStatus!
+}
+
+# The status of a discussion thread's selection in a Git revision.
+enum DiscussionThreadAnchorStatus {
+    # The selected lines are unchanged and at the same location.
+    EXACT
+    # The selected lines are unchanged, but at a different location.
+    MOVED
+    # The selected lines were changed or removed.
+    OUTDATED
 }
 
 # The target of a discussion thread. Today, the only possible target is a
@@ -3616,6 +3981,32 @@ type Site implements SettingsSubject {
         # Include only external accounts with this client ID.
         clientID: String
     ): ExternalAccountConnection!
+    # The audit log of security-relevant administrative actions (such as site configuration changes),
+    # most recent first.
+    #
+    # Only site admins can access this field.
+    auditLog(
+        # Returns the first n audit log entries from the list.
+        first: Int
+        # Include only actions performed by this user.
+        actor: ID
+        # Include only actions of this kind (e.g., "site.configuration.update").
+        action: String
+        # Include only actions on targets of this type (e.g., "ExternalService").
+        targetType: String
+        # Include only actions on the target with this ID.
+        targetID: String
+        # Include only actions performed after this time.
+        since: DateTime
+        # Include only actions performed before this time.
+        until: DateTime
+    ): AuditLogEntryConnection!
+    # Verifies that no audit log entry has been modified or removed since it was recorded (by checking the
+    # chain of hashes of the entries). Removal of the most recent entries can only be detected by comparing
+    # the hash of the most recent entry to one recorded elsewhere, such as in the exported audit log.
+    #
+    # Only site admins can access this field.
+    auditLogVerification: AuditLogVerification!
     # The build version of the Sourcegraph software that is running on this site (of the form
     # NNNNN_YYYY-MM-DD_XXXXX, like 12345_2018-01-01_abcdef).
     buildVersion: String!
@@ -3668,6 +4059,30 @@ type Site implements SettingsSubject {
         # Months of history (based on current UTC time).
         months: Int
     ): CodeIntelUsageStatistics!
+    # The repositories being transferred between gitserver instances after gitserver instances were added
+    # or removed. Only visible to site admins.
+    gitserverRepoMigrations: [GitserverRepoMigration!]!
+}
+
+# A repository being transferred to the gitserver instance that it is now assigned to from the
+# gitserver instance that previously had it cloned. While the transfer is in progress, reads are
+# served by the previous gitserver instance.
+type GitserverRepoMigration {
+    # The name of the repository.
+    repositoryName: String!
+    # The address of the gitserver instance the repository is transferred from.
+    from: String!
+    # The address of the gitserver instance the repository is transferred to.
+    to: String!
+    # The state of the transfer: QUEUED, TRANSFERRING, DONE or FAILED. Failed transfers are
+    # cloned from the code host instead.
+    state: String!
+    # The error that caused the transfer to fail, if any.
+    error: String
+    # The number of bytes transferred so far.
+    bytesTransferred: Float!
+    # When the state or progress of the transfer last changed.
+    updatedAt: DateTime!
 }
 
 # The configuration for a site.
@@ -4022,6 +4437,38 @@ type ProductSubscriptionStatus {
     noLicenseWarningUserCount: Int
     # The product license associated with this subscription, if any.
     license: ProductLicenseInfo
+    # The use of the licensed seats (user accounts), with daily snapshots. Only site admins can access this
+    # field.
+    seatUsage(
+        # The number of days of daily snapshots to return (including today).
+        days: Int = 30
+    ): SeatUsageReport!
+}
+
+# A report of the use of the licensed seats (user accounts) on this site.
+type SeatUsageReport {
+    # The current number of user accounts.
+    userCount: Int!
+    # The number of licensed seats, or null if it is unlimited.
+    licensedSeats: Int
+    # When the site first exceeded the licensed seats of its current license (even if it no longer does), or null if
+    # it never did.
+    overageSince: DateTime
+    # When new user accounts will be blocked if the site is in a seat overage grace period (configured with
+    # licensing.seatOverageGracePeriod in site configuration), or null otherwise.
+    gracePeriodEndsAt: DateTime
+    # The daily snapshots of seat usage, newest first. Days on which no snapshot was taken are omitted.
+    snapshots: [SeatUsageSnapshot!]!
+}
+
+# The peak number of user accounts on a day, and the number of seats licensed on that day.
+type SeatUsageSnapshot {
+    # The day (in UTC), in YYYY-MM-DD format.
+    date: String!
+    # The peak number of user accounts on the day.
+    userCount: Int!
+    # The number of licensed seats on the day, or null if it was unlimited.
+    licensedSeats: Int
 }
 
 # Information about this site's product license (which activates certain Sourcegraph features).
@@ -4150,9 +4597,37 @@ type ExtensionRegistryMutation {
         # The JavaScript bundle's "//# sourceMappingURL=" directive, if any, is ignored. When the bundle is served,
         # the source map provided here is referenced instead.
         sourceMap: String
+        # The base64-encoded ECDSA P-256 (SHA-256) signature of the bundle, in IEEE P1363 or ASN.1 DER form.
+        #
+        # This is required if the extension has a signing public key (see updateExtensionSigningPublicKey).
+        bundleSignature: String
         # Force publish even if there are warnings (such as invalid JSON warnings).
         force: Boolean = false
     ): ExtensionRegistryCreateExtensionResult!
+    # Set or clear the public key that the extension's release bundles must be signed with. If it is set, releases
+    # can only be published with a valid bundle signature, and bundles of releases that are unsigned or whose
+    # signature is invalid are not served.
+    #
+    # Only authorized extension publishers may set the key of an extension that has none. Only site admins may clear
+    # or change an extension's existing key. Releases are always verified with the key they were published with.
+    updateExtensionSigningPublicKey(
+        # The extension to update.
+        extension: ID!
+        # The base64-encoded DER (PKIX) ECDSA P-256 public key, or null to stop requiring signed releases.
+        signingPublicKey: String
+    ): ExtensionRegistryUpdateExtensionResult!
+    # Pin the extension to one of its releases, so that this site uses that release instead of the latest one,
+    # or unpin it.
+    #
+    # Only site admins may perform this mutation.
+    pinExtensionRelease(
+        # The extension to pin.
+        extension: ID!
+        # The release to pin the extension to, or null to unpin it (so that the latest release is used). For an
+        # extension on the remote registry, this is the ID of a release in RegistryExtension.releases, which
+        # identifies the release on the remote registry.
+        release: ID
+    ): ExtensionRegistryUpdateExtensionResult!
 }
 
 # The result of Mutation.extensionRegistry.createExtension.
@@ -4212,6 +4687,45 @@ type RegistryExtension implements Node {
     isWorkInProgress: Boolean!
     # Whether the viewer has admin privileges on this registry extension.
     viewerCanAdminister: Boolean!
+    # The base64-encoded DER (PKIX) ECDSA P-256 public key that the extension's release bundles are signed with,
+    # or null if releases are not signed.
+    signingPublicKey: String
+    # The release that this site is pinned to (instead of using the latest release), if any.
+    pinnedRelease: RegistryExtensionRelease
+    # The releases of the extension, newest first. For an extension on the remote registry, these are the releases
+    # that the remote registry lists.
+    releases(
+        # Returns the first n releases from the list.
+        first: Int
+    ): RegistryExtensionReleaseConnection
+}
+
+# A release of an extension in the extension registry.
+type RegistryExtensionRelease {
+    # The unique ID of the release.
+    id: ID!
+    # The version of the release, if any.
+    version: String
+    # The user who published the release, or null if the user was deleted.
+    creator: User
+    # The date when the release was published.
+    createdAt: DateTime!
+    # The URL to the bundled JavaScript source code of the release, if it is hosted on this site.
+    bundleURL: String
+    # Whether the release's bundle is signed.
+    signed: Boolean!
+    # Whether this site is pinned to this release.
+    pinned: Boolean!
+}
+
+# A list of extension releases.
+type RegistryExtensionReleaseConnection {
+    # A list of extension releases.
+    nodes: [RegistryExtensionRelease!]!
+    # The total count of releases in the connection.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
 }
 
 # A description of the extension, how to run or access it, and when to activate it.
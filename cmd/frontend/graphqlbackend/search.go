@@ -17,6 +17,7 @@ import (
 	"github.com/neelance/parallel"
 	"github.com/pkg/errors"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
@@ -130,8 +131,21 @@ func NewSearchImplementer(args *SearchArgs) (SearchImplementer, error) {
 	}, nil
 }
 
-func (r *schemaResolver) Search(args *SearchArgs) (SearchImplementer, error) {
-	return NewSearchImplementer(args)
+func (r *schemaResolver) Search(ctx context.Context, args *SearchArgs) (SearchImplementer, error) {
+	// 🚨 SECURITY: Access tokens must grant the "search:read" scope to run searches.
+	if err := authz.CheckScope(ctx, authz.ScopeSearchRead); err != nil {
+		return nil, err
+	}
+	sr, err := NewSearchImplementer(args)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := sr.(*searchResolver); ok {
+		// Only record searches that users run with this field in their search history (not
+		// searches that Sourcegraph runs internally).
+		return &searchHistoryRecorder{searchResolver: r}, nil
+	}
+	return sr, nil
 }
 
 // queryForStableResults transforms a query that returns a stable result
@@ -965,11 +979,16 @@ func (r *searchSuggestionResolver) ToLanguage() (*languageResolver, bool) {
 	return res, ok
 }
 
+func (r *searchSuggestionResolver) ToFrequentSearch() (*frequentSearchResolver, bool) {
+	res, ok := r.result.(*frequentSearchResolver)
+	return res, ok
+}
+
 // newSearchSuggestionResolver returns a new searchSuggestionResolver wrapping the
 // given result.
 //
 // A panic occurs if the type of result is not a *RepositoryResolver, *GitTreeEntryResolver,
-// *searchSymbolResult or *languageResolver.
+// *searchSymbolResult, *languageResolver or *frequentSearchResolver.
 func newSearchSuggestionResolver(result interface{}, score int) *searchSuggestionResolver {
 	switch r := result.(type) {
 	case *RepositoryResolver:
@@ -984,6 +1003,9 @@ func newSearchSuggestionResolver(result interface{}, score int) *searchSuggestio
 	case *languageResolver:
 		return &searchSuggestionResolver{result: r, score: score, length: len(r.Name()), label: r.Name()}
 
+	case *frequentSearchResolver:
+		return &searchSuggestionResolver{result: r, score: score, length: len(r.Query()), label: r.Query()}
+
 	default:
 		panic("never here")
 	}
@@ -29,6 +29,7 @@ type commitSearchResultResolver struct {
 	commit         *GitCommitResolver
 	refs           []*GitRefResolver
 	sourceRefs     []*GitRefResolver
+	containingRefs []*GitRefResolver
 	messagePreview *highlightedString
 	diffPreview    *highlightedString
 	icon           string
@@ -41,6 +42,7 @@ type commitSearchResultResolver struct {
 func (r *commitSearchResultResolver) Commit() *GitCommitResolver         { return r.commit }
 func (r *commitSearchResultResolver) Refs() []*GitRefResolver            { return r.refs }
 func (r *commitSearchResultResolver) SourceRefs() []*GitRefResolver      { return r.sourceRefs }
+func (r *commitSearchResultResolver) ContainingRefs() []*GitRefResolver  { return r.containingRefs }
 func (r *commitSearchResultResolver) MessagePreview() *highlightedString { return r.messagePreview }
 func (r *commitSearchResultResolver) DiffPreview() *highlightedString    { return r.diffPreview }
 func (r *commitSearchResultResolver) Icon() string {
@@ -124,6 +126,7 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 		args = append(args, "--regexp-ignore-case")
 	}
 
+	var refGlobs []git.RefGlob
 	for _, rev := range op.RepoRevs.Revs {
 		switch {
 		case rev.RevSpec != "":
@@ -138,12 +141,24 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 			args = append(args, rev.RevSpec)
 
 		case rev.RefGlob != "":
-			args = append(args, "--glob="+rev.RefGlob)
+			refGlobs = append(refGlobs, git.RefGlob{Include: rev.RefGlob})
 
 		case rev.ExcludeRefGlob != "":
-			args = append(args, "--exclude="+rev.ExcludeRefGlob)
+			refGlobs = append(refGlobs, git.RefGlob{Exclude: rev.ExcludeRefGlob})
 		}
 	}
+	refsValues, minusRefsValues := op.Query.StringValues(query.FieldRefs)
+	for _, s := range refsValues {
+		refGlobs = append(refGlobs, git.RefGlob{Include: s})
+	}
+	for _, s := range minusRefsValues {
+		refGlobs = append(refGlobs, git.RefGlob{Exclude: s})
+	}
+
+	merges, err := commitSearchMerges(op.Query)
+	if err != nil {
+		return nil, false, false, err
+	}
 
 	beforeValues, _ := op.Query.StringValues(query.FieldBefore)
 	for _, s := range beforeValues {
@@ -227,6 +242,9 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 			Diff:              op.Diff,
 			OnlyMatchingHunks: true,
 			Args:              args,
+			RefGlobs:          refGlobs,
+			Merges:            merges,
+			FirstParent:       op.Query.BoolValue(query.FieldFirstParent),
 		},
 	}
 
@@ -259,6 +277,7 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 		}
 		addRefs(&results[i].refs, rawResult.Refs)
 		addRefs(&results[i].sourceRefs, rawResult.SourceRefs)
+		addRefs(&results[i].containingRefs, rawResult.ContainingRefs)
 		var matchBody string
 		var matchHighlights []*highlightedRange
 		// TODO(sqs): properly combine message: and term values for type:commit searches
@@ -305,6 +324,13 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 		}
 
 		results[i].detail = fmt.Sprintf("[`%v` %v](%v)", commitHash, timeagoConfig.Format(rawResult.Commit.Author.Date), url)
+		if len(results[i].containingRefs) > 0 {
+			names := make([]string, len(results[i].containingRefs))
+			for j, ref := range results[i].containingRefs {
+				names[j] = "`" + ref.DisplayName() + "`"
+			}
+			results[i].detail += " in " + strings.Join(names, ", ")
+		}
 		results[i].url = url
 		results[i].icon = commitIcon
 		match := &searchResultMatchResolver{body: matchBody, highlights: matchHighlights, url: url}
@@ -315,6 +341,24 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 	return results, limitHit, timedOut, nil
 }
 
+// commitSearchMerges returns how merge commits are searched according to the
+// merges: field of q. Merge commits are not searched by default.
+func commitSearchMerges(q query.QueryInfo) (git.MergesMode, error) {
+	value, _ := q.StringValue(query.FieldMerges)
+	if value == "" {
+		return git.MergesExclude, nil
+	}
+	switch parseYesNoOnly(value) {
+	case Yes, True:
+		return git.MergesInclude, nil
+	case No, False:
+		return git.MergesExclude, nil
+	case Only:
+		return git.MergesOnly, nil
+	}
+	return "", fmt.Errorf("invalid value for merges: %q (must be yes, no or only)", value)
+}
+
 func cleanDiffPreview(highlights []*highlightedRange, rawDiffResult string) (string, []*highlightedRange) {
 	// A map of line number to number of lines that have been ignored before the particular line number.
 	lineByCountIgnored := make(map[int]int32)
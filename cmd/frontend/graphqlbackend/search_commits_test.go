@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -97,6 +98,72 @@ func TestSearchCommitsInRepo(t *testing.T) {
 	}
 }
 
+func TestSearchCommitsInRepo_refGlobsAndMerges(t *testing.T) {
+	ctx := context.Background()
+
+	git.Mocks.RawLogDiffSearch = func(opt git.RawLogDiffSearchOptions) ([]*git.LogCommitSearchResult, bool, error) {
+		if want := []git.RefGlob{
+			{Include: "refs/heads/release/*"},
+			{Include: "heads/hotfix/*"},
+			{Exclude: "refs/heads/hotfix/old"},
+		}; !reflect.DeepEqual(opt.RefGlobs, want) {
+			t.Errorf("got ref globs %v, want %v", opt.RefGlobs, want)
+		}
+		if want := git.MergesOnly; opt.Merges != want {
+			t.Errorf("got merges %q, want %q", opt.Merges, want)
+		}
+		if !opt.FirstParent {
+			t.Error("got !FirstParent")
+		}
+		return []*git.LogCommitSearchResult{{
+			Commit:         git.Commit{ID: "c1"},
+			ContainingRefs: []string{"refs/heads/release/1"},
+		}}, true, nil
+	}
+	defer git.ResetMocks()
+
+	q, err := query.ParseAndCheck("type:commit merges:only firstparent:yes refs:heads/hotfix/* -refs:refs/heads/hotfix/old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _, _, err := searchCommitsInRepo(ctx, search.CommitParameters{
+		RepoRevs: &search.RepositoryRevisions{
+			Repo: &types.Repo{ID: 1, Name: "repo"},
+			Revs: []search.RevisionSpecifier{{RefGlob: "refs/heads/release/*"}},
+		},
+		PatternInfo: &search.CommitPatternInfo{FileMatchLimit: int32(defaultMaxSearchResults)},
+		Query:       q,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	var refs []string
+	for _, ref := range results[0].ContainingRefs() {
+		refs = append(refs, ref.Name())
+	}
+	if want := []string{"refs/heads/release/1"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("got containing refs %v, want %v", refs, want)
+	}
+	if want := " in `release/1`"; !strings.HasSuffix(results[0].detail, want) {
+		t.Errorf("got detail %q, want suffix %q", results[0].detail, want)
+	}
+
+	q, err = query.ParseAndCheck("type:commit merges:sometimes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := searchCommitsInRepo(ctx, search.CommitParameters{
+		RepoRevs:    &search.RepositoryRevisions{Repo: &types.Repo{ID: 1, Name: "repo"}},
+		PatternInfo: &search.CommitPatternInfo{FileMatchLimit: int32(defaultMaxSearchResults)},
+		Query:       q,
+	}); err == nil {
+		t.Error("expected error for invalid merges: value")
+	}
+}
+
 func (r *commitSearchResultResolver) String() string {
 	return fmt.Sprintf("{commit: %+v diffPreview: %+v messagePreview: %+v}", r.commit, r.diffPreview, r.messagePreview)
 }
@@ -0,0 +1,297 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// searchTypeName returns the name of the search type, as used in the SearchPatternType GraphQL enum.
+func searchTypeName(t query.SearchType) string {
+	switch t {
+	case query.SearchTypeRegex:
+		return "regexp"
+	case query.SearchTypeLiteral:
+		return "literal"
+	case query.SearchTypeStructural:
+		return "structural"
+	}
+	return ""
+}
+
+// searchHistoryRecorder is the SearchImplementer for searches that users run with the top-level
+// GraphQL search field. It records them in the user's search history when their results are
+// resolved.
+type searchHistoryRecorder struct {
+	*searchResolver
+}
+
+func (r *searchHistoryRecorder) Results(ctx context.Context) (*SearchResultsResolver, error) {
+	start := time.Now()
+	rr, err := r.searchResolver.Results(ctx)
+	if err == nil && rr != nil {
+		r.recordSearchHistory(ctx, rr, time.Since(start))
+	}
+	return rr, err
+}
+
+// goRecordSearchHistory runs f in a new goroutine. Tests mock it to wait for f to return.
+var goRecordSearchHistory = func(f func()) { go f() }
+
+// recordSearchHistory records the search in the current user's search history in the background,
+// so that it does not delay the search results.
+//
+// Only searches that users perform in the web app are recorded (not API requests or subsequent pages
+// of paginated searches), and only if search history is enabled on the site and the user has not
+// opted out with the "search.recordHistory" setting.
+func (r *searchResolver) recordSearchHistory(ctx context.Context, rr *SearchResultsResolver, latency time.Duration) {
+	if trace.RequestSource(ctx) != trace.SourceBrowser || (r.pagination != nil && r.pagination.cursor != nil) {
+		return
+	}
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() || !conf.SearchHistoryEnabled() {
+		return
+	}
+	entry := &db.SearchHistoryEntry{
+		UserID:      a.UID,
+		Query:       r.rawQuery(),
+		PatternType: searchTypeName(r.patternType),
+		ResultCount: rr.MatchCount(),
+		LatencyMs:   int32(latency / time.Millisecond),
+	}
+
+	goRecordSearchHistory(func() {
+		// Don't use the request's context, which is canceled when the response is sent.
+		ctx, cancel := context.WithTimeout(actor.WithActor(context.Background(), a), time.Minute)
+		defer cancel()
+
+		if !searchHistoryRecordingAllowed(ctx) {
+			return
+		}
+		if err := db.SearchHistory.Insert(ctx, entry); err != nil {
+			log15.Warn("Could not record search history", "err", err)
+		}
+	})
+}
+
+// searchHistoryRecordingAllowed reports whether the current user's searches may be recorded in (and
+// suggested from) their search history: search history must be enabled on the site, and the user
+// must not have opted out with the "search.recordHistory" setting.
+func searchHistoryRecordingAllowed(ctx context.Context) bool {
+	if !conf.SearchHistoryEnabled() {
+		return false
+	}
+	settings, err := decodedViewerFinalSettings(ctx)
+	if err != nil {
+		log15.Warn("Could not read settings to check whether search history is enabled", "err", err)
+		return false
+	}
+	return settings.SearchRecordHistory == nil || *settings.SearchRecordHistory
+}
+
+type searchHistoryConnectionArgs struct {
+	First int32
+	After *string
+}
+
+func (a *searchHistoryConnectionArgs) limit() int {
+	if a.First < 0 {
+		return 0
+	}
+	return int(a.First)
+}
+
+func (r *UserResolver) RecentSearches(ctx context.Context, args *searchHistoryConnectionArgs) (*searchHistoryConnectionResolver, error) {
+	// 🚨 SECURITY: Search history can only be viewed by the user or site admin.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return nil, err
+	}
+	opt := db.SearchHistoryListOptions{UserID: r.user.ID, LimitOffset: &db.LimitOffset{Limit: args.limit()}}
+	if args.After != nil {
+		if err := relay.UnmarshalSpec(graphql.ID(*args.After), &opt.BeforeID); err != nil {
+			return nil, errors.Wrap(err, "invalid after cursor")
+		}
+	}
+	return &searchHistoryConnectionResolver{opt: opt}, nil
+}
+
+// searchHistoryConnectionResolver resolves a list of a user's recent searches. It is paginated by
+// the ID of the last entry on the previous page.
+type searchHistoryConnectionResolver struct {
+	opt db.SearchHistoryListOptions
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	entries []*db.SearchHistoryEntry
+	err     error
+}
+
+func (r *searchHistoryConnectionResolver) compute(ctx context.Context) ([]*db.SearchHistoryEntry, error) {
+	r.once.Do(func() {
+		opt := r.opt
+		if opt.LimitOffset != nil {
+			tmp := *opt.LimitOffset
+			opt.LimitOffset = &tmp
+			opt.Limit++ // so we can detect if there is a next page
+		}
+		r.entries, r.err = db.SearchHistory.ListRecent(ctx, opt)
+	})
+	return r.entries, r.err
+}
+
+func (r *searchHistoryConnectionResolver) Nodes(ctx context.Context) ([]*searchHistoryEntryResolver, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(entries) > r.opt.Limit {
+		entries = entries[:r.opt.Limit]
+	}
+	resolvers := make([]*searchHistoryEntryResolver, len(entries))
+	for i, e := range entries {
+		resolvers[i] = &searchHistoryEntryResolver{entry: e}
+	}
+	return resolvers, nil
+}
+
+func (r *searchHistoryConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opt := r.opt
+	opt.BeforeID = 0
+	count, err := db.SearchHistory.CountRecent(ctx, opt)
+	return int32(count), err
+}
+
+func (r *searchHistoryConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset == nil || len(entries) <= r.opt.Limit || r.opt.Limit == 0 {
+		return graphqlutil.HasNextPage(false), nil
+	}
+	return graphqlutil.NextPageCursor(string(relay.MarshalID("SearchHistoryEntry", entries[r.opt.Limit-1].ID))), nil
+}
+
+type searchHistoryEntryResolver struct {
+	entry *db.SearchHistoryEntry
+}
+
+func (r *searchHistoryEntryResolver) Query() string       { return r.entry.Query }
+func (r *searchHistoryEntryResolver) PatternType() string { return r.entry.PatternType }
+func (r *searchHistoryEntryResolver) ResultCount() int32  { return r.entry.ResultCount }
+func (r *searchHistoryEntryResolver) LatencyMilliseconds() int32 {
+	return r.entry.LatencyMs
+}
+func (r *searchHistoryEntryResolver) CreatedAt() DateTime { return DateTime{Time: r.entry.CreatedAt} }
+
+func (r *UserResolver) FrequentSearches(ctx context.Context, args *searchHistoryConnectionArgs) (*frequentSearchConnectionResolver, error) {
+	// 🚨 SECURITY: Search history can only be viewed by the user or site admin.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return nil, err
+	}
+	opt := db.SearchHistoryListOptions{UserID: r.user.ID, LimitOffset: &db.LimitOffset{Limit: args.limit()}}
+	if args.After != nil {
+		offset, err := strconv.Atoi(*args.After)
+		if err != nil || offset < 0 {
+			return nil, errors.Errorf("invalid after cursor %q", *args.After)
+		}
+		opt.Offset = offset
+	}
+	return &frequentSearchConnectionResolver{opt: opt}, nil
+}
+
+// frequentSearchConnectionResolver resolves a list of a user's most frequent searches. It is
+// paginated by offset, because the list is ordered by the number of times each search was performed.
+type frequentSearchConnectionResolver struct {
+	opt db.SearchHistoryListOptions
+
+	// cache results because they are used by multiple fields
+	once     sync.Once
+	searches []*db.FrequentSearch
+	err      error
+}
+
+func (r *frequentSearchConnectionResolver) compute(ctx context.Context) ([]*db.FrequentSearch, error) {
+	r.once.Do(func() {
+		opt := r.opt
+		if opt.LimitOffset != nil {
+			tmp := *opt.LimitOffset
+			opt.LimitOffset = &tmp
+			opt.Limit++ // so we can detect if there is a next page
+		}
+		r.searches, r.err = db.SearchHistory.ListFrequent(ctx, opt)
+	})
+	return r.searches, r.err
+}
+
+func (r *frequentSearchConnectionResolver) Nodes(ctx context.Context) ([]*frequentSearchResolver, error) {
+	searches, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(searches) > r.opt.Limit {
+		searches = searches[:r.opt.Limit]
+	}
+	resolvers := make([]*frequentSearchResolver, len(searches))
+	for i, s := range searches {
+		resolvers[i] = &frequentSearchResolver{search: s}
+	}
+	return resolvers, nil
+}
+
+func (r *frequentSearchConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.SearchHistory.CountFrequent(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *frequentSearchConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	searches, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset == nil || len(searches) <= r.opt.Limit || r.opt.Limit == 0 {
+		return graphqlutil.HasNextPage(false), nil
+	}
+	return graphqlutil.NextPageCursor(strconv.Itoa(r.opt.Offset + r.opt.Limit)), nil
+}
+
+type frequentSearchResolver struct {
+	search *db.FrequentSearch
+}
+
+func (r *frequentSearchResolver) Query() string       { return r.search.Query }
+func (r *frequentSearchResolver) PatternType() string { return r.search.PatternType }
+func (r *frequentSearchResolver) Count() int32        { return r.search.Count }
+func (r *frequentSearchResolver) LastSearchedAt() DateTime {
+	return DateTime{Time: r.search.LastSearchedAt}
+}
+
+func (r *schemaResolver) DeleteSearchHistory(ctx context.Context, args *struct {
+	User graphql.ID
+}) (*EmptyResponse, error) {
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: Only the user and site admins can delete a user's search history.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := db.SearchHistory.DeleteByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
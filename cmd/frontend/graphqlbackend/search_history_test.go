@@ -0,0 +1,219 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSearchResolver_recordSearchHistory(t *testing.T) {
+	defer resetMocks()
+	defer conf.Mock(nil)
+	defer func() { mockDecodedViewerFinalSettings = nil }()
+	goRecordSearchHistory = func(f func()) { f() }
+	defer func() { goRecordSearchHistory = func(f func()) { go f() } }()
+
+	disabled := false
+	browser := trace.WithRequestSource(actor.WithActor(context.Background(), &actor.Actor{UID: 1}), trace.SourceBrowser)
+	tests := map[string]struct {
+		ctx        context.Context
+		site       *schema.SearchHistory
+		settings   *schema.Settings
+		pagination *searchPaginationInfo
+		want       bool
+	}{
+		"recorded": {
+			ctx:  browser,
+			want: true,
+		},
+		"first page of paginated search": {
+			ctx:        browser,
+			pagination: &searchPaginationInfo{limit: 10},
+			want:       true,
+		},
+		"next page of paginated search": {
+			ctx:        browser,
+			pagination: &searchPaginationInfo{cursor: &searchCursor{RepositoryOffset: 1}, limit: 10},
+		},
+		"API request": {
+			ctx: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+		},
+		"anonymous user": {
+			ctx: trace.WithRequestSource(context.Background(), trace.SourceBrowser),
+		},
+		"disabled in site configuration": {
+			ctx:  browser,
+			site: &schema.SearchHistory{Enabled: &disabled},
+		},
+		"user opted out": {
+			ctx:      browser,
+			settings: &schema.Settings{SearchRecordHistory: &disabled},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resetMocks()
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{SearchHistory: test.site}})
+			mockDecodedViewerFinalSettings = test.settings
+			if mockDecodedViewerFinalSettings == nil {
+				mockDecodedViewerFinalSettings = &schema.Settings{}
+			}
+			var got *db.SearchHistoryEntry
+			db.Mocks.SearchHistory.Insert = func(e *db.SearchHistoryEntry) error {
+				got = e
+				return nil
+			}
+
+			r := &searchResolver{originalQuery: "foo repo:bar", patternType: query.SearchTypeRegex, pagination: test.pagination}
+			rr := &SearchResultsResolver{SearchResults: []SearchResultResolver{&RepositoryResolver{}, &RepositoryResolver{}}}
+			r.recordSearchHistory(test.ctx, rr, 1500*time.Millisecond)
+
+			if !test.want {
+				if got != nil {
+					t.Errorf("got recorded entry %+v, want none", got)
+				}
+				return
+			}
+			want := db.SearchHistoryEntry{UserID: 1, Query: "foo repo:bar", PatternType: "regexp", ResultCount: 2, LatencyMs: 1500}
+			if got == nil || *got != want {
+				t.Errorf("got recorded entry %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSearch_recordsSearchHistory(t *testing.T) {
+	args := &SearchArgs{Query: "foo", Version: "V2"}
+	sr, err := (&schemaResolver{}).Search(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sr.(*searchHistoryRecorder); !ok {
+		t.Errorf("got Search %T, want *searchHistoryRecorder", sr)
+	}
+
+	// Searches that Sourcegraph runs internally are not recorded.
+	sr, err = NewSearchImplementer(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sr.(*searchHistoryRecorder); ok {
+		t.Error("got NewSearchImplementer *searchHistoryRecorder, want internal searches not to be recorded")
+	}
+}
+
+func TestUser_RecentSearches(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	createdAt := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	db.Mocks.SearchHistory.ListRecent = func(opt db.SearchHistoryListOptions) ([]*db.SearchHistoryEntry, error) {
+		if opt.UserID != 1 || opt.BeforeID != 10 || opt.Limit != 2 {
+			t.Errorf("got unexpected options %+v", opt)
+		}
+		return []*db.SearchHistoryEntry{
+			{ID: 9, Query: "a", PatternType: "literal", ResultCount: 3, LatencyMs: 120, CreatedAt: createdAt},
+			{ID: 8, Query: "b", PatternType: "regexp", ResultCount: 0, LatencyMs: 80, CreatedAt: createdAt},
+		}, nil
+	}
+	db.Mocks.SearchHistory.CountRecent = func(opt db.SearchHistoryListOptions) (int, error) {
+		if opt.BeforeID != 0 {
+			t.Errorf("got BeforeID %d, want 0", opt.BeforeID)
+		}
+		return 5, nil
+	}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			Schema:  mustParseGraphQLSchema(t),
+			Query: `
+				{
+					node(id: "VXNlcjox") {
+						... on User {
+							recentSearches(first: 1, after: "U2VhcmNoSGlzdG9yeUVudHJ5OjEw") {
+								nodes {
+									query
+									patternType
+									resultCount
+									latencyMilliseconds
+									createdAt
+								}
+								totalCount
+								pageInfo {
+									hasNextPage
+									endCursor
+								}
+							}
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"node": {
+						"recentSearches": {
+							"nodes": [
+								{
+									"query": "a",
+									"patternType": "literal",
+									"resultCount": 3,
+									"latencyMilliseconds": 120,
+									"createdAt": "2020-01-02T00:00:00Z"
+								}
+							],
+							"totalCount": 5,
+							"pageInfo": {
+								"hasNextPage": true,
+								"endCursor": "U2VhcmNoSGlzdG9yeUVudHJ5Ojk="
+							}
+						}
+					}
+				}
+			`,
+		},
+	})
+}
+
+// 🚨 SECURITY: This tests that users can't view or delete other users' search history.
+func TestSearchHistory_otherUser(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	db.Mocks.SearchHistory.DeleteByUserID = func(userID int32) error {
+		t.Errorf("search history of user %d was deleted", userID)
+		return nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "bob"}, nil
+	}
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	user := &UserResolver{user: &types.User{ID: 2}}
+	if _, err := user.RecentSearches(ctx, &searchHistoryConnectionArgs{}); err == nil {
+		t.Error("RecentSearches: err == nil")
+	}
+	if _, err := user.FrequentSearches(ctx, &searchHistoryConnectionArgs{}); err == nil {
+		t.Error("FrequentSearches: err == nil")
+	}
+	if _, err := (&schemaResolver{}).DeleteSearchHistory(ctx, &struct{ User graphql.ID }{User: MarshalUserID(2)}); err == nil {
+		t.Error("DeleteSearchHistory: err == nil")
+	}
+}
@@ -0,0 +1,92 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// ownerFilter applies the owner: and -owner: filters of a query to file
+// matches. It is applied to the matches of each repository before they count
+// towards the result limit, so a search that hit its limit still returns
+// as many results as the limit allows.
+type ownerFilter struct {
+	include, exclude []string
+}
+
+// newOwnerFilter returns the owner filter of q, or nil if q has no owner:
+// or -owner: filters.
+func newOwnerFilter(q query.QueryInfo) *ownerFilter {
+	include, exclude := q.StringValues(query.FieldOwner)
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	return &ownerFilter{include: include, exclude: exclude}
+}
+
+// ownerFilterResultTypes returns the result types that an owner filter can be
+// applied to. Only files have owners, so repository, commit and diff results
+// are never returned when an owner filter is given.
+func ownerFilterResultTypes(resultTypes []string) []string {
+	var filtered []string
+	for _, resultType := range resultTypes {
+		if resultType == "file" || resultType == "path" || resultType == "symbol" {
+			filtered = append(filtered, resultType)
+		}
+	}
+	return filtered
+}
+
+// filter returns the matches owned by all owners included by f and by none
+// of the owners it excludes. A nil filter returns matches unchanged.
+func (f *ownerFilter) filter(ctx context.Context, matches []*FileMatchResolver) ([]*FileMatchResolver, error) {
+	if f == nil || len(matches) == 0 {
+		return matches, nil
+	}
+
+	type repoCommit struct {
+		repo   api.RepoName
+		commit api.CommitID
+	}
+	rulesets := map[repoCommit]*codeowners.Ruleset{}
+
+	filtered := make([]*FileMatchResolver, 0, len(matches))
+	for _, fm := range matches {
+		key := repoCommit{repo: fm.Repo.Name, commit: fm.CommitID}
+		rs, ok := rulesets[key]
+		if !ok {
+			var err error
+			rs, err = codeowners.ForCommit(ctx, gitserver.Repo{Name: fm.Repo.Name}, fm.CommitID)
+			if err != nil {
+				return nil, err
+			}
+			rulesets[key] = rs
+		}
+
+		if ownedByAll(rs, fm.JPath, f.include) && !ownedByAny(rs, fm.JPath, f.exclude) {
+			filtered = append(filtered, fm)
+		}
+	}
+	return filtered, nil
+}
+
+func ownedByAll(rs *codeowners.Ruleset, path string, owners []string) bool {
+	for _, owner := range owners {
+		if !rs.OwnedBy(path, owner) {
+			return false
+		}
+	}
+	return true
+}
+
+func ownedByAny(rs *codeowners.Ruleset, path string, owners []string) bool {
+	for _, owner := range owners {
+		if rs.OwnedBy(path, owner) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package graphqlbackend
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestOwnerFilter(t *testing.T) {
+	defer git.ResetMocks()
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if commit == "owners-c1" && name == ".github/CODEOWNERS" {
+			return []byte("*.go @alice\n/docs/ @org/writers @alice\n"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	repo := &types.Repo{Name: "r"}
+	fileMatch := func(path string) *FileMatchResolver {
+		return &FileMatchResolver{JPath: path, Repo: repo, CommitID: "owners-c1"}
+	}
+	matches := func() []*FileMatchResolver {
+		return []*FileMatchResolver{
+			fileMatch("main.go"),
+			fileMatch("README.md"),
+			fileMatch("docs/index.md"),
+		}
+	}
+
+	tests := map[string][]string{
+		"foo":                            {"main.go", "README.md", "docs/index.md"},
+		"owner:alice":                    {"main.go", "docs/index.md"},
+		"owner:@org/writers":             {"docs/index.md"},
+		"owner:alice -owner:org/writers": {"main.go"},
+		"-owner:alice":                   {"README.md"},
+		"owner:bob":                      nil,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			q, err := query.ParseAndCheck(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filtered, err := newOwnerFilter(q).filter(context.Background(), matches())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, fm := range filtered {
+				got = append(got, fm.JPath)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestOwnerFilterResultTypes(t *testing.T) {
+	tests := map[string][]string{
+		"foo":                        {"file", "path", "repo"},
+		"foo owner:alice":            {"file", "path"},
+		"foo type:symbol owner:bob":  {"symbol"},
+		"foo type:commit owner:bob":  nil,
+		"foo type:commit -owner:bob": nil,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			q, err := query.ParseAndCheck(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &searchResolver{query: q}
+			got := r.determineResultTypes(search.TextParameters{PatternInfo: &search.TextPatternInfo{}}, "")
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -686,6 +686,7 @@ func intersect(left, right *SearchResultsResolver) (*SearchResultsResolver, erro
 		}
 
 		ltmpFileMatch.JLineMatches = append(ltmpFileMatch.JLineMatches, rtmpFileMatch.JLineMatches...)
+		ltmpFileMatch.JMultilineMatches = append(ltmpFileMatch.JMultilineMatches, rtmpFileMatch.JMultilineMatches...)
 		merged = append(merged, ltmp)
 	}
 	left.SearchResults = merged
@@ -1174,6 +1175,7 @@ func getPatternInfo(q query.QueryInfo, opts *getPatternInfoOptions) (*search.Tex
 		IsRegExp:                     isRegExp,
 		IsStructuralPat:              isStructuralPat,
 		IsCaseSensitive:              q.IsCaseSensitive(),
+		IsMultiline:                  q.BoolValue(query.FieldMultiline),
 		FileMatchLimit:               opts.fileMatchLimit,
 		Pattern:                      pattern,
 		IncludePatterns:              includePatterns,
@@ -1187,6 +1189,15 @@ func getPatternInfo(q query.QueryInfo, opts *getPatternInfoOptions) (*search.Tex
 	if len(excludePatterns) > 0 {
 		patternInfo.ExcludePattern = unionRegExps(excludePatterns)
 	}
+
+	// Handle blameauthor:, -blameauthor:, blameafter: and blamebefore: filters.
+	blameAuthorPatterns, blameExcludeAuthorPatterns := q.RegexpPatterns(query.FieldBlameAuthor)
+	patternInfo.BlameAuthorPatterns = blameAuthorPatterns
+	if len(blameExcludeAuthorPatterns) > 0 {
+		patternInfo.BlameExcludeAuthorPattern = unionRegExps(blameExcludeAuthorPatterns)
+	}
+	patternInfo.BlameAfter, _ = q.StringValue(query.FieldBlameAfter)
+	patternInfo.BlameBefore, _ = q.StringValue(query.FieldBlameBefore)
 	return patternInfo, nil
 }
 
@@ -1265,6 +1276,9 @@ func (r *searchResolver) determineResultTypes(args search.TextParameters, forceO
 			resultTypes = []string{"file", "path", "repo"}
 		}
 	}
+	if newOwnerFilter(r.query) != nil {
+		resultTypes = ownerFilterResultTypes(resultTypes)
+	}
 	for _, resultType := range resultTypes {
 		if resultType == "file" {
 			args.PatternInfo.PatternMatchesContent = true
@@ -1430,6 +1444,19 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 	// repos, and removes the diff and commit resultTypes if it is breached.
 	resultTypes, alert = alertOnSearchLimit(resultTypes, &args)
 
+	cacheKey, cacheable := r.searchResultsCacheKey(ctx, &args, resultTypes)
+	if cacheable {
+		if rr, ok := getCachedSearchResults(cacheKey, &args, common); ok {
+			rr.start = start
+			if len(missingRepoRevs) > 0 {
+				rr.alert = alertForMissingRepoRevs(r.patternType, missingRepoRevs)
+			}
+			return rr, nil
+		}
+	} else if conf.SearchResultsCacheEnabled() {
+		searchResultsCacheCounter.WithLabelValues("uncacheable").Inc()
+	}
+
 	searchedFileContentsOrPaths := false
 	for _, resultType := range resultTypes {
 		resultType := resultType // shadow so it doesn't change in the goroutine
@@ -1537,6 +1564,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 						// merge line match results with an existing symbol result
 						m.JLimitHit = m.JLimitHit || r.JLimitHit
 						m.JLineMatches = r.JLineMatches
+						m.JMultilineMatches = r.JMultilineMatches
 					} else {
 						fileMatches[key] = r
 						resultsMu.Lock()
@@ -1702,6 +1730,10 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 		alert:               alert,
 	}
 
+	if cacheable && multiErr == nil && len(missingRepoRevs) == 0 {
+		setCachedSearchResults(cacheKey, &resultsResolver)
+	}
+
 	return &resultsResolver, multiErr.ErrorOrNil()
 }
 
@@ -0,0 +1,377 @@
+package graphqlbackend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// The search results cache stores complete results of repository, file and
+// path searches. Entries are keyed on the normalized query and the commits
+// that are searched in every repository (the commit indexed by zoekt and the
+// commit the revision resolves to on gitserver), so they are invalidated
+// naturally when a repository changes or is reindexed. The set of searched
+// repositories is part of the key, so users with access to different
+// repositories never share entries.
+
+const (
+	// searchResultsCacheVersion is part of every cache key. Bump it when
+	// the cached data changes.
+	searchResultsCacheVersion = 1
+
+	// searchResultsCacheMaxRepos is the maximum number of repositories a
+	// search can have to be cached. Resolving the commits of more
+	// repositories would take longer than we expect to save.
+	searchResultsCacheMaxRepos = 500
+
+	// searchResultsCacheResolveConcurrency is the maximum number of
+	// repositories whose commits are resolved concurrently for a cache key.
+	searchResultsCacheResolveConcurrency = 16
+
+	// searchResultsCacheMaxSize is the maximum size of a cache entry in
+	// bytes.
+	searchResultsCacheMaxSize = 1 << 20
+)
+
+var (
+	searchResultsCache        = rcache.NewWithTTL("search_results", 3600) // 1h
+	searchResultsCacheCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "graphql",
+		Name:      "search_results_cache_total",
+		Help:      "Counts cache hits, misses and searches that could not be cached.",
+	}, []string{"type"})
+)
+
+// cachedSearchResults is the cached form of a SearchResultsResolver.
+// Repositories are referenced by name and resolved from the repositories of
+// the search, which are part of the cache key.
+type cachedSearchResults struct {
+	Results     []cachedSearchResult
+	LimitHit    bool
+	Repos       []api.RepoName
+	Searched    []api.RepoName
+	Indexed     []api.RepoName
+	Partial     []api.RepoName
+	ResultCount int32
+}
+
+type cachedSearchResult struct {
+	Repo      api.RepoName     // set for repository results
+	RepoIcon  string           `json:",omitempty"`
+	FileMatch *cachedFileMatch `json:",omitempty"`
+}
+
+type cachedFileMatch struct {
+	Path        string
+	LineMatches []*lineMatch
+	// MultilineMatches is set instead of LineMatches for multiline searches.
+	MultilineMatches []*multilineMatch `json:",omitempty"`
+	LimitHit         bool
+	MatchCount       int
+	URI              string
+	Repo             api.RepoName
+	CommitID         api.CommitID
+	InputRev         *string
+}
+
+var mockSearchResultsCacheKey func(args *search.TextParameters, resultTypes []string) (string, bool)
+
+// searchResultsCacheKey returns the cache key for a search of args with the
+// given result types. It resolves the commits of all searched revisions. ok
+// is false if the search can't be cached, including when the cache is not
+// enabled in the site configuration.
+func (r *searchResolver) searchResultsCacheKey(ctx context.Context, args *search.TextParameters, resultTypes []string) (key string, ok bool) {
+	if mockSearchResultsCacheKey != nil {
+		return mockSearchResultsCacheKey(args, resultTypes)
+	}
+	if !conf.SearchResultsCacheEnabled() || len(args.Repos) > searchResultsCacheMaxRepos {
+		return "", false
+	}
+	for _, t := range resultTypes {
+		// Symbol, diff and commit results can't be stored yet, and diff and
+		// commit searches can depend on the current time (after:, before:).
+		if t != "repo" && t != "file" && t != "path" {
+			return "", false
+		}
+	}
+
+	for _, repoRev := range args.Repos {
+		for _, rev := range repoRev.Revs {
+			if rev.RefGlob != "" || rev.ExcludeRefGlob != "" {
+				return "", false
+			}
+		}
+	}
+
+	// Indexed repositories are searched at the commit zoekt indexed, which
+	// can lag behind the commit their default branch resolves to.
+	indexedCommits := make(map[*search.RepositoryRevisions]api.CommitID)
+	if args.Zoekt != nil && args.Zoekt.Enabled() {
+		indexed, _, err := zoektIndexedRepos(ctx, args.Zoekt, args.Repos, nil)
+		if err != nil {
+			return "", false
+		}
+		for _, repoRev := range indexed {
+			indexedCommits[repoRev] = repoRev.IndexedHEADCommit()
+		}
+	}
+
+	// Stop resolving the remaining repositories as soon as one fails, since
+	// the search can't be cached anyway.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	revs := make([]string, len(args.Repos))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		resolved = true
+		sem      = make(chan struct{}, searchResultsCacheResolveConcurrency)
+	)
+	for i, repoRev := range args.Repos {
+		wg.Add(1)
+		go func(i int, repoRev *search.RepositoryRevisions) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				resolved = false
+				mu.Unlock()
+				return
+			}
+
+			commits, err := resolveRepoRevCommits(ctx, repoRev)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				resolved = false
+				cancel()
+				return
+			}
+			revs[i] = repoRev.String() + "=" + commits
+			if commit, ok := indexedCommits[repoRev]; ok {
+				// The search may still bypass zoekt (for example with
+				// index:no), so the gitserver commit stays in the key.
+				revs[i] += "@zoekt=" + string(commit)
+			}
+		}(i, repoRev)
+	}
+	wg.Wait()
+	if !resolved {
+		return "", false
+	}
+	sort.Strings(revs)
+
+	b, err := json.Marshal(struct {
+		Version     int
+		Query       string
+		PatternType query.SearchType
+		Pattern     *search.TextPatternInfo
+		ResultTypes []string
+		MaxResults  int32
+		Revs        []string
+	}{
+		Version:     searchResultsCacheVersion,
+		Query:       r.query.ParseTree().String(),
+		PatternType: r.patternType,
+		Pattern:     args.PatternInfo,
+		ResultTypes: resultTypes,
+		MaxResults:  r.maxResults(),
+		Revs:        revs,
+	})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// resolveRepoRevCommits returns the commits that the revisions of repoRev
+// resolve to, separated by ":".
+func resolveRepoRevCommits(ctx context.Context, repoRev *search.RepositoryRevisions) (string, error) {
+	revs := repoRev.Revs
+	if len(revs) == 0 {
+		revs = []search.RevisionSpecifier{{RevSpec: ""}}
+	}
+
+	var commits string
+	for i, rev := range revs {
+		// Like searchFilesInRepo, don't trigger a fetch of the revision.
+		commit, err := git.ResolveRevision(ctx, repoRev.GitserverRepo(), nil, rev.RevSpec, &git.ResolveRevisionOptions{NoEnsureRevision: true})
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			commits += ":"
+		}
+		commits += string(commit)
+	}
+	return commits, nil
+}
+
+// getCachedSearchResults returns the cached results for key, if any.
+func getCachedSearchResults(key string, args *search.TextParameters, common searchResultsCommon) (*SearchResultsResolver, bool) {
+	b, ok := searchResultsCache.Get(key)
+	if !ok {
+		searchResultsCacheCounter.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	var cached cachedSearchResults
+	if err := json.Unmarshal(b, &cached); err != nil {
+		log15.Warn("failed to decode cached search results", "error", err)
+		return nil, false
+	}
+
+	reposByName := make(map[api.RepoName]*types.Repo, len(args.Repos))
+	for _, repoRev := range args.Repos {
+		reposByName[repoRev.Repo.Name] = repoRev.Repo
+	}
+	// The repositories are part of the key, so every cached repository is
+	// searched again. We still check to never return a nil repository.
+	missing := false
+	repo := func(name api.RepoName) *types.Repo {
+		r, ok := reposByName[name]
+		if !ok {
+			missing = true
+		}
+		return r
+	}
+	repos := func(names []api.RepoName) []*types.Repo {
+		if len(names) == 0 {
+			return nil
+		}
+		rs := make([]*types.Repo, len(names))
+		for i, name := range names {
+			rs[i] = repo(name)
+		}
+		return rs
+	}
+
+	common.limitHit = cached.LimitHit
+	common.resultCount = cached.ResultCount
+	common.repos = repos(cached.Repos)
+	common.searched = repos(cached.Searched)
+	common.indexed = repos(cached.Indexed)
+	if len(cached.Partial) > 0 {
+		common.partial = make(map[api.RepoName]struct{}, len(cached.Partial))
+		for _, name := range cached.Partial {
+			common.partial[name] = struct{}{}
+		}
+	}
+
+	results := make([]SearchResultResolver, 0, len(cached.Results))
+	for _, res := range cached.Results {
+		if fm := res.FileMatch; fm != nil {
+			results = append(results, &FileMatchResolver{
+				JPath:             fm.Path,
+				JLineMatches:      fm.LineMatches,
+				JMultilineMatches: fm.MultilineMatches,
+				JLimitHit:         fm.LimitHit,
+				MatchCount:        fm.MatchCount,
+				uri:               fm.URI,
+				Repo:              repo(fm.Repo),
+				CommitID:          fm.CommitID,
+				InputRev:          fm.InputRev,
+			})
+			continue
+		}
+		results = append(results, &RepositoryResolver{repo: repo(res.Repo), icon: res.RepoIcon})
+	}
+	if missing {
+		return nil, false
+	}
+
+	searchResultsCacheCounter.WithLabelValues("hit").Inc()
+	return &SearchResultsResolver{
+		searchResultsCommon: common,
+		SearchResults:       results,
+	}, true
+}
+
+// setCachedSearchResults stores rr under key if it is complete.
+func setCachedSearchResults(key string, rr *SearchResultsResolver) {
+	c := rr.searchResultsCommon
+	if rr.alert != nil || len(c.cloning) > 0 || len(c.missing) > 0 || len(c.timedout) > 0 || c.indexUnavailable {
+		searchResultsCacheCounter.WithLabelValues("incomplete").Inc()
+		return
+	}
+
+	names := func(repos []*types.Repo) []api.RepoName {
+		if len(repos) == 0 {
+			return nil
+		}
+		ns := make([]api.RepoName, len(repos))
+		for i, repo := range repos {
+			ns[i] = repo.Name
+		}
+		return ns
+	}
+
+	cached := cachedSearchResults{
+		Results:     make([]cachedSearchResult, 0, len(rr.SearchResults)),
+		LimitHit:    c.limitHit,
+		Repos:       names(c.repos),
+		Searched:    names(c.searched),
+		Indexed:     names(c.indexed),
+		ResultCount: c.resultCount,
+	}
+	for name := range c.partial {
+		cached.Partial = append(cached.Partial, name)
+	}
+	sort.Slice(cached.Partial, func(i, j int) bool { return cached.Partial[i] < cached.Partial[j] })
+
+	for _, res := range rr.SearchResults {
+		switch res := res.(type) {
+		case *FileMatchResolver:
+			if len(res.symbols) > 0 {
+				searchResultsCacheCounter.WithLabelValues("uncacheable").Inc()
+				return
+			}
+			cached.Results = append(cached.Results, cachedSearchResult{FileMatch: &cachedFileMatch{
+				Path:             res.JPath,
+				LineMatches:      res.JLineMatches,
+				MultilineMatches: res.JMultilineMatches,
+				LimitHit:         res.JLimitHit,
+				MatchCount:       res.MatchCount,
+				URI:              res.uri,
+				Repo:             res.Repo.Name,
+				CommitID:         res.CommitID,
+				InputRev:         res.InputRev,
+			}})
+		case *RepositoryResolver:
+			cached.Results = append(cached.Results, cachedSearchResult{Repo: res.repo.Name, RepoIcon: res.icon})
+		default:
+			searchResultsCacheCounter.WithLabelValues("uncacheable").Inc()
+			return
+		}
+	}
+
+	b, err := json.Marshal(&cached)
+	if err != nil {
+		log15.Warn("failed to encode search results for the cache", "error", err)
+		return
+	}
+	if len(b) > searchResultsCacheMaxSize {
+		searchResultsCacheCounter.WithLabelValues("too_large").Inc()
+		return
+	}
+	searchResultsCache.Set(key, b)
+}
@@ -0,0 +1,211 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/zoekt"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	searchbackend "github.com/sourcegraph/sourcegraph/internal/search/backend"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSearchResultsCacheKey(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ExperimentalFeatures: &schema.ExperimentalFeatures{SearchResultsCache: "enabled"}}})
+	defer conf.Mock(nil)
+
+	commits := map[string]api.CommitID{"": "c1", "b": "c2"}
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		return commits[spec], nil
+	}
+	defer git.ResetMocks()
+
+	q, err := query.ParseAndCheck("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &searchResolver{query: q}
+	repoA := &search.RepositoryRevisions{Repo: &types.Repo{Name: "a"}}
+	repoB := &search.RepositoryRevisions{Repo: &types.Repo{Name: "b"}, Revs: []search.RevisionSpecifier{{RevSpec: "b"}}}
+	args := func(repos ...*search.RepositoryRevisions) *search.TextParameters {
+		return &search.TextParameters{PatternInfo: &search.TextPatternInfo{Pattern: "foo"}, Repos: repos}
+	}
+	key := func(args *search.TextParameters, resultTypes ...string) string {
+		t.Helper()
+		k, ok := r.searchResultsCacheKey(context.Background(), args, resultTypes)
+		if !ok {
+			t.Fatal("expected search to be cacheable")
+		}
+		return k
+	}
+
+	k1 := key(args(repoA, repoB), "file", "repo")
+	if k2 := key(args(repoB, repoA), "file", "repo"); k1 != k2 {
+		t.Error("expected the order of repositories not to change the key")
+	}
+	if k2 := key(args(repoA), "file", "repo"); k1 == k2 {
+		t.Error("expected a different set of repositories to change the key")
+	}
+	commits["b"] = "c3"
+	if k2 := key(args(repoA, repoB), "file", "repo"); k1 == k2 {
+		t.Error("expected a new commit to change the key")
+	}
+
+	for _, resultType := range []string{"symbol", "diff", "commit"} {
+		if _, ok := r.searchResultsCacheKey(context.Background(), args(repoA), []string{resultType}); ok {
+			t.Errorf("expected %s searches not to be cacheable", resultType)
+		}
+	}
+	glob := &search.RepositoryRevisions{Repo: &types.Repo{Name: "c"}, Revs: []search.RevisionSpecifier{{RefGlob: "refs/heads/*"}}}
+	if _, ok := r.searchResultsCacheKey(context.Background(), args(repoA, glob), []string{"file"}); ok {
+		t.Error("expected searches of ref globs not to be cacheable")
+	}
+
+	// Indexed repositories are keyed on the commit zoekt indexed.
+	indexedAt := func(version string) *searchbackend.Zoekt {
+		return &searchbackend.Zoekt{Client: &fakeSearcher{repos: &zoekt.RepoList{Repos: []*zoekt.RepoListEntry{{
+			Repository: zoekt.Repository{Name: "a", Branches: []zoekt.RepositoryBranch{{Name: "HEAD", Version: version}}},
+		}}}}}
+	}
+	indexedArgs := func(z *searchbackend.Zoekt) *search.TextParameters {
+		a := args(&search.RepositoryRevisions{Repo: &types.Repo{Name: "a"}, Revs: []search.RevisionSpecifier{{RevSpec: ""}}})
+		a.Zoekt = z
+		return a
+	}
+	k1 = key(indexedArgs(indexedAt("v1")), "file")
+	if k2 := key(indexedArgs(indexedAt("v1")), "file"); k1 != k2 {
+		t.Error("expected the same indexed commit to have the same key")
+	}
+	if k2 := key(indexedArgs(indexedAt("v2")), "file"); k1 == k2 {
+		t.Error("expected a new indexed commit to change the key")
+	}
+}
+
+func TestSearchResultsCacheKey_ResolvesConcurrently(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{ExperimentalFeatures: &schema.ExperimentalFeatures{SearchResultsCache: "enabled"}}})
+	defer conf.Mock(nil)
+
+	// Every resolution blocks until all repositories are being resolved,
+	// which only happens if they are resolved concurrently.
+	const n = 4
+	started := make(chan struct{}, n)
+	all := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			<-started
+		}
+		close(all)
+	}()
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		started <- struct{}{}
+		select {
+		case <-all:
+			return "c", nil
+		case <-time.After(5 * time.Second):
+			return "", errors.New("timed out waiting for concurrent resolutions")
+		}
+	}
+	defer git.ResetMocks()
+
+	q, err := query.ParseAndCheck("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &search.TextParameters{PatternInfo: &search.TextPatternInfo{Pattern: "foo"}}
+	for i := 0; i < n; i++ {
+		args.Repos = append(args.Repos, &search.RepositoryRevisions{Repo: &types.Repo{Name: api.RepoName(fmt.Sprintf("r%d", i))}})
+	}
+	if _, ok := (&searchResolver{query: q}).searchResultsCacheKey(context.Background(), args, []string{"file"}); !ok {
+		t.Fatal("expected search to be cacheable")
+	}
+}
+
+func TestSearchResultsCache(t *testing.T) {
+	rcache.SetupForTest(t)
+
+	mockSearchResultsCacheKey = func(*search.TextParameters, []string) (string, bool) {
+		return "key", true
+	}
+	defer func() { mockSearchResultsCacheKey = nil }()
+
+	mockDecodedViewerFinalSettings = &schema.Settings{}
+	defer func() { mockDecodedViewerFinalSettings = nil }()
+
+	repo := &types.Repo{ID: 1, Name: "repo"}
+	db.Mocks.Repos.List = func(context.Context, db.ReposListOptions) ([]*types.Repo, error) {
+		return []*types.Repo{repo}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+	db.Mocks.Repos.MockGetByName(t, "repo", 1)
+	db.Mocks.Repos.MockGet(t, 1)
+
+	calls := 0
+	mockSearchFilesInRepos = func(args *search.TextParameters) ([]*FileMatchResolver, *searchResultsCommon, error) {
+		calls++
+		rev := "master"
+		return []*FileMatchResolver{
+			{
+				uri:          "git://repo?master#dir/file",
+				JPath:        "dir/file",
+				JLineMatches: []*lineMatch{{JLineNumber: 123, JPreview: "foo"}},
+				MatchCount:   1,
+				Repo:         repo,
+				CommitID:     "c1",
+				InputRev:     &rev,
+			},
+		}, &searchResultsCommon{repos: []*types.Repo{repo}, searched: []*types.Repo{repo}}, nil
+	}
+	defer func() { mockSearchFilesInRepos = nil }()
+
+	search := func() *SearchResultsResolver {
+		t.Helper()
+		sr, err := (&schemaResolver{}).Search(context.Background(), &SearchArgs{Query: "foo type:file", Version: "V2"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr, err := sr.Results(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rr
+	}
+
+	first := search()
+	second := search()
+	if calls != 1 {
+		t.Fatalf("have %d searches, want 1", calls)
+	}
+	if !reflect.DeepEqual(first.SearchResults, second.SearchResults) {
+		t.Errorf("cached results differ:\nhave %+v\nwant %+v", second.SearchResults, first.SearchResults)
+	}
+	if !reflect.DeepEqual(first.searchResultsCommon.searched, second.searchResultsCommon.searched) {
+		t.Errorf("have searched %v, want %v", second.searchResultsCommon.searched, first.searchResultsCommon.searched)
+	}
+}
+
+func TestSetCachedSearchResults_Incomplete(t *testing.T) {
+	rcache.SetupForTest(t)
+
+	repo := &types.Repo{ID: 1, Name: "repo"}
+	rr := &SearchResultsResolver{
+		searchResultsCommon: searchResultsCommon{repos: []*types.Repo{repo}, timedout: []*types.Repo{repo}},
+		SearchResults:       []SearchResultResolver{&RepositoryResolver{repo: repo}},
+	}
+	setCachedSearchResults("incomplete", rr)
+
+	args := &search.TextParameters{Repos: []*search.RepositoryRevisions{{Repo: repo}}}
+	if _, ok := getCachedSearchResults("incomplete", args, searchResultsCommon{}); ok {
+		t.Fatal("expected incomplete results not to be cached")
+	}
+}
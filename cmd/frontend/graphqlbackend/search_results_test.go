@@ -34,7 +34,7 @@ func TestSearchResults(t *testing.T) {
 	limitOffset := &db.LimitOffset{Limit: maxReposToSearch() + 1}
 
 	getResults := func(t *testing.T, query, version string) []string {
-		r, err := (&schemaResolver{}).Search(&SearchArgs{Query: query, Version: version})
+		r, err := (&schemaResolver{}).Search(context.Background(), &SearchArgs{Query: query, Version: version})
 		if err != nil {
 			t.Fatal("Search:", err)
 		}
@@ -544,6 +544,12 @@ func TestSearchResolver_getPatternInfo(t *testing.T) {
 			PathPatternsAreRegExps:       true,
 			PathPatternsAreCaseSensitive: true,
 		},
+		"p multiline:yes": {
+			Pattern:                "p",
+			IsRegExp:               true,
+			IsMultiline:            true,
+			PathPatternsAreRegExps: true,
+		},
 		"p file:f": {
 			Pattern:                "p",
 			IsRegExp:               true,
@@ -595,6 +601,14 @@ func TestSearchResolver_getPatternInfo(t *testing.T) {
 			PathPatternsAreRegExps: true,
 			ExcludePattern:         `f|(\.graphql$|\.gql$|\.graphqls$)`,
 		},
+		"p blameauthor:alice -blameauthor:bob -blameauthor:carol blameafter:2020-01-01": {
+			Pattern:                   "p",
+			IsRegExp:                  true,
+			PathPatternsAreRegExps:    true,
+			BlameAuthorPatterns:       []string{"alice"},
+			BlameExcludeAuthorPattern: "bob|carol",
+			BlameAfter:                "2020-01-01",
+		},
 	}
 	for queryStr, want := range tests {
 		t.Run(queryStr, func(t *testing.T) {
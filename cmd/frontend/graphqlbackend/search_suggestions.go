@@ -15,6 +15,7 @@ import (
 	"github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/search"
@@ -41,8 +42,14 @@ var (
 	mockShowFileSuggestions showSearchSuggestionResolvers
 	mockShowLangSuggestions showSearchSuggestionResolvers
 	mockShowSymbolMatches   showSearchSuggestionResolvers
+
+	mockShowSearchHistorySuggestions showSearchSuggestionResolvers
 )
 
+// maxSearchHistorySuggestions is the maximum number of searches from the user's own search history
+// to suggest.
+const maxSearchHistorySuggestions = 5
+
 func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestionsArgs) ([]*searchSuggestionResolver, error) {
 	args.applyDefaultsAndConstraints()
 
@@ -285,6 +292,39 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 	}
 	suggesters = append(suggesters, showFilesWithTextMatches)
 
+	showSearchHistorySuggestions := func(ctx context.Context) ([]*searchSuggestionResolver, error) {
+		if mockShowSearchHistorySuggestions != nil {
+			return mockShowSearchHistorySuggestions()
+		}
+
+		// Suggest searches from the user's own search history that start with the query, most
+		// frequent first.
+		a := actor.FromContext(ctx)
+		if !a.IsAuthenticated() || !searchHistoryRecordingAllowed(ctx) {
+			return nil, nil
+		}
+		rawQuery := strings.TrimSpace(r.rawQuery())
+		searches, err := db.SearchHistory.ListFrequent(ctx, db.SearchHistoryListOptions{
+			UserID:      a.UID,
+			Prefix:      rawQuery,
+			LimitOffset: &db.LimitOffset{Limit: maxSearchHistorySuggestions + 1},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		resolvers := make([]*searchSuggestionResolver, 0, len(searches))
+		for _, s := range searches {
+			if s.Query == rawQuery || len(resolvers) == maxSearchHistorySuggestions {
+				continue
+			}
+			// Rank searches the user performs often above symbol matches.
+			resolvers = append(resolvers, newSearchSuggestionResolver(&frequentSearchResolver{search: s}, 300+int(s.Count)))
+		}
+		return resolvers, nil
+	}
+	suggesters = append(suggesters, showSearchHistorySuggestions)
+
 	// Run suggesters.
 	var (
 		allSuggestions []*searchSuggestionResolver
@@ -331,6 +371,7 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 		file     string
 		symbol   string
 		lang     string
+		query    string
 	}
 	seen := make(map[key]struct{}, len(allSuggestions))
 	uniqueSuggestions := allSuggestions[:0]
@@ -356,6 +397,8 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 			k.symbol = s.symbol.Name + s.symbol.Parent
 		case *languageResolver:
 			k.lang = s.name
+		case *frequentSearchResolver:
+			k.query = s.search.PatternType + ":" + s.search.Query
 		default:
 			panic(fmt.Sprintf("unhandled: %#v", s))
 		}
@@ -11,7 +11,9 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/inventory"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
@@ -22,7 +24,7 @@ func TestSearchSuggestions(t *testing.T) {
 
 	getSuggestions := func(t *testing.T, query, version string) []string {
 		t.Helper()
-		r, err := (&schemaResolver{}).Search(&SearchArgs{Query: query, Version: version})
+		r, err := (&schemaResolver{}).Search(context.Background(), &SearchArgs{Query: query, Version: version})
 		if err != nil {
 			t.Fatal("Search:", err)
 		}
@@ -118,7 +120,7 @@ func TestSearchSuggestions(t *testing.T) {
 
 	// This test is only valid for Regexp searches. Literal searches won't return suggestions for an invalid regexp.
 	t.Run("single term invalid regex", func(t *testing.T) {
-		sr, err := (&schemaResolver{}).Search(&SearchArgs{Query: "[foo", PatternType: nil, Version: "V1"})
+		sr, err := (&schemaResolver{}).Search(context.Background(), &SearchArgs{Query: "[foo", PatternType: nil, Version: "V1"})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -366,3 +368,74 @@ func TestSearchSuggestions(t *testing.T) {
 		}
 	})
 }
+
+func TestSearchSuggestions_searchHistory(t *testing.T) {
+	defer func() { db.Mocks.SearchHistory = db.MockSearchHistory{} }()
+	db.Mocks.SearchHistory.ListFrequent = func(opt db.SearchHistoryListOptions) ([]*db.FrequentSearch, error) {
+		if want := (db.SearchHistoryListOptions{UserID: 1, Prefix: "repo:foo", LimitOffset: &db.LimitOffset{Limit: maxSearchHistorySuggestions + 1}}); !reflect.DeepEqual(opt, want) {
+			t.Error(cmp.Diff(opt, want))
+		}
+		return []*db.FrequentSearch{
+			{Query: "repo:foo bar", PatternType: "literal", Count: 3},
+			{Query: "repo:foo", PatternType: "literal", Count: 2},
+			{Query: "repo:foo baz", PatternType: "regexp", Count: 1},
+		}, nil
+	}
+
+	// Mock to bypass other suggestions.
+	mockShowRepoSuggestions = func() ([]*searchSuggestionResolver, error) { return nil, nil }
+	defer func() { mockShowRepoSuggestions = nil }()
+	mockShowFileSuggestions = func() ([]*searchSuggestionResolver, error) { return nil, nil }
+	defer func() { mockShowFileSuggestions = nil }()
+	mockShowLangSuggestions = func() ([]*searchSuggestionResolver, error) { return nil, nil }
+	defer func() { mockShowLangSuggestions = nil }()
+	mockShowSymbolMatches = func() ([]*searchSuggestionResolver, error) { return nil, nil }
+	defer func() { mockShowSymbolMatches = nil }()
+
+	defer conf.Mock(nil)
+	defer func() { mockDecodedViewerFinalSettings = nil }()
+
+	disabled := false
+	tests := map[string]struct {
+		site     *schema.SearchHistory
+		settings *schema.Settings
+		want     []string
+	}{
+		// The search that is the same as the query is not suggested.
+		"enabled": {
+			settings: &schema.Settings{},
+			want:     []string{"search:repo:foo bar", "search:repo:foo baz"},
+		},
+		"disabled in site configuration": {
+			site:     &schema.SearchHistory{Enabled: &disabled},
+			settings: &schema.Settings{},
+			want:     []string{},
+		},
+		"user opted out": {
+			settings: &schema.Settings{SearchRecordHistory: &disabled},
+			want:     []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{SearchHistory: test.site}})
+			mockDecodedViewerFinalSettings = test.settings
+
+			r, err := (&schemaResolver{}).Search(context.Background(), &SearchArgs{Query: "repo:foo", Version: "V2"})
+			if err != nil {
+				t.Fatal("Search:", err)
+			}
+			results, err := r.Suggestions(actor.WithActor(context.Background(), &actor.Actor{UID: 1}), &searchSuggestionsArgs{})
+			if err != nil {
+				t.Fatal("Suggestions:", err)
+			}
+			got := make([]string, len(results))
+			for i, result := range results {
+				got[i] = testStringResult(result)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got != want\ngot:  %v\nwant: %v", got, test.want)
+			}
+		})
+	}
+}
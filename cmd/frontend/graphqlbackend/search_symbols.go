@@ -129,6 +129,8 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 		)
 	}
 
+	owners := newOwnerFilter(args.Query)
+
 	var (
 		run = parallel.NewRun(conf.SearchSymbolsParallelism())
 		mu  sync.Mutex
@@ -161,6 +163,9 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 	goroutine.Go(func() {
 		defer run.Release()
 		matches, limitHit, reposLimitHit, searchErr := zoektSearchHEAD(ctx, args, zoektRepos, true, time.Since)
+		if searchErr == nil {
+			matches, searchErr = owners.filter(ctx, matches)
+		}
 		mu.Lock()
 		defer mu.Unlock()
 		if ctx.Err() == nil {
@@ -195,6 +200,9 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 		goroutine.Go(func() {
 			defer run.Release()
 			repoSymbols, repoErr := searchSymbolsInRepo(ctx, repoRevs, args.PatternInfo, args.Query, limit)
+			if repoErr == nil {
+				repoSymbols, repoErr = owners.filter(ctx, repoSymbols)
+			}
 			if repoErr != nil {
 				tr.LogFields(otlog.String("repo", string(repoRevs.Repo.Name)), otlog.String("repoErr", repoErr.Error()), otlog.Bool("timeout", errcode.IsTimeout(repoErr)), otlog.Bool("temporary", errcode.IsTemporary(repoErr)))
 			}
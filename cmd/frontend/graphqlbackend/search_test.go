@@ -244,6 +244,8 @@ func testStringResult(result *searchSuggestionResolver) string {
 		name = "file:" + r.Path()
 	case *languageResolver:
 		name = "lang:" + r.name
+	case *frequentSearchResolver:
+		name = "search:" + r.search.Query
 	default:
 		panic("never here")
 	}
@@ -0,0 +1,79 @@
+package graphqlbackend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+)
+
+// GetSeatUsageReport is called to obtain the report of the use of the licensed seats, with daily
+// snapshots for the given number of days.
+//
+// It is overridden in non-OSS builds, which track seat usage.
+var GetSeatUsageReport = func(ctx context.Context, days int) (*SeatUsageReport, error) {
+	return &SeatUsageReport{}, nil
+}
+
+// SeatUsageReport implements the GraphQL type SeatUsageReport.
+type SeatUsageReport struct {
+	UserCountValue         int
+	LicensedSeatsValue     int // 0 means unlimited
+	OverageSinceValue      time.Time
+	GracePeriodEndsAtValue time.Time
+	SnapshotsValue         []*SeatUsageSnapshot
+}
+
+func (r *SeatUsageReport) UserCount() int32 { return int32(r.UserCountValue) }
+
+func (r *SeatUsageReport) LicensedSeats() *int32 { return seatsOrNil(r.LicensedSeatsValue) }
+
+func (r *SeatUsageReport) OverageSince() *DateTime {
+	return DateTimeOrNil(timeOrNil(r.OverageSinceValue))
+}
+
+func (r *SeatUsageReport) GracePeriodEndsAt() *DateTime {
+	return DateTimeOrNil(timeOrNil(r.GracePeriodEndsAtValue))
+}
+
+func (r *SeatUsageReport) Snapshots() []*SeatUsageSnapshot { return r.SnapshotsValue }
+
+// SeatUsageSnapshot implements the GraphQL type SeatUsageSnapshot.
+type SeatUsageSnapshot struct {
+	DateValue          string
+	UserCountValue     int
+	LicensedSeatsValue int // 0 means unlimited
+}
+
+func (r *SeatUsageSnapshot) Date() string { return r.DateValue }
+
+func (r *SeatUsageSnapshot) UserCount() int32 { return int32(r.UserCountValue) }
+
+func (r *SeatUsageSnapshot) LicensedSeats() *int32 { return seatsOrNil(r.LicensedSeatsValue) }
+
+func seatsOrNil(seats int) *int32 {
+	if seats <= 0 {
+		return nil
+	}
+	v := int32(seats)
+	return &v
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (productSubscriptionStatus) SeatUsage(ctx context.Context, args *struct{ Days int32 }) (*SeatUsageReport, error) {
+	// 🚨 SECURITY: Only site admins may view the seat usage report.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	days := int(args.Days)
+	if days < 1 {
+		days = 1
+	}
+	return GetSeatUsageReport(ctx, days)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 )
@@ -69,6 +70,10 @@ func (r *settingsCascade) Subjects(ctx context.Context) ([]*settingsSubject, err
 
 // viewerFinalSettings returns the final (merged) settings for the viewer.
 func viewerFinalSettings(ctx context.Context) (*configurationResolver, error) {
+	// The settings are read on behalf of the server (e.g., to apply search defaults), not returned
+	// to the client, so the access token used for the request need not grant "settings:read".
+	ctx = authz.WithTokenScopes(ctx, nil)
+
 	cascade, err := (&schemaResolver{}).ViewerSettings(ctx)
 	if err != nil {
 		return nil, err
@@ -10,6 +10,7 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/siteid"
@@ -91,6 +92,10 @@ func (r *siteResolver) settingsSubject() api.SettingsSubject {
 }
 
 func (r *siteResolver) LatestSettings(ctx context.Context) (*settingsResolver, error) {
+	if err := authz.CheckScope(ctx, authz.ScopeSettingsRead); err != nil {
+		return nil, err
+	}
+
 	settings, err := db.Settings.GetLatest(ctx, r.settingsSubject())
 	if err != nil {
 		return nil, err
@@ -174,11 +179,26 @@ func (r *schemaResolver) UpdateSiteConfiguration(ctx context.Context, args *stru
 		return false, fmt.Errorf("blank site configuration is invalid (you can clear the site configuration by entering an empty JSON object: {})")
 	}
 	prev := globals.ConfigurationServerFrontendOnly.Raw()
+	before := prev.Site
 	prev.Site = args.Input
 	// TODO(slimsag): future: actually pass lastID through to prevent race conditions
+	//
+	// Write the configuration before (not in the same transaction as) its audit log entry, because
+	// Write applies it to this frontend right away, even if the transaction is rolled back later.
 	if err := globals.ConfigurationServerFrontendOnly.Write(ctx, prev); err != nil {
 		return false, err
 	}
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionSiteConfigUpdate,
+			TargetType: "Site",
+			TargetID:   string(marshalSiteGQLID(singletonSiteGQLID)),
+			Before:     backend.RedactSecrets(&before),
+			After:      backend.RedactSecrets(&args.Input),
+		}, nil
+	}); err != nil {
+		return false, fmt.Errorf("the site configuration was saved, but recording it in the audit log failed: %w", err)
+	}
 	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
 }
 
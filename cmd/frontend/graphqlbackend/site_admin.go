@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/pkg/errors"
@@ -137,7 +138,18 @@ func (*schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 		return nil, err
 	}
 
-	if err := db.Users.SetIsSiteAdmin(ctx, userID, args.SiteAdmin); err != nil {
+	after := strconv.FormatBool(args.SiteAdmin)
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := db.Users.SetIsSiteAdmin(ctx, userID, args.SiteAdmin); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionUserSiteAdminSet,
+			TargetType: "User",
+			TargetID:   string(args.UserID),
+			After:      &after,
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 	return &EmptyResponse{}, nil
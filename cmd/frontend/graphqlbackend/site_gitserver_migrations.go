@@ -0,0 +1,78 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func (r *siteResolver) GitserverRepoMigrations(ctx context.Context) ([]*gitserverRepoMigrationResolver, error) {
+	// 🚨 SECURITY: Only site admins can view gitserver internals.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		resolvers []*gitserverRepoMigrationResolver
+	)
+	for _, addr := range gitserver.DefaultClient.Addrs(ctx) {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			statuses, err := gitserver.DefaultClient.RepoMigrations(ctx, addr)
+			if err != nil {
+				// Don't fail the whole request if a single gitserver is
+				// unavailable or doesn't support migrations yet.
+				log15.Warn("failed to get gitserver repository migrations", "gitserver", addr, "error", err)
+				return
+			}
+			mu.Lock()
+			for _, st := range statuses {
+				resolvers = append(resolvers, &gitserverRepoMigrationResolver{status: st, to: addr})
+			}
+			mu.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+
+	sort.Slice(resolvers, func(i, j int) bool {
+		return resolvers[i].status.Repo < resolvers[j].status.Repo
+	})
+	return resolvers, nil
+}
+
+type gitserverRepoMigrationResolver struct {
+	status protocol.RepoMigrationStatus
+	to     string
+}
+
+func (r *gitserverRepoMigrationResolver) RepositoryName() string { return string(r.status.Repo) }
+
+func (r *gitserverRepoMigrationResolver) From() string { return r.status.From }
+
+func (r *gitserverRepoMigrationResolver) To() string { return r.to }
+
+func (r *gitserverRepoMigrationResolver) State() string {
+	return strings.ToUpper(string(r.status.State))
+}
+
+func (r *gitserverRepoMigrationResolver) Error() *string {
+	if r.status.Error == "" {
+		return nil
+	}
+	return &r.status.Error
+}
+
+func (r *gitserverRepoMigrationResolver) BytesTransferred() float64 { return float64(r.status.Bytes) }
+
+func (r *gitserverRepoMigrationResolver) UpdatedAt() DateTime {
+	return DateTime{Time: r.status.UpdatedAt}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"regexp"
+	"regexp/syntax"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,6 +17,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/go-langserver/pkg/lsp"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
@@ -66,12 +69,14 @@ var (
 type FileMatchResolver struct {
 	JPath        string       `json:"Path"`
 	JLineMatches []*lineMatch `json:"LineMatches"`
-	JLimitHit    bool         `json:"LimitHit"`
-	MatchCount   int          // Number of matches. Different from len(JLineMatches), as multiple lines may correspond to one logical match.
-	symbols      []*searchSymbolResult
-	uri          string
-	Repo         *types.Repo
-	CommitID     api.CommitID
+	// JMultilineMatches are set instead of JLineMatches for multiline searches.
+	JMultilineMatches []*multilineMatch `json:"MultilineMatches"`
+	JLimitHit         bool              `json:"LimitHit"`
+	MatchCount        int               // Number of matches. Different from len(JLineMatches), as multiple lines may correspond to one logical match.
+	symbols           []*searchSymbolResult
+	uri               string
+	Repo              *types.Repo
+	CommitID          api.CommitID
 	// InputRev is the Git revspec that the user originally requested to search. It is used to
 	// preserve the original revision specifier from the user instead of navigating them to the
 	// absolute commit ID when they select a result.
@@ -129,6 +134,13 @@ func (fm *FileMatchResolver) LineMatches() []*lineMatch {
 	return fm.JLineMatches
 }
 
+func (fm *FileMatchResolver) MultilineMatches() []*multilineMatch {
+	if fm.JMultilineMatches == nil {
+		return []*multilineMatch{}
+	}
+	return fm.JMultilineMatches
+}
+
 func (fm *FileMatchResolver) LimitHit() bool {
 	return fm.JLimitHit
 }
@@ -183,6 +195,30 @@ func (lm *lineMatch) LimitHit() bool {
 	return lm.JLimitHit
 }
 
+// multilineMatch is a match which may span multiple lines, as returned by
+// searcher for multiline searches.
+type multilineMatch struct {
+	JPreview string            `json:"Preview"`
+	JStart   multilinePosition `json:"Start"`
+	JEnd     multilinePosition `json:"End"`
+}
+
+type multilinePosition struct {
+	Line      int32
+	Character int32
+}
+
+func (mm *multilineMatch) Preview() string {
+	return mm.JPreview
+}
+
+func (mm *multilineMatch) Range() RangeResolver {
+	return NewRangeResolver(lsp.Range{
+		Start: lsp.Position{Line: int(mm.JStart.Line), Character: int(mm.JStart.Character)},
+		End:   lsp.Position{Line: int(mm.JEnd.Line), Character: int(mm.JEnd.Character)},
+	})
+}
+
 var mockTextSearch func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error)
 
 // textSearch searches repo@commit with p.
@@ -209,6 +245,12 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 		"Languages":       p.Languages,
 		"CombyRule":       []string{p.CombyRule},
 	}
+	if p.HasBlameFilters() {
+		q["BlameAuthorPatterns"] = p.BlameAuthorPatterns
+		q.Set("BlameExcludeAuthorPattern", p.BlameExcludeAuthorPattern)
+		q.Set("BlameAfter", p.BlameAfter)
+		q.Set("BlameBefore", p.BlameBefore)
+	}
 	if deadline, ok := ctx.Deadline(); ok {
 		t, err := deadline.MarshalText()
 		if err != nil {
@@ -229,6 +271,9 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 	if p.IsCaseSensitive {
 		q.Set("IsCaseSensitive", "true")
 	}
+	if p.IsMultiline {
+		q.Set("IsMultiline", "true")
+	}
 	if p.PathPatternsAreRegExps {
 		q.Set("PathPatternsAreRegExps", "true")
 	}
@@ -540,6 +585,21 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 		}
 	}
 
+	// Indexed search has no blame information, so when matches are filtered
+	// by blame, searcher filters the files of indexed repositories that zoekt
+	// found to match the pattern.
+	searchZoektFilesWithSearcher := args.PatternInfo.IsStructuralPat || args.PatternInfo.HasBlameFilters()
+
+	// Indexed search only matches within a line, so searcher searches all
+	// repositories when a multiline search can match across lines.
+	if args.PatternInfo.IsMultiline && patternMatchesNewline(args.PatternInfo) && len(zoektRepos) > 0 {
+		tr.LazyPrintf("multiline pattern, bypassing zoekt (using searcher) for %d indexed repos", len(zoektRepos))
+		searcherRepos = append(searcherRepos, zoektRepos...)
+		zoektRepos = nil
+	}
+
+	owners := newOwnerFilter(args.Query)
+
 	var (
 		// TODO: convert wg to an errgroup
 		wg                sync.WaitGroup
@@ -576,7 +636,8 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 	// callSearcherOverRepos calls searcher on a set of repos.
 	// searcherReposFilteredFiles is an optional map of {repo name => file list}
 	// that forces the searcher to only include the file list in the
-	// search. It is currently only set when Zoekt restricts the file list for structural search.
+	// search. It is currently only set when Zoekt restricts the file list for
+	// structural search or for a search with blame filters.
 	callSearcherOverRepos := func(
 		searcherRepos []*search.RepositoryRevisions,
 		searcherReposFilteredFiles map[string][]string,
@@ -635,13 +696,19 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 				repoRev := &search.RepositoryRevisions{Repo: repoAllRevs.Repo, Revs: []search.RevisionSpecifier{{RevSpec: rev}}}
 
 				args := *args
-				if args.PatternInfo.IsStructuralPat && searcherReposFilteredFiles != nil {
+				if searcherReposFilteredFiles != nil {
 					// Modify the search query to only run for the filtered files
 					if v, ok := searcherReposFilteredFiles[string(repoRev.Repo.Name)]; ok {
 						patternCopy := *args.PatternInfo
 						args.PatternInfo = &patternCopy
 						includePatternsCopy := []string{}
-						args.PatternInfo.IncludePatterns = append(includePatternsCopy, v...)
+						if args.PatternInfo.IsStructuralPat {
+							args.PatternInfo.IncludePatterns = append(includePatternsCopy, v...)
+						} else {
+							// Include patterns must all match, so the files are
+							// added as a single pattern to the query's own.
+							args.PatternInfo.IncludePatterns = append(append(includePatternsCopy, args.PatternInfo.IncludePatterns...), filesPattern(v))
+						}
 					}
 				}
 
@@ -651,6 +718,9 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 					defer done()
 
 					matches, repoLimitHit, err := searchFilesInRepo(ctx, args.SearcherURLs, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], args.PatternInfo, fetchTimeout)
+					if err == nil {
+						matches, err = owners.filter(ctx, matches)
+					}
 					if err != nil {
 						tr.LogFields(otlog.String("repo", string(repoRev.Repo.Name)), otlog.Error(err), otlog.Bool("timeout", errcode.IsTimeout(err)), otlog.Bool("temporary", errcode.IsTemporary(err)))
 						log15.Warn("searchFilesInRepo failed", "error", err, "repo", repoRev.Repo.Name)
@@ -700,6 +770,11 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 		} else {
 			matches, limitHit, reposLimitHit, err = zoektSearchHEADOnlyFiles(ctx, args, zoektRepos, false, time.Since)
 		}
+		if err == nil && !searchZoektFilesWithSearcher {
+			// Structural search and blame-filtered matches are filtered by
+			// owner when searcher searches the files found by zoekt.
+			matches, err = owners.filter(ctx, matches)
+		}
 		mu.Lock()
 		defer mu.Unlock()
 		if ctx.Err() == nil {
@@ -731,7 +806,7 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 			cancel()
 		}
 
-		if args.PatternInfo.IsStructuralPat {
+		if searchZoektFilesWithSearcher {
 			// A partition of {repo name => file list} that we will build from Zoekt matches
 			partition := make(map[string][]string)
 			var repos []*search.RepositoryRevisions
@@ -750,14 +825,15 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 				}
 			}
 
-			// For structural search, we run callSearcherOverRepos
-			// over the set of repos and files known to contain
-			// parts of the pattern as determined by Zoekt.
+			// For structural search and blame filters, we run
+			// callSearcherOverRepos over the set of repos and files
+			// known to contain parts of the pattern as determined by
+			// Zoekt.
 			// callSearcherOverRepos must acquire the lock, so we
 			// must release the lock held by Zoekt at this point.
 			// The Zoekt part of the search is done here as far as
-			// structural search is concerned, so the lock can be
-			// freely released.
+			// structural search and blame filters are concerned, so
+			// the lock can be freely released.
 			mu.Unlock()
 			err := callSearcherOverRepos(repos, partition)
 			mu.Lock()
@@ -787,6 +863,15 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 	return flattened, common, nil
 }
 
+// filesPattern returns a path pattern that matches exactly the given files.
+func filesPattern(files []string) string {
+	quoted := make([]string, len(files))
+	for i, f := range files {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	return "^(?:" + strings.Join(quoted, "|") + ")$"
+}
+
 func flattenFileMatches(unflattened [][]*FileMatchResolver, fileMatchLimit int) []*FileMatchResolver {
 	// Return early so we don't have to worry about empty lists in later
 	// calculations.
@@ -832,3 +917,41 @@ func flattenFileMatches(unflattened [][]*FileMatchResolver, fileMatchLimit int)
 
 	return flattened
 }
+
+// patternMatchesNewline reports whether matches of the pattern of p may
+// contain a newline, and so span multiple lines.
+func patternMatchesNewline(p *search.TextPatternInfo) bool {
+	if !p.IsRegExp {
+		return strings.Contains(p.Pattern, "\n")
+	}
+	re, err := syntax.Parse(p.Pattern, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return regexpMatchesNewline(re)
+}
+
+func regexpMatchesNewline(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar:
+		return true
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '\n' {
+				return true
+			}
+		}
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '\n' && '\n' <= re.Rune[i+1] {
+				return true
+			}
+		}
+	}
+	for _, sub := range re.Sub {
+		if regexpMatchesNewline(sub) {
+			return true
+		}
+	}
+	return false
+}
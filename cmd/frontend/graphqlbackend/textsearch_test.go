@@ -434,6 +434,22 @@ func TestSearchFilesInRepos_multipleRevsPerRepo(t *testing.T) {
 	}
 }
 
+func TestFilesPattern(t *testing.T) {
+	re := regexp.MustCompile(filesPattern([]string{"a.go", "dir/b+.go"}))
+	for path, want := range map[string]bool{
+		"a.go":      true,
+		"dir/b+.go": true,
+		"a.go.orig": false,
+		"x/a.go":    false,
+		"dir/bb.go": false,
+		"aago":      false,
+	} {
+		if got := re.MatchString(path); got != want {
+			t.Errorf("%q: got %v, want %v", path, got, want)
+		}
+	}
+}
+
 func TestRepoShouldBeSearched(t *testing.T) {
 	mockTextSearch = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
 		repoName := repo.Name
@@ -954,3 +970,26 @@ func Benchmark_zoektIndexedRepos(b *testing.B) {
 		_, _, _ = zoektIndexedRepos(ctx, z, repos, nil)
 	}
 }
+
+func TestPatternMatchesNewline(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		isRegExp bool
+		want     bool
+	}{
+		{pattern: `foo`, isRegExp: true, want: false},
+		{pattern: `foo.*bar`, isRegExp: true, want: false},
+		{pattern: `foo\nbar`, isRegExp: true, want: true},
+		{pattern: `foo\s+bar`, isRegExp: true, want: true},
+		{pattern: `foo[^;]*bar`, isRegExp: true, want: true},
+		{pattern: `(?s)foo.*bar`, isRegExp: true, want: true},
+		{pattern: `foo\nbar`, want: false},
+		{pattern: "foo\nbar", want: true},
+	}
+	for _, test := range tests {
+		p := &search.TextPatternInfo{Pattern: test.pattern, IsRegExp: test.isRegExp}
+		if got := patternMatchesNewline(p); got != test.want {
+			t.Errorf("patternMatchesNewline(%q, regexp %v) = %v, want %v", test.pattern, test.isRegExp, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/auth/twofactor"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func (r *UserResolver) TwoFactor(ctx context.Context) (*userTwoFactorResolver, error) {
+	// 🚨 SECURITY: Only the self user and site admins can view a user's two-factor authentication
+	// settings.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return nil, err
+	}
+	status, err := twofactor.GetStatus(ctx, r.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &userTwoFactorResolver{status: status, required: twofactor.RequiredForUser(r.user)}, nil
+}
+
+type userTwoFactorResolver struct {
+	status   *twofactor.Status
+	required bool
+}
+
+func (r *userTwoFactorResolver) Enabled() bool     { return r.status.Enabled() }
+func (r *userTwoFactorResolver) Required() bool    { return r.required }
+func (r *userTwoFactorResolver) TOTPEnabled() bool { return r.status.TOTPEnabled }
+func (r *userTwoFactorResolver) RecoveryCodesRemaining() int32 {
+	return int32(r.status.RecoveryCodesRemaining)
+}
+
+func (r *userTwoFactorResolver) WebAuthnCredentials() []*webAuthnCredentialResolver {
+	rs := make([]*webAuthnCredentialResolver, len(r.status.WebAuthnCredentials))
+	for i, c := range r.status.WebAuthnCredentials {
+		rs[i] = &webAuthnCredentialResolver{credential: c}
+	}
+	return rs
+}
+
+type webAuthnCredentialResolver struct {
+	credential *db.UserWebAuthnCredential
+}
+
+func marshalWebAuthnCredentialID(id int32) graphql.ID {
+	return relay.MarshalID("WebAuthnCredential", id)
+}
+
+func unmarshalWebAuthnCredentialID(id graphql.ID) (credentialID int32, err error) {
+	err = relay.UnmarshalSpec(id, &credentialID)
+	return
+}
+
+func (r *webAuthnCredentialResolver) ID() graphql.ID {
+	return marshalWebAuthnCredentialID(r.credential.ID)
+}
+func (r *webAuthnCredentialResolver) Name() string { return r.credential.Name }
+func (r *webAuthnCredentialResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.credential.CreatedAt}
+}
+func (r *webAuthnCredentialResolver) LastUsedAt() *DateTime {
+	return DateTimeOrNil(r.credential.LastUsedAt)
+}
+
+// checkSameUser unmarshals the user ID and checks that it is the current user. Site admins may not
+// enroll second factors for other users, because the user must possess the second factor.
+func checkSameUser(ctx context.Context, user graphql.ID) (userID int32, err error) {
+	userID, err = UnmarshalUserID(user)
+	if err != nil {
+		return 0, err
+	}
+	if a := actor.FromContext(ctx); !a.IsAuthenticated() || a.UID != userID {
+		return 0, errors.New("only the user may manage their own second factors")
+	}
+	return userID, nil
+}
+
+// checkCanRemoveTwoFactor checks that the current user may remove one of the user's second factors.
+// Site admins may remove other users' second factors (for account recovery), but users may not
+// remove their own last second factor if two-factor authentication is required for them.
+func checkCanRemoveTwoFactor(ctx context.Context, userID int32) error {
+	// 🚨 SECURITY: Only the self user and site admins can remove a user's second factors.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, userID); err != nil {
+		return err
+	}
+	if actor.FromContext(ctx).UID != userID {
+		return nil
+	}
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !twofactor.RequiredForUser(user) {
+		return nil
+	}
+	status, err := twofactor.GetStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	n := len(status.WebAuthnCredentials)
+	if status.TOTPEnabled {
+		n++
+	}
+	if n <= 1 {
+		return errors.New("two-factor authentication is required for your account, so you may not remove your last second factor (add another one first)")
+	}
+	return nil
+}
+
+type twoFactorReauthenticationInput struct {
+	Password     *string
+	TOTPCode     *string
+	RecoveryCode *string
+}
+
+// reauthenticate checks that the current user proved their presence with the reauthentication
+// input, so that a stolen session can't remove second factors or regenerate recovery codes.
+func reauthenticate(ctx context.Context, input *twoFactorReauthenticationInput) error {
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return backend.ErrNotAuthenticated
+	}
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return twofactor.Reauthenticate(ctx, a.UID, twofactor.Reauthentication{
+		Password:     deref(input.Password),
+		TOTPCode:     deref(input.TOTPCode),
+		RecoveryCode: deref(input.RecoveryCode),
+	})
+}
+
+func (r *schemaResolver) BeginTOTPEnrollment(ctx context.Context, args *struct {
+	User graphql.ID
+}) (*totpEnrollmentResolver, error) {
+	userID, err := checkSameUser(ctx, args.User)
+	if err != nil {
+		return nil, err
+	}
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := twofactor.BeginTOTPEnrollment(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &totpEnrollmentResolver{enrollment: enrollment}, nil
+}
+
+type totpEnrollmentResolver struct {
+	enrollment *twofactor.TOTPEnrollment
+}
+
+func (r *totpEnrollmentResolver) Secret() string { return r.enrollment.Secret }
+func (r *totpEnrollmentResolver) URI() string    { return r.enrollment.URI }
+
+func (r *schemaResolver) ConfirmTOTPEnrollment(ctx context.Context, args *struct {
+	User graphql.ID
+	Code string
+}) ([]string, error) {
+	userID, err := checkSameUser(ctx, args.User)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := twofactor.ConfirmTOTPEnrollment(ctx, userID, args.Code)
+	if err != nil {
+		return nil, err
+	}
+	return nonNilStrings(codes), nil
+}
+
+func (r *schemaResolver) RemoveTOTP(ctx context.Context, args *struct {
+	User             graphql.ID
+	Reauthentication *twoFactorReauthenticationInput
+}) (*EmptyResponse, error) {
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanRemoveTwoFactor(ctx, userID); err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: The current user must prove their presence.
+	if err := reauthenticate(ctx, args.Reauthentication); err != nil {
+		return nil, err
+	}
+	removed := "totp"
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := twofactor.RemoveTOTP(ctx, userID); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionUserTwoFactorRemove,
+			TargetType: "User",
+			TargetID:   string(args.User),
+			Before:     &removed,
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (r *schemaResolver) BeginWebAuthnRegistration(ctx context.Context, args *struct {
+	User graphql.ID
+}) (string, error) {
+	userID, err := checkSameUser(ctx, args.User)
+	if err != nil {
+		return "", err
+	}
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	options, err := twofactor.BeginWebAuthnRegistration(ctx, user)
+	if err != nil {
+		return "", err
+	}
+	return string(options), nil
+}
+
+func (r *schemaResolver) FinishWebAuthnRegistration(ctx context.Context, args *struct {
+	User       graphql.ID
+	Name       string
+	Credential string
+}) ([]string, error) {
+	userID, err := checkSameUser(ctx, args.User)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := twofactor.FinishWebAuthnRegistration(ctx, userID, args.Name, json.RawMessage(args.Credential))
+	if err != nil {
+		return nil, err
+	}
+	return nonNilStrings(codes), nil
+}
+
+func (r *schemaResolver) RemoveWebAuthnCredential(ctx context.Context, args *struct {
+	User             graphql.ID
+	Credential       graphql.ID
+	Reauthentication *twoFactorReauthenticationInput
+}) (*EmptyResponse, error) {
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	credentialID, err := unmarshalWebAuthnCredentialID(args.Credential)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanRemoveTwoFactor(ctx, userID); err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: The current user must prove their presence.
+	if err := reauthenticate(ctx, args.Reauthentication); err != nil {
+		return nil, err
+	}
+	creds, err := db.UserTwoFactor.ListWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var removed string
+	for _, c := range creds {
+		if c.ID == credentialID {
+			removed = c.Name
+		}
+	}
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := twofactor.RemoveWebAuthnCredential(ctx, userID, credentialID); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionUserTwoFactorRemove,
+			TargetType: "User",
+			TargetID:   string(args.User),
+			Before:     &removed,
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (r *schemaResolver) RegenerateTwoFactorRecoveryCodes(ctx context.Context, args *struct {
+	User             graphql.ID
+	Reauthentication *twoFactorReauthenticationInput
+}) ([]string, error) {
+	userID, err := checkSameUser(ctx, args.User)
+	if err != nil {
+		return nil, err
+	}
+	status, err := twofactor.GetStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled() {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+	// 🚨 SECURITY: The user must prove their presence.
+	if err := reauthenticate(ctx, args.Reauthentication); err != nil {
+		return nil, err
+	}
+	return twofactor.RegenerateRecoveryCodes(ctx, userID)
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// 🚨 SECURITY: This tests that users can't remove second factors they aren't allowed to remove.
+func TestMutation_RemoveTOTP(t *testing.T) {
+	const uid1GQLID = "VXNlcjox"
+	defer conf.Mock(nil)
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		AuthTwoFactor: &schema.AuthTwoFactor{RequireForSiteAdmins: true},
+	}})
+
+	mockTwoFactor := func(t *testing.T, user *types.User, removed *bool) {
+		now := time.Now()
+		db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) { return user, nil }
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			a := actor.FromContext(ctx)
+			return &types.User{ID: a.UID, SiteAdmin: a.UID == 2}, nil
+		}
+		db.Mocks.UserTwoFactor = db.MockUserTwoFactor{
+			GetTOTP: func(context.Context, int32) (*db.UserTOTP, error) {
+				if *removed {
+					return nil, nil
+				}
+				return &db.UserTOTP{EnabledAt: &now}, nil
+			},
+			ListWebAuthnCredentials: func(context.Context, int32) ([]*db.UserWebAuthnCredential, error) { return nil, nil },
+			CountRecoveryCodes:      func(context.Context, int32) (int, error) { return 0, nil },
+			DeleteTOTP: func(ctx context.Context, userID int32) error {
+				if userID != 1 {
+					t.Errorf("got user ID %d, want 1", userID)
+				}
+				*removed = true
+				return nil
+			},
+			SetRecoveryCodes: func(context.Context, int32, []string) error { return nil },
+		}
+		db.Mocks.Users.IsPassword = func(_ context.Context, id int32, password string) (bool, error) {
+			return password == "p"+strconv.Itoa(int(id)), nil
+		}
+		db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+			if e.Action != backend.AuditActionUserTwoFactorRemove || e.TargetID != uid1GQLID {
+				t.Errorf("unexpected audit log entry %+v", e)
+			}
+			return nil
+		}
+	}
+
+	// removeTOTPArgs returns the arguments to remove user 1's TOTP, reauthenticated with the
+	// password (which is "p" followed by the current user's ID in these tests).
+	removeTOTPArgs := func(password string) *struct {
+		User             graphql.ID
+		Reauthentication *twoFactorReauthenticationInput
+	} {
+		return &struct {
+			User             graphql.ID
+			Reauthentication *twoFactorReauthenticationInput
+		}{User: uid1GQLID, Reauthentication: &twoFactorReauthenticationInput{Password: &password}}
+	}
+
+	t.Run("user removing their last factor when not required", func(t *testing.T) {
+		resetMocks()
+		var removed bool
+		mockTwoFactor(t, &types.User{ID: 1}, &removed)
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&schemaResolver{}).RemoveTOTP(ctx, removeTOTPArgs("p1")); err != nil {
+			t.Fatal(err)
+		}
+		if !removed {
+			t.Error("TOTP was not removed")
+		}
+	})
+
+	t.Run("site admin removing their last factor when required", func(t *testing.T) {
+		resetMocks()
+		var removed bool
+		mockTwoFactor(t, &types.User{ID: 1, SiteAdmin: true, BuiltinAuth: true}, &removed)
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&schemaResolver{}).RemoveTOTP(ctx, removeTOTPArgs("p1")); err == nil {
+			t.Error("err == nil")
+		}
+		if removed {
+			t.Error("TOTP was removed")
+		}
+	})
+
+	t.Run("site admin removing another user's factor", func(t *testing.T) {
+		resetMocks()
+		var removed bool
+		mockTwoFactor(t, &types.User{ID: 1, SiteAdmin: true, BuiltinAuth: true}, &removed)
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 2})
+		if _, err := (&schemaResolver{}).RemoveTOTP(ctx, removeTOTPArgs("p2")); err != nil {
+			t.Fatal(err)
+		}
+		if !removed {
+			t.Error("TOTP was not removed")
+		}
+	})
+
+	t.Run("user removing their factor with the wrong password", func(t *testing.T) {
+		resetMocks()
+		var removed bool
+		mockTwoFactor(t, &types.User{ID: 1}, &removed)
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		for _, args := range []*struct {
+			User             graphql.ID
+			Reauthentication *twoFactorReauthenticationInput
+		}{removeTOTPArgs("wrong"), removeTOTPArgs("")} {
+			if _, err := (&schemaResolver{}).RemoveTOTP(ctx, args); err == nil {
+				t.Error("err == nil")
+			}
+		}
+		if removed {
+			t.Error("TOTP was removed")
+		}
+	})
+
+	t.Run("site admin removing another user's factor with the user's password", func(t *testing.T) {
+		resetMocks()
+		var removed bool
+		mockTwoFactor(t, &types.User{ID: 1}, &removed)
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 2})
+		if _, err := (&schemaResolver{}).RemoveTOTP(ctx, removeTOTPArgs("p1")); err == nil {
+			t.Error("err == nil")
+		}
+		if removed {
+			t.Error("TOTP was removed")
+		}
+	})
+
+	t.Run("non-site admin removing another user's factor", func(t *testing.T) {
+		resetMocks()
+		var removed bool
+		mockTwoFactor(t, &types.User{ID: 1}, &removed)
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 3})
+		if _, err := (&schemaResolver{}).RemoveTOTP(ctx, removeTOTPArgs("p3")); err == nil {
+			t.Error("err == nil")
+		}
+		if removed {
+			t.Error("TOTP was removed")
+		}
+	})
+}
+
+func TestMutation_BeginTOTPEnrollment_otherUser(t *testing.T) {
+	resetMocks()
+	// 🚨 SECURITY: Not even site admins may enroll second factors for other users.
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 2, SiteAdmin: true}, nil
+	}
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 2})
+	if _, err := (&schemaResolver{}).BeginTOTPEnrollment(ctx, &struct{ User graphql.ID }{User: "VXNlcjox"}); err == nil {
+		t.Error("err == nil")
+	}
+}
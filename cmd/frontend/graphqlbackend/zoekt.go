@@ -203,6 +203,7 @@ func zoektSearchHEAD(ctx context.Context, args *search.TextParameters, repos []*
 		inputRev := repoRev.RevSpecs()[0]
 		baseURI := &gituri.URI{URL: url.URL{Scheme: "git://", Host: string(repoRev.Repo.Name), RawQuery: "?" + url.QueryEscape(inputRev)}}
 		lines := make([]*lineMatch, 0, len(file.LineMatches))
+		var multilineMatches []*multilineMatch
 		symbols := []*searchSymbolResult{}
 		for _, l := range file.LineMatches {
 			if !l.FileName {
@@ -236,7 +237,18 @@ func zoektSearchHEAD(ctx context.Context, args *search.TextParameters, repos []*
 						})
 					}
 				}
-				if !isSymbol {
+				if !isSymbol && args.PatternInfo.IsMultiline {
+					// Multiline patterns which can match across lines are
+					// searched by searcher, so these matches are within
+					// a line.
+					for _, offset := range offsets {
+						multilineMatches = append(multilineMatches, &multilineMatch{
+							JPreview: string(l.Line),
+							JStart:   multilinePosition{Line: int32(l.LineNumber - 1), Character: offset[0]},
+							JEnd:     multilinePosition{Line: int32(l.LineNumber - 1), Character: offset[0] + offset[1]},
+						})
+					}
+				} else if !isSymbol {
 					lines = append(lines, &lineMatch{
 						JPreview:          string(l.Line),
 						JLineNumber:       int32(l.LineNumber - 1),
@@ -246,13 +258,14 @@ func zoektSearchHEAD(ctx context.Context, args *search.TextParameters, repos []*
 			}
 		}
 		matches[i] = &FileMatchResolver{
-			JPath:        file.FileName,
-			JLineMatches: lines,
-			JLimitHit:    fileLimitHit,
-			uri:          fileMatchURI(repoRev.Repo.Name, "", file.FileName),
-			symbols:      symbols,
-			Repo:         repoRev.Repo,
-			CommitID:     repoRev.IndexedHEADCommit(),
+			JPath:             file.FileName,
+			JLineMatches:      lines,
+			JMultilineMatches: multilineMatches,
+			JLimitHit:         fileLimitHit,
+			uri:               fileMatchURI(repoRev.Repo.Name, "", file.FileName),
+			symbols:           symbols,
+			Repo:              repoRev.Repo,
+			CommitID:          repoRev.IndexedHEADCommit(),
 		}
 	}
 
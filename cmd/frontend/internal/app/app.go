@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/NYTimes/gziphandler"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/auth/userpasswd"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
@@ -31,6 +32,12 @@ func NewHandler() http.Handler {
 
 	m.Handle("/", r)
 
+	// 🚨 SECURITY: Access tokens without the "user:all" scope may only use the UI routes that
+	// accept their scopes.
+	r.Use(authz.RouteScopesMiddleware(map[string][]string{
+		router.UI: authz.UserScopes,
+	}))
+
 	r.Get(router.RobotsTxt).Handler(trace.TraceRoute(http.HandlerFunc(robotsTxt)))
 	r.Get(router.Favicon).Handler(trace.TraceRoute(http.HandlerFunc(favicon)))
 	r.Get(router.OpenSearch).Handler(trace.TraceRoute(http.HandlerFunc(openSearch)))
@@ -55,6 +62,9 @@ func NewHandler() http.Handler {
 	r.Get(router.SignUp).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSignUp)))
 	r.Get(router.SiteInit).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSiteInit)))
 	r.Get(router.SignIn).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSignIn)))
+	r.Get(router.SignInTwoFactor).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSignInTwoFactor)))
+	r.Get(router.SignInTwoFactorTOTPEnroll).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSignInTwoFactorTOTPEnroll)))
+	r.Get(router.SignInTwoFactorWebAuthnOptions).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleSignInTwoFactorWebAuthnOptions)))
 	r.Get(router.SignOut).Handler(trace.TraceRoute(http.HandlerFunc(serveSignOut)))
 	r.Get(router.VerifyEmail).Handler(trace.TraceRoute(http.HandlerFunc(serveVerifyEmail)))
 	r.Get(router.ResetPasswordInit).Handler(trace.TraceRoute(http.HandlerFunc(userpasswd.HandleResetPasswordInit)))
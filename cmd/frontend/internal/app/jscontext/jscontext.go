@@ -29,6 +29,7 @@ import (
 var BillingPublishableKey string
 
 type authProviderInfo struct {
+	ServiceType       string `json:"serviceType"`
 	IsBuiltin         bool   `json:"isBuiltin"`
 	DisplayName       string `json:"displayName"`
 	AuthenticationURL string `json:"authenticationURL"`
@@ -113,6 +114,7 @@ func NewJSContextFromRequest(req *http.Request) JSContext {
 		info := p.CachedInfo()
 		if info != nil {
 			authProviders = append(authProviders, authProviderInfo{
+				ServiceType:       p.ConfigID().Type,
 				IsBuiltin:         p.Config().Builtin != nil,
 				DisplayName:       info.DisplayName,
 				AuthenticationURL: info.AuthenticationURL,
@@ -20,13 +20,16 @@ const (
 
 	Logout = "logout"
 
-	SignIn            = "sign-in"
-	SignOut           = "sign-out"
-	SignUp            = "sign-up"
-	SiteInit          = "site-init"
-	VerifyEmail       = "verify-email"
-	ResetPasswordInit = "reset-password.init"
-	ResetPasswordCode = "reset-password.code"
+	SignIn                         = "sign-in"
+	SignInTwoFactor                = "sign-in.2fa"
+	SignInTwoFactorTOTPEnroll      = "sign-in.2fa.totp-enroll"
+	SignInTwoFactorWebAuthnOptions = "sign-in.2fa.webauthn-options"
+	SignOut                        = "sign-out"
+	SignUp                         = "sign-up"
+	SiteInit                       = "site-init"
+	VerifyEmail                    = "verify-email"
+	ResetPasswordInit              = "reset-password.init"
+	ResetPasswordCode              = "reset-password.code"
 
 	RegistryExtensionBundle = "registry.extension.bundle"
 
@@ -66,6 +69,9 @@ func newRouter() *mux.Router {
 	base.Path("/-/site-init").Methods("POST").Name(SiteInit)
 	base.Path("/-/verify-email").Methods("GET").Name(VerifyEmail)
 	base.Path("/-/sign-in").Methods("POST").Name(SignIn)
+	base.Path("/-/sign-in/2fa").Methods("POST").Name(SignInTwoFactor)
+	base.Path("/-/sign-in/2fa/totp-enroll").Methods("POST").Name(SignInTwoFactorTOTPEnroll)
+	base.Path("/-/sign-in/2fa/webauthn-options").Methods("POST").Name(SignInTwoFactorWebAuthnOptions)
 	base.Path("/-/sign-out").Methods("GET").Name(SignOut)
 	base.Path("/-/reset-password-init").Methods("POST").Name(ResetPasswordInit)
 	base.Path("/-/reset-password-code").Methods("POST").Name(ResetPasswordCode)
@@ -18,6 +18,7 @@ import (
 	"github.com/gorilla/mux"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	uirouter "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/ui/router"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
@@ -182,6 +183,13 @@ func initRouter() {
 	// basic pages with static titles
 	router := newRouter()
 	uirouter.Router = router // make accessible to other packages
+
+	// 🚨 SECURITY: Access tokens without the "user:all" scope may only download repository
+	// contents.
+	router.Use(authz.RouteScopesMiddleware(map[string][]string{
+		routeRaw: {authz.ScopeRepoRead},
+	}))
+
 	router.Get(routeHome).Handler(handler(serveHome))
 	router.Get(routeThreads).Handler(handler(serveBrandedPageString("Threads")))
 	router.Get(routeCampaigns).Handler(handler(serveBrandedPageString("Campaigns")))
@@ -0,0 +1,65 @@
+package twofactor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+var encryptionKey = env.Get("SRC_TWO_FACTOR_ENCRYPTION_KEY", "", "secret key used to encrypt two-factor authentication (TOTP) secrets stored in the database")
+
+// ErrNoEncryptionKey occurs when a TOTP secret needs to be encrypted or decrypted but no encryption
+// key is configured.
+var ErrNoEncryptionKey = errors.New("TOTP two-factor authentication is not available because the SRC_TWO_FACTOR_ENCRYPTION_KEY environment variable is not set on the frontend")
+
+func newAEAD() (cipher.AEAD, error) {
+	if encryptionKey == "" {
+		return nil, ErrNoEncryptionKey
+	}
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt encrypts the user's secret with AES-256-GCM. The ciphertext is bound to the user ID, so
+// it can't be moved to another user.
+func encrypt(userID int32, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData(userID)), nil
+}
+
+// decrypt decrypts a ciphertext returned by encrypt.
+func decrypt(userID int32, ciphertext []byte) ([]byte, error) {
+	aead, err := newAEAD()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted two-factor secret")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(userID))
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting two-factor secret (was SRC_TWO_FACTOR_ENCRYPTION_KEY changed?)")
+	}
+	return plaintext, nil
+}
+
+func additionalData(userID int32) []byte {
+	return []byte("user:" + strconv.Itoa(int(userID)))
+}
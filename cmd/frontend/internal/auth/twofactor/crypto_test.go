@@ -0,0 +1,37 @@
+package twofactor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncrypt(t *testing.T) {
+	defer func(orig string) { encryptionKey = orig }(encryptionKey)
+
+	encryptionKey = ""
+	if _, err := encrypt(1, []byte("s")); err != ErrNoEncryptionKey {
+		t.Fatalf("got error %v, want %v", err, ErrNoEncryptionKey)
+	}
+
+	encryptionKey = "k1"
+	ciphertext, err := encrypt(1, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ciphertext, []byte("secret")) {
+		t.Fatal("ciphertext contains plaintext")
+	}
+	if plaintext, err := decrypt(1, ciphertext); err != nil {
+		t.Fatal(err)
+	} else if string(plaintext) != "secret" {
+		t.Errorf("got %q, want %q", plaintext, "secret")
+	}
+
+	if _, err := decrypt(2, ciphertext); err == nil {
+		t.Error("decrypt for another user: want error")
+	}
+	encryptionKey = "k2"
+	if _, err := decrypt(1, ciphertext); err == nil {
+		t.Error("decrypt with another key: want error")
+	}
+}
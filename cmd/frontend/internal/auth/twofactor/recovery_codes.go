@@ -0,0 +1,38 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// recoveryCodeCount is the number of recovery codes that a user gets.
+const recoveryCodeCount = 10
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// newRecoveryCodes generates recovery codes (of the form "xxxxx-xxxxx") and their hashes, which are
+// stored instead of the codes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		var b [6]byte // 48 bits of entropy
+		if _, err := rand.Read(b[:]); err != nil {
+			return nil, nil, err
+		}
+		s := recoveryCodeEncoding.EncodeToString(b[:])[:10]
+		code := s[:5] + "-" + s[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode returns the hash of the recovery code. Recovery codes have enough entropy that a
+// fast hash is sufficient. Dashes, whitespace and case are ignored.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Join(strings.FieldsFunc(code, func(r rune) bool { return r == '-' || r == ' ' }), ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
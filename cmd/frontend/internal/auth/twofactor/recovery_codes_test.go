@@ -0,0 +1,42 @@
+package twofactor
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+	seen := map[string]bool{}
+	for i, code := range codes {
+		if !regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`).MatchString(code) {
+			t.Errorf("invalid recovery code %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+		if hashes[i] != hashRecoveryCode(code) {
+			t.Errorf("hash of %q does not match", code)
+		}
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	want := hashRecoveryCode("abcde-fghij")
+	for _, code := range []string{"abcdefghij", "ABCDE-FGHIJ", " abcde fghij "} {
+		if got := hashRecoveryCode(code); got != want {
+			t.Errorf("hash of %q differs from hash of %q", code, "abcde-fghij")
+		}
+	}
+	if strings.Contains(want, "abcde") || hashRecoveryCode("abcde-fghik") == want {
+		t.Error("unexpected hash")
+	}
+}
@@ -0,0 +1,84 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238). These are the defaults that all common authenticator apps support.
+const (
+	totpDigits = 6
+	totpPeriod = 30 // seconds
+
+	// totpSkew is the number of time steps before and after the current one whose codes are
+	// accepted, to allow for clock drift and slow typing.
+	totpSkew = 1
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func newTOTPSecret() ([]byte, error) {
+	secret := make([]byte, 20) // the HMAC-SHA1 key size recommended by RFC 4226
+	_, err := rand.Read(secret)
+	return secret, err
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// totpCode returns the code of the time step (RFC 6238 with HMAC-SHA1).
+func totpCode(secret []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 section 5.3).
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// validateTOTPCode reports whether code is the code of a time step within totpSkew steps of now,
+// and returns that time step.
+func validateTOTPCode(secret []byte, code string, now time.Time) (step int64, ok bool) {
+	code = strings.Replace(strings.TrimSpace(code), " ", "", -1)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := totpStep(now)
+	for s := current - totpSkew; s <= current+totpSkew; s++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(secret, s)), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// totpURI returns the otpauth:// URI (as used in QR codes) for adding the secret to an
+// authenticator app. See https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func totpURI(secret []byte, issuer, accountName string) string {
+	u := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + accountName,
+		RawQuery: url.Values{
+			"secret":    []string{base32NoPadding.EncodeToString(secret)},
+			"issuer":    []string{issuer},
+			"algorithm": []string{"SHA1"},
+			"digits":    []string{fmt.Sprint(totpDigits)},
+			"period":    []string{fmt.Sprint(totpPeriod)},
+		}.Encode(),
+	}
+	return u.String()
+}
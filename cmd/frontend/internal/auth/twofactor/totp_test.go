@@ -0,0 +1,62 @@
+package twofactor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors from RFC 6238 appendix B (SHA1), truncated to 6 digits.
+	secret := []byte("12345678901234567890")
+	tests := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, want := range tests {
+		if got := totpCode(secret, totpStep(time.Unix(unix, 0))); got != want {
+			t.Errorf("at %d: got %q, want %q", unix, got, want)
+		}
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1234567890, 0)
+	current := totpStep(now)
+
+	tests := []struct {
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{code: totpCode(secret, current), wantStep: current, wantOK: true},
+		{code: " " + totpCode(secret, current)[:3] + " " + totpCode(secret, current)[3:], wantStep: current, wantOK: true},
+		{code: totpCode(secret, current-1), wantStep: current - 1, wantOK: true},
+		{code: totpCode(secret, current+1), wantStep: current + 1, wantOK: true},
+		{code: totpCode(secret, current-2)},
+		{code: totpCode(secret, current+2)},
+		{code: ""},
+		{code: "1234567"},
+	}
+	for _, test := range tests {
+		step, ok := validateTOTPCode(secret, test.code, now)
+		if ok != test.wantOK || step != test.wantStep {
+			t.Errorf("code %q: got (%d, %v), want (%d, %v)", test.code, step, ok, test.wantStep, test.wantOK)
+		}
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := totpURI([]byte("12345678901234567890"), "Sourcegraph", "alice@example.com")
+	if want := "otpauth://totp/Sourcegraph:alice@example.com?"; !strings.HasPrefix(uri, want) {
+		t.Errorf("got %q, want prefix %q", uri, want)
+	}
+	if want := "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"; !strings.Contains(uri, want) {
+		t.Errorf("got %q, want it to contain %q", uri, want)
+	}
+}
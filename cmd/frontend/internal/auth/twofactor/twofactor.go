@@ -0,0 +1,532 @@
+// Package twofactor implements two-factor authentication (TOTP with recovery codes, and WebAuthn
+// security keys) for builtin (username-password) user accounts.
+package twofactor
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+)
+
+// totpIssuer is shown as the account's issuer in authenticator apps.
+const totpIssuer = "Sourcegraph"
+
+// maxFailedAttempts is the number of failed verification attempts per user after which further
+// attempts are rejected until failedAttemptsPeriod has passed.
+const (
+	maxFailedAttempts    = 10
+	failedAttemptsPeriod = 15 * time.Minute
+)
+
+// ErrTooManyAttempts occurs when a user has failed verification too many times recently.
+var ErrTooManyAttempts = errors.New("too many failed two-factor authentication attempts, try again later")
+
+// ErrInvalidCode occurs when a TOTP or recovery code is incorrect.
+var ErrInvalidCode = errors.New("invalid two-factor authentication code")
+
+type cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, b []byte)
+	Delete(key string)
+	Increment(key string) (int, error)
+}
+
+// Mockable in tests.
+var (
+	challenges     cache = rcache.NewWithTTL("webauthn-challenge", webAuthnTimeout/1000*2)
+	failedAttempts cache = rcache.NewWithTTL("2fa-failed-attempts", int(failedAttemptsPeriod/time.Second))
+	timeNow              = time.Now
+	externalURL          = globals.ExternalURL
+)
+
+// RequiredForUser reports whether the site configuration requires the user to use two-factor
+// authentication. It is only required for builtin (username-password) user accounts.
+func RequiredForUser(user *types.User) bool {
+	c := conf.Get().AuthTwoFactor
+	return user.SiteAdmin && user.BuiltinAuth && c != nil && c.RequireForSiteAdmins
+}
+
+// EnrollmentRequired reports whether two-factor authentication is required for the user (see
+// RequiredForUser) but the user has no second factor. Such users must sign in again to enroll one.
+func EnrollmentRequired(ctx context.Context, user *types.User) (bool, error) {
+	if !RequiredForUser(user) {
+		return false, nil
+	}
+	status, err := GetStatus(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return !status.Enabled(), nil
+}
+
+// Reauthentication is proof that the current user is present, required to remove their second
+// factors or regenerate their recovery codes. Exactly one of the fields must be set.
+type Reauthentication struct {
+	Password     string
+	TOTPCode     string
+	RecoveryCode string
+}
+
+// ErrReauthenticationRequired occurs when a Reauthentication has none (or more than one) of its
+// fields set.
+var ErrReauthenticationRequired = errors.New("confirm with your password, a code from your authenticator app or a recovery code")
+
+// Reauthenticate verifies the user's password, TOTP code or recovery code. Failed attempts count
+// towards the same limit as failed two-factor authentication attempts.
+//
+// 🚨 SECURITY: A nil error means that the user proved their presence.
+func Reauthenticate(ctx context.Context, userID int32, reauth Reauthentication) error {
+	var set int
+	for _, s := range []string{reauth.Password, reauth.TOTPCode, reauth.RecoveryCode} {
+		if s != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return ErrReauthenticationRequired
+	}
+	switch {
+	case reauth.TOTPCode != "":
+		return VerifyTOTP(ctx, userID, reauth.TOTPCode)
+	case reauth.RecoveryCode != "":
+		return VerifyRecoveryCode(ctx, userID, reauth.RecoveryCode)
+	}
+	if err := checkFailedAttempts(userID); err != nil {
+		return err
+	}
+	ok, err := db.Users.IsPassword(ctx, userID, reauth.Password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		recordFailedAttempt(userID)
+		return errors.New("incorrect password")
+	}
+	return nil
+}
+
+// Status describes a user's enabled second factors.
+type Status struct {
+	TOTPEnabled            bool
+	WebAuthnCredentials    []*db.UserWebAuthnCredential
+	RecoveryCodesRemaining int
+}
+
+// Enabled reports whether the user has at least one second factor.
+func (s *Status) Enabled() bool {
+	return s.TOTPEnabled || len(s.WebAuthnCredentials) > 0
+}
+
+// Methods returns the verification methods that the user can use ("totp", "webauthn" and
+// "recoveryCode").
+func (s *Status) Methods() []string {
+	var methods []string
+	if s.TOTPEnabled {
+		methods = append(methods, "totp")
+	}
+	if len(s.WebAuthnCredentials) > 0 {
+		methods = append(methods, "webauthn")
+	}
+	if s.RecoveryCodesRemaining > 0 {
+		methods = append(methods, "recoveryCode")
+	}
+	return methods
+}
+
+// GetStatus returns the user's two-factor authentication status.
+func GetStatus(ctx context.Context, userID int32) (*Status, error) {
+	totp, err := db.UserTwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := db.UserTwoFactor.ListWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := db.UserTwoFactor.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		TOTPEnabled:            totp != nil && totp.EnabledAt != nil,
+		WebAuthnCredentials:    creds,
+		RecoveryCodesRemaining: count,
+	}, nil
+}
+
+// TOTPEnrollment is a pending TOTP enrollment, to be added to the user's authenticator app.
+type TOTPEnrollment struct {
+	Secret string // base32-encoded, for manual entry
+	URI    string // otpauth:// URI, for QR codes
+}
+
+// BeginTOTPEnrollment generates and stores a new pending TOTP secret for the user. The enrollment
+// must be confirmed with ConfirmTOTPEnrollment.
+func BeginTOTPEnrollment(ctx context.Context, user *types.User) (*TOTPEnrollment, error) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encrypt(user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UserTwoFactor.SetPendingTOTP(ctx, user.ID, encrypted); err != nil {
+		return nil, err
+	}
+	accountName := user.Username
+	if u := externalURL(); u != nil && u.Host != "" {
+		accountName += "@" + u.Hostname()
+	}
+	return &TOTPEnrollment{
+		Secret: base32NoPadding.EncodeToString(secret),
+		URI:    totpURI(secret, totpIssuer, accountName),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables the user's pending TOTP secret if code is valid for it. If TOTP is
+// the user's first second factor, new recovery codes are generated and returned.
+func ConfirmTOTPEnrollment(ctx context.Context, userID int32, code string) (recoveryCodes []string, err error) {
+	if err := checkFailedAttempts(userID); err != nil {
+		return nil, err
+	}
+	status, err := GetStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	totp, err := db.UserTwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil || totp.EnabledAt != nil {
+		return nil, errors.New("no pending TOTP enrollment")
+	}
+	secret, err := decrypt(userID, totp.EncryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := validateTOTPCode(secret, code, timeNow())
+	if !ok {
+		recordFailedAttempt(userID)
+		return nil, ErrInvalidCode
+	}
+	if err := db.UserTwoFactor.EnableTOTP(ctx, userID, step); err != nil {
+		return nil, err
+	}
+	if status.Enabled() {
+		return nil, nil
+	}
+	return RegenerateRecoveryCodes(ctx, userID)
+}
+
+// RemoveTOTP removes the user's TOTP factor.
+func RemoveTOTP(ctx context.Context, userID int32) error {
+	if err := db.UserTwoFactor.DeleteTOTP(ctx, userID); err != nil {
+		return err
+	}
+	return clearRecoveryCodesIfNoFactors(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes with new ones and returns them.
+func RegenerateRecoveryCodes(ctx context.Context, userID int32) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UserTwoFactor.SetRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// clearRecoveryCodesIfNoFactors removes the user's recovery codes after the last second factor was
+// removed. Otherwise the recovery codes would still be accepted by a later enrollment.
+func clearRecoveryCodesIfNoFactors(ctx context.Context, userID int32) error {
+	status, err := GetStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if status.Enabled() {
+		return nil
+	}
+	return db.UserTwoFactor.SetRecoveryCodes(ctx, userID, nil)
+}
+
+func challengeKey(userID int32, purpose string) string {
+	return strconv.Itoa(int(userID)) + ":" + purpose
+}
+
+// takeChallenge returns and forgets the stored challenge, so that each challenge can only be used
+// once.
+func takeChallenge(userID int32, purpose string) (string, error) {
+	key := challengeKey(userID, purpose)
+	challenge, ok := challenges.Get(key)
+	if !ok {
+		return "", errors.New("WebAuthn challenge expired or not found (try again)")
+	}
+	challenges.Delete(key)
+	return string(challenge), nil
+}
+
+func newStoredChallenge(userID int32, purpose string) (string, error) {
+	challenge, err := newChallenge()
+	if err != nil {
+		return "", err
+	}
+	challenges.Set(challengeKey(userID, purpose), []byte(challenge))
+	return challenge, nil
+}
+
+// BeginWebAuthnRegistration returns the options (as JSON) to pass to navigator.credentials.create
+// to register a new security key for the user.
+func BeginWebAuthnRegistration(ctx context.Context, user *types.User) (json.RawMessage, error) {
+	rp, err := newRelyingParty(externalURL())
+	if err != nil {
+		return nil, err
+	}
+	creds, err := db.UserTwoFactor.ListWebAuthnCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := newStoredChallenge(user.ID, "register")
+	if err != nil {
+		return nil, err
+	}
+
+	var opts creationOptions
+	opts.Challenge = challenge
+	opts.RP.ID = rp.ID
+	opts.RP.Name = "Sourcegraph"
+	opts.User.ID = b64.EncodeToString([]byte(strconv.Itoa(int(user.ID))))
+	opts.User.Name = user.Username
+	opts.User.DisplayName = user.DisplayName
+	if opts.User.DisplayName == "" {
+		opts.User.DisplayName = user.Username
+	}
+	for _, alg := range []int{coseAlgES256, coseAlgEdDSA, coseAlgRS256} {
+		opts.PubKeyCredParams = append(opts.PubKeyCredParams, struct {
+			Type string `json:"type"`
+			Alg  int    `json:"alg"`
+		}{Type: "public-key", Alg: alg})
+	}
+	opts.Timeout = webAuthnTimeout
+	opts.ExcludeCredentials = credentialDescriptors(creds)
+	opts.AuthenticatorSelection.UserVerification = "discouraged"
+	opts.Attestation = "none"
+	return json.Marshal(opts)
+}
+
+// FinishWebAuthnRegistration verifies the response (as JSON) of navigator.credentials.create and
+// registers the new security key under the given name. If it is the user's first second factor,
+// new recovery codes are generated and returned.
+func FinishWebAuthnRegistration(ctx context.Context, userID int32, name string, response json.RawMessage) (recoveryCodes []string, err error) {
+	rp, err := newRelyingParty(externalURL())
+	if err != nil {
+		return nil, err
+	}
+	var resp registrationResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return nil, errors.Wrap(err, "invalid WebAuthn registration response")
+	}
+	challenge, err := takeChallenge(userID, "register")
+	if err != nil {
+		return nil, err
+	}
+	credentialID, publicKey, signCount, err := verifyRegistration(rp, challenge, &resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "WebAuthn registration failed")
+	}
+
+	status, err := GetStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = "Security key"
+	}
+	if err := db.UserTwoFactor.CreateWebAuthnCredential(ctx, &db.UserWebAuthnCredential{
+		UserID:       userID,
+		Name:         name,
+		CredentialID: credentialID,
+		PublicKey:    publicKey,
+		SignCount:    int64(signCount),
+	}); err != nil {
+		return nil, err
+	}
+	if status.Enabled() {
+		return nil, nil
+	}
+	return RegenerateRecoveryCodes(ctx, userID)
+}
+
+// RemoveWebAuthnCredential removes one of the user's security keys.
+func RemoveWebAuthnCredential(ctx context.Context, userID, id int32) error {
+	if err := db.UserTwoFactor.DeleteWebAuthnCredential(ctx, userID, id); err != nil {
+		return err
+	}
+	return clearRecoveryCodesIfNoFactors(ctx, userID)
+}
+
+func credentialDescriptors(creds []*db.UserWebAuthnCredential) []credentialDescriptor {
+	descs := make([]credentialDescriptor, 0, len(creds))
+	for _, c := range creds {
+		descs = append(descs, credentialDescriptor{Type: "public-key", ID: b64.EncodeToString(c.CredentialID)})
+	}
+	return descs
+}
+
+// BeginWebAuthnLogin returns the options (as JSON) to pass to navigator.credentials.get to verify
+// one of the user's security keys.
+func BeginWebAuthnLogin(ctx context.Context, userID int32) (json.RawMessage, error) {
+	rp, err := newRelyingParty(externalURL())
+	if err != nil {
+		return nil, err
+	}
+	creds, err := db.UserTwoFactor.ListWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(creds) == 0 {
+		return nil, errors.New("no security keys are registered")
+	}
+	challenge, err := newStoredChallenge(userID, "login")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(requestOptions{
+		Challenge:        challenge,
+		RPID:             rp.ID,
+		AllowCredentials: credentialDescriptors(creds),
+		Timeout:          webAuthnTimeout,
+		UserVerification: "discouraged",
+	})
+}
+
+// VerifyWebAuthn verifies the response (as JSON) of navigator.credentials.get for the challenge
+// returned by BeginWebAuthnLogin.
+//
+// 🚨 SECURITY: A nil error means that the user completed two-factor authentication.
+func VerifyWebAuthn(ctx context.Context, userID int32, response json.RawMessage) error {
+	if err := checkFailedAttempts(userID); err != nil {
+		return err
+	}
+	rp, err := newRelyingParty(externalURL())
+	if err != nil {
+		return err
+	}
+	var resp assertionResponse
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return errors.Wrap(err, "invalid WebAuthn response")
+	}
+	challenge, err := takeChallenge(userID, "login")
+	if err != nil {
+		return err
+	}
+	credentialID, err := decodeBase64URL(resp.ID)
+	if err != nil {
+		return errors.Wrap(err, "invalid credential ID")
+	}
+	creds, err := db.UserTwoFactor.ListWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return err
+	}
+	var cred *db.UserWebAuthnCredential
+	for _, c := range creds {
+		if string(c.CredentialID) == string(credentialID) {
+			cred = c
+			break
+		}
+	}
+	if cred == nil {
+		recordFailedAttempt(userID)
+		return errors.New("unknown security key")
+	}
+	signCount, err := verifyAssertion(rp, challenge, cred.PublicKey, cred.SignCount, &resp)
+	if err != nil {
+		recordFailedAttempt(userID)
+		return errors.Wrap(err, "WebAuthn verification failed")
+	}
+	return db.UserTwoFactor.UpdateWebAuthnSignCount(ctx, cred.ID, int64(signCount))
+}
+
+// VerifyTOTP verifies a code from the user's authenticator app. Each code can only be used once.
+//
+// 🚨 SECURITY: A nil error means that the user completed two-factor authentication.
+func VerifyTOTP(ctx context.Context, userID int32, code string) error {
+	if err := checkFailedAttempts(userID); err != nil {
+		return err
+	}
+	totp, err := db.UserTwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if totp == nil || totp.EnabledAt == nil {
+		return errors.New("TOTP is not enabled")
+	}
+	secret, err := decrypt(userID, totp.EncryptedSecret)
+	if err != nil {
+		return err
+	}
+	step, ok := validateTOTPCode(secret, code, timeNow())
+	if ok {
+		// Reject replays of this code (or of older codes).
+		ok, err = db.UserTwoFactor.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return err
+		}
+	}
+	if !ok {
+		recordFailedAttempt(userID)
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// VerifyRecoveryCode verifies and uses up one of the user's recovery codes.
+//
+// 🚨 SECURITY: A nil error means that the user completed two-factor authentication.
+func VerifyRecoveryCode(ctx context.Context, userID int32, code string) error {
+	if err := checkFailedAttempts(userID); err != nil {
+		return err
+	}
+	ok, err := db.UserTwoFactor.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		recordFailedAttempt(userID)
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+func failedAttemptsKey(userID int32) string {
+	return strconv.Itoa(int(userID))
+}
+
+func checkFailedAttempts(userID int32) error {
+	if b, ok := failedAttempts.Get(failedAttemptsKey(userID)); ok {
+		if n, _ := strconv.Atoi(string(b)); n >= maxFailedAttempts {
+			return ErrTooManyAttempts
+		}
+	}
+	return nil
+}
+
+// recordFailedAttempt counts a failed verification attempt. The count expires failedAttemptsPeriod
+// after the most recent failure. It is incremented atomically, so concurrent failures are all
+// counted.
+func recordFailedAttempt(userID int32) {
+	if _, err := failedAttempts.Increment(failedAttemptsKey(userID)); err != nil {
+		log15.Error("Failed to record failed two-factor authentication attempt.", "userID", userID, "error", err)
+	}
+}
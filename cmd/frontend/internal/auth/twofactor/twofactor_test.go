@@ -0,0 +1,46 @@
+package twofactor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+func TestReauthenticate(t *testing.T) {
+	defer func(f cache) { failedAttempts = f }(failedAttempts)
+	failedAttempts = memoryCache{}
+	db.Mocks.Users.IsPassword = func(_ context.Context, _ int32, password string) (bool, error) {
+		return password == "correct", nil
+	}
+	db.Mocks.UserTwoFactor.UseRecoveryCode = func(_ context.Context, _ int32, hash string) (bool, error) {
+		return hash == hashRecoveryCode("aaaaa-bbbbb"), nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+	ctx := context.Background()
+
+	for _, reauth := range []Reauthentication{{}, {Password: "correct", RecoveryCode: "aaaaa-bbbbb"}} {
+		if err := Reauthenticate(ctx, 1, reauth); err != ErrReauthenticationRequired {
+			t.Errorf("%+v: got error %v, want %v", reauth, err, ErrReauthenticationRequired)
+		}
+	}
+	if err := Reauthenticate(ctx, 1, Reauthentication{Password: "correct"}); err != nil {
+		t.Errorf("correct password: %v", err)
+	}
+	if err := Reauthenticate(ctx, 1, Reauthentication{RecoveryCode: "AAAAA-BBBBB"}); err != nil {
+		t.Errorf("correct recovery code: %v", err)
+	}
+
+	// 🚨 SECURITY: Failed attempts are limited.
+	for i := 0; i < maxFailedAttempts; i++ {
+		if err := Reauthenticate(ctx, 1, Reauthentication{Password: "wrong"}); err == nil {
+			t.Fatal("wrong password: got nil error")
+		}
+	}
+	if err := Reauthenticate(ctx, 1, Reauthentication{Password: "correct"}); err != ErrTooManyAttempts {
+		t.Errorf("got error %v, want %v", err, ErrTooManyAttempts)
+	}
+	if err := Reauthenticate(ctx, 2, Reauthentication{Password: "correct"}); err != nil {
+		t.Errorf("other user: %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package twofactor
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// This file implements the relying party side of WebAuthn (https://www.w3.org/TR/webauthn-2/) for
+// security keys used as a second factor.
+//
+// Registration requests no attestation ("none" attestation conveyance), so the authenticator's
+// make and model are not verified. The browser's AuthenticatorAttestationResponse getPublicKey()
+// and getAuthenticatorData() methods provide the credential's public key and authenticator data,
+// which avoids decoding CBOR and COSE keys.
+
+// COSE algorithm identifiers of the supported public key types.
+const (
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+)
+
+// webAuthnTimeout is how long the user has to use their security key, in milliseconds.
+const webAuthnTimeout = 120000
+
+// relyingParty identifies the Sourcegraph site to authenticators.
+type relyingParty struct {
+	ID     string // the host name of the external URL
+	Origin string // the origin of the external URL
+}
+
+func newRelyingParty(externalURL *url.URL) (relyingParty, error) {
+	if externalURL == nil || externalURL.Host == "" {
+		return relyingParty{}, errors.New("WebAuthn security keys require the site's externalURL to be set")
+	}
+	return relyingParty{
+		ID:     externalURL.Hostname(),
+		Origin: externalURL.Scheme + "://" + externalURL.Host,
+	}, nil
+}
+
+type credentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"` // base64url
+}
+
+// creationOptions is the JSON form of PublicKeyCredentialCreationOptions. Binary values are
+// base64url-encoded.
+type creationOptions struct {
+	Challenge string `json:"challenge"`
+	RP        struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams []struct {
+		Type string `json:"type"`
+		Alg  int    `json:"alg"`
+	} `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		UserVerification string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+	Attestation string `json:"attestation"`
+}
+
+// requestOptions is the JSON form of PublicKeyCredentialRequestOptions. Binary values are
+// base64url-encoded.
+type requestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []credentialDescriptor `json:"allowCredentials"`
+	Timeout          int                    `json:"timeout"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// registrationResponse is the JSON form of the AuthenticatorAttestationResponse of a newly created
+// credential. Binary values are base64url-encoded.
+type registrationResponse struct {
+	ID                string `json:"id"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	PublicKey         string `json:"publicKey"` // PKIX (SubjectPublicKeyInfo) DER
+}
+
+// assertionResponse is the JSON form of an AuthenticatorAssertionResponse. Binary values are
+// base64url-encoded.
+type assertionResponse struct {
+	ID                string `json:"id"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+}
+
+var b64 = base64.RawURLEncoding
+
+func newChallenge() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(b[:]), nil
+}
+
+// decodeBase64URL decodes base64url with or without padding.
+func decodeBase64URL(s string) ([]byte, error) {
+	for len(s)%4 != 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return b64.DecodeString(s)
+}
+
+// verifyClientData checks the collected client data (https://www.w3.org/TR/webauthn-2/#dictionary-client-data).
+func verifyClientData(rp relyingParty, clientDataJSON []byte, wantType, wantChallenge string) error {
+	var cd struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+		Origin    string `json:"origin"`
+	}
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil {
+		return errors.Wrap(err, "invalid client data")
+	}
+	if cd.Type != wantType {
+		return errors.Errorf("unexpected client data type %q", cd.Type)
+	}
+	if subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(wantChallenge)) != 1 {
+		return errors.New("challenge mismatch")
+	}
+	if cd.Origin != rp.Origin {
+		return errors.Errorf("origin %q does not match the site's external URL %q", cd.Origin, rp.Origin)
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte // only present during registration
+}
+
+// parseAuthenticatorData parses and checks authenticator data
+// (https://www.w3.org/TR/webauthn-2/#sctn-authenticator-data).
+func parseAuthenticatorData(rp relyingParty, b []byte) (*authenticatorData, error) {
+	if len(b) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(b[:32], rpIDHash[:]) {
+		return nil, errors.Errorf("authenticator data is not for relying party %q", rp.ID)
+	}
+	d := &authenticatorData{Flags: b[32], SignCount: binary.BigEndian.Uint32(b[33:37])}
+	if d.Flags&flagUserPresent == 0 {
+		return nil, errors.New("user presence was not verified")
+	}
+	if d.Flags&flagAttestedCredentialData != 0 {
+		// The attested credential data is the AAGUID (16 bytes), the credential ID length (2
+		// bytes) and the credential ID, followed by the CBOR-encoded public key.
+		rest := b[37:]
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		n := int(binary.BigEndian.Uint16(rest[16:18]))
+		if len(rest) < 18+n {
+			return nil, errors.New("attested credential data is too short")
+		}
+		d.CredentialID = rest[18 : 18+n]
+	}
+	return d, nil
+}
+
+// verifyRegistration verifies the response to a credential creation request with the given
+// challenge and returns the new credential.
+func verifyRegistration(rp relyingParty, challenge string, resp *registrationResponse) (credentialID, publicKey []byte, signCount uint32, err error) {
+	clientDataJSON, err := decodeBase64URL(resp.ClientDataJSON)
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "invalid clientDataJSON")
+	}
+	if err := verifyClientData(rp, clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, nil, 0, err
+	}
+	rawAuthData, err := decodeBase64URL(resp.AuthenticatorData)
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "invalid authenticatorData")
+	}
+	authData, err := parseAuthenticatorData(rp, rawAuthData)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	credentialID, err = decodeBase64URL(resp.ID)
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "invalid credential ID")
+	}
+	if len(credentialID) == 0 || !bytes.Equal(credentialID, authData.CredentialID) {
+		return nil, nil, 0, errors.New("credential ID does not match the authenticator data")
+	}
+	publicKey, err = decodeBase64URL(resp.PublicKey)
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "invalid publicKey")
+	}
+	if _, err := parsePublicKey(publicKey); err != nil {
+		return nil, nil, 0, err
+	}
+	return credentialID, publicKey, authData.SignCount, nil
+}
+
+// verifyAssertion verifies the response to a credential request with the given challenge, signed
+// by the credential with the given public key. It returns the authenticator's new signature
+// counter.
+func verifyAssertion(rp relyingParty, challenge string, publicKey []byte, storedSignCount int64, resp *assertionResponse) (signCount uint32, err error) {
+	clientDataJSON, err := decodeBase64URL(resp.ClientDataJSON)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid clientDataJSON")
+	}
+	if err := verifyClientData(rp, clientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	rawAuthData, err := decodeBase64URL(resp.AuthenticatorData)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid authenticatorData")
+	}
+	authData, err := parseAuthenticatorData(rp, rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	sig, err := decodeBase64URL(resp.Signature)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid signature")
+	}
+
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+	var valid bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		var esig struct{ R, S *big.Int }
+		if rest, err := asn1.Unmarshal(sig, &esig); err == nil && len(rest) == 0 {
+			valid = ecdsa.Verify(key, digest[:], esig.R, esig.S)
+		}
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, signed, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+	if !valid {
+		return 0, errors.New("invalid signature")
+	}
+
+	// A signature counter that doesn't increase indicates a cloned authenticator. Authenticators
+	// that don't implement a counter always report 0.
+	if authData.SignCount != 0 || storedSignCount != 0 {
+		if int64(authData.SignCount) <= storedSignCount {
+			return 0, errors.New("signature counter did not increase (the security key may have been cloned)")
+		}
+	}
+	return authData.SignCount, nil
+}
+
+// parsePublicKey parses a PKIX public key of a supported type (ES256, EdDSA or RS256).
+func parsePublicKey(der []byte) (crypto.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid public key")
+	}
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("unsupported elliptic curve (only P-256 is supported)")
+		}
+	case ed25519.PublicKey, *rsa.PublicKey:
+	default:
+		return nil, errors.Errorf("unsupported public key type %T", key)
+	}
+	return key, nil
+}
@@ -0,0 +1,218 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+type memoryCache map[string][]byte
+
+func (c memoryCache) Get(key string) ([]byte, bool) { b, ok := c[key]; return b, ok }
+func (c memoryCache) Set(key string, b []byte)      { c[key] = b }
+func (c memoryCache) Delete(key string)             { delete(c, key) }
+func (c memoryCache) Increment(key string) (int, error) {
+	n, _ := strconv.Atoi(string(c[key]))
+	c[key] = []byte(strconv.Itoa(n + 1))
+	return n + 1, nil
+}
+
+// fakeAuthenticator is a security key that signs with an ECDSA P-256 key.
+type fakeAuthenticator struct {
+	t            *testing.T
+	rpID         string
+	credentialID []byte
+	key          *ecdsa.PrivateKey
+	signCount    uint32
+}
+
+func newFakeAuthenticator(t *testing.T, rpID string) *fakeAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeAuthenticator{t: t, rpID: rpID, credentialID: []byte("credential-1"), key: key}
+}
+
+func (a *fakeAuthenticator) authenticatorData(flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	b := append([]byte{}, rpIDHash[:]...)
+	b = append(b, flags)
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], a.signCount)
+	b = append(b, n[:]...)
+	if attested {
+		b = append(b, make([]byte, 16)...) // AAGUID
+		b = append(b, byte(len(a.credentialID)>>8), byte(len(a.credentialID)))
+		b = append(b, a.credentialID...)
+		b = append(b, 0xa0) // the (empty) CBOR-encoded public key, which is ignored
+	}
+	return b
+}
+
+func clientDataJSON(typ, challenge, origin string) []byte {
+	b, _ := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": origin})
+	return b
+}
+
+func (a *fakeAuthenticator) create(optionsJSON []byte, origin string) json.RawMessage {
+	var opts creationOptions
+	if err := json.Unmarshal(optionsJSON, &opts); err != nil {
+		a.t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.key.PublicKey)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	resp, _ := json.Marshal(registrationResponse{
+		ID:                b64.EncodeToString(a.credentialID),
+		ClientDataJSON:    b64.EncodeToString(clientDataJSON("webauthn.create", opts.Challenge, origin)),
+		AuthenticatorData: b64.EncodeToString(a.authenticatorData(flagUserPresent|flagAttestedCredentialData, true)),
+		PublicKey:         b64.EncodeToString(publicKey),
+	})
+	return resp
+}
+
+func (a *fakeAuthenticator) get(optionsJSON []byte, origin string) json.RawMessage {
+	var opts requestOptions
+	if err := json.Unmarshal(optionsJSON, &opts); err != nil {
+		a.t.Fatal(err)
+	}
+	a.signCount++
+	authData := a.authenticatorData(flagUserPresent, false)
+	cd := clientDataJSON("webauthn.get", opts.Challenge, origin)
+	cdHash := sha256.Sum256(cd)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), cdHash[:]...))
+	r, s, err := ecdsa.Sign(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	resp, _ := json.Marshal(assertionResponse{
+		ID:                b64.EncodeToString(a.credentialID),
+		ClientDataJSON:    b64.EncodeToString(cd),
+		AuthenticatorData: b64.EncodeToString(authData),
+		Signature:         b64.EncodeToString(sig),
+	})
+	return resp
+}
+
+func TestWebAuthn(t *testing.T) {
+	defer func(c, f cache, e func() *url.URL) { challenges, failedAttempts, externalURL = c, f, e }(challenges, failedAttempts, externalURL)
+	challenges, failedAttempts = memoryCache{}, memoryCache{}
+	externalURL = func() *url.URL { return &url.URL{Scheme: "https", Host: "sourcegraph.example.com"} }
+	const origin = "https://sourcegraph.example.com"
+
+	var (
+		creds         []*db.UserWebAuthnCredential
+		recoveryCodes []string
+	)
+	db.Mocks.UserTwoFactor = db.MockUserTwoFactor{
+		GetTOTP: func(context.Context, int32) (*db.UserTOTP, error) { return nil, nil },
+		ListWebAuthnCredentials: func(context.Context, int32) ([]*db.UserWebAuthnCredential, error) {
+			return creds, nil
+		},
+		CountRecoveryCodes: func(context.Context, int32) (int, error) { return len(recoveryCodes), nil },
+		SetRecoveryCodes: func(_ context.Context, _ int32, hashes []string) error {
+			recoveryCodes = hashes
+			return nil
+		},
+		CreateWebAuthnCredential: func(_ context.Context, c *db.UserWebAuthnCredential) error {
+			c.ID = int32(len(creds) + 1)
+			creds = append(creds, c)
+			return nil
+		},
+		UpdateWebAuthnSignCount: func(_ context.Context, id int32, signCount int64) error {
+			creds[id-1].SignCount = signCount
+			return nil
+		},
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	ctx := context.Background()
+	user := &types.User{ID: 1, Username: "alice"}
+	authenticator := newFakeAuthenticator(t, "sourcegraph.example.com")
+
+	// Register the security key.
+	opts, err := BeginWebAuthnRegistration(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := FinishWebAuthnRegistration(ctx, user.ID, "my key", authenticator.create(opts, origin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("got %d recovery codes for the first factor, want %d", len(codes), recoveryCodeCount)
+	}
+	if len(creds) != 1 || creds[0].Name != "my key" {
+		t.Fatalf("got credentials %+v", creds)
+	}
+	if _, err := FinishWebAuthnRegistration(ctx, user.ID, "my key", authenticator.create(opts, origin)); err == nil {
+		t.Error("reusing the registration challenge: want error")
+	}
+
+	// Sign in with the security key.
+	opts, err = BeginWebAuthnLogin(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyWebAuthn(ctx, user.ID, authenticator.get(opts, origin)); err != nil {
+		t.Fatal(err)
+	}
+	if creds[0].SignCount != 1 {
+		t.Errorf("got sign count %d, want 1", creds[0].SignCount)
+	}
+
+	t.Run("replayed challenge", func(t *testing.T) {
+		if err := VerifyWebAuthn(ctx, user.ID, authenticator.get(opts, origin)); err == nil {
+			t.Error("want error")
+		}
+	})
+	t.Run("wrong origin", func(t *testing.T) {
+		opts, err := BeginWebAuthnLogin(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyWebAuthn(ctx, user.ID, authenticator.get(opts, "https://evil.example.com")); err == nil {
+			t.Error("want error")
+		}
+	})
+	t.Run("cloned authenticator", func(t *testing.T) {
+		opts, err := BeginWebAuthnLogin(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		authenticator.signCount = 0
+		if err := VerifyWebAuthn(ctx, user.ID, authenticator.get(opts, origin)); err == nil {
+			t.Error("want error")
+		}
+	})
+	t.Run("other key", func(t *testing.T) {
+		opts, err := BeginWebAuthnLogin(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := newFakeAuthenticator(t, "sourcegraph.example.com")
+		other.signCount = 100
+		if err := VerifyWebAuthn(ctx, user.ID, other.get(opts, origin)); err == nil {
+			t.Error("want error")
+		}
+	})
+}
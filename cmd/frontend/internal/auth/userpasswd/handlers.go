@@ -195,7 +195,8 @@ func getByEmailOrUsername(ctx context.Context, emailOrUsername string) (*types.U
 }
 
 // HandleSignIn accepts a POST containing username-password credentials and authenticates the
-// current session if the credentials are valid.
+// current session if the credentials are valid. If the user must also perform two-factor
+// authentication, the session is only authenticated after HandleSignInTwoFactor succeeds.
 func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 	if handleEnabledCheck(w) {
 		return
@@ -229,6 +230,13 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 		return
 	}
+
+	// 🚨 SECURITY: require two-factor authentication (if enabled or required for the user) before
+	// authenticating the session
+	if beginTwoFactor(w, r, usr) {
+		return
+	}
+
 	actor := &actor.Actor{UID: usr.ID}
 
 	// Write the session cookie
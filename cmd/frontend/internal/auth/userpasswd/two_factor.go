@@ -0,0 +1,239 @@
+package userpasswd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/auth/twofactor"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// Two-factor authentication happens after the user's password is checked by HandleSignIn. If the
+// user has a second factor (or the site configuration requires one), HandleSignIn creates a
+// session that is pending two-factor authentication and responds with a twoFactorResponse. The
+// client then uses the handlers in this file to verify a second factor (or enroll one, if the user
+// has none but requires one). Only then is the session authenticated.
+
+// Two-factor authentication modes of a pending session.
+const (
+	twoFactorModeVerify = "verify" // the user must verify one of their second factors
+	twoFactorModeEnroll = "enroll" // the user must enroll a second factor (because it is required)
+)
+
+// twoFactorResponse is the response to a sign-in request whose password is correct but that still
+// requires two-factor authentication.
+type twoFactorResponse struct {
+	TwoFactor string   `json:"twoFactor"`         // the mode
+	Methods   []string `json:"methods,omitempty"` // the verification methods (in the verify mode)
+}
+
+// twoFactorMode returns the two-factor authentication mode for the user, or "" if the user need not
+// perform two-factor authentication.
+func twoFactorMode(ctx context.Context, usr *types.User) (mode string, status *twofactor.Status, err error) {
+	status, err = twofactor.GetStatus(ctx, usr.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case status.Enabled():
+		return twoFactorModeVerify, status, nil
+	case twofactor.RequiredForUser(usr):
+		return twoFactorModeEnroll, status, nil
+	default:
+		return "", status, nil
+	}
+}
+
+// beginTwoFactor is called after the user's password was checked. If the user must perform
+// two-factor authentication, it creates a pending session, writes the response and returns true.
+func beginTwoFactor(w http.ResponseWriter, r *http.Request, usr *types.User) (handled bool) {
+	mode, status, err := twoFactorMode(r.Context(), usr)
+	if err != nil {
+		httpLogAndError(w, "Error checking two-factor authentication", http.StatusInternalServerError, "err", err)
+		return true
+	}
+	if mode == "" {
+		return false
+	}
+	if err := session.SetActorPendingTwoFactor(w, r, &actor.Actor{UID: usr.ID}); err != nil {
+		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError, "err", err)
+		return true
+	}
+	resp := twoFactorResponse{TwoFactor: mode}
+	if mode == twoFactorModeVerify {
+		resp.Methods = status.Methods()
+	}
+	writeJSON(w, resp)
+	return true
+}
+
+// pendingTwoFactorUser returns the user of the current session that is pending two-factor
+// authentication. If there is none, it writes an error response and returns nil.
+func pendingTwoFactorUser(w http.ResponseWriter, r *http.Request) *types.User {
+	a := session.ActorPendingTwoFactor(r)
+	if a == nil {
+		http.Error(w, "Two-factor authentication session expired. Sign in with your password again.", http.StatusUnauthorized)
+		return nil
+	}
+	usr, err := db.Users.GetByID(r.Context(), a.UID)
+	if err != nil {
+		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "err", err)
+		return nil
+	}
+	return usr
+}
+
+type twoFactorRequest struct {
+	Method     string          `json:"method"` // "totp", "recoveryCode" or "webauthn"
+	Code       string          `json:"code"`
+	Credential json.RawMessage `json:"credential"`
+	Name       string          `json:"name"` // the name of a security key being enrolled
+}
+
+// HandleSignInTwoFactor accepts a POST containing a second factor for the current session that is
+// pending two-factor authentication, and authenticates the session if it is valid. In the enroll
+// mode, the second factor is enrolled and the user's new recovery codes are returned.
+//
+// 🚨 SECURITY: Any change to this function could allow bypassing two-factor authentication. Be
+// careful.
+func HandleSignInTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if handleEnabledCheck(w) {
+		return
+	}
+	var req twoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Could not decode request body", http.StatusBadRequest)
+		return
+	}
+	usr := pendingTwoFactorUser(w, r)
+	if usr == nil {
+		return
+	}
+	ctx := r.Context()
+	mode, _, err := twoFactorMode(ctx, usr)
+	if err != nil {
+		httpLogAndError(w, "Error checking two-factor authentication", http.StatusInternalServerError, "err", err)
+		return
+	}
+
+	// If the user no longer has (or requires) a second factor, there is nothing to verify.
+	var recoveryCodes []string
+	switch mode {
+	case twoFactorModeVerify:
+		switch req.Method {
+		case "totp":
+			err = twofactor.VerifyTOTP(ctx, usr.ID, req.Code)
+		case "recoveryCode":
+			err = twofactor.VerifyRecoveryCode(ctx, usr.ID, req.Code)
+		case "webauthn":
+			err = twofactor.VerifyWebAuthn(ctx, usr.ID, req.Credential)
+		default:
+			http.Error(w, "Unsupported two-factor authentication method", http.StatusBadRequest)
+			return
+		}
+	case twoFactorModeEnroll:
+		switch req.Method {
+		case "totp":
+			recoveryCodes, err = twofactor.ConfirmTOTPEnrollment(ctx, usr.ID, req.Code)
+		case "webauthn":
+			recoveryCodes, err = twofactor.FinishWebAuthnRegistration(ctx, usr.ID, req.Name, req.Credential)
+		default:
+			http.Error(w, "Unsupported two-factor authentication method", http.StatusBadRequest)
+			return
+		}
+	}
+	if err != nil {
+		status := http.StatusUnauthorized
+		if err == twofactor.ErrTooManyAttempts {
+			status = http.StatusTooManyRequests
+		}
+		log15.Warn("Two-factor authentication failed.", "userID", usr.ID, "method", req.Method, "error", err)
+		http.Error(w, "Two-factor authentication failed: "+err.Error(), status)
+		return
+	}
+
+	// Write the session cookie
+	if err := session.SetActor(w, r, &actor.Actor{UID: usr.ID}, 0); err != nil {
+		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, struct {
+		RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+	}{RecoveryCodes: recoveryCodes})
+}
+
+// HandleSignInTwoFactorTOTPEnroll begins enrolling TOTP for the user of the current session that
+// must enroll a second factor, and responds with the new TOTP secret.
+func HandleSignInTwoFactorTOTPEnroll(w http.ResponseWriter, r *http.Request) {
+	if handleEnabledCheck(w) {
+		return
+	}
+	usr := pendingTwoFactorUser(w, r)
+	if usr == nil {
+		return
+	}
+	mode, _, err := twoFactorMode(r.Context(), usr)
+	if err != nil {
+		httpLogAndError(w, "Error checking two-factor authentication", http.StatusInternalServerError, "err", err)
+		return
+	}
+	if mode != twoFactorModeEnroll {
+		http.Error(w, "Two-factor authentication is already enrolled", http.StatusBadRequest)
+		return
+	}
+	enrollment, err := twofactor.BeginTOTPEnrollment(r.Context(), usr)
+	if err == twofactor.ErrNoEncryptionKey {
+		httpLogAndError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		httpLogAndError(w, "Could not begin TOTP enrollment", http.StatusInternalServerError, "err", err)
+		return
+	}
+	writeJSON(w, struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}{Secret: enrollment.Secret, URI: enrollment.URI})
+}
+
+// HandleSignInTwoFactorWebAuthnOptions responds with the options for the browser's WebAuthn API:
+// request options to verify one of the user's security keys (in the verify mode), or creation
+// options to register a new security key (in the enroll mode).
+func HandleSignInTwoFactorWebAuthnOptions(w http.ResponseWriter, r *http.Request) {
+	if handleEnabledCheck(w) {
+		return
+	}
+	usr := pendingTwoFactorUser(w, r)
+	if usr == nil {
+		return
+	}
+	ctx := r.Context()
+	mode, _, err := twoFactorMode(ctx, usr)
+	if err != nil {
+		httpLogAndError(w, "Error checking two-factor authentication", http.StatusInternalServerError, "err", err)
+		return
+	}
+	var options json.RawMessage
+	if mode == twoFactorModeEnroll {
+		options, err = twofactor.BeginWebAuthnRegistration(ctx, usr)
+	} else {
+		options, err = twofactor.BeginWebAuthnLogin(ctx, usr.ID)
+	}
+	if err != nil {
+		httpLogAndError(w, "Could not begin WebAuthn authentication", http.StatusInternalServerError, "err", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(options)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log15.Error("Error writing JSON response.", "error", err)
+	}
+}
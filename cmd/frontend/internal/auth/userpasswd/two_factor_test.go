@@ -0,0 +1,62 @@
+package userpasswd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func Test_twoFactorMode(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+	defer conf.Mock(nil)
+
+	var (
+		totp  *db.UserTOTP
+		creds []*db.UserWebAuthnCredential
+	)
+	db.Mocks.UserTwoFactor = db.MockUserTwoFactor{
+		GetTOTP: func(context.Context, int32) (*db.UserTOTP, error) { return totp, nil },
+		ListWebAuthnCredentials: func(context.Context, int32) ([]*db.UserWebAuthnCredential, error) {
+			return creds, nil
+		},
+		CountRecoveryCodes: func(context.Context, int32) (int, error) { return 0, nil },
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		user    *types.User
+		require bool
+		totp    *db.UserTOTP
+		creds   []*db.UserWebAuthnCredential
+		want    string
+	}{
+		{name: "no factors", user: &types.User{ID: 1}, want: ""},
+		{name: "pending TOTP", user: &types.User{ID: 1}, totp: &db.UserTOTP{}, want: ""},
+		{name: "TOTP", user: &types.User{ID: 1}, totp: &db.UserTOTP{EnabledAt: &now}, want: twoFactorModeVerify},
+		{name: "security key", user: &types.User{ID: 1}, creds: []*db.UserWebAuthnCredential{{ID: 1}}, want: twoFactorModeVerify},
+		{name: "required for site admin", user: &types.User{ID: 1, SiteAdmin: true, BuiltinAuth: true}, require: true, want: twoFactorModeEnroll},
+		{name: "required for site admin with factor", user: &types.User{ID: 1, SiteAdmin: true, BuiltinAuth: true}, require: true, totp: &db.UserTOTP{EnabledAt: &now}, want: twoFactorModeVerify},
+		{name: "not required for non-site admin", user: &types.User{ID: 1}, require: true, want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthTwoFactor: &schema.AuthTwoFactor{RequireForSiteAdmins: test.require},
+			}})
+			totp, creds = test.totp, test.creds
+			mode, _, err := twoFactorMode(context.Background(), test.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode != test.want {
+				t.Errorf("got mode %q, want %q", mode, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// forEachDefaultBranch calls fn with the commit of the default branch of
+// every cloned repository whose refs changed after since. Repositories which
+// aren't cloned yet are skipped rather than cloned. Errors returned by fn are
+// logged with the given description of the task.
+func forEachDefaultBranch(ctx context.Context, since time.Time, task string, fn func(repo *types.Repo, commitID api.CommitID) error) {
+	const pageSize = 500
+	opt := db.ReposListOptions{
+		OnlyRepoIDs: true,
+		LimitOffset: &db.LimitOffset{Limit: pageSize},
+	}
+	for {
+		repos, err := db.Repos.List(ctx, opt)
+		if err != nil {
+			log15.Error("listing repositories to "+task, "error", err)
+			return
+		}
+
+		changed, err := reposChangedSince(ctx, repos, since)
+		if err != nil {
+			log15.Error("getting repositories to "+task, "error", err)
+			return
+		}
+		for _, repo := range changed {
+			commitID, err := git.ResolveRevision(ctx, gitserver.Repo{Name: repo.Name}, nil, "HEAD", &git.ResolveRevisionOptions{NoEnsureRevision: true})
+			if err != nil {
+				continue
+			}
+			if err := fn(repo, commitID); err != nil {
+				log15.Warn(task, "repo", repo.Name, "commitID", commitID, "error", err)
+			}
+		}
+
+		if len(repos) < pageSize {
+			return
+		}
+		opt.Offset += pageSize
+	}
+}
+
+var mockRepoInfo func(repos ...api.RepoName) (*protocol.RepoInfoResponse, error)
+
+// reposChangedSince returns the repositories which are cloned and whose refs
+// changed after since.
+func reposChangedSince(ctx context.Context, repos []*types.Repo, since time.Time) ([]*types.Repo, error) {
+	if len(repos) == 0 {
+		return nil, nil
+	}
+	names := make([]api.RepoName, len(repos))
+	for i, repo := range repos {
+		names[i] = repo.Name
+	}
+	var (
+		res *protocol.RepoInfoResponse
+		err error
+	)
+	if mockRepoInfo != nil {
+		res, err = mockRepoInfo(names...)
+	} else {
+		res, err = gitserver.DefaultClient.RepoInfo(ctx, names...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []*types.Repo
+	for _, repo := range repos {
+		info := res.Results[repo.Name]
+		if info == nil || !info.Cloned || info.LastChanged == nil || !info.LastChanged.After(since) {
+			continue
+		}
+		changed = append(changed, repo)
+	}
+	return changed, nil
+}
@@ -0,0 +1,22 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// DeleteOldSearchHistoryInPostgres deletes search history entries older than the
+// "search.history.retentionDays" site config value. It never returns.
+func DeleteOldSearchHistoryInPostgres(ctx context.Context) {
+	for {
+		retention := time.Duration(conf.SearchHistoryRetentionDays()) * 24 * time.Hour
+		if _, err := db.SearchHistory.DeleteOlderThan(ctx, time.Now().Add(-retention)); err != nil {
+			log15.Error("deleting expired rows from search_history table", "error", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
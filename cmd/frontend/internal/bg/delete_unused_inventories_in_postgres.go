@@ -0,0 +1,25 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+// inventoryRetention is how long stored language inventories are kept after
+// they were last used.
+const inventoryRetention = 30 * 24 * time.Hour
+
+// DeleteUnusedInventoriesInPostgres deletes the stored language inventories
+// of Git trees which have not been used for inventoryRetention. It never
+// returns.
+func DeleteUnusedInventoriesInPostgres(ctx context.Context) {
+	for {
+		if _, err := db.InventoryObjects.DeleteUnusedSince(ctx, time.Now().Add(-inventoryRetention)); err != nil {
+			log15.Error("deleting unused rows from inventory_objects table", "error", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
@@ -0,0 +1,59 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+var indexDependenciesInterval = env.Get("DEPENDENCY_INDEX_INTERVAL", "6h", "interval at which the dependencies declared by the manifest files of default branches are indexed in the background (0 disables it)")
+
+// IndexDependencies periodically extracts the packages that the default
+// branch of every cloned repository provides and depends on from its
+// manifest files, to build the dependency graph between repositories. Only
+// repositories whose default branch changed since the previous run are
+// indexed again.
+func IndexDependencies(ctx context.Context) {
+	interval, err := time.ParseDuration(indexDependenciesInterval)
+	if err != nil {
+		log15.Error("invalid DEPENDENCY_INDEX_INTERVAL, not indexing dependencies", "error", err)
+		return
+	}
+	// Sourcegraph.com has too many repositories to index all dependencies.
+	if interval <= 0 || envvar.SourcegraphDotComMode() {
+		return
+	}
+
+	// 🚨 SECURITY: The dependencies of all repositories are stored, and
+	// repository permissions are enforced when they are returned.
+	ctx = actor.WithActor(ctx, &actor.Actor{Internal: true})
+	for {
+		indexDependencies(ctx)
+		time.Sleep(interval)
+	}
+}
+
+// indexDependencies indexes the dependencies of every cloned repository
+// whose default branch isn't indexed yet.
+func indexDependencies(ctx context.Context) {
+	forEachDefaultBranch(ctx, time.Time{}, "index dependencies", func(repo *types.Repo, commitID api.CommitID) error {
+		indexed, err := db.RepoPackages.GetCommit(ctx, repo.ID)
+		if err != nil || indexed == commitID {
+			return err
+		}
+		m, err := depgraph.ForCommit(ctx, gitserver.Repo{Name: repo.Name}, commitID)
+		if err != nil {
+			return err
+		}
+		return db.RepoPackages.Set(ctx, repo.ID, commitID, m)
+	})
+}
@@ -0,0 +1,72 @@
+package bg
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/util"
+)
+
+func TestIndexDependencies(t *testing.T) {
+	defer func() {
+		db.Mocks = db.MockStores{}
+		git.ResetMocks()
+		mockRepoInfo = nil
+	}()
+
+	db.Mocks.Repos.List = func(ctx context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		if opt.Offset > 0 {
+			return nil, nil
+		}
+		return []*types.Repo{{ID: 1, Name: "changed"}, {ID: 2, Name: "unchanged"}, {ID: 3, Name: "not-cloned"}}, nil
+	}
+	lastChanged := time.Now()
+	mockRepoInfo = func(repos ...api.RepoName) (*protocol.RepoInfoResponse, error) {
+		return &protocol.RepoInfoResponse{Results: map[api.RepoName]*protocol.RepoInfo{
+			"changed":    {Cloned: true, LastChanged: &lastChanged},
+			"unchanged":  {Cloned: true, LastChanged: &lastChanged},
+			"not-cloned": {},
+		}}, nil
+	}
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		return "c2", nil
+	}
+	db.Mocks.RepoPackages.GetCommit = func(ctx context.Context, repo api.RepoID) (api.CommitID, error) {
+		if repo == 2 {
+			return "c2", nil
+		}
+		return "c1", nil
+	}
+	git.Mocks.ReadDir = func(commit api.CommitID, name string, recurse bool) ([]os.FileInfo, error) {
+		return []os.FileInfo{&util.FileInfo{Name_: "go.mod"}}, nil
+	}
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		return []byte("module example.com/a\nrequire example.com/b v1.0.0\n"), nil
+	}
+	var indexed []api.RepoID
+	db.Mocks.RepoPackages.Set = func(ctx context.Context, repo api.RepoID, commit api.CommitID, m *depgraph.Manifest) error {
+		if commit != "c2" {
+			t.Errorf("got commit %q, want c2", commit)
+		}
+		if want := []depgraph.Package{{Kind: depgraph.KindGo, Name: "example.com/b", Version: "v1.0.0"}}; !reflect.DeepEqual(m.Dependencies, want) {
+			t.Errorf("got dependencies %+v, want %+v", m.Dependencies, want)
+		}
+		indexed = append(indexed, repo)
+		return nil
+	}
+
+	indexDependencies(context.Background())
+
+	if want := []api.RepoID{1}; !reflect.DeepEqual(indexed, want) {
+		t.Errorf("got dependencies indexed for %v, want %v", indexed, want)
+	}
+}
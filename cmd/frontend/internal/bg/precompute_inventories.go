@@ -0,0 +1,53 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+var precomputeInventoriesInterval = env.Get("INVENTORY_PRECOMPUTE_INTERVAL", "6h", "interval at which the language inventories of default branches are computed in the background (0 disables it)")
+
+// PrecomputeInventories periodically computes the language inventory of the
+// default branch of every cloned repository that changed since the previous
+// run, so that language statistics are fast when they are requested.
+// Inventories are stored per Git tree, so only trees that changed are
+// computed.
+func PrecomputeInventories(ctx context.Context) {
+	interval, err := time.ParseDuration(precomputeInventoriesInterval)
+	if err != nil {
+		log15.Error("invalid INVENTORY_PRECOMPUTE_INTERVAL, not precomputing inventories", "error", err)
+		return
+	}
+	// Sourcegraph.com has too many repositories to compute all inventories.
+	if interval <= 0 || envvar.SourcegraphDotComMode() {
+		return
+	}
+
+	// 🚨 SECURITY: The inventories are only stored, never returned, so it is
+	// fine to compute them for all repositories.
+	ctx = actor.WithActor(ctx, &actor.Actor{Internal: true})
+	since := time.Now().Add(-interval)
+	for {
+		start := time.Now()
+		precomputeInventories(ctx, since)
+		since = start
+		time.Sleep(interval)
+	}
+}
+
+// precomputeInventories computes the inventories of the repositories which
+// changed after since.
+func precomputeInventories(ctx context.Context, since time.Time) {
+	forEachDefaultBranch(ctx, since, "precompute inventories", func(repo *types.Repo, commitID api.CommitID) error {
+		_, err := backend.Repos.GetInventory(ctx, repo, commitID, false)
+		return err
+	})
+}
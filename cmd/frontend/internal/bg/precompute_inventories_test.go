@@ -0,0 +1,61 @@
+package bg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/inventory"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestPrecomputeInventories(t *testing.T) {
+	defer func() {
+		db.Mocks = db.MockStores{}
+		backend.Mocks = backend.MockServices{}
+		git.ResetMocks()
+		mockRepoInfo = nil
+	}()
+
+	db.Mocks.Repos.List = func(ctx context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		if opt.Offset > 0 {
+			return nil, nil
+		}
+		return []*types.Repo{{ID: 1, Name: "changed"}, {ID: 2, Name: "unchanged"}, {ID: 3, Name: "not-cloned"}}, nil
+	}
+	since := time.Now().Add(-time.Hour)
+	before, after := since.Add(-time.Minute), since.Add(time.Minute)
+	mockRepoInfo = func(repos ...api.RepoName) (*protocol.RepoInfoResponse, error) {
+		return &protocol.RepoInfoResponse{Results: map[api.RepoName]*protocol.RepoInfo{
+			"changed":    {Cloned: true, LastChanged: &after},
+			"unchanged":  {Cloned: true, LastChanged: &before},
+			"not-cloned": {},
+		}}, nil
+	}
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		if opt == nil || !opt.NoEnsureRevision {
+			t.Error("expected the revision not to be fetched")
+		}
+		return "c1", nil
+	}
+	var computed []api.RepoName
+	backend.Mocks.Repos.GetInventory = func(ctx context.Context, repo *types.Repo, commitID api.CommitID) (*inventory.Inventory, error) {
+		if commitID != "c1" {
+			t.Errorf("got commit %q, want c1", commitID)
+		}
+		computed = append(computed, repo.Name)
+		return &inventory.Inventory{}, nil
+	}
+
+	precomputeInventories(context.Background(), since)
+
+	if want := []api.RepoName{"changed"}; !reflect.DeepEqual(computed, want) {
+		t.Errorf("got inventories computed for %v, want %v", computed, want)
+	}
+}
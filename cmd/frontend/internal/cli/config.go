@@ -269,10 +269,20 @@ func serviceConnections() conftypes.ServiceConnections {
 			PostgresDSN: dbutil.PostgresDSN(username, os.Getenv),
 		}
 	})
-	return serviceConnectionsVal
+
+	c := serviceConnectionsVal
+	if m := gitServerEndpoints(); m != nil {
+		// The gitservers can change at any time, so always use the latest.
+		c.GitServers = dynamicGitServers(m)
+	}
+	return c
 }
 
 func gitServers() []string {
+	if m := gitServerEndpoints(); m != nil {
+		return dynamicGitServers(m)
+	}
+
 	v := os.Getenv("SRC_GIT_SERVERS")
 	if v == "" {
 		// Detect 'go test' and setup default addresses in that case.
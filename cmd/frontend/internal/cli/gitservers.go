@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/endpoint"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+var rebalanceGitServers, _ = strconv.ParseBool(env.Get("SRC_GITSERVER_REBALANCE", "true", "transfer cloned repositories to their new gitserver when gitserver endpoints change, instead of recloning them"))
+
+var (
+	gitServerEndpointsOnce sync.Once
+	gitServerEndpointsVal  *endpoint.Map
+)
+
+// gitServerEndpoints returns the endpoint.Map for SRC_GIT_SERVERS if it is a
+// file://, dns+srv+rpc:// or k8s+rpc:// URL, so gitserver instances can be
+// added and removed without restarting every service. Otherwise it returns
+// nil.
+func gitServerEndpoints() *endpoint.Map {
+	gitServerEndpointsOnce.Do(func() {
+		v := os.Getenv("SRC_GIT_SERVERS")
+		if !strings.HasPrefix(v, "file://") && !strings.HasPrefix(v, "dns+srv+") && !strings.HasPrefix(v, "k8s+") {
+			return
+		}
+
+		gitServerEndpointsVal = endpoint.New(v)
+		r := &gitserverReassignmentReporter{}
+		gitServerEndpointsVal.Subscribe(r.report)
+	})
+	return gitServerEndpointsVal
+}
+
+var (
+	lastGitServersMu sync.Mutex
+	lastGitServers   []string
+)
+
+// dynamicGitServers returns the current gitserver addresses of m, sorted so
+// that every service hashes repositories to the same gitserver. If m has no
+// endpoints right now, e.g. because the file or DNS records can't be read,
+// the last addresses it had are returned, since repositories can't be
+// assigned to a gitserver without any.
+func dynamicGitServers(m *endpoint.Map) []string {
+	lastGitServersMu.Lock()
+	defer lastGitServersMu.Unlock()
+
+	eps, err := m.Endpoints()
+	if err != nil || len(eps) == 0 {
+		log15.Error("failed to get gitserver endpoints, using the last known gitservers", "error", err, "last", lastGitServers)
+		return lastGitServers
+	}
+
+	addrs := make([]string, 0, len(eps))
+	for addr := range eps {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	lastGitServers = addrs
+	return addrs
+}
+
+// gitserverReassignmentReporter logs which repositories are now assigned to a
+// different gitserver whenever the gitserver endpoints change. Unless
+// SRC_GITSERVER_REBALANCE is false, it also asks the new gitservers to
+// transfer the repositories from their previous gitserver.
+type gitserverReassignmentReporter struct {
+	mu sync.Mutex
+}
+
+func (r *gitserverReassignmentReporter) report(c endpoint.Change) {
+	go func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		names, err := db.Repos.ListEnabledNames(context.Background())
+		if err != nil {
+			log15.Error("failed to list repositories for gitserver reassignment report", "error", err)
+			return
+		}
+		repos := make([]api.RepoName, len(names))
+		for i, name := range names {
+			repos[i] = api.RepoName(name)
+		}
+
+		rs := gitserver.Reassignments(c.Before, c.After, repos)
+		gitserverReassignedRepos.Set(float64(len(rs)))
+
+		moves := map[[2]string]int{}
+		for _, re := range rs {
+			moves[[2]string{re.From, re.To}]++
+		}
+		for move, n := range moves {
+			log15.Info("repositories reassigned to a different gitserver", "from", move[0], "to", move[1], "count", n)
+		}
+
+		if !rebalanceGitServers {
+			log15.Warn("gitserver endpoints changed, repositories need to be recloned on their new gitserver",
+				"added", c.Added, "removed", c.Removed, "reassigned", len(rs), "total", len(repos))
+			return
+		}
+		log15.Info("gitserver endpoints changed, migrating repositories to their new gitserver",
+			"added", c.Added, "removed", c.Removed, "reassigned", len(rs), "total", len(repos))
+		migrateReassignedRepos(context.Background(), rs)
+	}()
+}
+
+// migrateReassignedRepos asks the new gitserver of each reassignment to
+// transfer the repository from its previous gitserver.
+func migrateReassignedRepos(ctx context.Context, rs []gitserver.RepoReassignment) {
+	byAddr := map[string][]protocol.RepoMigration{}
+	for _, re := range rs {
+		if re.From == "" || re.To == "" {
+			continue
+		}
+		byAddr[re.To] = append(byAddr[re.To], protocol.RepoMigration{Repo: re.Repo, From: re.From})
+	}
+
+	for addr, migrations := range byAddr {
+		if err := gitserver.DefaultClient.MigrateRepos(ctx, addr, migrations); err != nil {
+			log15.Error("failed to start repository migration, repositories will be recloned on their new gitserver", "gitserver", addr, "count", len(migrations), "error", err)
+		}
+	}
+}
+
+var gitserverReassignedRepos = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "src",
+	Subsystem: "frontend",
+	Name:      "gitserver_reassigned_repos",
+	Help:      "Number of repositories assigned to a different gitserver by the last change of gitserver endpoints.",
+})
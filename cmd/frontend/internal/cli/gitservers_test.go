@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/endpoint"
+)
+
+func TestDynamicGitServers(t *testing.T) {
+	defer func() { lastGitServers = nil }()
+
+	want := []string{"gitserver-0:3178", "gitserver-1:3178"}
+	if have := dynamicGitServers(endpoint.New("gitserver-1:3178 gitserver-0:3178")); !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+
+	// Without any endpoints, the last known gitservers are used.
+	if have := dynamicGitServers(endpoint.New("")); !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %v, want %v", have, want)
+	}
+}
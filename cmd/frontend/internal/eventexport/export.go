@@ -0,0 +1,124 @@
+// Package eventexport exports usage events from the event_logs table to the sinks configured in
+// the eventLogs.export site configuration (such as a data warehouse).
+package eventexport
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// exportInterval is how often new events are exported.
+	exportInterval = 30 * time.Second
+
+	// exportMinAge is how long after an event is inserted it is exported. Events are exported in
+	// order of their IDs, but inserts can commit out of order, so an event is only exported when
+	// every insert of an event with a lower ID has had time to commit (see
+	// db.EventLogs.ListAfterID). Otherwise the checkpoint could move past an event that isn't
+	// visible yet, and it would never be exported.
+	exportMinAge = time.Minute
+
+	defaultBatchSize = 500
+)
+
+// Start exports new usage events to the configured sinks periodically. It never returns.
+//
+// There can be multiple frontend processes running, so each batch is exported while holding a
+// lock on the sink's checkpoint row, and only 1 process exports to a sink at a time.
+func Start(ctx context.Context) {
+	for {
+		for _, c := range conf.Get().EventLogsExport {
+			if err := exportAll(ctx, c); err != nil {
+				log15.Error("Exporting usage events failed. The events will be exported again later.", "sink", c.Name, "error", err)
+			}
+		}
+		time.Sleep(exportInterval)
+	}
+}
+
+// exportAll exports all events that have not yet been exported to the sink.
+func exportAll(ctx context.Context, c *schema.EventLogsExportSink) error {
+	s, err := newSink(c)
+	if err != nil {
+		return err
+	}
+	batchSize := c.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	for {
+		n, err := exportBatch(ctx, c, s, batchSize)
+		if err != nil {
+			return err
+		}
+		if n < batchSize {
+			return nil
+		}
+	}
+}
+
+// exportBatch exports the next batch of at most batchSize events to the sink and advances the
+// sink's checkpoint. It returns the number of events exported.
+//
+// The checkpoint is only advanced after the sink accepted the batch, so every event is delivered
+// at least once. If the process fails after the sink accepted the batch but before the checkpoint
+// is advanced, the batch is delivered again.
+func exportBatch(ctx context.Context, c *schema.EventLogsExportSink, s sink, batchSize int) (n int, err error) {
+	if _, err := dbconn.Global.ExecContext(ctx, "INSERT INTO event_logs_export_checkpoints(sink) VALUES($1) ON CONFLICT DO NOTHING", c.Name); err != nil {
+		return 0, errors.Wrap(err, "creating checkpoint")
+	}
+
+	tx, err := dbconn.Global.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var lastEventID int64
+	err = tx.QueryRowContext(ctx, "SELECT last_event_id FROM event_logs_export_checkpoints WHERE sink=$1 FOR UPDATE SKIP LOCKED", c.Name).Scan(&lastEventID)
+	if err == sql.ErrNoRows {
+		// Another frontend process is exporting to this sink.
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "reading checkpoint")
+	}
+
+	events, err := db.EventLogs.ListAfterID(ctx, lastEventID, batchSize, exportMinAge)
+	if err != nil {
+		return 0, errors.Wrap(err, "listing events")
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	m := newMapper(c)
+	records := make([][]byte, len(events))
+	for i, e := range events {
+		if records[i], err = m.encode(e); err != nil {
+			return 0, errors.Wrapf(err, "encoding event %d", e.ID)
+		}
+	}
+	if err := s.send(ctx, records); err != nil {
+		return 0, errors.Wrapf(err, "sending %d events", len(records))
+	}
+
+	lastEventID = int64(events[len(events)-1].ID)
+	if _, err := tx.ExecContext(ctx, "UPDATE event_logs_export_checkpoints SET last_event_id=$2, updated_at=now() WHERE sink=$1", c.Name, lastEventID); err != nil {
+		return 0, errors.Wrap(err, "updating checkpoint")
+	}
+	return len(events), nil
+}
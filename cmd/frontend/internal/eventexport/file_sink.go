@@ -0,0 +1,91 @@
+package eventexport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	defaultMaxFileSizeMB = 100
+	defaultMaxFiles      = 5
+)
+
+// fileSink appends events as newline-delimited JSON to a file. When the file reaches the maximum
+// size, it is rotated: PATH is renamed to PATH.1, PATH.1 to PATH.2, and so on, and the oldest file
+// beyond the maximum number of files is removed.
+//
+// Each batch is written by whichever frontend process holds the sink's checkpoint lock, so with
+// multiple frontend replicas, the path must be on storage that all of them share. Otherwise the
+// events are scattered across the replicas' filesystems.
+type fileSink struct {
+	path        string
+	maxFileSize int64
+	maxFiles    int
+}
+
+func newFileSink(c *schema.EventLogsExportSink) *fileSink {
+	s := fileSink{path: c.Path, maxFileSize: int64(c.MaxFileSizeMB) * 1024 * 1024, maxFiles: c.MaxFiles}
+	if s.maxFileSize == 0 {
+		s.maxFileSize = defaultMaxFileSizeMB * 1024 * 1024
+	}
+	if s.maxFiles == 0 {
+		s.maxFiles = defaultMaxFiles
+	}
+	return &s
+}
+
+func (s *fileSink) send(ctx context.Context, records [][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if fi, err := os.Stat(s.path); err == nil && fi.Size() >= s.maxFileSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		_, _ = w.Write(r)
+		_ = w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	// Sync so that the batch is durable before the checkpoint is advanced.
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *fileSink) rotate() error {
+	if err := os.Remove(s.rotatedPath(s.maxFiles)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxFiles - 1; i >= 0; i-- {
+		if err := os.Rename(s.rotatedPath(i), s.rotatedPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// rotatedPath returns the path of the ith rotated file (or of the current file if i is 0).
+func (s *fileSink) rotatedPath(i int) string {
+	if i == 0 {
+		return s.path
+	}
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
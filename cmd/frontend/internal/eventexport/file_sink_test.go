@@ -0,0 +1,45 @@
+package eventexport
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventexport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events", "events.ndjson")
+	s := &fileSink{path: path, maxFileSize: 10, maxFiles: 2}
+	ctx := context.Background()
+
+	// Each batch fills the file beyond its maximum size, so every later batch rotates it.
+	for _, record := range []string{`{"n":1234567}`, `{"n":2345678}`, `{"n":3456789}`, `{"n":4567890}`} {
+		if err := s.send(ctx, [][]byte{[]byte(record)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for p, want := range map[string]string{
+		path:        "{\"n\":4567890}\n",
+		path + ".1": "{\"n\":3456789}\n",
+		path + ".2": "{\"n\":2345678}\n",
+	} {
+		got, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: got %q, want %q", p, got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("got %v, want the oldest rotated file to be removed", err)
+	}
+}
@@ -0,0 +1,92 @@
+package eventexport
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// redacted replaces the values of redacted fields.
+const redacted = "REDACTED"
+
+// argumentPrefix is the prefix of field names that refer to a property of the event's argument.
+const argumentPrefix = "argument."
+
+// eventFields are the names of the event_logs fields that can be exported.
+var eventFields = []string{"id", "name", "url", "user_id", "anonymous_user_id", "source", "argument", "version", "timestamp"}
+
+// mapper converts events to the records sent to a sink, using the sink's field mapping and
+// redaction configuration.
+type mapper struct {
+	fields map[string]string // exported field name -> event field name
+	redact map[string]bool   // event field names
+}
+
+func newMapper(c *schema.EventLogsExportSink) *mapper {
+	m := mapper{fields: c.Fields, redact: make(map[string]bool, len(c.Redact))}
+	if len(m.fields) == 0 {
+		m.fields = make(map[string]string, len(eventFields))
+		for _, f := range eventFields {
+			m.fields[f] = f
+		}
+	}
+	for _, f := range c.Redact {
+		m.redact[f] = true
+	}
+	return &m
+}
+
+// encode returns the JSON record for the event.
+func (m *mapper) encode(e *types.Event) ([]byte, error) {
+	return json.Marshal(m.record(e))
+}
+
+// record returns the fields of the event to export, with redacted fields replaced.
+func (m *mapper) record(e *types.Event) map[string]interface{} {
+	var argument interface{}
+	if e.Argument != "" {
+		if err := json.Unmarshal([]byte(e.Argument), &argument); err != nil {
+			argument = e.Argument
+		}
+	}
+
+	source := map[string]interface{}{
+		"id":                e.ID,
+		"name":              e.Name,
+		"url":               e.URL,
+		"user_id":           e.UserID,
+		"anonymous_user_id": e.AnonymousUserID,
+		"source":            e.Source,
+		"argument":          argument,
+		"version":           e.Version,
+		"timestamp":         e.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	for f := range m.redact {
+		if strings.HasPrefix(f, argumentPrefix) {
+			if o, ok := argument.(map[string]interface{}); ok {
+				if _, ok := o[strings.TrimPrefix(f, argumentPrefix)]; ok {
+					o[strings.TrimPrefix(f, argumentPrefix)] = redacted
+				}
+			}
+		} else if v, ok := source[f]; ok && v != nil && v != "" {
+			source[f] = redacted
+		}
+	}
+
+	record := make(map[string]interface{}, len(m.fields))
+	for name, f := range m.fields {
+		if strings.HasPrefix(f, argumentPrefix) {
+			var v interface{}
+			if o, ok := source["argument"].(map[string]interface{}); ok {
+				v = o[strings.TrimPrefix(f, argumentPrefix)]
+			}
+			record[name] = v
+		} else {
+			record[name] = source[f]
+		}
+	}
+	return record
+}
@@ -0,0 +1,86 @@
+package eventexport
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMapper(t *testing.T) {
+	userID := int32(2)
+	event := &types.Event{
+		ID:              1,
+		Name:            "SearchSubmitted",
+		URL:             "https://sourcegraph.example.com/search?q=secret",
+		UserID:          &userID,
+		AnonymousUserID: "abc",
+		Argument:        `{"query": "secret", "mode": "plain"}`,
+		Source:          "WEB",
+		Version:         "3.16.0",
+		Timestamp:       time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := map[string]struct {
+		config *schema.EventLogsExportSink
+		want   map[string]interface{}
+	}{
+		"default fields": {
+			config: &schema.EventLogsExportSink{},
+			want: map[string]interface{}{
+				"id":                int32(1),
+				"name":              "SearchSubmitted",
+				"url":               "https://sourcegraph.example.com/search?q=secret",
+				"user_id":           &userID,
+				"anonymous_user_id": "abc",
+				"source":            "WEB",
+				"argument":          map[string]interface{}{"query": "secret", "mode": "plain"},
+				"version":           "3.16.0",
+				"timestamp":         "2020-05-01T12:00:00Z",
+			},
+		},
+		"mapped fields": {
+			config: &schema.EventLogsExportSink{
+				Fields: map[string]string{"event": "name", "time": "timestamp", "mode": "argument.mode", "missing": "argument.missing"},
+			},
+			want: map[string]interface{}{
+				"event":   "SearchSubmitted",
+				"time":    "2020-05-01T12:00:00Z",
+				"mode":    "plain",
+				"missing": nil,
+			},
+		},
+		"redacted fields": {
+			config: &schema.EventLogsExportSink{
+				Fields: map[string]string{"url": "url", "anonymous_user_id": "anonymous_user_id", "argument": "argument", "query": "argument.query"},
+				Redact: []string{"url", "anonymous_user_id", "argument.query"},
+			},
+			want: map[string]interface{}{
+				"url":               redacted,
+				"anonymous_user_id": redacted,
+				"argument":          map[string]interface{}{"query": redacted, "mode": "plain"},
+				"query":             redacted,
+			},
+		},
+		"redacted argument": {
+			config: &schema.EventLogsExportSink{
+				Fields: map[string]string{"argument": "argument", "mode": "argument.mode"},
+				Redact: []string{"argument"},
+			},
+			want: map[string]interface{}{
+				"argument": redacted,
+				"mode":     nil,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := newMapper(test.config).record(event)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package eventexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// A sink receives batches of exported events. Each record is a JSON object.
+type sink interface {
+	// send delivers the records. If it returns an error, the batch is sent again later, so the
+	// sink may receive some records more than once.
+	send(ctx context.Context, records [][]byte) error
+}
+
+// newSink returns the sink for the configuration.
+func newSink(c *schema.EventLogsExportSink) (sink, error) {
+	switch c.Type {
+	case "http":
+		if c.Url == "" {
+			return nil, fmt.Errorf("event export sink %q: url is required", c.Name)
+		}
+		return &httpSink{url: c.Url, headers: c.Headers}, nil
+	case "kafka":
+		if c.Url == "" || c.Topic == "" {
+			return nil, fmt.Errorf("event export sink %q: url and topic are required", c.Name)
+		}
+		return &kafkaSink{url: strings.TrimSuffix(c.Url, "/") + "/topics/" + c.Topic, headers: c.Headers}, nil
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("event export sink %q: path is required", c.Name)
+		}
+		return newFileSink(c), nil
+	default:
+		return nil, fmt.Errorf("event export sink %q: unknown type %q", c.Name, c.Type)
+	}
+}
+
+// httpClient is the HTTP client used by sinks.
+var httpClient = &http.Client{Timeout: time.Minute}
+
+// httpSink POSTs each batch as newline-delimited JSON to a URL.
+type httpSink struct {
+	url     string
+	headers map[string]string
+}
+
+func (s *httpSink) send(ctx context.Context, records [][]byte) error {
+	var body bytes.Buffer
+	for _, r := range records {
+		body.Write(r)
+		body.WriteByte('\n')
+	}
+	return post(ctx, s.url, "application/x-ndjson", s.headers, &body)
+}
+
+// kafkaSink produces each event as a message to a Kafka topic using the Kafka REST Proxy API
+// (https://docs.confluent.io/current/kafka-rest/api.html), or any other service that implements
+// that API.
+type kafkaSink struct {
+	url     string // the topic URL
+	headers map[string]string
+}
+
+func (s *kafkaSink) send(ctx context.Context, records [][]byte) error {
+	type record struct {
+		Value json.RawMessage `json:"value"`
+	}
+	req := struct {
+		Records []record `json:"records"`
+	}{Records: make([]record, len(records))}
+	for i, r := range records {
+		req.Records[i].Value = r
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.url, "application/vnd.kafka.json.v2+json", s.headers, bytes.NewReader(body))
+}
+
+func post(ctx context.Context, url, contentType string, headers map[string]string, body io.Reader) error {
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected HTTP status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
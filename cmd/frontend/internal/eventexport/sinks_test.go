@@ -0,0 +1,85 @@
+package eventexport
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestHTTPSinks(t *testing.T) {
+	var (
+		gotPath, gotContentType, gotAuth, gotBody string
+		status                                    = http.StatusOK
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotPath, gotContentType, gotAuth, gotBody = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization"), string(body)
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	records := [][]byte{[]byte(`{"name":"a"}`), []byte(`{"name":"b"}`)}
+	headers := map[string]string{"Authorization": "Bearer t"}
+	ctx := context.Background()
+
+	t.Run("http", func(t *testing.T) {
+		s, err := newSink(&schema.EventLogsExportSink{Name: "s", Type: "http", Url: ts.URL + "/events", Headers: headers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.send(ctx, records); err != nil {
+			t.Fatal(err)
+		}
+		if want := "{\"name\":\"a\"}\n{\"name\":\"b\"}\n"; gotBody != want {
+			t.Errorf("got body %q, want %q", gotBody, want)
+		}
+		if gotPath != "/events" || gotContentType != "application/x-ndjson" || gotAuth != "Bearer t" {
+			t.Errorf("got path %q, content type %q, authorization %q", gotPath, gotContentType, gotAuth)
+		}
+	})
+
+	t.Run("kafka", func(t *testing.T) {
+		s, err := newSink(&schema.EventLogsExportSink{Name: "s", Type: "kafka", Url: ts.URL + "/", Topic: "events", Headers: headers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.send(ctx, records); err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"records":[{"value":{"name":"a"}},{"value":{"name":"b"}}]}`; gotBody != want {
+			t.Errorf("got body %q, want %q", gotBody, want)
+		}
+		if gotPath != "/topics/events" || gotContentType != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("got path %q, content type %q", gotPath, gotContentType)
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		status = http.StatusServiceUnavailable
+		defer func() { status = http.StatusOK }()
+		s, err := newSink(&schema.EventLogsExportSink{Name: "s", Type: "http", Url: ts.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.send(ctx, records); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+}
+
+func TestNewSink_invalid(t *testing.T) {
+	for _, c := range []*schema.EventLogsExportSink{
+		{Name: "s", Type: "http"},
+		{Name: "s", Type: "kafka", Url: "https://example.com"},
+		{Name: "s", Type: "file"},
+		{Name: "s", Type: "other"},
+	} {
+		if _, err := newSink(c); err == nil {
+			t.Errorf("%+v: got nil error, want non-nil", c)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 )
@@ -35,8 +36,10 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			token, sudoUser, err = authz.ParseAuthorizationHeader(headerValue)
 			if err != nil {
 				if authz.IsUnrecognizedScheme(err) {
-					// Ignore Authorization headers that we don't handle.
-					log15.Warn("Ignoring unrecognized Authorization header.", "err", err, "value", headerValue)
+					// Ignore Authorization headers that we don't handle (such as the "Bearer"
+					// tokens that the SCIM API authenticates itself). Don't log the header value,
+					// which may contain credentials.
+					log15.Debug("Ignoring unrecognized Authorization header.", "err", err)
 					next.ServeHTTP(w, r)
 					return
 				}
@@ -61,18 +64,19 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			//
 			// 🚨 SECURITY: It's important we check for the correct scopes to know what this token
 			// is allowed to do.
-			var requiredScope string
+			var requiredScopes []string
 			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
+				requiredScopes = authz.UserScopes
 			} else {
-				requiredScope = authz.ScopeSiteAdminSudo
+				requiredScopes = []string{authz.ScopeSiteAdminSudo}
 			}
-			subjectUserID, err := db.AccessTokens.Lookup(r.Context(), token, requiredScope)
+			accessToken, err := db.AccessTokens.Lookup(r.Context(), token, requiredScopes)
 			if err != nil {
 				log15.Error("Invalid access token.", "token", token, "err", err)
 				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
 				return
 			}
+			subjectUserID := accessToken.SubjectUserID
 
 			// Determine the actor's user ID.
 			var actorUserID int32
@@ -105,7 +109,14 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			// 🚨 SECURITY: Record the token's scopes and repository restrictions so that the
+			// GraphQL resolvers and API handlers can enforce them.
+			tokenScopes := &authz.TokenScopes{Scopes: accessToken.Scopes}
+			for _, repo := range accessToken.Repos {
+				tokenScopes.Repos = append(tokenScopes.Repos, api.RepoName(repo))
+			}
+			ctx := authz.WithTokenScopes(r.Context(), tokenScopes)
+			r = r.WithContext(actor.WithActor(ctx, &actor.Actor{UID: actorUserID}))
 		}
 
 		next.ServeHTTP(w, r)
@@ -7,8 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
 
+	"github.com/gorilla/mux"
+
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
@@ -69,9 +72,9 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token badbad")
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 			calledAccessTokensLookup = true
-			return 0, errors.New("x")
+			return nil, errors.New("x")
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
@@ -85,15 +88,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			req, _ := http.NewRequest("GET", "/", nil)
 			req.Header.Set("Authorization", headerValue)
 			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
+				if want := authz.UserScopes; !reflect.DeepEqual(requiredScopes, want) {
+					t.Errorf("got %q, want %q", requiredScopes, want)
 				}
-				return 123, nil
+				return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -109,15 +112,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req.Header.Set("Authorization", "token abcdef")
 		req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeUserAll; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := authz.UserScopes; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -143,15 +146,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			}
 			req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
+				if want := authz.UserScopes; !reflect.DeepEqual(requiredScopes, want) {
+					t.Errorf("got %q, want %q", requiredScopes, want)
 				}
-				return 123, nil
+				return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -165,15 +168,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -210,15 +213,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -242,15 +245,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="doesntexist"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return &db.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -281,3 +284,50 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		}
 	})
 }
+
+// 🚨 SECURITY: This tests that the scopes and repository restrictions of an access token are
+// enforced by the routes that declare the scopes they accept.
+func TestAccessTokenAuthMiddleware_scopes(t *testing.T) {
+	db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
+		return &db.AccessToken{
+			SubjectUserID: 123,
+			Scopes:        []string{authz.ScopeSearchRead},
+			Repos:         []string{"github.com/foo/bar"},
+		}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	tests := []struct {
+		name           string
+		declared       []string
+		wantStatusCode int
+	}{
+		{name: "route accepting search:read", declared: []string{authz.ScopeRepoRead, authz.ScopeSearchRead}, wantStatusCode: http.StatusOK},
+		{name: "route accepting repo:read", declared: []string{authz.ScopeRepoRead}, wantStatusCode: http.StatusForbidden},
+		{name: "route declaring no scopes", declared: nil, wantStatusCode: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := mux.NewRouter()
+			m.Path("/").Name("r").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tokenScopes := authz.TokenScopesFromContext(r.Context())
+				if !tokenScopes.AllowsRepo("github.com/foo/bar") {
+					t.Error("want access token to allow github.com/foo/bar")
+				}
+				if tokenScopes.AllowsRepo("github.com/foo/baz") {
+					t.Error("want access token to not allow github.com/foo/baz")
+				}
+			}))
+			m.Use(authz.RouteScopesMiddleware(map[string][]string{"r": test.declared}))
+			handler := AccessTokenAuthMiddleware(m)
+
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "token abcdef")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != test.wantStatusCode {
+				t.Errorf("got response status %d, want %d", rr.Code, test.wantStatusCode)
+			}
+		})
+	}
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/graph-gophers/graphql-go"
 	"github.com/inconshreveable/log15"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/httpapi"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/pkg/updatecheck"
 	apirouter "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/httpapi/router"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/httpapi/scim"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/handlerutil"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 	"github.com/sourcegraph/sourcegraph/internal/env"
@@ -43,6 +45,13 @@ func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketS
 		WriteErrBody: env.InsecureDev,
 	})
 
+	// 🚨 SECURITY: Access tokens without the "user:all" scope may only use the routes that accept
+	// their scopes. The GraphQL API checks the scopes of each query and mutation.
+	m.Use(authz.RouteScopesMiddleware(map[string][]string{
+		apirouter.GraphQL:     authz.UserScopes,
+		apirouter.RepoRefresh: {authz.ScopeRepoRead},
+	}))
+
 	// Set handlers for the installed routes.
 	m.Get(apirouter.RepoShield).Handler(trace.TraceRoute(handler(serveRepoShield)))
 
@@ -76,6 +85,12 @@ func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketS
 	m.Get(apirouter.SrcCliDownload).Handler(trace.TraceRoute(handler(srcCliDownloadServe)))
 
 	m.Get(apirouter.Registry).Handler(trace.TraceRoute(handler(registry.HandleRegistry)))
+	m.Get(apirouter.RegistryMirrorExport).Handler(trace.TraceRoute(handler(registry.HandleRegistryMirrorExport)))
+	m.Get(apirouter.RegistryMirrorImport).Handler(trace.TraceRoute(handler(registry.HandleRegistryMirrorImport)))
+
+	// The SCIM API authenticates requests itself, with access tokens with the "site-admin:scim"
+	// scope (which no other route accepts).
+	m.Get(apirouter.SCIM).Handler(trace.TraceRoute(scim.NewHandler()))
 
 	m.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("API no route: %s %s from %s", r.Method, r.URL, r.Referer())
@@ -13,7 +13,11 @@ const (
 	SrcCliVersion  = "src-cli.version"
 	SrcCliDownload = "src-cli.download"
 
-	Registry = "registry"
+	Registry             = "registry"
+	RegistryMirrorExport = "registry.mirror.export"
+	RegistryMirrorImport = "registry.mirror.import"
+
+	SCIM = "scim"
 
 	RepoShield  = "repo.shield"
 	RepoRefresh = "repo.refresh"
@@ -60,6 +64,8 @@ func New(base *mux.Router) *mux.Router {
 
 	base.StrictSlash(true)
 
+	base.Path("/registry/mirror").Methods("GET").Name(RegistryMirrorExport)
+	base.Path("/registry/mirror").Methods("POST").Name(RegistryMirrorImport)
 	addRegistryRoute(base)
 	addGraphQLRoute(base)
 	base.Path("/github-webhooks").Methods("POST").Name(GitHubWebhooks)
@@ -67,6 +73,7 @@ func New(base *mux.Router) *mux.Router {
 	base.Path("/lsif/upload").Methods("POST").Name(LSIFUpload)
 	base.Path("/src-cli/version").Methods("GET").Name(SrcCliVersion)
 	base.Path("/src-cli/{rest:.*}").Methods("GET").Name(SrcCliDownload)
+	base.PathPrefix("/scim/v2/").Name(SCIM)
 
 	// repo contains routes that are NOT specific to a revision. In these routes, the URL may not contain a revspec after the repo (that is, no "github.com/foo/bar@myrevspec").
 	repoPath := `/repos/` + routevar.Repo
@@ -0,0 +1,379 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// scimGroup is a SCIM group resource (RFC 7643 section 4.2), which maps onto a Sourcegraph
+// organization.
+type scimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+	Meta        *meta        `json:"meta,omitempty"`
+}
+
+// scimMember is a member of a SCIM group. Its value is the ID of a SCIM user.
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// toSCIMGroup returns the SCIM representation of a Sourcegraph organization.
+func toSCIMGroup(ctx context.Context, org *types.Org) (*scimGroup, error) {
+	memberships, err := db.OrgMembers.GetByOrgID(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	id := formatID(org.ID)
+	g := &scimGroup{
+		Schemas:     []string{schemaGroup},
+		ID:          id,
+		DisplayName: org.Name,
+		Members:     make([]scimMember, 0, len(memberships)),
+		Meta:        newMeta("Group", id, org.CreatedAt, org.UpdatedAt),
+	}
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		g.DisplayName = *org.DisplayName
+	}
+	for _, m := range memberships {
+		userID := formatID(m.UserID)
+		g.Members = append(g.Members, scimMember{Value: userID, Ref: resourceLocation("Users", userID)})
+	}
+	return g, nil
+}
+
+func serveListGroups(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	params, err := parseListParams(r)
+	if err != nil {
+		return err
+	}
+
+	var orgs []*types.Org
+	var total int
+	if f := params.filter; f != nil {
+		// Filters match at most one organization.
+		var org *types.Org
+		switch f.attribute {
+		case "id":
+			id, err := parseID(f.value)
+			if err == nil {
+				org, err = db.Orgs.GetByID(ctx, id)
+			}
+			if err != nil && err != errNotFound && !errcode.IsNotFound(err) {
+				return err
+			}
+		case "displayname":
+			// Organizations created by the SCIM API are named after the group's display name.
+			if name, err := auth.NormalizeUsername(f.value); err == nil {
+				org, err = db.Orgs.GetByName(ctx, name)
+				if err != nil && !errcode.IsNotFound(err) {
+					return err
+				}
+			}
+		default:
+			return badRequest("invalidFilter", "unsupported filter attribute %q (supported: id, displayName)", f.attribute)
+		}
+		if org != nil {
+			total = 1
+			if params.startIndex == 1 && params.count > 0 {
+				orgs = []*types.Org{org}
+			}
+		}
+	} else {
+		opt := &db.OrgsListOptions{LimitOffset: &db.LimitOffset{Limit: params.count, Offset: params.startIndex - 1}}
+		if orgs, err = db.Orgs.List(ctx, opt); err != nil {
+			return err
+		}
+		if total, err = db.Orgs.Count(ctx, db.OrgsListOptions{}); err != nil {
+			return err
+		}
+	}
+
+	resources := make([]*scimGroup, 0, len(orgs))
+	for _, org := range orgs {
+		g, err := toSCIMGroup(ctx, org)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, g)
+	}
+	return writeJSON(w, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func serveGetGroup(w http.ResponseWriter, r *http.Request, idStr string) error {
+	org, err := getOrg(r.Context(), idStr)
+	if err != nil {
+		return err
+	}
+	g, err := toSCIMGroup(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, g)
+}
+
+func getOrg(ctx context.Context, idStr string) (*types.Org, error) {
+	id, err := parseID(idStr)
+	if err != nil {
+		return nil, err
+	}
+	return db.Orgs.GetByID(ctx, id)
+}
+
+func serveCreateGroup(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var g scimGroup
+	if err := readJSON(r, &g); err != nil {
+		return err
+	}
+	name, err := auth.NormalizeUsername(g.DisplayName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid displayName: %s", err)
+	}
+	memberIDs, err := parseMemberIDs(ctx, g.Members)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Orgs.GetByName(ctx, name); err == nil {
+		return conflict("group with displayName %q already exists", g.DisplayName)
+	} else if !errcode.IsNotFound(err) {
+		return err
+	}
+
+	org, err := db.Orgs.Create(ctx, name, &g.DisplayName)
+	if err != nil {
+		return err
+	}
+	if err := setOrgMembers(ctx, org.ID, memberIDs); err != nil {
+		return err
+	}
+
+	created, err := toSCIMGroup(ctx, org)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", created.Meta.Location)
+	return writeJSON(w, http.StatusCreated, created)
+}
+
+func serveReplaceGroup(w http.ResponseWriter, r *http.Request, idStr string) error {
+	ctx := r.Context()
+	org, err := getOrg(ctx, idStr)
+	if err != nil {
+		return err
+	}
+	var g scimGroup
+	if err := readJSON(r, &g); err != nil {
+		return err
+	}
+	return updateGroup(ctx, w, org, &g)
+}
+
+func servePatchGroup(w http.ResponseWriter, r *http.Request, idStr string) error {
+	ctx := r.Context()
+	org, err := getOrg(ctx, idStr)
+	if err != nil {
+		return err
+	}
+	var patch patchRequest
+	if err := readJSON(r, &patch); err != nil {
+		return err
+	}
+	g, err := toSCIMGroup(ctx, org)
+	if err != nil {
+		return err
+	}
+	for _, op := range patch.Operations {
+		if err := applyGroupPatchOperation(g, op); err != nil {
+			return err
+		}
+	}
+	return updateGroup(ctx, w, org, g)
+}
+
+// applyGroupPatchOperation applies a PATCH operation to g. Operations on unsupported attributes
+// are ignored.
+func applyGroupPatchOperation(g *scimGroup, op patchOperation) error {
+	unmarshalMembers := func(value json.RawMessage) ([]scimMember, error) {
+		var members []scimMember
+		if err := json.Unmarshal(value, &members); err != nil {
+			return nil, badRequest("invalidValue", "invalid value for members: %s", err)
+		}
+		return members, nil
+	}
+
+	path := strings.ToLower(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		replace := strings.EqualFold(op.Op, "replace")
+		attrs := map[string]json.RawMessage{path: op.Value}
+		if path == "" {
+			// The value is an object with the attributes to set.
+			attrs = nil
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return badRequest("invalidValue", "invalid value for operation without path: %s", err)
+			}
+		}
+		for attr, value := range attrs {
+			switch strings.ToLower(attr) {
+			case "displayname":
+				if err := json.Unmarshal(value, &g.DisplayName); err != nil {
+					return badRequest("invalidValue", "invalid value for displayName: %s", err)
+				}
+			case "members":
+				members, err := unmarshalMembers(value)
+				if err != nil {
+					return err
+				}
+				if replace {
+					g.Members = members
+				} else {
+					g.Members = append(g.Members, members...)
+				}
+			}
+		}
+		return nil
+
+	case "remove":
+		switch {
+		case path == "members" && len(op.Value) == 0:
+			g.Members = nil
+		case path == "members":
+			// Some identity providers (e.g., Azure AD) send the members to remove as the value.
+			members, err := unmarshalMembers(op.Value)
+			if err != nil {
+				return err
+			}
+			for _, m := range members {
+				g.Members = removeMember(g.Members, m.Value)
+			}
+		case strings.HasPrefix(path, "members["):
+			f, err := parseFilter(strings.TrimSuffix(op.Path[len("members["):], "]"))
+			if err != nil {
+				return err
+			}
+			if f.attribute != "value" {
+				return badRequest("invalidPath", "unsupported path %q", op.Path)
+			}
+			g.Members = removeMember(g.Members, f.value)
+		}
+		return nil
+	}
+	return badRequest("invalidSyntax", "unsupported operation %q", op.Op)
+}
+
+func removeMember(members []scimMember, value string) []scimMember {
+	var kept []scimMember
+	for _, m := range members {
+		if m.Value != value {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// updateGroup updates the Sourcegraph organization to match the desired SCIM group g and writes
+// the updated SCIM group to the response.
+func updateGroup(ctx context.Context, w http.ResponseWriter, org *types.Org, g *scimGroup) error {
+	memberIDs, err := parseMemberIDs(ctx, g.Members)
+	if err != nil {
+		return err
+	}
+	if g.DisplayName != "" && (org.DisplayName == nil || g.DisplayName != *org.DisplayName) {
+		// The organization's name can't be changed, so only its display name is updated.
+		if org, err = db.Orgs.Update(ctx, org.ID, &g.DisplayName); err != nil {
+			return err
+		}
+	}
+	if err := setOrgMembers(ctx, org.ID, memberIDs); err != nil {
+		return err
+	}
+
+	updated, err := toSCIMGroup(ctx, org)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, updated)
+}
+
+// parseMemberIDs returns the user IDs of the members. It returns an error if a member is not an
+// existing user.
+func parseMemberIDs(ctx context.Context, members []scimMember) ([]int32, error) {
+	seen := map[int32]bool{}
+	var ids []int32
+	for _, m := range members {
+		id, err := parseID(m.Value)
+		if err == nil {
+			_, err = db.Users.GetByID(ctx, id)
+		}
+		if err == errNotFound || errcode.IsNotFound(err) {
+			return nil, badRequest("invalidValue", "member %q is not an existing user", m.Value)
+		} else if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// setOrgMembers sets the organization's members to exactly the given users.
+func setOrgMembers(ctx context.Context, orgID int32, userIDs []int32) error {
+	memberships, err := db.OrgMembers.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	want := map[int32]bool{}
+	for _, id := range userIDs {
+		want[id] = true
+	}
+	have := map[int32]bool{}
+	for _, m := range memberships {
+		have[m.UserID] = true
+		if !want[m.UserID] {
+			if err := db.OrgMembers.Remove(ctx, orgID, m.UserID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range userIDs {
+		if !have[id] {
+			if _, err := db.OrgMembers.Create(ctx, orgID, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func serveDeleteGroup(w http.ResponseWriter, r *http.Request, idStr string) error {
+	id, err := parseID(idStr)
+	if err != nil {
+		return err
+	}
+	if err := db.Orgs.Delete(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,387 @@
+// Package scim implements a SCIM 2.0 server (RFC 7643 and RFC 7644) that lets an identity provider
+// provision Sourcegraph users and organizations.
+//
+// SCIM users map onto Sourcegraph users (and their email addresses). Deactivating a SCIM user
+// deactivates the Sourcegraph user, and deleting it deletes the Sourcegraph user. SCIM groups map
+// onto Sourcegraph organizations, and their members onto the organizations' members.
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	contentType = "application/scim+json"
+
+	// pathPrefix is the path of the SCIM API, relative to the HTTP API (/.api).
+	pathPrefix = "/scim/v2"
+
+	// defaultCount and maxCount are the default and maximum number of resources returned in a
+	// list response.
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+// NewHandler returns a handler that serves the SCIM API at /.api/scim/v2.
+//
+// The handler authenticates requests itself: it only serves requests with an "Authorization:
+// Bearer" header with an access token with the "site-admin:scim" scope whose subject is a site
+// admin. Other credentials of the request (such as session cookies) are ignored.
+func NewHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := authenticate(r)
+		if err == nil {
+			err = serve(w, r.WithContext(ctx))
+		}
+		if err != nil {
+			writeError(w, err)
+		}
+	})
+}
+
+// authenticate returns the context of the request authenticated as the subject of the access
+// token in its "Authorization: Bearer" header (RFC 6750).
+//
+// 🚨 SECURITY: Only site admins may use the SCIM API, with a dedicated access token.
+func authenticate(r *http.Request) (context.Context, error) {
+	errUnauthorized := &scimError{status: http.StatusUnauthorized, detail: "must authenticate with an access token with the site-admin:scim scope (Authorization: Bearer TOKEN)"}
+	scheme, token := "", ""
+	if parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2); len(parts) == 2 {
+		scheme, token = parts[0], strings.TrimSpace(parts[1])
+	}
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, errUnauthorized
+	}
+	if allow := conf.AccessTokensAllow(); allow != conf.AccessTokensAll && allow != conf.AccessTokensAdmin {
+		return nil, &scimError{status: http.StatusUnauthorized, detail: "access token authorization is disabled"}
+	}
+
+	// The token is only accepted if it has the "site-admin:scim" scope.
+	accessToken, err := db.AccessTokens.Lookup(r.Context(), token, []string{authz.ScopeSiteAdminSCIM})
+	if err != nil {
+		if err != db.ErrAccessTokenNotFound {
+			log15.Error("Failed to look up SCIM access token.", "err", err)
+		}
+		return nil, errUnauthorized
+	}
+	ctx := authz.WithTokenScopes(r.Context(), &authz.TokenScopes{Scopes: accessToken.Scopes})
+	ctx = actor.WithActor(ctx, &actor.Actor{UID: accessToken.SubjectUserID})
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeSiteAdminSCIM); err != nil {
+		return nil, &scimError{status: http.StatusForbidden, detail: err.Error()}
+	}
+	return ctx, nil
+}
+
+func serve(w http.ResponseWriter, r *http.Request) error {
+	i := strings.Index(r.URL.Path, pathPrefix+"/")
+	if i == -1 {
+		return errNotFound
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path[i+len(pathPrefix):], "/"), "/")
+	resource, id := parts[0], ""
+	if len(parts) == 2 {
+		id = parts[1]
+	} else if len(parts) > 2 {
+		return errNotFound
+	}
+
+	switch resource {
+	case "ServiceProviderConfig":
+		if r.Method != "GET" || id != "" {
+			return errMethodNotAllowed
+		}
+		return writeJSON(w, http.StatusOK, serviceProviderConfig())
+
+	case "Users":
+		switch {
+		case id == "" && r.Method == "GET":
+			return serveListUsers(w, r)
+		case id == "" && r.Method == "POST":
+			return serveCreateUser(w, r)
+		case id != "" && r.Method == "GET":
+			return serveGetUser(w, r, id)
+		case id != "" && r.Method == "PUT":
+			return serveReplaceUser(w, r, id)
+		case id != "" && r.Method == "PATCH":
+			return servePatchUser(w, r, id)
+		case id != "" && r.Method == "DELETE":
+			return serveDeleteUser(w, r, id)
+		}
+		return errMethodNotAllowed
+
+	case "Groups":
+		switch {
+		case id == "" && r.Method == "GET":
+			return serveListGroups(w, r)
+		case id == "" && r.Method == "POST":
+			return serveCreateGroup(w, r)
+		case id != "" && r.Method == "GET":
+			return serveGetGroup(w, r, id)
+		case id != "" && r.Method == "PUT":
+			return serveReplaceGroup(w, r, id)
+		case id != "" && r.Method == "PATCH":
+			return servePatchGroup(w, r, id)
+		case id != "" && r.Method == "DELETE":
+			return serveDeleteGroup(w, r, id)
+		}
+		return errMethodNotAllowed
+	}
+	return errNotFound
+}
+
+// scimError is an error that is returned to the client as a SCIM error response (RFC 7644 section
+// 3.12).
+type scimError struct {
+	status   int
+	scimType string // e.g. "uniqueness" or "invalidFilter"
+	detail   string
+}
+
+func (e *scimError) Error() string { return e.detail }
+
+var (
+	errNotFound         = &scimError{status: http.StatusNotFound, detail: "resource not found"}
+	errMethodNotAllowed = &scimError{status: http.StatusMethodNotAllowed, detail: "method not allowed"}
+)
+
+func badRequest(scimType, format string, args ...interface{}) error {
+	return &scimError{status: http.StatusBadRequest, scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) error {
+	return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*scimError)
+	if !ok {
+		if errcode.IsNotFound(err) {
+			e = errNotFound
+		} else {
+			log15.Error("SCIM API error.", "err", err)
+			// Don't reveal internal errors (which may contain sensitive information) to the client.
+			e = &scimError{status: http.StatusInternalServerError, detail: "internal error"}
+		}
+	}
+	_ = writeJSON(w, e.status, struct {
+		Schemas  []string `json:"schemas"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+		Status   string   `json:"status"`
+	}{
+		Schemas:  []string{schemaError},
+		ScimType: e.scimType,
+		Detail:   e.detail,
+		Status:   strconv.Itoa(e.status),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest("invalidSyntax", "invalid JSON request body: %s", err)
+	}
+	return nil
+}
+
+// meta is the metadata of a SCIM resource.
+type meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location"`
+}
+
+func newMeta(resourceType, id string, created, lastModified time.Time) *meta {
+	return &meta{
+		ResourceType: resourceType,
+		Created:      &created,
+		LastModified: &lastModified,
+		Location:     resourceLocation(resourceType+"s", id),
+	}
+}
+
+// resourceLocation returns the URL of the resource with the given type (e.g. "Users") and ID.
+func resourceLocation(resourceType, id string) string {
+	return globals.ExternalURL().ResolveReference(&url.URL{Path: "/.api" + pathPrefix + "/" + resourceType + "/" + id}).String()
+}
+
+// listResponse is a SCIM list response (RFC 7644 section 3.4.2).
+type listResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// listParams are the parameters of a SCIM list request.
+type listParams struct {
+	filter     *filter
+	startIndex int // 1-based
+	count      int
+}
+
+func parseListParams(r *http.Request) (*listParams, error) {
+	q := r.URL.Query()
+	p := &listParams{startIndex: 1, count: defaultCount}
+	if s := q.Get("startIndex"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, badRequest("invalidValue", "invalid startIndex %q", s)
+		}
+		if n > 1 {
+			p.startIndex = n
+		}
+	}
+	if s := q.Get("count"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, badRequest("invalidValue", "invalid count %q", s)
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n > maxCount {
+			n = maxCount
+		}
+		p.count = n
+	}
+	if s := q.Get("filter"); s != "" {
+		f, err := parseFilter(s)
+		if err != nil {
+			return nil, err
+		}
+		p.filter = f
+	}
+	return p, nil
+}
+
+// filter is a SCIM filter. Only filters of the form `attribute eq "value"` are supported, which
+// is what identity providers use to look up existing resources before provisioning them.
+type filter struct {
+	attribute string // lowercase
+	value     string
+}
+
+func parseFilter(s string) (*filter, error) {
+	fields := strings.SplitN(strings.TrimSpace(s), " ", 3)
+	if len(fields) != 3 || !strings.EqualFold(fields[1], "eq") {
+		return nil, badRequest("invalidFilter", "unsupported filter %q (only filters of the form 'attribute eq \"value\"' are supported)", s)
+	}
+	value := strings.TrimSpace(fields[2])
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	} else if value != "true" && value != "false" {
+		return nil, badRequest("invalidFilter", "invalid filter value %s", value)
+	}
+	return &filter{attribute: strings.ToLower(fields[0]), value: value}, nil
+}
+
+// parseID parses the ID of a SCIM resource, which is the ID of the corresponding user or
+// organization.
+func parseID(id string) (int32, error) {
+	n, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return 0, errNotFound
+	}
+	return int32(n), nil
+}
+
+func formatID(id int32) string { return strconv.FormatInt(int64(id), 10) }
+
+// patchRequest is a SCIM PATCH request (RFC 7644 section 3.5.2).
+type patchRequest struct {
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parseBool parses a boolean patch value. Some identity providers send booleans as strings (e.g.
+// "False").
+func parseBool(v json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(v, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, badRequest("invalidValue", "invalid boolean value %s", v)
+}
+
+func serviceProviderConfig() interface{} {
+	type supported struct {
+		Supported  bool `json:"supported"`
+		MaxResults int  `json:"maxResults,omitempty"`
+	}
+	return struct {
+		Schemas               []string          `json:"schemas"`
+		Patch                 supported         `json:"patch"`
+		Bulk                  supported         `json:"bulk"`
+		Filter                supported         `json:"filter"`
+		ChangePassword        supported         `json:"changePassword"`
+		Sort                  supported         `json:"sort"`
+		ETag                  supported         `json:"etag"`
+		AuthenticationSchemes []json.RawMessage `json:"authenticationSchemes"`
+	}{
+		Schemas:        []string{schemaServiceProviderConfig},
+		Patch:          supported{Supported: true},
+		Filter:         supported{Supported: true, MaxResults: maxCount},
+		ChangePassword: supported{},
+		AuthenticationSchemes: []json.RawMessage{
+			json.RawMessage(`{"type":"oauthbearertoken","name":"Access token","description":"A Sourcegraph access token with the site-admin:scim scope, sent in the Authorization header as 'Bearer <token>'"}`),
+		},
+	}
+}
+
+// currentUserID returns the ID of the user that the access token belongs to.
+func currentUserID(ctx context.Context) (int32, error) {
+	user, err := backend.CurrentUser(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, backend.ErrNotAuthenticated
+	}
+	return user.ID, nil
+}
@@ -0,0 +1,549 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// serveTestRequest serves a SCIM request with an "Authorization: Bearer" header with an access
+// token of user 1 with the given scopes (or without an Authorization header, if scopes is nil).
+func serveTestRequest(t *testing.T, method, path, body string, scopes []string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, "/.api/scim/v2"+path, strings.NewReader(body))
+	if scopes != nil {
+		req.Header.Set("Authorization", "Bearer "+strings.Join(scopes, ","))
+	}
+	rec := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+var scimScopes = []string{authz.ScopeSiteAdminSCIM}
+
+func resetMocks(siteAdmin bool) {
+	db.Mocks = db.MockStores{}
+	// The test access tokens are their comma-separated scopes.
+	db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (*db.AccessToken, error) {
+		scopes := strings.Split(tokenHexEncoded, ",")
+		for _, scope := range scopes {
+			for _, required := range requiredScopes {
+				if scope == required {
+					return &db.AccessToken{SubjectUserID: 1, Scopes: scopes}, nil
+				}
+			}
+		}
+		return nil, db.ErrAccessTokenNotFound
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		if a := actor.FromContext(ctx); a.UID != 1 {
+			return nil, db.NewUserNotFoundError(a.UID)
+		}
+		return &types.User{ID: 1, SiteAdmin: siteAdmin}, nil
+	}
+	mockTransaction = func(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+}
+
+func resetAllMocks() {
+	db.Mocks = db.MockStores{}
+	mockTransaction = nil
+}
+
+// 🚨 SECURITY: This tests that only site admins with an access token with the "site-admin:scim"
+// scope can use the SCIM API.
+func TestServe_Authorization(t *testing.T) {
+	tests := map[string]struct {
+		siteAdmin  bool
+		scopes     []string
+		wantStatus int
+	}{
+		"no access token":            {siteAdmin: true, wantStatus: http.StatusUnauthorized},
+		"user:all scope":             {siteAdmin: true, scopes: []string{authz.ScopeUserAll}, wantStatus: http.StatusUnauthorized},
+		"not a site admin":           {siteAdmin: false, scopes: scimScopes, wantStatus: http.StatusForbidden},
+		"site admin with SCIM scope": {siteAdmin: true, scopes: scimScopes, wantStatus: http.StatusOK},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resetMocks(test.siteAdmin)
+			defer resetAllMocks()
+
+			rec := serveTestRequest(t, "GET", "/ServiceProviderConfig", "", test.scopes)
+			if rec.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d (body: %s)", rec.Code, test.wantStatus, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != contentType {
+				t.Errorf("got Content-Type %q, want %q", ct, contentType)
+			}
+		})
+	}
+}
+
+func TestServe_Users(t *testing.T) {
+	resetMocks(true)
+	defer resetAllMocks()
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := map[int32]*types.User{}
+	emails := map[int32][]string{}
+	db.Mocks.Users.Create = func(_ context.Context, info db.NewUser) (*types.User, error) {
+		if !info.EmailIsVerified || info.Password != "" {
+			t.Errorf("got unexpected new user %+v", info)
+		}
+		user := &types.User{ID: 2, Username: info.Username, DisplayName: info.DisplayName, CreatedAt: created, UpdatedAt: created}
+		users[user.ID] = user
+		emails[user.ID] = []string{info.Email}
+		return user, nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		if user, ok := users[id]; ok {
+			return user, nil
+		}
+		return nil, db.NewUserNotFoundError(id)
+	}
+	db.Mocks.Users.GetByUsername = func(_ context.Context, username string) (*types.User, error) {
+		for _, user := range users {
+			if user.Username == username {
+				return user, nil
+			}
+		}
+		return nil, db.NewUserNotFoundError(0)
+	}
+	db.Mocks.Users.GetByVerifiedEmail = func(_ context.Context, email string) (*types.User, error) {
+		for id, l := range emails {
+			for _, e := range l {
+				if e == email {
+					return users[id], nil
+				}
+			}
+		}
+		return nil, db.NewUserNotFoundError(0)
+	}
+	db.Mocks.Users.Update = func(id int32, update db.UserUpdate) error {
+		if update.Username != "" {
+			users[id].Username = update.Username
+		}
+		if update.DisplayName != nil {
+			users[id].DisplayName = *update.DisplayName
+		}
+		return nil
+	}
+	db.Mocks.Users.SetDeactivated = func(id int32, deactivated bool) error {
+		users[id].Deactivated = deactivated
+		return nil
+	}
+	var deleted []int32
+	db.Mocks.Users.Delete = func(_ context.Context, id int32) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+	db.Mocks.UserEmails.ListByUser = func(_ context.Context, opt db.UserEmailsListOptions) ([]*db.UserEmail, error) {
+		var l []*db.UserEmail
+		for _, e := range emails[opt.UserID] {
+			l = append(l, &db.UserEmail{UserID: opt.UserID, Email: e})
+		}
+		return l, nil
+	}
+	db.Mocks.UserEmails.Add = func(_ context.Context, userID int32, email string, _ *string) error {
+		emails[userID] = append(emails[userID], email)
+		return nil
+	}
+	db.Mocks.UserEmails.SetVerified = func(_ context.Context, _ int32, _ string, verified bool) error {
+		if !verified {
+			t.Error("got unverified email")
+		}
+		return nil
+	}
+	db.Mocks.UserEmails.Remove = func(_ context.Context, userID int32, email string) error {
+		var l []string
+		for _, e := range emails[userID] {
+			if e != email {
+				l = append(l, e)
+			}
+		}
+		emails[userID] = l
+		return nil
+	}
+	granted := 0
+	db.Mocks.Authz.GrantPendingPermissions = func(context.Context, *db.GrantPendingPermissionsArgs) error {
+		granted++
+		return nil
+	}
+
+	t.Run("create", func(t *testing.T) {
+		rec := serveTestRequest(t, "POST", "/Users", `{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+			"userName": "alice@example.com",
+			"name": {"formatted": "Alice Smith"},
+			"emails": [{"value": "alice@example.org"}, {"value": "alice@example.com", "primary": true}]
+		}`, scimScopes)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusCreated, rec.Body)
+		}
+		var got scimUser
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		want := scimUser{
+			Schemas:     []string{schemaUser},
+			ID:          "2",
+			UserName:    "alice",
+			Name:        &scimName{Formatted: "Alice Smith"},
+			DisplayName: "Alice Smith",
+			Active:      got.Active,
+			Emails: []scimEmail{
+				{Value: "alice@example.com", Type: "work", Primary: true},
+				{Value: "alice@example.org", Type: "work"},
+			},
+			Meta: got.Meta,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got user %+v, want %+v", got, want)
+		}
+		if got.Active == nil || !*got.Active {
+			t.Error("got inactive user, want active")
+		}
+		if loc := "http://example.com/.api/scim/v2/Users/2"; got.Meta == nil || got.Meta.Location != loc || rec.Header().Get("Location") != loc {
+			t.Errorf("got location %q, want %q", rec.Header().Get("Location"), loc)
+		}
+		if granted != 1 {
+			t.Errorf("got %d calls to GrantPendingPermissions, want 1", granted)
+		}
+	})
+
+	t.Run("create conflict", func(t *testing.T) {
+		rec := serveTestRequest(t, "POST", "/Users", `{"userName": "bob", "emails": [{"value": "alice@example.org"}]}`, scimScopes)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d (body: %s)", rec.Code, http.StatusConflict, rec.Body)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		rec := serveTestRequest(t, "GET", `/Users?filter=userName+eq+"alice@example.com"`, "", scimScopes)
+		var got listResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalResults != 1 {
+			t.Errorf("got %d results, want 1 (body: %s)", got.TotalResults, rec.Body)
+		}
+
+		rec = serveTestRequest(t, "GET", `/Users?filter=title+eq+"x"`, "", scimScopes)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got status %d for unsupported filter, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("patch emails and display name", func(t *testing.T) {
+		rec := serveTestRequest(t, "PATCH", "/Users/2", `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "alice@example.net"},
+				{"op": "remove", "path": "emails[value eq \"alice@example.org\"]"},
+				{"op": "replace", "value": {"displayName": "Alice Jones", "title": "ignored"}}
+			]
+		}`, scimScopes)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body)
+		}
+		if want := []string{"alice@example.net"}; !reflect.DeepEqual(emails[2], want) {
+			t.Errorf("got emails %q, want %q", emails[2], want)
+		}
+		if users[2].DisplayName != "Alice Jones" {
+			t.Errorf("got display name %q, want %q", users[2].DisplayName, "Alice Jones")
+		}
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		rec := serveTestRequest(t, "PATCH", "/Users/2", `{"Operations": [{"op": "replace", "path": "active", "value": "False"}]}`, scimScopes)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body)
+		}
+		if !users[2].Deactivated {
+			t.Error("got active user, want deactivated")
+		}
+		if len(deleted) != 0 {
+			t.Errorf("got deleted users %v, want none", deleted)
+		}
+		var got scimUser
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Active == nil || *got.Active {
+			t.Error("got active SCIM user, want inactive")
+		}
+
+		rec = serveTestRequest(t, "PATCH", "/Users/2", `{"Operations": [{"op": "replace", "path": "active", "value": true}]}`, scimScopes)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body)
+		}
+		if users[2].Deactivated {
+			t.Error("got deactivated user, want reactivated")
+		}
+	})
+
+	t.Run("deactivate token owner", func(t *testing.T) {
+		users[1] = &types.User{ID: 1, SiteAdmin: true}
+		rec := serveTestRequest(t, "PATCH", "/Users/1", `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`, scimScopes)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d (body: %s)", rec.Code, http.StatusBadRequest, rec.Body)
+		}
+		if users[1].Deactivated {
+			t.Error("got deactivated token owner")
+		}
+	})
+
+	t.Run("delete token owner", func(t *testing.T) {
+		users[1] = &types.User{ID: 1, SiteAdmin: true}
+		rec := serveTestRequest(t, "DELETE", "/Users/1", "", scimScopes)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d (body: %s)", rec.Code, http.StatusBadRequest, rec.Body)
+		}
+	})
+}
+
+func TestSetUserEmails(t *testing.T) {
+	tests := map[string]struct {
+		current, desired, want []string
+	}{
+		"unchanged":           {current: []string{"a", "b"}, desired: []string{"a", "b"}, want: []string{"a", "b"}},
+		"add":                 {current: []string{"a"}, desired: []string{"a", "b"}, want: []string{"a", "b"}},
+		"remove":              {current: []string{"a", "b"}, desired: []string{"b"}, want: []string{"b"}},
+		"change primary":      {current: []string{"a", "b", "c"}, desired: []string{"b", "a", "c"}, want: []string{"b", "c", "a"}},
+		"new primary":         {current: []string{"a", "b"}, desired: []string{"c", "b"}, want: []string{"c", "b"}},
+		"remove all":          {current: []string{"a"}, desired: nil, want: nil},
+		"case insensitive":    {current: []string{"A"}, desired: []string{"a"}, want: []string{"A"}},
+		"new primary and old": {current: []string{"a"}, desired: []string{"c", "a"}, want: []string{"c", "a"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db.Mocks = db.MockStores{}
+			transactions := 0
+			mockTransaction = func(ctx context.Context, fn func(ctx context.Context) error) error {
+				transactions++
+				return fn(ctx)
+			}
+			defer resetAllMocks()
+
+			emails := append([]string{}, test.current...)
+			db.Mocks.UserEmails.ListByUser = func(context.Context, db.UserEmailsListOptions) ([]*db.UserEmail, error) {
+				var l []*db.UserEmail
+				for _, e := range emails {
+					l = append(l, &db.UserEmail{Email: e})
+				}
+				return l, nil
+			}
+			db.Mocks.UserEmails.Add = func(_ context.Context, _ int32, email string, _ *string) error {
+				emails = append(emails, email)
+				return nil
+			}
+			db.Mocks.UserEmails.SetVerified = func(context.Context, int32, string, bool) error { return nil }
+			db.Mocks.UserEmails.Remove = func(_ context.Context, _ int32, email string) error {
+				var l []string
+				for _, e := range emails {
+					if e != email {
+						l = append(l, e)
+					}
+				}
+				emails = l
+				return nil
+			}
+			db.Mocks.Users.GetByVerifiedEmail = func(context.Context, string) (*types.User, error) {
+				return nil, db.NewUserNotFoundError(0)
+			}
+			db.Mocks.Authz.GrantPendingPermissions = func(context.Context, *db.GrantPendingPermissionsArgs) error { return nil }
+
+			if err := setUserEmails(context.Background(), 1, test.desired); err != nil {
+				t.Fatal(err)
+			}
+			if transactions != 1 {
+				t.Errorf("got %d transactions, want 1", transactions)
+			}
+			if len(emails) == 0 {
+				emails = nil
+			}
+			if !reflect.DeepEqual(emails, test.want) {
+				t.Errorf("got emails %q, want %q", emails, test.want)
+			}
+		})
+	}
+}
+
+func TestServe_Groups(t *testing.T) {
+	resetMocks(true)
+	defer resetAllMocks()
+
+	var org *types.Org
+	members := map[int32]bool{}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		if id == 1 || id == 2 || id == 3 {
+			return &types.User{ID: id}, nil
+		}
+		return nil, db.NewUserNotFoundError(id)
+	}
+	db.Mocks.Orgs.GetByName = func(_ context.Context, name string) (*types.Org, error) {
+		if org != nil && org.Name == name {
+			return org, nil
+		}
+		return nil, &db.OrgNotFoundError{Message: name}
+	}
+	db.Mocks.Orgs.GetByID = func(_ context.Context, id int32) (*types.Org, error) {
+		if org != nil && org.ID == id {
+			return org, nil
+		}
+		return nil, &db.OrgNotFoundError{}
+	}
+	db.Mocks.Orgs.Create = func(_ context.Context, name string, displayName *string) (*types.Org, error) {
+		org = &types.Org{ID: 5, Name: name, DisplayName: displayName}
+		return org, nil
+	}
+	db.Mocks.Orgs.Update = func(_ context.Context, id int32, displayName *string) (*types.Org, error) {
+		org.DisplayName = displayName
+		return org, nil
+	}
+	db.Mocks.OrgMembers.GetByOrgID = func(context.Context, int32) ([]*types.OrgMembership, error) {
+		var l []*types.OrgMembership
+		for _, id := range []int32{1, 2, 3} {
+			if members[id] {
+				l = append(l, &types.OrgMembership{OrgID: org.ID, UserID: id})
+			}
+		}
+		return l, nil
+	}
+	db.Mocks.OrgMembers.Create = func(_ context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		members[userID] = true
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	db.Mocks.OrgMembers.Remove = func(_ context.Context, orgID, userID int32) error {
+		delete(members, userID)
+		return nil
+	}
+
+	wantMembers := func(t *testing.T, want map[int32]bool) {
+		t.Helper()
+		if !reflect.DeepEqual(members, want) {
+			t.Errorf("got members %v, want %v", members, want)
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		rec := serveTestRequest(t, "POST", "/Groups", `{"displayName": "Engineering Team", "members": [{"value": "2"}, {"value": "3"}]}`, scimScopes)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusCreated, rec.Body)
+		}
+		if org.Name != "Engineering-Team" || *org.DisplayName != "Engineering Team" {
+			t.Errorf("got org %+v", org)
+		}
+		wantMembers(t, map[int32]bool{2: true, 3: true})
+
+		rec = serveTestRequest(t, "POST", "/Groups", `{"displayName": "Engineering Team"}`, scimScopes)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		rec := serveTestRequest(t, "PATCH", "/Groups/5", `{"Operations": [{"op": "add", "path": "members", "value": [{"value": "9"}]}]}`, scimScopes)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d (body: %s)", rec.Code, http.StatusBadRequest, rec.Body)
+		}
+		wantMembers(t, map[int32]bool{2: true, 3: true})
+	})
+
+	t.Run("patch members", func(t *testing.T) {
+		rec := serveTestRequest(t, "PATCH", "/Groups/5", `{"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "1"}]},
+			{"op": "remove", "path": "members[value eq \"2\"]"},
+			{"op": "remove", "path": "members", "value": [{"value": "3"}]},
+			{"op": "replace", "path": "displayName", "value": "Eng"}
+		]}`, scimScopes)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body)
+		}
+		wantMembers(t, map[int32]bool{1: true})
+		if *org.DisplayName != "Eng" {
+			t.Errorf("got display name %q, want %q", *org.DisplayName, "Eng")
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		rec := serveTestRequest(t, "PUT", "/Groups/5", `{"displayName": "Eng", "members": [{"value": "2"}]}`, scimScopes)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body)
+		}
+		wantMembers(t, map[int32]bool{2: true})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rec := serveTestRequest(t, "GET", "/Groups/6", "", scimScopes)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want %d (body: %s)", rec.Code, http.StatusNotFound, rec.Body)
+		}
+	})
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := map[string]*filter{
+		`userName eq "alice"`:           {attribute: "username", value: "alice"},
+		`emails.value Eq "a@b.com"`:     {attribute: "emails.value", value: "a@b.com"},
+		`displayName eq "Eng \"Team\""`: {attribute: "displayname", value: `Eng "Team"`},
+		`active eq true`:                {attribute: "active", value: "true"},
+		`userName sw "a"`:               nil,
+		`userName eq alice`:             nil,
+		`userName`:                      nil,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := parseFilter(input)
+			if want == nil {
+				if err == nil {
+					t.Errorf("got filter %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got filter %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// 🚨 SECURITY: This tests that the SCIM API ignores credentials other than an "Authorization:
+// Bearer" header with a SCIM access token.
+func TestServe_OtherCredentials(t *testing.T) {
+	resetMocks(true)
+	defer resetAllMocks()
+
+	t.Run("token scheme", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/.api/scim/v2/ServiceProviderConfig", nil)
+		req.Header.Set("Authorization", "token "+authz.ScopeSiteAdminSCIM)
+		rec := httptest.NewRecorder()
+		NewHandler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("authenticated request context", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/.api/scim/v2/ServiceProviderConfig", nil)
+		ctx := actor.WithActor(req.Context(), &actor.Actor{UID: 1})
+		ctx = authz.WithTokenScopes(ctx, &authz.TokenScopes{Scopes: scimScopes})
+		rec := httptest.NewRecorder()
+		NewHandler().ServeHTTP(rec, req.WithContext(ctx))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+		}
+	})
+}
@@ -0,0 +1,605 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// scimUser is a SCIM user resource (RFC 7643 section 4.1). Only the attributes that Sourcegraph
+// stores are supported; other attributes are ignored.
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *scimName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Meta        *meta       `json:"meta,omitempty"`
+}
+
+type scimName struct {
+	Formatted string `json:"formatted,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// displayName returns the user's display name, which is taken from the displayName attribute or,
+// if not set, the name.formatted attribute.
+func (u *scimUser) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		return u.Name.Formatted
+	}
+	return ""
+}
+
+func (u *scimUser) active() bool { return u.Active == nil || *u.Active }
+
+// emails returns the user's email addresses with the primary email address first.
+func (u *scimUser) emails() []string {
+	var emails []string
+	seen := map[string]bool{}
+	add := func(email string) {
+		email = strings.TrimSpace(email)
+		if email != "" && !seen[strings.ToLower(email)] {
+			seen[strings.ToLower(email)] = true
+			emails = append(emails, email)
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Primary {
+			add(e.Value)
+		}
+	}
+	for _, e := range u.Emails {
+		add(e.Value)
+	}
+	return emails
+}
+
+// toSCIMUser returns the SCIM representation of a Sourcegraph user.
+func toSCIMUser(ctx context.Context, user *types.User) (*scimUser, error) {
+	emails, err := db.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: user.ID, OnlyVerified: true})
+	if err != nil {
+		return nil, err
+	}
+	id := formatID(user.ID)
+	active := !user.Deactivated
+	u := &scimUser{
+		Schemas:     []string{schemaUser},
+		ID:          id,
+		UserName:    user.Username,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta:        newMeta("User", id, user.CreatedAt, user.UpdatedAt),
+	}
+	if user.DisplayName != "" {
+		u.Name = &scimName{Formatted: user.DisplayName}
+	}
+	// The primary email address is the oldest verified email address (see
+	// (*db.userEmails).GetPrimaryEmail), and emails are listed oldest first.
+	for i, e := range emails {
+		u.Emails = append(u.Emails, scimEmail{Value: e.Email, Type: "work", Primary: i == 0})
+	}
+	return u, nil
+}
+
+func serveListUsers(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	params, err := parseListParams(r)
+	if err != nil {
+		return err
+	}
+
+	var users []*types.User
+	var total int
+	if f := params.filter; f != nil {
+		// Filters match at most one user.
+		var user *types.User
+		switch f.attribute {
+		case "id":
+			id, err := parseID(f.value)
+			if err == nil {
+				user, err = db.Users.GetByID(ctx, id)
+			}
+			if err != nil && err != errNotFound && !errcode.IsNotFound(err) {
+				return err
+			}
+		case "username":
+			// Users created by the SCIM API have the normalized userName as their username.
+			if username, err := auth.NormalizeUsername(f.value); err == nil {
+				user, err = db.Users.GetByUsername(ctx, username)
+				if err != nil && !errcode.IsNotFound(err) {
+					return err
+				}
+			}
+		case "emails", "emails.value":
+			user, err = db.Users.GetByVerifiedEmail(ctx, f.value)
+			if err != nil && !errcode.IsNotFound(err) {
+				return err
+			}
+		default:
+			return badRequest("invalidFilter", "unsupported filter attribute %q (supported: id, userName, emails.value)", f.attribute)
+		}
+		if user != nil {
+			total = 1
+			if params.startIndex == 1 && params.count > 0 {
+				users = []*types.User{user}
+			}
+		}
+	} else {
+		opt := &db.UsersListOptions{LimitOffset: &db.LimitOffset{Limit: params.count, Offset: params.startIndex - 1}}
+		if users, err = db.Users.List(ctx, opt); err != nil {
+			return err
+		}
+		if total, err = db.Users.Count(ctx, &db.UsersListOptions{}); err != nil {
+			return err
+		}
+	}
+
+	resources := make([]*scimUser, 0, len(users))
+	for _, user := range users {
+		u, err := toSCIMUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, u)
+	}
+	return writeJSON(w, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func serveGetUser(w http.ResponseWriter, r *http.Request, idStr string) error {
+	user, err := getUser(r.Context(), idStr)
+	if err != nil {
+		return err
+	}
+	u, err := toSCIMUser(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, u)
+}
+
+func getUser(ctx context.Context, idStr string) (*types.User, error) {
+	id, err := parseID(idStr)
+	if err != nil {
+		return nil, err
+	}
+	return db.Users.GetByID(ctx, id)
+}
+
+func serveCreateUser(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var u scimUser
+	if err := readJSON(r, &u); err != nil {
+		return err
+	}
+	if !u.active() {
+		return badRequest("invalidValue", "creating inactive users is not supported")
+	}
+	username, err := auth.NormalizeUsername(u.UserName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid userName: %s", err)
+	}
+	emails := u.emails()
+	for _, email := range emails {
+		if err := checkEmailAvailable(ctx, 0, email); err != nil {
+			return err
+		}
+	}
+
+	// 🚨 SECURITY: The identity provider is trusted to have verified the user's email addresses.
+	newUser := db.NewUser{
+		Username:        username,
+		DisplayName:     u.displayName(),
+		EmailIsVerified: true,
+	}
+	if len(emails) > 0 {
+		newUser.Email = emails[0]
+	}
+	user, err := db.Users.Create(ctx, newUser)
+	if err != nil {
+		if db.IsUsernameExists(err) {
+			return conflict("user with userName %q already exists", username)
+		}
+		if db.IsEmailExists(err) {
+			return conflict("user with email %q already exists", newUser.Email)
+		}
+		return err
+	}
+	if len(emails) > 1 {
+		if err := addVerifiedEmails(ctx, user.ID, emails[1:]); err != nil {
+			return err
+		}
+	}
+	grantPendingPermissions(ctx, user.ID)
+
+	created, err := toSCIMUser(ctx, user)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", created.Meta.Location)
+	return writeJSON(w, http.StatusCreated, created)
+}
+
+func serveReplaceUser(w http.ResponseWriter, r *http.Request, idStr string) error {
+	ctx := r.Context()
+	user, err := getUser(ctx, idStr)
+	if err != nil {
+		return err
+	}
+	var u scimUser
+	if err := readJSON(r, &u); err != nil {
+		return err
+	}
+	return updateUser(ctx, w, user, &u)
+}
+
+func servePatchUser(w http.ResponseWriter, r *http.Request, idStr string) error {
+	ctx := r.Context()
+	user, err := getUser(ctx, idStr)
+	if err != nil {
+		return err
+	}
+	var patch patchRequest
+	if err := readJSON(r, &patch); err != nil {
+		return err
+	}
+	u, err := toSCIMUser(ctx, user)
+	if err != nil {
+		return err
+	}
+	for _, op := range patch.Operations {
+		if err := applyUserPatchOperation(u, op); err != nil {
+			return err
+		}
+	}
+	return updateUser(ctx, w, user, u)
+}
+
+// applyUserPatchOperation applies a PATCH operation to u. Operations on unsupported attributes
+// are ignored.
+func applyUserPatchOperation(u *scimUser, op patchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		if op.Path == "" {
+			// The value is an object with the attributes to set.
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return badRequest("invalidValue", "invalid value for operation without path: %s", err)
+			}
+			for path, value := range attrs {
+				if err := setUserAttribute(u, strings.ToLower(op.Op), path, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return setUserAttribute(u, strings.ToLower(op.Op), op.Path, op.Value)
+
+	case "remove":
+		path := strings.ToLower(op.Path)
+		switch {
+		case path == "displayname":
+			u.DisplayName = ""
+		case path == "name.formatted" || path == "name":
+			u.Name = nil
+		case path == "emails":
+			u.Emails = nil
+		case strings.HasPrefix(path, "emails["):
+			f, err := parseFilter(strings.TrimSuffix(op.Path[len("emails["):], "]"))
+			if err != nil {
+				return err
+			}
+			if f.attribute != "value" {
+				return badRequest("invalidPath", "unsupported path %q", op.Path)
+			}
+			emails := u.Emails[:0]
+			for _, e := range u.Emails {
+				if !strings.EqualFold(e.Value, f.value) {
+					emails = append(emails, e)
+				}
+			}
+			u.Emails = emails
+		}
+		return nil
+	}
+	return badRequest("invalidSyntax", "unsupported operation %q", op.Op)
+}
+
+func setUserAttribute(u *scimUser, op, path string, value json.RawMessage) error {
+	unmarshal := func(v interface{}) error {
+		if err := json.Unmarshal(value, v); err != nil {
+			return badRequest("invalidValue", "invalid value for %q: %s", path, err)
+		}
+		return nil
+	}
+
+	switch lpath := strings.ToLower(path); {
+	case lpath == "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		u.Active = &active
+	case lpath == "username":
+		return unmarshal(&u.UserName)
+	case lpath == "displayname":
+		return unmarshal(&u.DisplayName)
+	case lpath == "name":
+		u.DisplayName = ""
+		return unmarshal(&u.Name)
+	case lpath == "name.formatted":
+		u.DisplayName = ""
+		u.Name = &scimName{}
+		return unmarshal(&u.Name.Formatted)
+	case lpath == "emails":
+		var emails []scimEmail
+		if err := unmarshal(&emails); err != nil {
+			return err
+		}
+		if op == "add" {
+			// Keep the current primary email address unless a new one is given.
+			for _, e := range emails {
+				if e.Primary {
+					for i := range u.Emails {
+						u.Emails[i].Primary = false
+					}
+				}
+			}
+			u.Emails = append(u.Emails, emails...)
+		} else {
+			u.Emails = emails
+		}
+	case strings.HasPrefix(lpath, "emails[") && strings.HasSuffix(lpath, "].value"):
+		// Identity providers (e.g., Azure AD) use paths like `emails[type eq "work"].value` to
+		// set the user's email address. Sourcegraph doesn't store email types, so this sets the
+		// primary email address.
+		var email string
+		if err := unmarshal(&email); err != nil {
+			return err
+		}
+		emails := []scimEmail{{Value: email, Primary: true}}
+		for _, e := range u.Emails {
+			if !e.Primary {
+				emails = append(emails, e)
+			}
+		}
+		u.Emails = emails
+	}
+	return nil
+}
+
+// updateUser updates the Sourcegraph user to match the desired SCIM user u and writes the updated
+// SCIM user to the response.
+func updateUser(ctx context.Context, w http.ResponseWriter, user *types.User, u *scimUser) error {
+	if deactivate := !u.active(); deactivate != user.Deactivated {
+		if deactivate {
+			currentUserID, err := currentUserID(ctx)
+			if err != nil {
+				return err
+			}
+			if user.ID == currentUserID {
+				return badRequest("mutability", "unable to deactivate the user that owns the access token used for provisioning")
+			}
+		}
+		// A deactivated user keeps its data and may be reactivated later, but can't sign in or use
+		// its access tokens (see (*db.users).SetDeactivated).
+		if err := db.Users.SetDeactivated(ctx, user.ID, deactivate); err != nil {
+			return err
+		}
+	}
+
+	var update db.UserUpdate
+	if u.UserName != "" && u.UserName != user.Username {
+		username, err := auth.NormalizeUsername(u.UserName)
+		if err != nil {
+			return badRequest("invalidValue", "invalid userName: %s", err)
+		}
+		if username != user.Username {
+			update.Username = username
+		}
+	}
+	if displayName := u.displayName(); displayName != user.DisplayName {
+		update.DisplayName = &displayName
+	}
+	if update.Username != "" || update.DisplayName != nil {
+		if err := db.Users.Update(ctx, user.ID, update); err != nil {
+			if db.IsUsernameExists(err) {
+				return conflict("user with userName %q already exists", update.Username)
+			}
+			return err
+		}
+	}
+
+	if err := setUserEmails(ctx, user.ID, u.emails()); err != nil {
+		return err
+	}
+
+	user, err := db.Users.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	updated, err := toSCIMUser(ctx, user)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, updated)
+}
+
+// setUserEmails sets the user's verified email addresses to emails, whose first element is the
+// primary email address. The email addresses are updated in a single transaction, so the user
+// never ends up with only some of them (or without a primary email address).
+func setUserEmails(ctx context.Context, userID int32, emails []string) error {
+	var added bool
+	err := transaction(ctx, func(ctx context.Context) (err error) {
+		added, err = setUserEmailsTx(ctx, userID, emails)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		// Pending permissions are granted to the user's committed email addresses.
+		grantPendingPermissions(ctx, userID)
+	}
+	return nil
+}
+
+// mockTransaction, if set, is called instead of dbconn.Transaction in tests.
+var mockTransaction func(ctx context.Context, fn func(ctx context.Context) error) error
+
+func transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if mockTransaction != nil {
+		return mockTransaction(ctx, fn)
+	}
+	return dbconn.Transaction(ctx, fn)
+}
+
+// setUserEmailsTx is the body of setUserEmails, run in a transaction. It reports whether any email
+// addresses were added.
+func setUserEmailsTx(ctx context.Context, userID int32, emails []string) (bool, error) {
+	current, err := db.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: userID, OnlyVerified: true})
+	if err != nil {
+		return false, err
+	}
+	want := map[string]bool{}
+	for _, email := range emails {
+		want[strings.ToLower(email)] = true
+	}
+	have := map[string]bool{}
+	var kept []string
+	for _, e := range current {
+		if want[strings.ToLower(e.Email)] {
+			have[strings.ToLower(e.Email)] = true
+			kept = append(kept, e.Email)
+			continue
+		}
+		if err := db.UserEmails.Remove(ctx, userID, e.Email); err != nil {
+			return false, err
+		}
+	}
+
+	var added []string
+	for _, email := range emails {
+		if have[strings.ToLower(email)] {
+			continue
+		}
+		if err := checkEmailAvailable(ctx, userID, email); err != nil {
+			return false, err
+		}
+		added = append(added, email)
+	}
+	if len(emails) > 0 {
+		// The primary email address is the oldest verified one, so re-add the email addresses that
+		// are older than the new primary email address after it.
+		var older []string
+		for _, email := range kept {
+			if strings.EqualFold(email, emails[0]) {
+				break
+			}
+			older = append(older, email)
+		}
+		for _, email := range older {
+			if err := db.UserEmails.Remove(ctx, userID, email); err != nil {
+				return false, err
+			}
+		}
+		added = append(added, older...)
+	}
+	if len(added) == 0 {
+		return false, nil
+	}
+	if err := addVerifiedEmails(ctx, userID, added); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkEmailAvailable returns a conflict error if the email address is a verified email address
+// of a user other than userID.
+func checkEmailAvailable(ctx context.Context, userID int32, email string) error {
+	other, err := db.Users.GetByVerifiedEmail(ctx, email)
+	if err != nil && !errcode.IsNotFound(err) {
+		return err
+	}
+	if other != nil && other.ID != userID {
+		return conflict("user with email %q already exists", email)
+	}
+	return nil
+}
+
+func addVerifiedEmails(ctx context.Context, userID int32, emails []string) error {
+	for _, email := range emails {
+		// 🚨 SECURITY: The identity provider is trusted to have verified the user's email
+		// addresses.
+		if err := db.UserEmails.Add(ctx, userID, email, nil); err != nil {
+			return err
+		}
+		if err := db.UserEmails.SetVerified(ctx, userID, email, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func grantPendingPermissions(ctx context.Context, userID int32) {
+	if err := db.Authz.GrantPendingPermissions(ctx, &db.GrantPendingPermissionsArgs{
+		UserID: userID,
+		Perm:   authz.Read,
+		Type:   authz.PermRepos,
+	}); err != nil {
+		log15.Error("Failed to grant user pending permissions", "userID", userID, "error", err)
+	}
+}
+
+func serveDeleteUser(w http.ResponseWriter, r *http.Request, idStr string) error {
+	id, err := parseID(idStr)
+	if err != nil {
+		return err
+	}
+	if err := deleteUser(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// deleteUser deletes a user that was deleted in the identity provider. It is a soft delete, so the
+// user's data is kept.
+func deleteUser(ctx context.Context, id int32) error {
+	currentUserID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if id == currentUserID {
+		return badRequest("mutability", "unable to delete the user that owns the access token used for provisioning")
+	}
+	if err := db.Users.Delete(ctx, id); err != nil {
+		return err
+	}
+	return nil
+}
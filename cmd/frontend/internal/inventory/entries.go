@@ -48,14 +48,14 @@ func (c *Context) entries(ctx context.Context, entries []os.FileInfo, buf []byte
 func (c *Context) tree(ctx context.Context, tree os.FileInfo, buf []byte) (inv Inventory, err error) {
 	// Get and set from the cache.
 	if c.CacheGet != nil {
-		if inv, ok := c.CacheGet(tree); ok {
+		if inv, ok := c.CacheGet(ctx, tree); ok {
 			return inv, nil // cache hit
 		}
 	}
 	if c.CacheSet != nil {
 		defer func() {
 			if err == nil {
-				c.CacheSet(tree, inv) // store in cache
+				c.CacheSet(ctx, tree, inv) // store in cache
 			}
 		}()
 	}
@@ -95,14 +95,14 @@ func (c *Context) tree(ctx context.Context, tree os.FileInfo, buf []byte) (inv I
 func (c *Context) file(ctx context.Context, file os.FileInfo, buf []byte) (inv Inventory, err error) {
 	// Get and set from the cache.
 	if c.CacheGet != nil {
-		if inv, ok := c.CacheGet(file); ok {
+		if inv, ok := c.CacheGet(ctx, file); ok {
 			return inv, nil // cache hit
 		}
 	}
 	if c.CacheSet != nil {
 		defer func() {
 			if err == nil {
-				c.CacheSet(file, inv) // store in cache
+				c.CacheSet(ctx, file, inv) // store in cache
 			}
 		}()
 	}
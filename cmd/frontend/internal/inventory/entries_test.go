@@ -49,11 +49,11 @@ func TestContext_Entries(t *testing.T) {
 			}
 			return ioutil.NopCloser(bytes.NewReader(data)), nil
 		},
-		CacheGet: func(e os.FileInfo) (Inventory, bool) {
+		CacheGet: func(_ context.Context, e os.FileInfo) (Inventory, bool) {
 			cacheGetCalls = append(cacheGetCalls, e.Name())
 			return Inventory{}, false
 		},
-		CacheSet: func(e os.FileInfo, inv Inventory) {
+		CacheSet: func(_ context.Context, e os.FileInfo, inv Inventory) {
 			if _, ok := cacheSetCalls[e.Name()]; ok {
 				t.Fatalf("already stored %q in cache", e.Name())
 			}
@@ -0,0 +1,82 @@
+package discussions
+
+import (
+	"bytes"
+
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+// RelocateLineRange returns the range in the new version of a file that
+// corresponds to r in the old version, given the hunks of the diff between
+// the two versions. unchanged is false if any line of r was changed or
+// removed, or if lines were inserted within r. In that case the returned
+// range is a best guess.
+func RelocateLineRange(hunks []*diff.Hunk, r LineRange) (relocated LineRange, unchanged bool) {
+	start, unchanged := relocateLine(hunks, r.StartLine)
+	if r.EndLine <= r.StartLine {
+		return LineRange{StartLine: start, EndLine: start}, unchanged
+	}
+
+	prev := start
+	for line := r.StartLine + 1; line < r.EndLine; line++ {
+		cur, kept := relocateLine(hunks, line)
+		if !kept || cur != prev+1 {
+			unchanged = false
+		}
+		prev = cur
+	}
+	end := prev + 1
+	if end < start {
+		end = start
+	}
+	return LineRange{StartLine: start, EndLine: end}, unchanged
+}
+
+// relocateLine returns the zero-based line in the new version of a file that
+// corresponds to the zero-based line in the old version. If the line was
+// changed or removed, it returns the line it was removed at and false.
+func relocateLine(hunks []*diff.Hunk, line int) (int, bool) {
+	delta := 0
+	for _, h := range hunks {
+		// Hunks are one-based. An empty side of a hunk starts after the
+		// line it names.
+		origStart, newStart := int(h.OrigStartLine)-1, int(h.NewStartLine)-1
+		if h.OrigLines == 0 {
+			origStart++
+		}
+		if h.NewLines == 0 {
+			newStart++
+		}
+		if line < origStart {
+			break
+		}
+		if line >= origStart+int(h.OrigLines) {
+			delta = (newStart + int(h.NewLines)) - (origStart + int(h.OrigLines))
+			continue
+		}
+
+		old, new := origStart, newStart
+		for _, l := range bytes.Split(h.Body, []byte("\n")) {
+			if len(l) == 0 {
+				continue
+			}
+			switch l[0] {
+			case ' ':
+				if old == line {
+					return new, true
+				}
+				old++
+				new++
+			case '-':
+				if old == line {
+					return new, false
+				}
+				old++
+			case '+':
+				new++
+			}
+		}
+		return new, false
+	}
+	return line + delta, true
+}
@@ -0,0 +1,67 @@
+package discussions
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+func TestRelocateLineRange(t *testing.T) {
+	// Old:        New:
+	// 0 a         0 new1
+	// 1 b         1 new2
+	// 2 c         2 a
+	// 3 d         3 b
+	// 4 e         4 c
+	// 5 f         5 D
+	// 6 g         6 e
+	// 7 h         7 g
+	//             8 h
+	//             9 i
+	const fileDiff = `--- a.txt
++++ a.txt
+@@ -0,0 +1,2 @@
++new1
++new2
+@@ -4 +6 @@
+-d
++D
+@@ -6 +7,0 @@
+-f
+@@ -8,0 +10 @@
++i
+`
+	fd, err := diff.ParseFileDiff([]byte(fileDiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		r             LineRange
+		want          LineRange
+		wantUnchanged bool
+	}{
+		{name: "moved by insertion", r: LineRange{StartLine: 0, EndLine: 2}, want: LineRange{StartLine: 2, EndLine: 4}, wantUnchanged: true},
+		{name: "changed line", r: LineRange{StartLine: 2, EndLine: 4}, want: LineRange{StartLine: 4, EndLine: 6}, wantUnchanged: false},
+		{name: "removed line", r: LineRange{StartLine: 5, EndLine: 6}, want: LineRange{StartLine: 7, EndLine: 8}, wantUnchanged: false},
+		{name: "after removal", r: LineRange{StartLine: 6, EndLine: 8}, want: LineRange{StartLine: 7, EndLine: 9}, wantUnchanged: true},
+		{name: "spans removal", r: LineRange{StartLine: 4, EndLine: 7}, want: LineRange{StartLine: 6, EndLine: 8}, wantUnchanged: false},
+		{name: "empty range", r: LineRange{StartLine: 1, EndLine: 1}, want: LineRange{StartLine: 3, EndLine: 3}, wantUnchanged: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, unchanged := RelocateLineRange(fd.Hunks, test.r)
+			if got != test.want || unchanged != test.wantUnchanged {
+				t.Errorf("got %+v (unchanged %v), want %+v (unchanged %v)", got, unchanged, test.want, test.wantUnchanged)
+			}
+		})
+	}
+
+	t.Run("no hunks", func(t *testing.T) {
+		r := LineRange{StartLine: 3, EndLine: 5}
+		if got, unchanged := RelocateLineRange(nil, r); got != r || !unchanged {
+			t.Errorf("got %+v (unchanged %v), want %+v", got, unchanged, r)
+		}
+	})
+}
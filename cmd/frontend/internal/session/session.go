@@ -12,6 +12,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/auth/twofactor"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/env"
@@ -56,6 +57,10 @@ type sessionInfo struct {
 	Actor        *actor.Actor  `json:"actor"`
 	LastActive   time.Time     `json:"lastActive"`
 	ExpiryPeriod time.Duration `json:"expiryPeriod"`
+
+	// TwoFactorPending is whether the user signed in with their password but has not yet completed
+	// two-factor authentication. Such sessions do not authenticate requests.
+	TwoFactorPending bool `json:"twoFactorPending,omitempty"`
 }
 
 // SetSessionStore sets the backing store used for storing sessions on the server. It should be called exactly once.
@@ -236,6 +241,36 @@ func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiry
 	return SetData(w, r, "actor", value)
 }
 
+// twoFactorPendingExpiryPeriod is how long a user has to complete two-factor authentication after
+// signing in with their password.
+const twoFactorPendingExpiryPeriod = 10 * time.Minute
+
+// SetActorPendingTwoFactor sets the actor in the session, but marks the session as pending
+// two-factor authentication. The session does not authenticate requests until SetActor is called
+// after the user completes two-factor authentication. If no session exists, a new session is
+// created.
+func SetActorPendingTwoFactor(w http.ResponseWriter, r *http.Request, actor *actor.Actor) error {
+	return SetData(w, r, "actor", &sessionInfo{
+		Actor:            actor,
+		ExpiryPeriod:     twoFactorPendingExpiryPeriod,
+		LastActive:       time.Now(),
+		TwoFactorPending: true,
+	})
+}
+
+// ActorPendingTwoFactor returns the actor of the session if the session is pending two-factor
+// authentication (see SetActorPendingTwoFactor) and has not expired. Otherwise it returns nil.
+func ActorPendingTwoFactor(r *http.Request) *actor.Actor {
+	var info *sessionInfo
+	if err := GetData(r, "actor", &info); err != nil || info == nil || !info.TwoFactorPending {
+		return nil
+	}
+	if info.LastActive.Add(info.ExpiryPeriod).Before(time.Now()) {
+		return nil
+	}
+	return info.Actor
+}
+
 func hasSessionCookie(r *http.Request) bool {
 	c, _ := r.Cookie(cookieName)
 	return c != nil
@@ -343,8 +378,14 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return actor.WithActor(r.Context(), &actor.Actor{})
 		}
 
+		// Sessions pending two-factor authentication don't authenticate requests.
+		if info.TwoFactorPending {
+			return r.Context()
+		}
+
 		// Check that user still exists.
-		if _, err := db.Users.GetByID(r.Context(), info.Actor.UID); err != nil {
+		user, err := db.Users.GetByID(r.Context(), info.Actor.UID)
+		if err != nil {
 			if errcode.IsNotFound(err) {
 				_ = deleteSession(w, r) // clear the bad value
 			} else {
@@ -355,6 +396,23 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return r.Context() // not authenticated
 		}
 
+		// 🚨 SECURITY: Deactivated users (see (*db.users).SetDeactivated) may not sign in.
+		if user.Deactivated {
+			_ = deleteSession(w, r)
+			return r.Context() // not authenticated
+		}
+
+		// 🚨 SECURITY: If two-factor authentication is required for the user (e.g., because it was
+		// required or the user became a site admin after the user signed in) but the user has no
+		// second factor, sign the user out, so that the user must enroll one to sign in again.
+		if required, err := twofactor.EnrollmentRequired(r.Context(), user); err != nil {
+			log15.Error("Error checking two-factor authentication for session.", "uid", user.ID, "error", err)
+			return r.Context() // not authenticated
+		} else if required {
+			_ = deleteSession(w, r)
+			return r.Context() // not authenticated
+		}
+
 		// Renew session
 		if time.Since(info.LastActive) > 5*time.Minute {
 			info.LastActive = time.Now()
@@ -13,7 +13,9 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestSetActorDeleteSession(t *testing.T) {
@@ -289,3 +291,124 @@ func TestRecoverFromInvalidCookieValue(t *testing.T) {
 		t.Errorf("got cookies %+v, want %+v", cookies, want)
 	}
 }
+
+func TestSetActorPendingTwoFactor(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	requestWithCookies := func(w *httptest.ResponseRecorder) *http.Request {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	if err := SetActorPendingTwoFactor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123}); err != nil {
+		t.Fatal(err)
+	}
+	req := requestWithCookies(w)
+
+	// The pending session does not authenticate requests.
+	if a := actor.FromContext(authenticateByCookie(req, httptest.NewRecorder())); a.IsAuthenticated() {
+		t.Errorf("pending session authenticated actor %+v", a)
+	}
+	if a := ActorPendingTwoFactor(req); a == nil || a.UID != 123 {
+		t.Fatalf("got pending actor %+v, want UID 123", a)
+	}
+
+	// Completing two-factor authentication authenticates the session.
+	w = httptest.NewRecorder()
+	if err := SetActor(w, req, &actor.Actor{UID: 123}, 0); err != nil {
+		t.Fatal(err)
+	}
+	req = requestWithCookies(w)
+	if a := actor.FromContext(authenticateByCookie(req, httptest.NewRecorder())); a.UID != 123 {
+		t.Errorf("got actor %+v, want UID 123", a)
+	}
+	if a := ActorPendingTwoFactor(req); a != nil {
+		t.Errorf("got pending actor %+v after SetActor, want nil", a)
+	}
+}
+
+// 🚨 SECURITY: This tests that deactivated users' sessions don't authenticate requests.
+func TestDeactivatedUserSession(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	deactivated := false
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Deactivated: deactivated}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	w := httptest.NewRecorder()
+	if err := SetActor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123}, 0); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	if a := actor.FromContext(authenticateByCookie(req, httptest.NewRecorder())); a.UID != 123 {
+		t.Errorf("got actor %+v, want UID 123", a)
+	}
+	deactivated = true
+	if a := actor.FromContext(authenticateByCookie(req, httptest.NewRecorder())); a.IsAuthenticated() {
+		t.Errorf("deactivated user's session authenticated actor %+v", a)
+	}
+}
+
+func TestTwoFactorEnrollmentRequiredSession(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthTwoFactor: &schema.AuthTwoFactor{RequireForSiteAdmins: true}}})
+	defer conf.Mock(nil)
+	siteAdmin := false
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, SiteAdmin: siteAdmin, BuiltinAuth: true}, nil
+	}
+	var totp *db.UserTOTP
+	db.Mocks.UserTwoFactor = db.MockUserTwoFactor{
+		GetTOTP:                 func(context.Context, int32) (*db.UserTOTP, error) { return totp, nil },
+		ListWebAuthnCredentials: func(context.Context, int32) ([]*db.UserWebAuthnCredential, error) { return nil, nil },
+		CountRecoveryCodes:      func(context.Context, int32) (int, error) { return 0, nil },
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	newRequest := func() *http.Request {
+		w := httptest.NewRecorder()
+		if err := SetActor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123}, 0); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	if a := actor.FromContext(authenticateByCookie(newRequest(), httptest.NewRecorder())); a.UID != 123 {
+		t.Errorf("got actor %+v, want UID 123", a)
+	}
+
+	// 🚨 SECURITY: A site admin who must but didn't enroll a second factor is signed out.
+	siteAdmin = true
+	if a := actor.FromContext(authenticateByCookie(newRequest(), httptest.NewRecorder())); a.IsAuthenticated() {
+		t.Errorf("session of site admin without a second factor authenticated actor %+v", a)
+	}
+
+	now := time.Now()
+	totp = &db.UserTOTP{EnabledAt: &now}
+	if a := actor.FromContext(authenticateByCookie(newRequest(), httptest.NewRecorder())); a.UID != 123 {
+		t.Errorf("got actor %+v, want UID 123", a)
+	}
+}
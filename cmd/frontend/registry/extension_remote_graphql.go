@@ -5,7 +5,9 @@ import (
 	"net/url"
 
 	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/ui/router"
 	"github.com/sourcegraph/sourcegraph/internal/registry"
 )
@@ -85,3 +87,123 @@ func (r *registryExtensionRemoteResolver) IsWorkInProgress() bool {
 func (r *registryExtensionRemoteResolver) ViewerCanAdminister(ctx context.Context) (bool, error) {
 	return false, nil // can't administer remote extensions
 }
+
+func (r *registryExtensionRemoteResolver) SigningPublicKey() *string { return nil }
+
+func (r *registryExtensionRemoteResolver) PinnedRelease(ctx context.Context) (graphqlbackend.RegistryExtensionRelease, error) {
+	pinnedID, err := r.pinnedReleaseID(ctx)
+	if pinnedID == nil || err != nil {
+		return nil, err
+	}
+	releases, err := r.listReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if release.ID == *pinnedID {
+			return &registryExtensionRemoteReleaseResolver{v: release, pinned: true}, nil
+		}
+	}
+	// The pinned release is older than the releases that the remote registry lists.
+	return &registryExtensionRemoteReleaseResolver{v: &registry.Release{ID: *pinnedID, PublishedAt: r.v.PublishedAt}, pinned: true}, nil
+}
+
+func (r *registryExtensionRemoteResolver) Releases(ctx context.Context, args *graphqlutil.ConnectionArgs) (graphqlbackend.RegistryExtensionReleaseConnection, error) {
+	releases, err := r.listReleases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pinnedID, err := r.pinnedReleaseID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connection := &registryExtensionRemoteReleaseConnectionResolver{releases: releases, first: args.First}
+	if pinnedID != nil {
+		connection.pinnedID = *pinnedID
+	}
+	return connection, nil
+}
+
+// pinnedReleaseID returns the ID of the release (on the remote registry) that the extension is
+// pinned to, or nil if it isn't pinned.
+func (r *registryExtensionRemoteResolver) pinnedReleaseID(ctx context.Context) (*int64, error) {
+	if GetRemoteExtensionPins == nil {
+		return nil, nil
+	}
+	pins, err := GetRemoteExtensionPins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := pins[r.v.ExtensionID]; ok {
+		return &id, nil
+	}
+	return nil, nil
+}
+
+var mockListRemoteRegistryReleases func(extensionID string) ([]*registry.Release, error)
+
+func (r *registryExtensionRemoteResolver) listReleases(ctx context.Context) ([]*registry.Release, error) {
+	if mockListRemoteRegistryReleases != nil {
+		return mockListRemoteRegistryReleases(r.v.ExtensionID)
+	}
+	registryURL, err := url.Parse(r.v.RegistryURL)
+	if err != nil {
+		return nil, err
+	}
+	return registry.ListReleases(ctx, registryURL, r.v.ExtensionID)
+}
+
+// registryExtensionRemoteReleaseResolver implements the GraphQL type RegistryExtensionRelease with
+// data from a remote registry. Its ID is the ID of the release on the remote registry.
+type registryExtensionRemoteReleaseResolver struct {
+	v      *registry.Release
+	pinned bool
+}
+
+func (r *registryExtensionRemoteReleaseResolver) ID() graphql.ID {
+	return relay.MarshalID("RegistryExtensionRelease", r.v.ID)
+}
+
+func (r *registryExtensionRemoteReleaseResolver) Version() *string { return r.v.Version }
+
+func (r *registryExtensionRemoteReleaseResolver) Creator(context.Context) (*graphqlbackend.UserResolver, error) {
+	return nil, nil // the users of the remote registry are not known
+}
+
+func (r *registryExtensionRemoteReleaseResolver) CreatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.v.PublishedAt}
+}
+
+func (r *registryExtensionRemoteReleaseResolver) BundleURL() (*string, error) { return nil, nil }
+
+func (r *registryExtensionRemoteReleaseResolver) Signed() bool { return r.v.Signed }
+
+func (r *registryExtensionRemoteReleaseResolver) Pinned() bool { return r.pinned }
+
+// registryExtensionRemoteReleaseConnectionResolver resolves a list of the releases of an extension
+// on a remote registry.
+type registryExtensionRemoteReleaseConnectionResolver struct {
+	releases []*registry.Release
+	pinnedID int64
+	first    *int32
+}
+
+func (r *registryExtensionRemoteReleaseConnectionResolver) Nodes(context.Context) ([]graphqlbackend.RegistryExtensionRelease, error) {
+	releases := r.releases
+	if r.first != nil && len(releases) > int(*r.first) {
+		releases = releases[:*r.first]
+	}
+	l := make([]graphqlbackend.RegistryExtensionRelease, len(releases))
+	for i, release := range releases {
+		l[i] = &registryExtensionRemoteReleaseResolver{v: release, pinned: release.ID == r.pinnedID}
+	}
+	return l, nil
+}
+
+func (r *registryExtensionRemoteReleaseConnectionResolver) TotalCount(context.Context) (int32, error) {
+	return int32(len(r.releases)), nil
+}
+
+func (r *registryExtensionRemoteReleaseConnectionResolver) PageInfo(context.Context) (*graphqlutil.PageInfo, error) {
+	return graphqlutil.HasNextPage(r.first != nil && len(r.releases) > int(*r.first)), nil
+}
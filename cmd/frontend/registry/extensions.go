@@ -97,6 +97,7 @@ var GetLocalExtensionByExtensionID func(ctx context.Context, extensionIDWithoutP
 // GetExtensionByExtensionID gets the extension with the given extension ID.
 //
 // It returns either a local or remote extension, depending on what the extension ID refers to.
+// Remote extensions that are pinned to a release are returned with that release.
 //
 // The format of an extension ID is [host/]publisher/name. If the host is omitted, the host defaults
 // to the remote registry specified in site configuration (usually sourcegraph.com). The host must
@@ -115,7 +116,7 @@ func GetExtensionByExtensionID(ctx context.Context, extensionID string) (local g
 		}
 	}
 
-	x, err := getRemoteRegistryExtension(ctx, "extensionID", extensionIDWithoutPrefix)
+	x, err := getPinnedRemoteRegistryExtension(ctx, "extensionID", extensionIDWithoutPrefix)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -204,8 +205,8 @@ var FilterRemoteExtensions = func(extensions []*registry.Extension) []*registry.
 	return extensions
 }
 
-// listRemoteRegistryExtensions lists the remote registry extensions and rewrites their fields to be
-// from the frame-of-reference of this site.
+// listRemoteRegistryExtensions lists the remote registry extensions (with the pinned release of
+// pinned extensions) and rewrites their fields to be from the frame-of-reference of this site.
 func listRemoteRegistryExtensions(ctx context.Context, query string) ([]*registry.Extension, error) {
 	registryURL, err := getRemoteRegistryURL()
 	if registryURL == nil || err != nil {
@@ -220,6 +221,9 @@ func listRemoteRegistryExtensions(ctx context.Context, query string) ([]*registr
 	for _, x := range xs {
 		x.RegistryURL = registryURL.String()
 	}
+	if err := pinRemoteRegistryExtensions(ctx, xs); err != nil {
+		return nil, err
+	}
 	return xs, nil
 }
 
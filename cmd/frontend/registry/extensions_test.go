@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -220,3 +221,44 @@ func strptrptr(s string) **string {
 	tmp := &s
 	return &tmp
 }
+
+func TestPinRemoteRegistryExtensions(t *testing.T) {
+	ctx := context.Background()
+	GetRemoteExtensionPins = func(context.Context) (map[string]int64, error) {
+		return map[string]int64{"a/b": 1, "a/c": 3}, nil
+	}
+	defer func() { GetRemoteExtensionPins = nil }()
+	mockGetRemoteRegistryRelease = func(extensionID string, releaseID int64) (*registry.Extension, error) {
+		if extensionID != "a/b" || releaseID != 1 {
+			t.Errorf("got release %d of %q, want release 1 of %q", releaseID, extensionID, "a/b")
+		}
+		return &registry.Extension{ExtensionID: extensionID, ReleaseID: releaseID}, nil
+	}
+	defer func() { mockGetRemoteRegistryRelease = nil }()
+
+	xs := []*registry.Extension{
+		{ExtensionID: "a/b", ReleaseID: 2},
+		{ExtensionID: "a/c", ReleaseID: 3}, // latest release is the pinned release
+		{ExtensionID: "a/d", ReleaseID: 4}, // not pinned
+	}
+	if err := pinRemoteRegistryExtensions(ctx, xs); err != nil {
+		t.Fatal(err)
+	}
+	want := []*registry.Extension{
+		{ExtensionID: "a/b", ReleaseID: 1},
+		{ExtensionID: "a/c", ReleaseID: 3},
+		{ExtensionID: "a/d", ReleaseID: 4},
+	}
+	if !reflect.DeepEqual(xs, want) {
+		t.Errorf("got %+v, want %+v", xs, want)
+	}
+
+	t.Run("pinned release not found", func(t *testing.T) {
+		mockGetRemoteRegistryRelease = func(extensionID string, releaseID int64) (*registry.Extension, error) {
+			return nil, errors.New("not found")
+		}
+		if err := pinRemoteRegistryExtensions(ctx, []*registry.Extension{{ExtensionID: "a/b", ReleaseID: 2}}); err == nil {
+			t.Fatal("got nil error, want error (not a fallback to the latest release)")
+		}
+	})
+}
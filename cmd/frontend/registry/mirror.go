@@ -0,0 +1,19 @@
+package registry
+
+import "net/http"
+
+// HandleRegistryMirrorExport is called to handle HTTP requests to export extensions to an archive for
+// mirroring into another (e.g., air-gapped) Sourcegraph site's extension registry. If there is no
+// local extension registry, it returns an HTTP error response.
+var HandleRegistryMirrorExport = func(w http.ResponseWriter, r *http.Request) error {
+	http.Error(w, "no local extension registry exists", http.StatusNotFound)
+	return nil
+}
+
+// HandleRegistryMirrorImport is called to handle HTTP requests to import extensions from an archive
+// created by HandleRegistryMirrorExport into the local extension registry. If there is no local
+// extension registry, it returns an HTTP error response.
+var HandleRegistryMirrorImport = func(w http.ResponseWriter, r *http.Request) error {
+	http.Error(w, "no local extension registry exists", http.StatusNotFound)
+	return nil
+}
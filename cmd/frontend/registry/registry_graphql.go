@@ -33,6 +33,9 @@ type extensionRegistryResolver struct {
 	UpdateExtensionFunc  func(context.Context, *graphqlbackend.ExtensionRegistryUpdateExtensionArgs) (graphqlbackend.ExtensionRegistryMutationResult, error)
 	PublishExtensionFunc func(context.Context, *graphqlbackend.ExtensionRegistryPublishExtensionArgs) (graphqlbackend.ExtensionRegistryMutationResult, error)
 	DeleteExtensionFunc  func(context.Context, *graphqlbackend.ExtensionRegistryDeleteExtensionArgs) (*graphqlbackend.EmptyResponse, error)
+
+	UpdateExtensionSigningPublicKeyFunc func(context.Context, *graphqlbackend.ExtensionRegistryUpdateExtensionSigningPublicKeyArgs) (graphqlbackend.ExtensionRegistryMutationResult, error)
+	PinExtensionReleaseFunc             func(context.Context, *graphqlbackend.ExtensionRegistryPinExtensionReleaseArgs) (graphqlbackend.ExtensionRegistryMutationResult, error)
 }
 
 var errNoLocalExtensionRegistry = errors.New("no local extension registry exists")
@@ -97,6 +100,20 @@ func (r *extensionRegistryResolver) DeleteExtension(ctx context.Context, args *g
 	return r.DeleteExtensionFunc(ctx, args)
 }
 
+func (r *extensionRegistryResolver) UpdateExtensionSigningPublicKey(ctx context.Context, args *graphqlbackend.ExtensionRegistryUpdateExtensionSigningPublicKeyArgs) (graphqlbackend.ExtensionRegistryMutationResult, error) {
+	if r.UpdateExtensionSigningPublicKeyFunc == nil {
+		return nil, errNoLocalExtensionRegistry
+	}
+	return r.UpdateExtensionSigningPublicKeyFunc(ctx, args)
+}
+
+func (r *extensionRegistryResolver) PinExtensionRelease(ctx context.Context, args *graphqlbackend.ExtensionRegistryPinExtensionReleaseArgs) (graphqlbackend.ExtensionRegistryMutationResult, error) {
+	if r.PinExtensionReleaseFunc == nil {
+		return nil, errNoLocalExtensionRegistry
+	}
+	return r.PinExtensionReleaseFunc(ctx, args)
+}
+
 func (*extensionRegistryResolver) LocalExtensionIDPrefix() *string {
 	return GetLocalRegistryExtensionIDPrefix()
 }
@@ -121,9 +138,16 @@ func (r *extensionRegistryResolver) FilterRemoteExtensions(ids []string) []strin
 }
 
 type ExtensionRegistryMutationResult struct {
-	ID int32 // this is only used for local extensions, so it's OK that this only accepts a local extension ID
+	ID int32 // the ID of the local extension
+
+	// RemoteExtensionID is the extension ID of the remote extension, for mutations of remote
+	// extensions (whose ID is 0).
+	RemoteExtensionID string
 }
 
 func (r *ExtensionRegistryMutationResult) Extension(ctx context.Context) (graphqlbackend.RegistryExtension, error) {
+	if r.ID == 0 && r.RemoteExtensionID != "" {
+		return getExtensionByExtensionID(ctx, r.RemoteExtensionID)
+	}
 	return RegistryExtensionByIDInt32(ctx, r.ID)
 }
@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/registry"
+)
+
+// GetRemoteExtensionPins returns the IDs of the releases (on the remote registry) that site admins
+// pinned remote extensions to, by extension ID. It is nil when there is no local extension
+// registry, in which case remote extensions can't be pinned.
+var GetRemoteExtensionPins func(ctx context.Context) (map[string]int64, error)
+
+var mockGetRemoteRegistryRelease func(extensionID string, releaseID int64) (*registry.Extension, error)
+
+// GetRemoteRegistryRelease gets the remote registry extension with the manifest of the given
+// release (instead of its latest release). If the remote registry reports that the release is not
+// found, the returned error implements errcode.NotFounder.
+func GetRemoteRegistryRelease(ctx context.Context, extensionID string, releaseID int64) (*registry.Extension, error) {
+	if mockGetRemoteRegistryRelease != nil {
+		return mockGetRemoteRegistryRelease(extensionID, releaseID)
+	}
+
+	registryURL, err := getRemoteRegistryURL()
+	if registryURL == nil || err != nil {
+		return nil, err
+	}
+	x, err := registry.GetRelease(ctx, registryURL, extensionID, releaseID)
+	if x != nil {
+		x.RegistryURL = registryURL.String()
+	}
+	return x, err
+}
+
+// pinRemoteRegistryExtensions replaces each remote extension that is pinned to a release with that
+// release of the extension. The remote registry is only queried for extensions whose latest
+// release isn't the pinned release.
+func pinRemoteRegistryExtensions(ctx context.Context, xs []*registry.Extension) error {
+	if GetRemoteExtensionPins == nil || len(xs) == 0 {
+		return nil
+	}
+	pins, err := GetRemoteExtensionPins(ctx)
+	if err != nil {
+		return err
+	}
+	for i, x := range xs {
+		releaseID, ok := pins[x.ExtensionID]
+		if !ok || x.ReleaseID == releaseID {
+			continue
+		}
+		// Don't fall back to the latest release if the pinned release is unavailable, because site
+		// admins may have pinned the extension to avoid the latest release.
+		pinned, err := GetRemoteRegistryRelease(ctx, x.ExtensionID, releaseID)
+		if err != nil {
+			return errors.Wrapf(err, "get release %d of pinned extension %q", releaseID, x.ExtensionID)
+		}
+		xs[i] = pinned
+	}
+	return nil
+}
+
+// getPinnedRemoteRegistryExtension is like getRemoteRegistryExtension, except that it returns the
+// pinned release of the extension if it is pinned to a release.
+func getPinnedRemoteRegistryExtension(ctx context.Context, field, value string) (*registry.Extension, error) {
+	x, err := getRemoteRegistryExtension(ctx, field, value)
+	if x == nil || err != nil {
+		return x, err
+	}
+	xs := []*registry.Extension{x}
+	if err := pinRemoteRegistryExtensions(ctx, xs); err != nil {
+		return nil, err
+	}
+	return xs[0], nil
+}
+
+// GetRemoteRegistryExtensionByUUID gets the extension with the given UUID from the remote registry,
+// with the manifest of its latest release (even if it is pinned to another release).
+func GetRemoteRegistryExtensionByUUID(ctx context.Context, uuid string) (*registry.Extension, error) {
+	return getRemoteRegistryExtension(ctx, "uuid", uuid)
+}
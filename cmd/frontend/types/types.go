@@ -85,6 +85,7 @@ type User struct {
 	SiteAdmin   bool
 	BuiltinAuth bool
 	Tags        []string
+	Deactivated bool // whether the user was deactivated by an identity provider and may not sign in
 }
 
 type Org struct {
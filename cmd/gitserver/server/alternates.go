@@ -0,0 +1,282 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// Commands comparing a repository with another repository, e.g. one of its
+// forks, need the objects of both. The objects of the other repository are
+// made available with GIT_ALTERNATE_OBJECT_DIRECTORIES. If the other
+// repository is cloned on a different gitserver, we keep a copy of its
+// $GIT_DIR below the temporary directory and replace it when it doesn't
+// contain a requested commit.
+//
+// Copies that weren't used for alternateTTL are removed by the janitor, as
+// are the least recently used copies while the copies take up more than
+// alternatesMaxBytes. Copies left over from a previous run are removed at
+// startup together with the rest of the temporary directory (see
+// SetupAndClearTmp).
+
+// alternateRemoveDelay is how long we keep an out of date copy of a
+// repository around after it was replaced, so that commands which are still
+// using it can finish.
+const alternateRemoveDelay = 10 * time.Minute
+
+var (
+	alternateTTL       = 24 * time.Hour
+	alternatesMaxBytes = int64(10 * 1024 * 1024 * 1024)
+)
+
+func init() {
+	ttl := env.Get("SRC_GITSERVER_ALTERNATES_TTL", "24h", "How long to keep an unused copy of a repository fetched from another gitserver for cross-repository comparisons.")
+	if d, err := time.ParseDuration(ttl); err != nil {
+		log15.Error("Invalid SRC_GITSERVER_ALTERNATES_TTL, using the default.", "value", ttl, "error", err)
+	} else {
+		alternateTTL = d
+	}
+	maxMB := env.Get("SRC_GITSERVER_ALTERNATES_MAX_SIZE_MB", "10240", "The maximum total size in MB of the copies of repositories fetched from other gitservers for cross-repository comparisons.")
+	if n, err := strconv.ParseInt(maxMB, 10, 64); err != nil {
+		log15.Error("Invalid SRC_GITSERVER_ALTERNATES_MAX_SIZE_MB, using the default.", "value", maxMB, "error", err)
+	} else {
+		alternatesMaxBytes = n * 1024 * 1024
+	}
+}
+
+var alternateFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "src",
+	Subsystem: "gitserver",
+	Name:      "alternate_fetches_total",
+	Help:      "Number of copies of repositories fetched from another gitserver to be used as alternates, by state (done or failed).",
+}, []string{"state"})
+
+var alternatesEvicted = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "src",
+	Subsystem: "gitserver",
+	Name:      "alternates_evicted_total",
+	Help:      "Number of copies of repositories fetched from another gitserver that were removed because they were unused or took up too much space.",
+})
+
+// alternate is a copy of a repository that is cloned on another gitserver.
+type alternate struct {
+	mu      sync.Mutex // held while the copy is fetched; protects the fields below
+	dir     GitDir     // the current copy, if any
+	evicted bool       // the alternate was removed from Server.alternates
+
+	// These fields are protected by Server.alternatesMu.
+	lastUsed time.Time
+	size     int64 // the size of dir in bytes
+}
+
+// alternateObjects returns the objects directory of alt. It must contain
+// alt.Commit.
+func (s *Server) alternateObjects(ctx context.Context, alt *protocol.AlternateRepo) (string, error) {
+	repo := protocol.NormalizeRepo(alt.Repo)
+	// 🚨 SECURITY: The commit is passed to git as an argument.
+	if alt.Commit != "" && !isAbsoluteRevision(string(alt.Commit)) {
+		return "", errors.Errorf("invalid alternate commit %q", alt.Commit)
+	}
+	if dir := s.dir(repo); repoCloned(dir) {
+		return dir.Path("objects"), nil
+	}
+	if alt.Addr == "" {
+		return "", errors.Errorf("repository %s is not cloned", repo)
+	}
+
+	// Fetching a copy can take longer than the command is allowed to run.
+	// We continue it in the background so the next request can use it.
+	type result struct {
+		dir GitDir
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ctx, cancel := s.serverContext()
+		defer cancel()
+		ctx, cancel = context.WithTimeout(ctx, longGitCommandTimeout)
+		defer cancel()
+
+		dir, err := s.fetchAlternate(ctx, repo, alt.Addr, alt.Commit)
+		done <- result{dir: dir, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return "", res.err
+		}
+		return res.dir.Path("objects"), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fetchAlternate returns a copy of repo that contains commit, fetching it
+// from the gitserver at addr if the current copy doesn't.
+func (s *Server) fetchAlternate(ctx context.Context, repo api.RepoName, addr string, commit api.CommitID) (GitDir, error) {
+	var a *alternate
+	for {
+		s.alternatesMu.Lock()
+		if s.alternates == nil {
+			s.alternates = make(map[api.RepoName]*alternate)
+		}
+		var ok bool
+		a, ok = s.alternates[repo]
+		if !ok {
+			a = &alternate{}
+			s.alternates[repo] = a
+		}
+		a.lastUsed = time.Now()
+		s.alternatesMu.Unlock()
+
+		a.mu.Lock()
+		if !a.evicted {
+			break
+		}
+		// It was evicted while we waited for the lock, so look up its
+		// replacement.
+		a.mu.Unlock()
+	}
+	defer a.mu.Unlock()
+
+	if a.dir != "" && (commit == "" || hasCommit(ctx, a.dir, commit)) {
+		return a.dir, nil
+	}
+
+	tmp, err := s.tempDir("alternate-")
+	if err != nil {
+		return "", err
+	}
+	// The copy is removed unless it becomes the current copy.
+	keep := false
+	defer func() {
+		if !keep {
+			os.RemoveAll(tmp)
+		}
+	}()
+
+	dir := GitDir(filepath.Join(tmp, ".git"))
+	if _, err := fetchRepoExport(ctx, addr, repo, string(dir), func(int64) {}); err != nil {
+		alternateFetches.WithLabelValues("failed").Inc()
+		return "", errors.Wrapf(err, "failed to fetch objects of %s", repo)
+	}
+	alternateFetches.WithLabelValues("done").Inc()
+
+	if commit != "" && !hasCommit(ctx, dir, commit) {
+		return "", errors.Errorf("commit %s not found in %s", commit, repo)
+	}
+
+	size, err := dirSize(string(dir))
+	if err != nil {
+		return "", err
+	}
+
+	if old := a.dir; old != "" {
+		removeAlternateLater(repo, old)
+	}
+	a.dir = dir
+	keep = true
+
+	s.alternatesMu.Lock()
+	a.size = size
+	s.alternatesMu.Unlock()
+
+	// Make room for the new copy now rather than waiting for the janitor.
+	s.evictAlternates(time.Now(), a)
+	return dir, nil
+}
+
+// cleanupAlternates removes the copies that weren't used for alternateTTL,
+// and the least recently used copies while the copies take up more than
+// alternatesMaxBytes. It is run by the janitor.
+func (s *Server) cleanupAlternates() {
+	s.evictAlternates(time.Now(), nil)
+}
+
+// evictAlternates removes the copies that weren't used since now minus
+// alternateTTL, and then the least recently used copies until the remaining
+// copies take up at most alternatesMaxBytes. The copy keep (if not nil) is
+// only removed if it is unused for alternateTTL.
+func (s *Server) evictAlternates(now time.Time, keep *alternate) {
+	type entry struct {
+		repo api.RepoName
+		a    *alternate
+	}
+
+	s.alternatesMu.Lock()
+	var (
+		entries []entry
+		evict   []entry
+		total   int64
+	)
+	for repo, a := range s.alternates {
+		if now.Sub(a.lastUsed) > alternateTTL {
+			evict = append(evict, entry{repo, a})
+			continue
+		}
+		entries = append(entries, entry{repo, a})
+		total += a.size
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].a.lastUsed.Before(entries[j].a.lastUsed) })
+	for _, e := range entries {
+		if total <= alternatesMaxBytes {
+			break
+		}
+		// Copies that are still being fetched for the first time have no
+		// size yet.
+		if e.a == keep || e.a.size == 0 {
+			continue
+		}
+		evict = append(evict, e)
+		total -= e.a.size
+	}
+	for _, e := range evict {
+		delete(s.alternates, e.repo)
+	}
+	s.alternatesMu.Unlock()
+
+	for _, e := range evict {
+		go func(e entry) {
+			// Wait for a fetch in progress, so that its copy is removed too.
+			e.a.mu.Lock()
+			defer e.a.mu.Unlock()
+			e.a.evicted = true
+			if e.a.dir != "" {
+				removeAlternateLater(e.repo, e.a.dir)
+				e.a.dir = ""
+			}
+			alternatesEvicted.Inc()
+		}(e)
+	}
+}
+
+// removeAlternateLater removes the copy dir of repo after
+// alternateRemoveDelay, so that commands which are still using it can finish.
+func removeAlternateLater(repo api.RepoName, dir GitDir) {
+	time.AfterFunc(alternateRemoveDelay, func() {
+		if err := os.RemoveAll(filepath.Dir(string(dir))); err != nil {
+			log15.Warn("failed to remove alternate", "repo", repo, "error", err)
+		}
+	})
+}
+
+// hasCommit returns whether commit exists in the repository at dir.
+func hasCommit(ctx context.Context, dir GitDir, commit api.CommitID) bool {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-e", string(commit)+"^{commit}")
+	cmd.Dir = string(dir)
+	return cmd.Run() == nil
+}
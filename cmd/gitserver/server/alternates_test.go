@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestExecAlternate(t *testing.T) {
+	remote, cleanup1 := tmpDir(t)
+	defer cleanup1()
+
+	cmd := func(dir, name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = dir
+		c.Env = []string{
+			"GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@a.com",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+		}
+		b, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s: %s", name, strings.Join(arg, " "), err, b)
+		}
+		return strings.TrimSpace(string(b))
+	}
+
+	cmd(remote, "git", "init", ".")
+	cmd(remote, "sh", "-c", "echo hello > hello.txt")
+	cmd(remote, "git", "add", "hello.txt")
+	cmd(remote, "git", "commit", "-m", "hello")
+
+	const (
+		upstream = api.RepoName("example.com/foo/upstream")
+		fork     = api.RepoName("example.com/bar/fork")
+	)
+
+	// The upstream repository is cloned on another gitserver and has a
+	// commit the fork doesn't have.
+	upstreamReposDir, cleanup2 := tmpDir(t)
+	defer cleanup2()
+	upstreamServer := &Server{ReposDir: upstreamReposDir}
+	upstreamHTTP := httptest.NewServer(upstreamServer.Handler())
+	defer upstreamHTTP.Close()
+	upstreamDir := string(upstreamServer.dir(upstream))
+	cmd(upstreamReposDir, "git", "clone", "--mirror", remote, upstreamDir)
+
+	forkReposDir, cleanup3 := tmpDir(t)
+	defer cleanup3()
+	forkServer := &Server{ReposDir: forkReposDir}
+	defer forkServer.Stop()
+	cmd(forkReposDir, "git", "clone", "--mirror", remote, string(forkServer.dir(fork)))
+
+	cmd(remote, "sh", "-c", "echo upstream > hello.txt")
+	cmd(remote, "git", "commit", "-am", "upstream")
+	cmd(upstreamDir, "git", "fetch", "origin", "+refs/heads/*:refs/heads/*")
+	upstreamCommit := cmd(upstreamDir, "git", "rev-parse", "HEAD")
+
+	exec := func(alt *protocol.AlternateRepo) (stdout, execErr string) {
+		t.Helper()
+		body, err := json.Marshal(&protocol.ExecRequest{
+			Repo:      fork,
+			Args:      []string{"cat-file", "-t", upstreamCommit},
+			Alternate: alt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		forkServer.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/exec", strings.NewReader(string(body))))
+		res := w.Result()
+		b, _ := ioutil.ReadAll(res.Body)
+		return strings.TrimSpace(string(b)), res.Trailer.Get("X-Exec-Error")
+	}
+
+	if _, execErr := exec(nil); execErr == "" {
+		t.Fatal("expected upstream commit to be missing without an alternate")
+	}
+
+	alt := &protocol.AlternateRepo{
+		Repo:   upstream,
+		Addr:   mustParseURL(t, upstreamHTTP.URL).Host,
+		Commit: api.CommitID(upstreamCommit),
+	}
+	if stdout, execErr := exec(alt); execErr != "" || stdout != "commit" {
+		t.Fatalf("have stdout %q and error %q, want commit", stdout, execErr)
+	}
+
+	// The copy is reused while it contains the requested commit.
+	copyDir := forkServer.alternates[upstream].dir
+	if _, execErr := exec(alt); execErr != "" {
+		t.Fatal(execErr)
+	}
+	if have := forkServer.alternates[upstream].dir; have != copyDir {
+		t.Fatalf("expected copy %s to be reused, have %s", copyDir, have)
+	}
+
+	// A commit that doesn't exist anywhere fails the command.
+	alt.Commit = "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
+	if _, execErr := exec(alt); execErr == "" {
+		t.Fatal("expected error for missing alternate commit")
+	}
+	// The copy fetched to look for it is removed, and the previous copy kept.
+	if have := forkServer.alternates[upstream].dir; have != copyDir {
+		t.Fatalf("expected copy %s to be kept, have %s", copyDir, have)
+	}
+	if entries, err := ioutil.ReadDir(filepath.Join(forkReposDir, tempDirName)); err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the current copy in the temporary directory, have %d entries (error: %v)", len(entries), err)
+	}
+
+	alt.Commit = "--upload-pack=foo"
+	if _, execErr := exec(alt); !strings.Contains(execErr, "invalid alternate commit") {
+		t.Fatalf("unexpected error for invalid commit: %q", execErr)
+	}
+}
+
+func TestEvictAlternates(t *testing.T) {
+	defer func(ttl time.Duration, max int64) { alternateTTL, alternatesMaxBytes = ttl, max }(alternateTTL, alternatesMaxBytes)
+	alternateTTL, alternatesMaxBytes = time.Hour, 100
+
+	now := time.Now()
+	newAlternate := func(lastUsed time.Duration, size int64) *alternate {
+		return &alternate{lastUsed: now.Add(-lastUsed), size: size}
+	}
+	s := &Server{alternates: map[api.RepoName]*alternate{
+		"expired":  newAlternate(2*time.Hour, 10),
+		"old":      newAlternate(30*time.Minute, 60),
+		"fetching": newAlternate(20*time.Minute, 0),
+		"recent":   newAlternate(10*time.Minute, 50),
+		"new":      newAlternate(0, 200),
+	}}
+	keep := s.alternates["new"]
+
+	// The expired copy is removed, then the least recently used copies
+	// until the copies fit, but never the copy just fetched (new) nor copies
+	// that are still being fetched.
+	s.evictAlternates(now, keep)
+	var have []string
+	for repo := range s.alternates {
+		have = append(have, string(repo))
+	}
+	sort.Strings(have)
+	if want := []string{"fetching", "new"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have alternates %v, want %v", have, want)
+	}
+
+	// Without a copy to keep, the janitor removes it too.
+	s.evictAlternates(now, nil)
+	if _, ok := s.alternates["new"]; ok {
+		t.Error("expected copy exceeding the size limit to be removed")
+	}
+}
+
+func TestSetupAndClearTmpRemovesAlternates(t *testing.T) {
+	reposDir, cleanup := tmpDir(t)
+	defer cleanup()
+	s := &Server{ReposDir: reposDir}
+	tmp, err := s.tempDir("alternate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetupAndClearTmp(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("expected copy left over from a previous run to be removed, have error %v", err)
+	}
+}
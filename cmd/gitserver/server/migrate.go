@@ -0,0 +1,659 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// When gitserver instances are added or removed, repositories are hashed to
+// a different gitserver. Rather than cloning them again from the code host,
+// the new owner of a repository transfers the $GIT_DIR from the previous
+// owner as a tar stream:
+//
+//   1. The frontend sends /repo-migrate to the new owner with the previous
+//      owner of each reassigned repository.
+//   2. The new owner fetches /repo-export from the previous owner in the
+//      background, subject to the clone limiter.
+//   3. Until the transfer is done, exec requests for the repository are
+//      proxied to the previous owner so reads keep working.
+//
+// The previous owner keeps its copy. If a transfer fails, the repository is
+// cloned from the code host as usual on the next request. Unfinished
+// transfers are resumed when gitserver restarts.
+
+// migrationStatusTTL is how long the status of finished migrations is kept
+// around for /repo-migrations.
+const migrationStatusTTL = 24 * time.Hour
+
+// migrationsFile is the file in ReposDir that the status of migrations is
+// persisted to, so unfinished migrations are resumed after a restart.
+const migrationsFile = ".repo-migrations.json"
+
+// migrationStallTimeout is how long a transfer may go without receiving any
+// data before it is aborted. Transfers of large repositories take long, so we
+// don't limit their total duration.
+const migrationStallTimeout = 5 * time.Minute
+
+// migrationClient is used for requests to other gitservers. Requests are
+// bound by their context; see migrationStallTimeout.
+var migrationClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+	},
+}
+
+var (
+	repoMigrations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repo_migrations_total",
+		Help:      "Number of repositories transferred from another gitserver, by state (done or failed).",
+	}, []string{"state"})
+	repoMigrationBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repo_migration_bytes_total",
+		Help:      "Number of bytes received when transferring repositories from another gitserver.",
+	})
+	execProxied = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "exec_proxied_total",
+		Help:      "Number of exec requests proxied to the previous gitserver of a repository that is being migrated.",
+	})
+)
+
+// handleRepoExport streams the $GIT_DIR of a cloned repository as a tar
+// archive.
+func (s *Server) handleRepoExport(w http.ResponseWriter, r *http.Request) {
+	var req protocol.RepoExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Repo = protocol.NormalizeRepo(req.Repo)
+
+	dir := s.dir(req.Repo)
+	if !repoCloned(dir) {
+		http.Error(w, "repository not cloned", http.StatusNotFound)
+		return
+	}
+
+	snapshot, err := s.snapshotRepo(req.Repo, dir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(snapshot)
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.WriteHeader(http.StatusOK)
+	if err := writeTar(w, snapshot); err != nil {
+		// We have already written the header, so the best we can do is to
+		// truncate the archive. The receiver fails to read it.
+		log15.Error("failed to export repository", "repo", req.Repo, "error", err)
+	}
+}
+
+// snapshotRepo hard links the files of the $GIT_DIR dir into a temporary
+// directory and returns its path. It holds the update lock of repo while
+// doing so, which prevents fetches from changing refs and packs, but not
+// while the snapshot is streamed. Git replaces files rather than modifying
+// them in place, so the snapshot stays consistent.
+func (s *Server) snapshotRepo(repo api.RepoName, dir GitDir) (string, error) {
+	tmp, err := s.tempDir("export-repo-")
+	if err != nil {
+		return "", err
+	}
+
+	s.repoUpdateLocksMu.Lock()
+	l := s.repoUpdateLocksLocked(repo)
+	s.repoUpdateLocksMu.Unlock()
+	l.mu.Lock()
+	err = linkTree(string(dir), tmp)
+	l.mu.Unlock()
+
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", errors.Wrap(err, "failed to snapshot repository")
+	}
+	return tmp, nil
+}
+
+// linkTree recreates the directories below src in dst and hard links the
+// regular files in them.
+func linkTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		switch {
+		case fi.IsDir():
+			return os.Mkdir(filepath.Join(dst, rel), fi.Mode().Perm())
+		case fi.Mode().IsRegular():
+			return os.Link(path, filepath.Join(dst, rel))
+		}
+		return nil
+	})
+}
+
+// handleRepoMigrate registers the given migrations and transfers the
+// repositories in the background.
+func (s *Server) handleRepoMigrate(w http.ResponseWriter, r *http.Request) {
+	var req protocol.RepoMigrateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	var queued []protocol.RepoMigration
+
+	s.migrationsMu.Lock()
+	if s.migrations == nil {
+		s.migrations = make(map[api.RepoName]*protocol.RepoMigrationStatus)
+	}
+	for repo, st := range s.migrations {
+		if migrationFinished(st.State) && now.Sub(st.UpdatedAt) > migrationStatusTTL {
+			delete(s.migrations, repo)
+		}
+	}
+	for _, m := range req.Migrations {
+		m.Repo = protocol.NormalizeRepo(m.Repo)
+		if m.Repo == "" || m.From == "" {
+			continue
+		}
+		if st, ok := s.migrations[m.Repo]; ok && !migrationFinished(st.State) {
+			continue
+		}
+		if repoCloned(s.dir(m.Repo)) {
+			continue
+		}
+		s.migrations[m.Repo] = &protocol.RepoMigrationStatus{
+			Repo:      m.Repo,
+			From:      m.From,
+			State:     protocol.RepoMigrationQueued,
+			UpdatedAt: now,
+		}
+		queued = append(queued, m)
+	}
+	s.saveMigrationsLocked()
+	s.migrationsMu.Unlock()
+
+	for _, m := range queued {
+		go s.runMigration(m)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// runMigration transfers a registered repository migration and records its
+// outcome.
+func (s *Server) runMigration(m protocol.RepoMigration) {
+	// Create a new context because this is in a background goroutine.
+	ctx, cancel := s.serverContext()
+	defer cancel()
+
+	err := s.migrateRepo(ctx, m.Repo, m.From)
+	if err != nil {
+		log15.Warn("failed to migrate repository, it will be cloned from its code host instead", "repo", m.Repo, "from", m.From, "error", err)
+		s.updateMigration(m.Repo, func(st *protocol.RepoMigrationStatus) {
+			st.State = protocol.RepoMigrationFailed
+			st.Error = err.Error()
+		})
+		repoMigrations.WithLabelValues("failed").Inc()
+		return
+	}
+	log15.Info("repo migrated", "repo", m.Repo, "from", m.From)
+	s.updateMigration(m.Repo, func(st *protocol.RepoMigrationStatus) {
+		st.State = protocol.RepoMigrationDone
+	})
+	repoMigrations.WithLabelValues("done").Inc()
+}
+
+// resumeMigrations loads the migrations persisted by a previous run of
+// gitserver and restarts the unfinished ones.
+func (s *Server) resumeMigrations() {
+	b, err := ioutil.ReadFile(filepath.Join(s.ReposDir, migrationsFile))
+	if os.IsNotExist(err) {
+		return
+	}
+	var statuses []*protocol.RepoMigrationStatus
+	if err == nil {
+		err = json.Unmarshal(b, &statuses)
+	}
+	if err != nil {
+		log15.Error("failed to load repository migrations", "error", err)
+		return
+	}
+
+	var resumed []protocol.RepoMigration
+	s.migrationsMu.Lock()
+	s.migrations = make(map[api.RepoName]*protocol.RepoMigrationStatus, len(statuses))
+	for _, st := range statuses {
+		if !migrationFinished(st.State) {
+			if repoCloned(s.dir(st.Repo)) {
+				continue
+			}
+			st.State = protocol.RepoMigrationQueued
+			resumed = append(resumed, protocol.RepoMigration{Repo: st.Repo, From: st.From})
+		}
+		s.migrations[st.Repo] = st
+	}
+	s.migrationsMu.Unlock()
+
+	if len(resumed) > 0 {
+		log15.Info("resuming repository migrations", "count", len(resumed))
+	}
+	for _, m := range resumed {
+		go s.runMigration(m)
+	}
+}
+
+// saveMigrationsLocked persists the status of all migrations to
+// migrationsFile. s.migrationsMu must be held.
+func (s *Server) saveMigrationsLocked() {
+	statuses := make([]*protocol.RepoMigrationStatus, 0, len(s.migrations))
+	for _, st := range s.migrations {
+		statuses = append(statuses, st)
+	}
+	b, err := json.Marshal(statuses)
+	if err == nil {
+		path := filepath.Join(s.ReposDir, migrationsFile)
+		if err = ioutil.WriteFile(path+".new", b, 0600); err == nil {
+			err = os.Rename(path+".new", path)
+		}
+	}
+	if err != nil {
+		log15.Error("failed to save repository migrations", "error", err)
+	}
+}
+
+// handleRepoMigrations returns the status of the migrations registered on
+// this gitserver, sorted by repository name.
+func (s *Server) handleRepoMigrations(w http.ResponseWriter, r *http.Request) {
+	s.migrationsMu.Lock()
+	resp := make([]protocol.RepoMigrationStatus, 0, len(s.migrations))
+	for _, st := range s.migrations {
+		resp = append(resp, *st)
+	}
+	s.migrationsMu.Unlock()
+
+	sort.Slice(resp, func(i, j int) bool { return resp[i].Repo < resp[j].Repo })
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// migrationSource returns the address of the gitserver repo is being
+// transferred from, if any.
+func (s *Server) migrationSource(repo api.RepoName) (from string, ok bool) {
+	s.migrationsMu.Lock()
+	defer s.migrationsMu.Unlock()
+	st, ok := s.migrations[repo]
+	if !ok || migrationFinished(st.State) {
+		return "", false
+	}
+	return st.From, true
+}
+
+// updateMigration applies f to the status of the migration of repo. Changes
+// of its state are persisted.
+func (s *Server) updateMigration(repo api.RepoName, f func(*protocol.RepoMigrationStatus)) {
+	s.migrationsMu.Lock()
+	defer s.migrationsMu.Unlock()
+	if st, ok := s.migrations[repo]; ok {
+		state := st.State
+		f(st)
+		st.UpdatedAt = time.Now()
+		if st.State != state {
+			s.saveMigrationsLocked()
+		}
+	}
+}
+
+func migrationFinished(state protocol.RepoMigrationState) bool {
+	return state == protocol.RepoMigrationDone || state == protocol.RepoMigrationFailed
+}
+
+// migrateRepo transfers the $GIT_DIR of repo from the gitserver at from.
+func (s *Server) migrateRepo(ctx context.Context, repo api.RepoName, from string) error {
+	dir := s.dir(repo)
+	if repoCloned(dir) {
+		return nil
+	}
+
+	ctx, cancel, err := s.acquireCloneLimiter(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	lock, ok := s.locker.TryAcquire(dir, "migrating from "+from)
+	if !ok {
+		return errors.New("a clone is already in progress")
+	}
+	defer lock.Release()
+
+	// The repository may have been cloned while we waited for the limiter.
+	if repoCloned(dir) {
+		return nil
+	}
+
+	s.updateMigration(repo, func(st *protocol.RepoMigrationStatus) {
+		st.State = protocol.RepoMigrationTransferring
+	})
+
+	tmp, err := s.tempDir("migrate-repo-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	tmpDir := GitDir(filepath.Join(tmp, ".git"))
+
+	n, err := fetchRepoExport(ctx, from, repo, string(tmpDir), func(n int64) {
+		lock.SetStatus(fmt.Sprintf("migrating from %s: %s received", from, formatBytes(n)))
+		s.updateMigration(repo, func(st *protocol.RepoMigrationStatus) {
+			st.Bytes = n
+		})
+	})
+	repoMigrationBytes.Add(float64(n))
+	if err != nil {
+		return err
+	}
+
+	// Make sure we received a usable repository before putting it in place.
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir")
+	cmd.Dir = string(tmpDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "transferred repository is invalid: %s", out)
+	}
+	if err := setGitAttributes(tmpDir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(string(dir)), os.ModePerm); err != nil {
+		return err
+	}
+	return renameAndSync(string(tmpDir), string(dir))
+}
+
+// fetchRepoExport extracts the $GIT_DIR of repo, exported by the gitserver at
+// from, into dir. report is called periodically with the number of bytes
+// received so far. It returns the total number of bytes received.
+func fetchRepoExport(ctx context.Context, from string, repo api.RepoName, dir string, report func(n int64)) (int64, error) {
+	body, err := json.Marshal(&protocol.RepoExportRequest{Repo: repo})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest("POST", "http://"+from+"/repo-export", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	// Abort the transfer if the other gitserver stops sending data.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stalled := time.AfterFunc(migrationStallTimeout, cancel)
+	defer stalled.Stop()
+
+	resp, err := migrationClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.Errorf("export from %s failed with http status %d", from, resp.StatusCode)
+	}
+
+	pr := &progressReader{r: resp.Body, report: func(n int64) {
+		stalled.Reset(migrationStallTimeout)
+		report(n)
+	}}
+	err = readTar(pr, dir)
+	pr.report(pr.n)
+	if err != nil {
+		return pr.n, errors.Wrap(err, "failed to read repository archive")
+	}
+	return pr.n, nil
+}
+
+// proxyExec forwards an exec request to the gitserver at from, including the
+// trailers with the exit status of the command.
+func (s *Server) proxyExec(ctx context.Context, w http.ResponseWriter, from string, req *protocol.ExecRequest) error {
+	execProxied.Inc()
+
+	// Drop the remote URL so the previous owner doesn't start cloning a
+	// repository it doesn't have. If it doesn't have it, the transfer
+	// fails and we clone the repository ourselves.
+	proxied := *req
+	proxied.URL = ""
+
+	body, err := json.Marshal(&proxied)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest("POST", "http://"+from+"/exec", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := migrationClient.Do(r.WithContext(ctx))
+	if err != nil {
+		http.Error(w, "failed to proxy request to previous gitserver: "+err.Error(), http.StatusBadGateway)
+		return err
+	}
+	defer resp.Body.Close()
+
+	for k, vs := range resp.Header {
+		w.Header()[k] = vs
+	}
+	for k := range resp.Trailer {
+		w.Header().Add("Trailer", k)
+	}
+	w.WriteHeader(resp.StatusCode)
+
+	_, err = io.Copy(w, resp.Body)
+	for k, vs := range resp.Trailer {
+		w.Header()[k] = vs
+	}
+	return err
+}
+
+// writeTar writes the regular files and directories below dir to w.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() && !fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.CopyN(tw, f, hdr.Size)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// readTar extracts the regular files, directories and links of the tar archive
+// in r into dir.
+func readTar(r io.Reader, dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 🚨 SECURITY: Do not write outside of dir.
+		path, err := pathInDir(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if err1 := f.Close(); err == nil {
+				err = err1
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// 🚨 SECURITY: Symlinks must not point outside of dir, so that later
+			// entries can't be written through them.
+			target := filepath.FromSlash(hdr.Linkname)
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			if !isInDir(dir, target) {
+				return errors.Errorf("invalid symlink target in archive: %q -> %q", hdr.Name, hdr.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			// 🚨 SECURITY: Hard links must refer to files in dir.
+			target, err := pathInDir(dir, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Link(target, path); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unsupported file type in archive: %q", hdr.Name)
+		}
+	}
+}
+
+// pathInDir returns the path of the archive entry name in dir. It returns an
+// error if the path is outside of dir.
+func pathInDir(dir, name string) (string, error) {
+	if filepath.IsAbs(filepath.FromSlash(name)) {
+		return "", errors.Errorf("invalid path in archive: %q", name)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if !isInDir(dir, path) || path == filepath.Clean(dir) {
+		return "", errors.Errorf("invalid path in archive: %q", name)
+	}
+	return path, nil
+}
+
+// isInDir reports whether the cleaned path is dir or is inside of dir.
+func isInDir(dir, path string) bool {
+	dir = filepath.Clean(dir)
+	path = filepath.Clean(path)
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// progressReader counts the bytes read from r and calls report at most once
+// per second.
+type progressReader struct {
+	r      io.Reader
+	n      int64
+	report func(n int64)
+	last   time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	if now := time.Now(); now.Sub(p.last) >= time.Second {
+		p.last = now
+		p.report(p.n)
+	}
+	return n, err
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestMigrateRepo(t *testing.T) {
+	remote, cleanup1 := tmpDir(t)
+	defer cleanup1()
+
+	cmd := func(dir, name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = dir
+		c.Env = []string{
+			"GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@a.com",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+		}
+		b, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s", name, strings.Join(arg, " "), err)
+		}
+		return string(b)
+	}
+
+	cmd(remote, "git", "init", ".")
+	cmd(remote, "sh", "-c", "echo hello world > hello.txt")
+	cmd(remote, "git", "add", "hello.txt")
+	cmd(remote, "git", "commit", "-m", "hello")
+	wantCommit := cmd(remote, "git", "rev-parse", "HEAD")
+
+	const repo = api.RepoName("example.com/foo/bar")
+
+	// The previous owner has the repository cloned.
+	oldReposDir, cleanup2 := tmpDir(t)
+	defer cleanup2()
+	oldServer := &Server{ReposDir: oldReposDir}
+	old := httptest.NewServer(oldServer.Handler())
+	defer old.Close()
+	oldDir := oldServer.dir(repo)
+	cmd(oldReposDir, "git", "clone", "--mirror", remote, string(oldDir))
+
+	newReposDir, cleanup3 := tmpDir(t)
+	defer cleanup3()
+	newServer := &Server{ReposDir: newReposDir}
+	h := newServer.Handler()
+
+	from := mustParseURL(t, old.URL).Host
+
+	// Register the migration without transferring yet, so we can check
+	// that reads are proxied to the previous owner.
+	newServer.migrations = map[api.RepoName]*protocol.RepoMigrationStatus{
+		repo: {Repo: repo, From: from, State: protocol.RepoMigrationQueued},
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/exec", strings.NewReader(`{"repo": "example.com/foo/bar", "args": ["rev-parse", "HEAD"]}`)))
+	res := w.Result()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("proxied exec: have status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	if strings.TrimSpace(string(body)) != strings.TrimSpace(wantCommit) {
+		t.Fatalf("proxied exec: have %q, want %q", body, wantCommit)
+	}
+	if have := res.Trailer.Get("X-Exec-Exit-Status"); have != "0" {
+		t.Fatalf("proxied exec: have exit status trailer %q, want 0", have)
+	}
+
+	if err := newServer.migrateRepo(context.Background(), repo, from); err != nil {
+		t.Fatal(err)
+	}
+	newDir := newServer.dir(repo)
+	if !repoCloned(newDir) {
+		t.Fatal("repository not cloned after migration")
+	}
+	if have := cmd(string(newDir), "git", "rev-parse", "HEAD"); have != wantCommit {
+		t.Fatalf("have commit %q, want %q", have, wantCommit)
+	}
+	if _, locked := newServer.locker.Status(newDir); locked {
+		t.Fatal("repository still locked after migration")
+	}
+
+	// Migrating a repository the previous owner doesn't have fails.
+	if err := newServer.migrateRepo(context.Background(), "example.com/foo/missing", from); err == nil {
+		t.Fatal("expected migration of missing repository to fail")
+	}
+}
+
+func TestHandleRepoMigrate(t *testing.T) {
+	reposDir, cleanup := tmpDir(t)
+	defer cleanup()
+	s := &Server{ReposDir: reposDir}
+	h := s.Handler()
+	defer s.Stop()
+
+	// Nothing listens on this address, so the transfer fails.
+	req := `{"migrations": [{"repo": "example.com/foo/bar", "from": "127.0.0.1:1"}, {"repo": "example.com/foo/baz"}]}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/repo-migrate", strings.NewReader(req)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("have status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var statuses string
+	for i := 0; i < 1000; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/repo-migrations", nil))
+		statuses = w.Body.String()
+		if strings.Contains(statuses, `"state":"failed"`) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(statuses, `"repo":"example.com/foo/bar"`) || !strings.Contains(statuses, `"state":"failed"`) {
+		t.Fatalf("unexpected migration statuses: %s", statuses)
+	}
+	if strings.Contains(statuses, "example.com/foo/baz") {
+		t.Fatalf("migration without a source should be ignored: %s", statuses)
+	}
+
+	// Failed migrations are no longer proxied.
+	if _, ok := s.migrationSource("example.com/foo/bar"); ok {
+		t.Fatal("failed migration should not be proxied")
+	}
+}
+
+func TestResumeMigrations(t *testing.T) {
+	reposDir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	// A previous run was interrupted while transferring a repository from a
+	// gitserver that is gone now.
+	persisted := `[
+		{"repo": "example.com/foo/bar", "from": "127.0.0.1:1", "state": "transferring"},
+		{"repo": "example.com/foo/done", "from": "127.0.0.1:1", "state": "done"}
+	]`
+	if err := ioutil.WriteFile(filepath.Join(reposDir, migrationsFile), []byte(persisted), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{ReposDir: reposDir}
+	s.Handler()
+	defer s.Stop()
+
+	var b []byte
+	for i := 0; i < 1000; i++ {
+		var err error
+		if b, err = ioutil.ReadFile(filepath.Join(reposDir, migrationsFile)); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), `"state":"failed"`) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var statuses []protocol.RepoMigrationStatus
+	if err := json.Unmarshal(b, &statuses); err != nil {
+		t.Fatal(err)
+	}
+	states := map[api.RepoName]protocol.RepoMigrationState{}
+	for _, st := range statuses {
+		states[st.Repo] = st.State
+	}
+	want := map[api.RepoName]protocol.RepoMigrationState{
+		"example.com/foo/bar":  protocol.RepoMigrationFailed,
+		"example.com/foo/done": protocol.RepoMigrationDone,
+	}
+	if !reflect.DeepEqual(states, want) {
+		t.Fatalf("have persisted states %v, want %v", states, want)
+	}
+}
+
+func TestReadWriteTar(t *testing.T) {
+	dir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	src, cleanup2 := tmpDir(t)
+	defer cleanup2()
+	mkFiles(t, src, "objects/pack/a.pack", "HEAD")
+	if err := writeTar(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := readTar(bytes.NewReader(buf.Bytes()), dir); err != nil {
+		t.Fatal(err)
+	}
+	if !repoCloned(GitDir(dir)) {
+		t.Fatal("expected HEAD to be extracted")
+	}
+
+	for _, hdr := range []*tar.Header{
+		{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "a/../../evil", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "/evil", Typeflag: tar.TypeReg, Mode: 0600},
+		{Name: "evil", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+		{Name: "a/evil", Typeflag: tar.TypeSymlink, Linkname: "../../outside"},
+		{Name: "evil", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+		{Name: "evil", Typeflag: tar.TypeLink, Linkname: "../outside"},
+	} {
+		buf.Reset()
+		tw := tar.NewWriter(&buf)
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := readTar(&buf, dir); err == nil {
+			t.Errorf("%s -> %s: expected error for path outside of directory", hdr.Name, hdr.Linkname)
+		}
+	}
+
+	// Links within the directory are extracted.
+	buf.Reset()
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range []*tar.Header{
+		{Name: "refs/link", Typeflag: tar.TypeSymlink, Linkname: "../HEAD"},
+		{Name: "HEAD.link", Typeflag: tar.TypeLink, Linkname: "HEAD"},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := readTar(&buf, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"refs/link", "HEAD.link"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func mustParseURL(t *testing.T, rawurl string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
@@ -138,6 +138,16 @@ type Server struct {
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
+
+	migrationsMu sync.Mutex // protects the map below
+	// migrations tracks repositories which are being transferred from
+	// another gitserver. See migrate.go.
+	migrations map[api.RepoName]*protocol.RepoMigrationStatus
+
+	alternatesMu sync.Mutex // protects the map below
+	// alternates are copies of repositories cloned on other gitservers. See
+	// alternates.go.
+	alternates map[api.RepoName]*alternate
 }
 
 type locks struct {
@@ -222,6 +232,8 @@ func (s *Server) Handler() http.Handler {
 		s.cloneableLimiter.SetLimit(limit)
 	})
 
+	s.resumeMigrations()
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/archive", s.handleArchive)
 	mux.HandleFunc("/exec", s.handleExec)
@@ -234,6 +246,9 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
 	mux.HandleFunc("/getGitolitePhabricatorMetadata", s.handleGetGitolitePhabricatorMetadata)
 	mux.HandleFunc("/create-commit-from-patch", s.handleCreateCommitFromPatch)
+	mux.HandleFunc("/repo-export", s.handleRepoExport)
+	mux.HandleFunc("/repo-migrate", s.handleRepoMigrate)
+	mux.HandleFunc("/repo-migrations", s.handleRepoMigrations)
 	mux.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -243,6 +258,7 @@ func (s *Server) Handler() http.Handler {
 // Janitor does clean up tasks over s.ReposDir.
 func (s *Server) Janitor() {
 	s.cleanupRepos()
+	s.cleanupAlternates()
 }
 
 // Stop cancels the running background jobs and returns when done.
@@ -608,6 +624,15 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	}
 
 	dir := s.dir(req.Repo)
+
+	// While a repository is transferred from its previous gitserver we
+	// serve reads from there rather than cloning it again.
+	if from, ok := s.migrationSource(req.Repo); ok && !repoCloned(dir) {
+		status = "proxied"
+		execErr = s.proxyExec(ctx, w, from, req)
+		return
+	}
+
 	cloneProgress, cloneInProgress := s.locker.Status(dir)
 	if cloneInProgress {
 		status = "clone-in-progress"
@@ -654,6 +679,20 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	w.Header().Add("Trailer", "X-Exec-Stderr")
 	w.WriteHeader(http.StatusOK)
 
+	var env []string
+	if req.Alternate != nil {
+		objects, err := s.alternateObjects(ctx, req.Alternate)
+		if err != nil {
+			status = "alternate-failed"
+			execErr = err
+			w.Header().Set("X-Exec-Error", errorString(err))
+			w.Header().Set("X-Exec-Exit-Status", strconv.Itoa(exitStatus))
+			w.Header().Set("X-Exec-Stderr", "")
+			return
+		}
+		env = append(os.Environ(), "GIT_ALTERNATE_OBJECT_DIRECTORIES="+objects)
+	}
+
 	// Special-case `git rev-parse HEAD` requests. These are invoked by search queries for every repo in scope.
 	// For searches over large repo sets (> 1k), this leads to too many child process execs, which can lead
 	// to a persistent failure mode where every exec takes > 10s, which is disastrous for gitserver performance.
@@ -674,6 +713,7 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	cmdStart = time.Now()
 	cmd := exec.CommandContext(ctx, "git", req.Args...)
 	cmd.Dir = string(dir)
+	cmd.Env = env
 	cmd.Stdout = stdoutW
 	cmd.Stderr = stderrW
 
@@ -1054,14 +1094,7 @@ func (s *Server) doRepoUpdate(ctx context.Context, repo api.RepoName, url string
 	defer span.Finish()
 
 	s.repoUpdateLocksMu.Lock()
-	l, ok := s.repoUpdateLocks[repo]
-	if !ok {
-		l = &locks{
-			once: new(sync.Once),
-			mu:   new(sync.Mutex),
-		}
-		s.repoUpdateLocks[repo] = l
-	}
+	l := s.repoUpdateLocksLocked(repo)
 	once := l.once
 	mu := l.mu
 	s.repoUpdateLocksMu.Unlock()
@@ -1254,6 +1287,20 @@ func computeRefHash(dir GitDir) ([]byte, error) {
 	return hash, nil
 }
 
+// repoUpdateLocksLocked returns the update locks for repo, creating them if
+// necessary. s.repoUpdateLocksMu must be held.
+func (s *Server) repoUpdateLocksLocked(repo api.RepoName) *locks {
+	l, ok := s.repoUpdateLocks[repo]
+	if !ok {
+		l = &locks{
+			once: new(sync.Once),
+			mu:   new(sync.Mutex),
+		}
+		s.repoUpdateLocks[repo] = l
+	}
+	return l
+}
+
 func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 	// background context.
 	ctx, cancel1 := s.serverContext()
@@ -53,6 +53,7 @@ func newBitbucketCloudSource(svc *ExternalService, c *schema.BitbucketCloudConne
 	if err != nil {
 		return nil, err
 	}
+	cli = withRequestResilience(cli, (*requestResilience)(c.RequestResilience))
 
 	var eb excludeBuilder
 	for _, r := range c.Exclude {
@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"golang.org/x/time/rate"
@@ -60,6 +61,8 @@ func newBitbucketServerSource(svc *ExternalService, c *schema.BitbucketServerCon
 	if err != nil {
 		return nil, err
 	}
+	cli = rateLimitedDoer(cli, svc, ratelimit.DefaultCoordinator)
+	cli = withRequestResilience(cli, (*requestResilience)(c.RequestResilience))
 
 	var eb excludeBuilder
 	for _, r := range c.Exclude {
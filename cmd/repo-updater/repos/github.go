@@ -18,6 +18,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"golang.org/x/time/rate"
@@ -86,6 +87,8 @@ func newGithubSource(svc *ExternalService, c *schema.GitHubConnection, cf *httpc
 	if err != nil {
 		return nil, err
 	}
+	cli = rateLimitedDoer(cli, svc, ratelimit.DefaultCoordinator)
+	cli = withRequestResilience(cli, (*requestResilience)(c.RequestResilience))
 
 	var (
 		eb              excludeBuilder
@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -59,6 +60,8 @@ func newGitLabSource(svc *ExternalService, c *schema.GitLabConnection, cf *httpc
 	if err != nil {
 		return nil, err
 	}
+	cli = rateLimitedDoer(cli, svc, ratelimit.DefaultCoordinator)
+	cli = withRequestResilience(cli, (*requestResilience)(c.RequestResilience))
 
 	var eb excludeBuilder
 	for _, r := range c.Exclude {
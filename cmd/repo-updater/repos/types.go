@@ -17,12 +17,15 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/awscodecommit"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"github.com/xeipuuv/gojsonschema"
 	"golang.org/x/time/rate"
@@ -112,6 +115,38 @@ func (e ExternalService) Configuration() (cfg interface{}, _ error) {
 	return cfg, jsonc.Unmarshal(e.Config, cfg)
 }
 
+// RateLimitKey returns the key of the API budget shared by all services that
+// talk to the code host of this external service with the same token, along
+// with the normalized base URL of the code host. It returns false for kinds
+// that aren't rate limited.
+func (e *ExternalService) RateLimitKey() (ratelimit.Key, *url.URL, bool) {
+	cfg, err := e.Configuration()
+	if err != nil {
+		return ratelimit.Key{}, nil, false
+	}
+
+	var rawURL, token string
+	switch c := cfg.(type) {
+	case *schema.GitHubConnection:
+		rawURL, token = c.Url, c.Token
+	case *schema.GitLabConnection:
+		rawURL, token = c.Url, c.Token
+	case *schema.BitbucketServerConnection:
+		rawURL, token = c.Url, c.Token
+		if token == "" {
+			token = c.Username
+		}
+	default:
+		return ratelimit.Key{}, nil, false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ratelimit.Key{}, nil, false
+	}
+	return ratelimit.Key{ExternalServiceID: e.ID, Token: token}, extsvc.NormalizeBaseURL(u), true
+}
+
 // Exclude changes the configuration of an external service to exclude the given
 // repos from being synced.
 func (e *ExternalService) Exclude(rs ...*Repo) error {
@@ -928,6 +963,10 @@ func (es ExternalServices) With(opts ...func(*ExternalService)) ExternalServices
 }
 
 type RateLimiterRegistry struct {
+	// Coordinator, if set, is kept up to date with the configured limits so
+	// that other services share the same budget.
+	Coordinator *ratelimit.Coordinator
+
 	mu sync.Mutex
 	// Rate limiter per external service, keys are database ID of external services.
 	rateLimiters map[int64]*rate.Limiter
@@ -937,6 +976,7 @@ type RateLimiterRegistry struct {
 // empty registry is returned which can still to handle syncs.
 func NewRateLimiterRegistry(ctx context.Context, store Store) (*RateLimiterRegistry, error) {
 	r := &RateLimiterRegistry{
+		Coordinator:  ratelimit.DefaultCoordinator,
 		rateLimiters: make(map[int64]*rate.Limiter),
 	}
 
@@ -1033,10 +1073,26 @@ func (r *RateLimiterRegistry) updateRateLimiter(svc *ExternalService) error {
 
 	l := r.GetRateLimiter(svc.ID)
 	l.SetLimit(limit)
+	if r.Coordinator != nil {
+		r.Coordinator.SetLimit(svc.ID, limit, l.Burst())
+	}
 
 	return nil
 }
 
+// rateLimitedDoer returns cli wrapped so that every request made with it
+// reserves API budget from coordinator, which is shared with the other
+// services that talk to the code host of svc with the same token. Requests
+// reserve budget at the priority of their context, so work that a user is
+// waiting on should use ratelimit.WithPriority.
+func rateLimitedDoer(cli httpcli.Doer, svc *ExternalService, coordinator *ratelimit.Coordinator) httpcli.Doer {
+	k, _, ok := svc.RateLimitKey()
+	if !ok || coordinator == nil {
+		return cli
+	}
+	return coordinator.Doer(cli, func(context.Context) (ratelimit.Key, bool) { return k, true })
+}
+
 func getLimit(enabled bool, perHour float64) rate.Limit {
 	if enabled {
 		return rate.Limit(perHour / 3600)
@@ -1,6 +1,8 @@
 package repos
 
 import (
+	"context"
+	"net/http"
 	"testing"
 	"time"
 
@@ -10,7 +12,9 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"golang.org/x/time/rate"
 )
 
@@ -532,3 +536,49 @@ func TestRateLimiterRegistry(t *testing.T) {
 		t.Fatalf("Expected limit %f, got %f", expectedLimit, l.Limit())
 	}
 }
+
+func TestRateLimitedDoer(t *testing.T) {
+	svc := &ExternalService{
+		ID:     1,
+		Kind:   "GITHUB",
+		Config: `{"url": "https://github.com", "token": "secret"}`,
+	}
+
+	k, baseURL, ok := svc.RateLimitKey()
+	if !ok {
+		t.Fatal("want a rate limit key")
+	}
+	if want := (ratelimit.Key{ExternalServiceID: 1, Token: "secret"}); k != want {
+		t.Fatalf("want key %v, got %v", want, k)
+	}
+	if want := "https://github.com/"; baseURL.String() != want {
+		t.Fatalf("want base URL %q, got %q", want, baseURL)
+	}
+
+	// A shared budget of two requests, which is never refilled.
+	coordinator := ratelimit.NewCoordinator(nil)
+	coordinator.SetLimit(1, 0, 2)
+
+	cli := rateLimitedDoer(httpcli.DoerFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}), svc, coordinator)
+
+	ctx := ratelimit.WithPriority(context.Background(), ratelimit.PriorityInteractive)
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequest("GET", "https://api.github.com/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cli.Do(req.WithContext(ctx)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The requests were taken from the shared budget, so another service
+	// using the same token has to wait.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := coordinator.Reserve(ctx, k, ratelimit.PriorityInteractive, 1); err != context.DeadlineExceeded {
+		t.Fatalf("want budget to be used up, got %v", err)
+	}
+}
@@ -2,6 +2,9 @@ package repos
 
 import (
 	"net/url"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 )
 
 // setUserinfoBestEffort adds the username and password to rawurl. If user is
@@ -38,3 +41,37 @@ func setUserinfoBestEffort(rawurl, username, password string) string {
 
 	return u.String()
 }
+
+// requestResilience has the fields of the requestResilience config of the
+// code host connections that support it, in the same order, so that the
+// schema types convert to it.
+type requestResilience struct {
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerOpenSeconds      int
+	MaxRetries                     int
+	MaxRetryDelaySeconds           int
+}
+
+// withRequestResilience wraps cli, which must come from
+// httpcli.NewExternalHTTPClientFactory, so that its requests are retried and
+// circuit broken as configured in r. A nil r and fields that are zero use the
+// defaults; a negative MaxRetries or CircuitBreakerFailureThreshold disables
+// retries or the circuit breaker.
+func withRequestResilience(cli httpcli.Doer, r *requestResilience) httpcli.Doer {
+	p := httpcli.DefaultPolicies
+	if r != nil {
+		if r.MaxRetries != 0 {
+			p.Retry.MaxRetries = r.MaxRetries
+		}
+		if r.MaxRetryDelaySeconds > 0 {
+			p.Retry.MaxDelay = time.Duration(r.MaxRetryDelaySeconds) * time.Second
+		}
+		if r.CircuitBreakerFailureThreshold != 0 {
+			p.CircuitBreaker.FailureThreshold = r.CircuitBreakerFailureThreshold
+		}
+		if r.CircuitBreakerOpenSeconds > 0 {
+			p.CircuitBreaker.OpenTimeout = time.Duration(r.CircuitBreakerOpenSeconds) * time.Second
+		}
+	}
+	return httpcli.NewPoliciesMiddleware(p)(cli)
+}
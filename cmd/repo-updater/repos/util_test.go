@@ -1,7 +1,11 @@
 package repos
 
 import (
+	"net/http"
 	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 )
 
 func TestSetUserinfoBestEffort(t *testing.T) {
@@ -43,3 +47,52 @@ func TestSetUserinfoBestEffort(t *testing.T) {
 		}
 	}
 }
+
+func TestWithRequestResilience(t *testing.T) {
+	cases := []struct {
+		name string
+		r    *requestResilience
+		want httpcli.Policies
+	}{
+		{"not set", nil, httpcli.DefaultPolicies},
+		{"zero values use the defaults", &requestResilience{}, httpcli.DefaultPolicies},
+		{
+			name: "set",
+			r: &requestResilience{
+				MaxRetries:                     5,
+				MaxRetryDelaySeconds:           10,
+				CircuitBreakerFailureThreshold: 2,
+				CircuitBreakerOpenSeconds:      60,
+			},
+			want: httpcli.Policies{
+				Retry:          httpcli.RetryPolicy{MaxRetries: 5, MinDelay: httpcli.DefaultRetryPolicy.MinDelay, MaxDelay: 10 * time.Second},
+				CircuitBreaker: httpcli.CircuitBreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Minute},
+			},
+		},
+		{
+			name: "disabled",
+			r:    &requestResilience{MaxRetries: -1, CircuitBreakerFailureThreshold: -1},
+			want: httpcli.Policies{
+				Retry:          httpcli.RetryPolicy{MaxRetries: -1, MinDelay: httpcli.DefaultRetryPolicy.MinDelay, MaxDelay: httpcli.DefaultRetryPolicy.MaxDelay},
+				CircuitBreaker: httpcli.CircuitBreakerPolicy{FailureThreshold: -1, OpenTimeout: httpcli.DefaultCircuitBreakerPolicy.OpenTimeout},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var have httpcli.Policies
+			cli := withRequestResilience(httpcli.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				have = httpcli.RequestPolicies(req)
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}), c.r)
+
+			req, _ := http.NewRequest("GET", "https://github.com", nil)
+			if _, err := cli.Do(req); err != nil {
+				t.Fatal(err)
+			}
+			if have != c.want {
+				t.Fatalf("have %+v, want %+v", have, c.want)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 )
@@ -239,7 +240,9 @@ func (s *Server) handleRepoLookup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := s.repoLookup(r.Context(), args)
+	// The frontend is waiting on the lookup to serve a user's request.
+	ctx := ratelimit.WithPriority(r.Context(), ratelimit.PriorityInteractive)
+	result, err := s.repoLookup(ctx, args)
 	if err != nil {
 		if r.Context().Err() != nil {
 			http.Error(w, "request canceled", http.StatusGatewayTimeout)
@@ -311,7 +314,8 @@ func (s *Server) enqueueRepoUpdate(ctx context.Context, req *protocol.RepoUpdate
 }
 
 func (s *Server) handleExternalServiceSync(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithCancel(r.Context())
+	// A site admin is waiting on the external service to be validated.
+	ctx, cancel := context.WithCancel(ratelimit.WithPriority(r.Context(), ratelimit.PriorityInteractive))
 	defer cancel()
 
 	var req protocol.ExternalServiceSyncRequest
@@ -24,6 +24,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/store"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
 var cacheDir = env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
@@ -31,6 +32,26 @@ var cacheSizeMB = env.Get("SEARCHER_CACHE_SIZE_MB", "100000", "maximum size of t
 
 const port = "3181"
 
+// listBlobs returns the regular files of repo at commit.
+func listBlobs(ctx context.Context, repo gitserver.Repo, commit api.CommitID) ([]store.Blob, error) {
+	fis, err := git.ReadDir(ctx, repo, commit, "", true)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]store.Blob, 0, len(fis))
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		oi, ok := fi.Sys().(git.ObjectInfo)
+		if !ok {
+			continue
+		}
+		blobs = append(blobs, store.Blob{Path: fi.Name(), OID: oi.OID().String(), Size: fi.Size()})
+	}
+	return blobs, nil
+}
+
 func main() {
 	env.Lock()
 	env.HandleHelpFlag()
@@ -51,6 +72,15 @@ func main() {
 			FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
 				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
 			},
+			FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+				pathspecs := make([]string, len(paths))
+				for i, p := range paths {
+					// Don't interpret wildcards in file names.
+					pathspecs[i] = ":(literal)" + p
+				}
+				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: pathspecs})
+			},
+			ListBlobs:         listBlobs,
 			Path:              filepath.Join(cacheDir, "searcher-archives"),
 			MaxCacheSizeBytes: cacheSizeBytes,
 		},
@@ -67,6 +67,12 @@ type PatternInfo struct {
 	// when finding matches.
 	IsCaseSensitive bool
 
+	// IsMultiline if true returns the matches of the pattern as
+	// MultilineMatches instead of LineMatches, so that a match which spans
+	// several lines (such as a regular expression containing \n) is
+	// returned once with its exact range.
+	IsMultiline bool
+
 	// ExcludePattern is a pattern that may not match the returned files' paths.
 	// eg '**/node_modules'
 	ExcludePattern string
@@ -105,6 +111,27 @@ type PatternInfo struct {
 
 	// CombyRule is a rule that constrains matching for structural search. It only applies when IsStructuralPat is true.
 	CombyRule string
+
+	// BlameAuthorPatterns is a list of regular expressions that must *all*
+	// match the author of the commit that last changed a matching line, for
+	// the line to be returned. The author is matched as "Name <email>".
+	BlameAuthorPatterns []string
+
+	// BlameExcludeAuthorPattern is a regular expression that may not match
+	// the author of the commit that last changed a matching line.
+	BlameExcludeAuthorPattern string
+
+	// BlameAfter and BlameBefore restrict matching lines to those last
+	// changed after (or before) the given date. They accept any date format
+	// understood by Git, e.g. "2 weeks ago" or "2020-01-31".
+	BlameAfter  string
+	BlameBefore string
+}
+
+// HasBlameFilters returns whether matching lines are filtered by blame
+// information.
+func (p *PatternInfo) HasBlameFilters() bool {
+	return len(p.BlameAuthorPatterns) > 0 || p.BlameExcludeAuthorPattern != "" || p.BlameAfter != "" || p.BlameBefore != ""
 }
 
 func (p *PatternInfo) String() string {
@@ -125,6 +152,9 @@ func (p *PatternInfo) String() string {
 	if p.IsCaseSensitive {
 		args = append(args, "case")
 	}
+	if p.IsMultiline {
+		args = append(args, "multiline")
+	}
 	if !p.PatternMatchesContent {
 		args = append(args, "nocontent")
 	}
@@ -137,6 +167,18 @@ func (p *PatternInfo) String() string {
 	for _, lang := range p.Languages {
 		args = append(args, fmt.Sprintf("lang:%s", lang))
 	}
+	for _, author := range p.BlameAuthorPatterns {
+		args = append(args, fmt.Sprintf("blameauthor:%q", author))
+	}
+	if p.BlameExcludeAuthorPattern != "" {
+		args = append(args, fmt.Sprintf("-blameauthor:%q", p.BlameExcludeAuthorPattern))
+	}
+	if p.BlameAfter != "" {
+		args = append(args, fmt.Sprintf("blameafter:%q", p.BlameAfter))
+	}
+	if p.BlameBefore != "" {
+		args = append(args, fmt.Sprintf("blamebefore:%q", p.BlameBefore))
+	}
 
 	path := "glob"
 	if p.PathPatternsAreRegExps {
@@ -170,6 +212,9 @@ type Response struct {
 type FileMatch struct {
 	Path        string
 	LineMatches []LineMatch
+	// MultilineMatches are the matches of a multiline search (see
+	// PatternInfo.IsMultiline). LineMatches is empty for such searches.
+	MultilineMatches []MultilineMatch `json:",omitempty"`
 	// MatchCount is the number of matches. Different from len(LineMatches), as multiple lines may correspond to one logical match.
 	MatchCount int
 
@@ -194,3 +239,25 @@ type LineMatch struct {
 	// LimitHit is true if OffsetAndLengths may not include all OffsetAndLengths.
 	LimitHit bool
 }
+
+// MultilineMatch is a match which may span several lines.
+type MultilineMatch struct {
+	// Preview is the content of the lines from the start to the end of the
+	// match, without the final newline.
+	Preview string
+
+	// Start is the position of the first character of the match, and End
+	// the position after its last character. A match which ends with a
+	// newline ends at the start of the next line.
+	Start, End Position
+}
+
+// Position is a position in a file.
+type Position struct {
+	// Line is the 0-based line number.
+	Line int
+
+	// Character is the 0-based offset in the line, measured in characters,
+	// not bytes.
+	Character int
+}
@@ -0,0 +1,213 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// blameFilter filters line matches by the commit that last changed each
+// line, as reported by git blame.
+type blameFilter struct {
+	authors       []*regexp.Regexp // must all match
+	excludeAuthor *regexp.Regexp   // may not match, or nil
+	after, before time.Time        // zero if not set
+}
+
+// compileBlameFilter returns the blame filter described by p, or nil if p
+// has no blame filters. Dates are parsed by Git in repo.
+func compileBlameFilter(ctx context.Context, repo gitserver.Repo, p *protocol.PatternInfo) (*blameFilter, error) {
+	if !p.HasBlameFilters() {
+		return nil, nil
+	}
+
+	// Authors are matched with the same case sensitivity as the pattern.
+	flags := ""
+	if !p.IsCaseSensitive {
+		flags = "(?i)"
+	}
+
+	f := &blameFilter{}
+	for _, pattern := range p.BlameAuthorPatterns {
+		re, err := regexp.Compile(flags + pattern)
+		if err != nil {
+			return nil, badRequestError{err.Error()}
+		}
+		f.authors = append(f.authors, re)
+	}
+	if p.BlameExcludeAuthorPattern != "" {
+		re, err := regexp.Compile(flags + p.BlameExcludeAuthorPattern)
+		if err != nil {
+			return nil, badRequestError{err.Error()}
+		}
+		f.excludeAuthor = re
+	}
+
+	var err error
+	if p.BlameAfter != "" {
+		if f.after, err = git.ParseDate(ctx, repo, p.BlameAfter); err != nil {
+			return nil, errors.Wrap(err, "parsing blameafter")
+		}
+	}
+	if p.BlameBefore != "" {
+		if f.before, err = git.ParseDate(ctx, repo, p.BlameBefore); err != nil {
+			return nil, errors.Wrap(err, "parsing blamebefore")
+		}
+	}
+	return f, nil
+}
+
+// matches reports whether a line last changed by author at date is kept.
+func (f *blameFilter) matches(author string, date time.Time) bool {
+	for _, re := range f.authors {
+		if !re.MatchString(author) {
+			return false
+		}
+	}
+	if f.excludeAuthor != nil && f.excludeAuthor.MatchString(author) {
+		return false
+	}
+	if !f.after.IsZero() && !date.After(f.after) {
+		return false
+	}
+	if !f.before.IsZero() && !date.Before(f.before) {
+		return false
+	}
+	return true
+}
+
+// filterFileMatch removes the line matches of fm, a file match in
+// repo@commit, which don't pass f. It reports whether any line matches remain,
+// so path-only matches are never kept.
+func (f *blameFilter) filterFileMatch(ctx context.Context, repo gitserver.Repo, commit api.CommitID, fm *protocol.FileMatch) (bool, error) {
+	if len(fm.LineMatches) == 0 && len(fm.MultilineMatches) == 0 {
+		return false, nil
+	}
+
+	lines, err := blameLines(ctx, repo, commit, fm.Path)
+	if err != nil {
+		return false, err
+	}
+
+	lineMatches := fm.LineMatches[:0]
+	matchCount := 0
+	for _, lm := range fm.LineMatches {
+		// LineNumber is 0-based.
+		if l, ok := lines.at(lm.LineNumber + 1); ok && f.matches(l.author, l.date) {
+			lineMatches = append(lineMatches, lm)
+			matchCount += len(lm.OffsetAndLengths)
+		}
+	}
+	// Multiline matches are filtered by the line they start on.
+	multilineMatches := fm.MultilineMatches[:0]
+	for _, mm := range fm.MultilineMatches {
+		if l, ok := lines.at(mm.Start.Line + 1); ok && f.matches(l.author, l.date) {
+			multilineMatches = append(multilineMatches, mm)
+			matchCount++
+		}
+	}
+	fm.LineMatches = lineMatches
+	fm.MultilineMatches = multilineMatches
+	fm.MatchCount = matchCount
+	return len(lineMatches) > 0 || len(multilineMatches) > 0, nil
+}
+
+// blamedRange is a range of lines last changed by the same commit.
+type blamedRange struct {
+	startLine, endLine int // 1-based, endLine is exclusive
+	author             string
+	date               time.Time
+}
+
+// blamedLines is the blame information of a file, ordered by line.
+type blamedLines []blamedRange
+
+func (b blamedLines) at(line int) (blamedRange, bool) {
+	for _, r := range b {
+		if line >= r.startLine && line < r.endLine {
+			return r, true
+		}
+	}
+	return blamedRange{}, false
+}
+
+// blameLines returns the blame information of the file at path in
+// repo@commit.
+//
+// Blame information is cached by commit and path. The same blob can have a
+// different history at another path or commit, so it can't be cached by blob.
+func blameLines(ctx context.Context, repo gitserver.Repo, commit api.CommitID, path string) (blamedLines, error) {
+	key := string(repo.Name) + "@" + string(commit) + ":" + path
+	if lines, ok := blameCache.get(key); ok {
+		blameCacheCounter.WithLabelValues("hit").Inc()
+		return lines, nil
+	}
+	blameCacheCounter.WithLabelValues("miss").Inc()
+
+	hunks, err := git.BlameFile(ctx, repo, path, &git.BlameOptions{NewestCommit: commit})
+	if err != nil {
+		return nil, err
+	}
+	lines := make(blamedLines, len(hunks))
+	for i, h := range hunks {
+		lines[i] = blamedRange{
+			startLine: h.StartLine,
+			endLine:   h.EndLine,
+			author:    h.Author.Name + " <" + h.Author.Email + ">",
+			date:      h.Author.Date,
+		}
+	}
+	blameCache.set(key, lines)
+	return lines, nil
+}
+
+// blameCache holds the blame information of recently searched files.
+var blameCache = &blamedLinesCache{m: map[string]blamedLines{}}
+
+const maxBlameCacheSize = 10000
+
+type blamedLinesCache struct {
+	mu sync.Mutex
+	m  map[string]blamedLines
+}
+
+func (c *blamedLinesCache) get(key string) (blamedLines, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lines, ok := c.m[key]
+	return lines, ok
+}
+
+func (c *blamedLinesCache) set(key string, lines blamedLines) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.m) >= maxBlameCacheSize {
+		// Evict a random half of the entries.
+		for k := range c.m {
+			if len(c.m) < maxBlameCacheSize/2 {
+				break
+			}
+			delete(c.m, k)
+		}
+	}
+	c.m[key] = lines
+}
+
+var blameCacheCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "searcher",
+	Subsystem: "service",
+	Name:      "blame_cache_total",
+	Help:      "Number of blame cache lookups when filtering matches by blame.",
+}, []string{"type"})
+
+func init() {
+	prometheus.MustRegister(blameCacheCounter)
+}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestBlameFilter(t *testing.T) {
+	defer git.ResetMocks()
+
+	day := func(d int) time.Time { return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC) }
+	var blamed []string
+	git.Mocks.BlameFile = func(path string, opt *git.BlameOptions) ([]*git.Hunk, error) {
+		blamed = append(blamed, path)
+		return []*git.Hunk{
+			{StartLine: 1, EndLine: 3, Author: git.Signature{Name: "Alice", Email: "alice@example.com", Date: day(1)}},
+			{StartLine: 3, EndLine: 4, Author: git.Signature{Name: "Bob", Email: "bob@example.com", Date: day(10)}},
+		}, nil
+	}
+	git.Mocks.ParseDate = func(date string) (time.Time, error) {
+		return day(5), nil
+	}
+
+	matches := func() []protocol.FileMatch {
+		return []protocol.FileMatch{
+			{Path: "a.go", MatchCount: 3, LineMatches: []protocol.LineMatch{
+				{LineNumber: 0, OffsetAndLengths: [][2]int{{0, 1}}},
+				{LineNumber: 2, OffsetAndLengths: [][2]int{{0, 1}, {2, 1}}},
+			}},
+			{Path: "path-only.go"},
+		}
+	}
+
+	tests := []struct {
+		name string
+		p    protocol.PatternInfo
+		want map[string][]int // path -> line numbers
+	}{
+		{
+			name: "author",
+			p:    protocol.PatternInfo{BlameAuthorPatterns: []string{"alice"}},
+			want: map[string][]int{"a.go": {0}},
+		},
+		{
+			name: "case sensitive author",
+			p:    protocol.PatternInfo{BlameAuthorPatterns: []string{"^alice"}, IsCaseSensitive: true},
+			want: map[string][]int{},
+		},
+		{
+			name: "exclude author",
+			p:    protocol.PatternInfo{BlameExcludeAuthorPattern: "alice"},
+			want: map[string][]int{"a.go": {2}},
+		},
+		{
+			name: "author email",
+			p:    protocol.PatternInfo{BlameAuthorPatterns: []string{"@example\\.com>$"}},
+			want: map[string][]int{"a.go": {0, 2}},
+		},
+		{
+			name: "after",
+			p:    protocol.PatternInfo{BlameAfter: "5 days ago"},
+			want: map[string][]int{"a.go": {2}},
+		},
+		{
+			name: "before",
+			p:    protocol.PatternInfo{BlameBefore: "5 days ago"},
+			want: map[string][]int{"a.go": {0}},
+		},
+	}
+	ctx := context.Background()
+	repo := gitserver.Repo{Name: "r"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := compileBlameFilter(ctx, repo, &test.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var filtered []protocol.FileMatch
+			for _, fm := range matches() {
+				keep, err := f.filterFileMatch(ctx, repo, "c", &fm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if keep {
+					filtered = append(filtered, fm)
+				}
+			}
+			got := map[string][]int{}
+			for _, fm := range filtered {
+				count := 0
+				for _, lm := range fm.LineMatches {
+					got[fm.Path] = append(got[fm.Path], lm.LineNumber)
+					count += len(lm.OffsetAndLengths)
+				}
+				if fm.MatchCount != count {
+					t.Errorf("%s: got MatchCount %d, want %d", fm.Path, fm.MatchCount, count)
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	// The blame of a.go is cached by commit and path, and path-only matches
+	// are never blamed.
+	if want := []string{"a.go"}; !reflect.DeepEqual(blamed, want) {
+		t.Errorf("got blamed files %q, want %q", blamed, want)
+	}
+	f, err := compileBlameFilter(ctx, repo, &protocol.PatternInfo{BlameAuthorPatterns: []string{"alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.filterFileMatch(ctx, repo, "c2", &matches()[0]); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.go", "a.go"}; !reflect.DeepEqual(blamed, want) {
+		t.Errorf("got blamed files %q, want %q", blamed, want)
+	}
+
+	if f, err := compileBlameFilter(ctx, repo, &protocol.PatternInfo{Pattern: "x"}); f != nil || err != nil {
+		t.Errorf("got filter %v (error %v), want none", f, err)
+	}
+}
@@ -151,6 +151,7 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	span.SetTag("patternMatchesContent", p.PatternMatchesContent)
 	span.SetTag("patternMatchesPath", p.PatternMatchesPath)
 	span.SetTag("deadline", p.Deadline)
+	span.SetTag("hasBlameFilters", p.HasBlameFilters())
 	defer func(start time.Time) {
 		code := "200"
 		// We often have canceled and timed out requests. We do not want to
@@ -192,6 +193,17 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	if err != nil {
 		return nil, false, false, badRequestError{err.Error()}
 	}
+	bf, err := compileBlameFilter(ctx, p.GitserverRepo(), &p.PatternInfo)
+	if err != nil {
+		return nil, false, false, err
+	}
+	if bf != nil {
+		// Blame filters are applied before the file match limit, so that
+		// filtered out matches don't count towards it.
+		rg.filterMatch = func(ctx context.Context, fm *protocol.FileMatch) (bool, error) {
+			return bf.filterFileMatch(ctx, p.GitserverRepo(), p.Commit, fm)
+		}
+	}
 
 	if p.FetchTimeout == "" {
 		p.FetchTimeout = "500ms"
@@ -212,7 +224,17 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 		return path, zf, err
 	}
 
-	zipPath, zf, err := store.GetZipFileWithRetry(getZf)
+	var (
+		zipPath string
+		zf      *store.ZipFile
+	)
+	if !p.IsStructuralPat && s.Store.ListBlobs != nil && s.Store.FetchTarPaths != nil {
+		// Structural search needs a zip archive on disk, but other searches
+		// can use a view which shares unchanged files between commits.
+		zf, err = s.Store.OpenView(prepareCtx, p.GitserverRepo(), p.Commit)
+	} else {
+		zipPath, zf, err = store.GetZipFileWithRetry(getZf)
+	}
 	if err != nil {
 		return nil, false, false, errors.Wrap(err, "failed to get archive")
 	}
@@ -229,6 +251,21 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 
 	if p.IsStructuralPat {
 		matches, limitHit, err = structuralSearch(ctx, zipPath, p.Pattern, p.CombyRule, p.Languages, p.IncludePatterns, p.Repo)
+		if err == nil && rg.filterMatch != nil {
+			// Structural search has no file match limit of its own, so its
+			// matches are filtered afterwards.
+			filtered := matches[:0]
+			for i := range matches {
+				var keep bool
+				if keep, err = rg.filterMatch(ctx, &matches[i]); err != nil {
+					break
+				}
+				if keep {
+					filtered = append(filtered, matches[i])
+				}
+			}
+			matches = filtered
+		}
 	} else {
 		matches, limitHit, err = regexSearch(ctx, rg, zf, p.FileMatchLimit, p.PatternMatchesContent, p.PatternMatchesPath)
 	}
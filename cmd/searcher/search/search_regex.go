@@ -46,6 +46,10 @@ type readerGrep struct {
 	// ignoreCase if true means we need to do case insensitive matching.
 	ignoreCase bool
 
+	// multiline if true means matches are returned as MultilineMatches
+	// rather than LineMatches.
+	multiline bool
+
 	// transformBuf is reused between file searches to avoid
 	// re-allocating. It is only used if we need to transform the input
 	// before matching. For example we lower case the input in the case of
@@ -56,6 +60,11 @@ type readerGrep struct {
 	// whether a file path matches (and should be searched).
 	matchPath pathmatch.PathMatcher
 
+	// filterMatch, if set, is applied to every file match before it counts
+	// towards the file match limit. It may remove line matches from the file
+	// match, and reports whether the file match is kept.
+	filterMatch func(ctx context.Context, fm *protocol.FileMatch) (bool, error)
+
 	// literalSubstring is used to test if a file is worth considering for
 	// matches. literalSubstring is guaranteed to appear in any match found by
 	// re. It is the output of the longestLiteral function. It is only set if
@@ -124,6 +133,7 @@ func compile(p *protocol.PatternInfo) (*readerGrep, error) {
 	return &readerGrep{
 		re:               re,
 		ignoreCase:       !p.IsCaseSensitive,
+		multiline:        p.IsMultiline,
 		matchPath:        matchPath,
 		literalSubstring: literalSubstring,
 	}, nil
@@ -135,7 +145,9 @@ func (rg *readerGrep) Copy() *readerGrep {
 	return &readerGrep{
 		re:               rg.re,
 		ignoreCase:       rg.ignoreCase,
+		multiline:        rg.multiline,
 		matchPath:        rg.matchPath,
+		filterMatch:      rg.filterMatch,
 		literalSubstring: rg.literalSubstring,
 	}
 }
@@ -156,23 +168,7 @@ func (rg *readerGrep) matchString(s string) bool {
 // LimitHit is true if some matches may not have been included in the result.
 // NOTE: This is not safe to use concurrently.
 func (rg *readerGrep) Find(zf *store.ZipFile, f *store.SrcFile) (matches []protocol.LineMatch, limitHit bool, err error) {
-	// fileMatchBuf is what we run match on, fileBuf is the original
-	// data (for Preview).
-	fileBuf := zf.DataFor(f)
-	fileMatchBuf := fileBuf
-
-	// If we are ignoring case, we transform the input instead of
-	// relying on the regular expression engine which can be
-	// slow. compile has already lowercased the pattern. We also
-	// trade some correctness for perf by using a non-utf8 aware
-	// lowercase function.
-	if rg.ignoreCase {
-		if rg.transformBuf == nil {
-			rg.transformBuf = make([]byte, zf.MaxLen)
-		}
-		fileMatchBuf = rg.transformBuf[:len(fileBuf)]
-		bytesToLowerASCII(fileMatchBuf, fileBuf)
-	}
+	fileBuf, fileMatchBuf := rg.buffers(zf, f)
 
 	// Most files will not have a match and we bound the number of matched
 	// files we return. So we can avoid the overhead of parsing out new lines
@@ -227,6 +223,89 @@ func (rg *readerGrep) Find(zf *store.ZipFile, f *store.SrcFile) (matches []proto
 	return matches, limitHit, nil
 }
 
+// FindMultiline returns a MultilineMatch for each match of rg in reader.
+// Unlike Find, a match which spans several lines is returned once.
+// LimitHit is true if some matches may not have been included in the result.
+// NOTE: This is not safe to use concurrently.
+func (rg *readerGrep) FindMultiline(zf *store.ZipFile, f *store.SrcFile) (matches []protocol.MultilineMatch, limitHit bool, err error) {
+	fileBuf, fileMatchBuf := rg.buffers(zf, f)
+	if !bytes.Contains(fileMatchBuf, rg.literalSubstring) {
+		return nil, false, nil
+	}
+
+	locs := rg.re.FindAllIndex(fileMatchBuf, maxLineMatches+1)
+	if len(locs) > maxLineMatches {
+		locs = locs[:maxLineMatches]
+		limitHit = true
+	}
+
+	// lineNumber is the number of the line starting at lineStart, which
+	// contains the start of the previous match. Matches don't overlap, so
+	// we only need to scan forward from it.
+	lineNumber := 0
+	lineStart := 0
+	for _, match := range locs {
+		start, end := match[0], match[1]
+		lineNumber += bytes.Count(fileMatchBuf[lineStart:start], []byte{'\n'})
+		if idx := bytes.LastIndexByte(fileMatchBuf[lineStart:start], '\n'); idx >= 0 {
+			lineStart += idx + 1
+		}
+
+		endLineNumber := lineNumber + bytes.Count(fileMatchBuf[start:end], []byte{'\n'})
+		endLineStart := lineStart
+		if idx := bytes.LastIndexByte(fileMatchBuf[start:end], '\n'); idx >= 0 {
+			endLineStart = start + idx + 1
+		}
+
+		// The preview ends at the end of the line containing the last
+		// character of the match, excluding its newline.
+		previewEnd := len(fileBuf)
+		last := end
+		if end > start && fileMatchBuf[end-1] == '\n' {
+			last = end - 1
+		}
+		if idx := bytes.IndexByte(fileMatchBuf[last:], '\n'); idx >= 0 {
+			previewEnd = last + idx
+		}
+
+		matches = append(matches, protocol.MultilineMatch{
+			// We copy the preview since the ZipFile data can't be used
+			// once it is closed (see appendMatches).
+			Preview: string(fileBuf[lineStart:previewEnd]),
+			Start: protocol.Position{
+				Line:      lineNumber,
+				Character: utf8.RuneCount(fileBuf[lineStart:start]),
+			},
+			End: protocol.Position{
+				Line:      endLineNumber,
+				Character: utf8.RuneCount(fileBuf[endLineStart:end]),
+			},
+		})
+	}
+	return matches, limitHit, nil
+}
+
+// buffers returns the content of f (for previews) and the content to run
+// the match on. They differ when we are ignoring case.
+func (rg *readerGrep) buffers(zf *store.ZipFile, f *store.SrcFile) (fileBuf, fileMatchBuf []byte) {
+	fileBuf = zf.DataFor(f)
+	fileMatchBuf = fileBuf
+
+	// If we are ignoring case, we transform the input instead of
+	// relying on the regular expression engine which can be
+	// slow. compile has already lowercased the pattern. We also
+	// trade some correctness for perf by using a non-utf8 aware
+	// lowercase function.
+	if rg.ignoreCase {
+		if rg.transformBuf == nil {
+			rg.transformBuf = make([]byte, zf.MaxLen)
+		}
+		fileMatchBuf = rg.transformBuf[:len(fileBuf)]
+		bytesToLowerASCII(fileMatchBuf, fileBuf)
+	}
+	return fileBuf, fileMatchBuf
+}
+
 func hydrateLineNumbers(fileBuf []byte, lastLineNumber, lastMatchIndex, lineStart int, match []int) (lineNumber, matchIndex int) {
 	lineNumber = lastLineNumber + bytes.Count(fileBuf[lastMatchIndex:match[0]], []byte{'\n'})
 	return lineNumber, lineStart
@@ -285,6 +364,16 @@ func appendMatches(matches []protocol.LineMatch, fileBuf []byte, matchLineBuf []
 
 // FindZip is a convenience function to run Find on f.
 func (rg *readerGrep) FindZip(zf *store.ZipFile, f *store.SrcFile) (protocol.FileMatch, error) {
+	if rg.multiline {
+		mm, limitHit, err := rg.FindMultiline(zf, f)
+		return protocol.FileMatch{
+			Path:             f.Name,
+			MultilineMatches: mm,
+			MatchCount:       len(mm),
+			LimitHit:         limitHit,
+		}, err
+	}
+
 	lm, limitHit, err := rg.Find(zf, f)
 	return protocol.FileMatch{
 		Path:        f.Name,
@@ -342,6 +431,10 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMat
 		// so is effectively matching only on file paths).
 		for _, f := range files {
 			if rg.matchPath.MatchPath(f.Name) && rg.matchString(f.Name) {
+				if rg.filterMatch != nil {
+					// Path matches have no lines to filter.
+					continue
+				}
 				if len(matches) < fileMatchLimit {
 					matches = append(matches, protocol.FileMatch{Path: f.Name})
 				} else {
@@ -403,7 +496,7 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMat
 					})
 					return
 				}
-				match := len(fm.LineMatches) > 0
+				match := len(fm.LineMatches) > 0 || len(fm.MultilineMatches) > 0
 				if !match && patternMatchesPaths {
 					// Try matching against the file path.
 					match = rg.matchString(f.Name)
@@ -411,6 +504,16 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMat
 						fm.Path = f.Name
 					}
 				}
+				if match && rg.filterMatch != nil {
+					match, err = rg.filterMatch(ctx, &fm)
+					if err != nil {
+						wgErrOnce.Do(func() {
+							wgErr = err
+							cancel()
+						})
+						return
+					}
+				}
 				if match {
 					matchesmu.Lock()
 					if len(matches) < fileMatchLimit {
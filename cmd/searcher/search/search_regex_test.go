@@ -475,6 +475,75 @@ func TestMaxMatches(t *testing.T) {
 	}
 }
 
+func TestFindMultiline(t *testing.T) {
+	const content = "package main\n\nfunc main() {\n\tfmt.Println(\"héllo\")\n}\n"
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "main.go", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(content))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zf, err := store.MockZipFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		arg  protocol.PatternInfo
+		want []protocol.MultilineMatch
+	}{{
+		arg: protocol.PatternInfo{Pattern: `main\(\) \{\n\s*fmt`, IsRegExp: true},
+		want: []protocol.MultilineMatch{{
+			Preview: "func main() {\n\tfmt.Println(\"héllo\")",
+			Start:   protocol.Position{Line: 2, Character: 5},
+			End:     protocol.Position{Line: 3, Character: 4},
+		}},
+	}, {
+		arg: protocol.PatternInfo{Pattern: `LLO"\)\n}\n`, IsRegExp: true},
+		want: []protocol.MultilineMatch{{
+			Preview: "\tfmt.Println(\"héllo\")\n}",
+			Start:   protocol.Position{Line: 3, Character: 16},
+			End:     protocol.Position{Line: 5, Character: 0},
+		}},
+	}, {
+		arg: protocol.PatternInfo{Pattern: "main", IsCaseSensitive: true},
+		want: []protocol.MultilineMatch{{
+			Preview: "package main",
+			Start:   protocol.Position{Line: 0, Character: 8},
+			End:     protocol.Position{Line: 0, Character: 12},
+		}, {
+			Preview: "func main() {",
+			Start:   protocol.Position{Line: 2, Character: 5},
+			End:     protocol.Position{Line: 2, Character: 9},
+		}},
+	}}
+	for _, c := range cases {
+		c.arg.IsMultiline = true
+		rg, err := compile(&c.arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fm, err := rg.FindZip(zf, &zf.Files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fm.LineMatches) != 0 {
+			t.Errorf("%v: got line matches %v, want none", c.arg.String(), fm.LineMatches)
+		}
+		if !reflect.DeepEqual(fm.MultilineMatches, c.want) {
+			t.Errorf("%v: got %+v, want %+v", c.arg.String(), fm.MultilineMatches, c.want)
+		}
+		if fm.MatchCount != len(c.want) {
+			t.Errorf("%v: got match count %d, want %d", c.arg.String(), fm.MatchCount, len(c.want))
+		}
+	}
+}
+
 // Tests that:
 //
 // - IncludePatterns can match the path in any order
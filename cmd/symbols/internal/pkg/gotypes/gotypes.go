@@ -0,0 +1,165 @@
+// Package gotypes extracts the named types declared in Go source files,
+// together with their method sets.
+package gotypes
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+)
+
+// IsSource reports whether the file at the given path is a Go source file
+// whose types are collected. Tests are not collected.
+func IsSource(filePath string) bool {
+	return strings.HasSuffix(filePath, ".go") && !strings.HasSuffix(filePath, "_test.go")
+}
+
+type typeKey struct {
+	pkg  string // the package directory
+	name string
+}
+
+// Collector collects the types declared in the Go source files of a
+// repository. Methods may be declared in a different file than their
+// receiver type, so the method sets are only known once all files of a
+// package are added.
+type Collector struct {
+	types   map[typeKey]*protocol.GoType
+	methods map[typeKey][]string
+}
+
+// NewCollector returns a new, empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		types:   map[typeKey]*protocol.GoType{},
+		methods: map[typeKey][]string{},
+	}
+}
+
+// Add adds the types and methods declared in the Go source file at the
+// given path.
+func (c *Collector) Add(filePath string, data []byte) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filePath, data, 0)
+	if err != nil {
+		return err
+	}
+
+	pkg := path.Dir(filePath)
+	if pkg == "." {
+		pkg = ""
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if spec.Assign.IsValid() {
+					// Aliases don't declare a new type.
+					continue
+				}
+				pos := fset.Position(spec.Name.Pos())
+				t := &protocol.GoType{
+					Name:      spec.Name.Name,
+					Package:   pkg,
+					Path:      filePath,
+					Line:      pos.Line - 1,
+					Character: utf16Column(data, pos),
+				}
+				if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+					t.Interface = true
+					for _, field := range iface.Methods.List {
+						for _, name := range field.Names {
+							t.Methods = append(t.Methods, name.Name)
+						}
+						if len(field.Names) == 0 {
+							if embed := typeName(field.Type); embed != "" {
+								t.Embeds = append(t.Embeds, embed)
+							}
+						}
+					}
+				}
+				c.types[typeKey{pkg: pkg, name: t.Name}] = t
+			}
+
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			for {
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				} else if paren, ok := recv.(*ast.ParenExpr); ok {
+					recv = paren.X
+				} else {
+					break
+				}
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				key := typeKey{pkg: pkg, name: ident.Name}
+				c.methods[key] = append(c.methods[key], decl.Name.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Types returns the collected types, ordered by package and name.
+func (c *Collector) Types() []protocol.GoType {
+	types := make([]protocol.GoType, 0, len(c.types))
+	for key, t := range c.types {
+		t := *t
+		if !t.Interface {
+			t.Methods = append([]string(nil), c.methods[key]...)
+			sort.Strings(t.Methods)
+		}
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Package != types[j].Package {
+			return types[i].Package < types[j].Package
+		}
+		return types[i].Name < types[j].Name
+	})
+	return types
+}
+
+// typeName returns the name of the (possibly qualified) named type expr,
+// such as "Reader" or "io.Reader".
+func typeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			return x.Name + "." + expr.Sel.Name
+		}
+	}
+	return ""
+}
+
+// utf16Column returns the zero-based column of pos in UTF-16 code units,
+// as used by LSP.
+func utf16Column(data []byte, pos token.Position) int {
+	lineStart := pos.Offset - (pos.Column - 1)
+	n := 0
+	for _, r := range string(data[lineStart:pos.Offset]) {
+		if r > 0xFFFF {
+			// Encoded as a surrogate pair.
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package gotypes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	files := map[string]string{
+		"store/store.go": `package store
+
+import "io"
+
+// Store stores things.
+type Store interface {
+	io.Closer
+	Getter
+	Put(key, value string) error
+}
+
+type Getter interface{ Get(key string) (string, error) }
+
+type (
+	memory struct{ m map[string]string }
+	Alias  = memory
+)
+
+func (m *memory) Get(key string) (string, error) { return m.m[key], nil }
+`,
+		"store/memory.go": `package store
+
+func (m *memory) Put(key, value string) error { m.m[key] = value; return nil }
+func (memory) Close() error                   { return nil }
+func helper()                                 {}
+`,
+		"main.go": "package main\n\n/* ü */ type 𝔸 int\n",
+	}
+	for path, data := range files {
+		if err := c.Add(path, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Add("invalid.go", []byte("package")); err == nil {
+		t.Error("got no error for invalid file")
+	}
+
+	want := []protocol.GoType{
+		{Name: "𝔸", Package: "", Path: "main.go", Line: 2, Character: 13},
+		{Name: "Getter", Package: "store", Path: "store/store.go", Line: 11, Character: 5, Interface: true, Methods: []string{"Get"}},
+		{Name: "Store", Package: "store", Path: "store/store.go", Line: 5, Character: 5, Interface: true, Methods: []string{"Put"}, Embeds: []string{"io.Closer", "Getter"}},
+		{Name: "memory", Package: "store", Path: "store/store.go", Line: 14, Character: 1, Methods: []string{"Close", "Get", "Put"}},
+	}
+	if got := c.Types(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestIsSource(t *testing.T) {
+	for path, want := range map[string]bool{"a.go": true, "a/b.go": true, "a_test.go": false, "a.gox": false} {
+		if got := IsSource(path); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package symbols
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/gotypes"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// goTypesVersion is the version of the cached Go types. It must be bumped
+// when the format changes.
+const goTypesVersion = 1
+
+func (s *Service) handleGoTypes(w http.ResponseWriter, r *http.Request) {
+	var args protocol.GoTypesArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.goTypes(r.Context(), args)
+	if err != nil {
+		if err == context.Canceled && r.Context().Err() == context.Canceled {
+			return // client went away
+		}
+		log15.Error("Listing Go types failed", "args", args, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// goTypes returns the Go types declared in the repo@commit specified in
+// args. The types are extracted once and then stored in the disk cache.
+func (s *Service) goTypes(ctx context.Context, args protocol.GoTypesArgs) (result *protocol.GoTypesResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	span, ctx := ot.StartSpanFromContext(ctx, "goTypes")
+	span.SetTag("repo", args.Repo)
+	span.SetTag("commitID", args.CommitID)
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	key := fmt.Sprintf("gotypes-%d-%s@%s", goTypesVersion, args.Repo, args.CommitID)
+	f, err := s.cache.OpenWithPath(ctx, key, func(fetcherCtx context.Context, tempFile string) error {
+		result, err := s.extractGoTypes(fetcherCtx, args)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(tempFile, data, 0600)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer f.File.Close()
+
+	if err := json.NewDecoder(f.File).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Service) extractGoTypes(ctx context.Context, args protocol.GoTypesArgs) (*protocol.GoTypesResult, error) {
+	files, errChan, err := s.fetchRepositoryArchive(ctx, args.Repo, args.CommitID)
+	if err != nil {
+		return nil, err
+	}
+
+	c := gotypes.NewCollector()
+	for file := range files {
+		if !gotypes.IsSource(file.path) {
+			continue
+		}
+		if err := c.Add(file.path, file.data); err != nil {
+			log15.Debug("Skipping invalid Go file.", "repo", args.Repo, "commitID", args.CommitID, "path", file.path, "error", err)
+		}
+	}
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+	return &protocol.GoTypesResult{Types: c.Types()}, nil
+}
@@ -81,6 +81,7 @@ func (s *Service) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/gotypes", s.handleGoTypes)
 	mux.HandleFunc("/healthz", s.handleHealthCheck)
 
 	return mux
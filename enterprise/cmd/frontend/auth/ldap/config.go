@@ -0,0 +1,89 @@
+package ldap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+var mockGetProviderValue *provider
+
+// getProvider looks up the registered LDAP auth provider with the given ID.
+func getProvider(id string) *provider {
+	if mockGetProviderValue != nil {
+		return mockGetProviderValue
+	}
+	p, _ := providers.GetProviderByConfigID(providers.ConfigID{Type: providerType, ID: id}).(*provider)
+	return p
+}
+
+func handleGetProvider(w http.ResponseWriter, id string) (p *provider, handled bool) {
+	p = getProvider(id)
+	if p == nil {
+		log15.Error("No LDAP auth provider found with ID.", "id", id)
+		http.Error(w, "Misconfigured LDAP auth provider.", http.StatusInternalServerError)
+		return nil, true
+	}
+	return p, false
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems conf.Problems) {
+	seen := map[string]int{}
+	for i, p := range c.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		id := providerConfigID(p.Ldap)
+		if j, ok := seen[id]; ok {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d is duplicate of index %d, ignoring", i, j)))
+			continue
+		}
+		seen[id] = i
+
+		if p.Ldap.StartTLS && strings.HasPrefix(p.Ldap.Url, "ldaps://") {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: startTLS can't be used with an ldaps:// URL (use an ldap:// URL)", i)))
+		}
+		if filter := userSearchFilter(p.Ldap); !strings.Contains(filter, "{username}") {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: userSearchFilter must contain {username}", i)))
+		} else if _, err := goldap.CompileFilter(replacePlaceholders(filter, map[string]string{"username": "x"})); err != nil {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: invalid userSearchFilter: %s", i, err)))
+		}
+		if p.Ldap.GroupSearchBase != "" {
+			filter := replacePlaceholders(groupSearchFilter(p.Ldap), map[string]string{"dn": "x", "username": "x"})
+			if _, err := goldap.CompileFilter(filter); err != nil {
+				problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: invalid groupSearchFilter: %s", i, err)))
+			}
+		} else if len(p.Ldap.GroupOrgs) > 0 {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: groupOrgs requires groupSearchBase to be set", i)))
+		}
+	}
+	return problems
+}
+
+// providerConfigID produces a semi-stable identifier for an LDAP auth provider config object. It
+// is used to tell the sign-in endpoint which LDAP auth provider to use when there are multiple.
+// Its value is never persisted, and it must be deterministic.
+func providerConfigID(pc *schema.LDAPAuthProvider) string {
+	if pc.ConfigID != "" {
+		return pc.ConfigID
+	}
+	data, err := json.Marshal(pc)
+	if err != nil {
+		panic(err)
+	}
+	b := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(b[:16])
+}
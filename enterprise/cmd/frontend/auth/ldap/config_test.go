@@ -0,0 +1,76 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestValidateCustom(t *testing.T) {
+	tests := map[string]struct {
+		input        conf.Unified
+		wantProblems conf.Problems
+	}{
+		"valid": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{{Ldap: testConfig()}},
+			}},
+		},
+		"duplicates": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("LDAP auth provider at index 1 is duplicate of index 0"),
+		},
+		"startTLS with ldaps": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://x", StartTLS: true, UserSearchBase: "dc=x"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("startTLS can't be used with an ldaps:// URL"),
+		},
+		"userSearchFilter without placeholder": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x", UserSearchFilter: "(uid=alice)"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("userSearchFilter must contain {username}"),
+		},
+		"invalid filters": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x", UserSearchFilter: "uid={username}", GroupSearchBase: "dc=x", GroupSearchFilter: "(member={dn}"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("invalid userSearchFilter", "invalid groupSearchFilter"),
+		},
+		"groupOrgs without groupSearchBase": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x", GroupOrgs: map[string]string{"g": "o"}}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("groupOrgs requires groupSearchBase"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.TestValidator(t, test.input, validateConfig, test.wantProblems)
+		})
+	}
+}
+
+func TestProviderConfigID(t *testing.T) {
+	p := schema.LDAPAuthProvider{Url: "ldap://x", GroupOrgs: map[string]string{"a": "b", "c": "d"}}
+	id1 := providerConfigID(&p)
+	id2 := providerConfigID(&p)
+	if id1 != id2 {
+		t.Errorf("id1 (%q) != id2 (%q)", id1, id2)
+	}
+}
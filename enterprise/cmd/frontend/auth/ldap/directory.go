@@ -0,0 +1,225 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// conn is the subset of the methods of a connection to an LDAP server (*goldap.Conn) that is
+// needed to authenticate users. Tests use an in-process implementation.
+type conn interface {
+	Bind(username, password string) error
+	UnauthenticatedBind(username string) error
+	Search(*goldap.SearchRequest) (*goldap.SearchResult, error)
+	Close()
+}
+
+// dial connects to the LDAP server. It is a variable so that tests can mock it.
+var dial = func(c *schema.LDAPAuthProvider) (conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if u, err := url.Parse(c.Url); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+	lc, err := goldap.DialURL(c.Url, goldap.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}), goldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	lc.SetTimeout(30 * time.Second)
+	if c.StartTLS {
+		if err := lc.StartTLS(tlsConfig); err != nil {
+			lc.Close()
+			return nil, errors.Wrap(err, "StartTLS")
+		}
+	}
+	return lc, nil
+}
+
+// errInvalidCredentials is returned by authenticate when the username or password is wrong.
+var errInvalidCredentials = errors.New("invalid LDAP username or password")
+
+// ldapUser is the directory entry of an authenticated user.
+type ldapUser struct {
+	ID          string   `json:"id"` // the entryUUID (or objectGUID on Active Directory) of the entry
+	DN          string   `json:"dn"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+}
+
+// authenticate checks the username and password against the LDAP server and returns the user's
+// directory entry (and, if groupSearchBase is set, the user's groups). It returns
+// errInvalidCredentials if the user doesn't exist or the password is wrong.
+func authenticate(c *schema.LDAPAuthProvider, username, password string) (*ldapUser, error) {
+	// 🚨 SECURITY: A simple bind with a DN and an empty password is an unauthenticated bind
+	// (RFC 4513 section 5.1.2), which many LDAP servers allow without checking any password.
+	if username == "" || password == "" {
+		return nil, errInvalidCredentials
+	}
+
+	lc, err := dial(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "connect to LDAP server")
+	}
+	defer lc.Close()
+
+	if err := bindServiceAccount(lc, c); err != nil {
+		return nil, err
+	}
+	usernameAttr, emailAttr, displayNameAttr := usernameAttribute(c), emailAttribute(c), displayNameAttribute(c)
+	res, err := lc.Search(goldap.NewSearchRequest(
+		c.UserSearchBase, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		replacePlaceholders(userSearchFilter(c), map[string]string{"username": username}),
+		[]string{entryUUIDAttribute, objectGUIDAttribute, usernameAttr, emailAttr, displayNameAttr},
+		nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "search for user")
+	}
+	switch len(res.Entries) {
+	case 0:
+		return nil, errInvalidCredentials
+	case 1:
+	default:
+		return nil, fmt.Errorf("userSearchFilter matched %d entries for username %q (it must match exactly 1)", len(res.Entries), username)
+	}
+	entry := res.Entries[0]
+
+	// 🚨 SECURITY: Check the password by binding as the user.
+	if err := lc.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "bind as user")
+	}
+
+	id := entryID(entry)
+	if id == "" {
+		return nil, fmt.Errorf("the LDAP entry %q of username %q has no %s or %s attribute", entry.DN, username, entryUUIDAttribute, objectGUIDAttribute)
+	}
+	u := &ldapUser{
+		ID:          id,
+		DN:          entry.DN,
+		Username:    entry.GetAttributeValue(usernameAttr),
+		Email:       entry.GetAttributeValue(emailAttr),
+		DisplayName: entry.GetAttributeValue(displayNameAttr),
+	}
+	if u.Username == "" {
+		u.Username = username
+	}
+
+	if c.GroupSearchBase != "" {
+		// Search for groups as the service account, because users may not be allowed to.
+		if err := bindServiceAccount(lc, c); err != nil {
+			return nil, err
+		}
+		groupNameAttr := groupNameAttribute(c)
+		res, err := lc.Search(goldap.NewSearchRequest(
+			c.GroupSearchBase, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+			replacePlaceholders(groupSearchFilter(c), map[string]string{"dn": u.DN, "username": u.Username}),
+			[]string{groupNameAttr},
+			nil,
+		))
+		if err != nil {
+			return nil, errors.Wrap(err, "search for groups")
+		}
+		for _, entry := range res.Entries {
+			if name := entry.GetAttributeValue(groupNameAttr); name != "" {
+				u.Groups = append(u.Groups, name)
+			}
+		}
+	}
+	return u, nil
+}
+
+// bindServiceAccount binds as the service account (or anonymously if bindDN is not set).
+func bindServiceAccount(lc conn, c *schema.LDAPAuthProvider) error {
+	var err error
+	if c.BindDN == "" {
+		// Conn.Bind refuses empty passwords, so an anonymous bind must be requested explicitly.
+		err = lc.UnauthenticatedBind("")
+	} else {
+		err = lc.Bind(c.BindDN, c.BindPassword)
+	}
+	return errors.Wrap(err, "bind as service account")
+}
+
+const (
+	entryUUIDAttribute  = "entryUUID"
+	objectGUIDAttribute = "objectGUID"
+)
+
+// entryID returns a stable identifier of the directory entry, which (unlike its DN) doesn't
+// change when the entry is renamed or moved. It is the entryUUID attribute (RFC 4530), or the
+// objectGUID attribute on Active Directory, which doesn't support entryUUID.
+func entryID(e *goldap.Entry) string {
+	if id := e.GetAttributeValue(entryUUIDAttribute); id != "" {
+		return strings.ToLower(id)
+	}
+	if b := e.GetRawAttributeValue(objectGUIDAttribute); len(b) == 16 {
+		// The first 3 fields of the GUID are little-endian.
+		return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+			binary.LittleEndian.Uint32(b[0:4]), binary.LittleEndian.Uint16(b[4:6]), binary.LittleEndian.Uint16(b[6:8]), b[8:10], b[10:])
+	}
+	return ""
+}
+
+// replacePlaceholders replaces each "{name}" in the LDAP filter with the escaped value.
+func replacePlaceholders(filter string, values map[string]string) string {
+	oldnew := make([]string, 0, 2*len(values))
+	for name, value := range values {
+		oldnew = append(oldnew, "{"+name+"}", goldap.EscapeFilter(value))
+	}
+	return strings.NewReplacer(oldnew...).Replace(filter)
+}
+
+func userSearchFilter(c *schema.LDAPAuthProvider) string {
+	if c.UserSearchFilter != "" {
+		return c.UserSearchFilter
+	}
+	return "(uid={username})"
+}
+
+func usernameAttribute(c *schema.LDAPAuthProvider) string {
+	if c.UsernameAttribute != "" {
+		return c.UsernameAttribute
+	}
+	return "uid"
+}
+
+func emailAttribute(c *schema.LDAPAuthProvider) string {
+	if c.EmailAttribute != "" {
+		return c.EmailAttribute
+	}
+	return "mail"
+}
+
+func displayNameAttribute(c *schema.LDAPAuthProvider) string {
+	if c.DisplayNameAttribute != "" {
+		return c.DisplayNameAttribute
+	}
+	return "cn"
+}
+
+func groupSearchFilter(c *schema.LDAPAuthProvider) string {
+	if c.GroupSearchFilter != "" {
+		return c.GroupSearchFilter
+	}
+	return "(member={dn})"
+}
+
+func groupNameAttribute(c *schema.LDAPAuthProvider) string {
+	if c.GroupNameAttribute != "" {
+		return c.GroupNameAttribute
+	}
+	return "cn"
+}
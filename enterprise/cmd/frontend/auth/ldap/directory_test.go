@@ -0,0 +1,279 @@
+package ldap
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// fakeDirectory is an in-process stand-in for an LDAP server. It supports simple binds and
+// subtree searches with AND, OR, NOT, equality and presence filters.
+type fakeDirectory struct {
+	entries   []*goldap.Entry
+	passwords map[string]string // DN -> password
+
+	binds []string // DNs of successful binds
+}
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{
+		entries: []*goldap.Entry{
+			goldap.NewEntry("cn=sourcegraph,ou=services,dc=example,dc=com", nil),
+			goldap.NewEntry("uid=alice,ou=people,dc=example,dc=com", map[string][]string{
+				"objectClass": {"person"},
+				"entryUUID":   {"5f2b6e1c-6a3d-4c1e-9d2e-2f6a4c1b7e10"},
+				"uid":         {"alice"},
+				"mail":        {"alice@example.com"},
+				"cn":          {"Alice Smith"},
+			}),
+			goldap.NewEntry("uid=bob,ou=people,dc=example,dc=com", map[string][]string{
+				"objectClass": {"person"},
+				"entryUUID":   {"8d1c2a3b-4e5f-4a6b-8c7d-9e0f1a2b3c4d"},
+				"uid":         {"bob"},
+				"cn":          {"Bob"},
+			}),
+			goldap.NewEntry("cn=engineering,ou=groups,dc=example,dc=com", map[string][]string{
+				"cn":     {"engineering"},
+				"member": {"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+			}),
+			goldap.NewEntry("cn=admins,ou=groups,dc=example,dc=com", map[string][]string{
+				"cn":     {"admins"},
+				"member": {"uid=alice,ou=people,dc=example,dc=com"},
+			}),
+		},
+		passwords: map[string]string{
+			"cn=sourcegraph,ou=services,dc=example,dc=com": "servicepw",
+			"uid=alice,ou=people,dc=example,dc=com":        "alicepw",
+			"uid=bob,ou=people,dc=example,dc=com":          "bobpw",
+		},
+	}
+}
+
+func (d *fakeDirectory) Bind(dn, password string) error {
+	// Like *goldap.Conn, refuse unauthenticated binds (see UnauthenticatedBind).
+	if password == "" {
+		return goldap.NewError(goldap.ErrorEmptyPassword, errors.New("ldap: empty password not allowed by the client"))
+	}
+	if want, ok := d.passwords[dn]; !ok || password != want {
+		return goldap.NewError(goldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	d.binds = append(d.binds, dn)
+	return nil
+}
+
+func (d *fakeDirectory) UnauthenticatedBind(dn string) error {
+	d.binds = append(d.binds, "")
+	return nil
+}
+
+func (d *fakeDirectory) Search(req *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	filter, err := goldap.CompileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	var res goldap.SearchResult
+	for _, e := range d.entries {
+		if strings.HasSuffix(strings.ToLower(e.DN), ","+strings.ToLower(req.BaseDN)) && matchFilter(e, filter) {
+			res.Entries = append(res.Entries, e)
+		}
+	}
+	return &res, nil
+}
+
+func (d *fakeDirectory) Close() {}
+
+func matchFilter(e *goldap.Entry, f *ber.Packet) bool {
+	switch f.Tag {
+	case goldap.FilterAnd:
+		for _, c := range f.Children {
+			if !matchFilter(e, c) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, c := range f.Children {
+			if matchFilter(e, c) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return !matchFilter(e, f.Children[0])
+	case goldap.FilterEqualityMatch:
+		attr, value := f.Children[0].Value.(string), f.Children[1].Value.(string)
+		for _, v := range e.GetEqualFoldAttributeValues(attr) {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterPresent:
+		return len(e.GetEqualFoldAttributeValues(f.Value.(string))) > 0
+	}
+	panic("fakeDirectory: unsupported filter " + goldap.FilterMap[uint64(f.Tag)])
+}
+
+func removeAttribute(e *goldap.Entry, name string) {
+	attrs := e.Attributes[:0]
+	for _, a := range e.Attributes {
+		if a.Name != name {
+			attrs = append(attrs, a)
+		}
+	}
+	e.Attributes = attrs
+}
+
+func mockDial(t *testing.T, d *fakeDirectory) {
+	orig := dial
+	dial = func(*schema.LDAPAuthProvider) (conn, error) { return d, nil }
+	t.Cleanup(func() { dial = orig })
+}
+
+func testConfig() *schema.LDAPAuthProvider {
+	return &schema.LDAPAuthProvider{
+		Type:            providerType,
+		Url:             "ldap://ldap.example.com",
+		BindDN:          "cn=sourcegraph,ou=services,dc=example,dc=com",
+		BindPassword:    "servicepw",
+		UserSearchBase:  "ou=people,dc=example,dc=com",
+		GroupSearchBase: "ou=groups,dc=example,dc=com",
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Run("valid credentials", func(t *testing.T) {
+		d := newFakeDirectory()
+		mockDial(t, d)
+		u, err := authenticate(testConfig(), "alice", "alicepw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &ldapUser{
+			ID:          "5f2b6e1c-6a3d-4c1e-9d2e-2f6a4c1b7e10",
+			DN:          "uid=alice,ou=people,dc=example,dc=com",
+			Username:    "alice",
+			Email:       "alice@example.com",
+			DisplayName: "Alice Smith",
+			Groups:      []string{"engineering", "admins"},
+		}
+		if !reflect.DeepEqual(u, want) {
+			t.Errorf("got %+v, want %+v", u, want)
+		}
+		wantBinds := []string{
+			"cn=sourcegraph,ou=services,dc=example,dc=com",
+			"uid=alice,ou=people,dc=example,dc=com",
+			"cn=sourcegraph,ou=services,dc=example,dc=com",
+		}
+		if !reflect.DeepEqual(d.binds, wantBinds) {
+			t.Errorf("got binds %q, want %q", d.binds, wantBinds)
+		}
+	})
+
+	t.Run("anonymous bind", func(t *testing.T) {
+		d := newFakeDirectory()
+		mockDial(t, d)
+		c := testConfig()
+		c.BindDN, c.BindPassword = "", ""
+		if _, err := authenticate(c, "alice", "alicepw"); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"", "uid=alice,ou=people,dc=example,dc=com", ""}; !reflect.DeepEqual(d.binds, want) {
+			t.Errorf("got binds %q, want %q", d.binds, want)
+		}
+	})
+
+	t.Run("objectGUID on Active Directory", func(t *testing.T) {
+		d := newFakeDirectory()
+		mockDial(t, d)
+		alice := d.entries[1]
+		removeAttribute(alice, "entryUUID")
+		alice.Attributes = append(alice.Attributes, &goldap.EntryAttribute{
+			Name:       "objectGUID",
+			Values:     []string{"x"},
+			ByteValues: [][]byte{{0x78, 0x56, 0x34, 0x12, 0x34, 0x12, 0x78, 0x56, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}},
+		})
+		u, err := authenticate(testConfig(), "alice", "alicepw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "12345678-1234-5678-1234-56789abcdef0"; u.ID != want {
+			t.Errorf("got ID %q, want %q", u.ID, want)
+		}
+	})
+
+	t.Run("no stable ID", func(t *testing.T) {
+		d := newFakeDirectory()
+		mockDial(t, d)
+		alice := d.entries[1]
+		removeAttribute(alice, "entryUUID")
+		if _, err := authenticate(testConfig(), "alice", "alicepw"); err == nil || err == errInvalidCredentials {
+			t.Errorf("got error %v, want a missing ID error", err)
+		}
+	})
+
+	t.Run("custom filters and attributes", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		c := testConfig()
+		c.UserSearchFilter = "(&(objectClass=person)(|(uid={username})(mail={username})))"
+		c.DisplayNameAttribute = "uid"
+		c.GroupSearchFilter = "(&(member={dn})(!(cn=admins)))"
+		u, err := authenticate(c, "alice@example.com", "alicepw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Username != "alice" || u.DisplayName != "alice" || !reflect.DeepEqual(u.Groups, []string{"engineering"}) {
+			t.Errorf("unexpected user %+v", u)
+		}
+	})
+
+	t.Run("no groups without groupSearchBase", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		c := testConfig()
+		c.GroupSearchBase = ""
+		u, err := authenticate(c, "bob", "bobpw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Email != "" || u.Groups != nil {
+			t.Errorf("unexpected user %+v", u)
+		}
+	})
+
+	for name, test := range map[string]struct{ username, password string }{
+		"wrong password":   {"alice", "bobpw"},
+		"empty password":   {"alice", ""},
+		"unknown user":     {"carol", "alicepw"},
+		"filter injection": {"*", "alicepw"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockDial(t, newFakeDirectory())
+			if _, err := authenticate(testConfig(), test.username, test.password); err != errInvalidCredentials {
+				t.Errorf("got error %v, want %v", err, errInvalidCredentials)
+			}
+		})
+	}
+
+	t.Run("ambiguous user", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		c := testConfig()
+		c.UserSearchFilter = "(|(uid={username})(objectClass=person))"
+		if _, err := authenticate(c, "alice", "alicepw"); err == nil || err == errInvalidCredentials {
+			t.Errorf("got error %v, want a matched-multiple-entries error", err)
+		}
+	})
+
+	t.Run("wrong service account password", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		c := testConfig()
+		c.BindPassword = "x"
+		if _, err := authenticate(c, "alice", "alicepw"); err == nil || err == errInvalidCredentials {
+			t.Errorf("got error %v, want a service account bind error", err)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+// Package ldap implements auth via LDAP (including Active Directory).
+package ldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+)
+
+// All LDAP endpoints are under this path prefix.
+const authPrefix = auth.AuthURLPrefix + "/ldap"
+
+// Middleware is middleware for LDAP authentication, adding the sign-in endpoint
+// ("/.auth/ldap/login") under the auth path prefix.
+//
+// Unlike the SSO auth providers, there is no redirect flow: the sign-in page posts the username and
+// password that the user entered to the sign-in endpoint, which checks them against the LDAP server
+// and creates a new session.
+//
+// 🚨 SECURITY
+var Middleware = &auth.Middleware{
+	API: func(next http.Handler) http.Handler {
+		return next
+	},
+	App: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == authPrefix+"/login" {
+				handleSignIn(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	},
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleSignIn accepts a POST containing LDAP username-password credentials and authenticates the
+// current session if the credentials are valid.
+//
+// 🚨 SECURITY
+func handleSignIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusBadRequest)
+		return
+	}
+	// 🚨 SECURITY: Auth middleware runs before the CSRF middleware, so prevent login CSRF by
+	// requiring a header that cross-origin requests can't set (without a CORS preflight).
+	if r.Header.Get("X-Requested-With") != "Sourcegraph" {
+		http.Error(w, "Missing X-Requested-With header.", http.StatusForbidden)
+		return
+	}
+
+	p, handled := handleGetProvider(w, r.URL.Query().Get("pc"))
+	if handled {
+		return
+	}
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Could not decode request body", http.StatusBadRequest)
+		return
+	}
+
+	u, err := authenticate(&p.config, creds.Username, creds.Password)
+	if err == errInvalidCredentials {
+		log15.Warn("LDAP auth failed: invalid credentials.", "username", creds.Username)
+		http.Error(w, "Authentication failed", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		log15.Error("LDAP auth failed.", "username", creds.Username, "error", err)
+		http.Error(w, "Authentication failed. The LDAP server could not be reached or is misconfigured. Check the logs for more details.", http.StatusInternalServerError)
+		return
+	}
+
+	actr, safeErrMsg, err := getOrCreateUser(r.Context(), p, u)
+	if err != nil {
+		log15.Error("LDAP auth failed: error looking up or creating user.", "username", creds.Username, "error", err, "userErr", safeErrMsg)
+		http.Error(w, safeErrMsg, http.StatusInternalServerError)
+		return
+	}
+
+	// Write the session cookie
+	if err := session.SetActor(w, r, actr, 0); err != nil {
+		log15.Error("LDAP auth failed: could not create new user session.", "error", err)
+		http.Error(w, "Could not create new user session", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,148 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestMiddleware(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+
+	defer licensing.TestingSkipFeatureChecks()()
+
+	mockGetProviderValue = &provider{config: *testConfig()}
+	defer func() { mockGetProviderValue = nil }()
+
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+		if op.ExternalAccount.ServiceType == "ldap" && op.ExternalAccount.ServiceID == "ldap://ldap.example.com" && op.ExternalAccount.AccountID == "5f2b6e1c-6a3d-4c1e-9d2e-2f6a4c1b7e10" &&
+			op.UserProps.Username == "alice" && op.UserProps.Email == "alice@example.com" && op.UserProps.EmailIsVerified && op.UserProps.DisplayName == "Alice Smith" {
+			return 123, "", nil
+		}
+		return 0, "safeErr", fmt.Errorf("account %v not found in mock", op.ExternalAccount)
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	handler := http.NewServeMux()
+	handler.Handle("/", Middleware.App(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "This is the home; uid %d", actor.FromContext(r.Context()).UID)
+	})))
+
+	signIn := func(body string, header bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/.auth/ldap/login?pc=x", strings.NewReader(body))
+		if header {
+			req.Header.Set("X-Requested-With", "Sourcegraph")
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("other paths", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest("GET", "/search", nil))
+		if got, want := resp.Body.String(), "This is the home; uid 0"; got != want {
+			t.Errorf("got body %q, want %q", got, want)
+		}
+	})
+
+	t.Run("valid credentials", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		resp := signIn(`{"username":"alice","password":"alicepw"}`, true)
+		if want := http.StatusOK; resp.Code != want {
+			t.Fatalf("got status %d, want %d (body %q)", resp.Code, want, resp.Body.String())
+		}
+		if len(resp.Result().Cookies()) == 0 {
+			t.Error("got no session cookie")
+		}
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		resp := signIn(`{"username":"alice","password":"wrong"}`, true)
+		if want := http.StatusUnauthorized; resp.Code != want {
+			t.Errorf("got status %d, want %d", resp.Code, want)
+		}
+		if len(resp.Result().Cookies()) != 0 {
+			t.Error("got unexpected session cookie")
+		}
+	})
+
+	t.Run("missing X-Requested-With header", func(t *testing.T) {
+		mockDial(t, newFakeDirectory())
+		resp := signIn(`{"username":"alice","password":"alicepw"}`, false)
+		if want := http.StatusForbidden; resp.Code != want {
+			t.Errorf("got status %d, want %d", resp.Code, want)
+		}
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest("GET", "/.auth/ldap/login?pc=x", nil))
+		if want := http.StatusBadRequest; resp.Code != want {
+			t.Errorf("got status %d, want %d", resp.Code, want)
+		}
+	})
+}
+
+func TestSyncOrgMemberships(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	orgs := map[string]int32{"eng": 1, "admins": 2}
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		if id, ok := orgs[name]; ok {
+			return &types.Org{ID: id, Name: name}, nil
+		}
+		return nil, &db.OrgNotFoundError{Message: name}
+	}
+	var created []string
+	db.Mocks.Orgs.Create = func(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+		created = append(created, name)
+		orgs[name] = int32(len(orgs) + 1)
+		return &types.Org{ID: orgs[name], Name: name}, nil
+	}
+	members := map[int32]bool{2: true} // the user is a member of the admins org
+	db.Mocks.OrgMembers.GetByOrgIDAndUserID = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		if members[orgID] {
+			return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+		}
+		return nil, &db.ErrOrgMemberNotFound{}
+	}
+	db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		members[orgID] = true
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	db.Mocks.OrgMembers.Remove = func(ctx context.Context, orgID, userID int32) error {
+		delete(members, orgID)
+		return nil
+	}
+
+	c := testConfig()
+	c.GroupOrgs = map[string]string{
+		"Engineering": "eng",
+		"admins":      "admins",
+		"ops":         "ops",
+		"qa":          "qa",
+	}
+	if err := syncOrgMemberships(context.Background(), c, 1, []string{"engineering", "qa", "unmapped"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"qa"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("got created orgs %q, want %q", created, want)
+	}
+	if want := map[int32]bool{orgs["eng"]: true, orgs["qa"]: true}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got memberships %v, want %v", members, want)
+	}
+}
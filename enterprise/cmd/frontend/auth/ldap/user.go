@@ -0,0 +1,112 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// getOrCreateUser gets or creates the user account of the authenticated LDAP user and syncs the
+// user's organization memberships. It returns the authenticated actor if successful; otherwise it
+// returns a friendly error message (safeErrMsg) that is safe to display to users, and a non-nil err
+// with lower-level error details.
+func getOrCreateUser(ctx context.Context, p *provider, u *ldapUser) (_ *actor.Actor, safeErrMsg string, err error) {
+	login, err := auth.NormalizeUsername(u.Username)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", u.Username), err
+	}
+	displayName := u.DisplayName
+	if displayName == "" {
+		displayName = login
+	}
+
+	var data extsvc.AccountData
+	data.SetAccountData(u)
+
+	pi := p.CachedInfo()
+	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username: login,
+			Email:    u.Email,
+			// The LDAP server is the authority on the user's email address.
+			EmailIsVerified: u.Email != "",
+			DisplayName:     displayName,
+		},
+		ExternalAccount: extsvc.AccountSpec{
+			ServiceType: providerType,
+			ServiceID:   pi.ServiceID,
+			AccountID:   u.ID,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    true,
+	})
+	if err != nil {
+		return nil, safeErrMsg, err
+	}
+
+	if err := syncOrgMemberships(ctx, &p.config, userID, u.Groups); err != nil {
+		return nil, "Error syncing LDAP group memberships to organizations.", err
+	}
+	return actor.FromUser(userID), "", nil
+}
+
+// syncOrgMemberships adds the user to the organizations that their LDAP groups are mapped to in
+// groupOrgs, and removes the user from the other organizations in groupOrgs. Organizations that
+// don't exist are created. Group names are compared case-insensitively, like LDAP attribute values
+// usually are.
+func syncOrgMemberships(ctx context.Context, c *schema.LDAPAuthProvider, userID int32, groups []string) error {
+	if len(c.GroupOrgs) == 0 {
+		return nil
+	}
+	inGroup := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		inGroup[strings.ToLower(g)] = true
+	}
+	want := map[string]bool{}
+	for group, orgName := range c.GroupOrgs {
+		want[orgName] = want[orgName] || inGroup[strings.ToLower(group)]
+	}
+	orgNames := make([]string, 0, len(want))
+	for orgName := range want {
+		orgNames = append(orgNames, orgName)
+	}
+	sort.Strings(orgNames)
+
+	for _, orgName := range orgNames {
+		org, err := db.Orgs.GetByName(ctx, orgName)
+		if errcode.IsNotFound(err) {
+			if !want[orgName] {
+				continue
+			}
+			org, err = db.Orgs.Create(ctx, orgName, nil)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = db.OrgMembers.GetByOrgIDAndUserID(ctx, org.ID, userID)
+		if err != nil && !errcode.IsNotFound(err) {
+			return err
+		}
+		isMember := err == nil
+		switch {
+		case want[orgName] && !isMember:
+			if _, err := db.OrgMembers.Create(ctx, org.ID, userID); err != nil {
+				return err
+			}
+		case !want[orgName] && isMember:
+			if err := db.OrgMembers.Remove(ctx, org.ID, userID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
@@ -28,6 +29,10 @@ var (
 type PermsStore struct {
 	db    dbutil.DB
 	clock func() time.Time
+
+	// sharedTx is true if db is a transaction carried by a context (see dbconn.Transaction),
+	// which is committed or rolled back by whoever started it instead of by Done.
+	sharedTx bool
 }
 
 // NewPermsStore returns a new PermsStore with given parameters.
@@ -1627,12 +1632,16 @@ func (s *PermsStore) tx(ctx context.Context) (*sql.Tx, error) {
 	}
 }
 
-// Transact begins a new transaction and make a new PermsStore over it.
+// Transact begins a new transaction and make a new PermsStore over it. If ctx carries a transaction
+// (see dbconn.Transaction), the new PermsStore uses that transaction instead.
 func (s *PermsStore) Transact(ctx context.Context) (*PermsStore, error) {
 	if Mocks.Perms.Transact != nil {
 		return Mocks.Perms.Transact(ctx)
 	}
 
+	if tx := dbconn.TxFromContext(ctx); tx != nil {
+		return &PermsStore{db: tx, clock: s.clock, sharedTx: true}, nil
+	}
 	tx, err := s.tx(ctx)
 	if err != nil {
 		return nil, err
@@ -1648,7 +1657,7 @@ func (s *PermsStore) inTx() bool {
 
 // Done commits the transaction if error is nil. Otherwise, rolls back the transaction.
 func (s *PermsStore) Done(err *error) {
-	if !s.inTx() {
+	if !s.inTx() || s.sharedTx {
 		return
 	}
 
@@ -2,6 +2,7 @@ package bitbucketserver
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
@@ -66,7 +67,16 @@ func newAuthzProvider(
 		errs = multierror.Append(errs, errors.Errorf("authorization.hardTTL: must be larger than ttl"))
 	}
 
-	cli, err := bitbucketserver.NewClient(c, nil)
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+		return nil, errs.ErrorOrNil()
+	}
+	token := c.Token
+	if token == "" {
+		token = c.Username
+	}
+	cli, err := bitbucketserver.NewClient(c, iauthz.RateLimitedDoer("BITBUCKETSERVER", baseURL, token))
 	if err != nil {
 		errs = multierror.Append(errs, err)
 		return nil, errs.ErrorOrNil()
@@ -1,8 +1,19 @@
 package authz
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 )
 
 // ParseTTL parses ttl string to a valid time duration.
@@ -17,3 +28,81 @@ func ParseTTL(ttl string) (time.Duration, error) {
 	}
 	return d, nil
 }
+
+// RateLimitedDoer returns the HTTP client for an authz provider that talks to
+// the code host at baseURL with token. Every request reserves API budget from
+// ratelimit.DefaultCoordinator, which is shared with the other services that
+// use the same external service (of the given kind, e.g. "GITHUB") and token.
+// Requests reserve budget at the priority of their context, so permission
+// checks of a user's request should use ratelimit.WithPriority.
+func RateLimitedDoer(kind string, baseURL *url.URL, token string) httpcli.Doer {
+	baseURL = extsvc.NormalizeBaseURL(baseURL)
+	return ratelimit.DefaultCoordinator.Doer(http.DefaultClient, func(ctx context.Context) (ratelimit.Key, bool) {
+		return rateLimitKeys.get(ctx, kind, baseURL.String(), token)
+	})
+}
+
+// rateLimitKeyTTL is how long the external service of an authz provider is
+// cached. Authz providers are recreated every few seconds, so the cache is
+// shared by all of them.
+const rateLimitKeyTTL = time.Minute
+
+var rateLimitKeys = &rateLimitKeyCache{entries: map[string]rateLimitKeyEntry{}}
+
+type rateLimitKeyCache struct {
+	mu      sync.Mutex
+	entries map[string]rateLimitKeyEntry // by kind, base URL and token
+}
+
+type rateLimitKeyEntry struct {
+	key     ratelimit.Key
+	ok      bool
+	expires time.Time
+}
+
+// get returns the rate limit key of the external service of the given kind
+// with the base URL and token, or false if there is none.
+func (c *rateLimitKeyCache) get(ctx context.Context, kind, baseURL, token string) (ratelimit.Key, bool) {
+	cacheKey := kind + "\x00" + baseURL + "\x00" + token
+	c.mu.Lock()
+	e, ok := c.entries[cacheKey]
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.key, e.ok
+	}
+
+	e = rateLimitKeyEntry{expires: time.Now().Add(rateLimitKeyTTL)}
+	svcs, err := db.ExternalServices.List(ctx, db.ExternalServicesListOptions{Kinds: []string{kind}})
+	if err != nil {
+		// Don't hold up permission checks if the database is unavailable,
+		// and try again with the next request.
+		log15.Warn("authz: failed to look up the external service to rate limit requests", "kind", kind, "url", baseURL, "error", err)
+		return ratelimit.Key{}, false
+	}
+	for _, svc := range svcs {
+		var cfg struct {
+			URL      string `json:"url"`
+			Token    string `json:"token"`
+			Username string `json:"username"`
+		}
+		if err := jsonc.Unmarshal(svc.Config, &cfg); err != nil {
+			continue
+		}
+		svcToken := cfg.Token
+		if svcToken == "" {
+			// Bitbucket Server connections may use a username and password.
+			svcToken = cfg.Username
+		}
+		u, err := url.Parse(cfg.URL)
+		if err != nil || extsvc.NormalizeBaseURL(u).String() != baseURL || svcToken != token {
+			continue
+		}
+		e.key, e.ok = ratelimit.Key{ExternalServiceID: svc.ID, Token: token}, true
+		break
+	}
+
+	c.mu.Lock()
+	c.entries[cacheKey] = e
+	c.mu.Unlock()
+	return e.key, e.ok
+}
@@ -0,0 +1,42 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+)
+
+func TestRateLimitKeyCache(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+	calls := 0
+	db.Mocks.ExternalServices.List = func(opt db.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+		calls++
+		if len(opt.Kinds) != 1 || opt.Kinds[0] != "GITLAB" {
+			t.Errorf("got kinds %v, want [GITLAB]", opt.Kinds)
+		}
+		return []*types.ExternalService{
+			{ID: 1, Kind: "GITLAB", Config: `{"url": "https://gitlab.example.com", "token": "a"}`},
+			{ID: 2, Kind: "GITLAB", Config: `{"url": "https://GITLAB.com", "token": "a"}`},
+			{ID: 3, Kind: "GITLAB", Config: `{"url": "https://gitlab.com", "token": "b"}`},
+		}, nil
+	}
+
+	c := &rateLimitKeyCache{entries: map[string]rateLimitKeyEntry{}}
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		k, ok := c.get(ctx, "GITLAB", "https://gitlab.com/", "b")
+		if want := (ratelimit.Key{ExternalServiceID: 3, Token: "b"}); !ok || k != want {
+			t.Fatalf("got key %v (%v), want %v", k, ok, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d external service lookups, want 1 (cached)", calls)
+	}
+
+	if k, ok := c.get(ctx, "GITLAB", "https://gitlab.com/", "c"); ok {
+		t.Errorf("got key %v for unknown token, want none", k)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	iauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
@@ -27,7 +28,7 @@ type Provider struct {
 
 func NewProvider(githubURL *url.URL, baseToken string, cacheTTL time.Duration, mockCache cache) *Provider {
 	apiURL, _ := github.APIRoot(githubURL)
-	client := &clientAdapter{Client: github.NewClient(apiURL, baseToken, nil)}
+	client := &clientAdapter{Client: github.NewClient(apiURL, baseToken, iauthz.RateLimitedDoer("GITHUB", githubURL, baseToken))}
 
 	p := &Provider{
 		codeHost: extsvc.NewCodeHost(githubURL, github.ServiceType),
@@ -129,12 +129,12 @@ func newAuthzProvider(a *schema.GitLabAuthorization, instanceURL, token string,
 
 // NewOAuthProvider is a mockable constructor for new OAuthProvider instances.
 var NewOAuthProvider = func(op OAuthProviderOp) authz.Provider {
-	return newOAuthProvider(op, nil)
+	return newOAuthProvider(op, iauthz.RateLimitedDoer("GITLAB", op.BaseURL, op.Token))
 }
 
 // NewSudoProvider is a mockable constructor for new SudoProvider instances.
 var NewSudoProvider = func(op SudoProviderOp) authz.Provider {
-	return newSudoProvider(op, nil)
+	return newSudoProvider(op, iauthz.RateLimitedDoer("GITLAB", op.BaseURL, op.SudoToken))
 }
 
 // ValidateAuthz validates the authorization fields of the given GitLab external
@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -98,24 +99,40 @@ func (r *Resolver) SetRepositoryPermissionsForUsers(ctx context.Context, args *g
 		pendingBindIDs = append(pendingBindIDs, id)
 	}
 
-	txs, err := r.store.Transact(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "start transaction")
-	}
-	defer txs.Done(&err)
-
 	accounts := &extsvc.Accounts{
 		ServiceType: authz.SourcegraphServiceType,
 		ServiceID:   authz.SourcegraphServiceID,
 		AccountIDs:  pendingBindIDs,
 	}
-
-	if err = txs.SetRepoPermissions(ctx, p); err != nil {
-		return nil, errors.Wrap(err, "set repository permissions")
-	} else if err = txs.SetRepoPendingPermissions(ctx, accounts, p); err != nil {
-		return nil, errors.Wrap(err, "set repository pending permissions")
+	after, err := json.Marshal(bindIDs)
+	if err != nil {
+		return nil, err
 	}
+	afterStr := string(after)
 
+	// The permissions are set in the same transaction as the audit log entry is recorded in.
+	err = backend.PerformAuditedAction(ctx, func(ctx context.Context) (_ *backend.AuditEvent, err error) {
+		txs, err := r.store.Transact(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "start transaction")
+		}
+		defer txs.Done(&err)
+
+		if err = txs.SetRepoPermissions(ctx, p); err != nil {
+			return nil, errors.Wrap(err, "set repository permissions")
+		} else if err = txs.SetRepoPendingPermissions(ctx, accounts, p); err != nil {
+			return nil, errors.Wrap(err, "set repository pending permissions")
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionRepoPermissionsSet,
+			TargetType: "Repository",
+			TargetID:   string(args.Repository),
+			After:      &afterStr,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &graphqlbackend.EmptyResponse{}, nil
 }
 
@@ -69,6 +69,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 		gqlTests           []*gqltesting.Test
 		expUserIDs         []uint32
 		expAccounts        *extsvc.Accounts
+		expAuditLogAfter   string
 	}{
 		{
 			name: "set permissions via email",
@@ -108,6 +109,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 				ServiceID:   authz.SourcegraphServiceID,
 				AccountIDs:  []string{"bob"},
 			},
+			expAuditLogAfter: `["alice@example.com","bob"]`,
 		},
 		{
 			name: "set permissions via username",
@@ -147,6 +149,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 				ServiceID:   authz.SourcegraphServiceID,
 				AccountIDs:  []string{"bob"},
 			},
+			expAuditLogAfter: `["alice","bob"]`,
 		},
 	}
 	for _, test := range tests {
@@ -181,14 +184,24 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 				}
 				return nil
 			}
+			var auditLogEntries []*db.AuditLogEntry
+			db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+				auditLogEntries = append(auditLogEntries, e)
+				return nil
+			}
 			defer func() {
 				db.Mocks.UserEmails = db.MockUserEmails{}
 				db.Mocks.Users = db.MockUsers{}
 				db.Mocks.Repos = db.MockRepos{}
+				db.Mocks.AuditLog = db.MockAuditLog{}
 				edb.Mocks.Perms = edb.MockPerms{}
 			}()
 
 			gqltesting.RunTests(t, test.gqlTests)
+
+			if len(auditLogEntries) != 1 || auditLogEntries[0].Action != backend.AuditActionRepoPermissionsSet || auditLogEntries[0].TargetID != "UmVwb3NpdG9yeTox" || *auditLogEntries[0].After != test.expAuditLogAfter {
+				t.Errorf("unexpected audit log entries %+v", auditLogEntries)
+			}
 		})
 	}
 }
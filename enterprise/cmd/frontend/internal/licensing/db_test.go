@@ -0,0 +1,7 @@
+package licensing
+
+import "github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+
+func init() {
+	dbtesting.DBNameSuffix = "licensing"
+}
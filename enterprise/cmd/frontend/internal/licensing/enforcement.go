@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"html"
 	"net/http"
+	"time"
 
 	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 )
 
@@ -14,7 +16,7 @@ import (
 // the given UsersStore.
 func NewPreCreateUserHook(s UsersStore) func(context.Context) error {
 	return func(ctx context.Context) error {
-		info, err := GetConfiguredProductLicenseInfo()
+		info, signature, err := GetConfiguredProductLicenseInfoWithSignature()
 		if err != nil {
 			return err
 		}
@@ -36,6 +38,10 @@ func NewPreCreateUserHook(s UsersStore) func(context.Context) error {
 		if licensedUserCount > 0 && int32(userCount) >= licensedUserCount {
 			if info != nil && info.HasTag(TrueUpUserCountTag) {
 				log15.Info("Licensed user count exceeded, but license supports true-up and will not block creation of new user. The new user will be retroactively charged for in the next billing period. Contact sales@sourcegraph.com for help.", "activeUserCount", userCount, "licensedUserCount", licensedUserCount)
+			} else if ok, err := checkSeatOverageGracePeriod(ctx, signature, int(licensedUserCount), userCount); err != nil {
+				return err
+			} else if ok {
+				log15.Warn("Licensed user count exceeded, but the seat overage grace period allows creation of new user. Contact sales@sourcegraph.com to add seats.", "activeUserCount", userCount, "licensedUserCount", licensedUserCount)
 			} else {
 				message := "Unable to create user account: "
 				if info == nil {
@@ -51,6 +57,30 @@ func NewPreCreateUserHook(s UsersStore) func(context.Context) error {
 	}
 }
 
+// checkSeatOverageGracePeriod reports whether the licensing.seatOverageGracePeriod site
+// configuration allows a new user account to be created beyond the licensed seats of the license
+// with the given signature. If so, it records the start of the overage.
+func checkSeatOverageGracePeriod(ctx context.Context, signature string, licensedUserCount, userCount int) (bool, error) {
+	policy := conf.Get().LicensingSeatOverageGracePeriod
+	if policy == nil || signature == "" {
+		return false, nil
+	}
+	overageSince, err := getSeatOverageSince(ctx, signature)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if !allowSeatOverage(policy, licensedUserCount, userCount, overageSince, now) {
+		return false, nil
+	}
+	if userCount+1 > licensedUserCount {
+		if err := setSeatOverageSince(ctx, signature, now); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 // WriteSubscriptionErrorResponseForFeature is a wrapper around WriteSubscriptionErrorResponse that
 // generates the error title and message indicating that the current license does not active the
 // given feature.
@@ -0,0 +1,324 @@
+package licensing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/inconshreveable/log15"
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// seatUsageRetentionDays is the number of days of seat usage snapshots to keep.
+	seatUsageRetentionDays = 400
+
+	// seatUsageWarningPercent is the percentage of licensed seats in use at which site admins are
+	// warned that the site is approaching its licensed seats.
+	seatUsageWarningPercent = 90
+
+	// defaultMaxOveragePercent is the default for the maxOveragePercent property of the
+	// licensing.seatOverageGracePeriod site configuration.
+	defaultMaxOveragePercent = 10
+
+	seatUsageDateFormat = "2006-01-02"
+)
+
+// SeatUsageSnapshot is the peak number of user accounts on a day (in UTC), and the number of seats
+// licensed on that day.
+type SeatUsageSnapshot struct {
+	Date          string `json:"date"` // YYYY-MM-DD
+	UserCount     int    `json:"userCount"`
+	LicensedSeats int    `json:"licensedSeats"` // 0 means unlimited
+}
+
+// SeatUsage describes the current use of the licensed seats and the recent history of seat usage.
+type SeatUsage struct {
+	UserCount     int
+	LicensedSeats int // 0 means unlimited
+
+	// OverageSince is when the site first exceeded the licensed seats of its current license, or
+	// the zero time if it never did.
+	OverageSince time.Time
+
+	// GracePeriodEndsAt is when new user accounts will be blocked again if the site is in a seat
+	// overage grace period, or the zero time otherwise.
+	GracePeriodEndsAt time.Time
+
+	// Snapshots are the daily seat usage snapshots, newest first.
+	Snapshots []SeatUsageSnapshot
+}
+
+// seatUsageKey is the Redis hash that stored the seat usage snapshots before they were stored in
+// the seat_usage_snapshots table. See importRedisSeatUsage.
+func seatUsageKey() string {
+	return keyPrefix + "daily"
+}
+
+// seatOverageSinceKey is the Redis hash that stored the start of seat overages before they were
+// stored in the seat_overages table. See importRedisSeatUsage.
+func seatOverageSinceKey() string {
+	return keyPrefix + "overage_since"
+}
+
+// licensedSeats returns the number of user accounts allowed by the license (or by the lack of a
+// license), or 0 if it is unlimited.
+func licensedSeats(info *Info) int {
+	if info == nil {
+		return int(NoLicenseMaximumAllowedUserCount)
+	}
+	// See NewPreCreateUserHook for why 0 is treated as unlimited.
+	return int(info.UserCount)
+}
+
+// recordSeatUsage records the number of user accounts in today's seat usage snapshot (if it is the
+// day's peak) and removes snapshots older than seatUsageRetentionDays.
+var recordSeatUsage = func(ctx context.Context, now time.Time, userCount, licensedSeats int) error {
+	// If the licensed seats changed during the day, the snapshot starts over with the new license.
+	q := sqlf.Sprintf(`
+INSERT INTO seat_usage_snapshots(date, user_count, licensed_seats) VALUES(%s, %s, %s)
+ON CONFLICT (date) DO UPDATE SET
+  user_count=CASE WHEN seat_usage_snapshots.licensed_seats=excluded.licensed_seats
+    THEN GREATEST(seat_usage_snapshots.user_count, excluded.user_count)
+    ELSE excluded.user_count END,
+  licensed_seats=excluded.licensed_seats`,
+		now.UTC().Format(seatUsageDateFormat), userCount, licensedSeats)
+	if _, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return err
+	}
+
+	oldest := now.UTC().AddDate(0, 0, -seatUsageRetentionDays).Format(seatUsageDateFormat)
+	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM seat_usage_snapshots WHERE date < $1", oldest)
+	return err
+}
+
+// listSeatUsage returns the seat usage snapshots for the given number of days (including today),
+// newest first.
+var listSeatUsage = func(ctx context.Context, now time.Time, days int) ([]SeatUsageSnapshot, error) {
+	oldest := now.UTC().AddDate(0, 0, -days+1).Format(seatUsageDateFormat)
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT date, user_count, licensed_seats FROM seat_usage_snapshots WHERE date >= $1 ORDER BY date DESC", oldest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []SeatUsageSnapshot
+	for rows.Next() {
+		var (
+			s    SeatUsageSnapshot
+			date time.Time
+		)
+		if err := rows.Scan(&date, &s.UserCount, &s.LicensedSeats); err != nil {
+			return nil, err
+		}
+		s.Date = date.Format(seatUsageDateFormat)
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// importRedisSeatUsage moves the seat usage snapshots and the start of seat overages that were
+// stored in Redis (by previous versions) to the seat_usage_snapshots and seat_overages tables.
+// Rows already in the tables are kept. It runs once at startup (see StartMaxUserCount).
+var importRedisSeatUsage = func(ctx context.Context) error {
+	c := pool.Get()
+	defer c.Close()
+
+	overages, err := redis.StringMap(c.Do("HGETALL", seatOverageSinceKey()))
+	if err != nil {
+		return err
+	}
+	for signature, v := range overages {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			continue
+		}
+		if err := setSeatOverageSince(ctx, signature, since); err != nil {
+			return err
+		}
+	}
+	if len(overages) > 0 {
+		if _, err := c.Do("DEL", seatOverageSinceKey()); err != nil {
+			return err
+		}
+	}
+
+	values, err := redis.ByteSlices(c.Do("HVALS", seatUsageKey()))
+	if err != nil || len(values) == 0 {
+		return err
+	}
+	for _, v := range values {
+		var s SeatUsageSnapshot
+		if err := json.Unmarshal(v, &s); err != nil {
+			continue
+		}
+		if _, err := time.Parse(seatUsageDateFormat, s.Date); err != nil {
+			continue
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, "INSERT INTO seat_usage_snapshots(date, user_count, licensed_seats) VALUES($1, $2, $3) ON CONFLICT (date) DO NOTHING", s.Date, s.UserCount, s.LicensedSeats); err != nil {
+			return err
+		}
+	}
+	_, err = c.Do("DEL", seatUsageKey())
+	return err
+}
+
+// getSeatOverageSince returns when the site began exceeding the licensed seats of the license
+// with the given signature, or the zero time if it doesn't.
+var getSeatOverageSince = func(ctx context.Context, signature string) (time.Time, error) {
+	var since time.Time
+	err := dbconn.Global.QueryRowContext(ctx, "SELECT overage_since FROM seat_overages WHERE license_signature=$1", signature).Scan(&since)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return since, err
+}
+
+// setSeatOverageSince records when the site began exceeding the licensed seats of the license
+// with the given signature, unless it is already recorded. The record is never removed (even if
+// the site no longer exceeds its licensed seats), so that the grace period is only granted once
+// per license.
+var setSeatOverageSince = func(ctx context.Context, signature string, since time.Time) error {
+	_, err := dbconn.Global.ExecContext(ctx, "INSERT INTO seat_overages(license_signature, overage_since) VALUES($1, $2) ON CONFLICT (license_signature) DO NOTHING", signature, since.UTC())
+	return err
+}
+
+// checkSeatUsage runs periodically to record the daily seat usage snapshot and whether the site
+// exceeds its licensed seats.
+func checkSeatUsage(ctx context.Context, s UsersStore, info *Info, signature string) error {
+	count, err := s.Count(ctx)
+	if err != nil {
+		log15.Error("licensing.checkSeatUsage: error getting user count", "error", err)
+		return err
+	}
+	seats := licensedSeats(info)
+
+	if err := recordSeatUsage(ctx, time.Now(), count, seats); err != nil {
+		log15.Error("licensing.checkSeatUsage: error recording seat usage", "error", err)
+		return err
+	}
+
+	// Only record the start of an overage. Dropping back to the licensed seats doesn't reset it, so
+	// that deleting user accounts for a moment can't restart the grace period.
+	if signature != "" && seats > 0 && count > seats {
+		if err := setSeatOverageSince(ctx, signature, time.Now()); err != nil {
+			log15.Error("licensing.checkSeatUsage: error recording seat overage", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// maxSeatOverage returns the number of user accounts allowed beyond the licensed seats by the
+// grace period policy.
+func maxSeatOverage(policy *schema.LicensingSeatOverageGracePeriod, licensedSeats int) int {
+	percent := policy.MaxOveragePercent
+	if percent == 0 {
+		percent = defaultMaxOveragePercent
+	}
+	n := (licensedSeats*percent + 99) / 100
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// seatOverageGracePeriodEnd returns when the seat overage grace period that began at overageSince
+// ends under the policy.
+func seatOverageGracePeriodEnd(policy *schema.LicensingSeatOverageGracePeriod, overageSince time.Time) time.Time {
+	return overageSince.Add(time.Duration(policy.Days) * 24 * time.Hour)
+}
+
+// allowSeatOverage reports whether a new user account may be created (bringing the site's user
+// count above userCount) on a site with the given number of licensed seats, under the seat overage
+// grace period policy (if any). The overage began at overageSince, or it has not begun yet if
+// overageSince is the zero time.
+func allowSeatOverage(policy *schema.LicensingSeatOverageGracePeriod, licensedSeats, userCount int, overageSince, now time.Time) bool {
+	if policy == nil || licensedSeats <= 0 {
+		return false
+	}
+	if !overageSince.IsZero() && !now.Before(seatOverageGracePeriodEnd(policy, overageSince)) {
+		return false
+	}
+	return userCount+1 <= licensedSeats+maxSeatOverage(policy, licensedSeats)
+}
+
+// GetSeatUsage returns the current seat usage and the daily seat usage snapshots for the given
+// number of days.
+func GetSeatUsage(ctx context.Context, s UsersStore, days int) (*SeatUsage, error) {
+	info, signature, err := GetConfiguredProductLicenseInfoWithSignature()
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage := SeatUsage{UserCount: count, LicensedSeats: licensedSeats(info)}
+
+	if signature != "" {
+		if usage.OverageSince, err = getSeatOverageSince(ctx, signature); err != nil {
+			return nil, err
+		}
+		if policy := conf.Get().LicensingSeatOverageGracePeriod; policy != nil && !usage.OverageSince.IsZero() {
+			usage.GracePeriodEndsAt = seatOverageGracePeriodEnd(policy, usage.OverageSince)
+		}
+	}
+
+	if usage.Snapshots, err = listSeatUsage(ctx, time.Now(), days); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// SeatUsageAlert returns a message (and whether it is an error rather than a warning) to show to
+// site admins if the site is approaching or exceeding its licensed seats, or an empty message if
+// no alert is needed.
+func SeatUsageAlert(ctx context.Context, s UsersStore) (message string, isError bool, err error) {
+	info, signature, err := GetConfiguredProductLicenseInfoWithSignature()
+	if info == nil || err != nil {
+		// Sites without a license show a warning to all users instead.
+		return "", false, err
+	}
+	seats := licensedSeats(info)
+	if seats <= 0 {
+		return "", false, nil
+	}
+	count, err := s.Count(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch {
+	case count >= seats && info.HasTag(TrueUpUserCountTag):
+		if count == seats {
+			return "", false, nil
+		}
+		return fmt.Sprintf("This Sourcegraph site has %d user accounts, which exceeds its %d licensed seats. Additional users will be charged for in the next billing period.", count, seats), false, nil
+
+	case count >= seats:
+		policy := conf.Get().LicensingSeatOverageGracePeriod
+		overageSince, err := getSeatOverageSince(ctx, signature)
+		if err != nil {
+			return "", false, err
+		}
+		if policy != nil && allowSeatOverage(policy, seats, count, overageSince, time.Now()) {
+			msg := fmt.Sprintf("This Sourcegraph site has %d user accounts and %d licensed seats. New users are allowed during the seat overage grace period", count, seats)
+			if !overageSince.IsZero() {
+				msg += " until " + seatOverageGracePeriodEnd(policy, overageSince).Format("January 2, 2006")
+			}
+			return msg + ". Contact Sourcegraph at https://about.sourcegraph.com/contact/sales to add seats.", true, nil
+		}
+		return fmt.Sprintf("This Sourcegraph site has reached its %d licensed seats (%d user accounts), so new users can't sign up. Contact Sourcegraph at https://about.sourcegraph.com/contact/sales to add seats.", seats, count), true, nil
+
+	case count*100 >= seats*seatUsageWarningPercent:
+		return fmt.Sprintf("This Sourcegraph site is using %d of its %d licensed seats. Contact Sourcegraph at https://about.sourcegraph.com/contact/sales to add seats before new users are blocked.", count, seats), false, nil
+	}
+	return "", false, nil
+}
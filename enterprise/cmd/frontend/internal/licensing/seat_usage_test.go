@@ -0,0 +1,195 @@
+package licensing
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/license"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestAllowSeatOverage(t *testing.T) {
+	now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	policy := &schema.LicensingSeatOverageGracePeriod{Days: 30}
+	tests := []struct {
+		policy        *schema.LicensingSeatOverageGracePeriod
+		licensedSeats int
+		userCount     int
+		overageSince  time.Time
+		want          bool
+	}{
+		{policy: nil, licensedSeats: 100, userCount: 100, want: false},
+		{policy: policy, licensedSeats: 0, userCount: 100, want: false},
+
+		// Default maxOveragePercent of 10%.
+		{policy: policy, licensedSeats: 100, userCount: 100, want: true},
+		{policy: policy, licensedSeats: 100, userCount: 109, overageSince: now.AddDate(0, 0, -1), want: true},
+		{policy: policy, licensedSeats: 100, userCount: 110, overageSince: now.AddDate(0, 0, -1), want: false},
+
+		// At least 1 user account is allowed.
+		{policy: policy, licensedSeats: 5, userCount: 5, want: true},
+		{policy: policy, licensedSeats: 5, userCount: 6, overageSince: now, want: false},
+
+		{policy: &schema.LicensingSeatOverageGracePeriod{Days: 30, MaxOveragePercent: 50}, licensedSeats: 10, userCount: 14, overageSince: now, want: true},
+
+		// Grace period ended.
+		{policy: policy, licensedSeats: 100, userCount: 101, overageSince: now.AddDate(0, 0, -29), want: true},
+		{policy: policy, licensedSeats: 100, userCount: 101, overageSince: now.AddDate(0, 0, -30), want: false},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%+v %d/%d since %s", test.policy, test.userCount, test.licensedSeats, test.overageSince), func(t *testing.T) {
+			if got := allowSeatOverage(test.policy, test.licensedSeats, test.userCount, test.overageSince, now); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnforcementPreCreateUserSeatOverageGracePeriod(t *testing.T) {
+	MockGetConfiguredProductLicenseInfo = func() (*license.Info, string, error) {
+		return &license.Info{UserCount: 10}, "test-signature", nil
+	}
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		LicensingSeatOverageGracePeriod: &schema.LicensingSeatOverageGracePeriod{Days: 30, MaxOveragePercent: 20},
+	}})
+	origGet, origSet := getSeatOverageSince, setSeatOverageSince
+	var overageSince time.Time
+	getSeatOverageSince = func(ctx context.Context, signature string) (time.Time, error) { return overageSince, nil }
+	setSeatOverageSince = func(ctx context.Context, signature string, since time.Time) error {
+		if overageSince.IsZero() {
+			overageSince = since
+		}
+		return nil
+	}
+	defer func() {
+		MockGetConfiguredProductLicenseInfo = nil
+		conf.Mock(nil)
+		getSeatOverageSince, setSeatOverageSince = origGet, origSet
+	}()
+
+	hook := NewPreCreateUserHook(fakeStore{count: 10})
+	if err := hook(context.Background()); err != nil {
+		t.Fatalf("first user beyond licensed seats: got error %v, want nil", err)
+	}
+	if overageSince.IsZero() {
+		t.Error("want start of seat overage to be recorded")
+	}
+
+	if err := NewPreCreateUserHook(fakeStore{count: 12})(context.Background()); err == nil {
+		t.Error("beyond maxOveragePercent: got nil error, want non-nil")
+	}
+
+	overageSince = time.Now().AddDate(0, 0, -31)
+	if err := hook(context.Background()); err == nil {
+		t.Error("after grace period: got nil error, want non-nil")
+	}
+}
+
+func TestSeatUsageSnapshots(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	day := func(d int) time.Time { return time.Date(2020, 5, d, 12, 0, 0, 0, time.UTC) }
+	for _, r := range []struct {
+		now                      time.Time
+		userCount, licensedSeats int
+	}{
+		{day(1), 5, 10},
+		{day(2), 7, 10},
+		{day(2), 6, 10}, // not the day's peak
+		{day(3), 9, 10},
+		{day(3), 8, 20}, // the license changed
+	} {
+		if err := recordSeatUsage(ctx, r.now, r.userCount, r.licensedSeats); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := listSeatUsage(ctx, day(3), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SeatUsageSnapshot{
+		{Date: "2020-05-03", UserCount: 8, LicensedSeats: 20},
+		{Date: "2020-05-02", UserCount: 7, LicensedSeats: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Old snapshots are removed.
+	later := day(1).AddDate(0, 0, seatUsageRetentionDays+1)
+	if err := recordSeatUsage(ctx, later, 1, 10); err != nil {
+		t.Fatal(err)
+	}
+	got, err = listSeatUsage(ctx, later, 2*seatUsageRetentionDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[len(got)-1].Date != "2020-05-02" {
+		t.Errorf("got %+v, want the snapshot of 2020-05-01 to be removed", got)
+	}
+}
+
+func TestSeatOverageSince(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	if since, err := getSeatOverageSince(ctx, "a"); err != nil || !since.IsZero() {
+		t.Fatalf("got %v (error %v), want zero time", since, err)
+	}
+	first := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, since := range []time.Time{first, first.AddDate(0, 0, 1)} {
+		if err := setSeatOverageSince(ctx, "a", since); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since, err := getSeatOverageSince(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !since.Equal(first) {
+		t.Errorf("got %v, want the first overage %v", since, first)
+	}
+	if since, err := getSeatOverageSince(ctx, "b"); err != nil || !since.IsZero() {
+		t.Errorf("other license: got %v (error %v), want zero time", since, err)
+	}
+}
+
+func TestCheckSeatUsage_keepsOverageSince(t *testing.T) {
+	origRecord, origSet := recordSeatUsage, setSeatOverageSince
+	defer func() {
+		recordSeatUsage, setSeatOverageSince = origRecord, origSet
+	}()
+	recordSeatUsage = func(context.Context, time.Time, int, int) error { return nil }
+	var calls []time.Time
+	setSeatOverageSince = func(ctx context.Context, signature string, since time.Time) error {
+		calls = append(calls, since)
+		return nil
+	}
+
+	ctx := context.Background()
+	info := &Info{Info: license.Info{UserCount: 10}}
+	for _, count := range []int{11, 9, 10} {
+		if err := checkSeatUsage(ctx, fakeStore{count: count}, info, "test-signature"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 🚨 SECURITY: The start of the overage is only recorded, never reset, so that the grace period
+	// can't be restarted by deleting user accounts.
+	if len(calls) != 1 || calls[0].IsZero() {
+		t.Errorf("got calls %v, want 1 call with a non-zero time", calls)
+	}
+}
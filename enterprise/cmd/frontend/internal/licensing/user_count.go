@@ -132,7 +132,9 @@ func ActualUserCountDate(ctx context.Context) (string, error) {
 	return date, err
 }
 
-// StartMaxUserCount starts checking for a new count of max user accounts periodically.
+// StartMaxUserCount starts checking for a new count of max user accounts periodically. It also
+// records the daily seat usage snapshots, after importing any seat usage that previous versions
+// stored in Redis.
 func StartMaxUserCount(s UsersStore) {
 	if started {
 		panic("already started")
@@ -140,14 +142,23 @@ func StartMaxUserCount(s UsersStore) {
 	started = true
 
 	ctx := context.Background()
+	importCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	if err := importRedisSeatUsage(importCtx); err != nil {
+		log15.Error("licensing.startMaxUserCount: error importing seat usage from Redis", "error", err)
+	}
+	cancel()
+
 	const delay = 360 * time.Minute
 	for {
-		_, signature, err := GetConfiguredProductLicenseInfoWithSignature()
+		info, signature, err := GetConfiguredProductLicenseInfoWithSignature()
 		if err != nil {
 			log15.Error("licensing.startMaxUserCount: error getting configured license info")
-		} else if signature != "" {
+		} else {
 			ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
-			_ = checkMaxUsers(ctx, s, signature) // updates global state on its own, can safely ignore return value
+			if signature != "" {
+				_ = checkMaxUsers(ctx, s, signature) // updates global state on its own, can safely ignore return value
+			}
+			_ = checkSeatUsage(ctx, s, info, signature) // same as above
 			cancel()
 		}
 		time.Sleep(delay)
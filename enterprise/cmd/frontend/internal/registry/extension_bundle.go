@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	frontendregistry "github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
@@ -49,6 +50,29 @@ func handleRegistryExtensionBundle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 🚨 SECURITY: If the extension's publisher signs its releases (or signed this release), refuse
+	// to serve a bundle that is unsigned or whose signature is invalid (e.g., because it was
+	// modified in the database). The bundle is verified with the key stored with the release, not
+	// the extension's current key, so that the signature can't be replaced along with the key.
+	signature, err := dbReleases{}.GetBundleSignature(r.Context(), releaseID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	signed := signature.ExtensionSigningPublicKey != nil || signature.BundleSignature != nil
+	if signed && !wantSourceMap {
+		if signature.SigningPublicKey == nil || signature.BundleSignature == nil {
+			http.Error(w, "extension bundle is not signed by the extension's publisher", http.StatusForbidden)
+			return
+		}
+		if err := verifyBundleSignature(*signature.SigningPublicKey, *signature.BundleSignature, bundle); err != nil {
+			log15.Error("Refusing to serve extension bundle with invalid signature.", "release", releaseID, "error", err)
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-Sourcegraph-Extension-Signature", *signature.BundleSignature)
+	}
+
 	// 🚨 SECURITY: Prevent this URL from being rendered as an HTML page by browsers (to prevent an
 	// XSS attack). That would let attackers upload an HTML file with inline JavaScript and then
 	// cause victims to visit it, thereby executing the attacker's JavaScript in the context of
@@ -86,13 +110,17 @@ func handleRegistryExtensionBundle(w http.ResponseWriter, r *http.Request) {
 		}
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		data = sourceMap
+	} else if signed {
+		// Serve signed bundles unmodified, so that clients can verify the signature.
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		data = bundle
 	} else {
 		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
 		data = sourceMappingURLLineRegex.ReplaceAll(bundle, []byte{})
 	}
 	_, _ = w.Write(data)
 
-	if !wantSourceMap && sourceMap != nil {
+	if !wantSourceMap && !signed && sourceMap != nil {
 		// Append `//# sourceMappingURL=` directive to JS bundle if we have a source map. It is
 		// necessary to provide the absolute URL because the JS bundle is not loaded directly (e.g.,
 		// via importScripts); it is saved to a blob URL and then executed, which means any relative
@@ -8,6 +8,7 @@ import (
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 )
 
@@ -94,6 +95,25 @@ func (r *extensionDBResolver) ViewerCanAdminister(ctx context.Context) (bool, er
 	return err == nil, err
 }
 
+func (r *extensionDBResolver) SigningPublicKey() *string { return r.v.SigningPublicKey }
+
+func (r *extensionDBResolver) PinnedRelease(ctx context.Context) (graphqlbackend.RegistryExtensionRelease, error) {
+	if r.v.PinnedReleaseID == nil {
+		return nil, nil
+	}
+	release, err := dbReleases{}.GetByID(ctx, r.v.ID, *r.v.PinnedReleaseID)
+	if err != nil {
+		return nil, err
+	}
+	return &extensionReleaseResolver{extension: r.v, v: release}, nil
+}
+
+func (r *extensionDBResolver) Releases(ctx context.Context, args *graphqlutil.ConnectionArgs) (graphqlbackend.RegistryExtensionReleaseConnection, error) {
+	connection := &extensionReleaseConnectionResolver{extension: r.v}
+	args.Set(&connection.limitOffset)
+	return connection, nil
+}
+
 func (r *extensionDBResolver) release(ctx context.Context) (*dbRelease, error) {
 	if r.r != nil {
 		return r.r, nil
@@ -74,14 +74,20 @@ func getLatestForBatch(ctx context.Context, vs []*dbExtension) (map[int32]*dbRel
 }
 
 // prepReleaseManifest will set the Manifest field of the release. If the manifest has no "url"
-// field itself, a "url" field pointing to the extension's bundle is inserted. It also returns
-// the date that the release was published.
+// field itself, a "url" field pointing to the extension's bundle is inserted, along with a
+// "signature" field if the bundle is signed (so that clients can verify the bundle before
+// activating it). It also returns the date that the release was published.
 func prepReleaseManifest(extensionID string, release *dbRelease) error {
 	// Add URL to bundle if necessary.
 	o := make(map[string]interface{})
 	if err := json.Unmarshal([]byte(release.Manifest), &o); err != nil {
 		return err
 	}
+
+	// 🚨 SECURITY: The "signature" field is set only by the registry, never by the publisher.
+	_, changed := o["signature"]
+	delete(o, "signature")
+
 	urlStr, _ := o["url"].(string)
 	if urlStr == "" {
 		// Insert "url" field with link to bundle file on this site.
@@ -90,6 +96,15 @@ func prepReleaseManifest(extensionID string, release *dbRelease) error {
 			return err
 		}
 		o["url"] = bundleURL
+		if release.SigningPublicKey != nil && release.BundleSignature != nil {
+			o["signature"] = map[string]string{
+				"publicKey":       *release.SigningPublicKey,
+				"bundleSignature": *release.BundleSignature,
+			}
+		}
+		changed = true
+	}
+	if changed {
 		b, err := json.MarshalIndent(o, "", "  ")
 		if err != nil {
 			return err
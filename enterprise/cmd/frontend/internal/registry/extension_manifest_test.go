@@ -52,6 +52,42 @@ func TestGetExtensionManifestWithBundleURL(t *testing.T) {
 			t.Errorf("got %v, want %v", release.CreatedAt, t0)
 		}
 	})
+
+	t.Run(`signed release`, func(t *testing.T) {
+		mocks.releases.GetLatest = func(registryExtensionID int32, releaseTag string, includeArtifacts bool) (*dbRelease, error) {
+			return &dbRelease{
+				Manifest:         `{"name":"x"}`,
+				BundleSignature:  strptr("s"),
+				SigningPublicKey: strptr("k"),
+				CreatedAt:        t0,
+			}, nil
+		}
+		defer func() { mocks.releases.GetLatest = nil }()
+		release, err := getLatestRelease(ctx, "x", 1, "t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"name":"x","url":"/-/static/extension/0-x.js?fqw3qlts--x","signature":{"publicKey":"k","bundleSignature":"s"}}`; !jsonDeepEqual(release.Manifest, want) {
+			t.Errorf("got %q, want %q", release.Manifest, want)
+		}
+	})
+
+	t.Run(`manifest with publisher-supplied "signature"`, func(t *testing.T) {
+		mocks.releases.GetLatest = func(registryExtensionID int32, releaseTag string, includeArtifacts bool) (*dbRelease, error) {
+			return &dbRelease{
+				Manifest:  `{"name":"x","url":"u","signature":{"publicKey":"k","bundleSignature":"s"}}`,
+				CreatedAt: t0,
+			}, nil
+		}
+		defer func() { mocks.releases.GetLatest = nil }()
+		release, err := getLatestRelease(ctx, "x", 1, "t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"name":"x","url":"u"}`; !jsonDeepEqual(release.Manifest, want) {
+			t.Errorf("got %q, want %q", release.Manifest, want)
+		}
+	})
 }
 
 func jsonDeepEqual(a, b string) bool {
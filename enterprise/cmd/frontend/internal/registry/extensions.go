@@ -20,6 +20,7 @@ func init() {
 		}
 		return &extensionDBResolver{v: x}, nil
 	}
+	registry.GetRemoteExtensionPins = dbRemotePins{}.List
 }
 
 // prefixLocalExtensionID adds the local registry's extension ID prefix (from
@@ -28,6 +28,14 @@ type dbExtension struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
+	// SigningPublicKey is the base64-encoded DER (PKIX) ECDSA P-256 public key that the
+	// extension's publisher signs release bundles with, or nil if releases are not signed.
+	SigningPublicKey *string
+
+	// PinnedReleaseID is the ID of the release that this site is pinned to, or nil if the latest
+	// release is used.
+	PinnedReleaseID *int64
+
 	// NonCanonicalExtensionID is the denormalized fully qualified extension ID
 	// ("[registry/]publisher/name" format), using the username/name of the extension's publisher
 	// (joined from another table) as of when the query executed. Do not persist this, because the
@@ -52,6 +60,8 @@ type dbExtension struct {
 	NonCanonicalIsWorkInProgress bool
 }
 
+// dbExtensions queries the registry_extensions table. Its methods use the transaction carried by
+// the context, if any (see dbconn.Transaction).
 type dbExtensions struct{}
 
 // extensionNotFoundError occurs when an extension is not found in the extension registry.
@@ -81,7 +91,7 @@ func (s dbExtensions) Create(ctx context.Context, publisherUserID, publisherOrgI
 		return 0, err
 	}
 
-	if err := dbconn.Global.QueryRowContext(ctx,
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx,
 		// Include users/orgs table query (with "FOR UPDATE") to ensure that the publisher user/org
 		// not been deleted. If it was deleted, the query will return an error.
 		`
@@ -272,7 +282,7 @@ WHERE (%s)
 func (s dbExtensions) list(ctx context.Context, conds, order []*sqlf.Query, limitOffset *db.LimitOffset) ([]*dbExtension, error) {
 	order = append(order, sqlf.Sprintf("TRUE"))
 	q := sqlf.Sprintf(`
-SELECT x.id, x.uuid, x.publisher_user_id, x.publisher_org_id, x.name, x.created_at, x.updated_at,
+SELECT x.id, x.uuid, x.publisher_user_id, x.publisher_org_id, x.name, x.created_at, x.updated_at, x.signing_public_key, x.pinned_release_id,
   `+extensionIDExpr+` AS non_canonical_extension_id, `+extensionPublisherNameExpr+` AS non_canonical_publisher_name,
   (%s) AS non_canonical_is_work_in_progress
 %s
@@ -288,7 +298,7 @@ ORDER BY %s,
 		limitOffset.SQL(),
 	)
 
-	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -298,7 +308,7 @@ ORDER BY %s,
 	for rows.Next() {
 		var t dbExtension
 		var publisherUserID, publisherOrgID sql.NullInt64
-		if err := rows.Scan(&t.ID, &t.UUID, &publisherUserID, &publisherOrgID, &t.Name, &t.CreatedAt, &t.UpdatedAt, &t.NonCanonicalExtensionID, &t.Publisher.NonCanonicalName, &t.NonCanonicalIsWorkInProgress); err != nil {
+		if err := rows.Scan(&t.ID, &t.UUID, &publisherUserID, &publisherOrgID, &t.Name, &t.CreatedAt, &t.UpdatedAt, &t.SigningPublicKey, &t.PinnedReleaseID, &t.NonCanonicalExtensionID, &t.Publisher.NonCanonicalName, &t.NonCanonicalIsWorkInProgress); err != nil {
 			return nil, err
 		}
 		t.Publisher.UserID = int32(publisherUserID.Int64)
@@ -314,7 +324,7 @@ ORDER BY %s,
 func (s dbExtensions) Count(ctx context.Context, opt dbExtensionsListOptions) (int, error) {
 	q := sqlf.Sprintf("SELECT COUNT(*) %s", s.listCountSQL(opt.sqlConditions()))
 	var count int
-	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -326,7 +336,7 @@ func (dbExtensions) Update(ctx context.Context, id int32, name *string) error {
 		return mocks.extensions.Update(id, name)
 	}
 
-	res, err := dbconn.Global.ExecContext(ctx,
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx,
 		"UPDATE registry_extensions SET name=COALESCE($2, name), updated_at=now() WHERE id=$1 AND deleted_at IS NULL",
 		id, name,
 	)
@@ -343,13 +353,49 @@ func (dbExtensions) Update(ctx context.Context, id int32, name *string) error {
 	return nil
 }
 
+// UpdateSigningPublicKey sets (or, if signingPublicKey is nil, clears) the public key that the
+// registry extension's release bundles must be signed with.
+func (dbExtensions) UpdateSigningPublicKey(ctx context.Context, id int32, signingPublicKey *string) error {
+	if mocks.extensions.UpdateSigningPublicKey != nil {
+		return mocks.extensions.UpdateSigningPublicKey(id, signingPublicKey)
+	}
+
+	return execExtensionUpdate(ctx, id, "UPDATE registry_extensions SET signing_public_key=$2, updated_at=now() WHERE id=$1 AND deleted_at IS NULL", id, signingPublicKey)
+}
+
+// UpdatePinnedRelease pins the registry extension to the release with the given ID or, if
+// releaseID is nil, unpins it (so that its latest release is used). The caller must ensure that
+// the release belongs to the extension.
+func (dbExtensions) UpdatePinnedRelease(ctx context.Context, id int32, releaseID *int64) error {
+	if mocks.extensions.UpdatePinnedRelease != nil {
+		return mocks.extensions.UpdatePinnedRelease(id, releaseID)
+	}
+
+	return execExtensionUpdate(ctx, id, "UPDATE registry_extensions SET pinned_release_id=$2 WHERE id=$1 AND deleted_at IS NULL", id, releaseID)
+}
+
+func execExtensionUpdate(ctx context.Context, id int32, query string, args ...interface{}) error {
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return extensionNotFoundError{[]interface{}{id}}
+	}
+	return nil
+}
+
 // Delete marks an registry extension as deleted.
 func (dbExtensions) Delete(ctx context.Context, id int32) error {
 	if mocks.extensions.Delete != nil {
 		return mocks.extensions.Delete(id)
 	}
 
-	res, err := dbconn.Global.ExecContext(ctx, "UPDATE registry_extensions SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+	res, err := dbconn.FromContext(ctx).ExecContext(ctx, "UPDATE registry_extensions SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
 	if err != nil {
 		return err
 	}
@@ -365,10 +411,13 @@ func (dbExtensions) Delete(ctx context.Context, id int32) error {
 
 // mockExtensions mocks the registry extensions store.
 type mockExtensions struct {
-	Create           func(publisherUserID, publisherOrgID int32, name string) (int32, error)
-	GetByID          func(id int32) (*dbExtension, error)
-	GetByUUID        func(uuid string) (*dbExtension, error)
-	GetByExtensionID func(extensionID string) (*dbExtension, error)
-	Update           func(id int32, name *string) error
-	Delete           func(id int32) error
+	Create                 func(publisherUserID, publisherOrgID int32, name string) (int32, error)
+	GetByID                func(id int32) (*dbExtension, error)
+	GetByUUID              func(uuid string) (*dbExtension, error)
+	GetByExtensionID       func(extensionID string) (*dbExtension, error)
+	GetPublisher           func(name string) (*dbPublisher, error)
+	Update                 func(id int32, name *string) error
+	UpdateSigningPublicKey func(id int32, signingPublicKey *string) error
+	UpdatePinnedRelease    func(id int32, releaseID *int64) error
+	Delete                 func(id int32) error
 }
@@ -7,14 +7,17 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	frontendregistry "github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/registry"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
@@ -67,19 +70,54 @@ var (
 		}
 		return toRegistryAPIExtension(ctx, x)
 	}
+
+	registryGetRelease = func(ctx context.Context, extensionID string, releaseID int64) (*registry.Extension, error) {
+		x, err := dbExtensions{}.GetByExtensionID(ctx, extensionID)
+		if err != nil {
+			return nil, err
+		}
+		release, err := dbReleases{}.GetByID(ctx, x.ID, releaseID)
+		if err != nil {
+			return nil, err
+		}
+		if err := prepReleaseManifest(x.NonCanonicalExtensionID, release); err != nil {
+			return nil, err
+		}
+		return newExtension(x, release), nil
+	}
+
+	registryListReleases = func(ctx context.Context, extensionID string) ([]*registry.Release, error) {
+		x, err := dbExtensions{}.GetByExtensionID(ctx, extensionID)
+		if err != nil {
+			return nil, err
+		}
+		releases, err := dbReleases{}.List(ctx, x.ID, &db.LimitOffset{Limit: maxRegistryAPIReleases})
+		if err != nil {
+			return nil, err
+		}
+		rs := make([]*registry.Release, len(releases))
+		for i, r := range releases {
+			rs[i] = &registry.Release{ID: r.ID, Version: r.ReleaseVersion, PublishedAt: r.CreatedAt, Signed: r.BundleSignature != nil}
+		}
+		return rs, nil
+	}
 )
 
+// maxRegistryAPIReleases is the maximum number of an extension's releases (newest first) that the
+// registry API lists.
+const maxRegistryAPIReleases = 100
+
+// registryReleasesPathRegexp matches the path of an extension's releases (and of a release) in the
+// registry API. Extension IDs (without the registry prefix) have exactly 2 path components.
+var registryReleasesPathRegexp = lazyregexp.New(`^extension-id/([^/]+/[^/]+)/releases(?:/(\d+))?$`)
+
 func toRegistryAPIExtension(ctx context.Context, v *dbExtension) (*registry.Extension, error) {
 	release, err := getLatestRelease(ctx, v.NonCanonicalExtensionID, v.ID, "release")
 	if err != nil {
 		return nil, err
 	}
 
-	if release == nil {
-		return newExtension(v, nil, time.Time{}), nil
-	}
-
-	return newExtension(v, &release.Manifest, release.CreatedAt), nil
+	return newExtension(v, release), nil
 }
 
 func toRegistryAPIExtensionBatch(ctx context.Context, vs []*dbExtension) ([]*registry.Extension, error) {
@@ -90,17 +128,23 @@ func toRegistryAPIExtensionBatch(ctx context.Context, vs []*dbExtension) ([]*reg
 
 	var extensions []*registry.Extension
 	for _, v := range vs {
-		release, ok := releasesByExtensionID[v.ID]
-		if !ok {
-			extensions = append(extensions, newExtension(v, nil, time.Time{}))
-		} else {
-			extensions = append(extensions, newExtension(v, &release.Manifest, release.CreatedAt))
-		}
+		extensions = append(extensions, newExtension(v, releasesByExtensionID[v.ID]))
 	}
 	return extensions, nil
 }
 
-func newExtension(v *dbExtension, manifest *string, publishedAt time.Time) *registry.Extension {
+// newExtension returns the registry API representation of the extension with the given release (or
+// nil if it has no releases). The release's manifest must have been prepared with
+// prepReleaseManifest.
+func newExtension(v *dbExtension, release *dbRelease) *registry.Extension {
+	var (
+		manifest    *string
+		publishedAt time.Time
+		releaseID   int64
+	)
+	if release != nil {
+		manifest, publishedAt, releaseID = &release.Manifest, release.CreatedAt, release.ID
+	}
 	baseURL := strings.TrimSuffix(conf.Get().ExternalURL, "/")
 	return &registry.Extension{
 		UUID:        v.UUID,
@@ -115,6 +159,7 @@ func newExtension(v *dbExtension, manifest *string, publishedAt time.Time) *regi
 		UpdatedAt:   v.UpdatedAt,
 		PublishedAt: publishedAt,
 		URL:         baseURL + frontendregistry.ExtensionURL(v.NonCanonicalExtensionID),
+		ReleaseID:   releaseID,
 	}
 }
 
@@ -145,6 +190,7 @@ func handleRegistry(w http.ResponseWriter, r *http.Request) (err error) {
 	}
 
 	const extensionsPath = "/registry/extensions"
+	releasesMatch := registryReleasesPathRegexp.FindStringSubmatch(strings.TrimPrefix(urlPath, extensionsPath+"/"))
 	var result interface{}
 	switch {
 	case urlPath == extensionsPath:
@@ -157,6 +203,18 @@ func handleRegistry(w http.ResponseWriter, r *http.Request) (err error) {
 		}
 		result = xs
 
+	case releasesMatch != nil && releasesMatch[2] == "":
+		releases, err := registryListReleases(r.Context(), releasesMatch[1])
+		if err != nil {
+			if errcode.IsNotFound(err) {
+				w.Header().Set("Cache-Control", "max-age=5, private")
+				http.Error(w, "extension not found", http.StatusNotFound)
+				return nil
+			}
+			return err
+		}
+		result = releases
+
 	case strings.HasPrefix(urlPath, extensionsPath+"/"):
 		var (
 			spec = strings.TrimPrefix(urlPath, extensionsPath+"/")
@@ -164,6 +222,9 @@ func handleRegistry(w http.ResponseWriter, r *http.Request) (err error) {
 			err  error
 		)
 		switch {
+		case releasesMatch != nil:
+			releaseID, _ := strconv.ParseInt(releasesMatch[2], 10, 64)
+			x, err = registryGetRelease(r.Context(), releasesMatch[1], releaseID)
 		case strings.HasPrefix(spec, "uuid/"):
 			x, err = registryGetByUUID(r.Context(), strings.TrimPrefix(spec, "uuid/"))
 		case strings.HasPrefix(spec, "extension-id/"):
@@ -0,0 +1,633 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	frontendregistry "github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/registry"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+func init() {
+	frontendregistry.HandleRegistryMirrorExport = handleRegistryMirrorExport
+	frontendregistry.HandleRegistryMirrorImport = handleRegistryMirrorImport
+}
+
+// A mirror archive is a gzipped tar file that packages extensions (their manifests, bundles and
+// source maps) so that they can be imported into the extension registry of a site that can't
+// access the remote registry (such as an air-gapped site). It contains:
+//
+//   index.json                the mirrorArchiveIndex (always the first file)
+//   bundles/N.js              the bundle of the Nth extension in the index
+//   bundles/N.js.map          the source map of the Nth extension in the index (if any)
+
+const (
+	mirrorArchiveVersion   = 1
+	mirrorArchiveIndexName = "index.json"
+
+	// maxMirrorArchiveFileSize is the maximum size of a single file in a mirror archive.
+	maxMirrorArchiveFileSize = 100 * 1024 * 1024
+
+	// maxMirrorArchiveUncompressedSize is the maximum total size of the files in a mirror archive
+	// that can be imported (after decompression).
+	maxMirrorArchiveUncompressedSize = 1024 * 1024 * 1024
+)
+
+// mirrorArchiveIndex describes the extensions in a mirror archive.
+type mirrorArchiveIndex struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exportedAt"`
+	Source     string             `json:"source"` // the URL of the site that exported the archive
+	Extensions []*mirrorExtension `json:"extensions"`
+}
+
+// mirrorExtension describes the latest release of an extension in a mirror archive.
+type mirrorExtension struct {
+	ExtensionID  string                     `json:"extensionID"` // on the source registry (without a registry prefix)
+	Publisher    string                     `json:"publisher"`
+	Name         string                     `json:"name"`
+	PublishedAt  time.Time                  `json:"publishedAt"`
+	Manifest     string                     `json:"manifest"` // without the "url" and "signature" fields
+	BundleSHA256 string                     `json:"bundleSHA256"`
+	HasSourceMap bool                       `json:"hasSourceMap,omitempty"`
+	Signature    *schema.ExtensionSignature `json:"signature,omitempty"`
+
+	bundle, sourceMap []byte
+
+	// loadArtifacts, if set, loads the bundle and source map of an exported extension when the
+	// archive is written (instead of bundle and sourceMap), so that only one extension's artifacts
+	// are held in memory at a time.
+	loadArtifacts func(ctx context.Context) (bundle, sourceMap []byte, err error)
+}
+
+func (x *mirrorExtension) artifacts(ctx context.Context) (bundle, sourceMap []byte, err error) {
+	if x.loadArtifacts != nil {
+		return x.loadArtifacts(ctx)
+	}
+	return x.bundle, x.sourceMap, nil
+}
+
+func mirrorBundleName(i int) string { return fmt.Sprintf("bundles/%d.js", i) }
+
+// writeMirrorArchive streams a mirror archive with the given index and the bundles and source maps
+// of its extensions to w. The index's BundleSHA256 and HasSourceMap fields must already be set,
+// because the index is written before the artifacts.
+func writeMirrorArchive(ctx context.Context, w io.Writer, index *mirrorArchiveIndex) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	writeFile := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: index.ExportedAt,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := writeFile(mirrorArchiveIndexName, indexData); err != nil {
+		return err
+	}
+	for i, x := range index.Extensions {
+		bundle, sourceMap, err := x.artifacts(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "loading bundle for extension %q", x.ExtensionID)
+		}
+		if sha256Hex(bundle) != x.BundleSHA256 || (sourceMap != nil) != x.HasSourceMap {
+			return fmt.Errorf("bundle for extension %q changed during the export", x.ExtensionID)
+		}
+		if err := writeFile(mirrorBundleName(i), bundle); err != nil {
+			return err
+		}
+		if sourceMap != nil {
+			if err := writeFile(mirrorBundleName(i)+".map", sourceMap); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gzw.Close()
+}
+
+// readMirrorArchive reads a mirror archive written by writeMirrorArchive and checks the integrity of
+// its bundles. Files that are not named in the index are skipped.
+func readMirrorArchive(r io.Reader) (*mirrorArchiveIndex, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading extension mirror archive")
+	}
+	tr := tar.NewReader(gzr)
+
+	var (
+		index *mirrorArchiveIndex
+		files map[string]*[]byte // the files named in the index, and where to store them
+		total int64
+	)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "reading extension mirror archive")
+		}
+
+		// 🚨 SECURITY: Limit the total decompressed size (including skipped files, which are still
+		// decompressed) so that a small archive can't make the import use unbounded resources.
+		total += h.Size
+		if total > maxMirrorArchiveUncompressedSize {
+			return nil, errors.New("extension mirror archive is too large (after decompression)")
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		var dst *[]byte
+		if index == nil {
+			if h.Name != mirrorArchiveIndexName {
+				return nil, fmt.Errorf("extension mirror archive does not start with %s", mirrorArchiveIndexName)
+			}
+		} else if dst = files[h.Name]; dst == nil {
+			continue
+		} else if *dst != nil {
+			return nil, fmt.Errorf("extension mirror archive has multiple files named %q", h.Name)
+		}
+		if h.Size > maxMirrorArchiveFileSize {
+			return nil, fmt.Errorf("file %q in extension mirror archive is too large", h.Name)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading extension mirror archive")
+		}
+		if dst != nil {
+			*dst = data
+			continue
+		}
+
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, errors.Wrap(err, "reading extension mirror archive index")
+		}
+		if index.Version != mirrorArchiveVersion {
+			return nil, fmt.Errorf("unsupported extension mirror archive version %d (expected %d)", index.Version, mirrorArchiveVersion)
+		}
+		files = make(map[string]*[]byte, 2*len(index.Extensions))
+		for i, x := range index.Extensions {
+			if x == nil {
+				return nil, errors.New("extension mirror archive index has an invalid extension")
+			}
+			files[mirrorBundleName(i)] = &x.bundle
+			if x.HasSourceMap {
+				files[mirrorBundleName(i)+".map"] = &x.sourceMap
+			}
+		}
+	}
+
+	if index == nil {
+		return nil, fmt.Errorf("extension mirror archive has no %s", mirrorArchiveIndexName)
+	}
+	for _, x := range index.Extensions {
+		if x.bundle == nil {
+			return nil, fmt.Errorf("extension mirror archive has no bundle for extension %q", x.ExtensionID)
+		}
+		if sha256Hex(x.bundle) != x.BundleSHA256 {
+			return nil, fmt.Errorf("bundle for extension %q in extension mirror archive is corrupt (SHA-256 mismatch)", x.ExtensionID)
+		}
+	}
+	return index, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// exportMirrorExtension gets the latest release (or the pinned release, if it is pinned) of the
+// extension with the given extension ID for a mirror archive. The extension may be local or on
+// the remote registry. Its bundle and source map are checked and then discarded; they are loaded
+// again when the archive is written (see writeMirrorArchive).
+//
+// If the release was not published after since, it returns nil without loading the bundle.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view this extension.
+func exportMirrorExtension(ctx context.Context, extensionID string, since time.Time) (*mirrorExtension, error) {
+	_, extensionIDWithoutPrefix, isLocal, err := frontendregistry.ParseExtensionID(extensionID)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &mirrorExtension{ExtensionID: extensionIDWithoutPrefix}
+	var manifest string
+	if isLocal {
+		v, err := dbExtensions{}.GetByExtensionID(ctx, extensionIDWithoutPrefix)
+		if err != nil {
+			return nil, err
+		}
+		release, err := dbReleases{}.GetLatest(ctx, v.ID, "release", false)
+		if err != nil {
+			return nil, err
+		}
+		x.Publisher, x.Name, x.PublishedAt = v.Publisher.NonCanonicalName, v.Name, release.CreatedAt
+		if !x.PublishedAt.After(since) {
+			return nil, nil
+		}
+		manifest = release.Manifest
+		// A release without a bundle refers to its bundle by the URL in its manifest.
+		bundle, sourceMap, err := dbReleases{}.GetArtifacts(ctx, release.ID)
+		if err != nil && !errcode.IsNotFound(err) {
+			return nil, err
+		}
+		if bundle != nil {
+			x.bundle, x.sourceMap = bundle, sourceMap
+			x.loadArtifacts = func(ctx context.Context) ([]byte, []byte, error) {
+				return dbReleases{}.GetArtifacts(ctx, release.ID)
+			}
+			if release.BundleSignature != nil && release.SigningPublicKey != nil {
+				x.Signature = &schema.ExtensionSignature{PublicKey: *release.SigningPublicKey, BundleSignature: *release.BundleSignature}
+			}
+		}
+	} else {
+		_, remote, err := frontendregistry.GetExtensionByExtensionID(ctx, extensionID)
+		if err != nil {
+			return nil, err
+		}
+		if remote == nil || remote.Manifest == nil {
+			return nil, fmt.Errorf("extension %q has no releases on the remote registry", extensionID)
+		}
+		x.Publisher, x.Name, x.PublishedAt = remote.Publisher.Name, remote.Name, remote.PublishedAt
+		if !x.PublishedAt.After(since) {
+			return nil, nil
+		}
+		manifest = *remote.Manifest
+	}
+
+	var parsed schema.SourcegraphExtensionManifest
+	if err := jsonc.Unmarshal(manifest, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid manifest for extension %q: %s", extensionID, err)
+	}
+	if x.bundle == nil {
+		// The bundle is not in the local registry, so fetch it from the URL in the manifest.
+		if parsed.Url == "" {
+			return nil, fmt.Errorf("extension %q has no bundle", extensionID)
+		}
+		if x.bundle, err = fetchMirrorBundle(ctx, parsed.Url); err != nil {
+			return nil, errors.Wrapf(err, "fetching bundle for extension %q", extensionID)
+		}
+		x.loadArtifacts = func(ctx context.Context) ([]byte, []byte, error) {
+			bundle, err := fetchMirrorBundle(ctx, parsed.Url)
+			return bundle, nil, err
+		}
+		x.Signature = parsed.Signature
+	}
+	if x.Signature != nil {
+		if err := verifyBundleSignature(x.Signature.PublicKey, x.Signature.BundleSignature, x.bundle); err != nil {
+			return nil, errors.Wrapf(err, "extension %q", extensionID)
+		}
+	}
+	x.BundleSHA256, x.HasSourceMap = sha256Hex(x.bundle), x.sourceMap != nil
+	x.bundle, x.sourceMap = nil, nil
+
+	// Remove the fields that refer to the source site. The importing site's registry adds them for
+	// its own copy of the bundle.
+	if x.Manifest, err = removeManifestFields(manifest, "url", "signature"); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// fetchMirrorBundle fetches an extension bundle from a URL (typically on the remote registry).
+var fetchMirrorBundle = func(ctx context.Context, bundleURL string) ([]byte, error) {
+	resp, err := ctxhttp.Get(ctx, registry.HTTPClient, bundleURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected HTTP status %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxMirrorArchiveFileSize))
+}
+
+func removeManifestFields(manifest string, fields ...string) (string, error) {
+	var o map[string]interface{}
+	if err := jsonc.Unmarshal(manifest, &o); err != nil {
+		return "", err
+	}
+	for _, f := range fields {
+		delete(o, f)
+	}
+	b, err := json.MarshalIndent(o, "", "  ")
+	return string(b), err
+}
+
+// Results of importing an extension from a mirror archive.
+const (
+	mirrorImportCreated   = "created"   // the extension was created in the local registry
+	mirrorImportUpdated   = "updated"   // a new release of the existing local extension was published
+	mirrorImportUnchanged = "unchanged" // the local extension's latest release is identical
+)
+
+// importMirrorExtension publishes the release of an extension in a mirror archive to the local
+// registry, creating the extension if needed. The extension is published by the local user or
+// organization named in publishers (a map from the archive's publisher names to local publisher
+// names), or else with the same name as in the archive. It returns the ID of the local extension.
+//
+// The caller should call it with a context that carries a transaction (such as the one passed to
+// the action of backend.PerformAuditedAction), so that a failed import doesn't leave behind a new
+// extension without a release or an adopted signing public key.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func importMirrorExtension(ctx context.Context, x *mirrorExtension, publishers map[string]string, creatorUserID int32) (registryExtensionID int32, result string, err error) {
+	if err := validateExtensionManifest(x.Manifest); err != nil {
+		return 0, "", fmt.Errorf("invalid extension manifest: %s", err)
+	}
+
+	publisherName := x.Publisher
+	if name, ok := publishers[publisherName]; ok {
+		publisherName = name
+	}
+	publisher, err := dbExtensions{}.GetPublisher(ctx, publisherName)
+	if errcode.IsNotFound(err) {
+		return 0, "", fmt.Errorf("no user or organization named %q exists to publish the extension (create it or map the publisher to another name)", publisherName)
+	} else if err != nil {
+		return 0, "", err
+	}
+
+	result = mirrorImportUpdated
+	v, err := dbExtensions{}.GetByExtensionID(ctx, publisherName+"/"+x.Name)
+	if errcode.IsNotFound(err) {
+		id, err := dbExtensions{}.Create(ctx, publisher.UserID, publisher.OrgID, x.Name)
+		if err != nil {
+			return 0, "", err
+		}
+		if v, err = (dbExtensions{}).GetByID(ctx, id); err != nil {
+			return 0, "", err
+		}
+		result = mirrorImportCreated
+	} else if err != nil {
+		return 0, "", err
+	}
+
+	// 🚨 SECURITY: Keep the supply-chain guarantees of signed extensions. A signed release may only
+	// be imported if the local extension has the same signing key (or none yet, in which case the
+	// key is adopted), and an unsigned release may not be imported into a signed extension.
+	var bundleSignature *string
+	if x.Signature != nil {
+		normalized, err := normalizeBundleSignature(x.Signature.BundleSignature)
+		if err != nil {
+			return 0, "", err
+		}
+		if err := verifyBundleSignature(x.Signature.PublicKey, normalized, x.bundle); err != nil {
+			return 0, "", err
+		}
+		if v.SigningPublicKey != nil && *v.SigningPublicKey != x.Signature.PublicKey {
+			return 0, "", errors.New("the release is signed with a different key than the local extension's signing public key")
+		}
+		bundleSignature = &normalized
+	} else if v.SigningPublicKey != nil {
+		return 0, "", errors.New("the local extension requires signed releases, but the release is unsigned")
+	}
+
+	if result != mirrorImportCreated {
+		unchanged, err := isLatestMirrorRelease(ctx, v.ID, x)
+		if err != nil {
+			return 0, "", err
+		}
+		if unchanged {
+			return v.ID, mirrorImportUnchanged, nil
+		}
+	}
+
+	if x.Signature != nil && v.SigningPublicKey == nil {
+		if err := (dbExtensions{}).UpdateSigningPublicKey(ctx, v.ID, &x.Signature.PublicKey); err != nil {
+			return 0, "", err
+		}
+	}
+	release := dbRelease{
+		RegistryExtensionID: v.ID,
+		CreatorUserID:       creatorUserID,
+		ReleaseTag:          "release",
+		Manifest:            x.Manifest,
+		Bundle:              strptr(string(x.bundle)),
+		BundleSignature:     bundleSignature,
+	}
+	if x.Signature != nil {
+		release.SigningPublicKey = &x.Signature.PublicKey
+	}
+	if x.sourceMap != nil {
+		release.SourceMap = strptr(string(x.sourceMap))
+	}
+	if _, err := (dbReleases{}).Create(ctx, &release); err != nil {
+		return 0, "", err
+	}
+	return v.ID, result, nil
+}
+
+// isLatestMirrorRelease reports whether the latest release of the local extension has the same
+// manifest and bundle as the extension in the mirror archive (so that importing the same archive,
+// or a newer archive with only some updated extensions, does not publish duplicate releases).
+func isLatestMirrorRelease(ctx context.Context, registryExtensionID int32, x *mirrorExtension) (bool, error) {
+	releases, err := dbReleases{}.List(ctx, registryExtensionID, &db.LimitOffset{Limit: 1})
+	if err != nil || len(releases) == 0 {
+		return false, err
+	}
+	var a, b interface{}
+	if err := json.Unmarshal([]byte(releases[0].Manifest), &a); err != nil {
+		return false, err
+	}
+	if err := jsonc.Unmarshal(x.Manifest, &b); err != nil {
+		return false, err
+	}
+	if !reflect.DeepEqual(a, b) {
+		return false, nil
+	}
+	bundle, _, err := dbReleases{}.GetArtifacts(ctx, releases[0].ID)
+	if errcode.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return bytes.Equal(bundle, x.bundle), nil
+}
+
+// parseMirrorPublisherMappings parses publisher mappings of the form "archive-name:local-name".
+func parseMirrorPublisherMappings(values []string) (map[string]string, error) {
+	publishers := make(map[string]string, len(values))
+	for _, v := range values {
+		i := strings.Index(v, ":")
+		if i <= 0 || i == len(v)-1 {
+			return nil, fmt.Errorf("invalid publisher mapping %q (expected ARCHIVE-PUBLISHER:LOCAL-PUBLISHER)", v)
+		}
+		publishers[v[:i]] = v[i+1:]
+	}
+	return publishers, nil
+}
+
+// maxMirrorArchiveSize is the maximum size of a mirror archive that can be imported.
+const maxMirrorArchiveSize = 512 * 1024 * 1024
+
+// handleRegistryMirrorExport serves a mirror archive of the extensions given in the "extension"
+// query parameters (local or remote extension IDs). If the "since" query parameter (an RFC 3339
+// timestamp) is given, only extensions published after that time are included, which allows an
+// air-gapped site to be refreshed incrementally.
+func handleRegistryMirrorExport(w http.ResponseWriter, r *http.Request) error {
+	if conf.Extensions() == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	// 🚨 SECURITY: Only site admins may export extensions, because the archive may include
+	// extensions that other users are not permitted to view on the remote registry.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil
+	}
+
+	extensionIDs := r.URL.Query()["extension"]
+	if len(extensionIDs) == 0 {
+		http.Error(w, `at least 1 "extension" query parameter is required`, http.StatusBadRequest)
+		return nil
+	}
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			http.Error(w, `invalid "since" query parameter: `+err.Error(), http.StatusBadRequest)
+			return nil
+		}
+	}
+
+	index := mirrorArchiveIndex{
+		Version:    mirrorArchiveVersion,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Source:     conf.Get().ExternalURL,
+	}
+	for _, extensionID := range extensionIDs {
+		x, err := exportMirrorExtension(r.Context(), extensionID, since)
+		if errcode.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if x != nil {
+			index.Extensions = append(index.Extensions, x)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=sourcegraph-extensions-%s.tar.gz", index.ExportedAt.Format("20060102T150405Z")))
+	if err := writeMirrorArchive(r.Context(), w, &index); err != nil {
+		// The response has already started, so the error can't be reported to the client. The
+		// archive is truncated, so it fails to be imported.
+		log15.Error("Failed to write extension mirror archive.", "error", err)
+	}
+	return nil
+}
+
+// mirrorImportResult describes the result of importing an extension from a mirror archive.
+type mirrorImportResult struct {
+	ExtensionID string `json:"extensionID"`
+	Result      string `json:"result,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// handleRegistryMirrorImport imports the extensions in the mirror archive in the request body into
+// the local extension registry. Extensions are published by the user or organization with the same
+// name as their publisher in the archive, unless a "publisher" query parameter of the form
+// "archive-name:local-name" maps it to another name.
+//
+// Extensions that fail to import are reported in the response and do not prevent the other
+// extensions from being imported.
+func handleRegistryMirrorImport(w http.ResponseWriter, r *http.Request) error {
+	if conf.Extensions() == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+	if err := licensing.CheckFeature(licensing.FeatureExtensionRegistry); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil
+	}
+	// 🚨 SECURITY: Only site admins may import extensions, because they are published on behalf of
+	// any user or organization.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil
+	}
+
+	publishers, err := parseMirrorPublisherMappings(r.URL.Query()["publisher"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+	index, err := readMirrorArchive(http.MaxBytesReader(w, r.Body, maxMirrorArchiveSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	results := make([]mirrorImportResult, len(index.Extensions))
+	for i, x := range index.Extensions {
+		results[i].ExtensionID = x.ExtensionID
+		var result string
+		err := backend.PerformAuditedAction(r.Context(), func(ctx context.Context) (*backend.AuditEvent, error) {
+			var (
+				id  int32
+				err error
+			)
+			id, result, err = importMirrorExtension(ctx, x, publishers, actor.FromContext(ctx).UID)
+			if err != nil || result == mirrorImportUnchanged {
+				return nil, err
+			}
+			return &backend.AuditEvent{
+				Action:     backend.AuditActionExtensionMirrorImport,
+				TargetType: "RegistryExtension",
+				TargetID:   string(frontendregistry.MarshalRegistryExtensionID(frontendregistry.RegistryExtensionID{LocalID: id})),
+				After:      strptr(index.Source + "/" + x.ExtensionID),
+			}, nil
+		})
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Result = result
+		if result != mirrorImportUnchanged {
+			log15.Info("Imported extension from mirror archive.", "extensionID", x.ExtensionID, "source", index.Source, "result", result)
+		}
+	}
+	return json.NewEncoder(w).Encode(struct {
+		Extensions []mirrorImportResult `json:"extensions"`
+	}{Extensions: results})
+}
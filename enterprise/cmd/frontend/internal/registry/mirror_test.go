@@ -0,0 +1,318 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMirrorArchive(t *testing.T) {
+	ctx := context.Background()
+	want := &mirrorArchiveIndex{
+		Version:    mirrorArchiveVersion,
+		ExportedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Source:     "https://sourcegraph.example.com",
+		Extensions: []*mirrorExtension{
+			{ExtensionID: "a/b", Publisher: "a", Name: "b", Manifest: `{}`, BundleSHA256: sha256Hex([]byte("b1")), HasSourceMap: true, bundle: []byte("b1"), sourceMap: []byte("m1")},
+			{ExtensionID: "a/c", Publisher: "a", Name: "c", Manifest: `{}`, BundleSHA256: sha256Hex([]byte("b2")), bundle: []byte("b2"), Signature: &schema.ExtensionSignature{PublicKey: "k", BundleSignature: "s"}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := writeMirrorArchive(ctx, &buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readMirrorArchive(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	t.Run("bundle changed during export", func(t *testing.T) {
+		index := *want
+		index.Extensions = []*mirrorExtension{{
+			ExtensionID:  "a/b",
+			BundleSHA256: sha256Hex([]byte("b1")),
+			loadArtifacts: func(context.Context) ([]byte, []byte, error) {
+				return []byte("b2"), nil, nil
+			},
+		}}
+		if err := writeMirrorArchive(ctx, ioutil.Discard, &index); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+
+	validIndex := []byte(`{"version":1,"extensions":[{"extensionID":"a/b","bundleSHA256":"` + sha256Hex([]byte("b1")) + `"}]}`)
+
+	t.Run("skips files not in the index", func(t *testing.T) {
+		index, err := readMirrorArchive(writeTestMirrorArchive(t,
+			testArchiveFile{name: mirrorArchiveIndexName, data: validIndex},
+			testArchiveFile{name: "other", data: []byte("x")},
+			testArchiveFile{name: mirrorBundleName(0) + ".map", data: []byte("m")},
+			testArchiveFile{name: mirrorBundleName(0), data: []byte("b1")},
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x := index.Extensions[0]; string(x.bundle) != "b1" || x.sourceMap != nil {
+			t.Errorf("got bundle %q and source map %q, want bundle %q and no source map", x.bundle, x.sourceMap, "b1")
+		}
+	})
+
+	t.Run("index is not first", func(t *testing.T) {
+		if _, err := readMirrorArchive(writeTestMirrorArchive(t,
+			testArchiveFile{name: mirrorBundleName(0), data: []byte("b1")},
+			testArchiveFile{name: mirrorArchiveIndexName, data: validIndex},
+		)); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+
+	t.Run("too large after decompression", func(t *testing.T) {
+		if _, err := readMirrorArchive(writeTestMirrorArchive(t,
+			testArchiveFile{name: mirrorArchiveIndexName, data: validIndex},
+			testArchiveFile{name: "other", size: maxMirrorArchiveUncompressedSize},
+		)); err == nil || !strings.Contains(err.Error(), "too large") {
+			t.Errorf("got error %v, want too large", err)
+		}
+	})
+
+	t.Run("corrupt bundle", func(t *testing.T) {
+		if _, err := readMirrorArchive(writeTestMirrorArchive(t,
+			testArchiveFile{name: mirrorArchiveIndexName, data: []byte(`{"version":1,"extensions":[{"extensionID":"a/b","bundleSHA256":"0000"}]}`)},
+			testArchiveFile{name: mirrorBundleName(0), data: []byte("b1")},
+		)); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		if _, err := readMirrorArchive(bytes.NewReader([]byte("x"))); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+}
+
+// testArchiveFile is a file in a mirror archive written by writeTestMirrorArchive. If data is nil,
+// only a header with the given size is written, and the archive is truncated after it.
+type testArchiveFile struct {
+	name string
+	data []byte
+	size int64
+}
+
+// writeTestMirrorArchive writes a mirror archive with the given files in order.
+func writeTestMirrorArchive(t *testing.T, files ...testArchiveFile) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	truncated := false
+	for _, f := range files {
+		if f.data == nil {
+			if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: f.size}); err != nil {
+				t.Fatal(err)
+			}
+			truncated = true
+			break
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !truncated {
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExportMirrorExtension_Since(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	orig := envvar.SourcegraphDotComMode()
+	envvar.MockSourcegraphDotComMode(true) // extension IDs without a prefix are local
+	defer envvar.MockSourcegraphDotComMode(orig)
+	ctx := context.Background()
+
+	publishedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	bundle := []byte("exports.activate = () => {}")
+	mocks.extensions.GetByExtensionID = func(extensionID string) (*dbExtension, error) {
+		return &dbExtension{ID: 1, Name: "b", Publisher: dbPublisher{NonCanonicalName: "a"}}, nil
+	}
+	mocks.releases.GetLatest = func(registryExtensionID int32, releaseTag string, includeArtifacts bool) (*dbRelease, error) {
+		if includeArtifacts {
+			t.Error("want release to be loaded without artifacts")
+		}
+		return &dbRelease{ID: 1, Manifest: `{}`, CreatedAt: publishedAt}, nil
+	}
+	var loaded bool
+	mocks.releases.GetArtifacts = func(id int64) ([]byte, []byte, error) {
+		loaded = true
+		return bundle, nil, nil
+	}
+
+	x, err := exportMirrorExtension(ctx, "a/b", publishedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x != nil || loaded {
+		t.Errorf("got extension %+v (artifacts loaded: %v), want nil without loading artifacts", x, loaded)
+	}
+
+	x, err = exportMirrorExtension(ctx, "a/b", publishedAt.Add(-time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x == nil || x.BundleSHA256 != sha256Hex(bundle) {
+		t.Errorf("got extension %+v, want extension with bundle", x)
+	}
+}
+
+func TestParseMirrorPublisherMappings(t *testing.T) {
+	got, err := parseMirrorPublisherMappings([]string{"sourcegraph:acme", "alice:bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"sourcegraph": "acme", "alice": "bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, v := range []string{"", "a", ":b", "a:"} {
+		if _, err := parseMirrorPublisherMappings([]string{v}); err == nil {
+			t.Errorf("%q: got nil error, want non-nil", v)
+		}
+	}
+}
+
+func TestImportMirrorExtension(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	ctx := context.Background()
+
+	const manifest = `{"activationEvents": ["*"]}`
+	bundle := []byte("exports.activate = () => {}")
+	key, publicKey := testSigningKey(t)
+
+	var (
+		existing  *dbExtension
+		latest    *dbRelease
+		created   *dbRelease
+		publisher string
+	)
+	mocks.extensions.GetPublisher = func(name string) (*dbPublisher, error) {
+		publisher = name
+		return &dbPublisher{OrgID: 1, NonCanonicalName: name}, nil
+	}
+	mocks.extensions.GetByExtensionID = func(extensionID string) (*dbExtension, error) {
+		if existing == nil {
+			return nil, extensionNotFoundError{[]interface{}{extensionID}}
+		}
+		return existing, nil
+	}
+	mocks.extensions.Create = func(publisherUserID, publisherOrgID int32, name string) (int32, error) {
+		existing = &dbExtension{ID: 1, Name: name}
+		return 1, nil
+	}
+	mocks.extensions.GetByID = func(id int32) (*dbExtension, error) { return existing, nil }
+	mocks.extensions.UpdateSigningPublicKey = func(id int32, signingPublicKey *string) error {
+		existing.SigningPublicKey = signingPublicKey
+		return nil
+	}
+	mocks.releases.List = func(registryExtensionID int32, limitOffset *db.LimitOffset) ([]*dbRelease, error) {
+		if latest == nil {
+			return nil, nil
+		}
+		return []*dbRelease{latest}, nil
+	}
+	mocks.releases.GetArtifacts = func(id int64) ([]byte, []byte, error) {
+		return []byte(*latest.Bundle), nil, nil
+	}
+	mocks.releases.Create = func(release *dbRelease) (int64, error) {
+		created, latest = release, release
+		return 1, nil
+	}
+
+	x := &mirrorExtension{ExtensionID: "sourcegraph/b", Publisher: "sourcegraph", Name: "b", Manifest: manifest, bundle: bundle}
+	publishers := map[string]string{"sourcegraph": "acme"}
+
+	t.Run("create", func(t *testing.T) {
+		_, result, err := importMirrorExtension(ctx, x, publishers, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != mirrorImportCreated {
+			t.Errorf("got result %q, want %q", result, mirrorImportCreated)
+		}
+		if publisher != "acme" {
+			t.Errorf("got publisher %q, want %q", publisher, "acme")
+		}
+		if created == nil || *created.Bundle != string(bundle) || created.Manifest != manifest {
+			t.Errorf("got release %+v, want bundle and manifest from archive", created)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		created = nil
+		_, result, err := importMirrorExtension(ctx, x, publishers, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != mirrorImportUnchanged || created != nil {
+			t.Errorf("got result %q (release %+v), want %q", result, created, mirrorImportUnchanged)
+		}
+	})
+
+	t.Run("signed update", func(t *testing.T) {
+		y := *x
+		y.bundle = []byte("exports.activate = () => {};")
+		y.Signature = &schema.ExtensionSignature{PublicKey: publicKey, BundleSignature: testSignDER(t, key, y.bundle)}
+		_, result, err := importMirrorExtension(ctx, &y, publishers, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != mirrorImportUpdated {
+			t.Errorf("got result %q, want %q", result, mirrorImportUpdated)
+		}
+		if existing.SigningPublicKey == nil || *existing.SigningPublicKey != publicKey {
+			t.Error("want signing public key to be adopted from the archive")
+		}
+		if created.BundleSignature == nil {
+			t.Error("want release to have a bundle signature")
+		}
+	})
+
+	t.Run("unsigned update of signed extension", func(t *testing.T) {
+		y := *x
+		y.bundle = []byte("exports.activate = () => { evil() }")
+		if _, _, err := importMirrorExtension(ctx, &y, publishers, 1); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+
+	t.Run("signed with other key", func(t *testing.T) {
+		otherKey, otherPublicKey := testSigningKey(t)
+		y := *x
+		y.Signature = &schema.ExtensionSignature{PublicKey: otherPublicKey, BundleSignature: testSignDER(t, otherKey, y.bundle)}
+		if _, _, err := importMirrorExtension(ctx, &y, publishers, 1); err == nil {
+			t.Error("got nil error, want non-nil")
+		}
+	})
+}
@@ -7,6 +7,7 @@ func resetMocks() {
 type dbMocks struct {
 	extensions mockExtensions
 	releases   mockReleases
+	remotePins mockRemotePins
 }
 
 var mocks dbMocks
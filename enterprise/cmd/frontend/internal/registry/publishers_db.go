@@ -78,7 +78,7 @@ ORDER BY org_id ASC NULLS LAST, user_id ASC NULLS LAST
 		limitOffset.SQL(),
 	)
 
-	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +104,7 @@ ORDER BY org_id ASC NULLS LAST, user_id ASC NULLS LAST
 func (s dbExtensions) CountPublishers(ctx context.Context, opt dbPublishersListOptions) (int, error) {
 	q := sqlf.Sprintf(`%s SELECT COUNT(*) FROM publishers WHERE (%s)`, s.publishersSQLCTE(), sqlf.Join(opt.sqlConditions(), ") AND ("))
 	var count int
-	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -112,6 +112,10 @@ func (s dbExtensions) CountPublishers(ctx context.Context, opt dbPublishersListO
 
 // GePublisher gets the registry publisher with the given name.
 func (s dbExtensions) GetPublisher(ctx context.Context, name string) (*dbPublisher, error) {
+	if mocks.extensions.GetPublisher != nil {
+		return mocks.extensions.GetPublisher(name)
+	}
+
 	var userID, orgID sql.NullInt64
 	var p dbPublisher
 	q := sqlf.Sprintf(`
@@ -122,7 +126,7 @@ WITH publishers AS (
 )
 SELECT user_id, org_id, non_canonical_name FROM publishers ORDER BY user_id NULLS LAST LIMIT 1
 `, name, name)
-	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&userID, &orgID, &p.NonCanonicalName)
+	err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&userID, &orgID, &p.NonCanonicalName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, &publisherNotFoundError{[]interface{}{"name", name}}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	frontendregistry "github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
@@ -19,6 +20,8 @@ func init() {
 	frontendregistry.ExtensionRegistry.UpdateExtensionFunc = extensionRegistryUpdateExtension
 	frontendregistry.ExtensionRegistry.DeleteExtensionFunc = extensionRegistryDeleteExtension
 	frontendregistry.ExtensionRegistry.PublishExtensionFunc = extensionRegistryPublishExtension
+	frontendregistry.ExtensionRegistry.UpdateExtensionSigningPublicKeyFunc = extensionRegistryUpdateExtensionSigningPublicKey
+	frontendregistry.ExtensionRegistry.PinExtensionReleaseFunc = extensionRegistryPinExtensionRelease
 }
 
 func registryExtensionByIDInt32(ctx context.Context, id int32) (graphqlbackend.RegistryExtension, error) {
@@ -174,6 +177,11 @@ func extensionRegistryPublishExtension(ctx context.Context, args *graphqlbackend
 		}
 	}
 
+	bundleSignature, signingPublicKey, err := checkBundleSignature(ctx, id.LocalID, args.Bundle, args.BundleSignature)
+	if err != nil {
+		return nil, err
+	}
+
 	release := dbRelease{
 		RegistryExtensionID: id.LocalID,
 		CreatorUserID:       actor.FromContext(ctx).UID,
@@ -181,9 +189,200 @@ func extensionRegistryPublishExtension(ctx context.Context, args *graphqlbackend
 		Manifest:            args.Manifest,
 		Bundle:              args.Bundle,
 		SourceMap:           args.SourceMap,
+		BundleSignature:     bundleSignature,
+		SigningPublicKey:    signingPublicKey,
 	}
 	if _, err := (dbReleases{}).Create(ctx, &release); err != nil {
 		return nil, err
 	}
 	return &frontendregistry.ExtensionRegistryMutationResult{ID: id.LocalID}, nil
 }
+
+// checkBundleSignature checks the signature of a bundle being published and returns it in the form
+// to store, along with the signing public key it was verified with (which is stored with the
+// release). If the extension has a signing public key, the bundle must have a valid signature.
+func checkBundleSignature(ctx context.Context, registryExtensionID int32, bundle, signature *string) (bundleSignature, signingPublicKey *string, err error) {
+	extension, err := dbExtensions{}.GetByID(ctx, registryExtensionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if extension.SigningPublicKey == nil {
+		if signature != nil {
+			return nil, nil, errors.New("unable to publish a signed bundle for an extension with no signing public key (set the extension's signing public key first)")
+		}
+		return nil, nil, nil
+	}
+
+	// 🚨 SECURITY: Only accept bundles signed with the extension's signing key.
+	if bundle == nil || signature == nil {
+		return nil, nil, fmt.Errorf("extension %q requires signed releases (publish it with a bundle and its signature)", extension.NonCanonicalExtensionID)
+	}
+	normalized, err := normalizeBundleSignature(*signature)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyBundleSignature(*extension.SigningPublicKey, normalized, []byte(*bundle)); err != nil {
+		return nil, nil, err
+	}
+	return &normalized, extension.SigningPublicKey, nil
+}
+
+func extensionRegistryUpdateExtensionSigningPublicKey(ctx context.Context, args *graphqlbackend.ExtensionRegistryUpdateExtensionSigningPublicKeyArgs) (graphqlbackend.ExtensionRegistryMutationResult, error) {
+	if err := licensing.CheckFeature(licensing.FeatureExtensionRegistry); err != nil {
+		return nil, err
+	}
+
+	id, err := frontendregistry.UnmarshalRegistryExtensionID(args.Extension)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Check that the current user is authorized to update the extension.
+	if err := viewerCanAdministerExtension(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if args.SigningPublicKey != nil {
+		if _, err := parseSigningPublicKey(*args.SigningPublicKey); err != nil {
+			return nil, err
+		}
+	}
+	extension, err := dbExtensions{}.GetByID(ctx, id.LocalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Clearing or rotating an extension's signing public key would let a compromised
+	// publisher account publish bundles that aren't signed by the original key, so only site admins
+	// may do it.
+	if extension.SigningPublicKey != nil && (args.SigningPublicKey == nil || *args.SigningPublicKey != *extension.SigningPublicKey) {
+		if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+			return nil, errors.New("only site admins may clear or change an extension's signing public key (ask a site admin to do it)")
+		}
+	}
+
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := (dbExtensions{}).UpdateSigningPublicKey(ctx, id.LocalID, args.SigningPublicKey); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionExtensionSigningPublicKeySet,
+			TargetType: "RegistryExtension",
+			TargetID:   string(args.Extension),
+			Before:     extension.SigningPublicKey,
+			After:      args.SigningPublicKey,
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+	return &frontendregistry.ExtensionRegistryMutationResult{ID: id.LocalID}, nil
+}
+
+func extensionRegistryPinExtensionRelease(ctx context.Context, args *graphqlbackend.ExtensionRegistryPinExtensionReleaseArgs) (graphqlbackend.ExtensionRegistryMutationResult, error) {
+	if err := licensing.CheckFeature(licensing.FeatureExtensionRegistry); err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Only site admins may choose which release of an extension the site uses.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := frontendregistry.UnmarshalRegistryExtensionID(args.Extension)
+	if err != nil {
+		return nil, err
+	}
+	if id.RemoteID != nil {
+		return pinRemoteExtensionRelease(ctx, args, id.RemoteID.UUID)
+	}
+	extension, err := dbExtensions{}.GetByID(ctx, id.LocalID)
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseID *int64
+	if args.Release != nil {
+		rid, err := unmarshalRegistryExtensionReleaseID(*args.Release)
+		if err != nil {
+			return nil, err
+		}
+		// Check that the release belongs to the extension.
+		if _, err := (dbReleases{}).GetByID(ctx, id.LocalID, rid); err != nil {
+			return nil, err
+		}
+		releaseID = &rid
+	}
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		if err := (dbExtensions{}).UpdatePinnedRelease(ctx, id.LocalID, releaseID); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionExtensionPinnedReleaseSet,
+			TargetType: "RegistryExtension",
+			TargetID:   string(args.Extension),
+			Before:     formatReleaseID(extension.PinnedReleaseID),
+			After:      formatReleaseID(releaseID),
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+	return &frontendregistry.ExtensionRegistryMutationResult{ID: id.LocalID}, nil
+}
+
+// pinRemoteExtensionRelease pins the extension (with the given UUID) on the remote registry to the
+// release with the given ID on the remote registry, or unpins it. The caller must check that the
+// current user is a site admin.
+func pinRemoteExtensionRelease(ctx context.Context, args *graphqlbackend.ExtensionRegistryPinExtensionReleaseArgs, uuid string) (graphqlbackend.ExtensionRegistryMutationResult, error) {
+	// Get the extension without applying its pin, so that it can be unpinned even if its pinned
+	// release is unavailable.
+	x, err := frontendregistry.GetRemoteRegistryExtensionByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if x == nil {
+		return nil, errors.New("no remote extension registry is configured")
+	}
+
+	var releaseID *int64
+	if args.Release != nil {
+		rid, err := unmarshalRegistryExtensionReleaseID(*args.Release)
+		if err != nil {
+			return nil, err
+		}
+		// Check that the release of the extension exists on the remote registry.
+		if _, err := frontendregistry.GetRemoteRegistryRelease(ctx, x.ExtensionID, rid); err != nil {
+			return nil, err
+		}
+		releaseID = &rid
+	}
+	if err := backend.PerformAuditedAction(ctx, func(ctx context.Context) (*backend.AuditEvent, error) {
+		pins, err := dbRemotePins{}.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var before *int64
+		if id, ok := pins[x.ExtensionID]; ok {
+			before = &id
+		}
+		if err := (dbRemotePins{}).Set(ctx, x.ExtensionID, releaseID); err != nil {
+			return nil, err
+		}
+		return &backend.AuditEvent{
+			Action:     backend.AuditActionExtensionPinnedReleaseSet,
+			TargetType: "RegistryExtension",
+			TargetID:   string(args.Extension),
+			Before:     formatReleaseID(before),
+			After:      formatReleaseID(releaseID),
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+	return &frontendregistry.ExtensionRegistryMutationResult{RemoteExtensionID: x.ExtensionID}, nil
+}
+
+func formatReleaseID(id *int64) *string {
+	if id == nil {
+		return nil
+	}
+	return strptr(string(marshalRegistryExtensionReleaseID(*id)))
+}
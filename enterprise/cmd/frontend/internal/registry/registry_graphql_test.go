@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/registry"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestPinRemoteExtensionRelease(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	defer licensing.TestingSkipFeatureChecks()()
+	defer func() { db.Mocks = db.MockStores{} }()
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+
+	const uuid = "5b5b4e4e-6cb1-4a5d-9a3f-1a2b3c4d5e6f"
+
+	// The remote registry has release 1 of the extension a/b (with UUID uuid), but not release 2.
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(registry.MediaTypeHeaderName, registry.MediaType)
+		switch r.URL.Path {
+		case "/extensions/uuid/" + uuid:
+			_ = json.NewEncoder(w).Encode(&registry.Extension{UUID: uuid, ExtensionID: "a/b", ReleaseID: 3})
+		case "/extensions/extension-id/a/b/releases/1":
+			_ = json.NewEncoder(w).Encode(&registry.Extension{UUID: uuid, ExtensionID: "a/b", ReleaseID: 1})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer remote.Close()
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{Extensions: &schema.Extensions{RemoteRegistry: remote.URL}}})
+	defer conf.Mock(nil)
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	var auditLog []*db.AuditLogEntry
+	db.Mocks.AuditLog.Insert = func(e *db.AuditLogEntry) error {
+		auditLog = append(auditLog, e)
+		return nil
+	}
+	pins := map[string]int64{}
+	mocks.remotePins.List = func() (map[string]int64, error) { return pins, nil }
+	mocks.remotePins.Set = func(extensionID string, releaseID *int64) error {
+		if releaseID == nil {
+			delete(pins, extensionID)
+		} else {
+			pins[extensionID] = *releaseID
+		}
+		return nil
+	}
+
+	extension := relay.MarshalID("RegistryExtension", map[string]interface{}{"r": map[string]string{"r": remote.URL, "u": uuid}})
+	pin := func(releaseID int64) error {
+		args := &graphqlbackend.ExtensionRegistryPinExtensionReleaseArgs{Extension: extension}
+		if releaseID != 0 {
+			id := marshalRegistryExtensionReleaseID(releaseID)
+			args.Release = &id
+		}
+		_, err := extensionRegistryPinExtensionRelease(ctx, args)
+		return err
+	}
+
+	if err := pin(1); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"a/b": 1}; !reflect.DeepEqual(pins, want) {
+		t.Errorf("got pins %v, want %v", pins, want)
+	}
+	if err := pin(2); err == nil {
+		t.Error("pinning to a release that doesn't exist on the remote registry: got nil error, want non-nil")
+	}
+	if err := pin(0); err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 0 {
+		t.Errorf("got pins %v, want none", pins)
+	}
+
+	if len(auditLog) != 2 {
+		t.Fatalf("got %d audit log entries, want 2", len(auditLog))
+	}
+	release1 := string(marshalRegistryExtensionReleaseID(1))
+	if e := auditLog[0]; e.Before != nil || e.After == nil || *e.After != release1 {
+		t.Errorf("got pinning audit log entry %+v, want before nil and after %q", e, release1)
+	}
+	if e := auditLog[1]; e.Before == nil || *e.Before != release1 || e.After != nil {
+		t.Errorf("got unpinning audit log entry %+v, want before %q and after nil", e, release1)
+	}
+}
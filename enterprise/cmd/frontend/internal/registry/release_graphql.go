@@ -0,0 +1,113 @@
+package registry
+
+import (
+	"context"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	frontendregistry "github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func marshalRegistryExtensionReleaseID(id int64) graphql.ID {
+	return relay.MarshalID("RegistryExtensionRelease", id)
+}
+
+func unmarshalRegistryExtensionReleaseID(id graphql.ID) (releaseID int64, err error) {
+	err = relay.UnmarshalSpec(id, &releaseID)
+	return
+}
+
+// extensionReleaseResolver implements the GraphQL type RegistryExtensionRelease.
+type extensionReleaseResolver struct {
+	extension *dbExtension
+	v         *dbRelease
+}
+
+func (r *extensionReleaseResolver) ID() graphql.ID {
+	return marshalRegistryExtensionReleaseID(r.v.ID)
+}
+
+func (r *extensionReleaseResolver) Version() *string { return r.v.ReleaseVersion }
+
+func (r *extensionReleaseResolver) Creator(ctx context.Context) (*graphqlbackend.UserResolver, error) {
+	user, err := graphqlbackend.UserByIDInt32(ctx, r.v.CreatorUserID)
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *extensionReleaseResolver) CreatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.v.CreatedAt}
+}
+
+func (r *extensionReleaseResolver) BundleURL() (*string, error) {
+	release := *r.v
+	if err := prepReleaseManifest(r.extension.NonCanonicalExtensionID, &release); err != nil {
+		return nil, err
+	}
+	return frontendregistry.NewExtensionManifest(&release.Manifest).BundleURL()
+}
+
+func (r *extensionReleaseResolver) Signed() bool { return r.v.BundleSignature != nil }
+
+func (r *extensionReleaseResolver) Pinned() bool {
+	return r.extension.PinnedReleaseID != nil && *r.extension.PinnedReleaseID == r.v.ID
+}
+
+// extensionReleaseConnectionResolver resolves a list of an extension's releases.
+type extensionReleaseConnectionResolver struct {
+	extension   *dbExtension
+	limitOffset *db.LimitOffset
+
+	// cache results because they are used by multiple fields
+	once     sync.Once
+	releases []*dbRelease
+	err      error
+}
+
+func (r *extensionReleaseConnectionResolver) compute(ctx context.Context) ([]*dbRelease, error) {
+	r.once.Do(func() {
+		limitOffset := r.limitOffset
+		if limitOffset != nil {
+			tmp := *limitOffset
+			limitOffset = &tmp
+			limitOffset.Limit++ // so we can detect if there is a next page
+		}
+		r.releases, r.err = dbReleases{}.List(ctx, r.extension.ID, limitOffset)
+	})
+	return r.releases, r.err
+}
+
+func (r *extensionReleaseConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.RegistryExtensionRelease, error) {
+	releases, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.limitOffset != nil && len(releases) > r.limitOffset.Limit {
+		releases = releases[:r.limitOffset.Limit]
+	}
+	l := make([]graphqlbackend.RegistryExtensionRelease, len(releases))
+	for i, release := range releases {
+		l[i] = &extensionReleaseResolver{extension: r.extension, v: release}
+	}
+	return l, nil
+}
+
+func (r *extensionReleaseConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := dbReleases{}.Count(ctx, r.extension.ID)
+	return int32(count), err
+}
+
+func (r *extensionReleaseConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	releases, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.limitOffset != nil && len(releases) > r.limitOffset.Limit), nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/keegancsmith/sqlf"
 	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 )
 
@@ -22,9 +23,18 @@ type dbRelease struct {
 	Manifest            string
 	Bundle              *string
 	SourceMap           *string
+	BundleSignature     *string
 	CreatedAt           time.Time
+
+	// SigningPublicKey is the signing public key of the release's extension that BundleSignature
+	// was verified with when the release was published, or nil if the release is unsigned. It is
+	// stored with the release, so that rotating the extension's key doesn't change which key the
+	// release's bundle is verified with.
+	SigningPublicKey *string
 }
 
+// dbReleases queries the registry_extension_releases table. Its methods use the transaction
+// carried by the context, if any (see dbconn.Transaction).
 type dbReleases struct{}
 
 // releaseNotFoundError occurs when an extension release is not found in the
@@ -49,13 +59,13 @@ func (dbReleases) Create(ctx context.Context, release *dbRelease) (id int64, err
 		return mocks.releases.Create(release)
 	}
 
-	if err := dbconn.Global.QueryRowContext(ctx,
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx,
 		`
-INSERT INTO registry_extension_releases(registry_extension_id, creator_user_id, release_version, release_tag, manifest, bundle, source_map)
-VALUES($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO registry_extension_releases(registry_extension_id, creator_user_id, release_version, release_tag, manifest, bundle, source_map, bundle_signature, signing_public_key)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING id
 `,
-		release.RegistryExtensionID, release.CreatorUserID, release.ReleaseVersion, release.ReleaseTag, release.Manifest, release.Bundle, release.SourceMap,
+		release.RegistryExtensionID, release.CreatorUserID, release.ReleaseVersion, release.ReleaseTag, release.Manifest, release.Bundle, release.SourceMap, release.BundleSignature, release.SigningPublicKey,
 	).Scan(&id); err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			if pqErr.Message == "invalid input syntax for type json" {
@@ -67,21 +77,26 @@ RETURNING id
 	return id, nil
 }
 
+// releasePinnedCond is the SQL condition that restricts releases (aliased "rer") to the pinned
+// release of their extension (aliased "x"), if it is pinned to a release.
+const releasePinnedCond = "(x.pinned_release_id IS NULL OR rer.id=x.pinned_release_id)"
+
 // GetLatest gets the latest release for the extension with the given release tag (e.g., "release").
-// If includeArtifacts is true, it populates the (*dbRelease).{Bundle,SourceMap} fields, which may be large.
+// If the extension is pinned to a release, that release is returned instead. If includeArtifacts is true, it populates the (*dbRelease).{Bundle,SourceMap} fields, which may be large.
 func (dbReleases) GetLatest(ctx context.Context, registryExtensionID int32, releaseTag string, includeArtifacts bool) (*dbRelease, error) {
 	if mocks.releases.GetLatest != nil {
 		return mocks.releases.GetLatest(registryExtensionID, releaseTag, includeArtifacts)
 	}
 
 	q := sqlf.Sprintf(`
-SELECT id, registry_extension_id, creator_user_id, release_version, release_tag, manifest, CASE WHEN %v::boolean THEN bundle ELSE null END AS bundle, CASE WHEN %v::boolean THEN source_map ELSE null END AS source_map, created_at
-FROM registry_extension_releases
-WHERE registry_extension_id=%d AND release_tag=%s AND deleted_at IS NULL
-ORDER BY created_at DESC
+SELECT rer.id, rer.registry_extension_id, rer.creator_user_id, rer.release_version, rer.release_tag, rer.manifest, CASE WHEN %v::boolean THEN rer.bundle ELSE null END AS bundle, CASE WHEN %v::boolean THEN rer.source_map ELSE null END AS source_map, rer.bundle_signature, rer.created_at, rer.signing_public_key
+FROM registry_extension_releases rer
+JOIN registry_extensions x ON x.id=rer.registry_extension_id
+WHERE rer.registry_extension_id=%d AND rer.release_tag=%s AND rer.deleted_at IS NULL AND `+releasePinnedCond+`
+ORDER BY rer.created_at DESC
 LIMIT 1`, includeArtifacts, includeArtifacts, registryExtensionID, releaseTag)
 	var r dbRelease
-	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&r.ID, &r.RegistryExtensionID, &r.CreatorUserID, &r.ReleaseVersion, &r.ReleaseTag, &r.Manifest, &r.Bundle, &r.SourceMap, &r.CreatedAt)
+	err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&r.ID, &r.RegistryExtensionID, &r.CreatorUserID, &r.ReleaseVersion, &r.ReleaseTag, &r.Manifest, &r.Bundle, &r.SourceMap, &r.BundleSignature, &r.CreatedAt, &r.SigningPublicKey)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, releaseNotFoundError{[]interface{}{fmt.Sprintf("latest for registry extension ID %d tag %q", registryExtensionID, releaseTag)}}
@@ -92,7 +107,8 @@ LIMIT 1`, includeArtifacts, includeArtifacts, registryExtensionID, releaseTag)
 }
 
 // GetLatestBatch gets the latest releases for the extensions with the given release tag
-// (e.g., "release"). If includeArtifacts is true, it populates the (*dbRelease).{Bundle,SourceMap}
+// (e.g., "release"), or the pinned release for extensions that are pinned to a release. If
+// includeArtifacts is true, it populates the (*dbRelease).{Bundle,SourceMap}
 // fields, which may be large.
 func (dbReleases) GetLatestBatch(ctx context.Context, registryExtensionIDs []int32, releaseTag string, includeArtifacts bool) ([]*dbRelease, error) {
 	if mocks.releases.GetLatestBatch != nil {
@@ -110,13 +126,14 @@ func (dbReleases) GetLatestBatch(ctx context.Context, registryExtensionIDs []int
 
 	q := sqlf.Sprintf(`
 SELECT DISTINCT ON (rer.registry_extension_id)
-	rer.id, rer.registry_extension_id, rer.creator_user_id, rer.release_version, rer.release_tag, rer.manifest, CASE WHEN %v::boolean THEN rer.bundle ELSE null END AS bundle, CASE WHEN %v::boolean THEN rer.source_map ELSE null END AS source_map, rer.created_at
+	rer.id, rer.registry_extension_id, rer.creator_user_id, rer.release_version, rer.release_tag, rer.manifest, CASE WHEN %v::boolean THEN rer.bundle ELSE null END AS bundle, CASE WHEN %v::boolean THEN rer.source_map ELSE null END AS source_map, rer.bundle_signature, rer.created_at, rer.signing_public_key
 FROM registry_extension_releases rer
-WHERE rer.registry_extension_id IN (%s) AND rer.release_tag=%s AND rer.deleted_at IS NULL
+JOIN registry_extensions x ON x.id=rer.registry_extension_id
+WHERE rer.registry_extension_id IN (%s) AND rer.release_tag=%s AND rer.deleted_at IS NULL AND `+releasePinnedCond+`
 ORDER BY rer.registry_extension_id, rer.created_at DESC
 `, includeArtifacts, includeArtifacts, sqlf.Join(ids, ","), releaseTag)
 
-	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return nil, err
 	}
@@ -125,7 +142,7 @@ ORDER BY rer.registry_extension_id, rer.created_at DESC
 	var releases []*dbRelease
 	for rows.Next() {
 		var r dbRelease
-		err := rows.Scan(&r.ID, &r.RegistryExtensionID, &r.CreatorUserID, &r.ReleaseVersion, &r.ReleaseTag, &r.Manifest, &r.Bundle, &r.SourceMap, &r.CreatedAt)
+		err := rows.Scan(&r.ID, &r.RegistryExtensionID, &r.CreatorUserID, &r.ReleaseVersion, &r.ReleaseTag, &r.Manifest, &r.Bundle, &r.SourceMap, &r.BundleSignature, &r.CreatedAt, &r.SigningPublicKey)
 		if err != nil {
 			return nil, err
 		}
@@ -142,11 +159,15 @@ ORDER BY rer.registry_extension_id, rer.created_at DESC
 // GetArtifacts gets the bundled JavaScript source file contents and the source map for a release
 // (by ID).
 func (dbReleases) GetArtifacts(ctx context.Context, id int64) (bundle, sourcemap []byte, err error) {
+	if mocks.releases.GetArtifacts != nil {
+		return mocks.releases.GetArtifacts(id)
+	}
+
 	q := sqlf.Sprintf(`
 SELECT bundle, source_map
 FROM registry_extension_releases
 WHERE id=%d AND deleted_at IS NULL`, id)
-	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&bundle, &sourcemap); err != nil {
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&bundle, &sourcemap); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil, releaseNotFoundError{[]interface{}{fmt.Sprintf("registry extension release %d", id)}}
 		}
@@ -158,9 +179,110 @@ WHERE id=%d AND deleted_at IS NULL`, id)
 	return bundle, sourcemap, nil
 }
 
+// releaseBundleSignature describes the signature of a release's bundle.
+type releaseBundleSignature struct {
+	// ExtensionSigningPublicKey is the current signing public key of the release's extension. If
+	// it is set, the release's bundle must be signed.
+	ExtensionSigningPublicKey *string
+
+	// SigningPublicKey and BundleSignature are the key that the release's bundle was signed with
+	// (stored with the release) and the signature, or nil if the release is unsigned.
+	SigningPublicKey *string
+	BundleSignature  *string
+}
+
+// GetBundleSignature gets the bundle signature of a release (by ID).
+func (dbReleases) GetBundleSignature(ctx context.Context, id int64) (*releaseBundleSignature, error) {
+	if mocks.releases.GetBundleSignature != nil {
+		return mocks.releases.GetBundleSignature(id)
+	}
+
+	q := sqlf.Sprintf(`
+SELECT x.signing_public_key, rer.signing_public_key, rer.bundle_signature
+FROM registry_extension_releases rer
+JOIN registry_extensions x ON x.id=rer.registry_extension_id
+WHERE rer.id=%d AND rer.deleted_at IS NULL`, id)
+	var s releaseBundleSignature
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&s.ExtensionSigningPublicKey, &s.SigningPublicKey, &s.BundleSignature); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, releaseNotFoundError{[]interface{}{fmt.Sprintf("registry extension release %d", id)}}
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetByID gets a release (by ID) of the given extension, without its artifacts.
+func (dbReleases) GetByID(ctx context.Context, registryExtensionID int32, id int64) (*dbRelease, error) {
+	if mocks.releases.GetByID != nil {
+		return mocks.releases.GetByID(registryExtensionID, id)
+	}
+
+	releases, err := dbReleases{}.list(ctx, sqlf.Sprintf("registry_extension_id=%d AND id=%d", registryExtensionID, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, releaseNotFoundError{[]interface{}{fmt.Sprintf("registry extension release %d of registry extension ID %d", id, registryExtensionID)}}
+	}
+	return releases[0], nil
+}
+
+// List lists the releases of the extension, newest first, without their artifacts.
+func (dbReleases) List(ctx context.Context, registryExtensionID int32, limitOffset *db.LimitOffset) ([]*dbRelease, error) {
+	if mocks.releases.List != nil {
+		return mocks.releases.List(registryExtensionID, limitOffset)
+	}
+	return dbReleases{}.list(ctx, sqlf.Sprintf("registry_extension_id=%d", registryExtensionID), limitOffset)
+}
+
+func (dbReleases) list(ctx context.Context, cond *sqlf.Query, limitOffset *db.LimitOffset) ([]*dbRelease, error) {
+	q := sqlf.Sprintf(`
+SELECT id, registry_extension_id, creator_user_id, release_version, release_tag, manifest, bundle_signature, created_at, signing_public_key
+FROM registry_extension_releases
+WHERE (%s) AND deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+%s`, cond, limitOffset.SQL())
+
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []*dbRelease
+	for rows.Next() {
+		var r dbRelease
+		if err := rows.Scan(&r.ID, &r.RegistryExtensionID, &r.CreatorUserID, &r.ReleaseVersion, &r.ReleaseTag, &r.Manifest, &r.BundleSignature, &r.CreatedAt, &r.SigningPublicKey); err != nil {
+			return nil, err
+		}
+		releases = append(releases, &r)
+	}
+	return releases, rows.Err()
+}
+
+// Count counts the releases of the extension.
+func (dbReleases) Count(ctx context.Context, registryExtensionID int32) (int, error) {
+	if mocks.releases.Count != nil {
+		return mocks.releases.Count(registryExtensionID)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM registry_extension_releases WHERE registry_extension_id=%d AND deleted_at IS NULL", registryExtensionID)
+	var count int
+	if err := dbconn.FromContext(ctx).QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 // mockReleases mocks the registry extension releases store.
 type mockReleases struct {
-	Create         func(release *dbRelease) (int64, error)
-	GetLatest      func(registryExtensionID int32, releaseTag string, includeArtifacts bool) (*dbRelease, error)
-	GetLatestBatch func(registryExtensionIDs []int32, releaseTag string, includeArtifacts bool) ([]*dbRelease, error)
+	Create             func(release *dbRelease) (int64, error)
+	GetLatest          func(registryExtensionID int32, releaseTag string, includeArtifacts bool) (*dbRelease, error)
+	GetLatestBatch     func(registryExtensionIDs []int32, releaseTag string, includeArtifacts bool) ([]*dbRelease, error)
+	GetArtifacts       func(id int64) (bundle, sourcemap []byte, err error)
+	GetBundleSignature func(id int64) (*releaseBundleSignature, error)
+	GetByID            func(registryExtensionID int32, id int64) (*dbRelease, error)
+	List               func(registryExtensionID int32, limitOffset *db.LimitOffset) ([]*dbRelease, error)
+	Count              func(registryExtensionID int32) (int, error)
 }
@@ -156,6 +156,49 @@ func TestRegistryExtensionReleases(t *testing.T) {
 		}
 	})
 
+	t.Run("List, pinning and signatures", func(t *testing.T) {
+		releases, err := dbReleases{}.List(ctx, xExtensionID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(releases) != 2 || releases[0].ID != input2.ID {
+			t.Fatalf("got %+v, want releases %d and 1 (newest first)", releases, input2.ID)
+		}
+		if count, err := (dbReleases{}).Count(ctx, xExtensionID); err != nil || count != 2 {
+			t.Errorf("got count %d, %v, want 2", count, err)
+		}
+		first := releases[1].ID
+
+		if err := (dbExtensions{}).UpdatePinnedRelease(ctx, xExtensionID, &first); err != nil {
+			t.Fatal(err)
+		}
+		if r, err := (dbReleases{}).GetLatest(ctx, xExtensionID, "release", false); err != nil || r.ID != first {
+			t.Errorf("pinned: got release %+v, %v, want ID %d", r, err, first)
+		}
+		if err := (dbExtensions{}).UpdatePinnedRelease(ctx, xExtensionID, nil); err != nil {
+			t.Fatal(err)
+		}
+		if r, err := (dbReleases{}).GetLatest(ctx, xExtensionID, "release", false); err != nil || r.ID != input2.ID {
+			t.Errorf("unpinned: got release %+v, %v, want ID %d", r, err, input2.ID)
+		}
+
+		if err := (dbExtensions{}).UpdateSigningPublicKey(ctx, xExtensionID, strptr("k")); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := (dbExtensions{}).UpdateSigningPublicKey(ctx, xExtensionID, nil); err != nil {
+				t.Fatal(err)
+			}
+		}()
+		signature, err := dbReleases{}.GetBundleSignature(ctx, first)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k := signature.ExtensionSigningPublicKey; k == nil || *k != "k" || signature.SigningPublicKey != nil || signature.BundleSignature != nil {
+			t.Errorf("got %+v, want extension signing public key %q and no release signature", signature, "k")
+		}
+	})
+
 	t.Run("Create fails on invalid JSON", func(t *testing.T) {
 		_, err := dbReleases{}.Create(ctx, &dbRelease{
 			RegistryExtensionID: xExtensionID,
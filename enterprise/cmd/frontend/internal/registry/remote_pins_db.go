@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// dbRemotePins queries the registry_extension_remote_pins table, which holds the releases that
+// this site pins extensions from the remote registry to. Its methods use the transaction carried by
+// the context, if any (see dbconn.Transaction).
+type dbRemotePins struct{}
+
+// List returns the IDs of the releases (on the remote registry) that remote extensions are pinned
+// to, by extension ID.
+func (dbRemotePins) List(ctx context.Context) (map[string]int64, error) {
+	if mocks.remotePins.List != nil {
+		return mocks.remotePins.List()
+	}
+
+	q := sqlf.Sprintf("SELECT extension_id, release_id FROM registry_extension_remote_pins")
+	rows, err := dbconn.FromContext(ctx).QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pins := map[string]int64{}
+	for rows.Next() {
+		var (
+			extensionID string
+			releaseID   int64
+		)
+		if err := rows.Scan(&extensionID, &releaseID); err != nil {
+			return nil, err
+		}
+		pins[extensionID] = releaseID
+	}
+	return pins, rows.Err()
+}
+
+// Set pins the remote extension to the release (on the remote registry) with the given ID or, if
+// releaseID is nil, unpins it (so that its latest release is used).
+func (dbRemotePins) Set(ctx context.Context, extensionID string, releaseID *int64) error {
+	if mocks.remotePins.Set != nil {
+		return mocks.remotePins.Set(extensionID, releaseID)
+	}
+
+	var q *sqlf.Query
+	if releaseID == nil {
+		q = sqlf.Sprintf("DELETE FROM registry_extension_remote_pins WHERE extension_id=%s", extensionID)
+	} else {
+		q = sqlf.Sprintf(`
+INSERT INTO registry_extension_remote_pins(extension_id, release_id) VALUES(%s, %d)
+ON CONFLICT (extension_id) DO UPDATE SET release_id=excluded.release_id, created_at=now()`,
+			extensionID, *releaseID)
+	}
+	_, err := dbconn.FromContext(ctx).ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// mockRemotePins mocks the remote extension pins store.
+type mockRemotePins struct {
+	List func() (map[string]int64, error)
+	Set  func(extensionID string, releaseID *int64) error
+}
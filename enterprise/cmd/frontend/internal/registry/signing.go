@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Extension bundles are signed with ECDSA using the P-256 curve and SHA-256, which is the
+// algorithm that browsers' WebCrypto API supports for verifying signatures (so that clients can
+// verify a bundle before activating it, without trusting the server that served it).
+//
+// A publisher's signing key is stored on the extension as a base64-encoded DER (PKIX) public key,
+// and the signature of each release's bundle is stored as a base64-encoded IEEE P1363 signature
+// (the 32-byte r and s values concatenated). Signatures may be submitted in either that form or
+// the ASN.1 DER form produced by tools such as `openssl dgst -sign`.
+
+const p256SignatureSize = 64
+
+var errInvalidBundleSignature = errors.New("extension bundle signature is invalid")
+
+// parseSigningPublicKey parses a base64-encoded DER (PKIX) ECDSA P-256 public key.
+func parseSigningPublicKey(s string) (*ecdsa.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("signing public key is not valid base64: %s", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing public key: %s", err)
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || ecKey.Curve != elliptic.P256() {
+		return nil, errors.New("signing public key must be an ECDSA P-256 public key")
+	}
+	return ecKey, nil
+}
+
+// normalizeBundleSignature decodes a base64-encoded signature in either the IEEE P1363 or the
+// ASN.1 DER form and returns it base64-encoded in the IEEE P1363 form.
+func normalizeBundleSignature(s string) (string, error) {
+	sig, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("bundle signature is not valid base64: %s", err)
+	}
+	if len(sig) == p256SignatureSize {
+		return s, nil
+	}
+	var der struct{ R, S *big.Int }
+	if rest, err := asn1.Unmarshal(sig, &der); err != nil || len(rest) != 0 || der.R == nil || der.S == nil {
+		return "", errors.New("bundle signature must be an ECDSA P-256 signature (in IEEE P1363 or ASN.1 DER form)")
+	}
+	rb, sb := der.R.Bytes(), der.S.Bytes()
+	if len(rb) > p256SignatureSize/2 || len(sb) > p256SignatureSize/2 {
+		return "", errors.New("bundle signature is not an ECDSA P-256 signature")
+	}
+	raw := make([]byte, p256SignatureSize)
+	copy(raw[p256SignatureSize/2-len(rb):], rb)
+	copy(raw[p256SignatureSize-len(sb):], sb)
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// verifyBundleSignature verifies that signature (in the form returned by normalizeBundleSignature)
+// is a valid signature of bundle by the base64-encoded publicKey.
+func verifyBundleSignature(publicKey, signature string, bundle []byte) error {
+	key, err := parseSigningPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != p256SignatureSize {
+		return errInvalidBundleSignature
+	}
+	r := new(big.Int).SetBytes(sig[:p256SignatureSize/2])
+	s := new(big.Int).SetBytes(sig[p256SignatureSize/2:])
+	digest := sha256.Sum256(bundle)
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return errInvalidBundleSignature
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	frontendregistry "github.com/sourcegraph/sourcegraph/cmd/frontend/registry"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// testSigningKey generates a signing key and returns it and its base64-encoded public key.
+func testSigningKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, base64.StdEncoding.EncodeToString(der)
+}
+
+// testSignDER signs data and returns the base64-encoded ASN.1 DER signature.
+func testSignDER(t *testing.T, key *ecdsa.PrivateKey, data []byte) string {
+	t.Helper()
+	digest := sha256.Sum256(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func TestBundleSignature(t *testing.T) {
+	key, publicKey := testSigningKey(t)
+	bundle := []byte("exports.activate = () => {}")
+
+	signature, err := normalizeBundleSignature(testSignDER(t, key, bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := base64.StdEncoding.DecodeString(signature); len(raw) != p256SignatureSize {
+		t.Fatalf("got normalized signature of %d bytes, want %d", len(raw), p256SignatureSize)
+	}
+	if again, err := normalizeBundleSignature(signature); err != nil || again != signature {
+		t.Errorf("normalizing a normalized signature: got %q, %v, want %q", again, err, signature)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		if err := verifyBundleSignature(publicKey, signature, bundle); err != nil {
+			t.Errorf("got error %v, want nil", err)
+		}
+	})
+
+	t.Run("modified bundle", func(t *testing.T) {
+		if err := verifyBundleSignature(publicKey, signature, append(bundle, ';')); err != errInvalidBundleSignature {
+			t.Errorf("got error %v, want %v", err, errInvalidBundleSignature)
+		}
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		_, otherPublicKey := testSigningKey(t)
+		if err := verifyBundleSignature(otherPublicKey, signature, bundle); err != errInvalidBundleSignature {
+			t.Errorf("got error %v, want %v", err, errInvalidBundleSignature)
+		}
+	})
+
+	t.Run("invalid signatures", func(t *testing.T) {
+		for _, s := range []string{"", "!", base64.StdEncoding.EncodeToString([]byte("x"))} {
+			if _, err := normalizeBundleSignature(s); err == nil {
+				t.Errorf("%q: got nil error, want non-nil", s)
+			}
+		}
+	})
+}
+
+func TestParseSigningPublicKey(t *testing.T) {
+	_, publicKey := testSigningKey(t)
+	if _, err := parseSigningPublicKey(publicKey); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&p384.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"", "!", base64.StdEncoding.EncodeToString([]byte("x")), base64.StdEncoding.EncodeToString(der)} {
+		if _, err := parseSigningPublicKey(s); err == nil {
+			t.Errorf("%q: got nil error, want non-nil", s)
+		}
+	}
+}
+
+func TestCheckBundleSignature(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	ctx := context.Background()
+
+	key, publicKey := testSigningKey(t)
+	bundle := "exports.activate = () => {}"
+	signature := testSignDER(t, key, []byte(bundle))
+
+	var signingPublicKey *string
+	mocks.extensions.GetByID = func(id int32) (*dbExtension, error) {
+		return &dbExtension{ID: id, SigningPublicKey: signingPublicKey, NonCanonicalExtensionID: "a/b"}, nil
+	}
+
+	t.Run("no signing public key", func(t *testing.T) {
+		signingPublicKey = nil
+		if got, gotKey, err := checkBundleSignature(ctx, 1, &bundle, nil); err != nil || got != nil || gotKey != nil {
+			t.Errorf("unsigned bundle: got %v, %v, %v, want nil, nil, nil", got, gotKey, err)
+		}
+		if _, _, err := checkBundleSignature(ctx, 1, &bundle, &signature); err == nil {
+			t.Error("signed bundle: got nil error, want non-nil")
+		}
+	})
+
+	t.Run("signing public key", func(t *testing.T) {
+		signingPublicKey = &publicKey
+		got, gotKey, err := checkBundleSignature(ctx, 1, &bundle, &signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := verifyBundleSignature(publicKey, *got, []byte(bundle)); err != nil {
+			t.Errorf("stored signature does not verify: %v", err)
+		}
+		if gotKey == nil || *gotKey != publicKey {
+			t.Errorf("got signing public key %v, want %q", gotKey, publicKey)
+		}
+		if _, _, err := checkBundleSignature(ctx, 1, &bundle, nil); err == nil {
+			t.Error("unsigned bundle: got nil error, want non-nil")
+		}
+		if _, _, err := checkBundleSignature(ctx, 1, nil, &signature); err == nil {
+			t.Error("no bundle: got nil error, want non-nil")
+		}
+		modified := bundle + ";"
+		if _, _, err := checkBundleSignature(ctx, 1, &modified, &signature); err != errInvalidBundleSignature {
+			t.Errorf("modified bundle: got error %v, want %v", err, errInvalidBundleSignature)
+		}
+	})
+}
+
+func TestUpdateExtensionSigningPublicKey(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+	defer licensing.TestingSkipFeatureChecks()()
+	defer func() { db.Mocks = db.MockStores{} }()
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+
+	_, publicKey := testSigningKey(t)
+	_, otherPublicKey := testSigningKey(t)
+
+	// The current user (1) publishes the extension, but is not a site admin.
+	var siteAdmin bool
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: siteAdmin}, nil
+	}
+	db.Mocks.AuditLog.Insert = func(*db.AuditLogEntry) error { return nil }
+	extension := &dbExtension{ID: 1, Publisher: dbPublisher{UserID: 1}}
+	mocks.extensions.GetByID = func(id int32) (*dbExtension, error) { return extension, nil }
+	mocks.extensions.UpdateSigningPublicKey = func(id int32, signingPublicKey *string) error {
+		extension.SigningPublicKey = signingPublicKey
+		return nil
+	}
+
+	update := func(signingPublicKey *string) error {
+		_, err := extensionRegistryUpdateExtensionSigningPublicKey(ctx, &graphqlbackend.ExtensionRegistryUpdateExtensionSigningPublicKeyArgs{
+			Extension:        frontendregistry.MarshalRegistryExtensionID(frontendregistry.RegistryExtensionID{LocalID: 1}),
+			SigningPublicKey: signingPublicKey,
+		})
+		return err
+	}
+
+	if err := update(&publicKey); err != nil {
+		t.Fatalf("publisher setting the first key: %v", err)
+	}
+	if err := update(&publicKey); err != nil {
+		t.Errorf("publisher setting the same key: %v", err)
+	}
+
+	// 🚨 SECURITY: Only site admins may clear or change an existing key.
+	if err := update(&otherPublicKey); err == nil {
+		t.Error("publisher changing the key: got nil error, want non-nil")
+	}
+	if err := update(nil); err == nil {
+		t.Error("publisher clearing the key: got nil error, want non-nil")
+	}
+	if *extension.SigningPublicKey != publicKey {
+		t.Errorf("got signing public key %q, want it unchanged", *extension.SigningPublicKey)
+	}
+
+	siteAdmin = true
+	if err := update(&otherPublicKey); err != nil {
+		t.Errorf("site admin changing the key: %v", err)
+	}
+	if err := update(nil); err != nil {
+		t.Errorf("site admin clearing the key: %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
@@ -122,6 +123,54 @@ func initLicensing() {
 			ExpiresAtValue: info.ExpiresAt,
 		}, nil
 	}
+
+	// Make the Site.productSubscription.seatUsage GraphQL field return the tracked seat usage.
+	graphqlbackend.GetSeatUsageReport = func(ctx context.Context, days int) (*graphqlbackend.SeatUsageReport, error) {
+		usage, err := licensing.GetSeatUsage(ctx, &usersStore{}, days)
+		if err != nil {
+			return nil, err
+		}
+		snapshots := make([]*graphqlbackend.SeatUsageSnapshot, len(usage.Snapshots))
+		for i, s := range usage.Snapshots {
+			snapshots[i] = &graphqlbackend.SeatUsageSnapshot{
+				DateValue:          s.Date,
+				UserCountValue:     s.UserCount,
+				LicensedSeatsValue: s.LicensedSeats,
+			}
+		}
+		return &graphqlbackend.SeatUsageReport{
+			UserCountValue:         usage.UserCount,
+			LicensedSeatsValue:     usage.LicensedSeats,
+			OverageSinceValue:      usage.OverageSince,
+			GracePeriodEndsAtValue: usage.GracePeriodEndsAt,
+			SnapshotsValue:         snapshots,
+		}, nil
+	}
+
+	// Warn site admins when the site approaches or exceeds its licensed seats.
+	graphqlbackend.AlertFuncs = append(graphqlbackend.AlertFuncs, func(args graphqlbackend.AlertFuncArgs) []*graphqlbackend.Alert {
+		// Only site admins can act on this alert, so only show it to site admins.
+		if !args.IsSiteAdmin {
+			return nil
+		}
+
+		message, isError, err := licensing.SeatUsageAlert(context.Background(), &usersStore{})
+		if err != nil {
+			log15.Error("Error checking licensed seat usage.", "err", err)
+			return nil
+		}
+		if message == "" {
+			return nil
+		}
+		alert := &graphqlbackend.Alert{
+			TypeValue:    graphqlbackend.AlertTypeWarning,
+			MessageValue: message + " [**View license details**](/site-admin/license).",
+		}
+		if isError {
+			alert.TypeValue = graphqlbackend.AlertTypeError
+		}
+		return []*graphqlbackend.Alert{alert}
+	})
 }
 
 func initResolvers() {
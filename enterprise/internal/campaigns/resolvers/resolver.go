@@ -12,6 +12,7 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
@@ -38,11 +39,15 @@ func NewResolver(db *sql.DB) graphqlbackend.CampaignsResolver {
 }
 
 func allowReadAccess(ctx context.Context) error {
+	if err := authz.CheckScope(ctx, authz.ScopeCampaignsWrite); err != nil {
+		return err
+	}
+
 	if readAccess := conf.CampaignsReadAccessEnabled(); readAccess {
 		return nil
 	}
 
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return err
 	}
 
@@ -139,7 +144,7 @@ func (r *Resolver) PatchSetByID(ctx context.Context, id graphql.ID) (graphqlback
 
 func (r *Resolver) AddChangesetsToCampaign(ctx context.Context, args *graphqlbackend.AddChangesetsToCampaignArgs) (_ graphqlbackend.CampaignResolver, err error) {
 	// 🚨 SECURITY: Only site admins may modify changesets and campaigns for now.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, err
 	}
 
@@ -275,7 +280,7 @@ func (r *Resolver) UpdateCampaign(ctx context.Context, args *graphqlbackend.Upda
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, err
 	}
 
@@ -324,7 +329,7 @@ func (r *Resolver) DeleteCampaign(ctx context.Context, args *graphqlbackend.Dele
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, err
 	}
 
@@ -347,7 +352,7 @@ func (r *Resolver) RetryCampaign(ctx context.Context, args *graphqlbackend.Retry
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -393,7 +398,7 @@ func (r *Resolver) Campaigns(ctx context.Context, args *graphqlbackend.ListCampa
 
 func (r *Resolver) CreateChangesets(ctx context.Context, args *graphqlbackend.CreateChangesetsArgs) (_ []graphqlbackend.ExternalChangesetResolver, err error) {
 	// 🚨 SECURITY: Only site admins may create changesets for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, err
 	}
 
@@ -538,7 +543,7 @@ func (r *Resolver) CreatePatchSetFromPatches(ctx context.Context, args graphqlba
 	}()
 
 	// 🚨 SECURITY: Only site admins may create patch sets for now.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, err
 	}
 
@@ -594,7 +599,7 @@ func (r *Resolver) CloseCampaign(ctx context.Context, args *graphqlbackend.Close
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -621,7 +626,7 @@ func (r *Resolver) PublishCampaign(ctx context.Context, args *graphqlbackend.Pub
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -647,7 +652,7 @@ func (r *Resolver) PublishChangeset(ctx context.Context, args *graphqlbackend.Pu
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -673,7 +678,7 @@ func (r *Resolver) SyncChangeset(ctx context.Context, args *graphqlbackend.SyncC
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := backend.CheckCurrentUserIsSiteAdminWithScope(ctx, authz.ScopeCampaignsWrite); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
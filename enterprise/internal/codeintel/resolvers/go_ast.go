@@ -0,0 +1,221 @@
+package resolvers
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// goTypeRef is a reference to a named Go type, such as "Reader" or
+// "io.Reader".
+type goTypeRef struct {
+	qualifier string // the imported package name, or "" for the current package
+	name      string
+}
+
+// goIdentAt returns the identifier at the given zero-based line and
+// character (in UTF-16 code units) of the parsed Go file, and the node that
+// contains it. It returns nil if there is no identifier at the position.
+func goIdentAt(fset *token.FileSet, f *ast.File, data []byte, line, character int) (ident *ast.Ident, parent ast.Node) {
+	offset, ok := goOffset(data, line, character)
+	if !ok {
+		return nil, nil
+	}
+	pos := fset.File(f.Pos()).Pos(offset)
+
+	var stack []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if pos < n.Pos() || pos >= n.End() {
+			return false
+		}
+		if id, ok := n.(*ast.Ident); ok {
+			ident = id
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			return false
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return ident, parent
+}
+
+// goOffset converts a zero-based line and character (in UTF-16 code units)
+// to a byte offset in data.
+func goOffset(data []byte, line, character int) (int, bool) {
+	offset := 0
+	for i := 0; i < line; i++ {
+		j := strings.IndexByte(string(data[offset:]), '\n')
+		if j < 0 {
+			return 0, false
+		}
+		offset += j + 1
+	}
+	for i, r := range string(data[offset:]) {
+		if character <= 0 || r == '\n' {
+			return offset + i, true
+		}
+		if r > 0xFFFF {
+			character -= 2
+		} else {
+			character--
+		}
+	}
+	return 0, false
+}
+
+// goTypeRefAt returns the reference to the named type denoted by ident. It
+// returns false if ident does not denote a type (or possibly a type declared
+// in another file of the package).
+func goTypeRefAt(ident *ast.Ident, parent ast.Node, imports map[string]string) (goTypeRef, bool) {
+	switch parent := parent.(type) {
+	case *ast.SelectorExpr:
+		if parent.Sel != ident {
+			// The package name of a qualified identifier.
+			return goTypeRef{}, false
+		}
+		if x, ok := parent.X.(*ast.Ident); ok && x.Obj == nil && imports[x.Name] != "" {
+			return goTypeRef{qualifier: x.Name, name: ident.Name}, true
+		}
+		// A field or method.
+		return goTypeRef{}, false
+	case *ast.FuncDecl, *ast.KeyValueExpr, *ast.LabeledStmt, *ast.BranchStmt:
+		if ident.Obj == nil {
+			return goTypeRef{}, false
+		}
+	case *ast.Field:
+		for _, name := range parent.Names {
+			if name == ident {
+				// A field, method or parameter name.
+				return goTypeRef{}, false
+			}
+		}
+	}
+
+	if ident.Obj == nil || ident.Obj.Kind == ast.Typ {
+		// Identifiers which aren't declared in the file may be types
+		// declared in other files of the package.
+		return goTypeRef{name: ident.Name}, true
+	}
+	return goTypeRef{}, false
+}
+
+// goDeclaredType returns the type expression of the variable denoted by
+// ident, if it can be determined without type checking.
+func goDeclaredType(ident *ast.Ident) ast.Expr {
+	if ident.Obj == nil || ident.Obj.Kind != ast.Var {
+		return nil
+	}
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.Field:
+		return decl.Type
+	case *ast.ValueSpec:
+		if decl.Type != nil {
+			return decl.Type
+		}
+		for i, name := range decl.Names {
+			if name.Name == ident.Name && len(decl.Values) == len(decl.Names) {
+				return goValueType(decl.Values[i])
+			}
+		}
+	case *ast.AssignStmt:
+		for i, lhs := range decl.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Name == ident.Name && len(decl.Rhs) == len(decl.Lhs) {
+				return goValueType(decl.Rhs[i])
+			}
+		}
+	}
+	return nil
+}
+
+// goValueType returns the type expression of the value of expr, if it is
+// explicit (as in composite literals).
+func goValueType(expr ast.Expr) ast.Expr {
+	switch expr := expr.(type) {
+	case *ast.CompositeLit:
+		return expr.Type
+	case *ast.UnaryExpr:
+		if expr.Op == token.AND {
+			return goValueType(expr.X)
+		}
+	case *ast.ParenExpr:
+		return goValueType(expr.X)
+	case *ast.CallExpr:
+		if fun, ok := expr.Fun.(*ast.Ident); ok && fun.Name == "new" && fun.Obj == nil && len(expr.Args) == 1 {
+			return expr.Args[0]
+		}
+	}
+	return nil
+}
+
+// goNamedType returns the named type that the type expression expr is
+// composed of, such as T for *T, []T, chan T or map[K]T.
+func goNamedType(expr ast.Expr) (goTypeRef, bool) {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ArrayType:
+			expr = e.Elt
+		case *ast.Ellipsis:
+			expr = e.Elt
+		case *ast.ChanType:
+			expr = e.Value
+		case *ast.MapType:
+			expr = e.Value
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return goTypeRef{name: e.Name}, true
+		case *ast.SelectorExpr:
+			if x, ok := e.X.(*ast.Ident); ok {
+				return goTypeRef{qualifier: x.Name, name: e.Sel.Name}, true
+			}
+			return goTypeRef{}, false
+		default:
+			return goTypeRef{}, false
+		}
+	}
+}
+
+var goMajorVersionRegexp = regexp.MustCompile(`^v[0-9]+$|\.v[0-9]+$`)
+
+// goImports returns the import paths of the file, by the name under which
+// they are imported. Package names are guessed from import paths, as the
+// imported packages aren't parsed.
+func goImports(f *ast.File) map[string]string {
+	imports := make(map[string]string, len(f.Imports))
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		var name string
+		if spec.Name != nil {
+			name = spec.Name.Name
+		} else {
+			name = path.Base(importPath)
+			if goMajorVersionRegexp.MatchString(name) {
+				// As in github.com/a/b/v2 or gopkg.in/yaml.v2.
+				if strings.HasPrefix(name, "v") {
+					name = path.Base(path.Dir(importPath))
+				} else {
+					name = goMajorVersionRegexp.ReplaceAllString(name, "")
+				}
+			}
+			name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+		}
+		if name != "_" && name != "." {
+			imports[name] = importPath
+		}
+	}
+	return imports
+}
@@ -0,0 +1,443 @@
+package resolvers
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gosrc"
+	"github.com/sourcegraph/sourcegraph/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/internal/symbols"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// listGoTypes lists the Go types declared in the repository at the given
+// commit. It is a variable so that tests can mock it.
+var listGoTypes = func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]protocol.GoType, error) {
+	result, err := symbols.DefaultClient.GoTypes(ctx, protocol.GoTypesArgs{Repo: repo, CommitID: commit})
+	if err != nil {
+		return nil, err
+	}
+	return result.Types, nil
+}
+
+const (
+	// maxGoFallbackFileSize is the maximum size of the Go file that is
+	// parsed to find the type at a position.
+	maxGoFallbackFileSize = 1 << 20
+
+	// maxGoFallbackRepositories is the maximum number of dependent or
+	// dependency repositories that are searched for implementations. The Go
+	// types of each one are listed from an archive of the repository, so it
+	// is kept small.
+	maxGoFallbackRepositories = 10
+)
+
+// goStdlibInterfaces are the method sets of common interfaces of the
+// standard library, which are embedded in other interfaces. The types of the
+// standard library aren't listed, as it isn't a repository.
+var goStdlibInterfaces = map[string][]string{
+	"error":              {"Error"},
+	"fmt.Stringer":       {"String"},
+	"http.Handler":       {"ServeHTTP"},
+	"io.Closer":          {"Close"},
+	"io.ReadCloser":      {"Close", "Read"},
+	"io.ReadWriteCloser": {"Close", "Read", "Write"},
+	"io.ReadWriter":      {"Read", "Write"},
+	"io.Reader":          {"Read"},
+	"io.Seeker":          {"Seek"},
+	"io.WriteCloser":     {"Close", "Write"},
+	"io.Writer":          {"Write"},
+	"sort.Interface":     {"Len", "Less", "Swap"},
+}
+
+// isGoFile reports whether the Go fallback supports the file at the given
+// path.
+func isGoFile(filePath string) bool {
+	return strings.HasSuffix(filePath, ".go")
+}
+
+// goRepo is a repository at a commit, with the Go types declared in it.
+type goRepo struct {
+	repo   *types.Repo
+	commit api.CommitID
+	types  []protocol.GoType // ordered by package and name
+
+	module *string // the module path in the root go.mod file, once read
+}
+
+// goFallback answers the implementations and type definition queries on Go
+// files, which LSIF data doesn't answer, from the types that the symbols
+// service extracts from repositories and the dependency graph between
+// repositories. The results are
+// approximate: they are based on syntax alone, without type checking.
+type goFallback struct {
+	repos map[api.RepoID]*goRepo
+}
+
+func newGoFallback() *goFallback {
+	return &goFallback{repos: map[api.RepoID]*goRepo{}}
+}
+
+// typeDefinitions returns the definition of the type of the identifier at
+// the position in the file: the type itself, or the type of a variable.
+func (g *goFallback) typeDefinitions(ctx context.Context, repo *types.Repo, commit api.CommitID, filePath string, line, character int) ([]*lsif.LSIFLocation, error) {
+	r, t, err := g.typeAt(ctx, repo, commit, filePath, line, character, true)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return []*lsif.LSIFLocation{goTypeLocation(r, t)}, nil
+}
+
+// implementations returns the implementations of the interface at the
+// position in the file, searched in its repository and the repositories
+// that depend on it, or the interfaces that the concrete type at the
+// position implements, searched in its repository and its dependencies.
+func (g *goFallback) implementations(ctx context.Context, repo *types.Repo, commit api.CommitID, filePath string, line, character int, limit int) ([]*lsif.LSIFLocation, error) {
+	r, t, err := g.typeAt(ctx, repo, commit, filePath, line, character, false)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	methods := g.methodSet(r, t)
+	if len(methods) == 0 {
+		// Every type implements the empty interface, and types without
+		// methods only implement it.
+		return nil, nil
+	}
+
+	var others []*types.Repo
+	if t.Interface {
+		others, err = g.dependents(ctx, r)
+	} else {
+		others, err = g.dependencies(ctx, r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The other repositories are only loaded once the types of the previous
+	// ones didn't reach the limit.
+	var locations []*lsif.LSIFLocation
+	for n := -1; n < len(others); n++ {
+		cr := r
+		if n >= 0 {
+			if cr, err = g.loadOther(ctx, others[n]); err != nil {
+				return nil, err
+			}
+			if cr == nil {
+				continue
+			}
+		}
+		for i := range cr.types {
+			ct := &cr.types[i]
+			if ct.Interface == t.Interface {
+				continue
+			}
+			var ok bool
+			if t.Interface {
+				ok = goMethodSubset(methods, g.methodSet(cr, ct))
+			} else {
+				ms := g.methodSet(cr, ct)
+				ok = len(ms) > 0 && goMethodSubset(ms, methods)
+			}
+			if !ok {
+				continue
+			}
+			locations = append(locations, goTypeLocation(cr, ct))
+			if limit > 0 && len(locations) >= limit {
+				return locations, nil
+			}
+		}
+	}
+	return locations, nil
+}
+
+// typeAt returns the named type referenced by the identifier at the
+// position in the file and the repository that declares it, or nil if it
+// cannot be determined. If typeOf is true, the type of a variable at the
+// position is also returned.
+func (g *goFallback) typeAt(ctx context.Context, repo *types.Repo, commit api.CommitID, filePath string, line, character int, typeOf bool) (*goRepo, *protocol.GoType, error) {
+	data, err := git.ReadFile(ctx, gitserver.Repo{Name: repo.Name}, commit, filePath, maxGoFallbackFileSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	fset := token.NewFileSet()
+	// The partial syntax tree of files with errors is still useful.
+	f, _ := parser.ParseFile(fset, filePath, data, 0)
+	if f == nil {
+		return nil, nil, nil
+	}
+
+	ident, parent := goIdentAt(fset, f, data, line, character)
+	if ident == nil {
+		return nil, nil, nil
+	}
+	imports := goImports(f)
+	ref, ok := goTypeRefAt(ident, parent, imports)
+	if !ok && typeOf {
+		ref, ok = goNamedType(goDeclaredType(ident))
+	}
+	if !ok {
+		return nil, nil, nil
+	}
+
+	r, err := g.load(ctx, repo, commit)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkg := path.Dir(filePath)
+	if pkg == "." {
+		pkg = ""
+	}
+	if ref.qualifier != "" {
+		importPath := imports[ref.qualifier]
+		if importPath == "" {
+			return nil, nil, nil
+		}
+		r, pkg, err = g.resolveImport(ctx, r, importPath)
+		if err != nil || r == nil {
+			return nil, nil, err
+		}
+	}
+	return r, r.lookup(pkg, ref.name), nil
+}
+
+// methodSet returns the names of the methods of t, which is declared in r.
+// The methods of interfaces embedded from other packages are only known for
+// the common interfaces of the standard library.
+func (g *goFallback) methodSet(r *goRepo, t *protocol.GoType) map[string]bool {
+	methods := map[string]bool{}
+	var add func(t *protocol.GoType, seen map[string]bool)
+	add = func(t *protocol.GoType, seen map[string]bool) {
+		for _, name := range t.Methods {
+			methods[name] = true
+		}
+		for _, embed := range t.Embeds {
+			if seen[embed] {
+				continue
+			}
+			seen[embed] = true
+			if !strings.Contains(embed, ".") {
+				if et := r.lookup(t.Package, embed); et != nil {
+					add(et, seen)
+					continue
+				}
+			}
+			for _, name := range goStdlibInterfaces[embed] {
+				methods[name] = true
+			}
+		}
+	}
+	add(t, map[string]bool{t.Name: true})
+	return methods
+}
+
+// load returns the repository at the given commit with its Go types.
+func (g *goFallback) load(ctx context.Context, repo *types.Repo, commit api.CommitID) (*goRepo, error) {
+	if r, ok := g.repos[repo.ID]; ok && r.commit == commit {
+		return r, nil
+	}
+	ts, err := listGoTypes(ctx, repo.Name, commit)
+	if err != nil {
+		return nil, err
+	}
+	r := &goRepo{repo: repo, commit: commit, types: ts}
+	g.repos[repo.ID] = r
+	return r, nil
+}
+
+// loadDefaultBranch returns the repository at the head of its default
+// branch with its Go types, or nil if it isn't cloned.
+func (g *goFallback) loadDefaultBranch(ctx context.Context, repo *types.Repo) (*goRepo, error) {
+	if r, ok := g.repos[repo.ID]; ok {
+		return r, nil
+	}
+	commit, err := git.ResolveRevision(ctx, gitserver.Repo{Name: repo.Name}, nil, "HEAD", &git.ResolveRevisionOptions{NoEnsureRevision: true})
+	if err != nil {
+		return nil, ctx.Err()
+	}
+	return g.load(ctx, repo, commit)
+}
+
+// resolveImport returns the repository and the package directory in it of
+// the Go package with the given import path, or nil if it isn't found.
+func (g *goFallback) resolveImport(ctx context.Context, r *goRepo, importPath string) (*goRepo, string, error) {
+	if gosrc.IsStdlibPkg(importPath) {
+		return nil, "", nil
+	}
+
+	module := g.module(ctx, r)
+	if module == "" {
+		module = string(r.repo.Name)
+	}
+	if importPath == module {
+		return r, "", nil
+	}
+	if strings.HasPrefix(importPath, module+"/") {
+		return r, strings.TrimPrefix(importPath, module+"/"), nil
+	}
+
+	// Find the repository with the longest name which is a prefix of the
+	// import path.
+	elems := strings.Split(importPath, "/")
+	for i := len(elems); i >= 2; i-- {
+		repo, err := db.Repos.GetByName(ctx, api.RepoName(strings.Join(elems[:i], "/")))
+		if errcode.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		ir, err := g.loadDefaultBranch(ctx, repo)
+		if err != nil || ir == nil {
+			return nil, "", err
+		}
+		rest := elems[i:]
+		if len(rest) > 0 && goMajorVersionElemRegexp.MatchString(rest[0]) {
+			// The major version suffix of modules isn't a directory.
+			rest = rest[1:]
+		}
+		return ir, strings.Join(rest, "/"), nil
+	}
+	return nil, "", nil
+}
+
+var goMajorVersionElemRegexp = regexp.MustCompile(`^v[0-9]+$`)
+
+// module returns the path of the module declared by the root go.mod file of
+// the repository, or "" if there is none.
+func (g *goFallback) module(ctx context.Context, r *goRepo) string {
+	if r.module == nil {
+		var module string
+		data, err := git.ReadFile(ctx, gitserver.Repo{Name: r.repo.Name}, r.commit, "go.mod", maxGoFallbackFileSize)
+		if err == nil {
+			if m, err := depgraph.Parse("go.mod", data); err == nil && len(m.Provides) > 0 {
+				module = m.Provides[0].Name
+			}
+		}
+		r.module = &module
+	}
+	return *r.module
+}
+
+// dependents returns the repositories which depend on r, in the order in
+// which they are searched.
+func (g *goFallback) dependents(ctx context.Context, r *goRepo) ([]*types.Repo, error) {
+	ids, err := db.RepoPackages.ListDependents(ctx, r.repo.ID, maxGoFallbackRepositories)
+	if err != nil {
+		return nil, err
+	}
+	return g.reposByIDs(ctx, r, ids)
+}
+
+// dependencies returns the repositories that r depends on with Go modules,
+// in the order in which they are searched.
+func (g *goFallback) dependencies(ctx context.Context, r *goRepo) ([]*types.Repo, error) {
+	deps, err := db.RepoPackages.ListDependencies(ctx, r.repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []api.RepoID
+	seen := map[api.RepoID]bool{r.repo.ID: true}
+	for _, dep := range deps {
+		if dep.Kind != depgraph.KindGo || dep.RepoID == 0 || seen[dep.RepoID] {
+			continue
+		}
+		seen[dep.RepoID] = true
+		ids = append(ids, dep.RepoID)
+		if len(ids) == maxGoFallbackRepositories {
+			break
+		}
+	}
+	return g.reposByIDs(ctx, r, ids)
+}
+
+// reposByIDs returns the repositories with the given IDs other than r,
+// ordered by name.
+func (g *goFallback) reposByIDs(ctx context.Context, r *goRepo, ids []api.RepoID) ([]*types.Repo, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// 🚨 SECURITY: Repos.GetByIDs only returns repositories that the user
+	// has access to.
+	repos, err := db.Repos.GetByIDs(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	others := repos[:0]
+	for _, repo := range repos {
+		if repo.ID != r.repo.ID {
+			others = append(others, repo)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	return others, nil
+}
+
+// loadOther returns the default branch of a repository searched for
+// implementations, or nil if it isn't cloned or its Go types can't be
+// listed.
+func (g *goFallback) loadOther(ctx context.Context, repo *types.Repo) (*goRepo, error) {
+	r, err := g.loadDefaultBranch(ctx, repo)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// A single repository that can't be searched shouldn't fail the
+		// query.
+		log15.Warn("listing Go types", "repo", repo.Name, "error", err)
+		return nil, nil
+	}
+	return r, nil
+}
+
+// lookup returns the type with the given name declared in the package
+// directory pkg of the repository, or nil.
+func (r *goRepo) lookup(pkg, name string) *protocol.GoType {
+	i := sort.Search(len(r.types), func(i int) bool {
+		t := r.types[i]
+		return t.Package > pkg || (t.Package == pkg && t.Name >= name)
+	})
+	if i < len(r.types) && r.types[i].Package == pkg && r.types[i].Name == name {
+		return &r.types[i]
+	}
+	return nil
+}
+
+// goMethodSubset reports whether all methods in a are in b.
+func goMethodSubset(a, b map[string]bool) bool {
+	for name := range a {
+		if !b[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// goTypeLocation returns the location of the name of the type t declared in
+// r.
+func goTypeLocation(r *goRepo, t *protocol.GoType) *lsif.LSIFLocation {
+	start := lsp.Position{Line: t.Line, Character: t.Character}
+	end := lsp.Position{Line: t.Line, Character: t.Character + len(utf16.Encode([]rune(t.Name)))}
+	return &lsif.LSIFLocation{
+		RepositoryID: r.repo.ID,
+		Commit:       string(r.commit),
+		Path:         t.Path,
+		Range:        lsp.Range{Start: start, End: end},
+	}
+}
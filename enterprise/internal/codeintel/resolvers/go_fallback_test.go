@@ -0,0 +1,278 @@
+package resolvers
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/depgraph"
+	"github.com/sourcegraph/sourcegraph/internal/lsif"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+type notFoundErr struct{}
+
+func (notFoundErr) Error() string  { return "not found" }
+func (notFoundErr) NotFound() bool { return true }
+
+var (
+	libRepo = &types.Repo{ID: 1, Name: "github.com/a/lib"}
+	appRepo = &types.Repo{ID: 2, Name: "github.com/b/app"}
+
+	goTypesByRepo = map[api.RepoName][]protocol.GoType{
+		libRepo.Name: {
+			{Name: "Store", Path: "lib.go", Line: 2, Character: 5, Interface: true, Methods: []string{"Put"}, Embeds: []string{"getter"}},
+			{Name: "getter", Path: "lib.go", Line: 6, Character: 5, Interface: true, Methods: []string{"Get"}},
+			{Name: "memStore", Path: "lib.go", Line: 10, Character: 5, Methods: []string{"Get", "Put"}},
+		},
+		appRepo.Name: {
+			{Name: "DB", Package: "db", Path: "db/db.go", Line: 2, Character: 5, Methods: []string{"Close", "Get", "Put"}},
+			{Name: "Other", Package: "db", Path: "db/db.go", Line: 4, Character: 5, Methods: []string{"Get"}},
+		},
+	}
+)
+
+const libGo = `package lib
+
+type Store interface {
+	getter
+	Put(key, value string)
+}
+
+type getter interface {
+	Get(key string) string
+}
+
+type memStore map[string]string
+`
+
+const appGo = `package main
+
+import (
+	"github.com/a/lib"
+	"github.com/b/app/db"
+)
+
+func main() {
+	var s lib.Store = &db.DB{}
+	d := &db.DB{}
+}
+`
+
+const dbGo = `package db
+
+type DB struct{}
+
+type Other struct{}
+`
+
+func mockGoFallback(t *testing.T, files map[string]string) {
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if data, ok := files[name]; ok {
+			return []byte(data), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		return "c", nil
+	}
+	db.Mocks.Repos.GetByName = func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		for _, repo := range []*types.Repo{libRepo, appRepo} {
+			if repo.Name == name {
+				return repo, nil
+			}
+		}
+		return nil, notFoundErr{}
+	}
+	db.Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error) {
+		var repos []*types.Repo
+		for _, id := range ids {
+			for _, repo := range []*types.Repo{libRepo, appRepo} {
+				if repo.ID == id {
+					repos = append(repos, repo)
+				}
+			}
+		}
+		return repos, nil
+	}
+	db.Mocks.RepoPackages.ListDependents = func(ctx context.Context, repo api.RepoID, limit int) ([]api.RepoID, error) {
+		if repo == libRepo.ID {
+			return []api.RepoID{appRepo.ID}, nil
+		}
+		return nil, nil
+	}
+	db.Mocks.RepoPackages.ListDependencies = func(ctx context.Context, repo api.RepoID) ([]*db.RepoDependency, error) {
+		if repo == appRepo.ID {
+			return []*db.RepoDependency{{Package: depgraph.Package{Kind: depgraph.KindGo, Name: "github.com/a/lib"}, RepoID: libRepo.ID}}, nil
+		}
+		return nil, nil
+	}
+
+	orig := listGoTypes
+	listGoTypes = func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]protocol.GoType, error) {
+		return goTypesByRepo[repo], nil
+	}
+	t.Cleanup(func() {
+		listGoTypes = orig
+		git.ResetMocks()
+		db.Mocks = db.MockStores{}
+	})
+}
+
+func goLocation(repo *types.Repo, path string, line, character, length int) *lsif.LSIFLocation {
+	return &lsif.LSIFLocation{
+		RepositoryID: repo.ID,
+		Commit:       "c",
+		Path:         path,
+		Range: lsp.Range{
+			Start: lsp.Position{Line: line, Character: character},
+			End:   lsp.Position{Line: line, Character: character + length},
+		},
+	}
+}
+
+func TestGoFallback_TypeDefinitions(t *testing.T) {
+	mockGoFallback(t, map[string]string{
+		"go.mod":  "module github.com/b/app\n",
+		"main.go": appGo,
+	})
+
+	tests := map[string]struct {
+		line, character int
+		want            []*lsif.LSIFLocation
+	}{
+		"qualified type in dependency": {
+			line: 8, character: 12,
+			want: []*lsif.LSIFLocation{goLocation(libRepo, "lib.go", 2, 5, 5)},
+		},
+		"type in same module": {
+			line: 8, character: 23,
+			want: []*lsif.LSIFLocation{goLocation(appRepo, "db/db.go", 2, 5, 2)},
+		},
+		"type of variable": {
+			line: 9, character: 1,
+			want: []*lsif.LSIFLocation{goLocation(appRepo, "db/db.go", 2, 5, 2)},
+		},
+		"package name": {
+			line: 8, character: 9,
+		},
+		"function": {
+			line: 7, character: 6,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			locations, err := newGoFallback().typeDefinitions(context.Background(), appRepo, "c", "main.go", test.line, test.character)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(locations, test.want) {
+				t.Errorf("got %+v, want %+v", locations, test.want)
+			}
+		})
+	}
+}
+
+func TestGoFallback_Implementations(t *testing.T) {
+	t.Run("interface", func(t *testing.T) {
+		mockGoFallback(t, map[string]string{
+			"go.mod": "module github.com/a/lib\n",
+			"lib.go": libGo,
+		})
+		locations, err := newGoFallback().implementations(context.Background(), libRepo, "c", "lib.go", 2, 6, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*lsif.LSIFLocation{
+			goLocation(libRepo, "lib.go", 10, 5, 8),
+			goLocation(appRepo, "db/db.go", 2, 5, 2),
+		}
+		if !reflect.DeepEqual(locations, want) {
+			t.Errorf("got %+v, want %+v", locations, want)
+		}
+	})
+
+	t.Run("interface with limit", func(t *testing.T) {
+		mockGoFallback(t, map[string]string{
+			"go.mod": "module github.com/a/lib\n",
+			"lib.go": libGo,
+		})
+		locations, err := newGoFallback().implementations(context.Background(), libRepo, "c", "lib.go", 2, 6, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(locations) != 1 {
+			t.Errorf("got %d locations, want 1", len(locations))
+		}
+	})
+
+	t.Run("dependents are not loaded once the limit is reached", func(t *testing.T) {
+		mockGoFallback(t, map[string]string{
+			"go.mod": "module github.com/a/lib\n",
+			"lib.go": libGo,
+		})
+		mocked := listGoTypes
+		listGoTypes = func(ctx context.Context, repo api.RepoName, commit api.CommitID) ([]protocol.GoType, error) {
+			if repo != libRepo.Name {
+				t.Errorf("unexpected listing of the Go types of %s", repo)
+			}
+			return mocked(ctx, repo, commit)
+		}
+		if _, err := newGoFallback().implementations(context.Background(), libRepo, "c", "lib.go", 2, 6, 1); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("type", func(t *testing.T) {
+		mockGoFallback(t, map[string]string{
+			"go.mod":   "module github.com/b/app\n",
+			"db/db.go": dbGo,
+		})
+		locations, err := newGoFallback().implementations(context.Background(), appRepo, "c", "db/db.go", 2, 6, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*lsif.LSIFLocation{
+			goLocation(libRepo, "lib.go", 2, 5, 5),
+			goLocation(libRepo, "lib.go", 6, 5, 6),
+		}
+		if !reflect.DeepEqual(locations, want) {
+			t.Errorf("got %+v, want %+v", locations, want)
+		}
+	})
+}
+
+func TestGoImports(t *testing.T) {
+	const src = `package p
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"github.com/a/b/v2"
+	"github.com/c/go-d"
+	_ "github.com/e/f"
+)
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"fmt":  "fmt",
+		"yaml": "gopkg.in/yaml.v2",
+		"b":    "github.com/a/b/v2",
+		"d":    "github.com/c/go-d",
+	}
+	if got := goImports(f); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
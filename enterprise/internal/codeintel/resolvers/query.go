@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 
 	"github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/lsifserver/client"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/lsif"
 )
 
@@ -24,9 +27,27 @@ type lsifQueryResolver struct {
 var _ graphqlbackend.LSIFQueryResolver = &lsifQueryResolver{}
 
 func (r *lsifQueryResolver) Definitions(ctx context.Context, args *graphqlbackend.LSIFQueryPositionArgs) (graphqlbackend.LocationConnectionResolver, error) {
+	locations, err := r.definitions(ctx, args.Line, args.Character)
+	if err != nil {
+		return nil, err
+	}
+	if len(locations) > 0 {
+		return &locationConnectionResolver{
+			repo:      r.repositoryResolver.Type(),
+			commit:    r.commit,
+			locations: locations,
+		}, nil
+	}
+
+	return &locationConnectionResolver{}, nil
+}
+
+// definitions returns the definitions of the symbol at the position from the
+// first upload which has any.
+func (r *lsifQueryResolver) definitions(ctx context.Context, line, character int32) ([]*lsif.LSIFLocation, error) {
 	for _, upload := range r.uploads {
 		// TODO(efritz) - we should also detect renames/copies on position adjustment
-		adjustedPosition, ok, err := r.adjustPosition(ctx, upload.Commit, args.Line, args.Character)
+		adjustedPosition, ok, err := r.adjustPosition(ctx, upload.Commit, line, character)
 		if err != nil {
 			return nil, err
 		}
@@ -56,15 +77,11 @@ func (r *lsifQueryResolver) Definitions(ctx context.Context, args *graphqlbacken
 		}
 
 		if len(locations) > 0 {
-			return &locationConnectionResolver{
-				repo:      r.repositoryResolver.Type(),
-				commit:    r.commit,
-				locations: locations,
-			}, nil
+			return locations, nil
 		}
 	}
 
-	return &locationConnectionResolver{}, nil
+	return nil, nil
 }
 
 func (r *lsifQueryResolver) References(ctx context.Context, args *graphqlbackend.LSIFPagedQueryPositionArgs) (graphqlbackend.LocationConnectionResolver, error) {
@@ -195,6 +212,108 @@ func (r *lsifQueryResolver) Hover(ctx context.Context, args *graphqlbackend.LSIF
 	return nil, nil
 }
 
+func (r *lsifQueryResolver) Implementations(ctx context.Context, args *graphqlbackend.LSIFPagedQueryPositionArgs) (graphqlbackend.LocationConnectionResolver, error) {
+	if !isGoFile(r.path) || args.After != nil {
+		// All implementations are returned on the first page.
+		return &locationConnectionResolver{repo: r.repositoryResolver.Type(), commit: r.commit}, nil
+	}
+
+	pos, err := r.goFallbackPosition(ctx, args.Line, args.Character)
+	if err != nil || pos == nil {
+		return &locationConnectionResolver{repo: r.repositoryResolver.Type(), commit: r.commit}, err
+	}
+	limit := 0
+	if args.First != nil {
+		limit = int(*args.First)
+	}
+	locations, err := newGoFallback().implementations(ctx, pos.repo, pos.commit, pos.path, pos.line, pos.character, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &locationConnectionResolver{
+		repo:      r.repositoryResolver.Type(),
+		commit:    r.commit,
+		locations: locations,
+	}, nil
+}
+
+func (r *lsifQueryResolver) TypeDefinitions(ctx context.Context, args *graphqlbackend.LSIFQueryPositionArgs) (graphqlbackend.LocationConnectionResolver, error) {
+	if !isGoFile(r.path) {
+		return &locationConnectionResolver{repo: r.repositoryResolver.Type(), commit: r.commit}, nil
+	}
+
+	pos, err := r.goFallbackPosition(ctx, args.Line, args.Character)
+	if err != nil || pos == nil {
+		return &locationConnectionResolver{repo: r.repositoryResolver.Type(), commit: r.commit}, err
+	}
+	locations, err := newGoFallback().typeDefinitions(ctx, pos.repo, pos.commit, pos.path, pos.line, pos.character)
+	if err != nil {
+		return nil, err
+	}
+
+	return &locationConnectionResolver{
+		repo:      r.repositoryResolver.Type(),
+		commit:    r.commit,
+		locations: locations,
+	}, nil
+}
+
+// goFallbackPosition is the position in a file at which the Go fallback
+// looks up a type.
+type goFallbackPosition struct {
+	repo            *types.Repo
+	commit          api.CommitID
+	path            string
+	line, character int
+}
+
+// goFallbackPosition returns the position at which the Go fallback looks up
+// the type of the symbol at the requested position. If there are LSIF
+// uploads, it is the definition of the symbol according to LSIF, which is
+// more precise than the syntax of the requested file and saves resolving
+// imports to repositories. It returns nil if LSIF knows no definition of
+// the symbol, or if the definition is in a repository the user can't see.
+func (r *lsifQueryResolver) goFallbackPosition(ctx context.Context, line, character int32) (*goFallbackPosition, error) {
+	if len(r.uploads) == 0 {
+		return &goFallbackPosition{
+			repo:      r.repositoryResolver.Type(),
+			commit:    api.CommitID(r.commit),
+			path:      r.path,
+			line:      int(line),
+			character: int(character),
+		}, nil
+	}
+
+	locations, err := r.definitions(ctx, line, character)
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+	def := locations[0]
+	if !isGoFile(def.Path) {
+		return nil, nil
+	}
+	repo := r.repositoryResolver.Type()
+	if def.RepositoryID != repo.ID {
+		// 🚨 SECURITY: Repos.Get only returns repositories that the user has
+		// access to.
+		repo, err = db.Repos.Get(ctx, def.RepositoryID)
+		if errcode.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &goFallbackPosition{
+		repo:      repo,
+		commit:    api.CommitID(def.Commit),
+		path:      def.Path,
+		line:      def.Range.Start.Line,
+		character: def.Range.Start.Character,
+	}, nil
+}
+
 // adjustPosition adjusts the position denoted by `line` and `character` in the requested commit into an
 // LSP position in the upload commit. This method returns nil if no equivalent position is found.
 func (r *lsifQueryResolver) adjustPosition(ctx context.Context, uploadCommit string, line, character int32) (lsp.Position, bool, error) {
@@ -105,10 +105,12 @@ func (r *Resolver) LSIF(ctx context.Context, args *graphqlbackend.LSIFQueryArgs)
 		return nil, err
 	}
 
-	if len(uploads) == 0 {
+	if len(uploads) == 0 && !(args.GoFallback && isGoFile(args.Path)) {
 		return nil, nil
 	}
 
+	// Without uploads, only implementations and type definitions are
+	// resolved by the Go fallback.
 	return &lsifQueryResolver{
 		repositoryResolver: args.Repository,
 		commit:             args.Commit,
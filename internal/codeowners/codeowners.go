@@ -0,0 +1,347 @@
+// Package codeowners parses CODEOWNERS files and resolves the owners of files
+// in a repository.
+//
+// The GitHub, GitLab and Bitbucket (Code Owners for Bitbucket Server)
+// syntaxes are supported. They all share the same basic structure: each line
+// holds a gitignore-style path pattern followed by the owners of the paths
+// matching it, and the last matching line wins. In addition:
+//
+//   - GitLab files may be split into sections (e.g. "[Docs] @docs-team").
+//     The last matching line of every section applies, and lines without
+//     owners use the default owners of their section. GitLab does not
+//     distinguish users from groups ("@group"), so in GitLab files (those
+//     below .gitlab/ or with sections) all "@" owners are treated as teams.
+//   - Bitbucket files may define teams (e.g. "@@@Team @alice @bob"), refer
+//     to groups ("@@Group"), exclude paths ("!pattern") and contain merge
+//     check directives, which are ignored.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Owner is an owner listed in a CODEOWNERS file.
+type Owner struct {
+	// Name is the owner as written in the CODEOWNERS file, e.g. "@alice",
+	// "@org/team", "@@Group" or "alice@example.com".
+	Name string
+
+	// IsTeam is whether the owner is a team or group rather than an
+	// individual user.
+	IsTeam bool
+}
+
+// Handle returns the name of the owner without its leading "@" characters.
+func (o Owner) Handle() string {
+	return strings.TrimLeft(o.Name, "@")
+}
+
+// Matches reports whether the owner is identified by s. s may be given with
+// or without its leading "@" characters, and is compared case-insensitively.
+func (o Owner) Matches(s string) bool {
+	return strings.EqualFold(o.Handle(), strings.TrimLeft(s, "@"))
+}
+
+func parseOwner(s string) Owner {
+	switch {
+	case strings.HasPrefix(s, "@@"):
+		// Bitbucket group or team.
+		return Owner{Name: s, IsTeam: true}
+	case strings.HasPrefix(s, "@"):
+		// GitHub team ("@org/team") or GitLab group ("@group/subgroup").
+		return Owner{Name: s, IsTeam: strings.Contains(s, "/")}
+	default:
+		return Owner{Name: s}
+	}
+}
+
+type rule struct {
+	pattern string
+	re      *regexp.Regexp
+	owners  []Owner
+	section string
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	// Path is the path of the CODEOWNERS file in the repository, if known.
+	Path string
+
+	rules    []rule
+	sections []string // in the order in which they appear
+}
+
+// Parse parses the contents of a CODEOWNERS file. Lines that cannot be
+// parsed are skipped, so that a single bad line does not disable ownership
+// for the whole repository.
+func Parse(data []byte) *Ruleset {
+	var (
+		rs             = &Ruleset{sections: []string{""}}
+		section        string
+		sectionDefault []Owner
+		teams          = map[string][]Owner{}
+	)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// GitLab sections: "[Name]", "^[Optional]", "[Name][2] @default-owner".
+		if name, defaults, ok := parseSection(line); ok {
+			section = strings.ToLower(name)
+			sectionDefault = nil
+			for _, f := range fields(defaults) {
+				sectionDefault = append(sectionDefault, parseOwner(f))
+			}
+			if !containsString(rs.sections, section) {
+				rs.sections = append(rs.sections, section)
+			}
+			continue
+		}
+
+		// Bitbucket directives, e.g. "Check(@@@Team >= 1)" or
+		// "CODEOWNERS.toplevel.assignment_routing random 1".
+		if isBitbucketDirective(line) {
+			continue
+		}
+
+		fs := fields(line)
+		// Bitbucket team definitions: "@@@Team @alice @bob".
+		if strings.HasPrefix(fs[0], "@@@") {
+			var members []Owner
+			for _, f := range fs[1:] {
+				members = append(members, parseOwner(f))
+			}
+			teams[strings.ToLower(fs[0])] = members
+			continue
+		}
+
+		pattern := fs[0]
+		exclude := strings.HasPrefix(pattern, "!")
+		if exclude {
+			pattern = pattern[1:]
+		}
+		re, err := compilePattern(pattern)
+		if err != nil {
+			continue
+		}
+
+		var owners []Owner
+		if !exclude {
+			for _, f := range fs[1:] {
+				owner := parseOwner(f)
+				owners = append(owners, owner)
+				owners = append(owners, teams[strings.ToLower(f)]...)
+			}
+			if len(owners) == 0 {
+				owners = sectionDefault
+			}
+		}
+		rs.rules = append(rs.rules, rule{pattern: fs[0], re: re, owners: owners, section: section})
+	}
+	if len(rs.sections) > 1 {
+		rs.markGitLabGroups()
+	}
+	return rs
+}
+
+// markGitLabGroups marks all owners whose names start with "@" as teams,
+// because they can refer to GitLab groups as well as users.
+func (rs *Ruleset) markGitLabGroups() {
+	for i := range rs.rules {
+		owners := make([]Owner, len(rs.rules[i].owners))
+		for j, o := range rs.rules[i].owners {
+			if strings.HasPrefix(o.Name, "@") {
+				o.IsTeam = true
+			}
+			owners[j] = o
+		}
+		rs.rules[i].owners = owners
+	}
+}
+
+// Match returns the owners of the file or directory at path (relative to the
+// repository root). It returns nil if the path has no owners.
+func (rs *Ruleset) Match(path string) []Owner {
+	if rs == nil {
+		return nil
+	}
+	path = strings.Trim(path, "/")
+
+	// The last matching rule of each section wins.
+	last := make(map[string]*rule, len(rs.sections))
+	for i := range rs.rules {
+		if rs.rules[i].re.MatchString(path) {
+			last[rs.rules[i].section] = &rs.rules[i]
+		}
+	}
+
+	var owners []Owner
+	seen := map[string]bool{}
+	for _, section := range rs.sections {
+		r, ok := last[section]
+		if !ok {
+			continue
+		}
+		for _, o := range r.owners {
+			if key := strings.ToLower(o.Name); !seen[key] {
+				seen[key] = true
+				owners = append(owners, o)
+			}
+		}
+	}
+	return owners
+}
+
+// OwnedBy reports whether the file or directory at path is owned by the
+// owner identified by name (see Owner.Matches).
+func (rs *Ruleset) OwnedBy(path, name string) bool {
+	for _, o := range rs.Match(path) {
+		if o.Matches(name) {
+			return true
+		}
+	}
+	return false
+}
+
+var sectionPattern = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?(.*)$`)
+
+func parseSection(line string) (name, defaults string, ok bool) {
+	m := sectionPattern.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	// A pattern such as "[abc]*.go" is a path pattern, not a section: the
+	// remainder of a section header may only contain owners.
+	if m[2] != "" && !strings.HasPrefix(m[2], " ") && !strings.HasPrefix(m[2], "\t") {
+		return "", "", false
+	}
+	defaults = strings.TrimSpace(m[2])
+	for _, f := range fields(defaults) {
+		if !strings.Contains(f, "@") {
+			return "", "", false
+		}
+	}
+	return strings.TrimSpace(m[1]), defaults, true
+}
+
+func isBitbucketDirective(line string) bool {
+	return strings.HasPrefix(line, "CODEOWNERS.") ||
+		strings.HasPrefix(line, "Check(") ||
+		strings.HasPrefix(line, "OverallCheck(")
+}
+
+// fields splits a line on whitespace, honoring backslash-escaped spaces in
+// patterns, and drops trailing comments.
+func fields(line string) []string {
+	var (
+		fs  []string
+		cur strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			fs = append(fs, cur.String())
+			cur.Reset()
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			cur.WriteByte(c)
+			cur.WriteByte(line[i+1])
+			i++
+		case c == '#' && cur.Len() == 0:
+			flush()
+			return fs
+		case c == ' ' || c == '\t':
+			flush()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return fs
+}
+
+// compilePattern compiles a gitignore-style pattern to a regexp matching the
+// paths it applies to, including all paths in the directories it matches.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// Patterns with a slash (other than a trailing one) are relative to the
+	// repository root; others match at any depth.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored && !strings.HasPrefix(pattern, "**") {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories.
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				c = pattern[i]
+			}
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package codeowners
+
+import (
+	"container/list"
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func names(owners []Owner) []string {
+	var ns []string
+	for _, o := range owners {
+		ns = append(ns, o.Name)
+	}
+	return ns
+}
+
+func TestRuleset_Match(t *testing.T) {
+	tests := map[string]struct {
+		file string
+		want map[string][]string // path -> owner names
+	}{
+		"github": {
+			file: `
+# Default owners.
+*       @global-owner
+*.js    @js-owner # inline comment
+**/logs @logs-team
+/build/logs/ @doctocat
+docs/*  docs@example.com
+apps/   @octocat
+/docs/getting-started.md
+my\ file.txt @org/spaces
+`,
+			want: map[string][]string{
+				"README.md":                   {"@global-owner"},
+				"src/app.js":                  {"@js-owner"},
+				"build/logs/a.txt":            {"@doctocat"},
+				"other/build/logs/a.txt":      {"@logs-team"},
+				"docs/a.md":                   {"docs@example.com"},
+				"docs/sub/a.md":               {"docs@example.com"},
+				"docs/getting-started.md":     nil,
+				"x/apps/main.go":              {"@octocat"},
+				"apps":                        {"@global-owner"},
+				"deep/logs/x":                 {"@logs-team"},
+				"my file.txt":                 {"@org/spaces"},
+				"/src/app.js":                 {"@js-owner"},
+				"src/app.json":                {"@global-owner"},
+				"docs/getting-started.md.bak": {"docs@example.com"},
+			},
+		},
+		"gitlab sections": {
+			file: `
+* @default
+[Docs] @docs-team
+docs/
+*.md @writers
+
+^[Go][2]
+*.go @gophers
+[abc]*.txt @letters
+`,
+			want: map[string][]string{
+				"main.go":   {"@default", "@gophers"},
+				"docs/a.go": {"@default", "@docs-team", "@gophers"},
+				"docs/a.md": {"@default", "@writers"},
+				"a.txt":     {"@default", "@letters"},
+				"d.txt":     {"@default"},
+			},
+		},
+		"bitbucket": {
+			file: `
+CODEOWNERS.toplevel.assignment_routing random 1
+@@@Backend @alice @bob
+* @@Admins
+src/ @@@Backend
+!src/generated/
+Check(@@@Backend >= 1)
+`,
+			want: map[string][]string{
+				"README":              {"@@Admins"},
+				"src/a.java":          {"@@@Backend", "@alice", "@bob"},
+				"src/generated/a.go":  nil,
+				"other/src/generated": {"@@@Backend", "@alice", "@bob"},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rs := Parse([]byte(test.file))
+			for path, want := range test.want {
+				if got := names(rs.Match(path)); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: got owners %q, want %q", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestOwner(t *testing.T) {
+	rs := Parse([]byte("* @alice @org/team @@Group bob@example.com"))
+	owners := rs.Match("a")
+	if want := []bool{false, true, true, false}; len(owners) != len(want) {
+		t.Fatalf("got %d owners, want %d", len(owners), len(want))
+	} else {
+		for i, o := range owners {
+			if o.IsTeam != want[i] {
+				t.Errorf("%s: got IsTeam %v, want %v", o.Name, o.IsTeam, want[i])
+			}
+		}
+	}
+	for _, name := range []string{"alice", "@Alice", "org/team", "@@group", "group", "bob@example.com"} {
+		if !rs.OwnedBy("a", name) {
+			t.Errorf("expected a to be owned by %q", name)
+		}
+	}
+	if rs.OwnedBy("a", "carol") {
+		t.Error("expected a not to be owned by carol")
+	}
+
+	var nilRuleset *Ruleset
+	if nilRuleset.OwnedBy("a", "alice") {
+		t.Error("expected nothing to be owned without a ruleset")
+	}
+
+	// GitLab groups can't be told apart from users.
+	rs = Parse([]byte("[Section]\n* @group bob@example.com"))
+	for _, o := range rs.Match("a") {
+		if want := o.Name == "@group"; o.IsTeam != want {
+			t.Errorf("%s: got IsTeam %v, want %v", o.Name, o.IsTeam, want)
+		}
+	}
+}
+
+func TestRulesetCache(t *testing.T) {
+	c := &rulesetCache{m: map[string]*list.Element{}, lru: list.New()}
+	for i := 0; i < maxCacheSize; i++ {
+		c.set(strconv.Itoa(i), &Ruleset{})
+	}
+	// Using the oldest entry keeps it when the next entry is added.
+	if _, ok := c.get("0"); !ok {
+		t.Fatal("expected entry 0 to be cached")
+	}
+	c.set("new", &Ruleset{})
+	if _, ok := c.get("0"); !ok {
+		t.Error("expected recently used entry 0 to be kept")
+	}
+	if _, ok := c.get("1"); ok {
+		t.Error("expected least recently used entry 1 to be evicted")
+	}
+	if len(c.m) != maxCacheSize || c.lru.Len() != maxCacheSize {
+		t.Errorf("got %d entries, want %d", len(c.m), maxCacheSize)
+	}
+}
+
+func TestForCommit(t *testing.T) {
+	defer git.ResetMocks()
+
+	var reads []string
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		reads = append(reads, name)
+		if commit == "c1" && name == "CODEOWNERS" {
+			return []byte("* @alice"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	ctx := context.Background()
+	repo := gitserver.Repo{Name: "r"}
+	rs, err := ForCommit(ctx, repo, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs == nil || rs.Path != "CODEOWNERS" || !rs.OwnedBy("x", "alice") {
+		t.Fatalf("got ruleset %+v, want one read from CODEOWNERS", rs)
+	}
+	if want := []string{".github/CODEOWNERS", ".gitlab/CODEOWNERS", ".bitbucket/CODEOWNERS", "CODEOWNERS"}; !reflect.DeepEqual(reads, want) {
+		t.Errorf("got reads %q, want %q", reads, want)
+	}
+
+	// Results are cached per commit.
+	reads = nil
+	if _, err := ForCommit(ctx, repo, "c1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(reads) != 0 {
+		t.Errorf("got reads %q, want none", reads)
+	}
+
+	rs, err = ForCommit(ctx, repo, "c2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs != nil {
+		t.Errorf("got ruleset %+v, want nil", rs)
+	}
+}
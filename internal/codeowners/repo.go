@@ -0,0 +1,99 @@
+package codeowners
+
+import (
+	"container/list"
+	"context"
+	"os"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// Paths are the paths at which CODEOWNERS files are looked up, in order of
+// precedence. The first one that exists is used.
+var Paths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	".bitbucket/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// maxFileSize is the maximum size of a CODEOWNERS file that is read. GitHub
+// ignores CODEOWNERS files larger than 3 MB.
+const maxFileSize = 3 * 1024 * 1024
+
+// ForCommit returns the CODEOWNERS ruleset of the repository at the given
+// commit. It returns nil if the repository has no CODEOWNERS file.
+func ForCommit(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (*Ruleset, error) {
+	key := string(repo.Name) + "@" + string(commit)
+	if rs, ok := cache.get(key); ok {
+		return rs, nil
+	}
+
+	var rs *Ruleset
+	for _, path := range Paths {
+		data, err := git.ReadFile(ctx, repo, commit, path, maxFileSize)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rs = Parse(data)
+		rs.Path = path
+		if path == ".gitlab/CODEOWNERS" {
+			rs.markGitLabGroups()
+		}
+		break
+	}
+	cache.set(key, rs)
+	return rs, nil
+}
+
+// cache holds the rulesets of recently used commits. Commits are immutable,
+// so entries never need to be invalidated, and the least recently used entry
+// is evicted when the cache is full.
+var cache = &rulesetCache{m: map[string]*list.Element{}, lru: list.New()}
+
+const maxCacheSize = 1000
+
+type rulesetCache struct {
+	mu  sync.Mutex
+	m   map[string]*list.Element
+	lru *list.List // of *rulesetCacheEntry, most recently used first
+}
+
+type rulesetCacheEntry struct {
+	key string
+	rs  *Ruleset
+}
+
+func (c *rulesetCache) get(key string) (*Ruleset, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.m[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*rulesetCacheEntry).rs, true
+}
+
+func (c *rulesetCache) set(key string, rs *Ruleset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.m[key]; ok {
+		e.Value.(*rulesetCacheEntry).rs = rs
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.m[key] = c.lru.PushFront(&rulesetCacheEntry{key: key, rs: rs})
+	if c.lru.Len() > maxCacheSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.m, oldest.Value.(*rulesetCacheEntry).key)
+	}
+}
@@ -328,6 +328,10 @@ func AndOrQueryEnabled() bool {
 	return e.AndOrQuery == "enabled"
 }
 
+func SearchResultsCacheEnabled() bool {
+	return ExperimentalFeatures().SearchResultsCache == "enabled"
+}
+
 func SearchMultipleRevisionsPerRepository() bool {
 	x := ExperimentalFeatures()
 	return x.SearchMultipleRevisionsPerRepository != nil && *x.SearchMultipleRevisionsPerRepository
@@ -350,3 +354,23 @@ func AuthMinPasswordLength() int {
 	}
 	return val
 }
+
+// SearchHistoryEnabled reports whether searches are recorded in users' search
+// history (the site config "search.history.enabled" value, true by default).
+func SearchHistoryEnabled() bool {
+	val := Get().SearchHistory
+	if val == nil || val.Enabled == nil {
+		return true
+	}
+	return *val.Enabled
+}
+
+// SearchHistoryRetentionDays returns 90, or the site config
+// "search.history.retentionDays" value if configured.
+func SearchHistoryRetentionDays() int {
+	val := Get().SearchHistory
+	if val == nil || val.RetentionDays <= 0 {
+		return 90
+	}
+	return val.RetentionDays
+}
@@ -133,6 +133,11 @@ func CriticalGetLatest(ctx context.Context) (latest *CriticalConfig, err error)
 }
 
 func newTransaction(ctx context.Context) (tx queryable, done func(), err error) {
+	if ctxTx := dbconn.TxFromContext(ctx); ctxTx != nil {
+		// The transaction carried by ctx is committed by whoever started it.
+		return ctxTx, func() {}, nil
+	}
+
 	rtx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, nil, err
@@ -0,0 +1,46 @@
+package dbconn
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+)
+
+// Queryer is implemented by both *sql.DB and *sql.Tx.
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// Transaction calls fn with a context that carries a new transaction on Global. The transaction is
+// committed if fn returns nil and rolled back otherwise. Stores that run their queries with
+// FromContext take part in the transaction.
+//
+// If ctx already carries a transaction, fn is called with ctx and the transaction is left for the
+// outer call to commit or roll back.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if TxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+	return dbutil.Transaction(ctx, Global, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// TxFromContext returns the transaction carried by ctx (see Transaction), or nil if there is none.
+func TxFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+	return tx
+}
+
+// FromContext returns the transaction carried by ctx (see Transaction), or Global if there is none.
+func FromContext(ctx context.Context) Queryer {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return Global
+}
@@ -0,0 +1,108 @@
+// Package depgraph extracts the packages that a repository provides and
+// depends on from its manifest files, such as go.mod and package.json.
+package depgraph
+
+import (
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Package kinds, one per package ecosystem.
+const (
+	KindGo    = "go"
+	KindNPM   = "npm"
+	KindMaven = "maven"
+	KindPip   = "pip"
+	KindCargo = "cargo"
+)
+
+// Package is a package in a package ecosystem.
+type Package struct {
+	Kind string // the package ecosystem (e.g. KindGo)
+	Name string // the package name, as written in manifest files of the ecosystem
+
+	// Version is the version of the package that is provided, or the version
+	// (or version constraint) that is depended on. It is empty if unknown.
+	Version string
+}
+
+// Manifest is what a set of manifest files declares.
+type Manifest struct {
+	Provides     []Package // the packages defined by the manifest files
+	Dependencies []Package // the packages depended on
+}
+
+// parsers holds the manifest parsers, by manifest file name.
+var parsers = map[string]func(data []byte) (*Manifest, error){
+	"go.mod":           parseGoMod,
+	"package.json":     parsePackageJSON,
+	"pom.xml":          parsePOM,
+	"requirements.txt": parseRequirements,
+	"Cargo.toml":       parseCargoToml,
+}
+
+// IsManifest reports whether the file at the given path is a manifest file
+// that can be parsed.
+func IsManifest(filePath string) bool {
+	_, ok := parsers[path.Base(filePath)]
+	return ok
+}
+
+// Parse parses the contents of the manifest file at the given path.
+func Parse(filePath string, data []byte) (*Manifest, error) {
+	parse, ok := parsers[path.Base(filePath)]
+	if !ok {
+		return nil, errors.Errorf("not a manifest file: %s", filePath)
+	}
+	m, err := parse(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", filePath)
+	}
+	return m, nil
+}
+
+// add adds the packages of other to m.
+func (m *Manifest) add(other *Manifest) {
+	m.Provides = append(m.Provides, other.Provides...)
+	m.Dependencies = append(m.Dependencies, other.Dependencies...)
+}
+
+// normalize sorts the packages of m and removes duplicates. A package that
+// is depended on by multiple manifest files is kept with the first version.
+// Dependencies on provided packages (e.g. between the modules of a
+// monorepo) are removed.
+func (m *Manifest) normalize() {
+	m.Provides = dedupe(m.Provides)
+	m.Dependencies = dedupe(m.Dependencies)
+
+	provided := make(map[[2]string]bool, len(m.Provides))
+	for _, p := range m.Provides {
+		provided[[2]string{p.Kind, p.Name}] = true
+	}
+	deps := m.Dependencies[:0]
+	for _, p := range m.Dependencies {
+		if !provided[[2]string{p.Kind, p.Name}] {
+			deps = append(deps, p)
+		}
+	}
+	m.Dependencies = deps
+}
+
+func dedupe(pkgs []Package) []Package {
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		if pkgs[i].Kind != pkgs[j].Kind {
+			return pkgs[i].Kind < pkgs[j].Kind
+		}
+		return pkgs[i].Name < pkgs[j].Name
+	})
+	deduped := pkgs[:0]
+	for _, p := range pkgs {
+		if n := len(deduped); n > 0 && p.Kind == deduped[n-1].Kind && p.Name == deduped[n-1].Name {
+			continue
+		}
+		deduped = append(deduped, p)
+	}
+	return deduped
+}
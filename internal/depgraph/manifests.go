@@ -0,0 +1,290 @@
+package depgraph
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseGoMod parses a go.mod file. Indirect dependencies are ignored.
+func parseGoMod(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	inRequire := false
+	for _, line := range lines(data) {
+		line, comment := cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inRequire {
+			if fields[0] == ")" {
+				inRequire = false
+				continue
+			}
+		} else {
+			switch fields[0] {
+			case "module":
+				if len(fields) == 2 {
+					m.Provides = append(m.Provides, Package{Kind: KindGo, Name: unquote(fields[1])})
+				}
+				continue
+			case "require":
+				if len(fields) == 2 && fields[1] == "(" {
+					inRequire = true
+					continue
+				}
+				fields = fields[1:]
+			default:
+				// Skip blocks of other directives (replace, exclude).
+				continue
+			}
+		}
+
+		if len(fields) != 2 || strings.TrimSpace(comment) == "indirect" {
+			continue
+		}
+		m.Dependencies = append(m.Dependencies, Package{Kind: KindGo, Name: unquote(fields[0]), Version: fields[1]})
+	}
+	return m, nil
+}
+
+// parsePackageJSON parses a package.json file. Development, peer and
+// optional dependencies are included.
+func parsePackageJSON(data []byte) (*Manifest, error) {
+	var pkg struct {
+		Name                 string
+		Version              string
+		Dependencies         map[string]string
+		DevDependencies      map[string]string
+		PeerDependencies     map[string]string
+		OptionalDependencies map[string]string
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if pkg.Name != "" {
+		m.Provides = append(m.Provides, Package{Kind: KindNPM, Name: pkg.Name, Version: pkg.Version})
+	}
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			m.Dependencies = append(m.Dependencies, Package{Kind: KindNPM, Name: name, Version: deps[name]})
+		}
+	}
+	return m, nil
+}
+
+// parsePOM parses a Maven pom.xml file. Maven packages are named
+// "groupId:artifactId". Versions that reference properties are unknown.
+func parsePOM(data []byte) (*Manifest, error) {
+	type artifact struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	}
+	var project struct {
+		artifact
+		Parent       artifact   `xml:"parent"`
+		Dependencies []artifact `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+
+	version := func(v string) string {
+		v = strings.TrimSpace(v)
+		if strings.Contains(v, "${") {
+			return ""
+		}
+		return v
+	}
+
+	m := &Manifest{}
+	groupID := project.GroupID
+	if groupID == "" {
+		// The group ID is inherited from the parent.
+		groupID = project.Parent.GroupID
+	}
+	if groupID != "" && project.ArtifactID != "" {
+		m.Provides = append(m.Provides, Package{
+			Kind:    KindMaven,
+			Name:    strings.TrimSpace(groupID) + ":" + strings.TrimSpace(project.ArtifactID),
+			Version: version(project.Version),
+		})
+	}
+	for _, dep := range project.Dependencies {
+		if dep.GroupID == "" || dep.ArtifactID == "" {
+			continue
+		}
+		m.Dependencies = append(m.Dependencies, Package{
+			Kind:    KindMaven,
+			Name:    strings.TrimSpace(dep.GroupID) + ":" + strings.TrimSpace(dep.ArtifactID),
+			Version: version(dep.Version),
+		})
+	}
+	return m, nil
+}
+
+var (
+	requirementNameRegexp      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+	requirementSeparatorRegexp = regexp.MustCompile(`[-_.]+`)
+)
+
+// parseRequirements parses a pip requirements.txt file. Options (such as
+// "-r other.txt") and requirements given as URLs or paths are ignored.
+// Names are normalized as described in PEP 503.
+func parseRequirements(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	for _, line := range lines(data) {
+		line, _ = cut(line, " #")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		line, _ = cut(line, ";") // environment markers
+
+		name := requirementNameRegexp.FindString(line)
+		if name == "" {
+			continue
+		}
+		spec := strings.TrimSpace(line[len(name):])
+		if strings.HasPrefix(spec, ":") || strings.HasPrefix(spec, "+") || strings.HasPrefix(spec, "/") {
+			// Not a name, but a URL (as in "git+https://...") or a path.
+			continue
+		}
+		if strings.HasPrefix(spec, "[") {
+			// Skip extras, as in "requests[security]".
+			if i := strings.Index(spec, "]"); i >= 0 {
+				spec = strings.TrimSpace(spec[i+1:])
+			}
+		}
+		if strings.HasPrefix(spec, "@") {
+			// A direct reference, as in "name @ https://...".
+			spec = ""
+		}
+		if strings.HasPrefix(spec, "==") {
+			spec = strings.TrimSpace(spec[2:])
+		}
+
+		m.Dependencies = append(m.Dependencies, Package{
+			Kind:    KindPip,
+			Name:    strings.ToLower(requirementSeparatorRegexp.ReplaceAllString(name, "-")),
+			Version: spec,
+		})
+	}
+	return m, nil
+}
+
+var (
+	cargoVersionRegexp = regexp.MustCompile(`\bversion\s*=\s*("[^"]*"|'[^']*')`)
+	tomlStringRegexp   = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'[^']*')`)
+)
+
+// parseCargoToml parses a Rust Cargo.toml file. It only understands the
+// subset of TOML that is used to declare packages and dependencies.
+func parseCargoToml(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	var (
+		table string   // the current table
+		dep   *Package // the dependency declared by the current table, if any
+		pkg   Package  // the package declared by the [package] table
+	)
+	isDependencyTable := func(name string) bool {
+		// Dependencies are declared in [dependencies], [dev-dependencies],
+		// [build-dependencies] and [target.<cfg>.dependencies] tables.
+		return name == "dependencies" || strings.HasSuffix(name, "-dependencies") || strings.HasSuffix(name, ".dependencies")
+	}
+	for i, line := range lines(data) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, errors.Errorf("line %d: invalid table header", i+1)
+			}
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			dep = nil
+			// A dependency can be declared as a table, as in
+			// [dependencies.serde].
+			if j := strings.LastIndex(table, "."); j >= 0 && isDependencyTable(table[:j]) {
+				m.Dependencies = append(m.Dependencies, Package{Kind: KindCargo, Name: unquote(table[j+1:])})
+				dep = &m.Dependencies[len(m.Dependencies)-1]
+			}
+			continue
+		}
+
+		key, value := cut(line, "=")
+		key, value = unquote(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if s := tomlStringRegexp.FindString(value); s != "" {
+			// Drop trailing comments.
+			value = s
+		}
+		switch {
+		case table == "package" && key == "name":
+			pkg.Name = unquote(value)
+		case table == "package" && key == "version":
+			pkg.Version = unquote(value)
+		case dep != nil && key == "version":
+			dep.Version = unquote(value)
+		case isDependencyTable(table):
+			version := value
+			if strings.HasPrefix(value, "{") {
+				version = ""
+				if match := cargoVersionRegexp.FindStringSubmatch(value); match != nil {
+					version = match[1]
+				}
+			}
+			m.Dependencies = append(m.Dependencies, Package{Kind: KindCargo, Name: key, Version: unquote(version)})
+		}
+	}
+	if pkg.Name != "" {
+		m.Provides = append(m.Provides, Package{Kind: KindCargo, Name: pkg.Name, Version: pkg.Version})
+	}
+	return m, nil
+}
+
+func lines(data []byte) []string {
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
+
+// cut returns the text before and after the first sep in s, or s if it
+// doesn't contain sep.
+func cut(s, sep string) (before, after string) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+	return s, ""
+}
+
+// unquote removes the quotes around a quoted string.
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
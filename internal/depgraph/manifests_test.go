@@ -0,0 +1,145 @@
+package depgraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want Manifest
+	}{
+		"go.mod": {
+			data: `module github.com/a/b
+
+go 1.13
+
+require github.com/c/d v1.2.3
+
+require (
+	github.com/e/f v0.1.0 // indirect
+	"github.com/g/h" v2.0.0+incompatible
+)
+
+replace github.com/c/d => ../d
+`,
+			want: Manifest{
+				Provides: []Package{{Kind: KindGo, Name: "github.com/a/b"}},
+				Dependencies: []Package{
+					{Kind: KindGo, Name: "github.com/c/d", Version: "v1.2.3"},
+					{Kind: KindGo, Name: "github.com/g/h", Version: "v2.0.0+incompatible"},
+				},
+			},
+		},
+		"web/package.json": {
+			data: `{
+  "name": "@a/web",
+  "version": "1.0.0",
+  "dependencies": {"react": "^16.0.0", "lodash": "4.17.15"},
+  "devDependencies": {"typescript": "~3.8"}
+}`,
+			want: Manifest{
+				Provides: []Package{{Kind: KindNPM, Name: "@a/web", Version: "1.0.0"}},
+				Dependencies: []Package{
+					{Kind: KindNPM, Name: "lodash", Version: "4.17.15"},
+					{Kind: KindNPM, Name: "react", Version: "^16.0.0"},
+					{Kind: KindNPM, Name: "typescript", Version: "~3.8"},
+				},
+			},
+		},
+		"pom.xml": {
+			data: `<?xml version="1.0"?>
+<project>
+  <parent><groupId>org.example</groupId><artifactId>parent</artifactId></parent>
+  <artifactId>app</artifactId>
+  <version>1.0</version>
+  <dependencies>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.12</version></dependency>
+    <dependency><groupId>org.example</groupId><artifactId>lib</artifactId><version>${project.version}</version></dependency>
+  </dependencies>
+</project>`,
+			want: Manifest{
+				Provides: []Package{{Kind: KindMaven, Name: "org.example:app", Version: "1.0"}},
+				Dependencies: []Package{
+					{Kind: KindMaven, Name: "junit:junit", Version: "4.12"},
+					{Kind: KindMaven, Name: "org.example:lib"},
+				},
+			},
+		},
+		"requirements.txt": {
+			data: `# Comment
+-r base.txt
+Django==2.2.10
+requests[security] >= 2.8.1 ; python_version < "3.8"
+zope.interface  # pinned elsewhere
+pkg @ https://example.com/pkg.zip
+git+https://github.com/a/b.git#egg=b
+`,
+			want: Manifest{
+				Dependencies: []Package{
+					{Kind: KindPip, Name: "django", Version: "2.2.10"},
+					{Kind: KindPip, Name: "requests", Version: ">= 2.8.1"},
+					{Kind: KindPip, Name: "zope-interface"},
+					{Kind: KindPip, Name: "pkg"},
+				},
+			},
+		},
+		"Cargo.toml": {
+			data: `[package]
+name = "app"
+version = "0.1.0" # comment
+
+[dependencies]
+serde = "1.0"
+tokio = { version = "0.2", features = ["full"] }
+local = { path = "../local" }
+
+[dependencies.rand]
+version = "0.7"
+features = ["small_rng"]
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+
+[dev-dependencies]
+"quickcheck" = '0.9'
+`,
+			want: Manifest{
+				Provides: []Package{{Kind: KindCargo, Name: "app", Version: "0.1.0"}},
+				Dependencies: []Package{
+					{Kind: KindCargo, Name: "serde", Version: "1.0"},
+					{Kind: KindCargo, Name: "tokio", Version: "0.2"},
+					{Kind: KindCargo, Name: "local"},
+					{Kind: KindCargo, Name: "rand", Version: "0.7"},
+					{Kind: KindCargo, Name: "libc", Version: "0.2"},
+					{Kind: KindCargo, Name: "quickcheck", Version: "0.9"},
+				},
+			},
+		},
+	}
+	for path, test := range tests {
+		t.Run(path, func(t *testing.T) {
+			m, err := Parse(path, []byte(test.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*m, test.want) {
+				t.Errorf("got %+v, want %+v", *m, test.want)
+			}
+		})
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for path, data := range map[string]string{
+		"package.json": "{",
+		"pom.xml":      "<project>",
+		"Cargo.toml":   "[package",
+		"README.md":    "",
+	} {
+		if _, err := Parse(path, []byte(data)); err == nil {
+			t.Errorf("%s: got no error", path)
+		}
+	}
+}
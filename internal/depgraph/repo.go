@@ -0,0 +1,68 @@
+package depgraph
+
+import (
+	"context"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+const (
+	// maxManifests is the maximum number of manifest files that are read in
+	// a repository.
+	maxManifests = 100
+
+	// maxManifestSize is the maximum size of a manifest file that is read.
+	maxManifestSize = 1024 * 1024
+)
+
+// ignoredDirs are directories whose manifest files describe third-party
+// code, not the repository itself.
+var ignoredDirs = []string{"vendor", "node_modules", "third_party", "testdata"}
+
+// ForCommit returns the packages declared by the manifest files of the
+// repository at the given commit. Manifest files which can't be read or
+// parsed are skipped.
+func ForCommit(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (*Manifest, error) {
+	entries, err := git.ReadDir(ctx, repo, commit, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	n := 0
+	for _, e := range entries {
+		if !e.Mode().IsRegular() || !IsManifest(e.Name()) || isIgnored(e.Name()) || e.Size() > maxManifestSize {
+			continue
+		}
+		if n++; n > maxManifests {
+			break
+		}
+
+		data, err := git.ReadFile(ctx, repo, commit, e.Name(), maxManifestSize)
+		if err != nil {
+			log15.Warn("skipping unreadable manifest file", "repo", repo.Name, "commit", commit, "path", e.Name(), "error", err)
+			continue
+		}
+		fm, err := Parse(e.Name(), data)
+		if err != nil {
+			log15.Warn("skipping invalid manifest file", "repo", repo.Name, "commit", commit, "error", err)
+			continue
+		}
+		m.add(fm)
+	}
+	m.normalize()
+	return m, nil
+}
+
+func isIgnored(path string) bool {
+	for _, dir := range ignoredDirs {
+		if strings.HasPrefix(path, dir+"/") || strings.Contains(path, "/"+dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package depgraph
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/util"
+)
+
+func TestForCommit(t *testing.T) {
+	defer git.ResetMocks()
+	git.Mocks.ReadDir = func(commit api.CommitID, name string, recurse bool) ([]os.FileInfo, error) {
+		return []os.FileInfo{
+			&util.FileInfo{Name_: "cmd", Mode_: os.ModeDir},
+			&util.FileInfo{Name_: "go.mod"},
+			&util.FileInfo{Name_: "web/package.json"},
+			&util.FileInfo{Name_: "web/node_modules/react/package.json"},
+			&util.FileInfo{Name_: "vendor/github.com/c/d/go.mod"},
+			&util.FileInfo{Name_: "tools/go.mod"},
+			&util.FileInfo{Name_: "broken/package.json"},
+			&util.FileInfo{Name_: "unreadable/go.mod"},
+		}, nil
+	}
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		switch name {
+		case "go.mod":
+			return []byte("module github.com/a/b\nrequire github.com/c/d v1.0.0\n"), nil
+		case "tools/go.mod":
+			return []byte("module github.com/a/b/tools\nrequire (\n\tgithub.com/a/b v0.0.0\n\tgithub.com/c/d v1.1.0\n)\n"), nil
+		case "web/package.json":
+			return []byte(`{"name": "web", "dependencies": {"react": "16"}}`), nil
+		case "broken/package.json":
+			return []byte(`{`), nil
+		case "unreadable/go.mod":
+			return nil, errors.New("read error")
+		}
+		t.Errorf("unexpected read of %s", name)
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+
+	m, err := ForCommit(context.Background(), gitserver.Repo{Name: "r"}, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Manifest{
+		Provides: []Package{
+			{Kind: KindGo, Name: "github.com/a/b"},
+			{Kind: KindGo, Name: "github.com/a/b/tools"},
+			{Kind: KindNPM, Name: "web"},
+		},
+		Dependencies: []Package{
+			{Kind: KindGo, Name: "github.com/c/d", Version: "v1.0.0"},
+			{Kind: KindNPM, Name: "react", Version: "16"},
+		},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("got %+v, want %+v", m, want)
+	}
+}
@@ -0,0 +1,43 @@
+package endpoint
+
+import "sort"
+
+// Change describes an update to the endpoints of a Map.
+type Change struct {
+	// Before and After are the sorted endpoints before and after the change.
+	Before []string
+	After  []string
+
+	// Added and Removed are the sorted endpoints that were added to and
+	// removed from the Map.
+	Added   []string
+	Removed []string
+}
+
+func newChange(old, new *hashMap) Change {
+	var c Change
+	for v := range values(old) {
+		c.Before = append(c.Before, v)
+		if _, ok := values(new)[v]; !ok {
+			c.Removed = append(c.Removed, v)
+		}
+	}
+	for v := range values(new) {
+		c.After = append(c.After, v)
+		if _, ok := values(old)[v]; !ok {
+			c.Added = append(c.Added, v)
+		}
+	}
+	sort.Strings(c.Before)
+	sort.Strings(c.After)
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	return c
+}
+
+func values(m *hashMap) map[string]struct{} {
+	if m == nil {
+		return nil
+	}
+	return m.values
+}
@@ -0,0 +1,99 @@
+package endpoint
+
+import (
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+)
+
+const dnsSRVPrefix = "dns+srv+"
+
+// srvRefreshInterval is how often DNS SRV records are resolved again.
+var srvRefreshInterval = 10 * time.Second
+
+// lookupSRV is net.LookupSRV. It is a variable so tests can stub it out.
+var lookupSRV = net.LookupSRV
+
+func newSRVMap(urlspec string) *Map {
+	m := &Map{urlspec: urlspec}
+
+	m.init = func() (*hashMap, error) {
+		u, err := url.Parse(strings.TrimPrefix(urlspec, dnsSRVPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if u.Hostname() == "" {
+			return nil, errors.Errorf("invalid dns srv url. expected dns+srv+http://_service._proto.name/path, got %s", urlspec)
+		}
+
+		urls, err := resolveSRV(u)
+
+		interval := srvRefreshInterval
+
+		// Kick off resolver in the background.
+		go func() {
+			last, lastErr := urls, err
+			for {
+				time.Sleep(interval)
+				urls, err := resolveSRV(u)
+				if err != nil {
+					// Keep serving the last known endpoints. Resolution errors
+					// are usually transient.
+					// Only log when the error changes to avoid flooding the
+					// logs.
+					if lastErr == nil || err.Error() != lastErr.Error() {
+						log15.Warn("failed to resolve dns srv endpoints", "name", u.Hostname(), "error", err)
+					}
+					lastErr = err
+					continue
+				}
+				lastErr = nil
+				if reflect.DeepEqual(urls, last) {
+					continue
+				}
+				last = urls
+				m.set(newConsistentHashMap(urls), nil)
+			}
+		}()
+
+		if err != nil {
+			return nil, err
+		}
+		return newConsistentHashMap(urls), nil
+	}
+
+	return m
+}
+
+// resolveSRV returns the sorted endpoint URLs for the SRV records of u's
+// host.
+func resolveSRV(u *url.URL) ([]string, error) {
+	_, addrs, err := lookupSRV("", "", u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		host := net.JoinHostPort(strings.TrimSuffix(addr.Target, "."), strconv.Itoa(int(addr.Port)))
+		if u.Scheme == "rpc" {
+			urls = append(urls, host)
+			continue
+		}
+		uCopy := *u
+		uCopy.Host = host
+		urls = append(urls, uCopy.String())
+	}
+	if len(urls) == 0 {
+		return nil, errors.Errorf("no SRV records found for %s", u.Hostname())
+	}
+	sort.Strings(urls)
+	return urls, nil
+}
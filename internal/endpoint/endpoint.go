@@ -22,11 +22,12 @@ import (
 // the endpoints for a service and update the map when they change. It can
 // also fallback to static URLs if not configured for kubernetes.
 type Map struct {
-	mu      sync.Mutex
-	init    func() (*hashMap, error)
-	err     error
-	urls    *hashMap
-	urlspec string
+	mu          sync.Mutex
+	init        func() (*hashMap, error)
+	err         error
+	urls        *hashMap
+	urlspec     string
+	subscribers []func(Change)
 }
 
 // New creates a new Map for the URL specifier.
@@ -37,17 +38,37 @@ type Map struct {
 // the endpoints for the Kubernetes service. The values returned by Get will
 // look like http://endpoint:port/path.
 //
-// If the scheme is not prefixed with "k8s+", a space separated list of URLs is
-// expected. The map will consistently hash against these URLs in this case.
-// This is useful for specifying non-Kubernetes endpoints.
+// If the scheme is "file", the URLs are read from the file at the given path
+// and the map is updated whenever the file changes. The file contains
+// whitespace separated URLs, lines starting with "#" are ignored.
+//
+// If the scheme is prefixed with "dns+srv+", one URL is expected and its host
+// is resolved as a DNS SRV record, e.g.
+// dns+srv+http://_searcher._tcp.example.com/path. The map is updated whenever
+// the records change. The values returned by Get will look like
+// http://target:port/path. The "dns+srv+rpc" scheme is for services that are
+// addressed without a URL, such as gitserver: the values returned by Get will
+// look like target:port.
+//
+// Otherwise a space separated list of URLs is expected. The map will
+// consistently hash against these URLs in this case. This is useful for
+// specifying non-Kubernetes endpoints.
 //
 // Examples URL specifiers:
 //
 // 	"k8s+http://searcher"
+// 	"file:///etc/sourcegraph/searcher-endpoints"
+// 	"dns+srv+http://_searcher._tcp.example.com"
+// 	"dns+srv+rpc://_gitserver._tcp.example.com"
 // 	"http://searcher-1 http://searcher-2 http://searcher-3"
 //
 func New(urlspec string) *Map {
-	if !strings.HasPrefix(urlspec, "k8s+") {
+	switch {
+	case strings.HasPrefix(urlspec, "file://"):
+		return newFileMap(urlspec)
+	case strings.HasPrefix(urlspec, dnsSRVPrefix):
+		return newSRVMap(urlspec)
+	case !strings.HasPrefix(urlspec, "k8s+"):
 		return &Map{
 			urlspec: urlspec,
 			urls:    newConsistentHashMap(strings.Fields(urlspec)),
@@ -158,6 +179,33 @@ func (m *Map) Endpoints() (map[string]struct{}, error) {
 	return urls.values, nil
 }
 
+// Subscribe registers fn to be called with every change to the endpoints of
+// m after it was first accessed. fn is called from the goroutine watching for
+// changes, so it should not block.
+func (m *Map) Subscribe(fn func(Change)) {
+	m.mu.Lock()
+	m.subscribers = append(m.subscribers, fn)
+	m.mu.Unlock()
+}
+
+// set updates the endpoints of m and notifies subscribers if they changed.
+func (m *Map) set(urls *hashMap, err error) {
+	m.mu.Lock()
+	old := m.urls
+	m.urls, m.err = urls, err
+	subscribers := m.subscribers
+	m.mu.Unlock()
+
+	c := newChange(old, urls)
+	if len(c.Added) == 0 && len(c.Removed) == 0 {
+		return
+	}
+	log15.Info("endpoints changed", "urlspec", m.urlspec, "added", c.Added, "removed", c.Removed)
+	for _, fn := range subscribers {
+		fn(c)
+	}
+}
+
 func (m *Map) getUrls() (*hashMap, error) {
 	m.mu.Lock()
 	if m.init != nil {
@@ -188,10 +236,7 @@ func inform(client *k8s.Client, m *Map, u *k8sURL) error {
 			log15.Warn(`eventType is not "added" or "modified"`, "eventType", eventType, "subsets", endpoints.Subsets)
 			endpoints.Subsets = nil
 		}
-		urls, err := endpointsToMap(u, &endpoints)
-		m.mu.Lock()
-		m.urls, m.err = urls, err
-		m.mu.Unlock()
+		m.set(endpointsToMap(u, &endpoints))
 	}
 }
 
@@ -2,8 +2,13 @@ package endpoint
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -116,3 +121,100 @@ func TestK8sURL(t *testing.T) {
 		}
 	}
 }
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "endpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	orig := fileRefreshInterval
+	fileRefreshInterval = time.Millisecond
+	defer func() { fileRefreshInterval = orig }()
+
+	path := filepath.Join(dir, "endpoints")
+	write := func(content string) {
+		t.Helper()
+		tmp := path + ".tmp"
+		if err := ioutil.WriteFile(tmp, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("# gitservers\nhttp://test-1 http://test-2\n\nhttp://test-3\n")
+
+	m := New("file://" + path)
+	changes := make(chan Change, 1)
+	m.Subscribe(func(c Change) { changes <- c })
+	expectEndpoints(t, m, nil, "http://test-1", "http://test-2", "http://test-3")
+
+	write("http://test-2\nhttp://test-3\nhttp://test-4\n")
+	select {
+	case c := <-changes:
+		if want := []string{"http://test-4"}; !reflect.DeepEqual(c.Added, want) {
+			t.Errorf("unexpected added endpoints:\n%s", cmp.Diff(want, c.Added))
+		}
+		if want := []string{"http://test-1"}; !reflect.DeepEqual(c.Removed, want) {
+			t.Errorf("unexpected removed endpoints:\n%s", cmp.Diff(want, c.Removed))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+	expectEndpoints(t, m, nil, "http://test-2", "http://test-3", "http://test-4")
+
+	// A broken file keeps the last known endpoints.
+	write("# nothing\n")
+	time.Sleep(10 * time.Millisecond)
+	expectEndpoints(t, m, nil, "http://test-2", "http://test-3", "http://test-4")
+}
+
+func TestFile_Missing(t *testing.T) {
+	m := New("file:///does/not/exist")
+	if _, err := m.Get("foo", nil); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
+
+func TestDNSSRV(t *testing.T) {
+	orig := lookupSRV
+	defer func() { lookupSRV = orig }()
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		if name != "_searcher._tcp.example.com" {
+			return "", nil, fmt.Errorf("no such host %s", name)
+		}
+		return name, []*net.SRV{
+			{Target: "searcher-2.example.com.", Port: 3181},
+			{Target: "searcher-1.example.com.", Port: 3181},
+		}, nil
+	}
+
+	m := New("dns+srv+http://_searcher._tcp.example.com/path")
+	expectEndpoints(t, m, nil, "http://searcher-1.example.com:3181/path", "http://searcher-2.example.com:3181/path")
+
+	m = New("dns+srv+rpc://_searcher._tcp.example.com")
+	expectEndpoints(t, m, nil, "searcher-1.example.com:3181", "searcher-2.example.com:3181")
+
+	m = New("dns+srv+http://_missing._tcp.example.com")
+	if _, err := m.Get("foo", nil); err == nil {
+		t.Fatal("expected error for unresolvable name")
+	}
+}
+
+func TestChange(t *testing.T) {
+	before := newConsistentHashMap([]string{"b", "a"})
+	after := newConsistentHashMap([]string{"c", "a"})
+
+	want := Change{
+		Before:  []string{"a", "b"},
+		After:   []string{"a", "c"},
+		Added:   []string{"c"},
+		Removed: []string{"b"},
+	}
+	if have := newChange(before, after); !reflect.DeepEqual(have, want) {
+		t.Fatalf("unexpected change:\n%s", cmp.Diff(want, have))
+	}
+}
@@ -0,0 +1,97 @@
+package endpoint
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+)
+
+// fileRefreshInterval is how often endpoint files are checked for changes. We
+// poll rather than watch since the files are often swapped out by symlink,
+// e.g. when mounted from a Kubernetes ConfigMap.
+var fileRefreshInterval = 5 * time.Second
+
+func newFileMap(urlspec string) *Map {
+	m := &Map{urlspec: urlspec}
+
+	m.init = func() (*hashMap, error) {
+		u, err := url.Parse(urlspec)
+		if err != nil {
+			return nil, err
+		}
+		path := u.Path
+		if path == "" {
+			return nil, errors.Errorf("invalid file url. expected file:///path/to/file, got %s", urlspec)
+		}
+
+		urls, err := readEndpointsFile(path)
+
+		interval := fileRefreshInterval
+
+		// Kick off watcher in the background. It also picks up the file if
+		// it doesn't exist yet.
+		go func() {
+			last, lastErr := urls, err
+			for {
+				time.Sleep(interval)
+				urls, err := readEndpointsFile(path)
+				if err != nil {
+					// Keep serving the last known endpoints.
+					// Only log when the error changes to avoid flooding the
+					// logs.
+					if lastErr == nil || err.Error() != lastErr.Error() {
+						log15.Warn("failed to read endpoints file", "path", path, "error", err)
+					}
+					lastErr = err
+					continue
+				}
+				lastErr = nil
+				if reflect.DeepEqual(urls, last) {
+					continue
+				}
+				last = urls
+				m.set(newConsistentHashMap(urls), nil)
+			}
+		}()
+
+		if err != nil {
+			return nil, err
+		}
+		return newConsistentHashMap(urls), nil
+	}
+
+	return m
+}
+
+// readEndpointsFile returns the whitespace separated URLs in the file at
+// path, ignoring lines starting with "#".
+func readEndpointsFile(path string) ([]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, strings.Fields(line)...)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, errors.Errorf("no endpoints found in %s", path)
+	}
+	return urls, nil
+}
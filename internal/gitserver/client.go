@@ -109,6 +109,32 @@ func addrForKey(addrs []string, key string) string {
 	return addrs[serverIndex]
 }
 
+// RepoReassignment is a repository which is assigned to a different gitserver
+// after the gitserver addresses changed.
+type RepoReassignment struct {
+	Repo api.RepoName
+	From string
+	To   string
+}
+
+// Reassignments returns the repos which AddrForRepo assigns to a different
+// gitserver once the addresses change from oldAddrs to newAddrs.
+func Reassignments(oldAddrs, newAddrs []string, repos []api.RepoName) []RepoReassignment {
+	if len(oldAddrs) == 0 || len(newAddrs) == 0 {
+		return nil
+	}
+
+	var rs []RepoReassignment
+	for _, repo := range repos {
+		key := string(protocol.NormalizeRepo(repo))
+		from, to := addrForKey(oldAddrs, key), addrForKey(newAddrs, key)
+		if from != to {
+			rs = append(rs, RepoReassignment{Repo: repo, From: from, To: to})
+		}
+	}
+	return rs
+}
+
 // ArchiveOptions contains options for the Archive func.
 type ArchiveOptions struct {
 	Treeish string   // the tree or commit to produce an archive for
@@ -249,6 +275,15 @@ func (c *Cmd) sendExec(ctx context.Context) (_ io.ReadCloser, _ http.Header, err
 		EnsureRevision: c.EnsureRevision,
 		Args:           c.Args[1:],
 	}
+	if c.Alternate != nil {
+		alt := *c.Alternate
+		alt.Repo = protocol.NormalizeRepo(alt.Repo)
+		if alt.Addr == "" {
+			alt.Addr = c.client.AddrForRepo(ctx, alt.Repo)
+		}
+		req.Alternate = &alt
+		span.SetTag("alternate", alt.Repo)
+	}
 	resp, err := c.client.httpPost(ctx, repoName, "exec", req)
 	if err != nil {
 		return nil, nil, err
@@ -292,6 +327,10 @@ type Cmd struct {
 	Repo           // the repository to execute the command in
 	EnsureRevision string
 	ExitStatus     int
+
+	// Alternate, if set, is a repository whose objects are available to the
+	// command in addition to those of Repo. Its Addr is filled in if empty.
+	Alternate *protocol.AlternateRepo
 }
 
 // Repo represents a repository on gitserver. It contains the information necessary to identify and
@@ -754,6 +793,46 @@ func (c *Client) Remove(ctx context.Context, repo api.RepoName) error {
 	return nil
 }
 
+// MigrateRepos asks the gitserver at addr to transfer the given repositories
+// from the gitservers that previously owned them, rather than cloning them
+// from their code host. The transfers happen in the background.
+func (c *Client) MigrateRepos(ctx context.Context, addr string, migrations []protocol.RepoMigration) error {
+	req := &protocol.RepoMigrateRequest{Migrations: migrations}
+	resp, err := c.httpPost(ctx, "", "http://"+addr+"/repo-migrate", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return &url.Error{URL: resp.Request.URL.String(), Op: "MigrateRepos", Err: fmt.Errorf("MigrateRepos: http status %d: %s", resp.StatusCode, string(body))}
+	}
+	return nil
+}
+
+// RepoMigrations returns the status of the repository migrations on the
+// gitserver at addr.
+func (c *Client) RepoMigrations(ctx context.Context, addr string) ([]protocol.RepoMigrationStatus, error) {
+	req, err := http.NewRequest("GET", "http://"+addr+"/repo-migrations", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &url.Error{URL: resp.Request.URL.String(), Op: "RepoMigrations", Err: fmt.Errorf("RepoMigrations: http status %d", resp.StatusCode)}
+	}
+
+	var statuses []protocol.RepoMigrationStatus
+	err = json.NewDecoder(resp.Body).Decode(&statuses)
+	return statuses, err
+}
+
 func (c *Client) httpPost(ctx context.Context, repo api.RepoName, op string, payload interface{}) (resp *http.Response, err error) {
 	return c.do(ctx, repo, "POST", op, payload)
 }
@@ -240,3 +240,32 @@ func createSimpleGitRepo(t *testing.T, root string) string {
 
 	return dir
 }
+
+func TestReassignments(t *testing.T) {
+	var repos []api.RepoName
+	for i := 0; i < 100; i++ {
+		repos = append(repos, api.RepoName(fmt.Sprintf("github.com/foo/bar%d", i)))
+	}
+
+	addrs := []string{"gitserver-0", "gitserver-1"}
+	if rs := gitserver.Reassignments(addrs, addrs, repos); len(rs) != 0 {
+		t.Fatalf("expected no reassignments for unchanged addresses, got %d", len(rs))
+	}
+
+	newAddrs := []string{"gitserver-0", "gitserver-1", "gitserver-2"}
+	cli := gitserver.NewClient(&http.Client{})
+	cli.Addrs = func(ctx context.Context) []string { return newAddrs }
+
+	rs := gitserver.Reassignments(addrs, newAddrs, repos)
+	if len(rs) == 0 {
+		t.Fatal("expected reassignments after adding a gitserver")
+	}
+	for _, r := range rs {
+		if r.From == r.To {
+			t.Fatalf("unexpected reassignment %+v", r)
+		}
+		if have := cli.AddrForRepo(context.Background(), r.Repo); have != r.To {
+			t.Fatalf("repo %s: have address %s, want %s", r.Repo, have, r.To)
+		}
+	}
+}
@@ -22,6 +22,25 @@ type ExecRequest struct {
 	EnsureRevision string      `json:"ensureRevision"`
 	Args           []string    `json:"args"`
 	Opt            *RemoteOpts `json:"opt"`
+
+	// Alternate, if set, makes the objects of another repository available
+	// to the command, e.g. to compare a repository with one of its forks.
+	Alternate *AlternateRepo `json:"alternate,omitempty"`
+}
+
+// AlternateRepo is a repository whose objects are made available to a
+// command in another repository.
+type AlternateRepo struct {
+	Repo api.RepoName `json:"repo"`
+
+	// Addr is the address of the gitserver that has Repo cloned. If it is
+	// not the gitserver running the command, the objects of Repo are copied
+	// from it.
+	Addr string `json:"addr"`
+
+	// Commit is a commit that must exist in Repo. A copy of the objects of
+	// Repo that doesn't contain it is out of date and fetched again.
+	Commit api.CommitID `json:"commit"`
 }
 
 // RemoteOpts configures interactions with a remote repository.
@@ -203,3 +222,45 @@ type CreateCommitFromPatchError struct {
 func (e *CreateCommitFromPatchError) Error() string {
 	return e.InternalError
 }
+
+// RepoExportRequest is a request to stream the $GIT_DIR of a cloned
+// repository as a tar archive. It is used to move repositories between
+// gitserver instances.
+type RepoExportRequest struct {
+	Repo api.RepoName `json:"repo"`
+}
+
+// RepoMigration describes a repository which should be transferred from
+// the gitserver at From.
+type RepoMigration struct {
+	Repo api.RepoName `json:"repo"`
+	From string       `json:"from"` // address of the gitserver that has the repo cloned
+}
+
+// RepoMigrateRequest is a request to transfer already cloned repositories
+// from other gitserver instances rather than cloning them from their code
+// host.
+type RepoMigrateRequest struct {
+	Migrations []RepoMigration `json:"migrations"`
+}
+
+// RepoMigrationState is the state of a single repository migration.
+type RepoMigrationState string
+
+const (
+	RepoMigrationQueued       RepoMigrationState = "queued"
+	RepoMigrationTransferring RepoMigrationState = "transferring"
+	RepoMigrationDone         RepoMigrationState = "done"
+	RepoMigrationFailed       RepoMigrationState = "failed"
+)
+
+// RepoMigrationStatus is the status of a repository migration as reported
+// by the gitserver receiving the repository.
+type RepoMigrationStatus struct {
+	Repo      api.RepoName       `json:"repo"`
+	From      string             `json:"from"`
+	State     RepoMigrationState `json:"state"`
+	Error     string             `json:"error,omitempty"`
+	Bytes     int64              `json:"bytes"` // bytes transferred so far
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
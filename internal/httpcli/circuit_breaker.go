@@ -0,0 +1,172 @@
+package httpcli
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrCircuitOpen is returned by Doers wrapped with NewCircuitBreakerMiddleware
+// while requests to a host fail fast.
+var ErrCircuitOpen = errors.New("httpcli: circuit breaker is open")
+
+// CircuitBreakerPolicy configures NewCircuitBreakerMiddleware.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed requests to a host
+	// after which the breaker opens. Zero disables the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before a single trial
+	// request is let through.
+	OpenTimeout time.Duration
+}
+
+// DefaultCircuitBreakerPolicy is the CircuitBreakerPolicy used for external
+// services that don't configure their own.
+var DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+	FailureThreshold: 10,
+	OpenTimeout:      30 * time.Second,
+}
+
+// NewCircuitBreakerMiddleware returns a Middleware with a circuit breaker per
+// host. After FailureThreshold consecutive failures (network errors or 5xx
+// responses) to a host, requests to it fail with ErrCircuitOpen until
+// OpenTimeout has passed and a trial request succeeds.
+//
+// All Doers wrapped by the returned Middleware share the same breakers.
+func NewCircuitBreakerMiddleware(policy func(*http.Request) CircuitBreakerPolicy) Middleware {
+	breakers := &circuitBreakers{m: make(map[string]*circuitBreaker)}
+	return func(cli Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			p := policy(req)
+			if p.FailureThreshold <= 0 {
+				return cli.Do(req)
+			}
+
+			host := req.URL.Host
+			b := breakers.get(host)
+			if !b.allow(time.Now(), p) {
+				circuitBreakerRejected.WithLabelValues(host).Inc()
+				return nil, ErrCircuitOpen
+			}
+
+			resp, err := cli.Do(req)
+			if err != nil && req.Context().Err() != nil {
+				// Cancellations say nothing about the health of the host.
+				b.release()
+				return resp, err
+			}
+
+			b.record(time.Now(), p, err == nil && resp.StatusCode < 500)
+			return resp, err
+		})
+	}
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+type circuitBreakers struct {
+	mu sync.Mutex
+	m  map[string]*circuitBreaker
+}
+
+func (bs *circuitBreakers) get(host string) *circuitBreaker {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.m[host]
+	if !ok {
+		b = &circuitBreaker{host: host}
+		bs.m[host] = b
+	}
+	return b
+}
+
+type circuitBreaker struct {
+	host string
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int       // consecutive failures while closed
+	openedAt time.Time // when the breaker last opened
+	probing  bool      // whether a trial request is in flight while half-open
+}
+
+// allow returns whether a request may be sent now.
+func (b *circuitBreaker) allow(now time.Time, p CircuitBreakerPolicy) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if now.Sub(b.openedAt) < p.OpenTimeout {
+			return false
+		}
+		b.setState(circuitHalfOpen)
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// release gives up a trial request without recording its outcome.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// record records the outcome of an allowed request.
+func (b *circuitBreaker) record(now time.Time, p CircuitBreakerPolicy, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		b.setState(circuitClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= p.FailureThreshold {
+		b.openedAt = now
+		b.setState(circuitOpen)
+	}
+}
+
+func (b *circuitBreaker) setState(s circuitState) {
+	b.state = s
+	circuitBreakerState.WithLabelValues(b.host).Set(float64(s))
+}
+
+var (
+	circuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "src",
+		Subsystem: "httpcli",
+		Name:      "circuit_breaker_state",
+		Help:      "State of the circuit breaker per external host: 0 is closed, 1 is half-open and 2 is open.",
+	}, []string{"host"})
+
+	circuitBreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "httpcli",
+		Name:      "circuit_breaker_rejected_total",
+		Help:      "Total number of HTTP requests to external hosts that failed fast because the circuit breaker was open.",
+	}, []string{"host"})
+)
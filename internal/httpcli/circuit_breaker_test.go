@@ -0,0 +1,158 @@
+package httpcli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	policy := CircuitBreakerPolicy{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond}
+
+	status := http.StatusInternalServerError
+	calls := 0
+	cli := DoerFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return newStatusResponse(status), nil
+	})
+	doer := NewCircuitBreakerMiddleware(func(*http.Request) CircuitBreakerPolicy { return policy })(cli)
+
+	do := func(host string) error {
+		req, _ := http.NewRequest("GET", "http://"+host, nil)
+		_, err := doer.Do(req)
+		return err
+	}
+
+	// Failures below the threshold go through.
+	for i := 0; i < 2; i++ {
+		if err := do("a.example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Now the breaker is open.
+	if err := do("a.example.com"); err != ErrCircuitOpen {
+		t.Fatalf("have error %v, want %v", err, ErrCircuitOpen)
+	}
+	if calls != 2 {
+		t.Fatalf("have %d calls, want 2", calls)
+	}
+	// Other hosts are unaffected.
+	if err := do("b.example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	// After the timeout a failing trial request opens the breaker again.
+	time.Sleep(policy.OpenTimeout)
+	if err := do("a.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := do("a.example.com"); err != ErrCircuitOpen {
+		t.Fatalf("have error %v, want %v", err, ErrCircuitOpen)
+	}
+
+	// A successful trial request closes it.
+	time.Sleep(policy.OpenTimeout)
+	status = http.StatusOK
+	for i := 0; i < 3; i++ {
+		if err := do("a.example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Now()
+	p := CircuitBreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Minute}
+	b := &circuitBreaker{host: "example.com"}
+
+	b.record(now, p, false)
+	if b.allow(now, p) {
+		t.Fatal("want open breaker to reject requests")
+	}
+
+	later := now.Add(time.Minute)
+	if !b.allow(later, p) {
+		t.Fatal("want a trial request after the timeout")
+	}
+	if b.allow(later, p) {
+		t.Fatal("want only a single trial request")
+	}
+
+	// A canceled trial request lets another one through.
+	b.release()
+	if !b.allow(later, p) {
+		t.Fatal("want a new trial request after release")
+	}
+}
+
+func TestCircuitBreakerMiddleware_IgnoresCancellation(t *testing.T) {
+	policy := CircuitBreakerPolicy{FailureThreshold: 1, OpenTimeout: time.Hour}
+	cli := DoerFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("canceled")
+	})
+	doer := NewCircuitBreakerMiddleware(func(*http.Request) CircuitBreakerPolicy { return policy })(cli)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		if _, err := doer.Do(req.WithContext(ctx)); err == ErrCircuitOpen {
+			t.Fatal("cancellations must not open the breaker")
+		}
+	}
+}
+
+func TestPoliciesMiddleware(t *testing.T) {
+	var have Policies
+	cli := DoerFunc(func(req *http.Request) (*http.Response, error) {
+		have = RequestPolicies(req)
+		return &http.Response{StatusCode: 200}, nil
+	})
+
+	req, _ := http.NewRequest("GET", "https://github.example.com/api/v3", nil)
+	if _, err := cli.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if have != DefaultPolicies {
+		t.Fatalf("have %+v, want default policies", have)
+	}
+
+	want := Policies{
+		Retry:          RetryPolicy{MaxRetries: 7},
+		CircuitBreaker: CircuitBreakerPolicy{FailureThreshold: 1},
+	}
+	if _, err := NewPoliciesMiddleware(want)(cli).Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if have != want {
+		t.Fatalf("have %+v, want %+v", have, want)
+	}
+}
+
+func TestExternalMiddlewareOrder(t *testing.T) {
+	var calls int
+	cli := DoerFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return newStatusResponse(http.StatusServiceUnavailable), nil
+	})
+
+	doer := NewMiddleware(
+		NewCircuitBreakerMiddleware(RequestCircuitBreakerPolicy),
+		NewRetryMiddleware(RequestRetryPolicy),
+	)(cli)
+	doer = NewPoliciesMiddleware(Policies{
+		Retry:          RetryPolicy{MaxRetries: 10, MinDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		CircuitBreaker: CircuitBreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Hour},
+	})(doer)
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if _, err := doer.Do(req); err != ErrCircuitOpen {
+		t.Fatalf("have error %v, want %v", err, ErrCircuitOpen)
+	}
+	// Retries stop as soon as the breaker opens.
+	if calls != 2 {
+		t.Fatalf("have %d calls, want 2", calls)
+	}
+}
@@ -59,11 +59,18 @@ type Factory struct {
 
 // NewExternalHTTPClientFactory returns an httpcli.Factory with common options
 // and middleware pre-set for communicating to external services.
+//
+// Requests are retried and circuit broken according to their Policies, see
+// NewPoliciesMiddleware.
 func NewExternalHTTPClientFactory() *Factory {
 	return NewFactory(
 		// TODO(tsenart): Use middle for Prometheus instrumentation later.
 		NewMiddleware(
 			ContextErrorMiddleware,
+			NewCircuitBreakerMiddleware(RequestCircuitBreakerPolicy),
+			// The retry middleware wraps the circuit breaker, so every attempt
+			// counts towards the breaker and retries stop once it opens.
+			NewRetryMiddleware(RequestRetryPolicy),
 		),
 		// ExternalTransportOpt needs to be before TracedTransportOpt and
 		// NewCachedTransportOpt since it wants to extract a http.Transport,
@@ -0,0 +1,55 @@
+package httpcli
+
+import (
+	"context"
+	"net/http"
+)
+
+// Policies holds the RetryPolicy and CircuitBreakerPolicy used for requests by
+// Doers returned from NewExternalHTTPClientFactory.
+type Policies struct {
+	Retry          RetryPolicy
+	CircuitBreaker CircuitBreakerPolicy
+}
+
+// DefaultPolicies are the Policies of requests that weren't sent through a
+// Doer wrapped with NewPoliciesMiddleware.
+var DefaultPolicies = Policies{
+	Retry:          DefaultRetryPolicy,
+	CircuitBreaker: DefaultCircuitBreakerPolicy,
+}
+
+type policiesKey struct{}
+
+// NewPoliciesMiddleware returns a Middleware that makes the requests sent
+// through it use the given Policies. It must wrap a Doer returned from
+// NewExternalHTTPClientFactory, so that each external service can configure
+// its own policies even if it shares a host with another one.
+func NewPoliciesMiddleware(p Policies) Middleware {
+	return func(cli Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return cli.Do(req.WithContext(context.WithValue(req.Context(), policiesKey{}, p)))
+		})
+	}
+}
+
+// RequestPolicies returns the Policies of req, as set by
+// NewPoliciesMiddleware, or DefaultPolicies.
+func RequestPolicies(req *http.Request) Policies {
+	if p, ok := req.Context().Value(policiesKey{}).(Policies); ok {
+		return p
+	}
+	return DefaultPolicies
+}
+
+// RequestRetryPolicy returns the RetryPolicy of req. It can be passed to
+// NewRetryMiddleware.
+func RequestRetryPolicy(req *http.Request) RetryPolicy {
+	return RequestPolicies(req).Retry
+}
+
+// RequestCircuitBreakerPolicy returns the CircuitBreakerPolicy of req. It can
+// be passed to NewCircuitBreakerMiddleware.
+func RequestCircuitBreakerPolicy(req *http.Request) CircuitBreakerPolicy {
+	return RequestPolicies(req).CircuitBreaker
+}
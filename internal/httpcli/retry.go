@@ -0,0 +1,170 @@
+package httpcli
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RetryPolicy configures the retries done by NewRetryMiddleware.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a request is retried. Zero
+	// disables retries.
+	MaxRetries int
+	// MinDelay is the delay before the first retry. It doubles with every
+	// following retry.
+	MinDelay time.Duration
+	// MaxDelay caps the delay between retries. Responses with a Retry-After
+	// longer than MaxDelay are returned to the caller instead of retried.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy used for external services that
+// don't configure their own.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinDelay:   200 * time.Millisecond,
+	MaxDelay:   time.Minute,
+}
+
+// NewRetryMiddleware returns a Middleware that retries idempotent requests
+// that fail with a network error or a transient HTTP status (429, 500, 502,
+// 503 and 504) with exponential backoff, honouring the Retry-After header.
+//
+// Requests are idempotent if their method is, or if they set an
+// Idempotency-Key header. Requests with a body are only retried if the body
+// can be replayed through Request.GetBody.
+func NewRetryMiddleware(policy func(*http.Request) RetryPolicy) Middleware {
+	return func(cli Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			p := policy(req)
+			if p.MaxRetries <= 0 || !isRetryable(req) {
+				return cli.Do(req)
+			}
+
+			for attempt := 0; ; attempt++ {
+				resp, err := cli.Do(req)
+				if attempt >= p.MaxRetries {
+					return resp, err
+				}
+
+				delay, ok := retryDelay(p, attempt, req, resp, err)
+				if !ok {
+					return resp, err
+				}
+
+				if resp != nil {
+					_, _ = io.Copy(ioutil.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				retriesTotal.WithLabelValues(req.URL.Host).Inc()
+
+				t := time.NewTimer(delay)
+				select {
+				case <-req.Context().Done():
+					t.Stop()
+					return nil, req.Context().Err()
+				case <-t.C:
+				}
+
+				if req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					req = req.Clone(req.Context())
+					req.Body = body
+				}
+			}
+		})
+	}
+}
+
+// isRetryable returns true if req can safely be sent more than once.
+func isRetryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryDelay returns how long to wait before retrying a request that
+// resulted in resp and err, and whether it should be retried at all.
+func retryDelay(p RetryPolicy, attempt int, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		if req.Context().Err() != nil || err == ErrCircuitOpen {
+			return 0, false
+		}
+		return backoff(p, attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if d > p.MaxDelay {
+			return 0, false
+		}
+		return d, true
+	}
+	return backoff(p, attempt), true
+}
+
+// backoff returns the delay before the given retry: exponential in attempt,
+// capped at p.MaxDelay and jittered to avoid thundering herds.
+func backoff(p RetryPolicy, attempt int) time.Duration {
+	d := p.MaxDelay
+	if attempt < 30 {
+		if e := p.MinDelay << uint(attempt); e > 0 && e < d {
+			d = e
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+var retriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "src",
+	Subsystem: "httpcli",
+	Name:      "retries_total",
+	Help:      "Total number of retried HTTP requests to external services.",
+}, []string{"host"})
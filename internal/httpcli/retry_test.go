@@ -0,0 +1,239 @@
+package httpcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryMiddleware(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, MinDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	for _, tc := range []struct {
+		name      string
+		method    string
+		header    http.Header
+		responses []int  // status codes, 0 is a network error
+		calls     int    // expected number of calls
+		status    int    // expected final status
+		err       string // expected final error
+		policy    *RetryPolicy
+	}{
+		{
+			name:      "success is not retried",
+			responses: []int{200},
+			calls:     1,
+			status:    200,
+		},
+		{
+			name:      "transient status is retried",
+			responses: []int{503, 502, 200},
+			calls:     3,
+			status:    200,
+		},
+		{
+			name:      "network error is retried",
+			responses: []int{0, 200},
+			calls:     2,
+			status:    200,
+		},
+		{
+			name:      "gives up after max retries",
+			responses: []int{503, 503, 503, 200},
+			calls:     3,
+			status:    503,
+		},
+		{
+			name:      "gives up after max retries with error",
+			responses: []int{0, 0, 0},
+			calls:     3,
+			err:       "boom",
+		},
+		{
+			name:      "client errors are not retried",
+			responses: []int{404, 200},
+			calls:     1,
+			status:    404,
+		},
+		{
+			name:      "non-idempotent methods are not retried",
+			method:    "POST",
+			responses: []int{503, 200},
+			calls:     1,
+			status:    503,
+		},
+		{
+			name:      "idempotency key makes POST retryable",
+			method:    "POST",
+			header:    http.Header{"Idempotency-Key": []string{"abc"}},
+			responses: []int{503, 200},
+			calls:     2,
+			status:    200,
+		},
+		{
+			name:      "retries disabled",
+			responses: []int{503, 200},
+			calls:     1,
+			status:    503,
+			policy:    &RetryPolicy{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			cli := DoerFunc(func(r *http.Request) (*http.Response, error) {
+				code := tc.responses[calls]
+				calls++
+				if code == 0 {
+					return nil, errors.New("boom")
+				}
+				return newStatusResponse(code), nil
+			})
+
+			p := policy
+			if tc.policy != nil {
+				p = *tc.policy
+			}
+			mw := NewRetryMiddleware(func(*http.Request) RetryPolicy { return p })
+
+			method := tc.method
+			if method == "" {
+				method = "GET"
+			}
+			req, _ := http.NewRequest(method, "http://example.com", nil)
+			for k, vs := range tc.header {
+				req.Header[k] = vs
+			}
+
+			resp, err := mw(cli).Do(req)
+			if have, want := fmt.Sprint(err), fmt.Sprint(errOrNil(tc.err)); have != want {
+				t.Fatalf("have error %q, want %q", have, want)
+			}
+			if resp != nil && resp.StatusCode != tc.status {
+				t.Errorf("have status %d, want %d", resp.StatusCode, tc.status)
+			}
+			if calls != tc.calls {
+				t.Errorf("have %d calls, want %d", calls, tc.calls)
+			}
+		})
+	}
+}
+
+func TestRetryMiddleware_ReplaysBody(t *testing.T) {
+	var bodies []string
+	cli := DoerFunc(func(r *http.Request) (*http.Response, error) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			return newStatusResponse(http.StatusBadGateway), nil
+		}
+		return newStatusResponse(http.StatusOK), nil
+	})
+
+	mw := NewRetryMiddleware(func(*http.Request) RetryPolicy {
+		return RetryPolicy{MaxRetries: 1, MinDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	})
+
+	req, _ := http.NewRequest("PUT", "http://example.com", bytes.NewBufferString("payload"))
+	if _, err := mw(cli).Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || bodies[0] != "payload" || bodies[1] != "payload" {
+		t.Fatalf("unexpected bodies %q", bodies)
+	}
+}
+
+func TestRetryMiddleware_RetryAfter(t *testing.T) {
+	retryAfter := func(v string) Doer {
+		return DoerFunc(func(r *http.Request) (*http.Response, error) {
+			rr := httptest.NewRecorder()
+			rr.Header().Set("Retry-After", v)
+			rr.WriteHeader(http.StatusTooManyRequests)
+			return rr.Result(), nil
+		})
+	}
+
+	policy := RetryPolicy{MaxRetries: 1, MinDelay: time.Millisecond, MaxDelay: time.Second}
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	// A Retry-After within MaxDelay is waited for.
+	start := time.Now()
+	_, _ = NewRetryMiddleware(func(*http.Request) RetryPolicy { return policy })(retryAfter("1")).Do(req)
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("want to wait for Retry-After, waited %s", d)
+	}
+
+	// A Retry-After beyond MaxDelay is returned immediately.
+	calls := 0
+	cli := DoerFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return retryAfter("3600").Do(r)
+	})
+	resp, err := NewRetryMiddleware(func(*http.Request) RetryPolicy { return policy })(cli).Do(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests || calls != 1 {
+		t.Errorf("want single 429 response, got %v, %v after %d calls", resp, err, calls)
+	}
+}
+
+func TestRetryMiddleware_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	cli := DoerFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return newStatusResponse(http.StatusServiceUnavailable), nil
+	})
+
+	mw := NewRetryMiddleware(func(*http.Request) RetryPolicy {
+		return RetryPolicy{MaxRetries: 5, MinDelay: time.Hour, MaxDelay: time.Hour}
+	})
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	_, err := mw(cli).Do(req.WithContext(ctx))
+	if err != context.Canceled {
+		t.Fatalf("have error %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Fatalf("have %d calls, want 1", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		d     time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"garbage", 0, false},
+		{"-1", 0, false},
+		{"120", 2 * time.Minute, true},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	} {
+		d, ok := parseRetryAfter(tc.value, now)
+		if d != tc.d || ok != tc.ok {
+			t.Errorf("%q: have (%s, %t), want (%s, %t)", tc.value, d, ok, tc.d, tc.ok)
+		}
+	}
+}
+
+func errOrNil(s string) error {
+	if s == "" {
+		return nil
+	}
+	return errors.New(s)
+}
+
+func newStatusResponse(code int) *http.Response {
+	rr := httptest.NewRecorder()
+	rr.WriteHeader(code)
+	return rr.Result()
+}
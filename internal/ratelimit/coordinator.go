@@ -0,0 +1,444 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/redispool"
+)
+
+// Priority is the priority of a reservation made against a Coordinator.
+type Priority int
+
+const (
+	// PriorityBackground is used for periodic and batch work (syncing repos,
+	// permissions, changesets). Background reservations may not dip into the
+	// budget reserved for interactive work.
+	PriorityBackground Priority = iota
+	// PriorityInteractive is used for work a user is actively waiting on.
+	PriorityInteractive
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBackground:
+		return "background"
+	case PriorityInteractive:
+		return "interactive"
+	default:
+		return strconv.Itoa(int(p))
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose code host requests reserve API budget
+// at priority p (see Coordinator.Doer).
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority set with WithPriority, or
+// PriorityBackground if there is none.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityBackground
+}
+
+// Key identifies a shared API budget: the external service and the token
+// used to talk to it. The token is only ever stored hashed.
+type Key struct {
+	ExternalServiceID int64
+	Token             string
+}
+
+func (k Key) String() string {
+	sum := sha256.Sum256([]byte(k.Token))
+	return fmt.Sprintf("%d:%s", k.ExternalServiceID, hex.EncodeToString(sum[:8]))
+}
+
+// DefaultCoordinator is the Coordinator shared by all code in a process. It is
+// backed by the redis store so that the budget is shared across services.
+var DefaultCoordinator = NewCoordinator(redispool.Store)
+
+// Coordinator hands out API budget for code host tokens across all Sourcegraph
+// services. Each Key has a token bucket stored in Redis, so the frontend,
+// repo-updater and the permissions syncer all draw from the same budget. If
+// Redis is unavailable the Coordinator falls back to an in-process bucket,
+// which keeps a single process within the limit.
+//
+// Limits are configured per external service with SetLimit, usually by
+// repo-updater, and are published to Redis so that other services pick them
+// up.
+type Coordinator struct {
+	pool *redis.Pool
+
+	// InteractiveReserve is the fraction of the burst that is reserved for
+	// interactive reservations. Background reservations wait rather than use
+	// it.
+	InteractiveReserve float64
+
+	// StarvationThreshold is how long a reservation may wait before it is
+	// counted as starved.
+	StarvationThreshold time.Duration
+
+	mu     sync.Mutex
+	limits map[int64]limitConfig // by external service ID
+	local  map[string]*bucket    // fallback buckets, by Key.String()
+
+	lastWarning time.Time // last time we logged falling back to local buckets
+	lastSweep   time.Time // last time we removed idle local buckets
+
+	clock func() time.Time
+}
+
+type limitConfig struct {
+	limit rate.Limit
+	burst int
+}
+
+// NewCoordinator returns a Coordinator that stores its buckets in pool.
+func NewCoordinator(pool *redis.Pool) *Coordinator {
+	return &Coordinator{
+		pool:                pool,
+		InteractiveReserve:  0.2,
+		StarvationThreshold: time.Minute,
+		limits:              make(map[int64]limitConfig),
+		local:               make(map[string]*bucket),
+	}
+}
+
+// SetLimit sets the rate limit of all tokens used against the given external
+// service, both locally and for every other service sharing the Redis store.
+func (c *Coordinator) SetLimit(externalServiceID int64, limit rate.Limit, burst int) {
+	c.mu.Lock()
+	c.limits[externalServiceID] = limitConfig{limit: limit, burst: burst}
+	c.mu.Unlock()
+
+	if c.pool == nil {
+		return
+	}
+
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("HMSET", c.limitKey(externalServiceID), "rate", formatLimit(limit), "burst", burst)
+	if err != nil {
+		log15.Warn("ratelimit: failed to publish rate limit", "externalServiceID", externalServiceID, "error", err)
+	}
+}
+
+// Reserve blocks until cost units of API budget are available for k at the
+// given priority, or until ctx is done. Reservations for external services
+// without a configured limit return immediately.
+func (c *Coordinator) Reserve(ctx context.Context, k Key, p Priority, cost int) error {
+	waiting.WithLabelValues(p.String()).Inc()
+	defer waiting.WithLabelValues(p.String()).Dec()
+
+	start := c.now()
+	defer func() {
+		waited := c.now().Sub(start)
+		waitDuration.WithLabelValues(p.String()).Observe(waited.Seconds())
+		if waited >= c.StarvationThreshold {
+			starvedTotal.WithLabelValues(p.String()).Inc()
+		}
+	}()
+
+	for {
+		wait, err := c.take(k, p, cost)
+		if err != nil {
+			return err
+		}
+		if wait <= 0 {
+			return nil
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Doer returns an httpcli.Doer that reserves one unit of API budget before
+// each request made with cli, at the priority of the request's context (see
+// WithPriority). The budget is that of the Key returned by key. Requests for
+// which key returns false are not rate limited.
+func (c *Coordinator) Doer(cli httpcli.Doer, key func(ctx context.Context) (Key, bool)) httpcli.Doer {
+	return httpcli.DoerFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		if k, ok := key(ctx); ok {
+			if err := c.Reserve(ctx, k, PriorityFromContext(ctx), 1); err != nil {
+				return nil, err
+			}
+		}
+		return cli.Do(req)
+	})
+}
+
+// take attempts to take cost units from k's bucket. It returns how long to
+// wait before trying again, or zero if the units were taken.
+func (c *Coordinator) take(k Key, p Priority, cost int) (time.Duration, error) {
+	c.mu.Lock()
+	lc, ok := c.limits[k.ExternalServiceID]
+	c.mu.Unlock()
+	if !ok {
+		// Unknown locally, but another service may have published a limit.
+		lc = limitConfig{limit: rate.Inf}
+	}
+
+	if c.pool != nil {
+		wait, err := c.takeRedis(k, p, cost, lc)
+		if err == nil {
+			return wait, nil
+		}
+		if _, ok := err.(errBurstExceeded); ok {
+			return 0, err
+		}
+		redisErrors.Inc()
+		c.warnFallback(err)
+	}
+
+	if lc.limit == rate.Inf {
+		return 0, nil
+	}
+
+	floor, err := c.floor(p, cost, lc.burst)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	b, ok := c.local[k.String()]
+	if !ok {
+		c.sweepLocked(now)
+		b = &bucket{tokens: float64(lc.burst), last: now}
+		c.local[k.String()] = b
+	}
+	return b.take(now, lc.limit, lc.burst, cost, floor), nil
+}
+
+// sweepLocked removes local buckets that have been idle for longer than
+// bucketTTL, like Redis expires them, so that tokens which are no longer used
+// don't accumulate. It runs at most once per bucketTTL. c.mu must be held.
+func (c *Coordinator) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < bucketTTL {
+		return
+	}
+	c.lastSweep = now
+	for k, b := range c.local {
+		if now.Sub(b.last) >= bucketTTL {
+			delete(c.local, k)
+		}
+	}
+}
+
+// takeScript implements the same token bucket as bucket.take. Limits
+// published with SetLimit (KEYS[2]) take precedence over the caller's.
+//
+// ARGV: rate (-1 is infinite), burst, cost, interactive reserve fraction
+// (only for background), now in milliseconds, bucket TTL in milliseconds.
+//
+// Returns the number of milliseconds to wait, or -2 if cost exceeds the
+// budget available to the priority.
+var takeScript = redis.NewScript(2, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local conf = redis.call('HMGET', KEYS[2], 'rate', 'burst')
+if conf[1] then
+	rate = tonumber(conf[1])
+	burst = tonumber(conf[2])
+end
+if rate < 0 then
+	return 0
+end
+
+local cost = tonumber(ARGV[3])
+local floor = burst * tonumber(ARGV[4])
+if cost > burst then
+	return -2
+end
+if floor > burst - cost then
+	floor = burst - cost
+end
+
+local now = tonumber(ARGV[5])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+	tokens = burst
+	last = now
+end
+if now > last then
+	tokens = math.min(burst, tokens + (now - last) * rate / 1000)
+	last = now
+end
+
+local wait = 0
+if tokens - cost >= floor then
+	tokens = tokens - cost
+elseif rate == 0 then
+	wait = 1000
+else
+	wait = math.ceil((cost + floor - tokens) * 1000 / rate)
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'last', tostring(last))
+redis.call('PEXPIRE', KEYS[1], tonumber(ARGV[6]))
+return wait
+`)
+
+func (c *Coordinator) takeRedis(k Key, p Priority, cost int, lc limitConfig) (time.Duration, error) {
+	conn := c.pool.Get()
+	defer conn.Close()
+
+	reserve := 0.0
+	if p < PriorityInteractive {
+		reserve = c.InteractiveReserve
+	}
+
+	wait, err := redis.Int64(takeScript.Do(conn,
+		c.bucketKey(k), c.limitKey(k.ExternalServiceID),
+		formatLimit(lc.limit), lc.burst, cost, reserve,
+		c.now().UnixNano()/int64(time.Millisecond),
+		bucketTTL.Milliseconds(),
+	))
+	if err != nil {
+		return 0, err
+	}
+	if wait == -2 {
+		return 0, errBurstExceeded{cost: cost}
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// floor returns how many tokens must be left in the bucket after a
+// reservation of the given priority and cost.
+func (c *Coordinator) floor(p Priority, cost, burst int) (float64, error) {
+	if cost > burst {
+		return 0, errBurstExceeded{cost: cost}
+	}
+	if p >= PriorityInteractive {
+		return 0, nil
+	}
+	return math.Min(float64(burst)*c.InteractiveReserve, float64(burst-cost)), nil
+}
+
+// warnFallback logs that we are falling back to local buckets, at most once a
+// minute.
+func (c *Coordinator) warnFallback(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now := c.now(); now.Sub(c.lastWarning) >= time.Minute {
+		c.lastWarning = now
+		log15.Warn("ratelimit: falling back to local rate limiting", "error", err)
+	}
+}
+
+func (c *Coordinator) bucketKey(k Key) string {
+	return "ratelimit:bucket:" + k.String()
+}
+
+func (c *Coordinator) limitKey(externalServiceID int64) string {
+	return "ratelimit:limit:" + strconv.FormatInt(externalServiceID, 10)
+}
+
+func (c *Coordinator) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+// bucketTTL is how long an idle bucket is kept in Redis. After this long it
+// would be full again anyway for any reasonable limit.
+const bucketTTL = 2 * time.Hour
+
+func formatLimit(l rate.Limit) string {
+	if l == rate.Inf {
+		return "-1"
+	}
+	return strconv.FormatFloat(float64(l), 'f', -1, 64)
+}
+
+type errBurstExceeded struct{ cost int }
+
+func (e errBurstExceeded) Error() string {
+	return fmt.Sprintf("ratelimit: cost %d exceeds the available budget", e.cost)
+}
+
+// bucket is the in-process fallback token bucket. It mirrors takeScript.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) take(now time.Time, limit rate.Limit, burst, cost int, floor float64) time.Duration {
+	if now.After(b.last) {
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*float64(limit))
+		b.last = now
+	}
+
+	if b.tokens-float64(cost) >= floor {
+		b.tokens -= float64(cost)
+		return 0
+	}
+	if limit == 0 {
+		return time.Second
+	}
+	missing := float64(cost) + floor - b.tokens
+	return time.Duration(math.Ceil(missing / float64(limit) * float64(time.Second)))
+}
+
+var (
+	waitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "src",
+		Subsystem: "ratelimit",
+		Name:      "coordinator_wait_seconds",
+		Help:      "Time spent waiting for code host API budget.",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 15, 30, 60, 300, 900},
+	}, []string{"priority"})
+
+	waiting = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "src",
+		Subsystem: "ratelimit",
+		Name:      "coordinator_waiting",
+		Help:      "Number of reservations currently waiting for code host API budget.",
+	}, []string{"priority"})
+
+	starvedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "ratelimit",
+		Name:      "coordinator_starved_total",
+		Help:      "Number of reservations that waited longer than the starvation threshold.",
+	}, []string{"priority"})
+
+	redisErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "ratelimit",
+		Name:      "coordinator_redis_errors_total",
+		Help:      "Number of reservations that fell back to local rate limiting because Redis failed.",
+	})
+)
@@ -0,0 +1,270 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+func TestBucket_Take(t *testing.T) {
+	now := time.Now()
+	b := &bucket{tokens: 10, last: now}
+
+	// 1 token per second, burst of 10.
+	if wait := b.take(now, 1, 10, 8, 0); wait != 0 {
+		t.Fatalf("first take: want no wait, got %s", wait)
+	}
+	if wait := b.take(now, 1, 10, 4, 0); wait != 2*time.Second {
+		t.Fatalf("second take: want 2s wait, got %s", wait)
+	}
+	if wait := b.take(now.Add(2*time.Second), 1, 10, 4, 0); wait != 0 {
+		t.Fatalf("take after refill: want no wait, got %s", wait)
+	}
+
+	// Refills never exceed the burst.
+	if wait := b.take(now.Add(time.Hour), 1, 10, 10, 0); wait != 0 {
+		t.Fatalf("take full burst: want no wait, got %s", wait)
+	}
+	if b.tokens != 0 {
+		t.Fatalf("want empty bucket, got %v tokens", b.tokens)
+	}
+}
+
+func TestBucket_TakeFloor(t *testing.T) {
+	now := time.Now()
+	b := &bucket{tokens: 10, last: now}
+
+	// Background work must leave 2 tokens behind.
+	if wait := b.take(now, 1, 10, 8, 2); wait != 0 {
+		t.Fatalf("want no wait, got %s", wait)
+	}
+	if wait := b.take(now, 1, 10, 1, 2); wait != time.Second {
+		t.Fatalf("want 1s wait for background, got %s", wait)
+	}
+	// Interactive work may use the reserve.
+	if wait := b.take(now, 1, 10, 2, 0); wait != 0 {
+		t.Fatalf("want no wait for interactive, got %s", wait)
+	}
+}
+
+func TestCoordinator_LocalFallback(t *testing.T) {
+	now := time.Now()
+	c := NewCoordinator(unreachablePool())
+	c.clock = func() time.Time { return now }
+	c.SetLimit(1, rate.Limit(1), 10)
+
+	k := Key{ExternalServiceID: 1, Token: "secret"}
+	ctx := context.Background()
+
+	for i := 0; i < 8; i++ {
+		if err := c.Reserve(ctx, k, PriorityBackground, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The remaining 2 tokens are reserved for interactive reservations.
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := c.Reserve(cctx, k, PriorityBackground, 1); err != context.DeadlineExceeded {
+		t.Fatalf("want background reservation to wait, got %v", err)
+	}
+	if err := c.Reserve(ctx, k, PriorityInteractive, 2); err != nil {
+		t.Fatalf("want interactive reservation to succeed, got %v", err)
+	}
+
+	// Other tokens have their own budget.
+	if err := c.Reserve(ctx, Key{ExternalServiceID: 1, Token: "other"}, PriorityBackground, 8); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCoordinator_Unlimited(t *testing.T) {
+	c := NewCoordinator(unreachablePool())
+
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		if err := c.Reserve(ctx, Key{ExternalServiceID: 2}, PriorityBackground, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c.SetLimit(2, rate.Inf, 0)
+	if err := c.Reserve(ctx, Key{ExternalServiceID: 2}, PriorityBackground, 1000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCoordinator_BurstExceeded(t *testing.T) {
+	c := NewCoordinator(unreachablePool())
+	c.SetLimit(3, rate.Limit(1), 10)
+
+	err := c.Reserve(context.Background(), Key{ExternalServiceID: 3}, PriorityInteractive, 11)
+	if _, ok := err.(errBurstExceeded); !ok {
+		t.Fatalf("want errBurstExceeded, got %v", err)
+	}
+}
+
+func TestCoordinator_SweepsIdleBuckets(t *testing.T) {
+	now := time.Now()
+	c := NewCoordinator(unreachablePool())
+	c.clock = func() time.Time { return now }
+	c.SetLimit(4, rate.Limit(1), 10)
+
+	ctx := context.Background()
+	for _, token := range []string{"a", "b"} {
+		if err := c.Reserve(ctx, Key{ExternalServiceID: 4, Token: token}, PriorityBackground, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only "b" is used again before a new token shows up after the TTL.
+	now = now.Add(bucketTTL / 2)
+	if err := c.Reserve(ctx, Key{ExternalServiceID: 4, Token: "b"}, PriorityBackground, 1); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(bucketTTL / 2)
+	if err := c.Reserve(ctx, Key{ExternalServiceID: 4, Token: "c"}, PriorityBackground, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.local[Key{ExternalServiceID: 4, Token: "a"}.String()]; ok {
+		t.Error("want idle bucket to be removed")
+	}
+	if len(c.local) != 2 {
+		t.Errorf("want 2 local buckets, got %d", len(c.local))
+	}
+}
+
+func TestCoordinator_InteractiveGetsAheadOfBackground(t *testing.T) {
+	c := NewCoordinator(nil)
+	// 100 tokens per second and a burst of 10, of which 2 are reserved for
+	// interactive reservations.
+	c.SetLimit(5, rate.Limit(100), 10)
+
+	k := Key{ExternalServiceID: 5, Token: "secret"}
+	ctx := context.Background()
+	if err := c.Reserve(ctx, k, PriorityInteractive, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both wait for the empty bucket to refill. The background reservation
+	// starts waiting first, but the interactive one only needs a single
+	// token instead of one on top of the reserve, so it is granted first.
+	done := make(chan Priority, 2)
+	reserve := func(p Priority) {
+		if err := c.Reserve(ctx, k, p, 1); err != nil {
+			t.Error(err)
+		}
+		done <- p
+	}
+	go reserve(PriorityBackground)
+	time.Sleep(time.Millisecond)
+	go reserve(PriorityInteractive)
+
+	if first := <-done; first != PriorityInteractive {
+		t.Errorf("want the interactive reservation to be granted first, got %s", first)
+	}
+	<-done
+}
+
+func TestCoordinator_Doer(t *testing.T) {
+	c := NewCoordinator(nil)
+	// A budget of 5 requests, which is never refilled. 1 is reserved for
+	// interactive requests.
+	c.SetLimit(6, 0, 5)
+	k := Key{ExternalServiceID: 6, Token: "secret"}
+
+	var requests int
+	cli := c.Doer(httpcli.DoerFunc(func(*http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}), func(context.Context) (Key, bool) { return k, true })
+
+	do := func(ctx context.Context) error {
+		req, err := http.NewRequest("GET", "https://example.com", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cli.Do(req.WithContext(ctx))
+		return err
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := do(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Requests are background unless their context says otherwise, and
+	// background requests can't use the interactive reserve.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := do(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("want background request to wait, got %v", err)
+	}
+	if err := do(WithPriority(context.Background(), PriorityInteractive)); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 5 {
+		t.Fatalf("want 5 requests, got %d", requests)
+	}
+}
+
+func TestCoordinator_Redis(t *testing.T) {
+	pool := &redis.Pool{
+		MaxIdle: 3,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", "127.0.0.1:6379")
+		},
+	}
+	conn := pool.Get()
+	_, err := conn.Do("PING")
+	conn.Close()
+	if err != nil {
+		t.Skip("could not connect to redis", err)
+	}
+
+	k := Key{ExternalServiceID: -1, Token: t.Name()}
+
+	// Two coordinators stand in for two services sharing the budget.
+	publisher := NewCoordinator(pool)
+	consumer := NewCoordinator(pool)
+	for _, c := range []*Coordinator{publisher, consumer} {
+		conn := pool.Get()
+		_, err := conn.Do("DEL", c.bucketKey(k), c.limitKey(k.ExternalServiceID))
+		conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	publisher.SetLimit(k.ExternalServiceID, rate.Limit(0.01), 10)
+
+	ctx := context.Background()
+	if err := publisher.Reserve(ctx, k, PriorityInteractive, 5); err != nil {
+		t.Fatal(err)
+	}
+	// The consumer never had SetLimit called but must see the published
+	// limit and the tokens taken by the publisher.
+	if err := consumer.Reserve(ctx, k, PriorityInteractive, 5); err != nil {
+		t.Fatal(err)
+	}
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := consumer.Reserve(cctx, k, PriorityInteractive, 1); err != context.DeadlineExceeded {
+		t.Fatalf("want reservation to wait, got %v", err)
+	}
+}
+
+func unreachablePool() *redis.Pool {
+	return &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return nil, errors.New("redis is unavailable")
+		},
+	}
+}
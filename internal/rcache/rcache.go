@@ -154,6 +154,35 @@ func (r *Cache) Set(key string, b []byte) {
 	}
 }
 
+// Increment atomically increments the integer value of key (which is 0 if key does not exist) and
+// returns the new value. If the cache has a TTL, key expires ttlSeconds after the last increment.
+func (r *Cache) Increment(key string) (int, error) {
+	c := pool.Get()
+	defer c.Close()
+
+	rkey := r.rkeyPrefix() + key
+	if r.ttlSeconds == 0 {
+		return redis.Int(c.Do("INCR", rkey))
+	}
+	if err := c.Send("MULTI"); err != nil {
+		return 0, err
+	}
+	if err := c.Send("INCR", rkey); err != nil {
+		return 0, err
+	}
+	if err := c.Send("EXPIRE", rkey, r.ttlSeconds); err != nil {
+		return 0, err
+	}
+	values, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 2 {
+		return 0, fmt.Errorf("rcache: unexpected INCR transaction result %v", values)
+	}
+	return redis.Int(values[0], nil)
+}
+
 // Delete implements httpcache.Cache.Delete
 func (r *Cache) Delete(key string) {
 	c := pool.Get()
@@ -147,3 +147,21 @@ func bytes(s ...string) [][]byte {
 	}
 	return t
 }
+
+func TestCache_Increment(t *testing.T) {
+	SetupForTest(t)
+
+	c := NewWithTTL("some_prefix", 60)
+	for want := 1; want <= 3; want++ {
+		got, err := c.Increment("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %d, want %d", got, want)
+		}
+	}
+	if b, ok := c.Get("a"); !ok || string(b) != "3" {
+		t.Errorf("got %q, want %q", b, "3")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -63,6 +64,21 @@ func GetByExtensionID(ctx context.Context, registry *url.URL, extensionID string
 	return getBy(ctx, registry, "registry.GetByExtensionID", "extension-id", extensionID)
 }
 
+// GetRelease gets the extension from the remote registry with the given extension ID, with the
+// manifest of the release with the given ID (instead of its latest release). If the remote registry
+// reports that the release is not found, the returned error implements errcode.NotFounder.
+func GetRelease(ctx context.Context, registry *url.URL, extensionID string, releaseID int64) (*Extension, error) {
+	return getBy(ctx, registry, "registry.GetRelease", "extension-id", path.Join(extensionID, "releases", strconv.FormatInt(releaseID, 10)))
+}
+
+// ListReleases lists the releases of the extension on the remote registry with the given extension
+// ID, newest first.
+func ListReleases(ctx context.Context, registry *url.URL, extensionID string) ([]*Release, error) {
+	var releases []*Release
+	err := httpGet(ctx, "registry.ListReleases", toURL(registry, path.Join("extensions", "extension-id", extensionID, "releases"), nil), &releases)
+	return releases, err
+}
+
 func getBy(ctx context.Context, registry *url.URL, op, field, value string) (*Extension, error) {
 	var x *Extension
 	if err := httpGet(ctx, op, toURL(registry, path.Join("extensions", field, value), nil), &x); err != nil {
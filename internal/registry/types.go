@@ -19,6 +19,10 @@ type Extension struct {
 	PublishedAt time.Time `json:"publishedAt"`
 	URL         string    `json:"url"`
 
+	// ReleaseID is the ID of the release whose manifest is Manifest on the registry that this
+	// extension was retrieved from, or 0 if it is unknown (such as on older registries).
+	ReleaseID int64 `json:"releaseID,omitempty"`
+
 	// RegistryURL is the URL of the remote registry that this extension was retrieved from. It is
 	// not set by package registry.
 	RegistryURL string `json:"-"`
@@ -29,3 +33,11 @@ type Publisher struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
 }
+
+// Release describes a release of an extension in the extension registry.
+type Release struct {
+	ID          int64     `json:"id"`
+	Version     *string   `json:"version"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Signed      bool      `json:"signed"`
+}
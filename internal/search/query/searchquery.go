@@ -26,6 +26,14 @@ const (
 	FieldPatternType        = "patterntype"
 	FieldContent            = "content"
 	FieldVisibility         = "visibility"
+	FieldOwner              = "owner"
+	FieldMultiline          = "multiline"
+
+	// For file content search only. They filter matching lines by the commit
+	// that last changed them (as reported by git blame).
+	FieldBlameAuthor = "blameauthor"
+	FieldBlameAfter  = "blameafter"
+	FieldBlameBefore = "blamebefore"
 
 	// For diff and commit search only:
 	FieldBefore    = "before"
@@ -34,6 +42,12 @@ const (
 	FieldCommitter = "committer"
 	FieldMessage   = "message"
 
+	// For diff and commit search only. They select the refs that are searched
+	// and how merge commits are handled.
+	FieldRefs        = "refs"
+	FieldMerges      = "merges"
+	FieldFirstParent = "firstparent"
+
 	// Temporary experimental fields:
 	FieldIndex     = "index"
 	FieldCount     = "count"  // Searches that specify `count:` will fetch at least that number of results, or the full result set
@@ -62,6 +76,12 @@ var (
 			FieldPatternType: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldContent:     {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldVisibility:  {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldOwner:       {Literal: types.StringType, Quoted: types.StringType, Negatable: true},
+			FieldMultiline:   {Literal: types.BoolType, Quoted: types.BoolType, Singular: true},
+
+			FieldBlameAuthor: regexpNegatableFieldType,
+			FieldBlameAfter:  {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldBlameBefore: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 
 			FieldRepoHasFile:        regexpNegatableFieldType,
 			FieldRepoHasCommitAfter: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
@@ -72,6 +92,10 @@ var (
 			FieldCommitter: regexpNegatableFieldType,
 			FieldMessage:   regexpNegatableFieldType,
 
+			FieldRefs:        {Literal: types.StringType, Quoted: types.StringType, Negatable: true},
+			FieldMerges:      {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldFirstParent: {Literal: types.BoolType, Quoted: types.BoolType, Singular: true},
+
 			// Experimental fields:
 			FieldIndex:     {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldCount:     {Literal: types.StringType, Quoted: types.StringType, Singular: true},
@@ -168,7 +168,9 @@ func valueToTypedValue(field, value string, quoted bool) []*types.Value {
 		return []*types.Value{{String: &value}}
 
 	case
-		FieldCase:
+		FieldCase,
+		FieldMultiline,
+		FieldFirstParent:
 		b, _ := parseBool(value)
 		return []*types.Value{{Bool: &b}}
 
@@ -190,7 +192,8 @@ func valueToTypedValue(field, value string, quoted bool) []*types.Value {
 		FieldLang, "l", "language",
 		FieldType,
 		FieldPatternType,
-		FieldContent:
+		FieldContent,
+		FieldOwner:
 		return []*types.Value{{String: &value}}
 
 	case FieldRepoHasFile:
@@ -205,9 +208,20 @@ func valueToTypedValue(field, value string, quoted bool) []*types.Value {
 	case
 		FieldAuthor,
 		FieldCommitter,
-		FieldMessage, "m", "msg":
+		FieldMessage, "m", "msg",
+		FieldBlameAuthor:
 		return []*types.Value{{Regexp: parseRegexpOrPanic(field, value)}}
 
+	case
+		FieldBlameAfter,
+		FieldBlameBefore:
+		return []*types.Value{{String: &value}}
+
+	case
+		FieldRefs,
+		FieldMerges:
+		return []*types.Value{{String: &value}}
+
 	case
 		FieldIndex,
 		FieldCount,
@@ -206,7 +206,9 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 		FieldDefault:
 		// Search patterns are not validated here, as it depends on the search type.
 	case
-		FieldCase:
+		FieldCase,
+		FieldMultiline,
+		FieldFirstParent:
 		return satisfies(isSingular, isBoolean, isNotNegated)
 	case
 		FieldRepo, "r":
@@ -237,6 +239,9 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 	case
 		FieldRepoHasCommitAfter:
 		return satisfies(isSingular, isNotNegated)
+	case
+		FieldOwner:
+		// Owners are matched literally, so any value is valid.
 	case
 		FieldBefore, "until",
 		FieldAfter, "since":
@@ -244,8 +249,19 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 	case
 		FieldAuthor,
 		FieldCommitter,
-		FieldMessage, "m", "msg":
+		FieldMessage, "m", "msg",
+		FieldBlameAuthor:
 		return satisfies(isValidRegexp)
+	case
+		FieldBlameAfter,
+		FieldBlameBefore:
+		return satisfies(isSingular, isNotNegated)
+	case
+		FieldRefs:
+		// Ref globs are validated when they are compiled.
+	case
+		FieldMerges:
+		return satisfies(isSingular, isNotNegated)
 	case
 		FieldIndex:
 		return satisfies(isSingular, isNotNegated)
@@ -114,8 +114,10 @@ type TextPatternInfo struct {
 	IsCaseSensitive bool
 	FileMatchLimit  int32
 
-	// We do not support IsMultiline
-	// IsMultiline     bool
+	// IsMultiline is whether matches are returned with their exact range,
+	// which may span multiple lines, rather than split into line matches.
+	IsMultiline bool
+
 	IncludePatterns []string
 	ExcludePattern  string
 
@@ -129,6 +131,19 @@ type TextPatternInfo struct {
 	PatternMatchesPath    bool
 
 	Languages []string
+
+	// Blame filters for content matches. See
+	// pkg/searcher/protocol.PatternInfo for their documentation.
+	BlameAuthorPatterns       []string
+	BlameExcludeAuthorPattern string
+	BlameAfter                string
+	BlameBefore               string
+}
+
+// HasBlameFilters returns whether content matches are filtered by blame
+// information. Only searcher supports these filters.
+func (p *TextPatternInfo) HasBlameFilters() bool {
+	return len(p.BlameAuthorPatterns) > 0 || p.BlameExcludeAuthorPattern != "" || p.BlameAfter != "" || p.BlameBefore != ""
 }
 
 func (p *TextPatternInfo) String() string {
@@ -161,6 +176,18 @@ func (p *TextPatternInfo) String() string {
 	for _, lang := range p.Languages {
 		args = append(args, fmt.Sprintf("lang:%s", lang))
 	}
+	for _, author := range p.BlameAuthorPatterns {
+		args = append(args, fmt.Sprintf("blameauthor:%q", author))
+	}
+	if p.BlameExcludeAuthorPattern != "" {
+		args = append(args, fmt.Sprintf("-blameauthor:%q", p.BlameExcludeAuthorPattern))
+	}
+	if p.BlameAfter != "" {
+		args = append(args, fmt.Sprintf("blameafter:%q", p.BlameAfter))
+	}
+	if p.BlameBefore != "" {
+		args = append(args, fmt.Sprintf("blamebefore:%q", p.BlameBefore))
+	}
 
 	for _, inc := range p.FilePatternsReposMustInclude {
 		args = append(args, fmt.Sprintf("repositoryPathPattern:%s", inc))
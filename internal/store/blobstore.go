@@ -0,0 +1,598 @@
+package store
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+	"golang.org/x/sys/unix"
+)
+
+// Blob is a regular file in the tree of a commit.
+type Blob struct {
+	// Path is the path of the file relative to the repository root.
+	Path string
+
+	// OID is the Git object ID of the file contents.
+	OID string
+
+	// Size is the size of the file in bytes.
+	Size int64
+}
+
+// maxFetchPaths is the maximum number of paths we ask for in a single call
+// to FetchTarPaths. The paths are sent in the URL of the gitserver request,
+// so we batch them to keep it to a reasonable length.
+const maxFetchPaths = 500
+
+// blobStore is an on disk store of the searchable contents of repositories
+// which stores each blob only once per repository, no matter how many
+// commits contain it.
+//
+// Every repository has its own directory. The contents of its blobs are
+// appended one after another to the file "pack", and the file "index" has a
+// line "oid offset length" for every blob in the pack. The files of a commit
+// are stored in a "*.view" file as a JSON list of SrcFile, whose offsets
+// point into the pack.
+//
+// A view is read into a ZipFile whose Data is the pack, so it can be
+// searched just like the zip archives created by PrepareZip. Since the pack
+// is only appended to, the data of a view never changes once it is written.
+//
+// We use an LRU to do cache eviction of whole repositories, based on the
+// modification times of their directories which we touch when opening a
+// view.
+type blobStore struct {
+	// dir is the directory containing a directory per repository.
+	dir string
+
+	mu sync.Mutex
+	// repoMus serializes changes to the directory of a repository.
+	repoMus map[string]*sync.Mutex
+}
+
+// repoDir returns the directory of repo.
+func (b *blobStore) repoDir(repo api.RepoName) string {
+	h := sha256.Sum256([]byte(repo))
+	return filepath.Join(b.dir, hex.EncodeToString(h[:]))
+}
+
+// repoMu returns the mutex guarding dir.
+func (b *blobStore) repoMu(dir string) *sync.Mutex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.repoMus == nil {
+		b.repoMus = make(map[string]*sync.Mutex)
+	}
+	mu, ok := b.repoMus[dir]
+	if !ok {
+		mu = new(sync.Mutex)
+		b.repoMus[dir] = mu
+	}
+	return mu
+}
+
+// evict removes the least recently used repositories from the store until
+// it is smaller than maxCacheSizeBytes. Views of removed repositories are
+// removed from zipCache first.
+func (b *blobStore) evict(maxCacheSizeBytes int64, zipCache *ZipCache) (stats diskcache.EvictStats, err error) {
+	list, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, errors.Wrapf(err, "failed to ReadDir %s", b.dir)
+	}
+
+	type repoDir struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var dirs []repoDir
+	for _, fi := range list {
+		if !fi.IsDir() {
+			continue
+		}
+		path := filepath.Join(b.dir, fi.Name())
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			log.Printf("failed to ReadDir %s: %s", path, err)
+			continue
+		}
+		d := repoDir{path: path, modTime: fi.ModTime()}
+		for _, f := range files {
+			d.size += f.Size()
+		}
+		dirs = append(dirs, d)
+		stats.CacheSize += d.size
+	}
+
+	// Remove the oldest first.
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].modTime.Before(dirs[j].modTime)
+	})
+	size := stats.CacheSize
+	for _, d := range dirs {
+		if size <= maxCacheSizeBytes {
+			break
+		}
+		if err := b.remove(d.path, zipCache); err != nil {
+			log.Printf("failed to remove %s: %s", d.path, err)
+			continue
+		}
+		stats.Evicted++
+		size -= d.size
+	}
+	return stats, nil
+}
+
+// remove removes the directory of a repository.
+func (b *blobStore) remove(dir string, zipCache *ZipCache) error {
+	mu := b.repoMu(dir)
+	mu.Lock()
+	defer mu.Unlock()
+
+	views, err := filepath.Glob(filepath.Join(dir, "*.view"))
+	if err != nil {
+		return err
+	}
+	for _, path := range views {
+		zipCache.delete(path)
+	}
+	return os.RemoveAll(dir)
+}
+
+// OpenView returns a searchable view of repo at commit. Unlike PrepareZip,
+// it only fetches the contents of files which are not already stored for
+// another commit of repo, so searching many commits of a repository does not
+// fetch and store near-duplicate archives. It requires ListBlobs and
+// FetchTarPaths to be set.
+//
+// A view has no zip archive on disk, so callers which need a path should use
+// PrepareZip instead. The returned file MUST be Closed when it is no longer
+// needed.
+func (s *Store) OpenView(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (zf *ZipFile, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "Store.openView")
+	ext.Component.Set(span, "store")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	// Ensure we have initialized
+	s.Start()
+
+	if s.ListBlobs == nil || s.FetchTarPaths == nil {
+		return nil, errors.New("OpenView requires ListBlobs and FetchTarPaths")
+	}
+	if len(commit) != 40 {
+		return nil, errors.Errorf("commit must be resolved (repo=%q, commit=%q)", repo.Name, commit)
+	}
+
+	largeFilePatterns := conf.Get().SearchLargeFiles
+
+	// As in PrepareZip, fetching can take a long time, so we open in the
+	// background to give it extra time.
+	type result struct {
+		zf  *ZipFile
+		err error
+	}
+	resC := make(chan result, 1)
+	go func() {
+		bgctx := opentracing.ContextWithSpan(context.Background(), opentracing.SpanFromContext(ctx))
+		bgctx, cancel := context.WithTimeout(bgctx, 2*time.Minute)
+		defer cancel()
+		zf, err := s.openView(bgctx, repo, commit, largeFilePatterns)
+		resC <- result{zf, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		// Release the view once it is opened.
+		go func() {
+			if res := <-resC; res.zf != nil {
+				res.zf.Close()
+			}
+		}()
+		return nil, ctx.Err()
+
+	case res := <-resC:
+		return res.zf, res.err
+	}
+}
+
+func (s *Store) openView(ctx context.Context, repo gitserver.Repo, commit api.CommitID, largeFilePatterns []string) (*ZipFile, error) {
+	dir := s.blobs.repoDir(repo.Name)
+	mu := s.blobs.repoMu(dir)
+	mu.Lock()
+	defer mu.Unlock()
+
+	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q", commit, largeFilePatterns)))
+	path := filepath.Join(dir, hex.EncodeToString(h[:])+".view")
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := s.writeView(ctx, dir, path, repo, commit, largeFilePatterns); err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch %s@%s", repo.Name, commit)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Update modified time. Modified time is used to decide which
+	// repositories to evict from the store.
+	now := time.Now()
+	if err := os.Chtimes(dir, now, now); err != nil {
+		log.Printf("failed to touch %s: %s", dir, err)
+	}
+
+	return s.ZipCache.get(path, readView)
+}
+
+// writeView writes the view of repo at commit to path, first fetching the
+// contents of the files which are missing from the pack in dir.
+func (s *Store) writeView(ctx context.Context, dir, path string, repo gitserver.Repo, commit api.CommitID, largeFilePatterns []string) error {
+	blobs, err := s.ListBlobs(ctx, repo, commit)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	index, err := readBlobIndex(filepath.Join(dir, "index"))
+	if err != nil {
+		return err
+	}
+
+	// We do not search the content of large files unless they are
+	// whitelisted, so we don't need to fetch them.
+	isLarge := func(blob Blob) bool {
+		return blob.Size > maxFileSize && !ignoreSizeMax(blob.Path, largeFilePatterns)
+	}
+
+	missing := map[string]string{} // path -> oid
+	for _, blob := range blobs {
+		if _, ok := index[blob.OID]; !ok && !isLarge(blob) {
+			missing[blob.Path] = blob.OID
+		}
+	}
+	if len(missing) > 0 {
+		if err := s.fetchBlobs(ctx, dir, repo, commit, missing, index); err != nil {
+			return err
+		}
+	}
+
+	files := make([]SrcFile, 0, len(blobs))
+	for _, blob := range blobs {
+		f := SrcFile{Name: blob.Path}
+		if !isLarge(blob) {
+			loc, ok := index[blob.OID]
+			if !ok {
+				return errors.Errorf("archive is missing %s", blob.Path)
+			}
+			f.Off, f.Len = loc.off, loc.len
+		}
+		files = append(files, f)
+	}
+
+	data, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".part"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fetchBlobs appends the contents of the missing blobs (keyed by path) to the
+// pack in dir and adds them to index. If the pack is empty, we fetch the whole
+// archive since the commit most likely shares nothing with the pack.
+func (s *Store) fetchBlobs(ctx context.Context, dir string, repo gitserver.Repo, commit api.CommitID, missing map[string]string, index blobIndex) (err error) {
+	fetchQueueSize.Inc()
+	ctx, releaseFetchLimiter, err := s.fetchLimiter.Acquire(ctx) // Acquire concurrent fetches semaphore
+	if err != nil {
+		return err // err will be a context error
+	}
+	fetchQueueSize.Dec()
+	defer releaseFetchLimiter() // Release concurrent fetches semaphore
+
+	fetching.Inc()
+	span, ctx := ot.StartSpanFromContext(ctx, "Store.fetchBlobs")
+	ext.Component.Set(span, "store")
+	span.SetTag("repo", repo.Name)
+	span.SetTag("commit", commit)
+	span.SetTag("missing", len(missing))
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.SetTag("err", err.Error())
+			fetchFailed.Inc()
+		}
+		fetching.Dec()
+		span.Finish()
+	}()
+
+	pack, err := os.OpenFile(filepath.Join(dir, "pack"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err1 := pack.Close(); err == nil {
+			err = err1
+		}
+	}()
+	fi, err := pack.Stat()
+	if err != nil {
+		return err
+	}
+
+	w := &blobWriter{
+		pack:    bufio.NewWriter(pack),
+		off:     fi.Size(),
+		missing: missing,
+		added:   blobIndex{},
+	}
+	if len(index) == 0 {
+		r, err := s.FetchTar(ctx, repo, commit)
+		if err != nil {
+			return err
+		}
+		if err := w.copyFrom(r); err != nil {
+			return err
+		}
+	} else {
+		paths := make([]string, 0, len(missing))
+		for path := range missing {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for len(paths) > 0 {
+			n := len(paths)
+			if n > maxFetchPaths {
+				n = maxFetchPaths
+			}
+			r, err := s.FetchTarPaths(ctx, repo, commit, paths[:n])
+			if err != nil {
+				return err
+			}
+			if err := w.copyFrom(r); err != nil {
+				return err
+			}
+			paths = paths[n:]
+		}
+	}
+	if err := w.pack.Flush(); err != nil {
+		return err
+	}
+	if err := pack.Sync(); err != nil {
+		return err
+	}
+
+	// Only index the blobs once they are safely in the pack.
+	if err := appendBlobIndex(filepath.Join(dir, "index"), w.added); err != nil {
+		return err
+	}
+	for oid, loc := range w.added {
+		index[oid] = loc
+	}
+	return nil
+}
+
+// blobWriter appends the searchable contents of blobs read from tar archives
+// to a pack.
+type blobWriter struct {
+	pack *bufio.Writer
+	// off is the offset in the pack at which the next blob is written.
+	off int64
+	// missing maps the paths we want to the oid of their blob.
+	missing map[string]string
+	// added are the blobs written so far.
+	added blobIndex
+}
+
+// copyFrom appends the missing blobs in the tar archive r to the pack. Like
+// copySearchable, it only stores the contents of files which are not binary.
+func (w *blobWriter) copyFrom(r io.ReadCloser) error {
+	defer r.Close()
+	tr := tar.NewReader(r)
+
+	// 32*1024 is the same size used by io.Copy
+	buf := make([]byte, 32*1024)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// See the comment in copySearchable.
+			if err == tar.ErrHeader {
+				return temporaryError{error: err}
+			}
+			return err
+		}
+
+		// We only care about files
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		oid, ok := w.missing[hdr.Name]
+		if !ok {
+			continue
+		}
+		if _, ok := w.added[oid]; ok {
+			continue
+		}
+
+		n, err := io.ReadFull(tr, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		// Heuristic: Assume file is binary if first 256 bytes contain a
+		// 0x00. We only search names of binary files, so store them as
+		// empty.
+		if bytes.IndexByte(buf[:n], 0x00) >= 0 {
+			w.added[oid] = blobLocation{}
+			continue
+		}
+
+		if _, err := w.pack.Write(buf[:n]); err != nil {
+			return err
+		}
+		rest, err := io.CopyBuffer(w.pack, tr, buf)
+		if err != nil {
+			return err
+		}
+		size := int64(n) + rest
+		if size > math.MaxInt32 {
+			return errors.Errorf("file %s has size > 2gb: %v", hdr.Name, size)
+		}
+		w.added[oid] = blobLocation{off: w.off, len: int32(size)}
+		w.off += size
+	}
+}
+
+// blobLocation is the location of the contents of a blob in a pack.
+type blobLocation struct {
+	off int64
+	len int32
+}
+
+// blobIndex maps the oid of a blob to its location in a pack.
+type blobIndex map[string]blobLocation
+
+// readBlobIndex reads the index at path. A missing index is empty.
+func readBlobIndex(path string) (blobIndex, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return blobIndex{}, nil
+		}
+		return nil, err
+	}
+	index := blobIndex{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			// Ignore lines which were not completely written.
+			continue
+		}
+		off, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		n, err := strconv.ParseInt(fields[2], 10, 32)
+		if err != nil {
+			continue
+		}
+		index[fields[0]] = blobLocation{off: off, len: int32(n)}
+	}
+	return index, nil
+}
+
+// appendBlobIndex appends the blobs in index to the index at path.
+func appendBlobIndex(path string, index blobIndex) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for oid, loc := range index {
+		fmt.Fprintf(w, "%s %d %d\n", oid, loc.off, loc.len)
+	}
+	err = w.Flush()
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// readView reads the view at path into a ZipFile whose Data is the pack of
+// its repository.
+func readView(path string) (*ZipFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	zf := &ZipFile{}
+	if err := json.Unmarshal(data, &zf.Files); err != nil {
+		return nil, errors.Wrapf(err, "invalid view %s", path)
+	}
+
+	// The pack does not exist if the view only has files we don't search.
+	var size int64
+	f, err := os.Open(filepath.Join(filepath.Dir(path), "pack"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if f != nil {
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		size = fi.Size()
+	}
+
+	for _, file := range zf.Files {
+		if file.Off+int64(file.Len) > size {
+			if f != nil {
+				f.Close()
+			}
+			return nil, errors.Errorf("view %s refers to data beyond its pack", path)
+		}
+		if int(file.Len) > zf.MaxLen {
+			zf.MaxLen = int(file.Len)
+		}
+	}
+
+	// We want sequential reads.
+	sort.Slice(zf.Files, func(i, j int) bool { return zf.Files[i].Off < zf.Files[j].Off })
+
+	if size == 0 {
+		// There is nothing to mmap.
+		if f != nil {
+			f.Close()
+		}
+		zf.Data = []byte{}
+		return zf, nil
+	}
+
+	zf.Data, err = unix.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	zf.f = f
+	return zf, nil
+}
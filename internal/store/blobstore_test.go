@@ -0,0 +1,186 @@
+package store
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+// blobTestStore returns a store whose repository "foo" has the given commits,
+// and a pointer to the paths requested by each fetch. A nil list of paths
+// records a fetch of the whole archive.
+func blobTestStore(t *testing.T, commits map[api.CommitID]map[string]string) (*Store, *[][]string, func()) {
+	s, cleanup := tmpStore(t)
+
+	oid := func(content string) string {
+		h := sha1.Sum([]byte(content))
+		return hex.EncodeToString(h[:])
+	}
+	tarOf := func(files map[string]string) io.ReadCloser {
+		buf := new(bytes.Buffer)
+		w := tar.NewWriter(buf)
+		for name, content := range files {
+			if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write([]byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return ioutil.NopCloser(bytes.NewReader(buf.Bytes()))
+	}
+
+	var fetches [][]string
+	s.ListBlobs = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) ([]Blob, error) {
+		var blobs []Blob
+		for name, content := range commits[commit] {
+			blobs = append(blobs, Blob{Path: name, OID: oid(content), Size: int64(len(content))})
+		}
+		return blobs, nil
+	}
+	s.FetchTar = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+		fetches = append(fetches, nil)
+		return tarOf(commits[commit]), nil
+	}
+	s.FetchTarPaths = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+		fetches = append(fetches, paths)
+		files := map[string]string{}
+		for _, path := range paths {
+			files[path] = commits[commit][path]
+		}
+		return tarOf(files), nil
+	}
+	return s, &fetches, cleanup
+}
+
+func viewContents(t *testing.T, zf *ZipFile) map[string]string {
+	t.Helper()
+	contents := map[string]string{}
+	for i := range zf.Files {
+		contents[zf.Files[i].Name] = string(zf.DataFor(&zf.Files[i]))
+	}
+	return contents
+}
+
+func TestOpenView(t *testing.T) {
+	large := strings.Repeat("a", maxFileSize+1)
+	commits := map[api.CommitID]map[string]string{
+		"1111111111111111111111111111111111111111": {
+			"a.go":      "package a",
+			"b.go":      "package b",
+			"copy.go":   "package b",
+			"large.txt": large,
+			"bin":       "\x00\x01",
+		},
+		"2222222222222222222222222222222222222222": {
+			"a.go":      "package a",
+			"b.go":      "package b // changed",
+			"c.go":      "package c",
+			"large.txt": large,
+		},
+	}
+	s, fetches, cleanup := blobTestStore(t, commits)
+	defer cleanup()
+
+	repo := gitserver.Repo{Name: "foo"}
+	zf, err := s.OpenView(context.Background(), repo, "1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"a.go":      "package a",
+		"b.go":      "package b",
+		"copy.go":   "package b",
+		"large.txt": "",
+		"bin":       "",
+	}
+	if got := viewContents(t, zf); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	zf.Close()
+
+	zf, err = s.OpenView(context.Background(), repo, "2222222222222222222222222222222222222222")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]string{
+		"a.go":      "package a",
+		"b.go":      "package b // changed",
+		"c.go":      "package c",
+		"large.txt": "",
+	}
+	if got := viewContents(t, zf); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	zf.Close()
+
+	// Opening a commit again uses the stored view.
+	zf, err = s.OpenView(context.Background(), repo, "1111111111111111111111111111111111111111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf.Close()
+
+	// The first commit fetches the whole archive, the second only the
+	// files which changed.
+	if len(*fetches) != 2 {
+		t.Fatalf("got %d fetches, want 2: %v", len(*fetches), *fetches)
+	}
+	if (*fetches)[0] != nil {
+		t.Errorf("got first fetch of paths %v, want whole archive", (*fetches)[0])
+	}
+	got := (*fetches)[1]
+	sort.Strings(got)
+	if want := []string{"b.go", "c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got second fetch of paths %v, want %v", got, want)
+	}
+}
+
+func TestOpenView_evict(t *testing.T) {
+	commit := api.CommitID("1111111111111111111111111111111111111111")
+	s, _, cleanup := blobTestStore(t, map[api.CommitID]map[string]string{
+		commit: {"a.go": "package a"},
+	})
+	defer cleanup()
+
+	zf, err := s.OpenView(context.Background(), gitserver.Repo{Name: "foo"}, commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf.Close() // don't block eviction of this view
+
+	if n := s.ZipCache.count(); n != 1 {
+		t.Fatalf("expected 1 item in cache, got %d", n)
+	}
+
+	stats, err := s.blobs.evict(0, &s.ZipCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Evicted != 1 {
+		t.Errorf("expected 1 repository to be evicted, got %d", stats.Evicted)
+	}
+
+	// Make sure the view is gone from the zip cache and disk.
+	if n := s.ZipCache.count(); n != 0 {
+		t.Fatalf("expected 0 items in cache, got %d", n)
+	}
+	if _, err := os.Stat(s.blobs.repoDir("foo")); !os.IsNotExist(err) {
+		t.Errorf("expected non-existence error, got %v", err)
+	}
+}
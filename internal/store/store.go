@@ -56,6 +56,14 @@ type Store struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only contains the
+	// given paths. It is used by OpenView.
+	FetchTarPaths func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error)
+
+	// ListBlobs returns the regular files in the tree of a repository at
+	// the specified commit. It is used by OpenView.
+	ListBlobs func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) ([]Blob, error)
+
 	// Path is the directory to store the cache
 	Path string
 
@@ -74,6 +82,9 @@ type Store struct {
 	// fetchLimiter limits concurrent calls to FetchTar.
 	fetchLimiter *mutablelimiter.Limiter
 
+	// blobs is the store of the views returned by OpenView.
+	blobs *blobStore
+
 	// ZipCache provides efficient access to repo zip files.
 	ZipCache ZipCache
 }
@@ -105,6 +116,9 @@ func (s *Store) Start() {
 			BackgroundTimeout: 2 * time.Minute,
 			BeforeEvict:       s.ZipCache.delete,
 		}
+		s.blobs = &blobStore{
+			dir: filepath.Join(s.Path, "blobs"),
+		}
 		_ = os.MkdirAll(s.Path, 0700)
 		metrics.MustRegisterDiskMonitor(s.Path)
 		go s.watchAndEvict()
@@ -356,8 +370,20 @@ func (s *Store) watchAndEvict() {
 			log.Printf("failed to Evict: %s", err)
 			continue
 		}
-		cacheSizeBytes.Set(float64(stats.CacheSize))
-		evictions.Add(float64(stats.Evicted))
+
+		// Views share the cache size with the zips, so they may use
+		// whatever the zips don't.
+		zipSize := stats.CacheSize
+		if zipSize > s.MaxCacheSizeBytes {
+			zipSize = s.MaxCacheSizeBytes
+		}
+		blobStats, err := s.blobs.evict(s.MaxCacheSizeBytes-zipSize, &s.ZipCache)
+		if err != nil {
+			log.Printf("failed to Evict: %s", err)
+			continue
+		}
+		cacheSizeBytes.Set(float64(stats.CacheSize + blobStats.CacheSize))
+		evictions.Add(float64(stats.Evicted + blobStats.Evicted))
 	}
 }
 
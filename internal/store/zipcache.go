@@ -44,6 +44,11 @@ func (c *ZipCache) shardFor(path string) *zipCacheShard {
 // Get returns a zipFile for the file on disk at path.
 // The file MUST be Closed when it is no longer needed.
 func (c *ZipCache) Get(path string) (*ZipFile, error) {
+	return c.get(path, readZipFile)
+}
+
+// get is like Get, but uses read to populate the cache on a miss.
+func (c *ZipCache) get(path string, read func(path string) (*ZipFile, error)) (*ZipFile, error) {
 	shard := c.shardFor(path)
 	shard.mu.Lock()
 	defer shard.mu.Unlock()
@@ -58,7 +63,7 @@ func (c *ZipCache) Get(path string) (*ZipFile, error) {
 	// Cache miss.
 	// Reading zip files is fast enough that we can populate the map in-band,
 	// which also conveniently provides free single-flighting.
-	zf, err := readZipFile(path)
+	zf, err := read(path)
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +102,36 @@ func (c *Client) Search(ctx context.Context, args search.SymbolsParameters) (res
 	return result, err
 }
 
+// GoTypes lists the Go types declared in a repository, with their method
+// sets.
+func (c *Client) GoTypes(ctx context.Context, args protocol.GoTypesArgs) (result *protocol.GoTypesResult, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "symbols.Client.GoTypes")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+	span.SetTag("Repo", string(args.Repo))
+	span.SetTag("CommitID", string(args.CommitID))
+
+	resp, err := c.httpPost(ctx, "gotypes", key{repo: args.Repo, commitID: args.CommitID}, args)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, errors.Errorf("Symbol.GoTypes http status %d for %+v: %s", resp.StatusCode, args, string(body))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
 func (c *Client) httpPost(ctx context.Context, method string, key key, payload interface{}) (resp *http.Response, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "symbols.Client.httpPost")
 	defer func() {
@@ -56,3 +56,47 @@ type Symbol struct {
 
 	FileLimited bool
 }
+
+// GoTypesArgs are the arguments to list the Go types declared in a repository.
+type GoTypesArgs struct {
+	// Repo is the name of the repository.
+	Repo api.RepoName `json:"repo"`
+
+	// CommitID is the commit to list the types of.
+	CommitID api.CommitID `json:"commitID"`
+}
+
+// GoTypesResult is the result of listing the Go types declared in a repository.
+type GoTypesResult struct {
+	Types []GoType
+}
+
+// GoType is a named type declared at the top level of a Go package, other
+// than in tests.
+type GoType struct {
+	Name string
+
+	// Package is the directory of the package that declares the type,
+	// relative to the repository root ("" for the root).
+	Package string
+
+	// Path, Line and Character are the position of the type name in its
+	// declaration. Line and Character are zero-based, and Character is
+	// measured in UTF-16 code units.
+	Path      string
+	Line      int
+	Character int
+
+	// Interface is whether the type is an interface type.
+	Interface bool
+
+	// Methods are the names of the methods declared by an interface type,
+	// or of the methods declared with a receiver of the type (or a pointer
+	// to it) for other types. Methods promoted from embedded fields are not
+	// included.
+	Methods []string
+
+	// Embeds are the interfaces embedded in an interface type, as written
+	// in its declaration (such as "Reader" or "io.Reader").
+	Embeds []string
+}
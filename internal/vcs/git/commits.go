@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 )
@@ -49,6 +50,11 @@ type CommitsOptions struct {
 	// gitserver doesn't already contain a clone of the repository or if the
 	// commit must be fetched from the remote.
 	RemoteURLFunc func() (string, error)
+
+	// Alternate, if set, is another repository whose objects are available to the command. This
+	// allows listing the commits of a repository that are not in another repository, such as the
+	// repository it was forked from.
+	Alternate *protocol.AlternateRepo
 }
 
 // logEntryPattern is the regexp pattern that matches entries in the output of the `git shortlog
@@ -148,7 +154,12 @@ func commitLog(ctx context.Context, repo gitserver.Repo, opt CommitsOptions) (co
 
 	cmd := gitserver.DefaultClient.Command("git", args...)
 	cmd.Repo = repo
-	cmd.EnsureRevision = opt.Range
+	if opt.Alternate == nil {
+		// With an alternate, the range refers to commits that don't exist in
+		// repo, so checking for them would trigger a fetch every time.
+		cmd.EnsureRevision = opt.Range
+	}
+	cmd.Alternate = opt.Alternate
 	retryer := &commandRetryer{
 		cmd:           cmd,
 		remoteURLFunc: opt.RemoteURLFunc,
@@ -332,6 +343,11 @@ func parseCommitsFromOnelineLog(data []byte) (commits []*onelineCommit, err erro
 			return commits, fmt.Errorf("parsing git oneline commit: short entry: %q", e)
 		}
 		sha1 := e[:40]
+		// With `git log -m`, merge commits are followed by the parent they
+		// are compared to, as in "(40-char SHA) (from (40-char SHA))".
+		if from := []byte(" (from "); bytes.HasPrefix(e[40:], from) && len(e) > 40+len(from)+41 {
+			e = append(e[:40:40], e[40+len(from)+41:]...)
+		}
 		i := bytes.Index(e, []byte{' '})
 		if i == -1 {
 			return commits, fmt.Errorf("parsing git oneline commit: no ' ': %q", e)
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// ParseDate returns the time denoted by date, which may be in any format understood by Git (e.g.,
+// "2 weeks ago", "yesterday" or "2020-01-31"). The date is parsed by Git in the given repository, so
+// that it is interpreted the same way as the dates given to Git commands such as `git log --after`.
+func ParseDate(ctx context.Context, repo gitserver.Repo, date string) (time.Time, error) {
+	if Mocks.ParseDate != nil {
+		return Mocks.ParseDate(date)
+	}
+
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: ParseDate")
+	span.SetTag("Date", date)
+	defer span.Finish()
+
+	// `git rev-parse --since=<date>` prints the date as `--max-age=<unix timestamp>`.
+	cmd := gitserver.DefaultClient.Command("git", "rev-parse", "--since="+date)
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return time.Time{}, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(out)), "--max-age=")
+	unix, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected output parsing date %q: %q", date, out)
+	}
+	return time.Unix(unix, 0).UTC(), nil
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	repo := MakeGitRepository(t, "GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit --allow-empty -m foo --author='a <a@a.com>' --date 2006-01-02T15:04:05Z")
+	ctx := context.Background()
+
+	got, err := ParseDate(ctx, repo, "2020-01-31T10:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	got, err = ParseDate(ctx, repo, "2 days ago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Now().Add(-48 * time.Hour); got.Sub(want) > time.Minute || want.Sub(got) > time.Minute {
+		t.Errorf("got %s, want about %s", got, want)
+	}
+}
@@ -74,8 +74,28 @@ type RawLogDiffSearchOptions struct {
 	// No arguments that affect the format of the output should be present in this
 	// slice.
 	Args []string
+
+	// RefGlobs are the ref globs to search, in addition to any revisions in Args. When set,
+	// the ContainingRefs of the results are computed.
+	RefGlobs []RefGlob
+
+	// Merges specifies whether merge commits are searched.
+	Merges MergesMode
+
+	// FirstParent makes the search only follow the first parent of merge commits. The diff
+	// of a merge commit is always against its first parent.
+	FirstParent bool
 }
 
+// MergesMode specifies whether RawLogDiffSearch searches merge commits.
+type MergesMode string
+
+const (
+	MergesExclude MergesMode = ""        // merge commits are not searched (the default)
+	MergesInclude MergesMode = "include" // merge commits are searched along with other commits
+	MergesOnly    MergesMode = "only"    // only merge commits are searched
+)
+
 // LogCommitSearchResult describes a matching diff from (Repository).RawLogDiffSearch.
 type LogCommitSearchResult struct {
 	Commit         Commit      // the commit whose diff was matched
@@ -89,6 +109,10 @@ type LogCommitSearchResult struct {
 	// `git log --help` documentation on the `--source` flag.)
 	SourceRefs []string
 
+	// ContainingRefs is the list of ref names matching the searched ref globs which
+	// contain this commit. It is only set if RawLogDiffSearchOptions.RefGlobs is set.
+	ContainingRefs []string
+
 	// Incomplete indicates that this result may represent a subset of the actual data.
 	// This can occur when the underlying command returns early due to an impending
 	// timeout.
@@ -163,6 +187,16 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 		}
 	}
 
+	refGlobs, err := CompileRefGlobs(opt.RefGlobs)
+	if err != nil {
+		return nil, false, err
+	}
+	switch opt.Merges {
+	case MergesExclude, MergesInclude, MergesOnly:
+	default:
+		return nil, false, fmt.Errorf("invalid Merges: %q", opt.Merges)
+	}
+
 	if opt.Query.IsCaseSensitive != opt.Paths.IsCaseSensitive {
 		// These options can't be set separately in `git log`, so fail.
 		return nil, false, fmt.Errorf("invalid options: Query.IsCaseSensitive != Paths.IsCaseSensitive")
@@ -238,6 +272,7 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 	// So we first must run `git log --oneline --source ...` (which does have that info),
 	// and then later we will go look up each commit's patch and other info.
 	onelineArgs := append([]string{}, args...)
+	onelineArgs = append(onelineArgs, refGlobArgs(opt.RefGlobs)...)
 	onelineArgs = append(onelineArgs,
 		"-z",
 		"--no-abbrev-commit",
@@ -245,8 +280,20 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 		"--no-color",
 		"--source",
 		"--no-patch",
-		"--no-merges",
 	)
+	switch opt.Merges {
+	case MergesExclude:
+		onelineArgs = append(onelineArgs, "--no-merges")
+	case MergesOnly:
+		onelineArgs = append(onelineArgs, "--merges")
+	}
+	if opt.Merges != MergesExclude {
+		// Without -m, the diffs of merge commits are not searched.
+		onelineArgs = append(onelineArgs, "-m")
+	}
+	if opt.FirstParent {
+		onelineArgs = append(onelineArgs, "--first-parent")
+	}
 	appendCommonQueryArgs(&onelineArgs)
 	appendCommonDashDashArgs(&onelineArgs)
 
@@ -314,14 +361,26 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 		return nil, false, err
 	}
 
-	// Now fetch the full commit data for all of the commits.
-	commitOIDs := make([]string, len(onelineCommits))
-	for i, c := range onelineCommits {
-		commitOIDs[i] = c.sha1
+	// Now fetch the full commit data for all of the commits. With -m, merge commits are listed
+	// once for every parent they are compared to.
+	commitOIDs := make([]string, 0, len(onelineCommits))
+	seen := make(map[string]bool, len(onelineCommits))
+	for _, c := range onelineCommits {
+		if !seen[c.sha1] {
+			seen[c.sha1] = true
+			commitOIDs = append(commitOIDs, c.sha1)
+		}
 	}
 	showArgs := append([]string{}, "show")
 	showArgs = append(showArgs, "--no-patch") // will be overridden if opt.FormatArgs has --patch
-	showArgs = append(showArgs, opt.FormatArgs...)
+	for _, arg := range opt.FormatArgs {
+		if arg == "--no-merges" && opt.Merges != MergesExclude {
+			// Show merge commits, compared to their first parent.
+			showArgs = append(showArgs, "-m", "--first-parent")
+			continue
+		}
+		showArgs = append(showArgs, arg)
+	}
 	showArgs = append(showArgs, opt.Args...)
 	showArgs = append(showArgs, commitOIDs...)
 	// Need --patch (TODO(sqs): or just --raw, which is smaller) if we are filtering by file paths,
@@ -432,9 +491,159 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 		results[len(results)-1].Incomplete = true
 	}
 
+	if len(opt.RefGlobs) > 0 {
+		if err := addContainingRefs(ctx, repo, results, refGlobs); err != nil {
+			if ctx.Err() != nil {
+				// Return partial data.
+				return results, false, err
+			}
+			return nil, false, err
+		}
+	}
+
 	return results, complete, nil
 }
 
+// refGlobArgs returns the `git log` args which select the refs matching globs. Because `git log
+// --exclude` only applies to the next `--glob`, the excludes following an include are passed
+// before it, so that the refs are the same as those matched by CompileRefGlobs.
+func refGlobArgs(globs []RefGlob) []string {
+	var args []string
+	for i, g := range globs {
+		if g.Include == "" {
+			continue
+		}
+		for _, g2 := range globs[i+1:] {
+			if g2.Exclude != "" {
+				args = append(args, "--exclude="+g2.Exclude)
+			}
+		}
+		args = append(args, "--glob="+g.Include)
+	}
+	return args
+}
+
+// addContainingRefs sets the ContainingRefs of the results to the refs matching globs which
+// contain their commit. Containment is computed once for all results, from the commit graph
+// between the tips of the matching refs and the results, instead of asking git for the refs
+// containing each commit.
+func addContainingRefs(ctx context.Context, repo gitserver.Repo, results []*LogCommitSearchResult, globs RefGlobs) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "for-each-ref", "--format=%(refname) %(objectname) %(*objectname)")
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return err
+	}
+	refsByTip := map[string][]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !globs.Match(fields[0]) {
+			continue
+		}
+		tip := fields[len(fields)-1] // the commit that an annotated tag points to
+		refsByTip[tip] = append(refsByTip[tip], fields[0])
+	}
+	if len(refsByTip) == 0 {
+		return nil
+	}
+
+	commits := make([]string, 0, len(results))
+	index := make(map[string]int, len(results))
+	for _, result := range results {
+		if _, ok := index[string(result.Commit.ID)]; !ok {
+			index[string(result.Commit.ID)] = len(commits)
+			commits = append(commits, string(result.Commit.ID))
+		}
+	}
+
+	// Ancestors of every result can't contain any of them, so the walk stops at the parents of
+	// their merge base.
+	args := []string{"rev-list", "--parents", "--topo-order", "--reverse"}
+	for tip := range refsByTip {
+		args = append(args, tip)
+	}
+	cmd = gitserver.DefaultClient.Command("git", append([]string{"merge-base", "--octopus"}, commits...)...)
+	cmd.Repo = repo
+	if out, err := cmd.Output(ctx); err == nil && len(bytes.TrimSpace(out)) > 0 {
+		args = append(args, "--not", string(bytes.TrimSpace(out))+"^@")
+	} else if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	cmd = gitserver.DefaultClient.Command("git", args...)
+	cmd.Repo = repo
+	out, err = cmd.Output(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Parents are listed before their children, so the results that each commit contains are
+	// known once its parents were seen.
+	contained := map[string]map[int]struct{}{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// A commit shares the set of its parent when it adds nothing to it, which is the case of
+		// most commits.
+		var set map[int]struct{}
+		shared := false
+		for _, parent := range fields[1:] {
+			ps := contained[parent]
+			if len(ps) == 0 {
+				continue
+			}
+			if set == nil {
+				set, shared = ps, true
+				continue
+			}
+			if shared {
+				set, shared = copyIntSet(set), false
+			}
+			for i := range ps {
+				set[i] = struct{}{}
+			}
+		}
+		if i, ok := index[fields[0]]; ok {
+			if set == nil {
+				set = map[int]struct{}{}
+			} else if shared {
+				set = copyIntSet(set)
+			}
+			set[i] = struct{}{}
+		}
+		if set != nil {
+			contained[fields[0]] = set
+		}
+	}
+
+	containingRefs := make([][]string, len(commits))
+	for tip, refs := range refsByTip {
+		for i := range contained[tip] {
+			containingRefs[i] = append(containingRefs[i], refs...)
+		}
+	}
+	for _, refs := range containingRefs {
+		sort.Strings(refs)
+	}
+	for _, result := range results {
+		result.ContainingRefs = containingRefs[index[string(result.Commit.ID)]]
+	}
+	return nil
+}
+
+func copyIntSet(s map[int]struct{}) map[int]struct{} {
+	c := make(map[int]struct{}, len(s))
+	for i := range s {
+		c[i] = struct{}{}
+	}
+	return c
+}
+
 // cachedRefResolver is a short-lived cache for ref resolutions. Only use it for the lifetime of a
 // single request and for a single repo.
 type refResolveCache struct {
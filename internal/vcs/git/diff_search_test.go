@@ -158,3 +158,117 @@ func TestRepository_RawLogDiffSearch_emptyCommit(t *testing.T) {
 		}
 	}
 }
+
+func TestRepository_RawLogDiffSearch_refGlobsAndMerges(t *testing.T) {
+	t.Parallel()
+
+	commit := func(msg, date string) string {
+		return "GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=" + date + " git commit -m " + msg + " --author='a <a@a.com>' --date " + date
+	}
+	repo := MakeGitRepository(t,
+		"echo root > f",
+		"git add f",
+		commit("root", "2006-01-02T15:04:05Z"),
+
+		"git checkout -b release/1",
+		"echo fix1 > f",
+		"git add f",
+		commit("fix1", "2006-01-02T15:04:06Z"),
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:06Z git tag -a -m v1 v1",
+
+		"git checkout -b release/2",
+		"echo fix2 > f",
+		"git add f",
+		commit("fix2", "2006-01-02T15:04:07Z"),
+
+		"git checkout master",
+		"GIT_AUTHOR_NAME=a GIT_AUTHOR_EMAIL=a@a.com GIT_AUTHOR_DATE=2006-01-02T15:04:08Z GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:08Z git merge --no-ff -m merge release/2",
+	)
+
+	type result struct {
+		Message        string
+		ContainingRefs []string
+	}
+	tests := []struct {
+		name string
+		opt  RawLogDiffSearchOptions
+		want []result
+	}{{
+		name: "ref glob",
+		opt: RawLogDiffSearchOptions{
+			RefGlobs: []RefGlob{{Include: "heads/release/*"}},
+		},
+		want: []result{
+			{Message: "fix2", ContainingRefs: []string{"refs/heads/release/2"}},
+			{Message: "fix1", ContainingRefs: []string{"refs/heads/release/1", "refs/heads/release/2"}},
+			{Message: "root", ContainingRefs: []string{"refs/heads/release/1", "refs/heads/release/2"}},
+		},
+	}, {
+		name: "excluded ref glob",
+		opt: RawLogDiffSearchOptions{
+			RefGlobs: []RefGlob{{Include: "heads/release/*"}, {Exclude: "refs/heads/release/2"}},
+		},
+		want: []result{
+			{Message: "fix1", ContainingRefs: []string{"refs/heads/release/1"}},
+			{Message: "root", ContainingRefs: []string{"refs/heads/release/1"}},
+		},
+	}, {
+		name: "ref globs with merges and annotated tags",
+		opt: RawLogDiffSearchOptions{
+			RefGlobs: []RefGlob{{Include: "heads/mast*"}, {Include: "heads/release/*"}, {Include: "tags/*"}},
+			Merges:   MergesInclude,
+		},
+		want: []result{
+			{Message: "merge", ContainingRefs: []string{"refs/heads/master"}},
+			{Message: "fix2", ContainingRefs: []string{"refs/heads/master", "refs/heads/release/2"}},
+			{Message: "fix1", ContainingRefs: []string{"refs/heads/master", "refs/heads/release/1", "refs/heads/release/2", "refs/tags/v1"}},
+			{Message: "root", ContainingRefs: []string{"refs/heads/master", "refs/heads/release/1", "refs/heads/release/2", "refs/tags/v1"}},
+		},
+	}, {
+		name: "merges",
+		opt: RawLogDiffSearchOptions{
+			Merges: MergesInclude,
+		},
+		want: []result{{Message: "merge"}, {Message: "fix2"}, {Message: "fix1"}, {Message: "root"}},
+	}, {
+		name: "only merges",
+		opt: RawLogDiffSearchOptions{
+			Merges: MergesOnly,
+		},
+		want: []result{{Message: "merge"}},
+	}, {
+		name: "first parent",
+		opt: RawLogDiffSearchOptions{
+			Merges:      MergesInclude,
+			FirstParent: true,
+		},
+		want: []result{{Message: "merge"}, {Message: "root"}},
+	}, {
+		name: "diff of merge",
+		opt: RawLogDiffSearchOptions{
+			Query:  TextSearchOptions{Pattern: "fix2"},
+			Diff:   true,
+			Merges: MergesInclude,
+		},
+		want: []result{{Message: "merge"}, {Message: "fix2"}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, complete, err := RawLogDiffSearch(ctx, repo, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !complete {
+				t.Fatal("!complete")
+			}
+			var got []result
+			for _, r := range results {
+				got = append(got, result{Message: r.Commit.Message, ContainingRefs: r.ContainingRefs})
+			}
+			if !cmp.Equal(test.want, got) {
+				t.Errorf("mismatch (-want +got):\n%s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/vcs"
 )
@@ -58,6 +59,13 @@ func ExecSafe(ctx context.Context, repo gitserver.Repo, params []string) (stdout
 // ExecReader executes an arbitrary `git` command (`git [args...]`) and returns a reader connected
 // to its stdout.
 func ExecReader(ctx context.Context, repo gitserver.Repo, args []string) (io.ReadCloser, error) {
+	return ExecReaderWithAlternate(ctx, repo, nil, args)
+}
+
+// ExecReaderWithAlternate is like ExecReader, but the objects of the alternate repository alt are
+// available to the command in addition to those of repo. This is used to compare a repository with
+// another repository, such as one of its forks.
+func ExecReaderWithAlternate(ctx context.Context, repo gitserver.Repo, alt *protocol.AlternateRepo, args []string) (io.ReadCloser, error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Git: ExecReader")
 	span.SetTag("args", args)
 	defer span.Finish()
@@ -67,6 +75,7 @@ func ExecReader(ctx context.Context, repo gitserver.Repo, args []string) (io.Rea
 	}
 	cmd := gitserver.DefaultClient.Command("git", args...)
 	cmd.Repo = repo
+	cmd.Alternate = alt
 	return gitserver.StdoutReader(ctx, cmd)
 }
 
@@ -129,6 +138,8 @@ var (
 		"--find-copies",
 		"--find-renames",
 		"--inter-hunk-context",
+		"--merges",
+		"--first-parent",
 	}
 )
 
@@ -3,6 +3,7 @@ package git
 import (
 	"io"
 	"os"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
 )
@@ -21,6 +22,8 @@ var Mocks, emptyMocks struct {
 	ResolveRevision  func(spec string, opt *ResolveRevisionOptions) (api.CommitID, error)
 	Stat             func(commit api.CommitID, name string) (os.FileInfo, error)
 	GetObject        func(objectName string) (OID, ObjectType, error)
+	BlameFile        func(path string, opt *BlameOptions) ([]*Hunk, error)
+	ParseDate        func(date string) (time.Time, error)
 }
 
 // ResetMocks clears the mock functions set on Mocks (so that subsequent tests don't inadvertently
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Language inventories of Git trees and blobs, keyed by object ID. Since
+-- objects are immutable, entries never need to be invalidated.
+CREATE TABLE IF NOT EXISTS inventory_objects (
+    oid text NOT NULL,
+    enhanced boolean NOT NULL,
+    inventory jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (oid, enhanced)
+);
+
+COMMIT;
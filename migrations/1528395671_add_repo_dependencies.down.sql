@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS repo_packages_commits;
+DROP TABLE IF EXISTS repo_packages;
+
+COMMIT;
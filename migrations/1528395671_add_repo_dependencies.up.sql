@@ -0,0 +1,22 @@
+BEGIN;
+
+-- The packages that repositories provide and depend on, as declared in the
+-- manifest files (go.mod, package.json, etc.) of their default branch.
+CREATE TABLE IF NOT EXISTS repo_packages (
+    repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+    kind text NOT NULL,
+    name text NOT NULL,
+    version text NOT NULL DEFAULT '',
+    dependency boolean NOT NULL
+);
+CREATE INDEX IF NOT EXISTS repo_packages_repo_id ON repo_packages(repo_id);
+CREATE INDEX IF NOT EXISTS repo_packages_kind_name ON repo_packages(kind, name);
+
+-- The commit at which the packages of each repository were last extracted.
+CREATE TABLE IF NOT EXISTS repo_packages_commits (
+    repo_id integer PRIMARY KEY REFERENCES repo(id) ON DELETE CASCADE,
+    commit_id text NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- An optional time after which an access token is no longer valid, and an
+-- optional list of the only repositories (by name) it may access.
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS expires_at timestamp with time zone;
+ALTER TABLE access_tokens ADD COLUMN IF NOT EXISTS repos text[] NOT NULL DEFAULT '{}';
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- The audit log records security-relevant administrative actions. Each entry
+-- stores the SHA-256 hash of the previous entry's hash and its own contents, so
+-- that modifying or deleting an entry breaks the chain. Entries are never
+-- updated, so actor_user_id intentionally has no foreign key constraint.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id bigserial NOT NULL PRIMARY KEY,
+    "timestamp" timestamp with time zone NOT NULL DEFAULT now(),
+    actor_user_id integer,
+    actor_ip text NOT NULL DEFAULT '',
+    action text NOT NULL,
+    target_type text NOT NULL,
+    target_id text NOT NULL DEFAULT '',
+    before text,
+    after text,
+    prev_hash bytea NOT NULL,
+    hash bytea NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_timestamp ON audit_log USING btree ("timestamp");
+CREATE INDEX IF NOT EXISTS audit_log_actor_user_id ON audit_log USING btree (actor_user_id);
+CREATE INDEX IF NOT EXISTS audit_log_action ON audit_log USING btree (action);
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TABLE IF EXISTS user_webauthn_credentials;
+
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_last_used_step;
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_recovery_codes;
+
+COMMIT;
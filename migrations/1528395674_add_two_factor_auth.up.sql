@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Two-factor authentication for builtin accounts. totp_secret is encrypted by
+-- the frontend, and two_factor_recovery_codes holds SHA-256 hashes of the
+-- unused recovery codes. totp_enabled_at is NULL while TOTP enrollment is
+-- pending confirmation.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret bytea;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at timestamp with time zone;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_used_step bigint NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_recovery_codes text[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS user_webauthn_credentials (
+    id serial NOT NULL PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    credential_id bytea NOT NULL,
+    public_key bytea NOT NULL,
+    sign_count bigint NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    last_used_at timestamp with time zone
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_webauthn_credentials_credential_id ON user_webauthn_credentials USING btree (credential_id);
+CREATE INDEX IF NOT EXISTS user_webauthn_credentials_user_id ON user_webauthn_credentials USING btree (user_id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE registry_extensions DROP COLUMN IF EXISTS pinned_release_id;
+ALTER TABLE registry_extension_releases DROP COLUMN IF EXISTS bundle_signature;
+ALTER TABLE registry_extensions DROP COLUMN IF EXISTS signing_public_key;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE registry_extensions ADD COLUMN signing_public_key text;
+ALTER TABLE registry_extension_releases ADD COLUMN bundle_signature text;
+ALTER TABLE registry_extensions ADD COLUMN pinned_release_id bigint REFERENCES registry_extension_releases(id) ON DELETE SET NULL;
+
+COMMIT;
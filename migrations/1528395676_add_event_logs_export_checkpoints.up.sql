@@ -0,0 +1,9 @@
+BEGIN;
+
+CREATE TABLE event_logs_export_checkpoints (
+    sink text PRIMARY KEY,
+    last_event_id bigint NOT NULL DEFAULT 0,
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS inventory_objects_last_used_at;
+ALTER TABLE inventory_objects DROP COLUMN IF EXISTS last_used_at;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Inventories that are no longer used (e.g. of trees which are not part of
+-- any recent commit) are deleted based on when they were last used.
+ALTER TABLE inventory_objects ADD COLUMN IF NOT EXISTS last_used_at timestamp with time zone NOT NULL DEFAULT now();
+CREATE INDEX IF NOT EXISTS inventory_objects_last_used_at ON inventory_objects(last_used_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Users deactivated by an identity provider (e.g., with the SCIM API) can't
+-- sign in, but their data is kept so that they can be reactivated.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at timestamp with time zone;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- The signing public key that a release's bundle signature was verified with
+-- when it was published. Releases are verified with this key (not the
+-- extension's current key), so rotating the extension's key doesn't change
+-- which key existing releases are verified with.
+ALTER TABLE registry_extension_releases ADD COLUMN IF NOT EXISTS signing_public_key text;
+
+UPDATE registry_extension_releases rer SET signing_public_key=x.signing_public_key
+FROM registry_extensions x
+WHERE x.id=rer.registry_extension_id AND rer.bundle_signature IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Daily snapshots of the peak number of user accounts and the number of
+-- licensed seats, kept for 400 days.
+CREATE TABLE IF NOT EXISTS seat_usage_snapshots (
+    date date PRIMARY KEY,
+    user_count integer NOT NULL,
+    licensed_seats integer NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE event_logs DROP COLUMN IF EXISTS created_at;
+
+COMMIT;
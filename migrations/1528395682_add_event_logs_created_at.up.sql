@@ -0,0 +1,10 @@
+BEGIN;
+
+-- When the event was inserted (set by the database). Usage event exports only
+-- include events inserted some time ago, so that an event whose insert commits
+-- after the insert of an event with a higher ID is not skipped. The column has
+-- no default for existing events, so that adding it doesn't rewrite the table.
+ALTER TABLE event_logs ADD COLUMN IF NOT EXISTS created_at timestamp with time zone;
+ALTER TABLE event_logs ALTER COLUMN created_at SET DEFAULT now();
+
+COMMIT;
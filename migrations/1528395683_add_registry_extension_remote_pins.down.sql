@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS registry_extension_remote_pins;
+
+COMMIT;
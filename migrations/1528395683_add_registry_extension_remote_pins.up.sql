@@ -0,0 +1,11 @@
+BEGIN;
+
+-- The releases that this site pins extensions from the remote registry to (by
+-- their extension ID and the ID of the release on the remote registry).
+CREATE TABLE IF NOT EXISTS registry_extension_remote_pins (
+    extension_id text PRIMARY KEY,
+    release_id bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+-- When the site first exceeded the licensed seats of each license. Rows are
+-- never removed, so that the seat overage grace period is only granted once
+-- per license.
+CREATE TABLE IF NOT EXISTS seat_overages (
+    license_signature text PRIMARY KEY,
+    overage_since timestamp with time zone NOT NULL
+);
+
+COMMIT;
@@ -106,6 +106,36 @@
 // 1528395668_campaign_description_nullable.up.sql (143B)
 // 1528395669_add_synced_at_to_perms_tables.down.sql (121B)
 // 1528395669_add_synced_at_to_perms_tables.up.sql (143B)
+// 1528395670_add_inventory_objects.down.sql (57B)
+// 1528395670_add_inventory_objects.up.sql (385B)
+// 1528395671_add_repo_dependencies.down.sql (97B)
+// 1528395671_add_repo_dependencies.up.sql (848B)
+// 1528395672_add_access_token_restrictions.down.sql (132B)
+// 1528395672_add_access_token_restrictions.up.sql (334B)
+// 1528395673_add_audit_log.down.sql (49B)
+// 1528395673_add_audit_log.up.sql (981B)
+// 1528395674_add_two_factor_auth.down.sql (304B)
+// 1528395674_add_two_factor_auth.up.sql (1.254kB)
+// 1528395675_add_registry_extension_signing.down.sql (244B)
+// 1528395675_add_registry_extension_signing.up.sql (290B)
+// 1528395676_add_event_logs_export_checkpoints.down.sql (69B)
+// 1528395676_add_event_logs_export_checkpoints.up.sql (200B)
+// 1528395677_add_search_history.down.sql (54B)
+// 1528395677_add_search_history.up.sql (500B)
+// 1528395678_add_inventory_objects_last_used_at.down.sql (136B)
+// 1528395678_add_inventory_objects_last_used_at.up.sql (373B)
+// 1528395679_add_users_deactivated_at.down.sql (73B)
+// 1528395679_add_users_deactivated_at.up.sql (246B)
+// 1528395680_add_registry_extension_release_signing_public_key.down.sql (99B)
+// 1528395680_add_registry_extension_release_signing_public_key.up.sql (568B)
+// 1528395681_add_seat_usage_snapshots.down.sql (60B)
+// 1528395681_add_seat_usage_snapshots.up.sql (277B)
+// 1528395682_add_event_logs_created_at.down.sql (74B)
+// 1528395682_add_event_logs_created_at.up.sql (487B)
+// 1528395683_add_registry_extension_remote_pins.down.sql (70B)
+// 1528395683_add_registry_extension_remote_pins.up.sql (362B)
+// 1528395684_add_seat_overages.down.sql (53B)
+// 1528395684_add_seat_overages.up.sql (325B)
 
 package migrations
 
@@ -2294,6 +2324,606 @@ func _1528395669_add_synced_at_to_perms_tablesUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __1528395670_add_inventory_objectsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x39\x00\xc6\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x69\x6e\x76\x65\x6e\x74\x6f\x72\x79\x5f\x6f\x62\x6a\x65\x63\x74\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xc3\xe4\x5c\xc9\x39\x00\x00\x00")
+
+func _1528395670_add_inventory_objectsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395670_add_inventory_objectsDownSql,
+		"1528395670_add_inventory_objects.down.sql",
+	)
+}
+
+func _1528395670_add_inventory_objectsDownSql() (*asset, error) {
+	bytes, err := _1528395670_add_inventory_objectsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395670_add_inventory_objects.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2, 0x7, 0x98, 0xac, 0x4a, 0x7f, 0x86, 0x96, 0xa, 0xe8, 0x58, 0x5a, 0x7e, 0x91, 0xad, 0x7f, 0x93, 0x48, 0xc, 0xad, 0xf, 0x8a, 0x8c, 0x9d, 0x39, 0x45, 0x28, 0x49, 0xe0, 0xba, 0x4d, 0x1d}}
+	return a, nil
+}
+
+var __1528395670_add_inventory_objectsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x5c\x8f\xc1\x6e\xc2\x40\x0c\x44\xef\xfb\x15\x73\x04\x09\xf8\x01\x4e\x01\x16\x14\x35\x40\x05\x41\x2a\x27\xb4\x9b\x75\x61\x69\x62\x57\x89\x81\xd2\xaf\xaf\x12\x54\x0e\x1c\x3d\x63\xcf\x3c\x4f\xec\x22\x5d\x8d\x8d\x19\x0e\x91\x39\x3e\x5e\xdc\x91\x10\xf9\x4a\xac\x52\x47\x6a\x20\x9f\x58\x44\x85\xd6\x44\x0d\x1c\x07\xf8\x52\x7c\x33\xc0\x17\xdd\x29\xc0\xdf\x21\xfe\x4c\x85\x22\x9d\x8d\xb0\x8d\x5c\x50\x9b\xf4\xd0\x1a\xb8\x9a\x10\xab\xea\xa2\xce\x97\x34\x00\xb1\x76\x99\x4c\x57\xaa\xc1\x44\x01\x2a\xf0\x5d\xa1\x2b\x63\x70\x4a\x61\x64\xa6\x1b\x9b\xe4\x16\x79\x32\xc9\x2c\xd2\x39\x56\xeb\x1c\xf6\x23\xdd\xe6\xdb\x27\xd8\xfd\xf0\xdf\xd0\x33\x00\x20\x31\x40\xe9\x47\xbb\xdd\xd5\x2e\xcb\x06\x9d\x4c\x7c\x72\x5c\xb4\x98\x22\x25\x39\x7e\xb1\x9f\x69\x38\x37\xc2\xfe\xc5\x2d\x6a\x6a\x79\x0e\x4e\xa1\xb1\xa2\x46\x5d\xf5\x8d\x5b\xd4\x53\x37\xe2\x57\x98\x9e\x17\x98\xd9\x79\xb2\xcb\x72\xb0\xdc\x7a\xfd\x47\xf9\xfb\x26\x5d\x26\x9b\x3d\xde\xec\x1e\x3d\x89\xa1\x7d\xff\x81\xd3\x37\xfd\xb1\x31\xd3\xf5\x72\x99\xe6\x63\xf3\x37\x00\x26\x99\x0e\xca\x81\x01\x00\x00")
+
+func _1528395670_add_inventory_objectsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395670_add_inventory_objectsUpSql,
+		"1528395670_add_inventory_objects.up.sql",
+	)
+}
+
+func _1528395670_add_inventory_objectsUpSql() (*asset, error) {
+	bytes, err := _1528395670_add_inventory_objectsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395670_add_inventory_objects.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x18, 0x16, 0x38, 0x67, 0x3d, 0x8b, 0x17, 0xb6, 0x67, 0x78, 0x11, 0xd8, 0xa7, 0x40, 0xd7, 0xac, 0xf5, 0xa9, 0xde, 0xf5, 0xc1, 0x95, 0x9f, 0x10, 0x88, 0x31, 0xca, 0xe8, 0x33, 0x8f, 0xff, 0x89}}
+	return a, nil
+}
+
+var __1528395671_add_repo_dependenciesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x61\x00\x9e\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x70\x6f\x5f\x70\x61\x63\x6b\x61\x67\x65\x73\x5f\x63\x6f\x6d\x6d\x69\x74\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x70\x6f\x5f\x70\x61\x63\x6b\x61\x67\x65\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x5e\xf8\x7c\x21\x61\x00\x00\x00")
+
+func _1528395671_add_repo_dependenciesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395671_add_repo_dependenciesDownSql,
+		"1528395671_add_repo_dependencies.down.sql",
+	)
+}
+
+func _1528395671_add_repo_dependenciesDownSql() (*asset, error) {
+	bytes, err := _1528395671_add_repo_dependenciesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395671_add_repo_dependencies.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdc, 0xb0, 0x58, 0x8, 0x35, 0xfc, 0x0, 0xec, 0x6f, 0xb, 0xde, 0xd9, 0xb2, 0x5b, 0xeb, 0x16, 0x58, 0xb8, 0x37, 0x3c, 0xe7, 0xf1, 0x44, 0x1b, 0xc2, 0x9a, 0x7, 0x43, 0x2, 0x37, 0x47, 0x82}}
+	return a, nil
+}
+
+var __1528395671_add_repo_dependenciesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\x41\x6f\x9b\x40\x10\x85\xef\xfc\x8a\x77\x0b\x48\x8e\xff\x80\x4f\x04\xaf\x2b\x54\x8c\x2b\x4c\xa4\xe4\x84\x36\xec\x60\xb6\x81\x5d\xb4\x3b\x89\x93\xfe\xfa\x0a\xa8\x9d\x46\x49\xab\xf8\xc8\xbc\x99\x8f\x79\xfb\xe6\x46\x7c\x4b\xf3\x55\x10\x5c\x5f\xa3\x6c\x09\x83\xac\x1f\xe5\x81\x3c\xb8\x95\x0c\x47\x83\xf5\x9a\xad\xd3\xe4\x31\x38\xfb\xac\x15\x41\x1a\x05\x45\x03\x19\x05\x6b\x16\x90\x1e\x8a\xea\x4e\x3a\x52\xd0\x06\xdc\xd2\xc8\xea\xa5\xd1\x0d\x79\x46\xa3\x3b\xf2\x08\x0f\x76\xd9\x5b\xb5\x38\xf1\x97\x3f\xfd\x38\x4b\x5c\x2f\x23\xd8\x66\x9c\xd2\x0e\x8a\x1a\xf9\xd4\x31\x1e\x9c\x34\x75\xbb\x0c\x92\x42\xc4\xa5\x40\x19\xdf\x64\x02\xe9\x06\xf9\xae\x84\xb8\x4b\xf7\xe5\x7e\xda\xac\x3a\x2f\x1b\x06\x00\xe6\x9a\x1e\xb7\x60\x3a\x90\x9b\xda\xf3\xdb\x2c\x43\x21\x36\xa2\x10\x79\x22\xe6\xb9\x50\xab\x08\xbb\x1c\x6b\x91\x89\x52\x20\x89\xf7\x49\xbc\x16\x8b\x89\xf1\xa8\x8d\x02\xd3\x0b\x9f\xa7\xe7\xba\x91\x3d\x7d\x56\x7f\x26\xe7\xb5\x35\xef\x25\xac\xc5\x26\xbe\xcd\x4a\x5c\x5d\xcd\x5d\xf3\x73\x91\xa9\x5f\xf1\x60\x6d\x47\xd2\x9c\x7b\x83\x68\x75\xf2\x99\xe6\x6b\x71\xf7\x3f\x9f\xd5\xc9\xe1\x2e\x7f\x2f\x84\x7f\x84\x4b\x58\xa3\xd3\x6a\xb2\xf5\x81\x36\x4a\x0b\x8c\x5a\xf4\x76\x19\xb5\xed\x7b\xcd\x90\x8c\x63\xab\xeb\x16\xfc\xf7\xb5\xd8\x06\x24\xeb\xf6\xed\x60\x5e\x71\x24\x47\xe8\xa4\x67\xd0\x0b\x3b\x59\x33\xa9\xaf\x27\x5a\xcd\x7f\xfb\x57\xb2\x3f\x8a\x74\x1b\x17\xf7\xf8\x2e\xee\x2f\x09\x77\x86\x8e\xa0\x4f\x92\x7c\x1a\x94\x64\x52\x95\x64\xb0\xee\xc9\xb3\xec\x07\x1c\x35\xb7\xd3\x27\x7e\x59\x43\x1f\x03\x36\xf6\x18\x46\x63\x84\x41\xb2\xdb\x6e\xd3\x72\x15\xfc\x1e\x00\x3c\xe1\x56\xf6\x50\x03\x00\x00")
+
+func _1528395671_add_repo_dependenciesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395671_add_repo_dependenciesUpSql,
+		"1528395671_add_repo_dependencies.up.sql",
+	)
+}
+
+func _1528395671_add_repo_dependenciesUpSql() (*asset, error) {
+	bytes, err := _1528395671_add_repo_dependenciesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395671_add_repo_dependencies.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x73, 0xe1, 0xac, 0xd5, 0x77, 0xb2, 0xf3, 0x2, 0xc0, 0xd9, 0xd8, 0x9a, 0xc0, 0xc9, 0x85, 0x2, 0xf, 0xec, 0xe2, 0x2f, 0xa3, 0x63, 0xcd, 0x28, 0x49, 0xdb, 0xf8, 0xbd, 0x30, 0x4f, 0xf9, 0x20}}
+	return a, nil
+}
+
+var __1528395672_add_access_token_restrictionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x48\x4c\x4e\x4e\x2d\x2e\x8e\x2f\xc9\xcf\x4e\xcd\x2b\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4a\x2d\xc8\x2f\xb6\x26\x59\x5b\x6a\x45\x41\x66\x51\x6a\x71\x7c\x62\x89\x35\x17\x97\xb3\xbf\xaf\xaf\x67\x88\x35\x17\x60\x00\x57\xa5\xe2\xc2\x84\x00\x00\x00")
+
+func _1528395672_add_access_token_restrictionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395672_add_access_token_restrictionsDownSql,
+		"1528395672_add_access_token_restrictions.down.sql",
+	)
+}
+
+func _1528395672_add_access_token_restrictionsDownSql() (*asset, error) {
+	bytes, err := _1528395672_add_access_token_restrictionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395672_add_access_token_restrictions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x27, 0x91, 0xe6, 0x24, 0xad, 0x4a, 0x59, 0x4c, 0xe0, 0x97, 0x7a, 0xab, 0xe0, 0x30, 0x16, 0xc0, 0xc9, 0x5c, 0xa3, 0xfb, 0xae, 0x37, 0x3d, 0x7a, 0xdb, 0x42, 0x83, 0x1, 0x17, 0x51, 0xb9, 0x4}}
+	return a, nil
+}
+
+var __1528395672_add_access_token_restrictionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x8e\xc1\x4a\xc3\x40\x14\x45\xf7\xf3\x15\x77\x57\x05\xeb\x0f\x64\x95\x36\xa9\x04\x26\x09\xd8\x09\x08\x22\x65\x4c\x5f\xcd\xc3\xe4\x4d\xc8\x3c\x6c\xa3\xf8\xef\x62\x28\x7e\x80\xcb\x7b\x17\xe7\x9c\x4d\xfe\x50\x54\x89\x31\xeb\x35\x52\x41\x18\x95\x83\xf8\x1e\xca\x03\xc1\x9f\x94\x26\x9c\x3b\x6e\x3b\x78\x81\x6f\x5b\x8a\x11\x1a\xde\x49\xc0\x11\x12\xd0\x07\x79\xa3\x09\x1f\xbe\xe7\xe3\x1d\xbc\x1c\xe1\xe5\x17\xf5\xc7\xe9\x39\x2a\xc2\x09\xda\x11\x82\xf4\x33\x26\x1a\x43\x64\x0d\x13\x53\xc4\xcd\xeb\x0c\xf1\x03\xdd\x82\x15\x83\x9f\xaf\x8a\x7b\x93\x5a\x97\x3f\xc2\xa5\x1b\x9b\x5f\xbf\xc3\xa2\x8d\x48\xb3\x0c\xdb\xda\x36\x65\x85\x62\x87\xaa\x76\xc8\x9f\x8a\xbd\xdb\x83\x2e\x23\x4f\x14\x0f\x5e\x97\xf8\xa8\x7e\x18\x71\x66\xed\x96\x89\xcf\x20\x94\xfc\x87\xbb\x04\x43\xe9\xa2\xcf\x2f\xcb\x5f\x35\xd6\x22\xcb\x77\x69\x63\x1d\x56\x5f\xdf\xab\xc4\x98\x6d\x5d\x96\x85\x4b\xcc\xcf\x00\xac\x57\xc7\x20\x4e\x01\x00\x00")
+
+func _1528395672_add_access_token_restrictionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395672_add_access_token_restrictionsUpSql,
+		"1528395672_add_access_token_restrictions.up.sql",
+	)
+}
+
+func _1528395672_add_access_token_restrictionsUpSql() (*asset, error) {
+	bytes, err := _1528395672_add_access_token_restrictionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395672_add_access_token_restrictions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe7, 0xec, 0xda, 0x15, 0x6e, 0x3d, 0x91, 0x57, 0xad, 0x15, 0xba, 0x5b, 0xf, 0xb6, 0xf, 0x8a, 0xd5, 0x13, 0xbc, 0xbe, 0x64, 0x84, 0xeb, 0xd2, 0xfe, 0x7d, 0xfc, 0xcb, 0x2b, 0x22, 0xe7, 0x5a}}
+	return a, nil
+}
+
+var __1528395673_add_audit_logDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x31\x00\xce\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x56\x27\xac\x48\x31\x00\x00\x00")
+
+func _1528395673_add_audit_logDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395673_add_audit_logDownSql,
+		"1528395673_add_audit_log.down.sql",
+	)
+}
+
+func _1528395673_add_audit_logDownSql() (*asset, error) {
+	bytes, err := _1528395673_add_audit_logDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395673_add_audit_log.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdb, 0x41, 0x92, 0x36, 0x57, 0xd0, 0xde, 0x25, 0x23, 0x41, 0x1f, 0xb0, 0x21, 0xfd, 0xd2, 0x3d, 0xe5, 0xb3, 0x6, 0xd4, 0x3d, 0x5b, 0xfc, 0x68, 0x36, 0xca, 0x66, 0x23, 0x7f, 0xa0, 0x5c, 0x53}}
+	return a, nil
+}
+
+var __1528395673_add_audit_logUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x92\xc1\x6e\xdb\x3c\x10\x84\xef\x7a\x8a\x41\x2e\xb6\x81\xd8\x87\x1f\xf8\x7b\xf1\xc9\x69\x94\x54\xa8\x2d\x17\xb6\x0c\x24\x27\x81\x16\xd7\xd2\x22\x32\x69\x90\x2b\xbb\xea\xd3\x17\x94\xda\xc4\x6e\x9a\xb4\xbd\x91\xdc\xe1\xb7\xcb\x19\xde\xc4\xf7\x49\x3a\x8d\xa2\xf1\x18\x59\x45\x50\x8d\x66\x41\x6d\x4b\x38\x2a\xac\xd3\x1e\x9e\x8a\xc6\xb1\xb4\x63\x47\x35\x1d\x95\x11\x28\xbd\x67\xc3\x5e\x9c\x12\x3e\x12\x54\x21\x6c\x8d\x9f\x20\x56\x45\x05\x32\xe2\xda\x40\xf3\x62\x1d\x79\x48\x45\x58\x7f\x9a\x8d\xff\xfb\xff\x03\x2a\xe5\x2b\xd8\x5d\x77\x76\x70\x74\x64\xdb\xf8\xfe\xc2\xc0\xf7\x45\x65\x34\x58\x3c\xec\xc9\xa0\xb0\x46\xc8\x88\xbf\x86\xb7\x01\x28\x95\x12\xec\xad\xe6\x5d\xcb\xa6\x84\x75\xd0\x54\x93\x84\xb5\x32\x3d\x06\x5b\x47\xea\xa9\x6f\x5a\x54\x8a\xcd\x04\xb1\x11\xc7\xe4\xa1\x1c\xc1\xd0\x91\x5c\x40\x35\x07\xad\x84\x74\x20\x87\xf1\xad\xcb\x1b\x4f\x2e\x67\x0d\xee\x7a\xb2\x35\xaa\xae\xdb\x30\x13\x8c\xc5\xce\x3a\xe2\xd2\xe0\x89\xda\x30\x55\x78\x39\x1b\x99\x44\x1f\x57\xf1\x2c\x8b\x91\xcd\x6e\xe6\x31\x92\x3b\xa4\xcb\x0c\xf1\x43\xb2\xce\xd6\xbd\x8f\x79\xf0\x71\x18\x01\x00\x6b\x6c\xb9\xf4\xe4\x58\xd5\x9d\x2e\xdd\xcc\xe7\xf8\xb2\x4a\x16\xb3\xd5\x23\x3e\xc7\x8f\xd7\x9d\xec\x4a\x78\x4f\x5e\xd4\xfe\x70\x85\xe7\x25\x4e\x2c\x55\xb7\xc5\x37\x6b\xe8\xe5\xfa\x6d\x7c\x37\xdb\xcc\x33\x18\x7b\x1a\x8e\x7a\xc0\xeb\xd7\x94\xe4\xce\x4b\x7c\x80\xd0\x57\x79\x0d\x19\x0c\x9e\x65\x6c\xcd\xa5\xa8\xaf\x88\x72\x25\x49\x2e\xed\x81\xde\x29\xb3\xfe\x43\x83\x2d\x05\x3f\x3b\xd1\x8f\x8e\x3b\x21\x77\xb6\x0f\x5f\x23\xef\xbe\xc3\xb6\x15\x52\xbf\xb4\xf9\x4d\x21\x1a\x4d\xa3\x9f\x61\x24\xe9\x6d\xfc\xf0\x56\x18\xf9\x8b\xa7\xcb\xf4\x2c\xa3\xcd\x3a\x49\xef\xb1\x15\x47\x84\xe1\x59\x06\xa3\xe9\xdf\x61\x2f\x5d\x7f\x1b\x7d\xa1\xfb\x07\x78\x08\xe4\x5d\x2a\x5b\xd3\x79\xb0\x5c\x2c\x92\x6c\x1a\x7d\x1f\x00\x9f\x71\x09\xfa\xd5\x03\x00\x00")
+
+func _1528395673_add_audit_logUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395673_add_audit_logUpSql,
+		"1528395673_add_audit_log.up.sql",
+	)
+}
+
+func _1528395673_add_audit_logUpSql() (*asset, error) {
+	bytes, err := _1528395673_add_audit_logUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395673_add_audit_log.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2f, 0xf6, 0x55, 0x30, 0x9, 0x2a, 0x48, 0x87, 0x4b, 0x6a, 0x58, 0xdb, 0x9b, 0xa9, 0x4e, 0x35, 0x5f, 0x66, 0x92, 0x17, 0x28, 0x29, 0x4f, 0xd0, 0xb3, 0x82, 0x15, 0x32, 0xe7, 0xee, 0xc, 0x3f}}
+	return a, nil
+}
+
+var __1528395674_add_two_factor_authDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\xcf\x4d\xaa\x83\x30\x14\xc5\xf1\x79\x56\x91\x7d\x64\xa4\xbe\xbc\x12\xf0\xa3\x68\x0a\x9d\x5d\x62\x72\x4a\x0b\x62\x24\xb9\x56\xba\xfb\x62\xe9\xa0\x53\xe7\xe7\xf7\x87\x53\xea\x93\x69\x95\x10\x7f\x7d\x77\x96\xb6\x28\x6b\x2d\xcd\xbf\xd4\x57\x33\xd8\x41\xae\x19\x89\x36\x8c\x6e\xe5\xfb\x4c\x3e\x21\x60\xe6\x87\x9b\xb2\x12\xa2\xa8\xad\xee\xbf\x62\xdf\x65\xf9\x49\x54\x5d\x7d\x69\xda\x9f\x06\x47\x5e\x28\xc3\x27\xb0\x3a\x86\x30\xbb\x71\x42\x20\x77\x14\x4e\x2e\x33\xad\x19\x81\x32\x63\x39\x80\xb7\x48\x37\xe7\x39\x26\x4a\xf0\xf1\x89\xf4\x22\x1f\x03\xf6\xbb\x55\xd7\x34\xc6\x2a\xf1\x1e\x00\x37\x3e\xc0\x3c\x30\x01\x00\x00")
+
+func _1528395674_add_two_factor_authDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395674_add_two_factor_authDownSql,
+		"1528395674_add_two_factor_auth.down.sql",
+	)
+}
+
+func _1528395674_add_two_factor_authDownSql() (*asset, error) {
+	bytes, err := _1528395674_add_two_factor_authDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395674_add_two_factor_auth.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa2, 0x6f, 0x24, 0xa7, 0x21, 0xa7, 0x30, 0xd3, 0x13, 0xfd, 0xe3, 0x92, 0x83, 0x2f, 0x2e, 0x25, 0x67, 0xcf, 0x7f, 0xbf, 0xa2, 0x95, 0x55, 0xc, 0x84, 0x61, 0xab, 0x82, 0xb9, 0x65, 0x1a, 0x1c}}
+	return a, nil
+}
+
+var __1528395674_add_two_factor_authUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x93\x51\x6f\xda\x30\x14\x85\xdf\xf3\x2b\xce\x5b\x41\x2a\xd5\x34\x69\x7b\xe1\x29\x05\xb7\x8b\x16\x42\x07\x41\x6a\x35\x4d\x96\x13\x5f\x88\xb5\x60\x23\xfb\x66\x8c\x4d\xfb\xef\x53\x42\x5b\xda\x75\x45\xe5\x2d\x89\x7d\xbe\x73\xef\x3d\x37\x97\xe2\x3a\xc9\x86\x51\x34\x18\x20\xdf\xba\xc1\x52\x95\xec\x3c\x54\xc3\x15\x59\x36\xa5\x62\xe3\x2c\x96\xce\xa3\x68\x4c\xcd\xc6\x42\x95\xa5\x6b\x2c\x87\x0b\xb0\xe3\x8d\x0c\x54\x7a\x62\x98\x00\xb2\xa5\xdf\x6d\x98\x34\x8a\x5d\x8b\xe3\x8a\xb0\xf4\xce\x32\x59\x7d\x0e\x65\x35\x78\xeb\xe4\xde\x40\x7a\x2a\xdd\x0f\xf2\x3b\x59\x3a\x4d\x01\x95\xab\x75\xc0\xfc\x53\x3c\x78\xff\xe1\x23\x2a\x15\x2a\x0a\x70\x4b\x70\x45\x2d\xaa\xb1\x4d\x20\x8d\x07\x11\x3a\xd1\x7d\x01\x64\x55\x51\x93\x96\xaa\x2b\x22\x5b\xa4\x29\xb6\x95\xa9\x09\xf9\x34\xbf\x01\x59\xef\xea\x7a\x4d\xb6\x3d\x6d\x51\x1b\xb2\xda\xd8\x15\x4a\x67\x97\xc6\xaf\xbb\xfe\x2e\xa2\x38\xcd\xc5\x0c\x79\x7c\x99\x0a\x34\x81\x7c\x40\x3c\x1e\x63\x34\x4d\x17\x93\x0c\xc9\x15\xb2\x69\x0e\x71\x9b\xcc\xf3\xf9\xb3\xae\x8b\x1d\x93\x1a\x9e\xac\x7e\x52\x32\x9b\x35\x05\x56\xeb\x0d\xb6\x86\xab\xee\x15\xbf\x9c\xa5\xd3\xa1\xb5\x0a\x2c\xdb\x31\xc9\xc0\xb4\x41\x61\x56\xc6\x72\x67\xdd\xcd\x64\x2c\xae\xe2\x45\x9a\xe3\xdd\x69\xe4\x57\x13\x63\xfa\xc9\x5f\xbf\xbd\xe4\x9f\xfd\xfe\x73\x36\x8c\xa2\xd1\x4c\xc4\xb9\xb8\x37\x79\xce\x6c\x2d\xe5\x96\x8a\x76\xc7\xac\x2c\x3d\xe9\x76\xd3\x54\x1d\xd0\x8b\x00\xc0\x68\x04\xf2\x46\xd5\x07\xf8\xcd\x2c\x99\xc4\xb3\x3b\x7c\x16\x77\xe7\xdd\x9d\x8e\x61\x34\x8c\x65\x5a\x91\x3f\xdc\x9c\x89\x2b\x31\x13\xd9\x48\xec\x7d\x42\xcf\xe8\x3e\xa6\x19\xc6\x22\x15\xb9\xc0\x28\x9e\x8f\xe2\xb1\xd8\x43\xac\x5a\x53\xd7\xc7\xa3\x7c\xff\xfd\x50\x92\x34\xed\x32\x33\xa9\x7f\x6e\x6c\x9a\xa2\x36\xa5\xfc\x4e\xbb\xff\x1e\x07\xb3\xb2\xb2\xfb\x4b\x5e\x8f\xe1\xd1\x4a\xf1\xf1\x4d\x78\xa9\xb5\x6e\xdb\xeb\xef\xf5\x87\xd0\x8f\x10\xa2\xfe\x21\x90\x45\x96\x7c\x59\x08\x24\xd9\x58\xdc\xbe\x35\x97\x27\xcf\xed\x40\xa6\xd9\x91\x08\x17\xf3\x24\xbb\x46\xc1\x9e\x08\xbd\x67\xba\xfe\xf0\xa1\x86\xd3\xcc\x1f\xa2\x7e\xbb\xed\xbd\xa2\xeb\x7a\x3a\x99\x24\xf9\x30\xfa\x3b\x00\x37\xbb\xd7\x6d\xe6\x04\x00\x00")
+
+func _1528395674_add_two_factor_authUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395674_add_two_factor_authUpSql,
+		"1528395674_add_two_factor_auth.up.sql",
+	)
+}
+
+func _1528395674_add_two_factor_authUpSql() (*asset, error) {
+	bytes, err := _1528395674_add_two_factor_authUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395674_add_two_factor_auth.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8c, 0xce, 0x52, 0x75, 0x0, 0xa3, 0x52, 0x19, 0x6, 0xf6, 0x80, 0x72, 0x27, 0xcc, 0xd, 0x5, 0xeb, 0x2f, 0x60, 0xfa, 0x6b, 0x72, 0x12, 0x13, 0xe6, 0x7d, 0xdb, 0x7c, 0x75, 0x4d, 0x60, 0xbb}}
+	return a, nil
+}
+
+var __1528395675_add_registry_extension_signingDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\xce\x4b\x0a\xc2\x30\x10\x80\xe1\x7d\x4e\x31\xf7\xc8\xaa\xad\x51\x02\x7d\x48\x1b\xc1\xdd\xd0\xda\x21\x0c\x86\xb1\xe4\x01\xf6\xf6\xe2\xc2\xa5\x08\xee\x7f\x3e\xfe\xda\x9c\x6c\xaf\x95\xaa\x5a\x67\x46\x70\x55\xdd\x1a\x88\xe4\x39\xe5\xb8\x23\x3d\x33\x49\xe2\x87\x24\x38\x8c\xc3\x19\x9a\xa1\xbd\x74\x3d\xd8\x23\x98\xab\x9d\xdc\x04\x1b\x8b\xd0\x8a\x91\x02\xcd\x89\x90\x57\xfd\x03\xfa\xa4\xdf\xc0\xa5\xc8\x1a\x08\x13\x7b\x99\x73\x89\xa4\xff\x1c\x7b\x03\x2c\x1e\xb7\xb2\x04\xbe\xe1\x9d\x76\xad\x54\x33\x74\x9d\x75\x5a\xbd\x06\x00\x3d\xc1\xa7\x79\xf4\x00\x00\x00")
+
+func _1528395675_add_registry_extension_signingDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395675_add_registry_extension_signingDownSql,
+		"1528395675_add_registry_extension_signing.down.sql",
+	)
+}
+
+func _1528395675_add_registry_extension_signingDownSql() (*asset, error) {
+	bytes, err := _1528395675_add_registry_extension_signingDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395675_add_registry_extension_signing.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf9, 0x27, 0x56, 0xfd, 0x66, 0x89, 0x38, 0x2d, 0x80, 0xe9, 0xf2, 0x5b, 0xd9, 0xbe, 0xc8, 0x1d, 0x81, 0xee, 0xc4, 0x90, 0x12, 0x70, 0xa3, 0x5e, 0x73, 0xfd, 0xea, 0x1b, 0x3e, 0xc7, 0x4, 0xc9}}
+	return a, nil
+}
+
+var __1528395675_add_registry_extension_signingUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xce\x4d\x6a\x85\x30\x10\x00\xe0\x7d\x4e\x31\xcb\xf6\x0c\x59\xf9\x33\x2d\x42\x8c\xa0\x71\x1d\xb4\x19\xc2\x50\x99\x4a\x12\x41\x6f\x5f\xba\xe8\xc2\xcd\x7b\xef\x02\x1f\x5f\x8d\x9f\x9d\xd5\x4a\x55\xc6\xe1\x08\xae\xaa\x0d\x42\xa2\xc8\xb9\xa4\xcb\xd3\x59\x48\x32\xff\x48\x86\xaa\x6d\xa1\x19\xcc\xdc\x5b\xc8\x1c\x85\x25\xfa\xfd\x58\x37\xfe\xf2\xdf\x74\x41\xa1\xb3\xe8\x27\x86\x4f\xb4\xd1\x92\xe9\x66\xad\x87\x84\x8d\xfc\x1f\xb9\x94\x23\xd1\x4b\xd2\x4d\xd8\x59\x84\xc2\x3f\xee\x39\xc0\xca\x91\xa5\xc0\x88\x1f\x38\xa2\x6d\x70\x7a\x94\x79\xe3\xf0\x0e\x83\x85\x16\x0d\x3a\x84\x09\x1d\xd8\xd9\x18\xad\x54\x33\xf4\x7d\xe7\xb4\xfa\x1d\x00\x19\x1f\x24\xc6\x22\x01\x00\x00")
+
+func _1528395675_add_registry_extension_signingUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395675_add_registry_extension_signingUpSql,
+		"1528395675_add_registry_extension_signing.up.sql",
+	)
+}
+
+func _1528395675_add_registry_extension_signingUpSql() (*asset, error) {
+	bytes, err := _1528395675_add_registry_extension_signingUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395675_add_registry_extension_signing.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x43, 0x48, 0x1a, 0x83, 0x14, 0xc2, 0x99, 0x17, 0xdd, 0x33, 0x7b, 0x8c, 0xec, 0x8c, 0x80, 0xc9, 0x34, 0xd7, 0x45, 0x45, 0x6b, 0x28, 0xf6, 0x74, 0xb9, 0x23, 0x6c, 0xf3, 0x82, 0xdc, 0x75, 0x7a}}
+	return a, nil
+}
+
+var __1528395676_add_event_logs_export_checkpointsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x45\x00\xba\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x65\x76\x65\x6e\x74\x5f\x6c\x6f\x67\x73\x5f\x65\x78\x70\x6f\x72\x74\x5f\x63\x68\x65\x63\x6b\x70\x6f\x69\x6e\x74\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x53\x7e\x95\xac\x45\x00\x00\x00")
+
+func _1528395676_add_event_logs_export_checkpointsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395676_add_event_logs_export_checkpointsDownSql,
+		"1528395676_add_event_logs_export_checkpoints.down.sql",
+	)
+}
+
+func _1528395676_add_event_logs_export_checkpointsDownSql() (*asset, error) {
+	bytes, err := _1528395676_add_event_logs_export_checkpointsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395676_add_event_logs_export_checkpoints.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x80, 0x21, 0x69, 0x6e, 0x1a, 0x6e, 0xac, 0x60, 0x63, 0xfb, 0xd, 0x5e, 0x79, 0x29, 0xde, 0x78, 0x54, 0x9e, 0xc4, 0xa6, 0x7, 0xa4, 0xa7, 0x2f, 0x75, 0x76, 0x5e, 0xe9, 0x76, 0x28, 0xd9, 0xa6}}
+	return a, nil
+}
+
+var __1528395676_add_event_logs_export_checkpointsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8c\xb1\x6e\x84\x30\x14\x04\x7b\x7f\xc5\x96\x77\x52\x8a\xf4\x54\xbe\x8b\x13\xa1\x18\x2e\x42\xa6\xa0\xb2\x1c\x78\x02\x0b\xb0\xad\xf8\x25\xa0\x7c\x7d\x24\x28\x53\xae\x76\x66\x6e\xea\xad\xac\x0b\x21\xee\x8d\x92\x46\xc1\xc8\x9b\x56\xa0\x1f\x0a\x6c\x97\x38\x66\x4b\x7b\x8a\x5f\x6c\xfb\x89\xfa\x39\x45\x1f\x38\xe3\x22\x00\x20\xfb\x30\x83\x69\x67\x7c\x34\x65\x25\x9b\x0e\xef\xaa\x7b\x3a\xae\xc5\x65\xb6\x67\xc3\x0f\xf8\xf4\xa3\x0f\x8c\xfa\x61\x50\xb7\x5a\xe3\x45\xbd\xca\x56\x1b\x3c\x9f\xf0\x77\x1a\x1c\xd3\x60\x1d\x83\xfd\x4a\x99\xdd\x9a\xb0\x79\x9e\x8e\x89\xdf\x18\xe8\xbf\x1b\xe2\x76\xb9\x8a\x6b\x21\xc4\xfd\x51\x55\xa5\x29\xc4\xdf\x00\x4b\x54\x61\x6e\xc8\x00\x00\x00")
+
+func _1528395676_add_event_logs_export_checkpointsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395676_add_event_logs_export_checkpointsUpSql,
+		"1528395676_add_event_logs_export_checkpoints.up.sql",
+	)
+}
+
+func _1528395676_add_event_logs_export_checkpointsUpSql() (*asset, error) {
+	bytes, err := _1528395676_add_event_logs_export_checkpointsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395676_add_event_logs_export_checkpoints.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xb8, 0x51, 0x2b, 0xee, 0x1c, 0x75, 0xe4, 0x28, 0xf1, 0xb, 0x6d, 0x7e, 0x6e, 0xcc, 0xdc, 0x94, 0x58, 0xc, 0xb, 0xd9, 0x3d, 0x53, 0xd3, 0xa5, 0x67, 0x71, 0xa2, 0x66, 0xb4, 0xb6, 0xd, 0x84}}
+	return a, nil
+}
+
+var __1528395677_add_search_historyDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x36\x00\xc9\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x61\x72\x63\x68\x5f\x68\x69\x73\x74\x6f\x72\x79\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xea\x94\xc8\x31\x36\x00\x00\x00")
+
+func _1528395677_add_search_historyDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395677_add_search_historyDownSql,
+		"1528395677_add_search_history.down.sql",
+	)
+}
+
+func _1528395677_add_search_historyDownSql() (*asset, error) {
+	bytes, err := _1528395677_add_search_historyDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395677_add_search_history.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7b, 0xe2, 0x7b, 0x2c, 0x41, 0x63, 0x83, 0x5a, 0xef, 0x4, 0xa3, 0x4d, 0xac, 0xb0, 0xb5, 0xfc, 0x68, 0x11, 0xd8, 0xe6, 0xa, 0xd, 0x78, 0xfd, 0xad, 0x28, 0x35, 0x5a, 0x66, 0x97, 0x70, 0xc5}}
+	return a, nil
+}
+
+var __1528395677_add_search_historyUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xc1\x6e\x82\x40\x14\x45\xf7\x7c\xc5\x5d\x42\xe2\x1f\xb8\x42\x78\x36\xa4\x38\x34\x88\x49\x5d\x4d\xa6\xf0\xa2\x93\x28\xd8\x99\x47\x2c\xfd\xfa\x26\x60\xd4\xd8\xd6\xe5\xe4\x9c\x77\xe7\xe6\x2e\xe8\x25\x53\xf3\x20\x48\x4a\x8a\x2b\x42\x15\x2f\x72\x82\x67\xe3\xea\xbd\xde\x5b\x2f\x9d\x1b\x10\x06\x00\x60\x1b\x7c\xd8\x9d\x67\x67\xcd\x01\x6f\x65\xb6\x8a\xcb\x2d\x5e\x69\x3b\x1b\x69\xef\xd9\x69\xdb\xc0\xb6\xc2\x3b\x76\x50\x45\x05\xb5\xc9\x73\x94\xb4\xa4\x92\x54\x42\xeb\xd1\xf1\xa1\x6d\x22\x14\x0a\x29\xe5\x54\x11\x92\x78\x9d\xc4\x29\x4d\x21\x9f\x3d\xbb\x01\xc2\x5f\x72\xbd\x9f\xc0\xc9\x88\xb0\x6b\xb5\x0c\x27\xfe\x8b\x3b\xf6\xfd\x41\x74\xdd\xf5\xad\xfc\xaa\x30\x29\x07\x23\xdc\xd6\x83\x3e\xfa\x7f\x84\xda\xb1\x11\x6e\xb4\x11\x88\x3d\xb2\x17\x73\x3c\xe1\x6c\x65\x3f\x3e\xf1\xdd\xb5\x7c\xbd\x40\x4a\xcb\x78\x93\x57\x68\xbb\x73\x18\x05\xd1\x6d\xc0\x4c\xa5\xf4\xfe\x30\xa0\xbe\x8c\xa3\xef\xbe\x28\xd4\x83\x14\x5e\xa4\x19\xee\xac\x94\xd6\x49\x34\x7f\x9a\xfd\x34\xf3\x06\xc7\x8a\xc5\x6a\x95\x55\xf3\xe0\x67\x00\x9b\xaf\xad\xf2\xf4\x01\x00\x00")
+
+func _1528395677_add_search_historyUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395677_add_search_historyUpSql,
+		"1528395677_add_search_history.up.sql",
+	)
+}
+
+func _1528395677_add_search_historyUpSql() (*asset, error) {
+	bytes, err := _1528395677_add_search_historyUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395677_add_search_history.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x66, 0xb5, 0x1c, 0xee, 0x4b, 0x9f, 0xf7, 0x3a, 0x93, 0x16, 0xd3, 0xe3, 0xa7, 0x79, 0x62, 0x91, 0xea, 0x98, 0x8d, 0x4e, 0xed, 0x5e, 0xaa, 0x30, 0x26, 0x8a, 0xd5, 0x7d, 0x5b, 0x27, 0x76, 0x16}}
+	return a, nil
+}
+
+var __1528395678_add_inventory_objects_last_used_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xcc\x2b\x4b\xcd\x2b\xc9\x2f\xaa\x8c\xcf\x4f\xca\x4a\x4d\x2e\x29\x8e\xcf\x49\x2c\x2e\x89\x2f\x2d\x4e\x4d\x89\x4f\x2c\xb1\xe6\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\xc5\x54\xab\x00\x36\xd2\xd9\xdf\x27\xd4\xd7\x0f\xc9\x4c\x54\x13\xb8\x9c\xfd\x7d\x7d\x3d\x43\xac\xb9\x00\x03\x00\xc4\x08\x74\xcd\x88\x00\x00\x00")
+
+func _1528395678_add_inventory_objects_last_used_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395678_add_inventory_objects_last_used_atDownSql,
+		"1528395678_add_inventory_objects_last_used_at.down.sql",
+	)
+}
+
+func _1528395678_add_inventory_objects_last_used_atDownSql() (*asset, error) {
+	bytes, err := _1528395678_add_inventory_objects_last_used_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395678_add_inventory_objects_last_used_at.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa8, 0x32, 0x2d, 0xe8, 0x1a, 0xb5, 0xec, 0x97, 0x1a, 0xdb, 0xee, 0x9b, 0x87, 0x38, 0x4d, 0xf, 0x3d, 0x2e, 0x2e, 0x5, 0x76, 0x97, 0xe9, 0x3b, 0x53, 0x3a, 0x7c, 0x8e, 0x98, 0x14, 0x1f, 0x70}}
+	return a, nil
+}
+
+var __1528395678_add_inventory_objects_last_used_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8e\xc1\x8a\xf2\x30\x14\x46\xf7\x79\x8a\x6f\xa9\x0b\x7d\x81\xae\xaa\x8d\x3f\x81\x98\x82\x46\x70\x57\x62\xbd\x9a\xfc\xd8\x44\x92\x3b\x53\x9c\xa7\x1f\x2c\x2e\x66\xc6\xe5\x85\x73\xcf\x77\x56\xf2\x9f\x32\x95\x10\x8b\x05\x54\xfc\xa4\xc8\x29\x07\x2a\x60\xef\x18\x2e\x13\x62\xc2\x2d\xc5\x2b\x65\x7c\x14\x3a\x63\x46\xcb\xeb\x12\xe9\x02\xce\x44\x05\xa3\x0f\xbd\x7f\x71\x8c\xbb\xcb\x8c\x74\x79\xba\x5c\x7c\x20\x53\x4f\x91\xd1\xa7\x61\x08\x3c\x9f\xa8\x33\xdd\x88\xe9\x8c\x93\x7b\xca\x52\xc4\xe8\x29\x82\x3d\x3d\x30\x52\x26\xdc\x5c\xe1\x69\x68\x29\x6a\x6d\xe5\x0e\xb6\x5e\x69\x89\xf0\x2a\x7b\x74\xe9\xf4\x9f\x7a\x2e\xa8\x9b\x06\xeb\x56\x1f\xb6\x06\x6a\x03\xd3\x5a\xc8\xa3\xda\xdb\xfd\x64\xe8\x9e\x86\xce\x31\x38\x0c\x54\xd8\x0d\x77\x8c\x81\xfd\x74\xe2\x2b\x45\x9a\x1e\xcc\x41\x6b\x34\x72\x53\x1f\xb4\x45\x4c\xe3\x6c\x5e\x89\xf5\x4e\xd6\x56\x42\x99\x46\x1e\xff\x88\xdf\x1a\xba\x5f\x53\xad\x79\xaf\x9c\xfd\x24\xe6\x95\x10\xeb\x76\xbb\x55\xb6\x12\xdf\x03\x00\xe1\x1e\xbd\x2f\x75\x01\x00\x00")
+
+func _1528395678_add_inventory_objects_last_used_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395678_add_inventory_objects_last_used_atUpSql,
+		"1528395678_add_inventory_objects_last_used_at.up.sql",
+	)
+}
+
+func _1528395678_add_inventory_objects_last_used_atUpSql() (*asset, error) {
+	bytes, err := _1528395678_add_inventory_objects_last_used_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395678_add_inventory_objects_last_used_at.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x65, 0xab, 0x60, 0xb3, 0x67, 0xa1, 0xa, 0x8a, 0x1d, 0x35, 0x16, 0x50, 0x5c, 0xb3, 0x25, 0xe3, 0xca, 0x33, 0x7a, 0x72, 0x30, 0x31, 0x8f, 0xe0, 0xcb, 0x85, 0x2d, 0x5e, 0x13, 0xda, 0x5a, 0x78}}
+	return a, nil
+}
+
+var __1528395679_add_users_deactivated_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x49\x00\xb6\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x64\x65\x61\x63\x74\x69\x76\x61\x74\x65\x64\x5f\x61\x74\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xc1\x00\x0b\x10\x49\x00\x00\x00")
+
+func _1528395679_add_users_deactivated_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395679_add_users_deactivated_atDownSql,
+		"1528395679_add_users_deactivated_at.down.sql",
+	)
+}
+
+func _1528395679_add_users_deactivated_atDownSql() (*asset, error) {
+	bytes, err := _1528395679_add_users_deactivated_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395679_add_users_deactivated_at.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8d, 0xeb, 0x49, 0x57, 0xab, 0x77, 0x2, 0x2d, 0xa9, 0xf5, 0x8a, 0x7d, 0xbb, 0xa7, 0x13, 0x8e, 0xfc, 0xd3, 0x37, 0x6c, 0x59, 0xd9, 0xe8, 0x80, 0x9a, 0xc7, 0x62, 0xf7, 0x31, 0xbc, 0x13, 0x48}}
+	return a, nil
+}
+
+var __1528395679_add_users_deactivated_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\x8e\xcd\x4a\xc3\x40\x14\x85\xf7\xf3\x14\x67\xa7\x42\x9b\x17\xc8\x2a\x4d\xa3\x0c\xe4\x47\xcc\x14\xdc\xc9\x4d\xe7\xd2\x5c\x24\x93\x30\x73\x5b\x89\x4f\x2f\x29\x82\xae\xcf\x39\xdf\xf9\x0e\xd5\x8b\x6d\x73\x63\xf6\x7b\x9c\x12\xc7\x04\xcf\x74\x56\xb9\x91\xb2\xc7\xb0\x82\x02\xc4\x73\x50\xd1\x15\x4b\x9c\x6f\xe2\x39\xe2\x91\xb3\x4b\xb6\xc3\x97\xe8\x08\x1d\x19\x7d\x69\x1b\x14\xaf\xf6\x09\x67\x0a\x0f\xba\xc1\x92\x5c\x02\x24\xec\x30\x5c\x75\xeb\x48\x84\x27\x25\x48\xc2\x27\x2f\x8a\x34\x43\x47\xba\x47\xeb\xb6\xc2\xc0\x88\x7f\xd7\x99\x29\x6a\x57\xbd\xc1\x15\x87\xba\xc2\xf5\x6e\x56\x1c\x8f\x28\xbb\xfa\xd4\xb4\xb0\xcf\x68\x3b\x87\xea\xdd\xf6\xae\xff\xaf\xfc\xb1\x21\x65\xe2\xa4\x34\x2d\xbf\x82\x32\x31\xbe\xe7\xc0\xb9\x31\x65\xd7\x34\xd6\xe5\xe6\x67\x00\x72\xea\x7a\x10\xf6\x00\x00\x00")
+
+func _1528395679_add_users_deactivated_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395679_add_users_deactivated_atUpSql,
+		"1528395679_add_users_deactivated_at.up.sql",
+	)
+}
+
+func _1528395679_add_users_deactivated_atUpSql() (*asset, error) {
+	bytes, err := _1528395679_add_users_deactivated_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395679_add_users_deactivated_at.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa1, 0xf9, 0xae, 0x24, 0xbd, 0x54, 0xe6, 0xa6, 0xed, 0xd0, 0x9c, 0x4f, 0xee, 0xa3, 0xf2, 0x70, 0xe8, 0xcb, 0xb0, 0xc2, 0xa8, 0x4b, 0x7d, 0xf1, 0x1e, 0xd0, 0xc7, 0x19, 0xf0, 0x76, 0xe, 0x3}}
+	return a, nil
+}
+
+var __1528395680_add_registry_extension_release_signing_public_keyDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x63\x00\x9c\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x72\x65\x67\x69\x73\x74\x72\x79\x5f\x65\x78\x74\x65\x6e\x73\x69\x6f\x6e\x5f\x72\x65\x6c\x65\x61\x73\x65\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x69\x67\x6e\x69\x6e\x67\x5f\x70\x75\x62\x6c\x69\x63\x5f\x6b\x65\x79\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x7d\xc3\x10\xd4\x63\x00\x00\x00")
+
+func _1528395680_add_registry_extension_release_signing_public_keyDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395680_add_registry_extension_release_signing_public_keyDownSql,
+		"1528395680_add_registry_extension_release_signing_public_key.down.sql",
+	)
+}
+
+func _1528395680_add_registry_extension_release_signing_public_keyDownSql() (*asset, error) {
+	bytes, err := _1528395680_add_registry_extension_release_signing_public_keyDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395680_add_registry_extension_release_signing_public_key.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xda, 0xe1, 0xd4, 0xe, 0xad, 0x9c, 0xf1, 0xc, 0x4a, 0xac, 0x67, 0xda, 0x17, 0xe8, 0xf6, 0x40, 0x97, 0xac, 0x65, 0x18, 0x64, 0xbc, 0x9e, 0xbc, 0x9d, 0x61, 0xac, 0xaa, 0x17, 0xda, 0xb6, 0xfd}}
+	return a, nil
+}
+
+var __1528395680_add_registry_extension_release_signing_public_keyUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x91\xcd\xee\xd3\x30\x10\xc4\xef\x7e\x8a\xb9\x15\x24\xfe\x79\x81\xa8\x87\xb4\x49\x21\x52\x3e\x50\xe2\x0a\x6e\x91\x9b\x2c\xb1\x45\xe5\x20\x7b\x43\xd2\xb7\x47\x4e\x45\x51\x45\xe1\xea\x9d\xf9\xcd\xae\xe7\x90\x7d\xcc\xab\x58\x88\xb7\x37\x48\x4d\xf0\x66\xb4\xc6\x8e\xf8\x31\x5f\xae\xa6\xc7\x77\xba\x81\xb5\x62\x28\x38\xba\x92\xf2\xb4\xf3\xb8\xcc\x76\xb8\xde\xa5\x8a\x67\x47\x58\x94\xc7\x4f\x72\xe6\x9b\xa1\x01\x8b\x61\x1d\x68\x8b\x26\x0b\xc3\xdb\x70\xa3\x79\x4d\x43\x84\xe6\x8e\xf1\x50\x8e\x9e\x4d\x60\x6d\xfc\x96\xf8\xce\x4e\x0c\xd6\x14\x30\xb4\x32\x59\x6f\x26\xbb\xf3\xe8\x67\xe7\xc8\x72\xd0\xbc\xff\x00\x3f\xc1\x4d\xac\x38\xac\xcb\x9a\x9e\x94\x81\x32\x4c\xe4\xed\x8e\xd1\x6b\x65\xc7\x8d\xb5\x68\xd3\xeb\xe0\x06\xad\xc6\x6f\x46\xf7\xcf\x75\x22\x91\x14\x32\x6b\x20\x93\x43\x91\xc1\xd1\x68\x3c\xbb\x5b\xf7\x48\xe9\x1e\xd6\x24\x4d\x71\xac\x8b\x73\x59\x21\x3f\xa1\xaa\x25\xb2\xaf\x79\x2b\xdb\xdf\x9f\xd9\x6d\xe7\xf7\x5d\x08\x66\x5a\x39\x16\xe2\xfc\x39\x4d\xe4\xff\xa9\x8e\x1c\xda\x4c\xbe\x80\xec\xd7\xe8\xef\x47\x71\x6a\xea\xf2\x05\xd0\x63\x15\x5f\x3e\x65\x4d\x86\x35\x32\xc3\xde\x91\x8b\x5e\xa4\x9a\x01\x49\x95\x22\x4c\xef\xed\x76\x7f\xda\xcd\xdb\xed\xa6\xea\x5c\x14\xb1\x10\xc7\xba\x2c\x73\x19\x8b\x5f\x03\x00\xdb\x15\x8c\x94\x38\x02\x00\x00")
+
+func _1528395680_add_registry_extension_release_signing_public_keyUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395680_add_registry_extension_release_signing_public_keyUpSql,
+		"1528395680_add_registry_extension_release_signing_public_key.up.sql",
+	)
+}
+
+func _1528395680_add_registry_extension_release_signing_public_keyUpSql() (*asset, error) {
+	bytes, err := _1528395680_add_registry_extension_release_signing_public_keyUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395680_add_registry_extension_release_signing_public_key.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xaf, 0xa2, 0x8b, 0x98, 0xdf, 0xd8, 0x6e, 0x5e, 0xaa, 0x8b, 0xba, 0x3e, 0xdd, 0x59, 0x4, 0x2c, 0xeb, 0xfb, 0x3f, 0xd6, 0x97, 0xc0, 0xd1, 0x9, 0x62, 0xb3, 0x19, 0x47, 0xd4, 0x9, 0xe2, 0xba}}
+	return a, nil
+}
+
+var __1528395681_add_seat_usage_snapshotsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x3c\x00\xc3\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x61\x74\x5f\x75\x73\x61\x67\x65\x5f\x73\x6e\x61\x70\x73\x68\x6f\x74\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xc7\x4d\x84\xf8\x3c\x00\x00\x00")
+
+func _1528395681_add_seat_usage_snapshotsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395681_add_seat_usage_snapshotsDownSql,
+		"1528395681_add_seat_usage_snapshots.down.sql",
+	)
+}
+
+func _1528395681_add_seat_usage_snapshotsDownSql() (*asset, error) {
+	bytes, err := _1528395681_add_seat_usage_snapshotsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395681_add_seat_usage_snapshots.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x43, 0x30, 0x73, 0xc7, 0xde, 0xf, 0xbe, 0x6e, 0x26, 0x49, 0x7a, 0xd1, 0xef, 0xe7, 0x4c, 0x1, 0xf3, 0xfc, 0x3e, 0xef, 0x75, 0x9d, 0xd, 0x64, 0x74, 0x97, 0xf6, 0x8, 0xe4, 0xd1, 0xfc, 0x4e}}
+	return a, nil
+}
+
+var __1528395681_add_seat_usage_snapshotsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8e\x31\x4f\xc3\x30\x10\x46\xf7\xfb\x15\xdf\x08\x52\x8b\x3a\xb0\x65\x4a\x8b\x41\x16\x49\x8a\x52\x23\xd1\x29\x3a\x92\x6b\x1b\xb5\x38\x51\xce\x19\xfa\xef\x91\x5d\x01\x03\x8b\x07\x7f\xef\x9e\xde\xda\xbc\xd8\x2a\x23\x5a\x2e\xf1\xc4\xfd\xe5\x0a\xf5\x3c\xea\x69\x08\x8a\xe1\x80\x70\x12\x8c\xc2\x67\xf8\xf9\xeb\x53\xa6\xf8\x35\xab\x4c\xe0\xb6\x1d\x66\x1f\x14\xec\xbb\x04\xfd\xee\x51\x74\xe9\x5b\xf1\x2a\x1d\x54\x38\xe8\x02\x67\x19\x03\x0e\xc3\x84\xc7\xd5\x0a\x1d\x5f\xf5\x81\x36\xb5\xc9\x9d\x81\xcb\xd7\x85\x81\x7d\x46\xb5\x75\x30\x1f\x76\xe7\x76\xe9\xa8\x99\x95\x8f\xd2\xfc\xb5\xdc\x11\x00\x74\x1c\xe4\xf6\xbc\xd5\xb6\xcc\xeb\x3d\x5e\xcd\x7e\x91\xa6\x98\xd5\xa4\x28\xf4\x3e\xc8\x51\xa6\xe4\xac\xde\x8b\xe2\x06\xfc\x44\x35\xd1\xaf\xff\x20\xba\xcf\x88\x36\xdb\xb2\xb4\x2e\xa3\xef\x01\x00\x8c\x49\xe7\xcd\x15\x01\x00\x00")
+
+func _1528395681_add_seat_usage_snapshotsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395681_add_seat_usage_snapshotsUpSql,
+		"1528395681_add_seat_usage_snapshots.up.sql",
+	)
+}
+
+func _1528395681_add_seat_usage_snapshotsUpSql() (*asset, error) {
+	bytes, err := _1528395681_add_seat_usage_snapshotsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395681_add_seat_usage_snapshots.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdb, 0x7b, 0xd8, 0x9b, 0xce, 0x49, 0xff, 0x48, 0x12, 0x61, 0x8b, 0xb7, 0xe8, 0xae, 0xa4, 0xf6, 0x76, 0x5b, 0x8f, 0x83, 0x11, 0x42, 0x31, 0xae, 0xd5, 0x83, 0x5c, 0x90, 0xb8, 0x7e, 0x97, 0xb5}}
+	return a, nil
+}
+
+var __1528395682_add_event_logs_created_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x4a\x00\xb5\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x65\x76\x65\x6e\x74\x5f\x6c\x6f\x67\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x63\x72\x65\x61\x74\x65\x64\x5f\x61\x74\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x2d\x88\xac\x2a\x4a\x00\x00\x00")
+
+func _1528395682_add_event_logs_created_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395682_add_event_logs_created_atDownSql,
+		"1528395682_add_event_logs_created_at.down.sql",
+	)
+}
+
+func _1528395682_add_event_logs_created_atDownSql() (*asset, error) {
+	bytes, err := _1528395682_add_event_logs_created_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395682_add_event_logs_created_at.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x5f, 0x79, 0x74, 0x51, 0x49, 0xc3, 0xda, 0xfd, 0x56, 0xd, 0x8d, 0xe8, 0x3b, 0x67, 0xbd, 0x13, 0xf8, 0xd3, 0x43, 0xcd, 0x26, 0x2, 0x4f, 0xbe, 0x5c, 0x30, 0x7d, 0x45, 0xde, 0xc7, 0xb0, 0x9f}}
+	return a, nil
+}
+
+var __1528395682_add_event_logs_created_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x4d\x6e\xdb\x30\x10\x85\xf7\x3a\xc5\xdb\x35\x01\x1a\x5f\xc0\x2b\x27\x56\x0a\x01\xfe\x01\x6a\x1a\xed\x2e\x18\x8b\x23\x91\xa8\xc4\x11\x38\xe3\x3a\xe9\xe9\x0b\x29\x32\xa0\x4d\x96\x9c\xf7\xf8\xf1\xe3\x3c\x97\x3f\xaa\xc3\xba\x28\x9e\x9e\xf0\x2b\x70\x82\x05\x06\xff\xe5\x64\xb8\x91\x22\x26\xe5\x6c\xec\xf1\xa0\x6c\xb8\x7c\x4c\xb1\x27\xa3\x0b\x29\x3f\xae\x70\x56\x6a\xef\x7d\x7e\x1f\x24\x9b\x42\x52\xf7\x31\xe2\x62\xaa\xbb\xab\x9f\xd3\x05\x4a\xa5\x67\x58\xec\x19\xd4\xca\x77\xa8\xc0\x02\x19\x28\xdd\xdf\x0d\xa2\x3c\xd7\x51\x4b\xdf\x47\xd3\x91\x47\x8d\x71\x9e\x04\xe6\x4c\x9a\xc5\xa5\x68\x01\x84\x10\xdb\xc0\x19\xd5\x16\x51\x91\xc4\xa0\x7f\xe2\x30\xb0\x5f\xc1\x05\x46\x2d\xdd\xb5\x4f\x08\x34\xf1\x92\xc0\x73\x43\xd7\xce\xd0\x48\x06\xbf\x47\xb5\x98\xda\xd9\x77\x21\xe6\xfd\x38\x8e\x06\x2f\xac\xe9\x9b\x21\xf3\x2d\x47\xe3\xc9\xc5\xe8\xd2\xf1\xaa\xd8\xec\x5c\xf9\x13\x6e\xf3\xbc\x2b\x3f\x01\x6f\x9d\xb4\x8a\xcd\x76\x8b\x97\xe3\xee\xbc\x3f\xa0\x7a\xc5\xe1\xe8\x50\xfe\xae\x4e\xee\x84\x3a\x33\x19\xfb\x37\xb2\x69\x15\x6a\xd4\x0f\x9f\x9f\x18\x8f\xf8\x27\x89\xd7\x5f\x42\xa7\xf1\x8c\x5d\x80\x4e\xa5\xc3\xb6\x7c\xdd\x9c\x77\x0e\x49\x6e\x0f\x8f\xeb\xa2\x78\x39\xee\xf7\x95\x5b\x17\xff\x07\x00\x80\xc0\x1d\xcf\xe7\x01\x00\x00")
+
+func _1528395682_add_event_logs_created_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395682_add_event_logs_created_atUpSql,
+		"1528395682_add_event_logs_created_at.up.sql",
+	)
+}
+
+func _1528395682_add_event_logs_created_atUpSql() (*asset, error) {
+	bytes, err := _1528395682_add_event_logs_created_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395682_add_event_logs_created_at.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf7, 0xb3, 0x15, 0x83, 0xaf, 0xd6, 0x4b, 0x9b, 0x11, 0x9c, 0x59, 0x17, 0x71, 0x93, 0x21, 0x8e, 0xef, 0xc4, 0xea, 0xed, 0xd6, 0x52, 0xac, 0x6, 0xa2, 0xac, 0x8d, 0xd3, 0x2e, 0xce, 0xae, 0xa7}}
+	return a, nil
+}
+
+var __1528395683_add_registry_extension_remote_pinsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x46\x00\xb9\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x67\x69\x73\x74\x72\x79\x5f\x65\x78\x74\x65\x6e\x73\x69\x6f\x6e\x5f\x72\x65\x6d\x6f\x74\x65\x5f\x70\x69\x6e\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x76\xe2\x23\x82\x46\x00\x00\x00")
+
+func _1528395683_add_registry_extension_remote_pinsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395683_add_registry_extension_remote_pinsDownSql,
+		"1528395683_add_registry_extension_remote_pins.down.sql",
+	)
+}
+
+func _1528395683_add_registry_extension_remote_pinsDownSql() (*asset, error) {
+	bytes, err := _1528395683_add_registry_extension_remote_pinsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395683_add_registry_extension_remote_pins.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x9b, 0x61, 0x2a, 0xb2, 0xbe, 0xbe, 0xf5, 0xf2, 0x69, 0x47, 0xfe, 0x3b, 0x52, 0x43, 0x73, 0x7d, 0xbb, 0x59, 0x98, 0x83, 0x7a, 0xd1, 0xc7, 0x61, 0xff, 0x60, 0x4, 0x12, 0xd0, 0x23, 0x14, 0x8d}}
+	return a, nil
+}
+
+var __1528395683_add_registry_extension_remote_pinsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\xc1\x8a\xf2\x30\x14\x85\xf7\x79\x8a\xb3\xb4\xf0\xfb\xbf\x40\x57\xd5\xc6\x21\x4c\x5b\x87\x1a\x61\x5c\x95\x3a\xbd\xda\x0b\xd3\x44\x9a\x0b\xea\x3c\xfd\xd0\x28\x75\x33\xab\x10\x72\xbe\xf3\x1d\xb2\xd2\x6f\xa6\x4a\x95\x5a\x2e\x61\x7b\xc2\x48\xdf\xd4\x06\x0a\x90\xbe\x15\x48\xcf\x01\x81\x85\x70\x61\x17\x40\x37\x21\x17\xd8\xbb\x80\xd3\xe8\x07\x48\x04\x06\x2f\xd3\x71\xe6\x20\xe3\x1d\xe2\xb1\x38\xde\xa7\x3a\xe9\x89\xc7\x17\x03\x93\xa3\x75\x5d\x84\x4c\x0e\x7f\x7a\xe2\xd1\x07\xef\xfe\x6a\x4b\xfe\xab\x75\xad\x33\xab\x61\xb3\x55\xa1\x61\x36\xa8\xb6\x16\xfa\xd3\xec\xec\x6e\x4e\x35\xb3\xa3\x79\xf0\x4d\x5c\xbb\x50\x00\x5e\xfe\x86\x3b\x08\xdd\x04\x1f\xb5\x29\xb3\xfa\x80\x77\x7d\xf8\x17\x23\xcf\x0d\x53\xe0\xc8\x67\x76\x12\x25\xd5\xbe\x28\x1e\xef\x5f\x23\xb5\x42\x5d\x33\x7d\x08\x0f\x14\xa4\x1d\x2e\xb8\xb2\xf4\xf1\x8a\x1f\xef\x68\x26\x90\xeb\x4d\xb6\x2f\x2c\x9c\xbf\x2e\x12\x95\xa4\x4a\xad\xb7\x65\x69\x6c\xaa\x7e\x07\x00\x7d\xcb\x3c\xf7\x6a\x01\x00\x00")
+
+func _1528395683_add_registry_extension_remote_pinsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395683_add_registry_extension_remote_pinsUpSql,
+		"1528395683_add_registry_extension_remote_pins.up.sql",
+	)
+}
+
+func _1528395683_add_registry_extension_remote_pinsUpSql() (*asset, error) {
+	bytes, err := _1528395683_add_registry_extension_remote_pinsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395683_add_registry_extension_remote_pins.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf6, 0x69, 0xd9, 0xaa, 0xf6, 0x49, 0x69, 0x6e, 0xf4, 0xe6, 0x54, 0xfe, 0x77, 0x4a, 0xe3, 0xb2, 0x28, 0x12, 0xa4, 0xe3, 0x46, 0x6b, 0xf6, 0x74, 0x17, 0xd2, 0x5d, 0xd8, 0x8f, 0x75, 0x23, 0xc5}}
+	return a, nil
+}
+
+var __1528395684_add_seat_overagesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x35\x00\xca\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x61\x74\x5f\x6f\x76\x65\x72\x61\x67\x65\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xb1\xbd\x3d\xfd\x35\x00\x00\x00")
+
+func _1528395684_add_seat_overagesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395684_add_seat_overagesDownSql,
+		"1528395684_add_seat_overages.down.sql",
+	)
+}
+
+func _1528395684_add_seat_overagesDownSql() (*asset, error) {
+	bytes, err := _1528395684_add_seat_overagesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395684_add_seat_overages.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x48, 0xc7, 0xb3, 0xf4, 0xd0, 0xdd, 0x7a, 0x7b, 0x95, 0x40, 0xcf, 0x7b, 0xf6, 0x7e, 0xf2, 0xbc, 0x9e, 0x2c, 0xd0, 0xe6, 0x46, 0x4b, 0xe5, 0xd2, 0x39, 0x2, 0x26, 0x6f, 0xbe, 0x9, 0x9d, 0x85}}
+	return a, nil
+}
+
+var __1528395684_add_seat_overagesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x3c\x8e\xcd\x6a\x72\x31\x14\x45\xe7\x79\x8a\x3d\xfc\x3e\xd0\xbe\x80\x23\x95\xb4\x5c\xea\x4f\xb9\xde\xd2\x3a\x92\x90\x6c\xbd\x01\x4d\x24\xe7\x54\x6d\x9f\xbe\xdc\xa0\x1d\x66\x91\xb5\xf6\x99\xd9\x97\x66\x35\x31\x66\x3c\xc6\x47\xcf\x04\xed\x09\x89\x4a\xec\x63\x11\x05\x6f\x9e\x0c\x0c\x95\x1f\xa3\x67\x12\x06\x08\x9d\x0a\xf2\x1e\x74\xbe\x7f\xe0\x27\xb4\xf9\x2a\x70\x85\x43\x2c\xf1\xc2\x82\xc2\x53\xbe\x30\x8c\x20\x19\xda\x3b\xad\x99\xc1\x46\xbe\xb0\xb8\x03\x71\x28\xce\x13\x67\x96\x98\x03\xa2\x20\xa7\xe3\xf7\x00\x93\x32\x20\x27\x5f\x63\x67\x96\xbf\x15\x33\x6f\xed\xb4\xb3\xe8\xa6\xb3\x85\x45\xf3\x8c\xd5\xba\x83\xfd\x6c\x36\xdd\xa6\xde\xb5\xbb\x97\x05\xff\x0c\x80\x87\xb7\x93\x78\x48\x4e\xbf\x0a\xa1\xbc\x29\xde\xda\x66\x39\x6d\xb7\x78\xb5\xdb\x51\xfd\x77\xd7\x76\x12\x93\x27\x34\x9e\x28\xea\x4e\x67\x5c\xa3\xf6\xf5\x89\x9f\x9c\x58\xd7\x56\xef\x8b\x85\xf9\x3f\x31\x66\xbe\x5e\x2e\x9b\x6e\x62\x7e\x07\x00\x82\x15\xe3\x96\x45\x01\x00\x00")
+
+func _1528395684_add_seat_overagesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395684_add_seat_overagesUpSql,
+		"1528395684_add_seat_overages.up.sql",
+	)
+}
+
+func _1528395684_add_seat_overagesUpSql() (*asset, error) {
+	bytes, err := _1528395684_add_seat_overagesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395684_add_seat_overages.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xfd, 0x2c, 0x3d, 0x31, 0x23, 0x98, 0x51, 0x4e, 0xcb, 0xcb, 0x8c, 0x4f, 0xe0, 0x57, 0x7c, 0x18, 0xa4, 0x6, 0x9a, 0xe9, 0xe8, 0x1, 0xe2, 0x37, 0x97, 0xf2, 0x72, 0xf1, 0xe4, 0x3d, 0xde, 0xfa}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -2491,6 +3121,36 @@ var _bindata = map[string]func() (*asset, error){
 	"1528395668_campaign_description_nullable.up.sql":                         _1528395668_campaign_description_nullableUpSql,
 	"1528395669_add_synced_at_to_perms_tables.down.sql":                       _1528395669_add_synced_at_to_perms_tablesDownSql,
 	"1528395669_add_synced_at_to_perms_tables.up.sql":                         _1528395669_add_synced_at_to_perms_tablesUpSql,
+	"1528395670_add_inventory_objects.down.sql":                               _1528395670_add_inventory_objectsDownSql,
+	"1528395670_add_inventory_objects.up.sql":                                 _1528395670_add_inventory_objectsUpSql,
+	"1528395671_add_repo_dependencies.down.sql":                               _1528395671_add_repo_dependenciesDownSql,
+	"1528395671_add_repo_dependencies.up.sql":                                 _1528395671_add_repo_dependenciesUpSql,
+	"1528395672_add_access_token_restrictions.down.sql":                       _1528395672_add_access_token_restrictionsDownSql,
+	"1528395672_add_access_token_restrictions.up.sql":                         _1528395672_add_access_token_restrictionsUpSql,
+	"1528395673_add_audit_log.down.sql":                                       _1528395673_add_audit_logDownSql,
+	"1528395673_add_audit_log.up.sql":                                         _1528395673_add_audit_logUpSql,
+	"1528395674_add_two_factor_auth.down.sql":                                 _1528395674_add_two_factor_authDownSql,
+	"1528395674_add_two_factor_auth.up.sql":                                   _1528395674_add_two_factor_authUpSql,
+	"1528395675_add_registry_extension_signing.down.sql":                      _1528395675_add_registry_extension_signingDownSql,
+	"1528395675_add_registry_extension_signing.up.sql":                        _1528395675_add_registry_extension_signingUpSql,
+	"1528395676_add_event_logs_export_checkpoints.down.sql":                   _1528395676_add_event_logs_export_checkpointsDownSql,
+	"1528395676_add_event_logs_export_checkpoints.up.sql":                     _1528395676_add_event_logs_export_checkpointsUpSql,
+	"1528395677_add_search_history.down.sql":                                  _1528395677_add_search_historyDownSql,
+	"1528395677_add_search_history.up.sql":                                    _1528395677_add_search_historyUpSql,
+	"1528395678_add_inventory_objects_last_used_at.down.sql":                  _1528395678_add_inventory_objects_last_used_atDownSql,
+	"1528395678_add_inventory_objects_last_used_at.up.sql":                    _1528395678_add_inventory_objects_last_used_atUpSql,
+	"1528395679_add_users_deactivated_at.down.sql":                            _1528395679_add_users_deactivated_atDownSql,
+	"1528395679_add_users_deactivated_at.up.sql":                              _1528395679_add_users_deactivated_atUpSql,
+	"1528395680_add_registry_extension_release_signing_public_key.down.sql":   _1528395680_add_registry_extension_release_signing_public_keyDownSql,
+	"1528395680_add_registry_extension_release_signing_public_key.up.sql":     _1528395680_add_registry_extension_release_signing_public_keyUpSql,
+	"1528395681_add_seat_usage_snapshots.down.sql":                            _1528395681_add_seat_usage_snapshotsDownSql,
+	"1528395681_add_seat_usage_snapshots.up.sql":                              _1528395681_add_seat_usage_snapshotsUpSql,
+	"1528395682_add_event_logs_created_at.down.sql":                           _1528395682_add_event_logs_created_atDownSql,
+	"1528395682_add_event_logs_created_at.up.sql":                             _1528395682_add_event_logs_created_atUpSql,
+	"1528395683_add_registry_extension_remote_pins.down.sql":                  _1528395683_add_registry_extension_remote_pinsDownSql,
+	"1528395683_add_registry_extension_remote_pins.up.sql":                    _1528395683_add_registry_extension_remote_pinsUpSql,
+	"1528395684_add_seat_overages.down.sql":                                   _1528395684_add_seat_overagesDownSql,
+	"1528395684_add_seat_overages.up.sql":                                     _1528395684_add_seat_overagesUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -2640,6 +3300,36 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1528395668_campaign_description_nullable.up.sql":                         {_1528395668_campaign_description_nullableUpSql, map[string]*bintree{}},
 	"1528395669_add_synced_at_to_perms_tables.down.sql":                       {_1528395669_add_synced_at_to_perms_tablesDownSql, map[string]*bintree{}},
 	"1528395669_add_synced_at_to_perms_tables.up.sql":                         {_1528395669_add_synced_at_to_perms_tablesUpSql, map[string]*bintree{}},
+	"1528395670_add_inventory_objects.down.sql":                               {_1528395670_add_inventory_objectsDownSql, map[string]*bintree{}},
+	"1528395670_add_inventory_objects.up.sql":                                 {_1528395670_add_inventory_objectsUpSql, map[string]*bintree{}},
+	"1528395671_add_repo_dependencies.down.sql":                               {_1528395671_add_repo_dependenciesDownSql, map[string]*bintree{}},
+	"1528395671_add_repo_dependencies.up.sql":                                 {_1528395671_add_repo_dependenciesUpSql, map[string]*bintree{}},
+	"1528395672_add_access_token_restrictions.down.sql":                       {_1528395672_add_access_token_restrictionsDownSql, map[string]*bintree{}},
+	"1528395672_add_access_token_restrictions.up.sql":                         {_1528395672_add_access_token_restrictionsUpSql, map[string]*bintree{}},
+	"1528395673_add_audit_log.down.sql":                                       {_1528395673_add_audit_logDownSql, map[string]*bintree{}},
+	"1528395673_add_audit_log.up.sql":                                         {_1528395673_add_audit_logUpSql, map[string]*bintree{}},
+	"1528395674_add_two_factor_auth.down.sql":                                 {_1528395674_add_two_factor_authDownSql, map[string]*bintree{}},
+	"1528395674_add_two_factor_auth.up.sql":                                   {_1528395674_add_two_factor_authUpSql, map[string]*bintree{}},
+	"1528395675_add_registry_extension_signing.down.sql":                      {_1528395675_add_registry_extension_signingDownSql, map[string]*bintree{}},
+	"1528395675_add_registry_extension_signing.up.sql":                        {_1528395675_add_registry_extension_signingUpSql, map[string]*bintree{}},
+	"1528395676_add_event_logs_export_checkpoints.down.sql":                   {_1528395676_add_event_logs_export_checkpointsDownSql, map[string]*bintree{}},
+	"1528395676_add_event_logs_export_checkpoints.up.sql":                     {_1528395676_add_event_logs_export_checkpointsUpSql, map[string]*bintree{}},
+	"1528395677_add_search_history.down.sql":                                  {_1528395677_add_search_historyDownSql, map[string]*bintree{}},
+	"1528395677_add_search_history.up.sql":                                    {_1528395677_add_search_historyUpSql, map[string]*bintree{}},
+	"1528395678_add_inventory_objects_last_used_at.down.sql":                  {_1528395678_add_inventory_objects_last_used_atDownSql, map[string]*bintree{}},
+	"1528395678_add_inventory_objects_last_used_at.up.sql":                    {_1528395678_add_inventory_objects_last_used_atUpSql, map[string]*bintree{}},
+	"1528395679_add_users_deactivated_at.down.sql":                            {_1528395679_add_users_deactivated_atDownSql, map[string]*bintree{}},
+	"1528395679_add_users_deactivated_at.up.sql":                              {_1528395679_add_users_deactivated_atUpSql, map[string]*bintree{}},
+	"1528395680_add_registry_extension_release_signing_public_key.down.sql":   {_1528395680_add_registry_extension_release_signing_public_keyDownSql, map[string]*bintree{}},
+	"1528395680_add_registry_extension_release_signing_public_key.up.sql":     {_1528395680_add_registry_extension_release_signing_public_keyUpSql, map[string]*bintree{}},
+	"1528395681_add_seat_usage_snapshots.down.sql":                            {_1528395681_add_seat_usage_snapshotsDownSql, map[string]*bintree{}},
+	"1528395681_add_seat_usage_snapshots.up.sql":                              {_1528395681_add_seat_usage_snapshotsUpSql, map[string]*bintree{}},
+	"1528395682_add_event_logs_created_at.down.sql":                           {_1528395682_add_event_logs_created_atDownSql, map[string]*bintree{}},
+	"1528395682_add_event_logs_created_at.up.sql":                             {_1528395682_add_event_logs_created_atUpSql, map[string]*bintree{}},
+	"1528395683_add_registry_extension_remote_pins.down.sql":                  {_1528395683_add_registry_extension_remote_pinsDownSql, map[string]*bintree{}},
+	"1528395683_add_registry_extension_remote_pins.up.sql":                    {_1528395683_add_registry_extension_remote_pinsUpSql, map[string]*bintree{}},
+	"1528395684_add_seat_overages.down.sql":                                   {_1528395684_add_seat_overagesDownSql, map[string]*bintree{}},
+	"1528395684_add_seat_overages.up.sql":                                     {_1528395684_add_seat_overagesUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
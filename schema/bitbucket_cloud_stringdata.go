@@ -58,6 +58,38 @@ const BitbucketCloudSchemaJSON = `{
         "requestsPerHour": 7200
       }
     },
+    "requestResilience": {
+      "description": "Retries and circuit breaking of API requests to Bitbucket Cloud. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.",
+      "title": "BitbucketCloudRequestResilience",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "maxRetries": {
+          "description": "Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 3
+        },
+        "maxRetryDelaySeconds": {
+          "description": "Maximum delay between retries. Responses with a longer Retry-After are not retried.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 60
+        },
+        "circuitBreakerFailureThreshold": {
+          "description": "Number of consecutive failed requests after which requests fail fast without contacting Bitbucket Cloud. -1 disables the circuit breaker.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 10
+        },
+        "circuitBreakerOpenSeconds": {
+          "description": "How long requests fail fast before a trial request is let through.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 30
+        }
+      }
+    },
     "username": {
       "description": "The username to use when authenticating to the Bitbucket Cloud. Also set the corresponding \"appPassword\" field.",
       "type": "string"
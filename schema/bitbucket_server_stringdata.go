@@ -50,6 +50,38 @@ const BitbucketServerSchemaJSON = `{
         "requestsPerHour": 28800
       }
     },
+    "requestResilience": {
+      "description": "Retries and circuit breaking of API requests to Bitbucket Server. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.",
+      "title": "BitbucketServerRequestResilience",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "maxRetries": {
+          "description": "Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 3
+        },
+        "maxRetryDelaySeconds": {
+          "description": "Maximum delay between retries. Responses with a longer Retry-After are not retried.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 60
+        },
+        "circuitBreakerFailureThreshold": {
+          "description": "Number of consecutive failed requests after which requests fail fast without contacting Bitbucket Server. -1 disables the circuit breaker.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 10
+        },
+        "circuitBreakerOpenSeconds": {
+          "description": "How long requests fail fast before a trial request is let through.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 30
+        }
+      }
+    },
     "url": {
       "description": "URL of a Bitbucket Server instance, such as https://bitbucket.example.com.",
       "type": "string",
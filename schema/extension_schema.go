@@ -16,6 +16,13 @@ type SourcegraphExtensionManifest struct {
 	Repository       *ExtensionRepository    `json:"repository,omitempty"`
 	Wip              bool                    `json:"wip,omitempty"`
 	Url              string                  `json:"url"`
+	Signature        *ExtensionSignature     `json:"signature,omitempty"`
+}
+
+// ExtensionSignature description: The signature of the bundled JavaScript source code of this extension.
+type ExtensionSignature struct {
+	PublicKey       string `json:"publicKey"`
+	BundleSignature string `json:"bundleSignature"`
 }
 
 // ExtensionRepository description: The location of the version control repository for this extension.
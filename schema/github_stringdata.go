@@ -58,6 +58,38 @@ const GitHubSchemaJSON = `{
         "requestsPeHour": 5000
       }
     },
+    "requestResilience": {
+      "description": "Retries and circuit breaking of API requests to GitHub. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.",
+      "title": "GitHubRequestResilience",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "maxRetries": {
+          "description": "Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 3
+        },
+        "maxRetryDelaySeconds": {
+          "description": "Maximum delay between retries. Responses with a longer Retry-After are not retried.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 60
+        },
+        "circuitBreakerFailureThreshold": {
+          "description": "Number of consecutive failed requests after which requests fail fast without contacting GitHub. -1 disables the circuit breaker.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 10
+        },
+        "circuitBreakerOpenSeconds": {
+          "description": "How long requests fail fast before a trial request is let through.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 30
+        }
+      }
+    },
     "certificate": {
       "description": "TLS certificate of the GitHub Enterprise instance. This is only necessary if the certificate is self-signed or signed by an internal CA. To get the certificate run ` + "`" + `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM` + "`" + `. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.",
       "type": "string",
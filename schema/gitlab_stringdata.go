@@ -52,6 +52,38 @@ const GitLabSchemaJSON = `{
         "requestsPerHour": 36000
       }
     },
+    "requestResilience": {
+      "description": "Retries and circuit breaking of API requests to GitLab. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.",
+      "title": "GitLabRequestResilience",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "maxRetries": {
+          "description": "Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 3
+        },
+        "maxRetryDelaySeconds": {
+          "description": "Maximum delay between retries. Responses with a longer Retry-After are not retried.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 60
+        },
+        "circuitBreakerFailureThreshold": {
+          "description": "Number of consecutive failed requests after which requests fail fast without contacting GitLab. -1 disables the circuit breaker.",
+          "type": "integer",
+          "minimum": -1,
+          "default": 10
+        },
+        "circuitBreakerOpenSeconds": {
+          "description": "How long requests fail fast before a trial request is let through.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 30
+        }
+      }
+    },
     "gitURLType": {
       "description": "The type of Git URLs to use for cloning and fetching Git repositories on this GitLab instance.\n\nIf \"http\", Sourcegraph will access GitLab repositories using Git URLs of the form http(s)://gitlab.example.com/myteam/myproject.git (using https: if the GitLab instance uses HTTPS).\n\nIf \"ssh\", Sourcegraph will access GitLab repositories using Git URLs of the form git@example.gitlab.com:myteam/myproject.git. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.",
       "type": "string",
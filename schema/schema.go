@@ -64,6 +64,7 @@ type AuthProviders struct {
 	HttpHeader    *HTTPHeaderAuthProvider
 	Github        *GitHubAuthProvider
 	Gitlab        *GitLabAuthProvider
+	Ldap          *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -85,6 +86,9 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -103,12 +107,20 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"})
+}
+
+// AuthTwoFactor description: Configures two-factor authentication (2FA) for builtin user accounts (with the `builtin` auth provider). Users can enroll TOTP authenticator apps and WebAuthn security keys on their two-factor authentication settings page. Storing TOTP secrets requires the `SRC_TWO_FACTOR_ENCRYPTION_KEY` environment variable to be set on the frontend.
+type AuthTwoFactor struct {
+	// RequireForSiteAdmins description: Require site admins to sign in with a second factor. Site admins without a second factor are signed out and must enroll one when they next sign in with their password.
+	RequireForSiteAdmins bool `json:"requireForSiteAdmins,omitempty"`
 }
 
 // BitbucketCloudConnection description: Configuration for a connection to Bitbucket Cloud.
@@ -137,6 +149,8 @@ type BitbucketCloudConnection struct {
 	//
 	// It is important that the Sourcegraph repository name generated with this pattern be unique to this code host. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
 	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// RequestResilience description: Retries and circuit breaking of API requests to Bitbucket Cloud. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+	RequestResilience *BitbucketCloudRequestResilience `json:"requestResilience,omitempty"`
 	// Teams description: An array of team names identifying Bitbucket Cloud teams whose repositories should be mirrored on Sourcegraph.
 	Teams []string `json:"teams,omitempty"`
 	// Url description: URL of Bitbucket Cloud, such as https://bitbucket.org. Generally, admin should not modify the value of this option because Bitbucket Cloud is a public hosting platform.
@@ -153,6 +167,18 @@ type BitbucketCloudRateLimit struct {
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
 
+// BitbucketCloudRequestResilience description: Retries and circuit breaking of API requests to Bitbucket Cloud. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+type BitbucketCloudRequestResilience struct {
+	// CircuitBreakerFailureThreshold description: Number of consecutive failed requests after which requests fail fast without contacting Bitbucket Cloud. -1 disables the circuit breaker.
+	CircuitBreakerFailureThreshold int `json:"circuitBreakerFailureThreshold,omitempty"`
+	// CircuitBreakerOpenSeconds description: How long requests fail fast before a trial request is let through.
+	CircuitBreakerOpenSeconds int `json:"circuitBreakerOpenSeconds,omitempty"`
+	// MaxRetries description: Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// MaxRetryDelaySeconds description: Maximum delay between retries. Responses with a longer Retry-After are not retried.
+	MaxRetryDelaySeconds int `json:"maxRetryDelaySeconds,omitempty"`
+}
+
 // BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions.
 type BitbucketServerAuthorization struct {
 	// HardTTL description: Duration after which a user's cached permissions must be updated before authorizing any user actions. This is 3 days by default.
@@ -213,6 +239,8 @@ type BitbucketServerConnection struct {
 	//
 	// The special string "none" can be used as the only element to disable this feature. Repositories matched by multiple query strings are only imported once. Here's the official Bitbucket Server documentation about which query string parameters are valid: https://docs.atlassian.com/bitbucket-server/rest/6.1.2/bitbucket-rest.html#idp355
 	RepositoryQuery []string `json:"repositoryQuery,omitempty"`
+	// RequestResilience description: Retries and circuit breaking of API requests to Bitbucket Server. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+	RequestResilience *BitbucketServerRequestResilience `json:"requestResilience,omitempty"`
 	// Token description: A Bitbucket Server personal access token with Read scope. Create one at https://[your-bitbucket-hostname]/plugins/servlet/access-tokens/add. Also set the corresponding "username" field.
 	//
 	// For Bitbucket Server instances that don't support personal access tokens (Bitbucket Server version 5.4 and older), specify user-password credentials in the "username" and "password" fields.
@@ -276,6 +304,18 @@ type BitbucketServerRateLimit struct {
 	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
+
+// BitbucketServerRequestResilience description: Retries and circuit breaking of API requests to Bitbucket Server. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+type BitbucketServerRequestResilience struct {
+	// CircuitBreakerFailureThreshold description: Number of consecutive failed requests after which requests fail fast without contacting Bitbucket Server. -1 disables the circuit breaker.
+	CircuitBreakerFailureThreshold int `json:"circuitBreakerFailureThreshold,omitempty"`
+	// CircuitBreakerOpenSeconds description: How long requests fail fast before a trial request is let through.
+	CircuitBreakerOpenSeconds int `json:"circuitBreakerOpenSeconds,omitempty"`
+	// MaxRetries description: Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// MaxRetryDelaySeconds description: Maximum delay between retries. Responses with a longer Retry-After are not retried.
+	MaxRetryDelaySeconds int `json:"maxRetryDelaySeconds,omitempty"`
+}
 type BitbucketServerUsernameIdentity struct {
 	Type string `json:"type"`
 }
@@ -338,6 +378,32 @@ type Discussions struct {
 	// AbuseProtection description: Enable abuse protection features (for public instances like Sourcegraph.com, not recommended for private instances).
 	AbuseProtection bool `json:"abuseProtection,omitempty"`
 }
+
+// EventLogsExportSink description: A sink to export usage events to.
+type EventLogsExportSink struct {
+	// BatchSize description: The maximum number of events to export in a batch.
+	BatchSize int `json:"batchSize,omitempty"`
+	// Fields description: Maps the names of the fields in the exported events to event_logs fields (id, name, url, user_id, anonymous_user_id, source, argument, version and timestamp) or to properties of the event's argument (argument.PROPERTY). If not set, all event_logs fields are exported with their own names.
+	Fields map[string]string `json:"fields,omitempty"`
+	// Headers description: For "http" and "kafka" sinks, additional HTTP headers to send (e.g., for authentication).
+	Headers map[string]string `json:"headers,omitempty"`
+	// MaxFileSizeMB description: For "file" sinks, the size at which the file is rotated.
+	MaxFileSizeMB int `json:"maxFileSizeMB,omitempty"`
+	// MaxFiles description: For "file" sinks, the number of rotated files to keep.
+	MaxFiles int `json:"maxFiles,omitempty"`
+	// Name description: A unique name for the sink. The export progress is recorded under this name, so renaming a sink exports all retained events again.
+	Name string `json:"name"`
+	// Path description: For "file" sinks, the path of the file to write events to. Rotated files are named PATH.1, PATH.2, and so on (oldest last). Any frontend replica may write a batch, so if there are multiple frontend replicas, the path must be on a volume that all of them share (or run only 1 replica).
+	Path string `json:"path,omitempty"`
+	// Redact description: The event_logs fields or argument properties (argument.PROPERTY) whose values are replaced with "REDACTED" before they are exported, to avoid exporting personally identifiable information.
+	Redact []string `json:"redact,omitempty"`
+	// Topic description: For "kafka" sinks, the topic to produce events to.
+	Topic string `json:"topic,omitempty"`
+	// Type description: The type of sink: "http" POSTs each batch as newline-delimited JSON to the URL, "file" appends events as newline-delimited JSON to a file (rotating it when it grows too large), and "kafka" produces each event to a topic with the Kafka REST Proxy API.
+	Type string `json:"type"`
+	// Url description: For "http" sinks, the URL to POST batches to. For "kafka" sinks, the base URL of the Kafka REST Proxy.
+	Url string `json:"url,omitempty"`
+}
 type ExcludedAWSCodeCommitRepo struct {
 	// Id description: The ID of an AWS Code Commit repository (as returned by the AWS API) to exclude from mirroring. Use this to exclude the repository, even if renamed, or to differentiate between repositories with the same name in multiple regions.
 	Id string `json:"id,omitempty"`
@@ -401,6 +467,8 @@ type ExperimentalFeatures struct {
 	EventLogging string `json:"eventLogging,omitempty"`
 	// SearchMultipleRevisionsPerRepository description: Enables searching multiple revisions of the same repository (using `repo:myrepo@branch1:branch2`).
 	SearchMultipleRevisionsPerRepository *bool `json:"searchMultipleRevisionsPerRepository,omitempty"`
+	// SearchResultsCache description: Caches the results of repository, file and path searches. Cached results are keyed on the commits that are searched, so they are never stale.
+	SearchResultsCache string `json:"searchResultsCache,omitempty"`
 	// StructuralSearch description: Enables structural search.
 	StructuralSearch string `json:"structuralSearch,omitempty"`
 	// TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
@@ -505,6 +573,8 @@ type GitHubConnection struct {
 	//
 	// If you need to narrow the set of mirrored repositories further (and don't want to enumerate it with a list or query set as above), create a new bot/machine user on GitHub or GitHub Enterprise that is only affiliated with the desired repositories.
 	RepositoryQuery []string `json:"repositoryQuery,omitempty"`
+	// RequestResilience description: Retries and circuit breaking of API requests to GitHub. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+	RequestResilience *GitHubRequestResilience `json:"requestResilience,omitempty"`
 	// Token description: A GitHub personal access token. Create one for GitHub.com at https://github.com/settings/tokens/new?description=Sourcegraph (for GitHub Enterprise, replace github.com with your instance's hostname). See https://docs.sourcegraph.com/admin/external_service/github#github-api-token-and-access for which scopes are required for which use cases.
 	Token string `json:"token"`
 	// Url description: URL of a GitHub instance, such as https://github.com or https://github-enterprise.example.com.
@@ -520,6 +590,18 @@ type GitHubRateLimit struct {
 	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
+
+// GitHubRequestResilience description: Retries and circuit breaking of API requests to GitHub. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+type GitHubRequestResilience struct {
+	// CircuitBreakerFailureThreshold description: Number of consecutive failed requests after which requests fail fast without contacting GitHub. -1 disables the circuit breaker.
+	CircuitBreakerFailureThreshold int `json:"circuitBreakerFailureThreshold,omitempty"`
+	// CircuitBreakerOpenSeconds description: How long requests fail fast before a trial request is let through.
+	CircuitBreakerOpenSeconds int `json:"circuitBreakerOpenSeconds,omitempty"`
+	// MaxRetries description: Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// MaxRetryDelaySeconds description: Maximum delay between retries. Responses with a longer Retry-After are not retried.
+	MaxRetryDelaySeconds int `json:"maxRetryDelaySeconds,omitempty"`
+}
 type GitHubWebhook struct {
 	// Org description: The name of the GitHub organization to which the webhook belongs
 	Org string `json:"org"`
@@ -585,6 +667,8 @@ type GitLabConnection struct {
 	//
 	// It is important that the Sourcegraph repository name generated with this pattern be unique to this code host. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
 	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// RequestResilience description: Retries and circuit breaking of API requests to GitLab. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+	RequestResilience *GitLabRequestResilience `json:"requestResilience,omitempty"`
 	// Token description: A GitLab access token with "api" scope. If you are enabling permissions with identity provider type "external", this token should also have "sudo" scope.
 	Token string `json:"token"`
 	// Url description: URL of a GitLab instance, such as https://gitlab.example.com or (for GitLab.com) https://gitlab.com.
@@ -611,6 +695,18 @@ type GitLabRateLimit struct {
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
 
+// GitLabRequestResilience description: Retries and circuit breaking of API requests to GitLab. If not set, transient errors are retried up to 3 times and requests fail fast for 30 seconds after 10 consecutive failures.
+type GitLabRequestResilience struct {
+	// CircuitBreakerFailureThreshold description: Number of consecutive failed requests after which requests fail fast without contacting GitLab. -1 disables the circuit breaker.
+	CircuitBreakerFailureThreshold int `json:"circuitBreakerFailureThreshold,omitempty"`
+	// CircuitBreakerOpenSeconds description: How long requests fail fast before a trial request is let through.
+	CircuitBreakerOpenSeconds int `json:"circuitBreakerOpenSeconds,omitempty"`
+	// MaxRetries description: Maximum number of times an idempotent request is retried after a transient error (HTTP 429, 5xx or a network error). -1 disables retries.
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// MaxRetryDelaySeconds description: Maximum delay between retries. Responses with a longer Retry-After are not retried.
+	MaxRetryDelaySeconds int `json:"maxRetryDelaySeconds,omitempty"`
+}
+
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
 	// Blacklist description: Regular expression to filter repositories from auto-discovery, so they will not get cloned automatically.
@@ -687,6 +783,50 @@ func (v *IdentityProvider) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"oauth", "username", "external"})
 }
 
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which signs users in with the username and password of their account on an LDAP server (such as Active Directory).
+type LDAPAuthProvider struct {
+	// BindDN description: The DN of the service account used to search for users and groups. If not set, searches are performed anonymously.
+	BindDN string `json:"bindDN,omitempty"`
+	// BindPassword description: The password of the service account (`bindDN`).
+	BindPassword string `json:"bindPassword,omitempty"`
+	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. For example, in configuration for a code host, you may want to designate this authentication provider as the identity provider for the code host.
+	ConfigID    string `json:"configID,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	// DisplayNameAttribute description: The attribute of the user entry that holds the user's display name.
+	DisplayNameAttribute string `json:"displayNameAttribute,omitempty"`
+	// EmailAttribute description: The attribute of the user entry that holds the user's email address. The email address is considered verified.
+	EmailAttribute string `json:"emailAttribute,omitempty"`
+	// GroupNameAttribute description: The attribute of group entries that holds the group's name, as used in `groupOrgs`.
+	GroupNameAttribute string `json:"groupNameAttribute,omitempty"`
+	// GroupOrgs description: Maps LDAP group names to the names of Sourcegraph organizations. When a user signs in, they are added to the organizations of their groups and removed from the other organizations in this map. Organizations that don't exist are created.
+	GroupOrgs map[string]string `json:"groupOrgs,omitempty"`
+	// GroupSearchBase description: The DN of the subtree to search for the user's groups in. Group memberships are only synced to organizations (see `groupOrgs`) if this is set.
+	GroupSearchBase string `json:"groupSearchBase,omitempty"`
+	// GroupSearchFilter description: The LDAP filter that finds the groups of the user signing in. "{dn}" is replaced with the DN of the user's entry and "{username}" with the value of the user's `usernameAttribute`.
+	GroupSearchFilter string `json:"groupSearchFilter,omitempty"`
+	// InsecureSkipVerify description: Whether to (insecurely) skip verification of the LDAP server's TLS certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// StartTLS description: Upgrade ldap:// connections to TLS with the StartTLS operation before sending credentials.
+	StartTLS bool   `json:"startTLS,omitempty"`
+	Type     string `json:"type"`
+	// Url description: The URL of the LDAP server. Use the ldaps:// scheme for LDAP over TLS, or the ldap:// scheme (optionally with `startTLS`).
+	Url string `json:"url"`
+	// UserSearchBase description: The DN of the subtree to search for users in.
+	UserSearchBase string `json:"userSearchBase"`
+	// UserSearchFilter description: The LDAP filter that finds the user signing in. "{username}" is replaced with the username entered on the sign-in page. The filter must match exactly one user.
+	UserSearchFilter string `json:"userSearchFilter,omitempty"`
+	// UsernameAttribute description: The attribute of the user entry that holds the user's username, which is normalized to a Sourcegraph username.
+	UsernameAttribute string `json:"usernameAttribute,omitempty"`
+}
+
+// LicensingSeatOverageGracePeriod description: Allows new user accounts to be created for a limited time after the license's maximum user count is reached, instead of blocking them immediately. Site admins are alerted while the site exceeds its licensed seats. If not set, new user accounts are blocked when the maximum user count is reached (unless the license allows true-up).
+type LicensingSeatOverageGracePeriod struct {
+	// Days description: The number of days after the site first exceeds its licensed seats during which new user accounts can still be created.
+	Days int `json:"days"`
+	// MaxOveragePercent description: The maximum number of user accounts beyond the licensed seats, as a percentage of the licensed seats (at least 1 user account is always allowed).
+	MaxOveragePercent int `json:"maxOveragePercent,omitempty"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	// Sentry description: Configuration for Sentry
@@ -842,6 +982,14 @@ type SMTPServerConfig struct {
 	// Username description: The username to use when communicating with the SMTP server.
 	Username string `json:"username,omitempty"`
 }
+
+// SearchHistory description: Configures the per-user search history, which records the searches users perform in the web app (the query, pattern type, result count and latency). Users can see their recent and frequent searches, and search suggestions include matching searches from their own history. Users can opt out with the `search.recordHistory` setting.
+type SearchHistory struct {
+	// Enabled description: Whether searches are recorded in users' search history. If false, no new searches are recorded (existing search history is kept until it expires).
+	Enabled *bool `json:"enabled,omitempty"`
+	// RetentionDays description: The number of days to keep search history entries. Older entries are deleted.
+	RetentionDays int `json:"retentionDays,omitempty"`
+}
 type SearchSavedQueries struct {
 	// Description description: Description of this saved query
 	Description string `json:"description"`
@@ -885,6 +1033,8 @@ type Settings struct {
 	ExperimentalFeatures *SettingsExperimentalFeatures `json:"experimentalFeatures,omitempty"`
 	// Extensions description: The Sourcegraph extensions to use. Enable an extension by adding a property `"my/extension": true` (where `my/extension` is the extension ID). Override a previously enabled extension and disable it by setting its value to `false`.
 	Extensions map[string]bool `json:"extensions,omitempty"`
+	// ExtensionsTrustedSigningPublicKeys description: The signing public keys (base64-encoded DER ECDSA P-256 public keys) that signed extensions may be signed with. If set, a signed extension whose signing public key is not in this list is not activated. Only honored in global settings.
+	ExtensionsTrustedSigningPublicKeys []string `json:"extensions.trustedSigningPublicKeys,omitempty"`
 	// Motd description: DEPRECATED: Use `notices` instead.
 	//
 	// An array (often with just one element) of messages to display at the top of all pages, including for unauthenticated users. Users may dismiss a message (and any message with the same string value will remain dismissed for the user).
@@ -909,6 +1059,8 @@ type Settings struct {
 	SearchIncludeArchived *bool `json:"search.includeArchived,omitempty"`
 	// SearchIncludeForks description: Whether searches should include searching forked repositories.
 	SearchIncludeForks *bool `json:"search.includeForks,omitempty"`
+	// SearchRecordHistory description: Whether your searches are recorded in your search history (if search history is enabled on the site). Your search history is only visible to you and site admins, and it is used to show your recent and frequent searches and to suggest searches.
+	SearchRecordHistory *bool `json:"search.recordHistory,omitempty"`
 	// SearchRepositoryGroups description: Named groups of repositories that can be referenced in a search query using the repogroup: operator.
 	SearchRepositoryGroups map[string][]string `json:"search.repositoryGroups,omitempty"`
 	// SearchSavedQueries description: DEPRECATED: Saved search queries
@@ -955,6 +1107,8 @@ type SiteConfiguration struct {
 	//   ```
 	//
 	AuthSessionExpiry string `json:"auth.sessionExpiry,omitempty"`
+	// AuthTwoFactor description: Configures two-factor authentication (2FA) for builtin user accounts (with the `builtin` auth provider). Users can enroll TOTP authenticator apps and WebAuthn security keys on their two-factor authentication settings page. Storing TOTP secrets requires the `SRC_TWO_FACTOR_ENCRYPTION_KEY` environment variable to be set on the frontend.
+	AuthTwoFactor *AuthTwoFactor `json:"auth.twoFactor,omitempty"`
 	// AuthUserOrgMap description: Ensure that matching users are members of the specified orgs (auto-joining users to the orgs if they are not already a member). Provide a JSON object of the form `{"*": ["org1", "org2"]}`, where org1 and org2 are orgs that all users are automatically joined to. Currently the only supported key is `"*"`.
 	AuthUserOrgMap map[string][]string `json:"auth.userOrgMap,omitempty"`
 	// AutomationReadAccessEnabled description: DEPRECATED: The automation feature was renamed to campaigns. Use `campaigns.readAccess.enabled` instead.
@@ -987,6 +1141,8 @@ type SiteConfiguration struct {
 	EmailImap *IMAPServerConfig `json:"email.imap,omitempty"`
 	// EmailSmtp description: The SMTP server used to send transactional emails (such as email verifications, reset-password emails, and notifications).
 	EmailSmtp *SMTPServerConfig `json:"email.smtp,omitempty"`
+	// EventLogsExport description: Sinks to export usage events (from the event_logs table) to, such as a data warehouse. Events are exported in batches, in order, at least once: after a failure, a batch is retried and may be delivered again.
+	EventLogsExport []*EventLogsExportSink `json:"eventLogs.export,omitempty"`
 	// ExperimentalFeatures description: Experimental features to enable or disable. Features that are now enabled by default are marked as deprecated.
 	ExperimentalFeatures *ExperimentalFeatures `json:"experimentalFeatures,omitempty"`
 	// Extensions description: Configures Sourcegraph extensions.
@@ -1011,6 +1167,8 @@ type SiteConfiguration struct {
 	HtmlHeadTop string `json:"htmlHeadTop,omitempty"`
 	// LicenseKey description: The license key associated with a Sourcegraph product subscription, which is necessary to activate Sourcegraph Enterprise functionality. To obtain this value, contact Sourcegraph to purchase a subscription. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.
 	LicenseKey string `json:"licenseKey,omitempty"`
+	// LicensingSeatOverageGracePeriod description: Allows new user accounts to be created for a limited time after the license's maximum user count is reached, instead of blocking them immediately. Site admins are alerted while the site exceeds its licensed seats. If not set, new user accounts are blocked when the maximum user count is reached (unless the license allows true-up).
+	LicensingSeatOverageGracePeriod *LicensingSeatOverageGracePeriod `json:"licensing.seatOverageGracePeriod,omitempty"`
 	// LightstepAccessToken description: DEPRECATED. Use Jaeger (`"observability.tracing": { "sampling": "selective" }`), instead.
 	LightstepAccessToken string `json:"lightstepAccessToken,omitempty"`
 	// LightstepProject description: DEPRECATED. Use Jaeger (`"observability.tracing": { "sampling": "selective" }`), instead.
@@ -1035,6 +1193,8 @@ type SiteConfiguration struct {
 	PermissionsUserMapping *PermissionsUserMapping `json:"permissions.userMapping,omitempty"`
 	// RepoListUpdateInterval description: Interval (in minutes) for checking code hosts (such as GitHub, Gitolite, etc.) for new repositories.
 	RepoListUpdateInterval int `json:"repoListUpdateInterval,omitempty"`
+	// SearchHistory description: Configures the per-user search history, which records the searches users perform in the web app (the query, pattern type, result count and latency). Users can see their recent and frequent searches, and search suggestions include matching searches from their own history. Users can opt out with the `search.recordHistory` setting.
+	SearchHistory *SearchHistory `json:"search.history,omitempty"`
 	// SearchIndexEnabled description: Whether indexed search is enabled. If unset Sourcegraph detects the environment to decide if indexed search is enabled. Indexed search is RAM heavy, and is disabled by default in the single docker image. All other environments will have it enabled by default. The size of all your repository working copies is the amount of additional RAM required.
 	SearchIndexEnabled *bool `json:"search.index.enabled,omitempty"`
 	// SearchIndexSymbolsEnabled description: Whether indexed symbol search is enabled. This is contingent on the indexed search configuration, and is true by default for instances with indexed search enabled. Enabling this will cause every repository to re-index, which is a time consuming (several hours) operation. Additionally, it requires more storage and ram to accommodate the added symbols information in the search index.
@@ -117,6 +117,12 @@ const SettingsSchemaJSON = `{
       "default": false,
       "!go": { "pointer": true }
     },
+    "search.recordHistory": {
+      "description": "Whether your searches are recorded in your search history (if search history is enabled on the site). Your search history is only visible to you and site admins, and it is used to show your recent and frequent searches and to suggest searches.",
+      "type": "boolean",
+      "default": true,
+      "!go": { "pointer": true }
+    },
     "quicklinks": {
       "description": "Links that should be accessible quickly from the home and search pages.",
       "type": "array",
@@ -172,6 +178,13 @@ const SettingsSchemaJSON = `{
         "description": "` + "`" + `true` + "`" + ` to enable the extension, ` + "`" + `false` + "`" + ` to disable the extension (if it was previously enabled)"
       }
     },
+    "extensions.trustedSigningPublicKeys": {
+      "description": "The signing public keys (base64-encoded DER ECDSA P-256 public keys) that signed extensions may be signed with. If set, a signed extension whose signing public key is not in this list is not activated. Only honored in global settings.",
+      "type": "array",
+      "items": {
+        "type": "string"
+      }
+    },
     "codeHost.useNativeTooltips": {
       "description": "Whether to use the code host's native hover tooltips when they exist (GitHub's jump-to-definition tooltips, for example).",
       "type": "boolean",
@@ -43,6 +43,27 @@ const SiteSchemaJSON = `{
       "group": "Search",
       "examples": [["go.sum", "package-lock.json", "*.thrift"]]
     },
+    "search.history": {
+      "description": "Configures the per-user search history, which records the searches users perform in the web app (the query, pattern type, result count and latency). Users can see their recent and frequent searches, and search suggestions include matching searches from their own history. Users can opt out with the ` + "`" + `search.recordHistory` + "`" + ` setting.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "description": "Whether searches are recorded in users' search history. If false, no new searches are recorded (existing search history is kept until it expires).",
+          "type": "boolean",
+          "default": true,
+          "!go": { "pointer": true }
+        },
+        "retentionDays": {
+          "description": "The number of days to keep search history entries. Older entries are deleted.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 90
+        }
+      },
+      "examples": [{ "enabled": true, "retentionDays": 30 }],
+      "group": "Search"
+    },
     "debug.search.symbolsParallelism": {
       "description": "(debug) controls the amount of symbol search parallelism. Defaults to 20. It is not recommended to change this outside of debugging scenarios. This option will be removed in a future version.",
       "type": "integer",
@@ -102,6 +123,12 @@ const SiteSchemaJSON = `{
           "enum": ["enabled", "disabled"],
           "default": "disabled"
         },
+        "searchResultsCache": {
+          "description": "Caches the results of repository, file and path searches. Cached results are keyed on the commits that are searched, so they are never stale.",
+          "type": "string",
+          "enum": ["enabled", "disabled"],
+          "default": "disabled"
+        },
         "searchMultipleRevisionsPerRepository": {
           "description": "Enables searching multiple revisions of the same repository (using ` + "`" + `repo:myrepo@branch1:branch2` + "`" + `).",
           "type": "boolean",
@@ -211,6 +238,23 @@ const SiteSchemaJSON = `{
       "default": false,
       "group": "Misc."
     },
+    "eventLogs.export": {
+      "description": "Sinks to export usage events (from the event_logs table) to, such as a data warehouse. Events are exported in batches, in order, at least once: after a failure, a batch is retried and may be delivered again.",
+      "type": "array",
+      "items": { "$ref": "#/definitions/EventLogsExportSink" },
+      "examples": [
+        [
+          {
+            "name": "warehouse",
+            "type": "http",
+            "url": "https://ingest.example.com/sourcegraph-events",
+            "headers": { "Authorization": "Bearer TOKEN" },
+            "redact": ["url", "anonymous_user_id"]
+          }
+        ]
+      ],
+      "group": "Misc."
+    },
     "disableAutoGitUpdates": {
       "description": "Disable periodically fetching git contents for existing repositories.",
       "type": "boolean",
@@ -643,6 +687,29 @@ const SiteSchemaJSON = `{
       "type": "string",
       "group": "Sourcegraph Enterprise license"
     },
+    "licensing.seatOverageGracePeriod": {
+      "description": "Allows new user accounts to be created for a limited time after the license's maximum user count is reached, instead of blocking them immediately. Site admins are alerted while the site exceeds its licensed seats. If not set, new user accounts are blocked when the maximum user count is reached (unless the license allows true-up).",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["days"],
+      "properties": {
+        "days": {
+          "description": "The number of days after the site first exceeds its licensed seats during which new user accounts can still be created.",
+          "type": "integer",
+          "minimum": 1,
+          "maximum": 90
+        },
+        "maxOveragePercent": {
+          "description": "The maximum number of user accounts beyond the licensed seats, as a percentage of the licensed seats (at least 1 user account is always allowed).",
+          "type": "integer",
+          "minimum": 1,
+          "maximum": 100,
+          "default": 10
+        }
+      },
+      "examples": [{ "days": 30, "maxOveragePercent": 10 }],
+      "group": "Sourcegraph Enterprise license"
+    },
     "auth.providers": {
       "description": "The authentication providers to use for identifying and signing in users. See instructions below for configuring SAML, OpenID Connect (including G Suite), and HTTP authentication proxies. Multiple authentication providers are supported (by specifying multiple elements in this array).",
       "type": "array",
@@ -651,7 +718,7 @@ const SiteSchemaJSON = `{
         "properties": {
           "type": {
             "type": "string",
-            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab"]
+            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"]
           }
         },
         "oneOf": [
@@ -660,7 +727,8 @@ const SiteSchemaJSON = `{
           { "$ref": "#/definitions/OpenIDConnectAuthProvider" },
           { "$ref": "#/definitions/HTTPHeaderAuthProvider" },
           { "$ref": "#/definitions/GitHubAuthProvider" },
-          { "$ref": "#/definitions/GitLabAuthProvider" }
+          { "$ref": "#/definitions/GitLabAuthProvider" },
+          { "$ref": "#/definitions/LDAPAuthProvider" }
         ],
         "!go": {
           "taggedUnionType": true
@@ -682,6 +750,20 @@ const SiteSchemaJSON = `{
       "examples": ["168h"],
       "group": "Authentication"
     },
+    "auth.twoFactor": {
+      "description": "Configures two-factor authentication (2FA) for builtin user accounts (with the ` + "`" + `builtin` + "`" + ` auth provider). Users can enroll TOTP authenticator apps and WebAuthn security keys on their two-factor authentication settings page. Storing TOTP secrets requires the ` + "`" + `SRC_TWO_FACTOR_ENCRYPTION_KEY` + "`" + ` environment variable to be set on the frontend.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "requireForSiteAdmins": {
+          "description": "Require site admins to sign in with a second factor. Site admins without a second factor are signed out and must enroll one when they next sign in with their password.",
+          "type": "boolean",
+          "default": false
+        }
+      },
+      "examples": [{ "requireForSiteAdmins": true }],
+      "group": "Authentication"
+    },
     "auth.enableUsernameChanges": {
       "description": "Enables users to change their username after account creation. Warning: setting this to be true has security implications if you have enabled (or will at any point in the future enable) repository permissions with an option that relies on username equivalency between Sourcegraph and an external service or authentication provider. Do NOT set this to true if you are using non-built-in authentication OR rely on username equivalency for repository permissions.",
       "type": "boolean",
@@ -704,6 +786,73 @@ const SiteSchemaJSON = `{
     }
   },
   "definitions": {
+    "EventLogsExportSink": {
+      "description": "A sink to export usage events to.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["name", "type"],
+      "properties": {
+        "name": {
+          "description": "A unique name for the sink. The export progress is recorded under this name, so renaming a sink exports all retained events again.",
+          "type": "string",
+          "pattern": "^[a-zA-Z0-9_.-]+$"
+        },
+        "type": {
+          "description": "The type of sink: \"http\" POSTs each batch as newline-delimited JSON to the URL, \"file\" appends events as newline-delimited JSON to a file (rotating it when it grows too large), and \"kafka\" produces each event to a topic with the Kafka REST Proxy API.",
+          "type": "string",
+          "enum": ["http", "file", "kafka"]
+        },
+        "url": {
+          "description": "For \"http\" sinks, the URL to POST batches to. For \"kafka\" sinks, the base URL of the Kafka REST Proxy.",
+          "type": "string",
+          "pattern": "^https?://"
+        },
+        "headers": {
+          "description": "For \"http\" and \"kafka\" sinks, additional HTTP headers to send (e.g., for authentication).",
+          "type": "object",
+          "additionalProperties": { "type": "string" }
+        },
+        "topic": {
+          "description": "For \"kafka\" sinks, the topic to produce events to.",
+          "type": "string"
+        },
+        "path": {
+          "description": "For \"file\" sinks, the path of the file to write events to. Rotated files are named PATH.1, PATH.2, and so on (oldest last). Any frontend replica may write a batch, so if there are multiple frontend replicas, the path must be on a volume that all of them share (or run only 1 replica).",
+          "type": "string"
+        },
+        "maxFileSizeMB": {
+          "description": "For \"file\" sinks, the size at which the file is rotated.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 100
+        },
+        "maxFiles": {
+          "description": "For \"file\" sinks, the number of rotated files to keep.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 5
+        },
+        "batchSize": {
+          "description": "The maximum number of events to export in a batch.",
+          "type": "integer",
+          "minimum": 1,
+          "maximum": 10000,
+          "default": 500
+        },
+        "fields": {
+          "description": "Maps the names of the fields in the exported events to event_logs fields (id, name, url, user_id, anonymous_user_id, source, argument, version and timestamp) or to properties of the event's argument (argument.PROPERTY). If not set, all event_logs fields are exported with their own names.",
+          "type": "object",
+          "additionalProperties": { "type": "string", "pattern": "^(id|name|url|user_id|anonymous_user_id|source|argument|version|timestamp|argument\\..+)$" },
+          "examples": [{ "event": "name", "user": "user_id", "time": "timestamp", "query": "argument.query" }]
+        },
+        "redact": {
+          "description": "The event_logs fields or argument properties (argument.PROPERTY) whose values are replaced with \"REDACTED\" before they are exported, to avoid exporting personally identifiable information.",
+          "type": "array",
+          "items": { "type": "string" },
+          "examples": [["url", "anonymous_user_id", "argument.query"]]
+        }
+      }
+    },
     "BrandAssets": {
       "type": "object",
       "properties": {
@@ -937,6 +1086,100 @@ const SiteSchemaJSON = `{
         "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" }
       }
     },
+    "LDAPAuthProvider": {
+      "description": "Configures the LDAP authentication provider, which signs users in with the username and password of their account on an LDAP server (such as Active Directory).",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "url", "userSearchBase"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "ldap"
+        },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" },
+        "configID": {
+          "description": "An identifier that can be used to reference this authentication provider in other parts of the config. For example, in configuration for a code host, you may want to designate this authentication provider as the identity provider for the code host.",
+          "type": "string"
+        },
+        "url": {
+          "description": "The URL of the LDAP server. Use the ldaps:// scheme for LDAP over TLS, or the ldap:// scheme (optionally with ` + "`" + `startTLS` + "`" + `).",
+          "type": "string",
+          "pattern": "^ldaps?://",
+          "examples": ["ldaps://ldap.example.com", "ldap://ldap.example.com:389"]
+        },
+        "startTLS": {
+          "description": "Upgrade ldap:// connections to TLS with the StartTLS operation before sending credentials.",
+          "type": "boolean",
+          "default": false
+        },
+        "insecureSkipVerify": {
+          "description": "Whether to (insecurely) skip verification of the LDAP server's TLS certificate.",
+          "type": "boolean",
+          "default": false
+        },
+        "bindDN": {
+          "description": "The DN of the service account used to search for users and groups. If not set, searches are performed anonymously.",
+          "type": "string",
+          "examples": ["cn=sourcegraph,ou=services,dc=example,dc=com"]
+        },
+        "bindPassword": {
+          "description": "The password of the service account (` + "`" + `bindDN` + "`" + `).",
+          "type": "string"
+        },
+        "userSearchBase": {
+          "description": "The DN of the subtree to search for users in.",
+          "type": "string",
+          "examples": ["ou=people,dc=example,dc=com"]
+        },
+        "userSearchFilter": {
+          "description": "The LDAP filter that finds the user signing in. \"{username}\" is replaced with the username entered on the sign-in page. The filter must match exactly one user.",
+          "type": "string",
+          "default": "(uid={username})",
+          "examples": ["(sAMAccountName={username})", "(&(objectClass=person)(|(uid={username})(mail={username})))"]
+        },
+        "usernameAttribute": {
+          "description": "The attribute of the user entry that holds the user's username, which is normalized to a Sourcegraph username.",
+          "type": "string",
+          "default": "uid",
+          "examples": ["sAMAccountName"]
+        },
+        "emailAttribute": {
+          "description": "The attribute of the user entry that holds the user's email address. The email address is considered verified.",
+          "type": "string",
+          "default": "mail"
+        },
+        "displayNameAttribute": {
+          "description": "The attribute of the user entry that holds the user's display name.",
+          "type": "string",
+          "default": "cn",
+          "examples": ["displayName"]
+        },
+        "groupSearchBase": {
+          "description": "The DN of the subtree to search for the user's groups in. Group memberships are only synced to organizations (see ` + "`" + `groupOrgs` + "`" + `) if this is set.",
+          "type": "string",
+          "examples": ["ou=groups,dc=example,dc=com"]
+        },
+        "groupSearchFilter": {
+          "description": "The LDAP filter that finds the groups of the user signing in. \"{dn}\" is replaced with the DN of the user's entry and \"{username}\" with the value of the user's ` + "`" + `usernameAttribute` + "`" + `.",
+          "type": "string",
+          "default": "(member={dn})",
+          "examples": ["(memberUid={username})", "(|(member={dn})(uniqueMember={dn}))"]
+        },
+        "groupNameAttribute": {
+          "description": "The attribute of group entries that holds the group's name, as used in ` + "`" + `groupOrgs` + "`" + `.",
+          "type": "string",
+          "default": "cn"
+        },
+        "groupOrgs": {
+          "description": "Maps LDAP group names to the names of Sourcegraph organizations. When a user signs in, they are added to the organizations of their groups and removed from the other organizations in this map. Organizations that don't exist are created.",
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          },
+          "examples": [{ "engineering": "eng", "sourcegraph-admins": "admins" }]
+        }
+      }
+    },
     "AuthProviderCommon": {
       "$comment": "This schema is not used directly. The *AuthProvider schemas refer to its properties directly.",
       "description": "Common properties for authentication providers.",
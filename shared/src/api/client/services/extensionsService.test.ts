@@ -97,6 +97,41 @@ describe('activeExtensions', () => {
             } as Record<string, ExecutableExtension[]>)
         ))
 
+    test('does not activate unsigned extensions when trusted signing public keys are set', () =>
+        scheduler().run(({ cold, expectObservable }) =>
+            expectObservable(
+                from(
+                    new TestExtensionsService(
+                        [{ id: 'x', manifest, rawManifest: null }],
+                        {
+                            activeLanguages: cold<ReadonlySet<string>>('-a-|', {
+                                a: new Set(['x']),
+                            }),
+                        },
+                        {
+                            data: cold<SettingsCascadeOrError>('-a-|', {
+                                a: {
+                                    final: { extensions: { x: true } },
+                                    subjects: [
+                                        {
+                                            subject: { __typename: 'Site', id: 's', viewerCanAdminister: false },
+                                            settings: { 'extensions.trustedSigningPublicKeys': ['k'] },
+                                            lastID: null,
+                                        },
+                                    ],
+                                },
+                            }),
+                        },
+                        enabledExtensions => enabledExtensions,
+                        cold('-a-|', { a: '' }),
+                        () => of(null)
+                    ).activeExtensions
+                )
+            ).toBe('-a-|', {
+                a: [],
+            })
+        ))
+
     test('fetches a sideloaded extension and adds it to the set of registry extensions', () => {
         scheduler().run(({ cold, expectObservable }) => {
             expectObservable(
@@ -17,6 +17,7 @@ import { ModelService } from './modelService'
 import { checkOk } from '../../../backend/fetch'
 import { ExtensionManifest } from '../../../schema/extensionSchema'
 import { fromFetch } from '../../../graphql/fromFetch'
+import { getTrustedSigningPublicKeys, verifyExtensionBundle } from '../../../extensions/bundleSignature'
 
 /**
  * The information about an extension necessary to execute and activate it.
@@ -125,6 +126,10 @@ export class ExtensionsService {
         // Extensions that have been activated (including extensions with zero "activationEvents" that evaluate to
         // true currently).
         const activatedExtensionIDs = new Set<string>()
+        const trustedSigningPublicKeys = from(this.settingsService.data).pipe(
+            map(getTrustedSigningPublicKeys),
+            distinctUntilChanged(isEqual)
+        )
         return combineLatest([from(this.modelService.activeLanguages), this.enabledExtensions]).pipe(
             tap(([activeLanguages, enabledExtensions]) => {
                 const activeExtensions = this.extensionActivationFilter(enabledExtensions, activeLanguages)
@@ -139,7 +144,31 @@ export class ExtensionsService {
             switchMap(extensions =>
                 combineLatestOrDefault(
                     extensions.map(x =>
-                        this.memoizedGetScriptURLForExtension(getScriptURLFromExtensionManifest(x)).pipe(
+                        combineLatest([
+                            this.memoizedGetScriptURLForExtension(getScriptURLFromExtensionManifest(x)),
+                            trustedSigningPublicKeys,
+                        ]).pipe(
+                            switchMap(([scriptURL, trustedKeys]) => {
+                                if (scriptURL === null) {
+                                    return of(null)
+                                }
+                                if (x.manifest && !isErrorLike(x.manifest) && x.manifest.signature) {
+                                    return this.memoizedVerifyExtensionBundle({
+                                        scriptURL,
+                                        signature: x.manifest.signature,
+                                        trustedKeys,
+                                    })
+                                }
+                                if (trustedKeys) {
+                                    // Only run extensions signed with a trusted key (a registry or mirror could
+                                    // strip the signature from the manifest).
+                                    console.error(
+                                        `Refusing to activate unsigned extension ${x.id} because extensions.trustedSigningPublicKeys is set`
+                                    )
+                                    return of(null)
+                                }
+                                return of(scriptURL)
+                            }),
                             map(scriptURL =>
                                 scriptURL === null
                                     ? null
@@ -168,6 +197,30 @@ export class ExtensionsService {
             ),
         url => url
     )
+
+    /**
+     * Verifies the signature of a signed extension's bundle before the extension is activated. It emits a blob URL
+     * of the verified bundle if the signature is valid and made with a trusted key, or null otherwise (so that the
+     * extension is not activated).
+     */
+    private memoizedVerifyExtensionBundle = memoizeObservable<
+        {
+            scriptURL: string
+            signature: NonNullable<ExtensionManifest['signature']>
+            trustedKeys: string[] | undefined
+        },
+        string | null
+    >(
+        ({ scriptURL, signature, trustedKeys }) =>
+            from(verifyExtensionBundle(scriptURL, signature, trustedKeys)).pipe(
+                catchError(err => {
+                    console.error(`Refusing to activate extension with unverified bundle ${scriptURL}`, err)
+                    return [null]
+                })
+            ),
+        ({ scriptURL, signature, trustedKeys }) =>
+            `${scriptURL}:${signature.bundleSignature}:${(trustedKeys || []).join(',')}`
+    )
 }
 
 function asObservable(input: string | ObservableInput<string>): Observable<string> {
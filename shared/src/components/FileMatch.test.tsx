@@ -5,7 +5,7 @@ import { cleanup, getAllByTestId, getByTestId, render } from '@testing-library/r
 import _VisibilitySensor from 'react-visibility-sensor'
 import sinon from 'sinon'
 import { MockVisibilitySensor } from './CodeExcerpt.test'
-import { FileMatch, IFileMatch } from './FileMatch'
+import { FileMatch, IFileMatch, multilineMatchItems } from './FileMatch'
 import { HIGHLIGHTED_FILE_LINES_REQUEST, NOOP_SETTINGS_CASCADE, RESULT } from '../util/searchTestHelpers'
 
 jest.mock('react-visibility-sensor', (): typeof _VisibilitySensor => ({ children, onChange }) => (
@@ -35,3 +35,26 @@ describe('FileMatch', () => {
         expect(getAllByTestId(container, 'result-container').length).toBe(1)
     })
 })
+
+describe('multilineMatchItems', () => {
+    it('highlights the part of each line covered by the match', () => {
+        expect(
+            multilineMatchItems({
+                preview: 'func main() {\n\tfmt.Println()',
+                range: { start: { line: 2, character: 5 }, end: { line: 3, character: 4 } },
+            })
+        ).toStrictEqual([
+            { highlightRanges: [{ start: 5, highlightLength: 8 }], preview: 'func main() {', line: 2 },
+            { highlightRanges: [{ start: 0, highlightLength: 4 }], preview: '\tfmt.Println()', line: 3 },
+        ])
+    })
+
+    it('ends at the end of the last line of a match ending with a newline', () => {
+        expect(
+            multilineMatchItems({
+                preview: 'foo',
+                range: { start: { line: 0, character: 1 }, end: { line: 1, character: 0 } },
+            })
+        ).toStrictEqual([{ highlightRanges: [{ start: 1, highlightLength: 2 }], preview: 'foo', line: 0 }])
+    })
+})
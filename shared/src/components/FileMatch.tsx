@@ -16,12 +16,34 @@ export type IFileMatch = Partial<Pick<GQL.IFileMatch, 'revSpec' | 'symbols' | 'l
     file: Pick<GQL.IFile, 'path' | 'url'> & { commit: Pick<GQL.IGitCommit, 'oid'> }
     repository: Pick<GQL.IRepository, 'name' | 'url'>
     lineMatches: ILineMatch[]
+    multilineMatches?: IMultilineMatch[]
 }
 
 export type ILineMatch = Pick<GQL.ILineMatch, 'preview' | 'lineNumber' | 'offsetAndLengths' | 'limitHit'> & {
     badge?: BadgeAttachmentRenderOptions
 }
 
+export type IMultilineMatch = Pick<GQL.IMultilineMatch, 'preview'> & {
+    range: { start: Pick<GQL.IPosition, 'line' | 'character'>; end: Pick<GQL.IPosition, 'line' | 'character'> }
+}
+
+/**
+ * Splits a match which may span multiple lines into one item per line, highlighting the part of
+ * each line that the match covers.
+ */
+export const multilineMatchItems = ({ preview, range: { start, end } }: IMultilineMatch): IMatchItem[] =>
+    // The preview doesn't include the line after a match ending with a newline.
+    preview.split('\n').map((linePreview, index) => {
+        const line = start.line + index
+        const highlightStart = line === start.line ? start.character : 0
+        const highlightEnd = line === end.line ? end.character : linePreview.length
+        return {
+            highlightRanges: [{ start: highlightStart, highlightLength: highlightEnd - highlightStart }],
+            preview: linePreview,
+            line,
+        }
+    })
+
 export interface IMatchItem {
     highlightRanges: {
         start: number
@@ -95,6 +117,9 @@ export class FileMatch extends React.PureComponent<Props> {
             line: m.lineNumber,
             badge: m.badge,
         }))
+        for (const match of this.props.result.multilineMatches || []) {
+            items.push(...multilineMatchItems(match))
+        }
 
         const { repoAtRevURL, revDisplayName } =
             result.revSpec?.__typename === 'GitRevSpecExpr' && result.revSpec.object?.commit
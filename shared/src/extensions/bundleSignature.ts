@@ -0,0 +1,70 @@
+import { ExtensionManifest } from '../schema/extensionSchema'
+import { SettingsCascadeOrError } from '../settings/settings'
+import { isErrorLike } from '../util/errors'
+
+function base64ToBuffer(value: string): ArrayBuffer {
+    const binary = atob(value)
+    const bytes = new Uint8Array(binary.length)
+    for (let i = 0; i < binary.length; i++) {
+        bytes[i] = binary.charCodeAt(i)
+    }
+    return bytes.buffer
+}
+
+/**
+ * Returns the extension signing public keys that site admins trust, from the
+ * `extensions.trustedSigningPublicKeys` global settings, or undefined if none are set. Only the global settings
+ * are used, so that users and organizations can't add trusted keys.
+ */
+export function getTrustedSigningPublicKeys(settings: SettingsCascadeOrError): string[] | undefined {
+    const site = settings.subjects?.find(({ subject }) => subject.__typename === 'Site')
+    if (!site || !site.settings || isErrorLike(site.settings)) {
+        return undefined
+    }
+    const keys: unknown = site.settings['extensions.trustedSigningPublicKeys']
+    if (!Array.isArray(keys) || keys.length === 0) {
+        return undefined
+    }
+    return keys.filter((key): key is string => typeof key === 'string')
+}
+
+/**
+ * Fetches the extension bundle at the given URL and verifies it with the signature from the extension manifest
+ * (which the extension registry adds if the extension's publisher signs its releases). If trusted signing public
+ * keys are given, the signature's public key must be one of them.
+ *
+ * @returns A blob URL of the verified bundle, so that the bytes that were verified are the bytes that are
+ * executed (even if the bundle at the given URL changes after it was verified).
+ * @throws If the key is not trusted, or the bundle can't be fetched or its signature is invalid.
+ */
+export async function verifyExtensionBundle(
+    scriptURL: string,
+    signature: NonNullable<ExtensionManifest['signature']>,
+    trustedSigningPublicKeys?: string[]
+): Promise<string> {
+    if (trustedSigningPublicKeys && !trustedSigningPublicKeys.includes(signature.publicKey)) {
+        throw new Error('extension bundle is signed with a key that is not in extensions.trustedSigningPublicKeys')
+    }
+    const response = await fetch(scriptURL, { credentials: 'include' })
+    if (!response.ok) {
+        throw new Error(`fetching extension bundle failed with HTTP status ${response.status}`)
+    }
+    const bundle = await response.arrayBuffer()
+    const key = await crypto.subtle.importKey(
+        'spki',
+        base64ToBuffer(signature.publicKey),
+        { name: 'ECDSA', namedCurve: 'P-256' },
+        false,
+        ['verify']
+    )
+    const valid = await crypto.subtle.verify(
+        { name: 'ECDSA', hash: { name: 'SHA-256' } },
+        key,
+        base64ToBuffer(signature.bundleSignature),
+        bundle
+    )
+    if (!valid) {
+        throw new Error('extension bundle signature is invalid')
+    }
+    return URL.createObjectURL(new Blob([bundle], { type: 'application/javascript' }))
+}
@@ -23,4 +23,8 @@ describe('parseExtensionManifestOrError', () => {
         const value = parseExtensionManifestOrError('{"tags":[1]}')
         expect(isErrorLike(value)).toBeTruthy()
     })
+    test('invalid signature', () => {
+        const value = parseExtensionManifestOrError('{"url":"a","activationEvents":["*"],"signature":{"publicKey":1}}')
+        expect(isErrorLike(value)).toBeTruthy()
+    })
 })
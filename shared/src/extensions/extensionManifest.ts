@@ -15,6 +15,7 @@ export type ExtensionManifest = Pick<
     | 'tags'
     | 'readme'
     | 'url'
+    | 'signature'
     | 'icon'
     | 'activationEvents'
     | 'contributes'
@@ -65,6 +66,15 @@ export function parseExtensionManifestOrError(input: string): ExtensionManifest
         } else if (typeof value.url !== 'string') {
             problems.push('"url" property must be a string')
         }
+        if (value.signature) {
+            if (
+                !isPlainObject(value.signature) ||
+                typeof value.signature.publicKey !== 'string' ||
+                typeof value.signature.bundleSignature !== 'string'
+            ) {
+                problems.push('"signature" property must be an object with string "publicKey" and "bundleSignature"')
+            }
+        }
         if (!value.activationEvents) {
             problems.push('"activationEvents" property must be set')
         } else if (!Array.isArray(value.activationEvents)) {
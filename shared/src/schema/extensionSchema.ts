@@ -37,6 +37,17 @@ export interface ExtensionManifest {
     description?: string
     readme?: string
     url: string
+
+    /**
+     * The signature of the bundle at {@link ExtensionManifest#url}, set by the extension registry if the
+     * extension's publisher signs its releases.
+     */
+    signature?: {
+        /** The base64-encoded DER (PKIX) ECDSA P-256 public key. */
+        publicKey: string
+        /** The base64-encoded ECDSA P-256 (SHA-256) signature of the bundle, in IEEE P1363 form. */
+        bundleSignature: string
+    }
     repository?: {
         type?: string
         url: string
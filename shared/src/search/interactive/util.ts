@@ -39,6 +39,14 @@ export enum FilterType {
     content = 'content',
     patterntype = 'patterntype',
     index = 'index',
+    owner = 'owner',
+    blameauthor = 'blameauthor',
+    blameafter = 'blameafter',
+    blamebefore = 'blamebefore',
+    multiline = 'multiline',
+    refs = 'refs',
+    merges = 'merges',
+    firstparent = 'firstparent',
 }
 
 export const isFilterType = (filter: string): filter is FilterType => filter in FilterType
@@ -53,10 +61,20 @@ export enum NegatedFilters {
     f = '-f',
     l = '-l',
     repohasfile = '-repohasfile',
+    owner = '-owner',
+    blameauthor = '-blameauthor',
+    refs = '-refs',
 }
 
 /** The list of filters that are able to be negated. */
-export type NegatableFilter = FilterType.repo | FilterType.file | FilterType.repohasfile | FilterType.lang
+export type NegatableFilter =
+    | FilterType.repo
+    | FilterType.file
+    | FilterType.repohasfile
+    | FilterType.lang
+    | FilterType.owner
+    | FilterType.blameauthor
+    | FilterType.refs
 
 export const isNegatableFilter = (filter: FilterType): filter is NegatableFilter =>
     Object.keys(NegatedFilters).includes(filter)
@@ -75,6 +93,9 @@ const negatedFilterToNegatableFilter: { [key: string]: NegatableFilter } = {
     '-f': FilterType.file,
     '-l': FilterType.lang,
     '-repohasfile': FilterType.repohasfile,
+    '-owner': FilterType.owner,
+    '-blameauthor': FilterType.blameauthor,
+    '-refs': FilterType.refs,
 }
 
 export const resolveNegatedFilter = (filter: NegatedFilters): NegatableFilter => negatedFilterToNegatableFilter[filter]
@@ -35,17 +35,28 @@ describe('getCompletionItems()', () => {
             'archived',
             'author',
             'before',
+            'blameafter',
+            'blameauthor',
+            '-blameauthor',
+            'blamebefore',
             'case',
             'content',
             'count',
             'file',
             '-file',
+            'firstparent',
             'fork',
             'index',
             'lang',
             '-lang',
+            'merges',
             'message',
+            'multiline',
+            'owner',
+            '-owner',
             'patterntype',
+            'refs',
+            '-refs',
             'repo',
             '-repo',
             'repogroup',
@@ -89,17 +100,28 @@ describe('getCompletionItems()', () => {
             'archived',
             'author',
             'before',
+            'blameafter',
+            'blameauthor',
+            '-blameauthor',
+            'blamebefore',
             'case',
             'content',
             'count',
             'file',
             '-file',
+            'firstparent',
             'fork',
             'index',
             'lang',
             '-lang',
+            'merges',
             'message',
+            'multiline',
+            'owner',
+            '-owner',
             'patterntype',
+            'refs',
+            '-refs',
             'repo',
             '-repo',
             'repogroup',
@@ -124,17 +146,28 @@ describe('getCompletionItems()', () => {
             'archived',
             'author',
             'before',
+            'blameafter',
+            'blameauthor',
+            '-blameauthor',
+            'blamebefore',
             'case',
             'content',
             'count',
             'file',
             '-file',
+            'firstparent',
             'fork',
             'index',
             'lang',
             '-lang',
+            'merges',
             'message',
+            'multiline',
+            'owner',
+            '-owner',
             'patterntype',
+            'refs',
+            '-refs',
             'repo',
             '-repo',
             'repogroup',
@@ -166,17 +199,28 @@ describe('getCompletionItems()', () => {
             'archived',
             'author',
             'before',
+            'blameafter',
+            'blameauthor',
+            '-blameauthor',
+            'blamebefore',
             'case',
             'content',
             'count',
             'file',
             '-file',
+            'firstparent',
             'fork',
             'index',
             'lang',
             '-lang',
+            'merges',
             'message',
+            'multiline',
+            'owner',
+            '-owner',
             'patterntype',
+            'refs',
+            '-refs',
             'repo',
             '-repo',
             'repogroup',
@@ -204,17 +248,28 @@ describe('getCompletionItems()', () => {
             'archived',
             'author',
             'before',
+            'blameafter',
+            'blameauthor',
+            '-blameauthor',
+            'blamebefore',
             'case',
             'content',
             'count',
             'file',
             '-file',
+            'firstparent',
             'fork',
             'index',
             'lang',
             '-lang',
+            'merges',
             'message',
+            'multiline',
+            'owner',
+            '-owner',
             'patterntype',
+            'refs',
+            '-refs',
             'repo',
             '-repo',
             'repogroup',
@@ -66,6 +66,19 @@ export const FILTERS: Record<NegatableFilter, NegatableFilterDefinition> &
     [FilterType.before]: {
         description: 'Commits made before a certain date',
     },
+    [FilterType.blameafter]: {
+        description: 'Lines last changed after a certain date',
+        singular: true,
+    },
+    [FilterType.blameauthor]: {
+        negatable: true,
+        description: negated =>
+            `${negated ? 'Exclude' : 'Include only'} lines last changed by an author matching the given regex pattern`,
+    },
+    [FilterType.blamebefore]: {
+        description: 'Lines last changed before a certain date',
+        singular: true,
+    },
     [FilterType.case]: {
         description: 'Treat the search pattern as case-sensitive.',
         discreteValues: ['yes', 'no'],
@@ -88,6 +101,12 @@ export const FILTERS: Record<NegatableFilter, NegatableFilterDefinition> &
             `${negated ? 'Exclude' : 'Include only'} results from files matching the given regex pattern.`,
         suggestions: 'File',
     },
+    [FilterType.firstparent]: {
+        description: 'Only follow the first parent of merge commits in commit and diff searches.',
+        discreteValues: ['yes', 'no'],
+        default: 'no',
+        singular: true,
+    },
     [FilterType.fork]: {
         discreteValues: ['yes', 'no', 'only'],
         description: 'Include results from forked repositories.',
@@ -103,14 +122,35 @@ export const FILTERS: Record<NegatableFilter, NegatableFilterDefinition> &
         description: negated => `${negated ? 'Exclude' : 'Include only'} results from the given language`,
         suggestions: LANGUAGES,
     },
+    [FilterType.merges]: {
+        discreteValues: ['yes', 'no', 'only'],
+        description: 'Include merge commits in commit and diff searches.',
+        singular: true,
+    },
     [FilterType.message]: {
         description: 'Commits with messages matching a certain string',
     },
+    [FilterType.multiline]: {
+        description: 'Return matches which span multiple lines as a single match.',
+        discreteValues: ['yes', 'no'],
+        default: 'no',
+        singular: true,
+    },
+    [FilterType.owner]: {
+        negatable: true,
+        description: negated =>
+            `${negated ? 'Exclude' : 'Include only'} results from files owned by the given user or team`,
+    },
     [FilterType.patterntype]: {
         discreteValues: ['regexp', 'literal', 'structural'],
         description: 'The pattern type (regexp, literal, structural) in use',
         singular: true,
     },
+    [FilterType.refs]: {
+        negatable: true,
+        description: negated =>
+            `${negated ? 'Exclude' : 'Include'} commits from the refs matching the given glob pattern`,
+    },
     [FilterType.repo]: {
         alias: 'r',
         negatable: true,
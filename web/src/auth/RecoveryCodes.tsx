@@ -0,0 +1,17 @@
+import * as React from 'react'
+
+/**
+ * Shows newly generated two-factor authentication recovery codes, which are only shown once.
+ */
+export const RecoveryCodes: React.FunctionComponent<{ codes: string[]; className?: string }> = ({
+    codes,
+    className = '',
+}) => (
+    <div className={`alert alert-warning ${className}`}>
+        <p>
+            Save these recovery codes in a safe place. Each code can be used once to sign in if you lose access to
+            your second factor. They will not be shown again.
+        </p>
+        <pre className="mb-0 e2e-recovery-codes">{codes.join('\n')}</pre>
+    </div>
+)
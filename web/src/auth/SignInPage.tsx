@@ -35,6 +35,15 @@ export const SignInPage: React.FunctionComponent<SignInPageProps> = props => {
                             {window.context.authProviders.map((provider, i) =>
                                 provider.isBuiltin ? (
                                     <UsernamePasswordSignInForm key={i} {...props} />
+                                ) : provider.serviceType === 'ldap' && provider.authenticationURL ? (
+                                    <UsernamePasswordSignInForm
+                                        key={i}
+                                        {...props}
+                                        ldapProvider={{
+                                            displayName: provider.displayName,
+                                            authenticationURL: provider.authenticationURL,
+                                        }}
+                                    />
                                 ) : (
                                     <div className="mb-2">
                                         <a key={i} href={provider.authenticationURL} className="btn btn-secondary">
@@ -0,0 +1,213 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import * as React from 'react'
+import { Form } from '../components/Form'
+import { ErrorAlert } from '../components/alerts'
+import { asError } from '../../../shared/src/util/errors'
+import { RecoveryCodes } from './RecoveryCodes'
+import { createWebAuthnCredential, getWebAuthnAssertion, isWebAuthnSupported } from './webAuthn'
+
+/**
+ * The response to a sign-in request whose password was correct but that still requires two-factor
+ * authentication.
+ */
+export interface TwoFactorChallenge {
+    /**
+     * "verify" if the user must verify one of their second factors, or "enroll" if the user must
+     * enroll a second factor (because the site requires it).
+     */
+    twoFactor: 'verify' | 'enroll'
+    methods?: ('totp' | 'webauthn' | 'recoveryCode')[]
+}
+
+interface Props {
+    challenge: TwoFactorChallenge
+
+    /** Called after the session is authenticated. */
+    onSignedIn: () => void
+}
+
+interface State {
+    code: string
+    useRecoveryCode: boolean
+    totpEnrollment?: { secret: string; uri: string }
+    recoveryCodes?: string[]
+    error?: Error
+    loading: boolean
+}
+
+const postJSON = async (url: string, body: object): Promise<any> => {
+    const resp = await fetch(url, {
+        credentials: 'same-origin',
+        method: 'POST',
+        headers: {
+            ...window.context.xhrHeaders,
+            Accept: 'application/json',
+            'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(body),
+    })
+    if (resp.status !== 200) {
+        throw new Error((await resp.text()) || 'Unknown Error')
+    }
+    return resp.json()
+}
+
+/**
+ * The second step of signing in with a username and password, in which the user verifies a second
+ * factor (or enrolls one, if the site requires it).
+ */
+export class TwoFactorSignInForm extends React.Component<Props, State> {
+    public state: State = { code: '', useRecoveryCode: false, loading: false }
+
+    public render(): JSX.Element | null {
+        if (this.state.recoveryCodes) {
+            return (
+                <div className="signin-signup-form">
+                    <p>Two-factor authentication is now enabled for your account.</p>
+                    <RecoveryCodes codes={this.state.recoveryCodes} />
+                    <button type="button" className="btn btn-primary btn-block" onClick={this.props.onSignedIn}>
+                        Continue
+                    </button>
+                </div>
+            )
+        }
+
+        const methods = this.props.challenge.methods || []
+        const enroll = this.props.challenge.twoFactor === 'enroll'
+        const canUseTOTP = enroll ? !!this.state.totpEnrollment : methods.includes('totp') || this.state.useRecoveryCode
+        return (
+            <Form className="signin-signup-form signin-form e2e-two-factor-form" onSubmit={this.handleSubmit}>
+                {enroll ? (
+                    <p>
+                        Two-factor authentication is required for your account. Set up an authenticator app or a
+                        security key to continue.
+                    </p>
+                ) : (
+                    <p>Two-factor authentication is enabled for your account.</p>
+                )}
+                {this.state.error && <ErrorAlert className="my-2" error={this.state.error} icon={false} />}
+                {enroll && !this.state.totpEnrollment && (
+                    <div className="form-group">
+                        <button
+                            type="button"
+                            className="btn btn-secondary btn-block"
+                            onClick={this.beginTOTPEnrollment}
+                            disabled={this.state.loading}
+                        >
+                            Set up an authenticator app
+                        </button>
+                    </div>
+                )}
+                {this.state.totpEnrollment && (
+                    <div className="form-group">
+                        <p>
+                            Add this account to your authenticator app by opening{' '}
+                            <a href={this.state.totpEnrollment.uri}>this link</a> on your phone or entering the key{' '}
+                            <code className="e2e-totp-secret">{this.state.totpEnrollment.secret}</code>, then enter the
+                            6-digit code that it shows.
+                        </p>
+                    </div>
+                )}
+                {canUseTOTP && (
+                    <>
+                        <div className="form-group">
+                            <input
+                                className="form-control signin-signup-form__input"
+                                type="text"
+                                placeholder={this.state.useRecoveryCode ? 'Recovery code' : '6-digit code'}
+                                onChange={this.onCodeFieldChange}
+                                required={true}
+                                value={this.state.code}
+                                disabled={this.state.loading}
+                                autoCapitalize="off"
+                                autoFocus={true}
+                                autoComplete="one-time-code"
+                                inputMode={this.state.useRecoveryCode ? 'text' : 'numeric'}
+                            />
+                        </div>
+                        <div className="form-group">
+                            <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
+                                Verify
+                            </button>
+                        </div>
+                    </>
+                )}
+                {(enroll || methods.includes('webauthn')) && isWebAuthnSupported() && !this.state.useRecoveryCode && (
+                    <div className="form-group">
+                        <button
+                            type="button"
+                            className="btn btn-secondary btn-block"
+                            onClick={this.useSecurityKey}
+                            disabled={this.state.loading}
+                        >
+                            {enroll ? 'Register a security key' : 'Use a security key'}
+                        </button>
+                    </div>
+                )}
+                {!enroll && methods.includes('recoveryCode') && (
+                    <small className="form-text text-muted">
+                        <button type="button" className="btn btn-link p-0" onClick={this.toggleRecoveryCode}>
+                            {this.state.useRecoveryCode ? 'Use your second factor' : 'Use a recovery code'}
+                        </button>
+                    </small>
+                )}
+                {this.state.loading && (
+                    <div className="w-100 text-center mb-2">
+                        <LoadingSpinner className="icon-inline" />
+                    </div>
+                )}
+            </Form>
+        )
+    }
+
+    private onCodeFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
+        this.setState({ code: e.target.value })
+    }
+
+    private toggleRecoveryCode = (): void => {
+        this.setState(state => ({ useRecoveryCode: !state.useRecoveryCode, code: '', error: undefined }))
+    }
+
+    private beginTOTPEnrollment = (): void => {
+        this.run(async () => {
+            const totpEnrollment = await postJSON('/-/sign-in/2fa/totp-enroll', {})
+            this.setState({ totpEnrollment, loading: false })
+        })
+    }
+
+    private useSecurityKey = (): void => {
+        const enroll = this.props.challenge.twoFactor === 'enroll'
+        this.run(async () => {
+            const options = JSON.stringify(await postJSON('/-/sign-in/2fa/webauthn-options', {}))
+            const credential = enroll ? await createWebAuthnCredential(options) : await getWebAuthnAssertion(options)
+            await this.verify({ method: 'webauthn', credential: JSON.parse(credential), name: 'Security key' })
+        })
+    }
+
+    private handleSubmit = (event: React.FormEvent<HTMLFormElement>): void => {
+        event.preventDefault()
+        if (this.state.loading) {
+            return
+        }
+        this.run(() =>
+            this.verify({ method: this.state.useRecoveryCode ? 'recoveryCode' : 'totp', code: this.state.code })
+        )
+    }
+
+    private async verify(body: object): Promise<void> {
+        const { recoveryCodes } = await postJSON('/-/sign-in/2fa', body)
+        if (recoveryCodes && recoveryCodes.length > 0) {
+            this.setState({ recoveryCodes, loading: false })
+        } else {
+            this.props.onSignedIn()
+        }
+    }
+
+    private run(f: () => Promise<void>): void {
+        this.setState({ loading: true, error: undefined })
+        f().catch(error => {
+            console.error('Two-factor authentication error:', error)
+            this.setState({ loading: false, error: asError(error) })
+        })
+    }
+}
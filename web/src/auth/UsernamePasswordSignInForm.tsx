@@ -7,10 +7,20 @@ import { eventLogger } from '../tracking/eventLogger'
 import { getReturnTo, PasswordInput } from './SignInSignUpCommon'
 import { ErrorAlert } from '../components/alerts'
 import { asError } from '../../../shared/src/util/errors'
+import { TwoFactorChallenge, TwoFactorSignInForm } from './TwoFactorSignInForm'
 
 interface Props {
     location: H.Location
     history: H.History
+
+    /**
+     * If set, the form signs in with the username and password of an account on this LDAP server
+     * instead of a builtin Sourcegraph account.
+     */
+    ldapProvider?: {
+        displayName: string
+        authenticationURL: string
+    }
 }
 
 interface State {
@@ -18,10 +28,14 @@ interface State {
     password: string
     error?: Error
     loading: boolean
+
+    /** Set if the password was correct but the user must still perform two-factor authentication. */
+    twoFactorChallenge?: TwoFactorChallenge
 }
 
 /**
- * The form for signing in with a username and password.
+ * The form for signing in with a username and password (of a builtin account, or an account on an
+ * LDAP server).
  */
 export class UsernamePasswordSignInForm extends React.Component<Props, State> {
     constructor(props: Props) {
@@ -34,9 +48,14 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
     }
 
     public render(): JSX.Element | null {
+        if (this.state.twoFactorChallenge) {
+            return <TwoFactorSignInForm challenge={this.state.twoFactorChallenge} onSignedIn={this.onSignedIn} />
+        }
         return (
             <Form className="signin-signup-form signin-form e2e-signin-form" onSubmit={this.handleSubmit}>
-                {window.context.allowSignup ? (
+                {this.props.ldapProvider ? (
+                    <p className="text-muted">Sign in with your {this.props.ldapProvider.displayName} account.</p>
+                ) : window.context.allowSignup ? (
                     <p>
                         <Link to={`/sign-up${this.props.location.search}`}>Don't have an account? Sign up.</Link>
                     </p>
@@ -48,14 +67,14 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                     <input
                         className="form-control signin-signup-form__input"
                         type="text"
-                        placeholder="Username or email"
+                        placeholder={this.props.ldapProvider ? 'Username' : 'Username or email'}
                         onChange={this.onEmailFieldChange}
                         required={true}
                         value={this.state.email}
                         disabled={this.state.loading}
                         autoCapitalize="off"
                         autoFocus={true}
-                        autoComplete="username email"
+                        autoComplete={this.props.ldapProvider ? 'username' : 'username email'}
                     />
                 </div>
                 <div className="form-group">
@@ -72,7 +91,7 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                     <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
                         Sign in
                     </button>
-                    {window.context.resetPasswordEnabled && (
+                    {window.context.resetPasswordEnabled && !this.props.ldapProvider && (
                         <small className="form-text text-muted">
                             <Link to="/password-reset">Forgot password?</Link>
                         </small>
@@ -95,6 +114,15 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
         this.setState({ password: e.target.value })
     }
 
+    private onSignedIn = (): void => {
+        if (new URLSearchParams(this.props.location.search).get('close') === 'true') {
+            window.close()
+        } else {
+            const returnTo = getReturnTo(this.props.location)
+            window.location.replace(returnTo)
+        }
+    }
+
     private handleSubmit = (event: React.FormEvent<HTMLFormElement>): void => {
         event.preventDefault()
         if (this.state.loading) {
@@ -103,7 +131,8 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
 
         this.setState({ loading: true })
         eventLogger.log('InitiateSignIn')
-        fetch('/-/sign-in', {
+        const { ldapProvider } = this.props
+        fetch(ldapProvider ? ldapProvider.authenticationURL : '/-/sign-in', {
             credentials: 'same-origin',
             method: 'POST',
             headers: {
@@ -111,19 +140,21 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                 Accept: 'application/json',
                 'Content-Type': 'application/json',
             },
-            body: JSON.stringify({
-                email: this.state.email,
-                password: this.state.password,
-            }),
+            body: JSON.stringify(
+                ldapProvider
+                    ? { username: this.state.email, password: this.state.password }
+                    : { email: this.state.email, password: this.state.password }
+            ),
         })
-            .then(resp => {
+            .then(async resp => {
                 if (resp.status === 200) {
-                    if (new URLSearchParams(this.props.location.search).get('close') === 'true') {
-                        window.close()
-                    } else {
-                        const returnTo = getReturnTo(this.props.location)
-                        window.location.replace(returnTo)
+                    // The response body is only non-empty if two-factor authentication is required.
+                    const body = await resp.text()
+                    if (body) {
+                        this.setState({ loading: false, twoFactorChallenge: JSON.parse(body) })
+                        return
                     }
+                    this.onSignedIn()
                 } else if (resp.status === 401) {
                     throw new Error('User or password was incorrect')
                 } else {
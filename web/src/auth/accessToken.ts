@@ -4,4 +4,9 @@
 export enum AccessTokenScopes {
     UserAll = 'user:all',
     SiteAdminSudo = 'site-admin:sudo',
+    SearchRead = 'search:read',
+    RepoRead = 'repo:read',
+    CampaignsWrite = 'campaigns:write',
+    SettingsRead = 'settings:read',
+    SiteAdminSCIM = 'site-admin:scim',
 }
@@ -0,0 +1,94 @@
+/**
+ * Helpers for using the browser's WebAuthn API with the JSON options and responses of the Sourcegraph
+ * backend, in which binary values are base64url-encoded.
+ */
+
+function base64URLToBuffer(value: string): ArrayBuffer {
+    const base64 = value.replace(/-/g, '+').replace(/_/g, '/')
+    const binary = atob(base64 + '='.repeat((4 - (base64.length % 4)) % 4))
+    const bytes = new Uint8Array(binary.length)
+    for (let i = 0; i < binary.length; i++) {
+        bytes[i] = binary.charCodeAt(i)
+    }
+    return bytes.buffer
+}
+
+function bufferToBase64URL(buffer: ArrayBuffer): string {
+    const bytes = new Uint8Array(buffer)
+    let binary = ''
+    for (let i = 0; i < bytes.length; i++) {
+        binary += String.fromCharCode(bytes[i])
+    }
+    return btoa(binary)
+        .replace(/\+/g, '-')
+        .replace(/\//g, '_')
+        .replace(/=+$/, '')
+}
+
+/** Reports whether the browser supports WebAuthn security keys. */
+export const isWebAuthnSupported = (): boolean =>
+    typeof window.PublicKeyCredential !== 'undefined' && typeof navigator.credentials !== 'undefined'
+
+/**
+ * Registers a new security key with the given JSON-encoded PublicKeyCredentialCreationOptions and
+ * returns the JSON-encoded credential to send to the backend.
+ */
+export async function createWebAuthnCredential(optionsJSON: string): Promise<string> {
+    const options = JSON.parse(optionsJSON)
+    const credential = (await navigator.credentials.create({
+        publicKey: {
+            ...options,
+            challenge: base64URLToBuffer(options.challenge),
+            user: { ...options.user, id: base64URLToBuffer(options.user.id) },
+            excludeCredentials: options.excludeCredentials.map((c: { type: 'public-key'; id: string }) => ({
+                ...c,
+                id: base64URLToBuffer(c.id),
+            })),
+        },
+    })) as PublicKeyCredential | null
+    if (!credential) {
+        throw new Error('No security key was registered.')
+    }
+    const response = credential.response as AuthenticatorAttestationResponse & {
+        getAuthenticatorData?: () => ArrayBuffer
+        getPublicKey?: () => ArrayBuffer | null
+    }
+    const publicKey = response.getPublicKey && response.getPublicKey()
+    if (!response.getAuthenticatorData || !publicKey) {
+        throw new Error('This browser does not support registering security keys. Try a newer browser.')
+    }
+    return JSON.stringify({
+        id: bufferToBase64URL(credential.rawId),
+        clientDataJSON: bufferToBase64URL(response.clientDataJSON),
+        authenticatorData: bufferToBase64URL(response.getAuthenticatorData()),
+        publicKey: bufferToBase64URL(publicKey),
+    })
+}
+
+/**
+ * Verifies one of the user's security keys with the given JSON-encoded
+ * PublicKeyCredentialRequestOptions and returns the JSON-encoded assertion to send to the backend.
+ */
+export async function getWebAuthnAssertion(optionsJSON: string): Promise<string> {
+    const options = JSON.parse(optionsJSON)
+    const credential = (await navigator.credentials.get({
+        publicKey: {
+            ...options,
+            challenge: base64URLToBuffer(options.challenge),
+            allowCredentials: options.allowCredentials.map((c: { type: 'public-key'; id: string }) => ({
+                ...c,
+                id: base64URLToBuffer(c.id),
+            })),
+        },
+    })) as PublicKeyCredential | null
+    if (!credential) {
+        throw new Error('No security key was used.')
+    }
+    const response = credential.response as AuthenticatorAssertionResponse
+    return JSON.stringify({
+        id: bufferToBase64URL(credential.rawId),
+        clientDataJSON: bufferToBase64URL(response.clientDataJSON),
+        authenticatorData: bufferToBase64URL(response.authenticatorData),
+        signature: bufferToBase64URL(response.signature),
+    })
+}
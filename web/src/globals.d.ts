@@ -107,6 +107,7 @@ interface SourcegraphContext
 
     /** Authentication provider instances in site config. */
     authProviders?: {
+        serviceType: string
         displayName: string
         isBuiltin: boolean
         authenticationURL?: string
@@ -123,6 +123,19 @@ export function search(
                                             lineNumber
                                             offsetAndLengths
                                         }
+                                        multilineMatches {
+                                            preview
+                                            range {
+                                                start {
+                                                    line
+                                                    character
+                                                }
+                                                end {
+                                                    line
+                                                    character
+                                                }
+                                            }
+                                        }
                                     }
                                     ... on CommitSearchResult {
                                         ${genericSearchResultInterfaceFields}
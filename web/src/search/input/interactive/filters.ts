@@ -93,5 +93,13 @@ export const FilterTypeToProseNames: Record<FilterType, string> = {
     content: 'Content',
     patterntype: 'Pattern type',
     index: 'Indexed repos',
+    owner: 'Code owner',
+    blameauthor: 'Line last changed by',
+    blameafter: 'Line last changed after',
+    blamebefore: 'Line last changed before',
+    multiline: 'Multiline matches',
+    refs: 'Commits from refs',
+    merges: 'Merge commits',
+    firstparent: 'First parent only',
     visibility: 'Repository visiblity',
 }
@@ -17,17 +17,40 @@ import { eventLogger } from '../../../tracking/eventLogger'
 import { UserAreaRouteContext } from '../../area/UserArea'
 import { ErrorAlert } from '../../../components/alerts'
 
-function createAccessToken(user: GQL.ID, scopes: string[], note: string): Observable<GQL.ICreateAccessTokenResult> {
+/** The scopes that let an access token act as its user, with their descriptions. */
+const USER_SCOPES: { scope: AccessTokenScopes; description: string }[] = [
+    { scope: AccessTokenScopes.UserAll, description: 'Full control of all resources accessible to the user account' },
+    { scope: AccessTokenScopes.SearchRead, description: 'Ability to run searches' },
+    { scope: AccessTokenScopes.RepoRead, description: 'Ability to read repositories and their contents' },
+    { scope: AccessTokenScopes.CampaignsWrite, description: 'Ability to read, create and update campaigns' },
+    { scope: AccessTokenScopes.SettingsRead, description: 'Ability to read settings' },
+]
+
+/** The scopes that only site admins may create access tokens with, with their descriptions. */
+const SITE_ADMIN_SCOPES: { scope: AccessTokenScopes; description: string }[] = [
+    { scope: AccessTokenScopes.SiteAdminSudo, description: 'Ability to perform any action as any other user' },
+    {
+        scope: AccessTokenScopes.SiteAdminSCIM,
+        description: 'Ability to provision users and organizations with the SCIM API',
+    },
+]
+
+function createAccessToken(
+    user: GQL.ID,
+    scopes: string[],
+    note: string,
+    expiresAt: string | null
+): Observable<GQL.ICreateAccessTokenResult> {
     return mutateGraphQL(
         gql`
-            mutation CreateAccessToken($user: ID!, $scopes: [String!]!, $note: String!) {
-                createAccessToken(user: $user, scopes: $scopes, note: $note) {
+            mutation CreateAccessToken($user: ID!, $scopes: [String!]!, $note: String!, $expiresAt: DateTime) {
+                createAccessToken(user: $user, scopes: $scopes, note: $note, expiresAt: $expiresAt) {
                     id
                     token
                 }
             }
         `,
-        { user, scopes, note }
+        { user, scopes, note, expiresAt }
     ).pipe(
         map(({ data, errors }) => {
             if (!data || !data.createAccessToken || (errors && errors.length > 0)) {
@@ -52,6 +75,9 @@ interface State {
     /** The selected scopes checkboxes. */
     scopes: string[]
 
+    /** The contents of the expiry date input field (YYYY-MM-DD), or empty if the token never expires. */
+    expiresAt: string
+
     creationOrError?: 'loading' | GQL.ICreateAccessTokenResult | ErrorLike
 }
 
@@ -62,6 +88,7 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
     public state: State = {
         note: '',
         scopes: [AccessTokenScopes.UserAll],
+        expiresAt: '',
     }
 
     private submits = new Subject<React.FormEvent<HTMLFormElement>>()
@@ -77,7 +104,12 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
                     concatMap(() =>
                         concat(
                             [{ creationOrError: 'loading' }],
-                            createAccessToken(this.props.user.id, this.state.scopes, this.state.note).pipe(
+                            createAccessToken(
+                                this.props.user.id,
+                                this.state.scopes,
+                                this.state.note,
+                                this.state.expiresAt ? new Date(this.state.expiresAt).toISOString() : null
+                            ).pipe(
                                 tap(result => {
                                     // Go back to access tokens list page and display the token secret value.
                                     this.props.history.push(`${this.props.match.url.replace(/\/new$/, '')}`)
@@ -136,48 +168,53 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
                         <label className="mb-1" htmlFor="user-settings-create-access-token-page__note">
                             Token scope
                         </label>
-                        <div>
-                            <small className="form-help text-muted">
-                                Tokens with limited user scopes are not yet supported.
-                            </small>
-                        </div>
-                        <div className="form-check">
-                            <input
-                                className="form-check-input"
-                                type="checkbox"
-                                id="user-settings-create-access-token-page__scope-user:all"
-                                checked={true}
-                                value={AccessTokenScopes.UserAll}
-                                onChange={this.onScopesChange}
-                                disabled={true}
-                            />
-                            <label
-                                className="form-check-label"
-                                htmlFor="user-settings-create-access-token-page__scope-user:all"
-                            >
-                                <strong>{AccessTokenScopes.UserAll}</strong> — Full control of all resources accessible
-                                to the user account
-                            </label>
-                        </div>
-                        {this.props.user.siteAdmin && (
-                            <div className="form-check">
+                        {USER_SCOPES.map(({ scope, description }) => (
+                            <div className="form-check" key={scope}>
                                 <input
                                     className="form-check-input"
                                     type="checkbox"
-                                    id="user-settings-create-access-token-page__scope-site-admin:sudo"
-                                    checked={this.state.scopes.includes(AccessTokenScopes.SiteAdminSudo)}
-                                    value={AccessTokenScopes.SiteAdminSudo}
+                                    id={`user-settings-create-access-token-page__scope-${scope}`}
+                                    checked={this.state.scopes.includes(scope)}
+                                    value={scope}
                                     onChange={this.onScopesChange}
                                 />
                                 <label
                                     className="form-check-label"
-                                    htmlFor="user-settings-create-access-token-page__scope-site-admin:sudo"
+                                    htmlFor={`user-settings-create-access-token-page__scope-${scope}`}
                                 >
-                                    <strong>{AccessTokenScopes.SiteAdminSudo}</strong> — Ability to perform any action
-                                    as any other user
+                                    <strong>{scope}</strong> — {description}
                                 </label>
                             </div>
-                        )}
+                        ))}
+                        {this.props.user.siteAdmin &&
+                            SITE_ADMIN_SCOPES.map(({ scope, description }) => (
+                                <div className="form-check" key={scope}>
+                                    <input
+                                        className="form-check-input"
+                                        type="checkbox"
+                                        id={`user-settings-create-access-token-page__scope-${scope}`}
+                                        checked={this.state.scopes.includes(scope)}
+                                        value={scope}
+                                        onChange={this.onScopesChange}
+                                    />
+                                    <label
+                                        className="form-check-label"
+                                        htmlFor={`user-settings-create-access-token-page__scope-${scope}`}
+                                    >
+                                        <strong>{scope}</strong> — {description}
+                                    </label>
+                                </div>
+                            ))}
+                    </div>
+                    <div className="form-group">
+                        <label htmlFor="user-settings-create-access-token-page__expires-at">Expiration date</label>
+                        <input
+                            type="date"
+                            className="form-control"
+                            id="user-settings-create-access-token-page__expires-at"
+                            onChange={this.onExpiresAtChange}
+                        />
+                        <small className="form-help text-muted">Leave empty for a token that never expires.</small>
                     </div>
                     <button
                         type="submit"
@@ -208,6 +245,9 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
     private onNoteChange: React.ChangeEventHandler<HTMLInputElement> = e =>
         this.setState({ note: e.currentTarget.value })
 
+    private onExpiresAtChange: React.ChangeEventHandler<HTMLInputElement> = e =>
+        this.setState({ expiresAt: e.currentTarget.value })
+
     private onScopesChange: React.ChangeEventHandler<HTMLInputElement> = e => {
         const checked = e.currentTarget.checked
         const value = e.currentTarget.value
@@ -0,0 +1,432 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import DeleteIcon from 'mdi-react/DeleteIcon'
+import * as React from 'react'
+import { RouteComponentProps } from 'react-router'
+import { map } from 'rxjs/operators'
+import { dataOrThrowErrors, gql } from '../../../../../shared/src/graphql/graphql'
+import * as GQL from '../../../../../shared/src/graphql/schema'
+import { asError, createAggregateError, ErrorLike, isErrorLike } from '../../../../../shared/src/util/errors'
+import { RecoveryCodes } from '../../../auth/RecoveryCodes'
+import { createWebAuthnCredential, isWebAuthnSupported } from '../../../auth/webAuthn'
+import { mutateGraphQL, queryGraphQL } from '../../../backend/graphql'
+import { ErrorAlert } from '../../../components/alerts'
+import { Form } from '../../../components/Form'
+import { PageTitle } from '../../../components/PageTitle'
+import { Timestamp } from '../../../components/time/Timestamp'
+import { eventLogger } from '../../../tracking/eventLogger'
+
+const fetchTwoFactor = (user: GQL.ID): Promise<GQL.IUserTwoFactor> =>
+    queryGraphQL(
+        gql`
+            query UserTwoFactor($user: ID!) {
+                node(id: $user) {
+                    ... on User {
+                        twoFactor {
+                            enabled
+                            required
+                            totpEnabled
+                            webAuthnCredentials {
+                                id
+                                name
+                                createdAt
+                                lastUsedAt
+                            }
+                            recoveryCodesRemaining
+                        }
+                    }
+                }
+            }
+        `,
+        { user }
+    )
+        .pipe(
+            map(dataOrThrowErrors),
+            map(data => {
+                if (!data.node) {
+                    throw new Error('User not found')
+                }
+                return (data.node as GQL.IUser).twoFactor
+            })
+        )
+        .toPromise()
+
+/** Runs a two-factor authentication mutation and returns the named field of its result. */
+const mutateTwoFactor = <T extends unknown>(
+    mutation: string,
+    field: keyof GQL.IMutation,
+    variables: { [name: string]: unknown }
+): Promise<T> =>
+    mutateGraphQL(mutation, variables)
+        .pipe(
+            map(({ data, errors }) => {
+                if (!data || !data[field] || (errors && errors.length > 0)) {
+                    throw createAggregateError(errors)
+                }
+                return (data[field] as unknown) as T
+            })
+        )
+        .toPromise()
+
+interface Props extends RouteComponentProps<{}> {
+    user: GQL.IUser
+    authenticatedUser: GQL.IUser
+}
+
+interface State {
+    twoFactor?: GQL.IUserTwoFactor | ErrorLike
+    totpEnrollment?: GQL.ITOTPEnrollment
+    code: string
+    /** The current user's password, to confirm removing second factors and generating recovery codes. */
+    password: string
+    recoveryCodes?: string[]
+    error?: Error
+    loading: boolean
+}
+
+/**
+ * A page for managing a user's second factors for two-factor authentication.
+ */
+export class UserSettingsTwoFactorPage extends React.Component<Props, State> {
+    public state: State = { code: '', password: '', loading: false }
+
+    public componentDidMount(): void {
+        eventLogger.logViewEvent('UserSettingsTwoFactor')
+        this.refresh()
+    }
+
+    public render(): JSX.Element | null {
+        const isSelf = this.props.authenticatedUser.id === this.props.user.id
+        const { twoFactor } = this.state
+        return (
+            <div className="user-settings-two-factor-page">
+                <PageTitle title="Two-factor authentication" />
+                <h2>Two-factor authentication</h2>
+                <p>
+                    Two-factor authentication requires a code from an authenticator app or a security key, in addition
+                    to the password, to sign in.
+                </p>
+                {this.state.error && <ErrorAlert className="mb-3" error={this.state.error} />}
+                {this.state.recoveryCodes && <RecoveryCodes className="mb-3" codes={this.state.recoveryCodes} />}
+                {twoFactor === undefined ? (
+                    <LoadingSpinner className="icon-inline" />
+                ) : isErrorLike(twoFactor) ? (
+                    <ErrorAlert error={twoFactor} />
+                ) : (
+                    <>
+                        {twoFactor.required && !twoFactor.enabled && (
+                            <div className="alert alert-warning">
+                                Two-factor authentication is required for this account.
+                            </div>
+                        )}
+                        {twoFactor.enabled && (
+                            <div className="form-group">
+                                <label htmlFor="user-settings-two-factor-page__password">
+                                    Your password (to remove second factors or generate new recovery codes)
+                                </label>
+                                <input
+                                    id="user-settings-two-factor-page__password"
+                                    className="form-control"
+                                    type="password"
+                                    value={this.state.password}
+                                    onChange={this.onPasswordFieldChange}
+                                    disabled={this.state.loading}
+                                    autoComplete="current-password"
+                                />
+                            </div>
+                        )}
+                        <h3>Authenticator app</h3>
+                        {twoFactor.totpEnabled ? (
+                            <p>
+                                <span className="badge badge-success">Enabled</span>{' '}
+                                <button
+                                    type="button"
+                                    className="btn btn-sm btn-danger"
+                                    onClick={this.removeTOTP}
+                                    disabled={this.state.loading}
+                                >
+                                    Remove
+                                </button>
+                            </p>
+                        ) : !isSelf ? (
+                            <p className="text-muted">Not enabled.</p>
+                        ) : this.state.totpEnrollment ? (
+                            <Form className="form-inline mb-3" onSubmit={this.confirmTOTPEnrollment}>
+                                <p className="w-100">
+                                    Add this account to your authenticator app by opening{' '}
+                                    <a href={this.state.totpEnrollment.uri}>this link</a> on your phone or entering
+                                    the key <code>{this.state.totpEnrollment.secret}</code>, then enter the 6-digit code
+                                    that it shows.
+                                </p>
+                                <input
+                                    className="form-control mr-2"
+                                    type="text"
+                                    placeholder="6-digit code"
+                                    value={this.state.code}
+                                    onChange={this.onCodeFieldChange}
+                                    required={true}
+                                    disabled={this.state.loading}
+                                    autoComplete="one-time-code"
+                                    inputMode="numeric"
+                                />
+                                <button type="submit" className="btn btn-primary" disabled={this.state.loading}>
+                                    Verify
+                                </button>
+                            </Form>
+                        ) : (
+                            <p>
+                                <button
+                                    type="button"
+                                    className="btn btn-secondary"
+                                    onClick={this.beginTOTPEnrollment}
+                                    disabled={this.state.loading}
+                                >
+                                    Set up an authenticator app
+                                </button>
+                            </p>
+                        )}
+                        <h3>Security keys</h3>
+                        {twoFactor.webAuthnCredentials.length > 0 ? (
+                            <ul className="list-group mb-3">
+                                {twoFactor.webAuthnCredentials.map(credential => (
+                                    <li
+                                        key={credential.id}
+                                        className="list-group-item py-2 d-flex align-items-center justify-content-between"
+                                    >
+                                        <div>
+                                            <strong>{credential.name}</strong>{' '}
+                                            <small className="text-muted">
+                                                added <Timestamp date={credential.createdAt} />
+                                                {credential.lastUsedAt && (
+                                                    <>
+                                                        , last used <Timestamp date={credential.lastUsedAt} />
+                                                    </>
+                                                )}
+                                            </small>
+                                        </div>
+                                        <button
+                                            type="button"
+                                            className="btn btn-sm btn-danger"
+                                            onClick={() => this.removeWebAuthnCredential(credential)}
+                                            disabled={this.state.loading}
+                                            data-tooltip="Remove security key"
+                                        >
+                                            <DeleteIcon className="icon-inline" />
+                                        </button>
+                                    </li>
+                                ))}
+                            </ul>
+                        ) : (
+                            <p className="text-muted">No security keys.</p>
+                        )}
+                        {isSelf && isWebAuthnSupported() && (
+                            <p>
+                                <button
+                                    type="button"
+                                    className="btn btn-secondary"
+                                    onClick={this.registerSecurityKey}
+                                    disabled={this.state.loading}
+                                >
+                                    Register a security key
+                                </button>
+                            </p>
+                        )}
+                        {twoFactor.enabled && (
+                            <>
+                                <h3>Recovery codes</h3>
+                                <p>
+                                    {twoFactor.recoveryCodesRemaining} unused recovery code
+                                    {twoFactor.recoveryCodesRemaining === 1 ? '' : 's'} remaining.
+                                </p>
+                                {isSelf && (
+                                    <p>
+                                        <button
+                                            type="button"
+                                            className="btn btn-secondary"
+                                            onClick={this.regenerateRecoveryCodes}
+                                            disabled={this.state.loading}
+                                        >
+                                            Generate new recovery codes
+                                        </button>
+                                    </p>
+                                )}
+                            </>
+                        )}
+                    </>
+                )}
+                {this.state.loading && <LoadingSpinner className="icon-inline" />}
+            </div>
+        )
+    }
+
+    private refresh(): void {
+        fetchTwoFactor(this.props.user.id).then(
+            twoFactor => this.setState({ twoFactor }),
+            error => this.setState({ twoFactor: asError(error) })
+        )
+    }
+
+    private onCodeFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
+        this.setState({ code: e.target.value })
+    }
+
+    private onPasswordFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
+        this.setState({ password: e.target.value })
+    }
+
+    /** The reauthentication that confirms removing second factors and generating recovery codes. */
+    private reauthentication(): GQL.ITwoFactorReauthenticationInput {
+        return { password: this.state.password }
+    }
+
+    private beginTOTPEnrollment = (): void =>
+        this.run(async () => {
+            const totpEnrollment = await mutateTwoFactor<GQL.ITOTPEnrollment>(
+                gql`
+                    mutation BeginTOTPEnrollment($user: ID!) {
+                        beginTOTPEnrollment(user: $user) {
+                            secret
+                            uri
+                        }
+                    }
+                `,
+                'beginTOTPEnrollment',
+                { user: this.props.user.id }
+            )
+            this.setState({ totpEnrollment, code: '' })
+        })
+
+    private confirmTOTPEnrollment = (event: React.FormEvent<HTMLFormElement>): void => {
+        event.preventDefault()
+        this.run(async () => {
+            const recoveryCodes = await mutateTwoFactor<string[]>(
+                gql`
+                    mutation ConfirmTOTPEnrollment($user: ID!, $code: String!) {
+                        confirmTOTPEnrollment(user: $user, code: $code)
+                    }
+                `,
+                'confirmTOTPEnrollment',
+                { user: this.props.user.id, code: this.state.code }
+            )
+            eventLogger.log('TwoFactorTOTPEnrolled')
+            this.setState({ totpEnrollment: undefined, code: '' })
+            this.showRecoveryCodes(recoveryCodes)
+        })
+    }
+
+    private removeTOTP = (): void => {
+        if (!window.confirm('Remove the authenticator app?')) {
+            return
+        }
+        this.run(async () => {
+            await mutateTwoFactor(
+                gql`
+                    mutation RemoveTOTP($user: ID!, $reauthentication: TwoFactorReauthenticationInput!) {
+                        removeTOTP(user: $user, reauthentication: $reauthentication) {
+                            alwaysNil
+                        }
+                    }
+                `,
+                'removeTOTP',
+                { user: this.props.user.id, reauthentication: this.reauthentication() }
+            )
+            eventLogger.log('TwoFactorTOTPRemoved')
+        })
+    }
+
+    private registerSecurityKey = (): void => {
+        const name = window.prompt('Name for the security key:', 'Security key')
+        if (name === null) {
+            return
+        }
+        this.run(async () => {
+            const options = await mutateTwoFactor<string>(
+                gql`
+                    mutation BeginWebAuthnRegistration($user: ID!) {
+                        beginWebAuthnRegistration(user: $user)
+                    }
+                `,
+                'beginWebAuthnRegistration',
+                { user: this.props.user.id }
+            )
+            const credential = await createWebAuthnCredential(options)
+            const recoveryCodes = await mutateTwoFactor<string[]>(
+                gql`
+                    mutation FinishWebAuthnRegistration($user: ID!, $name: String!, $credential: String!) {
+                        finishWebAuthnRegistration(user: $user, name: $name, credential: $credential)
+                    }
+                `,
+                'finishWebAuthnRegistration',
+                { user: this.props.user.id, name, credential }
+            )
+            eventLogger.log('TwoFactorSecurityKeyRegistered')
+            this.showRecoveryCodes(recoveryCodes)
+        })
+    }
+
+    private removeWebAuthnCredential = (credential: GQL.IWebAuthnCredential): void => {
+        if (!window.confirm(`Remove the security key ${credential.name}?`)) {
+            return
+        }
+        this.run(async () => {
+            await mutateTwoFactor(
+                gql`
+                    mutation RemoveWebAuthnCredential(
+                        $user: ID!
+                        $credential: ID!
+                        $reauthentication: TwoFactorReauthenticationInput!
+                    ) {
+                        removeWebAuthnCredential(
+                            user: $user
+                            credential: $credential
+                            reauthentication: $reauthentication
+                        ) {
+                            alwaysNil
+                        }
+                    }
+                `,
+                'removeWebAuthnCredential',
+                { user: this.props.user.id, credential: credential.id, reauthentication: this.reauthentication() }
+            )
+            eventLogger.log('TwoFactorSecurityKeyRemoved')
+        })
+    }
+
+    private regenerateRecoveryCodes = (): void => {
+        if (!window.confirm('Generate new recovery codes? Your existing recovery codes will no longer work.')) {
+            return
+        }
+        this.run(async () => {
+            const recoveryCodes = await mutateTwoFactor<string[]>(
+                gql`
+                    mutation RegenerateTwoFactorRecoveryCodes(
+                        $user: ID!
+                        $reauthentication: TwoFactorReauthenticationInput!
+                    ) {
+                        regenerateTwoFactorRecoveryCodes(user: $user, reauthentication: $reauthentication)
+                    }
+                `,
+                'regenerateTwoFactorRecoveryCodes',
+                { user: this.props.user.id, reauthentication: this.reauthentication() }
+            )
+            this.showRecoveryCodes(recoveryCodes)
+        })
+    }
+
+    private showRecoveryCodes(recoveryCodes: string[]): void {
+        if (recoveryCodes.length > 0) {
+            this.setState({ recoveryCodes })
+        }
+    }
+
+    /** Runs an action, then refreshes the two-factor authentication settings. */
+    private run(action: () => Promise<void>): void {
+        this.setState({ loading: true, error: undefined, recoveryCodes: undefined })
+        action().then(
+            () => {
+                this.setState({ loading: false, password: '' })
+                this.refresh()
+            },
+            error => this.setState({ loading: false, error: asError(error) })
+        )
+    }
+}
@@ -37,6 +37,12 @@ export const userSettingsAreaRoutes: readonly UserSettingsAreaRoute[] = [
         exact: true,
         render: lazyComponent(() => import('./auth/UserSettingsPasswordPage'), 'UserSettingsPasswordPage'),
     },
+    {
+        path: '/two-factor',
+        exact: true,
+        render: lazyComponent(() => import('./auth/UserSettingsTwoFactorPage'), 'UserSettingsTwoFactorPage'),
+        condition: ({ user }) => user.builtinAuth,
+    },
     {
         path: '/emails',
         exact: true,
@@ -19,6 +19,13 @@ export const userSettingsSideBarItems: UserSettingsSidebarItems = {
             // Only the builtin auth provider has a password.
             condition: ({ user }) => user.builtinAuth,
         },
+        {
+            label: 'Two-factor authentication',
+            to: '/two-factor',
+            exact: true,
+            // Only builtin (username-password) accounts support two-factor authentication.
+            condition: ({ user }) => user.builtinAuth,
+        },
         {
             label: 'Emails',
             to: '/emails',